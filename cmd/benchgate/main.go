@@ -0,0 +1,71 @@
+// benchgate compares a `go test -bench` run against a baseline run and
+// exits 1 when a benchmark regressed past the limits or is missing from the
+// new run. See `make bench`.
+//
+//	benchgate [-time 0.15] [-mem 0.10] <baseline.txt> <new.txt>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jack-work/figaro/internal/benchgate"
+)
+
+func main() {
+	lim := benchgate.DefaultLimits
+	flag.Float64Var(&lim.Time, "time", lim.Time, "allowed ns/op growth (0.15 = 15%)")
+	flag.Float64Var(&lim.Memory, "mem", lim.Memory, "allowed B/op and allocs/op growth")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: benchgate [-time F] [-mem F] <baseline.txt> <new.txt>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	old, err := load(flag.Arg(0))
+	if os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "benchgate: no baseline at %s; record one with make bench-baseline\n", flag.Arg(0))
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	cur, err := load(flag.Arg(1))
+	if err != nil {
+		fatal(err)
+	}
+	rep := benchgate.Compare(old, cur, lim)
+	if err := rep.Write(os.Stdout); err != nil {
+		fatal(err)
+	}
+	failed := false
+	if bad := rep.Regressions(); len(bad) > 0 {
+		fmt.Fprintf(os.Stderr, "benchgate: %d regression(s) past %.0f%% time / %.0f%% memory\n", len(bad), lim.Time*100, lim.Memory*100)
+		failed = true
+	}
+	if len(rep.Removed) > 0 {
+		fmt.Fprintf(os.Stderr, "benchgate: %d benchmark(s) missing from the new run; rerun make bench-baseline if they were dropped on purpose\n", len(rep.Removed))
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func load(path string) (benchgate.Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return benchgate.Parse(f)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "benchgate:", err)
+	os.Exit(2)
+}
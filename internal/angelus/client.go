@@ -3,6 +3,7 @@ package angelus
 import (
 	"context"
 
+	"github.com/jack-work/figaro/internal/message"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/transport"
 	"github.com/jack-work/jkrpc"
@@ -50,6 +51,13 @@ func (c *Client) CreateEphemeral(ctx context.Context, loadout string, patch *rpc
 	return &resp, err
 }
 
+// Import creates a dormant conversation under loadout holding msgs.
+func (c *Client) Import(ctx context.Context, loadout string, patch *rpc.ChalkboardPatch, msgs []message.Message) (*rpc.ImportResponse, error) {
+	var resp rpc.ImportResponse
+	err := c.cli.Call(ctx, rpc.MethodImport, rpc.ImportRequest{Loadout: loadout, Patch: patch, Messages: msgs}, &resp)
+	return &resp, err
+}
+
 // Promote climbs a conversation trunk up `levels` stump-bounded levels (it
 // absorbs its parent trunk's run). levels <= 0 means one level.
 func (c *Client) Promote(ctx context.Context, figaroID string, levels int) (*rpc.PromoteResponse, error) {
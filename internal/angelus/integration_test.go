@@ -334,3 +334,70 @@ model = "mock-model"
 	require.NotEqual(t, fr2.Continuation, fr2.Alternative)
 	require.Equal(t, created.FigaroID, fr2.Continuation)
 }
+
+// TestIntegration_Import writes a prompt and answer into a new dormant
+// conversation: no agent starts, the log and list carry the exchange, and
+// the per-import patch lands on its chalkboard.
+func TestIntegration_Import(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(dir+"/loadouts", 0700))
+	require.NoError(t, os.WriteFile(dir+"/loadouts/mock.toml", []byte(`
+[system]
+provider = "mock"
+model = "mock-model"
+`), 0600))
+
+	backend, err := store.NewXwalBackend(dir + "/arias")
+	require.NoError(t, err)
+	a := angelus.New(angelus.Config{RuntimeDir: testRuntimeDir(t, dir), Backend: backend})
+	factory := func(string, provider.Knobs) (provider.Provider, error) {
+		return &mockProviderForIntegration{}, nil
+	}
+	loaded, err := config.Load(dir)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.Handlers = angelus.NewHandlers(angelus.ServerConfig{
+		Angelus: a, Config: loaded, ProviderFactory: factory, Ctx: ctx,
+	}).Map
+	go a.Run(ctx)
+	defer a.Shutdown(0)
+
+	waitForAngelus(t, a.SocketPath)
+	acli, err := angelus.DialClient(transport.UnixEndpoint(a.SocketPath))
+	require.NoError(t, err)
+	defer acli.Close()
+
+	_, err = acli.Import(ctx, "mock", nil, nil)
+	require.Error(t, err, "empty import")
+
+	patch := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.model": json.RawMessage(`"batch-model"`)}}
+	resp, err := acli.Import(ctx, "mock", patch, []message.Message{
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent("2+2?")}},
+		{Role: message.RoleAssistant, Content: []message.Content{message.TextContent("4")},
+			StopReason: message.StopEnd, Usage: &message.Usage{InputTokens: 10, OutputTokens: 1}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.FigaroID)
+	assert.Nil(t, a.Registry.Get(resp.FigaroID), "import must not start an agent")
+
+	read, err := acli.AriaRead(ctx, resp.FigaroID, 0, 0)
+	require.NoError(t, err)
+	var texts []string
+	for _, e := range read.Entries {
+		var m message.Message
+		require.NoError(t, json.Unmarshal(e.Payload, &m))
+		for _, c := range m.Content {
+			texts = append(texts, string(m.Role)+":"+c.Text)
+		}
+	}
+	assert.Equal(t, []string{"user:2+2?", "assistant:4"}, texts)
+
+	meta, err := backend.Meta(resp.FigaroID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, meta.MessageCount)
+	assert.Equal(t, 1, meta.TurnCount)
+	assert.Equal(t, 10, meta.TokensIn)
+	assert.Equal(t, "batch-model", meta.Model)
+	assert.Equal(t, "mock", meta.Provider)
+}
@@ -46,6 +46,33 @@ type ServerConfig struct {
 
 	// ChalkboardTemplates renders Patches as system reminders. nil = skip.
 	ChalkboardTemplates *template.Template
+
+	// OnAgent is called with every agent the daemon creates or restores,
+	// once it is registered — the place to Subscribe daemon-side
+	// listeners. nil = none.
+	OnAgent func(*figaro.Agent)
+
+	// PromptGuard is handed to every agent as figaro.Config.PromptGuard.
+	// nil = prompts go out unchecked.
+	PromptGuard figaro.PromptGuard
+
+	// ResponseGuard is handed to every agent as
+	// figaro.Config.ResponseGuard. nil = model output goes unchecked.
+	ResponseGuard figaro.ResponseGuard
+
+	// Budget is handed to every agent as figaro.Config.Budget. nil = no
+	// spend limits.
+	Budget figaro.Budget
+
+	// Signer is handed to every agent as figaro.Config.Signer. nil =
+	// prompts are not signed.
+	Signer figaro.Signer
+
+	// Limits is handed to every agent as figaro.Config.Limits.
+	Limits figaro.Limits
+
+	// ReplyLang is handed to every agent as figaro.Config.ReplyLang.
+	ReplyLang string
 }
 
 // Handlers wraps the angelus JSON-RPC handler map.
@@ -64,10 +91,18 @@ func NewHandlers(cfg ServerConfig) *Handlers {
 		cbTmpls:            cfg.ChalkboardTemplates,
 		outfitter:          outfit.New(cfg.Config.ConfigDir),
 		availableProviders: cfg.AvailableProviders,
+		onAgent:            cfg.OnAgent,
+		promptGuard:        cfg.PromptGuard,
+		respGuard:          cfg.ResponseGuard,
+		budget:             cfg.Budget,
+		signer:             cfg.Signer,
+		limits:             cfg.Limits,
+		replyLang:          cfg.ReplyLang,
 	}
 	return &Handlers{
 		Map: map[string]jkrpc.HandlerFunc{
 			rpc.MethodCreate:       h.create,
+			rpc.MethodImport:       h.importConv,
 			rpc.MethodFork:         h.fork,
 			rpc.MethodPromote:      h.promote,
 			rpc.MethodKill:         h.kill,
@@ -97,6 +132,13 @@ type handlers struct {
 	cbTmpls            *template.Template
 	outfitter          *outfit.Outfitter
 	availableProviders []string
+	onAgent            func(*figaro.Agent)
+	promptGuard        figaro.PromptGuard
+	respGuard          figaro.ResponseGuard
+	budget             figaro.Budget
+	signer             figaro.Signer
+	limits             figaro.Limits
+	replyLang          string
 
 	// configMu guards config against concurrent reload + read. The
 	// reload-from-disk is cheap, but other handlers may dereference
@@ -227,39 +269,11 @@ func (h *handlers) create(ctx context.Context, params json.RawMessage) (interfac
 	// without a daemon restart. One os.ReadFile + toml.Unmarshal per
 	// request is cheap relative to anything downstream.
 	h.reloadConfigIfChanged()
-	loadoutName := req.Loadout
-	if loadoutName == "" {
-		loadoutName = h.config.Config.DefaultLoadout
-	}
-	if loadoutName == "" {
-		return nil, h.errNoDefaultLoadout()
-	}
-
-	// Resolve loadout -> chalkboard patch. Missing files are not
-	// fatal; the patch comes back empty and req.Patch may still
-	// supply system.provider. loadoutPatch is the STABLE loadout (it
-	// defines the loadout node's identity/version); base layers the
-	// per-create req.Patch overrides on top for provider/knob resolution.
-	loadoutPatch, err := h.outfitter.Load(loadoutName)
+	loadoutName, loadoutPatch, base, err := h.resolveLoadout(req.Loadout, req.Patch)
 	if err != nil {
-		return nil, h.errLoadoutNotFound(loadoutName, err)
-	}
-	base := chalkboard.Patch{Set: map[string]json.RawMessage{}}
-	for k, v := range loadoutPatch.Set {
-		base.Set[k] = v
-	}
-	base.Remove = append(base.Remove, loadoutPatch.Remove...)
-	if req.Patch != nil {
-		for k, v := range req.Patch.Set {
-			base.Set[k] = v
-		}
-		base.Remove = append(base.Remove, req.Patch.Remove...)
+		return nil, err
 	}
-
 	provName := patchString(base, "system.provider")
-	if provName == "" {
-		return nil, h.errNoProvider(loadoutName)
-	}
 	knobs := knobsFromPatch(base)
 
 	span.SetAttributes(
@@ -328,20 +342,29 @@ func (h *handlers) create(ctx context.Context, params json.RawMessage) (interfac
 	sockPath := filepath.Join(h.angelus.FigaroSocketDir(), id+".sock")
 
 	agent := figaro.NewAgent(figaro.Config{
-		ID:         id,
-		SocketPath: sockPath,
-		Provider:   prov,
-		Outfitter:  h.outfitter,
-		Tools:      tool.DefaultRegistryFn(cwdFromChalkboard(cbState, cwd)),
-		Backend:    backend,
-		Chalkboard: cbState,
-		InlineBoot: inlineBoot,
+		ID:            id,
+		SocketPath:    sockPath,
+		Provider:      prov,
+		Outfitter:     h.outfitter,
+		Tools:         tool.DefaultRegistryFn(cwdFromChalkboard(cbState, cwd)),
+		Backend:       backend,
+		Chalkboard:    cbState,
+		InlineBoot:    inlineBoot,
+		PromptGuard:   h.promptGuard,
+		ResponseGuard: h.respGuard,
+		Budget:        h.budget,
+		Signer:        h.signer,
+		Limits:        h.limits,
+		ReplyLang:     h.replyLang,
 	})
 
 	if err := h.angelus.Registry.Register(agent); err != nil {
 		agent.Kill()
 		return nil, err
 	}
+	if h.onAgent != nil {
+		h.onAgent(agent)
+	}
 
 	go agent.StartSocket(h.ctx)
 
@@ -357,6 +380,120 @@ func (h *handlers) create(ctx context.Context, params json.RawMessage) (interfac
 	}, nil
 }
 
+// resolveLoadout picks the loadout (name, else the configured default)
+// and loads it. Missing files are not fatal; the patch comes back empty
+// and reqPatch may still supply system.provider. loadoutPatch is the
+// STABLE loadout (it defines the loadout node's identity/version); base
+// layers the per-request reqPatch overrides on top for provider/knob
+// resolution and always names a provider.
+func (h *handlers) resolveLoadout(name string, reqPatch *rpc.ChalkboardPatch) (string, chalkboard.Patch, chalkboard.Patch, error) {
+	var loadoutPatch, base chalkboard.Patch
+	if name == "" {
+		name = h.config.Config.DefaultLoadout
+	}
+	if name == "" {
+		return name, loadoutPatch, base, h.errNoDefaultLoadout()
+	}
+	loadoutPatch, err := h.outfitter.Load(name)
+	if err != nil {
+		return name, loadoutPatch, base, h.errLoadoutNotFound(name, err)
+	}
+	base = chalkboard.Patch{Set: map[string]json.RawMessage{}}
+	for k, v := range loadoutPatch.Set {
+		base.Set[k] = v
+	}
+	base.Remove = append(base.Remove, loadoutPatch.Remove...)
+	if reqPatch != nil {
+		for k, v := range reqPatch.Set {
+			base.Set[k] = v
+		}
+		base.Remove = append(base.Remove, reqPatch.Remove...)
+	}
+	if patchString(base, "system.provider") == "" {
+		return name, loadoutPatch, base, h.errNoProvider(name)
+	}
+	return name, loadoutPatch, base, nil
+}
+
+// importConv writes req.Messages into a fresh conversation under the
+// loadout without starting an agent; the aria restores on first attach
+// like any dormant one.
+func (h *handlers) importConv(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	_, span := figOtel.Start(ctx, "angelus.import")
+	defer span.End()
+
+	var req rpc.ImportRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, err
+	}
+	backend := h.angelus.Backend
+	if backend == nil {
+		return nil, errors.New("import: no backend (ephemeral angelus)")
+	}
+	if len(req.Messages) == 0 {
+		return nil, errors.New("import: no messages")
+	}
+	h.reloadConfigIfChanged()
+	loadoutName, loadoutPatch, _, err := h.resolveLoadout(req.Loadout, req.Patch)
+	if err != nil {
+		return nil, err
+	}
+	loadoutID, err := backend.CreateLoadout(loadoutName, loadoutPatch)
+	if err != nil {
+		return nil, fmt.Errorf("create loadout node: %w", err)
+	}
+	id, err := backend.CreateConversation(loadoutID)
+	if err != nil {
+		return nil, fmt.Errorf("create conversation: %w", err)
+	}
+	cwd, _ := os.Getwd()
+	if boot := convBootPatch(req.Patch, id, cwd); !boot.IsEmpty() {
+		if err := backend.ApplyChalkboard(id, boot); err != nil {
+			return nil, fmt.Errorf("seed conversation chalkboard: %w", err)
+		}
+	}
+	figLog, err := backend.Open(id)
+	if err != nil {
+		return nil, fmt.Errorf("open conversation: %w", err)
+	}
+	now := time.Now().UnixMilli()
+	meta := &store.AriaMeta{CreatedAtMS: now, LastActiveMS: now}
+	for _, m := range req.Messages {
+		if m.Timestamp == 0 {
+			m.Timestamp = now
+		}
+		e, err := figLog.Append(store.Entry[message.Message]{Payload: m})
+		if err != nil {
+			return nil, fmt.Errorf("import %s: append: %w", id, err)
+		}
+		meta.MessageCount++
+		meta.LastFigaroLT = e.LT
+		if m.Role == message.RoleAssistant {
+			meta.TurnCount++
+		}
+		if u := m.Usage; u != nil {
+			meta.TokensIn += u.InputTokens
+			meta.TokensOut += u.OutputTokens
+			meta.CacheReadTokens += u.CacheReadTokens
+			meta.CacheWriteTokens += u.CacheWriteTokens
+			meta.ContextTokens, meta.ContextExact = u.InputTokens+u.OutputTokens, true
+		}
+	}
+	if snap, err := backend.ChalkboardState(id); err == nil {
+		state := chalkboard.Patch{Set: snap}
+		meta.Provider = patchString(state, "system.provider")
+		meta.Model = patchString(state, "system.model")
+		meta.Cwd = patchString(state, "system.cwd")
+		meta.LoadoutName = patchString(state, "system.loadout_name")
+		meta.LoadoutVersion = patchString(state, "system.loadout_version")
+	}
+	if err := backend.SetMeta(id, meta); err != nil {
+		slog.Warn("import: write aria meta", "aria", id, "err", err)
+	}
+	slog.Info("imported figaro", "id", id, "loadout", loadoutName, "messages", len(req.Messages))
+	return rpc.ImportResponse{FigaroID: id}, nil
+}
+
 // fork branches a conversation at its head. The addressed trunk keeps its id
 // and remains live; the alternative is a new dormant conversation.
 func (h *handlers) fork(ctx context.Context, params json.RawMessage) (interface{}, error) {
@@ -455,6 +592,7 @@ func (h *handlers) forkMetaSnapshot(parent string) *store.AriaMeta {
 		copy.Cwd = info.Cwd
 		copy.LoadoutName = info.LoadoutName
 		copy.LoadoutVersion = info.LoadoutVersion
+		copy.Persona = info.Persona
 		copy.ContextTokens = info.ContextTokens
 		copy.ContextLimit = info.ContextLimit
 		copy.ContextExact = info.ContextExact
@@ -727,6 +865,7 @@ func (h *handlers) list(ctx context.Context, params json.RawMessage) (interface{
 			Mantra:           info.Mantra,
 			Cwd:              info.Cwd,
 			LoadoutName:      info.LoadoutName,
+			Persona:          info.Persona,
 			BoundPIDs:        boundPIDs[info.ID],
 		}
 		if !req.IDsOnly && info.LoadoutName != "" {
@@ -856,6 +995,7 @@ func (h *handlers) fillFromMeta(meta *store.AriaMeta, entry *rpc.FigaroInfoRespo
 	entry.Mantra = meta.Mantra
 	entry.Cwd = meta.Cwd
 	entry.LoadoutName = meta.LoadoutName
+	entry.Persona = meta.Persona
 	if meta.CreatedAtMS != 0 {
 		entry.CreatedAt = meta.CreatedAtMS
 	}
@@ -1159,21 +1299,30 @@ func (h *handlers) restoreOne(ctx context.Context, ariaID string) (figaro.Figaro
 		}
 	}
 	agent := figaro.NewAgent(figaro.Config{
-		ID:         ariaID,
-		SocketPath: sockPath,
-		Provider:   prov,
-		Outfitter:  h.outfitter,
-		Tools:      tool.DefaultRegistryFn(cwdFromChalkboard(cb, toolRoot)),
-		Backend:    h.angelus.Backend,
-		Chalkboard: cb,
-		CreatedAt:  createdAt,
-		LastActive: lastActive,
+		ID:            ariaID,
+		SocketPath:    sockPath,
+		Provider:      prov,
+		Outfitter:     h.outfitter,
+		Tools:         tool.DefaultRegistryFn(cwdFromChalkboard(cb, toolRoot)),
+		Backend:       h.angelus.Backend,
+		Chalkboard:    cb,
+		CreatedAt:     createdAt,
+		LastActive:    lastActive,
+		PromptGuard:   h.promptGuard,
+		ResponseGuard: h.respGuard,
+		Budget:        h.budget,
+		Signer:        h.signer,
+		Limits:        h.limits,
+		ReplyLang:     h.replyLang,
 	})
 
 	if err := h.angelus.Registry.Register(agent); err != nil {
 		agent.Kill()
 		return nil, fmt.Errorf("restore %s: register: %w", ariaID, err)
 	}
+	if h.onAgent != nil {
+		h.onAgent(agent)
+	}
 
 	go agent.StartSocket(ctx)
 
@@ -0,0 +1,154 @@
+// Package audit keeps an append-only, hash-chained log of what the daemon
+// sends and runs on an aria's behalf: provider requests, prompts, tool
+// calls and the guard's decisions on them. Each entry carries the hash of
+// the one before it, so an edited, dropped or reordered line breaks the
+// chain at that point. Prompt, answer and argument text is stored only as
+// a SHA-256 digest; the conversations hold the text itself.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry kinds.
+const (
+	KindRequest = "request" // one provider Send
+	KindPrompt  = "prompt"  // a prompt, after the prompt guard
+	KindTool    = "tool"    // a tool call, before it runs
+	KindAnswer  = "answer"  // a finished turn's answer
+)
+
+// Entry is one line of the log.
+type Entry struct {
+	Seq        int64    `json:"seq"`
+	Time       string   `json:"time"`
+	Kind       string   `json:"kind"`
+	Aria       string   `json:"aria,omitempty"`
+	Provider   string   `json:"provider,omitempty"`
+	Model      string   `json:"model,omitempty"`
+	Tool       string   `json:"tool,omitempty"`
+	Digest     string   `json:"digest,omitempty"`   // sha256 of the text the entry is about
+	Decision   string   `json:"decision,omitempty"` // sent, masked, blocked, allowed, flagged, ok, error
+	Rules      []string `json:"rules,omitempty"`    // guard rules or secret kinds behind the decision
+	DurationMS int64    `json:"duration_ms,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Prev       string   `json:"prev"`
+	Hash       string   `json:"hash"`
+}
+
+// Digest is the hex SHA-256 of text, as stored in Entry.Digest.
+func Digest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// hash chains e to its predecessor: the SHA-256 of e's JSON with Hash
+// empty. Prev is part of that JSON.
+func (e Entry) hash() string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends to one audit file. Safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	seq  int64
+	last string
+}
+
+// Open opens or creates the log at path and picks the chain up from its
+// last entry. A log whose tail does not parse is refused rather than
+// forked.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{f: f}
+	var tail Entry
+	err = Scan(f, func(e Entry) error {
+		tail = e
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	l.seq, l.last = tail.Seq, tail.Hash
+	return l, nil
+}
+
+// Append stamps e with the next seq, the time (when unset) and the chain
+// hashes, and writes it as one line.
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Seq = l.seq + 1
+	if e.Time == "" {
+		e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	e.Prev = l.last
+	e.Hash = e.hash()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	l.seq, l.last = e.Seq, e.Hash
+	return nil
+}
+
+func (l *Log) Close() error { return l.f.Close() }
+
+// Scan calls fn with each entry of r in order. It stops at the first line
+// that is not an entry, or at fn's error.
+func Scan(r io.Reader, fn func(Entry) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// Verify walks the chain in r and returns the last entry that checked out;
+// its Seq is the count of good entries. The error names the first entry
+// whose seq, link or hash is wrong.
+func Verify(r io.Reader) (Entry, error) {
+	var prev Entry
+	err := Scan(r, func(e Entry) error {
+		switch {
+		case e.Seq != prev.Seq+1:
+			return fmt.Errorf("seq %d: follows seq %d (entries dropped or reordered)", e.Seq, prev.Seq)
+		case e.Prev != prev.Hash:
+			return fmt.Errorf("seq %d: prev does not match the hash of seq %d", e.Seq, prev.Seq)
+		case e.Hash != e.hash():
+			return fmt.Errorf("seq %d: hash mismatch (entry altered)", e.Seq)
+		}
+		prev = e
+		return nil
+	})
+	return prev, err
+}
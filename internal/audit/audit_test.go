@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeEntries(t *testing.T, path string, n int) {
+	t.Helper()
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if err := l.Append(Entry{Kind: KindTool, Aria: "a1", Tool: "bash", Decision: "allowed"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func verifyFile(t *testing.T, path string) (int64, error) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	last, err := Verify(f)
+	return last.Seq, err
+}
+
+func TestChainSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeEntries(t, path, 3)
+	writeEntries(t, path, 2)
+	n, err := verifyFile(t, path)
+	if err != nil || n != 5 {
+		t.Fatalf("Verify = %d, %v; want 5, nil", n, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeEntries(t, path, 4)
+	b, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n")
+
+	cases := map[string]string{
+		"altered":   strings.Join(lines[:1], "") + strings.Replace(lines[1], `"allowed"`, `"blocked"`, 1) + strings.Join(lines[2:], ""),
+		"dropped":   lines[0] + lines[2] + lines[3],
+		"reordered": lines[0] + lines[2] + lines[1] + lines[3],
+	}
+	for name, text := range cases {
+		if _, err := Verify(strings.NewReader(text)); err == nil {
+			t.Errorf("%s: Verify passed", name)
+		}
+	}
+	if _, err := Verify(strings.NewReader(lines[0] + lines[1])); err != nil {
+		t.Errorf("truncated tail must still verify: %v", err)
+	}
+}
+
+func TestOpenRefusesCorruptTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeEntries(t, path, 1)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	f.WriteString("{not json\n")
+	f.Close()
+	if _, err := Open(path); err == nil {
+		t.Fatal("Open accepted a corrupt log")
+	}
+}
+
+func TestConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				l.Append(Entry{Kind: KindRequest, Provider: "mock"})
+			}
+		}()
+	}
+	wg.Wait()
+	l.Close()
+	if n, err := verifyFile(t, path); err != nil || n != 80 {
+		t.Fatalf("Verify = %d, %v; want 80, nil", n, err)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	if got := Digest("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("Digest = %s", got)
+	}
+	var b bytes.Buffer
+	if last, err := Verify(&b); last.Seq != 0 || err != nil {
+		t.Fatalf("empty log: %d, %v", last.Seq, err)
+	}
+}
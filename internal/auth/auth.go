@@ -4,6 +4,8 @@
 // and hush in priority order.
 package auth
 
+import "net/http"
+
 // OAuthConfig describes an OAuth provider's endpoints. Used by the login
 // flow to drive the PKCE handshake and seed hush with the result.
 type OAuthConfig struct {
@@ -14,3 +16,7 @@ type OAuthConfig struct {
 	ClientID     string
 	Scopes       string
 }
+
+// HTTPClient sends the login flows' requests. The CLI points it at the
+// shared provider transport so [network] proxy settings apply.
+var HTTPClient = http.DefaultClient
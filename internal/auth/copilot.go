@@ -54,7 +54,7 @@ func LoginDeviceCode(cfg DeviceCodeConfig, domain string, promptCode func(userCo
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("device code request: %w", err)
 	}
@@ -117,7 +117,7 @@ func pollAccessToken(tokenURL, clientID, deviceCode string) (token string, done
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient.Do(req)
 	if err != nil {
 		return "", false, 0, fmt.Errorf("poll token: %w", err)
 	}
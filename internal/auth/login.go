@@ -71,7 +71,7 @@ func Login(hushClient *hush.Client, cfg OAuthConfig, promptCode func() (string,
 		"code_verifier": pkce.Verifier,
 	})
 
-	resp, err := http.Post(cfg.TokenURL, "application/json", strings.NewReader(string(tokenBody)))
+	resp, err := HTTPClient.Post(cfg.TokenURL, "application/json", strings.NewReader(string(tokenBody)))
 	if err != nil {
 		return fmt.Errorf("token exchange: %w", err)
 	}
@@ -0,0 +1,207 @@
+// Package benchgate compares two runs of `go test -bench` and reports the
+// benchmarks that got slower or allocate more. It backs `make bench`: the
+// baseline run is recorded on the commit to compare against, and a change
+// that regresses a hot path past the limits fails the target.
+//
+// Each benchmark's samples (-count N) are reduced to their median, which a
+// single noisy run cannot move. Time is compared by ns/op; memory by B/op
+// and allocs/op, which are steadier and so get a tighter limit.
+package benchgate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Set is one run: each benchmark's samples by unit ("ns/op", "B/op",
+// "allocs/op", ...). Names are qualified by package.
+type Set map[string]map[string][]float64
+
+// procSuffix is the -GOMAXPROCS suffix go test appends to a name.
+var procSuffix = regexp.MustCompile(`-\d+$`)
+
+// Parse reads go test -bench output. Lines other than pkg: headers and
+// benchmark results are ignored.
+func Parse(r io.Reader) (Set, error) {
+	set := Set{}
+	pkg := ""
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimSpace(p)
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 4 || !strings.HasPrefix(f[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(f[1]); err != nil {
+			continue // "BenchmarkX --- FAIL" and the like
+		}
+		name := procSuffix.ReplaceAllString(f[0], "")
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		units := set[name]
+		if units == nil {
+			units = map[string][]float64{}
+			set[name] = units
+		}
+		for i := 2; i+1 < len(f); i += 2 {
+			v, err := strconv.ParseFloat(f[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: value %q: %w", name, f[i], err)
+			}
+			units[f[i+1]] = append(units[f[i+1]], v)
+		}
+	}
+	return set, sc.Err()
+}
+
+// Limits are the relative growth past which a benchmark regresses: 0.15
+// fails a benchmark 15% slower than its baseline.
+type Limits struct {
+	Time   float64 // ns/op
+	Memory float64 // B/op and allocs/op
+}
+
+// DefaultLimits leave room for run-to-run noise on a quiet machine.
+var DefaultLimits = Limits{Time: 0.15, Memory: 0.10}
+
+// gated are the units compared, lower being better.
+var gated = []string{"ns/op", "B/op", "allocs/op"}
+
+// Row is one benchmark's comparison in one unit.
+type Row struct {
+	Name      string
+	Unit      string
+	Old, New  float64
+	Delta     float64 // (New-Old)/Old
+	Regressed bool
+}
+
+// Report is the comparison of two runs.
+type Report struct {
+	Rows []Row
+	// Added and Removed are benchmarks only in the new or the old run.
+	// A removed one fails the gate: it most often stopped running (a build
+	// failure or a panic), not left on purpose.
+	Added, Removed []string
+}
+
+// Regressions returns the rows past their limit.
+func (r Report) Regressions() []Row {
+	var out []Row
+	for _, row := range r.Rows {
+		if row.Regressed {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// Compare reduces both runs to medians and compares them under lim.
+func Compare(old, cur Set, lim Limits) Report {
+	var rep Report
+	for _, name := range sortedNames(cur) {
+		if _, ok := old[name]; !ok {
+			rep.Added = append(rep.Added, name)
+		}
+	}
+	for _, name := range sortedNames(old) {
+		units, ok := cur[name]
+		if !ok {
+			rep.Removed = append(rep.Removed, name)
+			continue
+		}
+		for _, unit := range gated {
+			o, n := median(old[name][unit]), median(units[unit])
+			if math.IsNaN(o) || math.IsNaN(n) {
+				continue
+			}
+			row := Row{Name: name, Unit: unit, Old: o, New: n}
+			if o > 0 {
+				row.Delta = (n - o) / o
+			}
+			limit := lim.Memory
+			if unit == "ns/op" {
+				limit = lim.Time
+			}
+			// An allocation or a few bytes more on a tiny count is not a
+			// regression however large the percentage.
+			row.Regressed = row.Delta > limit && (unit == "ns/op" || n-o >= 2)
+			rep.Rows = append(rep.Rows, row)
+		}
+	}
+	return rep
+}
+
+// Write prints the report as a table, regressions marked.
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "benchmark\tunit\told\tnew\tdelta\t\t")
+	for _, row := range r.Rows {
+		mark := ""
+		if row.Regressed {
+			mark = "REGRESSION"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%+.1f%%\t%s\t\n", short(row.Name), row.Unit, num(row.Old), num(row.New), row.Delta*100, mark)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, name := range r.Added {
+		fmt.Fprintf(w, "new (no baseline): %s\n", short(name))
+	}
+	for _, name := range r.Removed {
+		fmt.Fprintf(w, "gone (baseline only): %s\n", short(name))
+	}
+	return nil
+}
+
+// short drops the package path down to its last element.
+func short(name string) string {
+	if i := strings.Index(name, ".Benchmark"); i >= 0 {
+		return path.Base(name[:i]) + name[i:]
+	}
+	return name
+}
+
+func num(v float64) string {
+	if v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func sortedNames(s Set) []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// median is NaN for no samples.
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
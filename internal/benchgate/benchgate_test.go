@@ -0,0 +1,84 @@
+package benchgate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const baseline = `goos: linux
+pkg: github.com/jack-work/figaro/internal/store
+BenchmarkConversationSave/xwal/messages=100-8   	    8112	     30000 ns/op	   10000 B/op	     165 allocs/op
+BenchmarkConversationSave/xwal/messages=100-8   	    8112	     29000 ns/op	   10000 B/op	     165 allocs/op
+BenchmarkConversationSave/xwal/messages=100-8   	    8112	     90000 ns/op	   10000 B/op	     165 allocs/op
+BenchmarkGone-8   	    10	     100 ns/op
+pkg: github.com/jack-work/figaro/internal/render
+BenchmarkMarkdownRender/cold-8   	     121	  12000000 ns/op	   0.28 MB/s	 2000000 B/op	   46000 allocs/op
+BenchmarkTiny-8   	 1000000	     10 ns/op	   0 B/op	   1 allocs/op
+--- FAIL: BenchmarkBroken
+PASS
+`
+
+const (
+	save   = "github.com/jack-work/figaro/internal/store.BenchmarkConversationSave/xwal/messages=100"
+	render = "github.com/jack-work/figaro/internal/render.BenchmarkMarkdownRender/cold"
+	tiny   = "github.com/jack-work/figaro/internal/render.BenchmarkTiny"
+)
+
+func parse(t *testing.T, s string) Set {
+	t.Helper()
+	set, err := Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestParse(t *testing.T) {
+	set := parse(t, baseline)
+	if got := set[save]["ns/op"]; len(got) != 3 || got[2] != 90000 {
+		t.Fatalf("ns/op samples = %v", got)
+	}
+	if md := set[render]; md["MB/s"][0] != 0.28 || md["allocs/op"][0] != 46000 {
+		t.Fatalf("render units = %v", md)
+	}
+	if len(set) != 4 {
+		t.Fatalf("parsed %d benchmarks, want 4", len(set))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cur := `pkg: github.com/jack-work/figaro/internal/store
+BenchmarkConversationSave/xwal/messages=100-8   	    8112	     31000 ns/op	   10000 B/op	     165 allocs/op
+BenchmarkNew-8   	    10	     100 ns/op
+pkg: github.com/jack-work/figaro/internal/render
+BenchmarkMarkdownRender/cold-8   	     121	  12100000 ns/op	   0.28 MB/s	 2400000 B/op	   46000 allocs/op
+BenchmarkTiny-8   	 1000000	     13 ns/op	   0 B/op	   2 allocs/op
+`
+	rep := Compare(parse(t, baseline), parse(t, cur), DefaultLimits)
+
+	var got []string
+	for _, r := range rep.Regressions() {
+		got = append(got, r.Name+" "+r.Unit)
+	}
+	// save: the 90000 outlier does not move the median (30000 -> 31000).
+	// render: B/op grew 20%. tiny: 30% slower; one more alloc is noise.
+	want := []string{render + " B/op", tiny + " ns/op"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("regressions:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(rep.Added) != 1 || !strings.HasSuffix(rep.Added[0], "BenchmarkNew") {
+		t.Fatalf("added = %v", rep.Added)
+	}
+	if len(rep.Removed) != 1 || !strings.HasSuffix(rep.Removed[0], "BenchmarkGone") {
+		t.Fatalf("removed = %v", rep.Removed)
+	}
+
+	var out bytes.Buffer
+	if err := rep.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "REGRESSION") != 2 {
+		t.Fatalf("report:\n%s", out.String())
+	}
+}
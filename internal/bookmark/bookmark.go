@@ -0,0 +1,152 @@
+// Package bookmark keeps notes and bookmarks on individual messages. They
+// live in a JSON file beside the aria store, keyed by aria and LT, so
+// marking a message never touches its log or its hash chain.
+package bookmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/filelock"
+)
+
+// Mark is a bookmark on one message. Note is empty for a plain bookmark.
+type Mark struct {
+	Aria    string    `json:"aria"`
+	LT      int       `json:"lt"`
+	Note    string    `json:"note,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// ErrNoMark is a Remove of a message that is not marked.
+var ErrNoMark = errors.New("no such bookmark")
+
+// Store is the bookmark file. Edits hold <path>.lock, so two figaro
+// processes marking messages at once do not lose each other's marks.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// Open returns the store at path; the file is created by the first Set.
+func Open(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// lock serializes edits within the process and across processes.
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	f, err := filelock.Acquire(s.path + ".lock")
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		f.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) load() ([]Mark, error) {
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var marks []Mark
+	if err := json.Unmarshal(raw, &marks); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return marks, nil
+}
+
+func (s *Store) save(marks []Mark) error {
+	raw, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Set marks aria's message at lt with note, replacing the note of a mark
+// already there. The mark keeps its original creation time.
+func (s *Store) Set(aria string, lt int, note string) (Mark, error) {
+	if aria == "" || lt < 1 {
+		return Mark{}, fmt.Errorf("bookmark %s:%d: want an aria and an LT of 1 or more", aria, lt)
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return Mark{}, err
+	}
+	defer unlock()
+	marks, err := s.load()
+	if err != nil {
+		return Mark{}, err
+	}
+	for i, m := range marks {
+		if m.Aria == aria && m.LT == lt {
+			marks[i].Note = note
+			return marks[i], s.save(marks)
+		}
+	}
+	m := Mark{Aria: aria, LT: lt, Note: note, Created: s.now().UTC()}
+	return m, s.save(append(marks, m))
+}
+
+// Remove drops the mark on aria's message at lt.
+func (s *Store) Remove(aria string, lt int) (Mark, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return Mark{}, err
+	}
+	defer unlock()
+	marks, err := s.load()
+	if err != nil {
+		return Mark{}, err
+	}
+	for i, m := range marks {
+		if m.Aria == aria && m.LT == lt {
+			return m, s.save(append(marks[:i], marks[i+1:]...))
+		}
+	}
+	return Mark{}, ErrNoMark
+}
+
+// List returns aria's marks in LT order, or every aria's (newest first)
+// when aria is empty.
+func (s *Store) List(aria string) ([]Mark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	marks, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if aria == "" {
+		sort.SliceStable(marks, func(i, j int) bool { return marks[i].Created.After(marks[j].Created) })
+		return marks, nil
+	}
+	var out []Mark
+	for _, m := range marks {
+		if m.Aria == aria {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LT < out[j].LT })
+	return out, nil
+}
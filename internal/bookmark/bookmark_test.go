@@ -0,0 +1,46 @@
+package bookmark
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := Open(filepath.Join(t.TempDir(), "bookmarks.json"))
+	s.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	if _, err := s.Set("aria1", 7, "the fix"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("aria1", 3, "")
+	s.Set("aria2", 1, "elsewhere")
+	first, _ := s.Set("aria1", 7, "the real fix")
+	if first.Note != "the real fix" || !first.Created.Equal(time.Date(2026, 3, 1, 12, 1, 0, 0, time.UTC)) {
+		t.Errorf("re-set = %+v", first)
+	}
+
+	marks, err := s.List("aria1")
+	if err != nil || len(marks) != 2 || marks[0].LT != 3 || marks[1].Note != "the real fix" {
+		t.Fatalf("list aria1 = %+v %v", marks, err)
+	}
+	all, _ := s.List("")
+	if len(all) != 3 || all[0].Aria != "aria2" {
+		t.Errorf("list all = %+v", all)
+	}
+
+	if _, err := s.Remove("aria1", 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Remove("aria1", 3); !errors.Is(err, ErrNoMark) {
+		t.Errorf("second remove: %v", err)
+	}
+	if _, err := s.Set("aria1", 0, ""); err == nil {
+		t.Error("set at LT 0 succeeded")
+	}
+	if marks, _ := s.List("aria1"); len(marks) != 1 || marks[0].LT != 7 {
+		t.Errorf("left = %+v", marks)
+	}
+}
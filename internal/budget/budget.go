@@ -0,0 +1,203 @@
+// Package budget keeps the daemon's token and dollar spend per calendar
+// day and checks it against the configured daily and monthly limits. The
+// ledger is one small JSON file, rewritten after every provider response;
+// the CLI reads the same file to show what is left.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Usage is spend over some period. Tokens counts input plus output;
+// cache reads and writes are kept for the record but priced, not counted.
+type Usage struct {
+	TokensIn   int     `json:"tokens_in,omitempty"`
+	TokensOut  int     `json:"tokens_out,omitempty"`
+	CacheRead  int     `json:"cache_read,omitempty"`
+	CacheWrite int     `json:"cache_write,omitempty"`
+	USD        float64 `json:"usd,omitempty"`
+}
+
+func (u Usage) Tokens() int { return u.TokensIn + u.TokensOut }
+
+func (u *Usage) add(v Usage) {
+	u.TokensIn += v.TokensIn
+	u.TokensOut += v.TokensOut
+	u.CacheRead += v.CacheRead
+	u.CacheWrite += v.CacheWrite
+	u.USD += v.USD
+}
+
+// keepMonths bounds the ledger: days older than this are dropped on write.
+const keepMonths = 13
+
+func dayKey(t time.Time) string { return t.Local().Format("2006-01-02") }
+
+// Ledger is the per-day spend file. Safe for concurrent use.
+type Ledger struct {
+	mu   sync.Mutex
+	path string
+	days map[string]Usage
+}
+
+type ledgerFile struct {
+	Days map[string]Usage `json:"days"`
+}
+
+// Open reads the ledger at path; a missing file is an empty ledger.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{path: path, days: map[string]Usage{}}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f ledgerFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for k, v := range f.Days {
+		l.days[k] = v
+	}
+	return l, nil
+}
+
+// Add books u against t's day and writes the ledger.
+func (l *Ledger) Add(t time.Time, u Usage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d := l.days[dayKey(t)]
+	d.add(u)
+	l.days[dayKey(t)] = d
+	cutoff := dayKey(t.AddDate(0, -keepMonths, 0))
+	for k := range l.days {
+		if k < cutoff {
+			delete(l.days, k)
+		}
+	}
+	return l.save()
+}
+
+func (l *Ledger) save() error {
+	b, err := json.MarshalIndent(ledgerFile{Days: l.days}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// Spent is the spend on t's day and in t's calendar month.
+func (l *Ledger) Spent(t time.Time) (day, month Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	today := dayKey(t)
+	prefix := today[:len("2006-01-")]
+	for k, v := range l.days {
+		if k == today {
+			day.add(v)
+		}
+		if len(k) == len(today) && k[:len(prefix)] == prefix {
+			month.add(v)
+		}
+	}
+	return day, month
+}
+
+// Limits are the configured caps; zero means no cap.
+type Limits struct {
+	DailyTokens   int     `json:"daily_tokens,omitempty"`
+	MonthlyTokens int     `json:"monthly_tokens,omitempty"`
+	DailyUSD      float64 `json:"daily_usd,omitempty"`
+	MonthlyUSD    float64 `json:"monthly_usd,omitempty"`
+}
+
+func (lim Limits) IsZero() bool { return lim == Limits{} }
+
+// ExceededError reports the first limit that spend has reached, or that
+// a request of Next tokens would pass.
+type ExceededError struct {
+	Period string // "daily" or "monthly"
+	USD    bool   // a dollar limit; else tokens
+	Limit  float64
+	Spent  float64
+	Next   int
+}
+
+func (e *ExceededError) Error() string {
+	if e.Next > 0 {
+		return fmt.Sprintf("%s budget of %.0f tokens would be passed by a %d-token request (%.0f spent)", e.Period, e.Limit, e.Next, e.Spent)
+	}
+	if e.USD {
+		return fmt.Sprintf("%s budget of $%.2f reached ($%.2f spent)", e.Period, e.Limit, e.Spent)
+	}
+	return fmt.Sprintf("%s budget of %.0f tokens reached (%.0f spent)", e.Period, e.Limit, e.Spent)
+}
+
+// Check returns an *ExceededError when day or month spend has reached a
+// limit, daily limits first. next is the input size of the request about
+// to be sent, 0 when unknown; a token limit it would pass counts as
+// reached. Dollar limits look at spend only.
+func (lim Limits) Check(day, month Usage, next int) error {
+	over := func(limit, spent int) bool {
+		return limit > 0 && (spent >= limit || next > 0 && spent+next > limit)
+	}
+	switch {
+	case over(lim.DailyTokens, day.Tokens()):
+		return &ExceededError{"daily", false, float64(lim.DailyTokens), float64(day.Tokens()), tokensNext(lim.DailyTokens, day.Tokens(), next)}
+	case lim.DailyUSD > 0 && day.USD >= lim.DailyUSD:
+		return &ExceededError{"daily", true, lim.DailyUSD, day.USD, 0}
+	case over(lim.MonthlyTokens, month.Tokens()):
+		return &ExceededError{"monthly", false, float64(lim.MonthlyTokens), float64(month.Tokens()), tokensNext(lim.MonthlyTokens, month.Tokens(), next)}
+	case lim.MonthlyUSD > 0 && month.USD >= lim.MonthlyUSD:
+		return &ExceededError{"monthly", true, lim.MonthlyUSD, month.USD, 0}
+	}
+	return nil
+}
+
+// tokensNext is the Next to report: 0 when spend alone reached the limit.
+func tokensNext(limit, spent, next int) int {
+	if spent >= limit {
+		return 0
+	}
+	return next
+}
+
+// Remaining is what is left under each limit; fields whose limit is unset
+// are -1. Spend past a limit leaves 0.
+type Remaining struct {
+	DailyTokens   int     `json:"daily_tokens"`
+	MonthlyTokens int     `json:"monthly_tokens"`
+	DailyUSD      float64 `json:"daily_usd"`
+	MonthlyUSD    float64 `json:"monthly_usd"`
+}
+
+func (lim Limits) Remaining(day, month Usage) Remaining {
+	r := Remaining{-1, -1, -1, -1}
+	if lim.DailyTokens > 0 {
+		r.DailyTokens = max(lim.DailyTokens-day.Tokens(), 0)
+	}
+	if lim.MonthlyTokens > 0 {
+		r.MonthlyTokens = max(lim.MonthlyTokens-month.Tokens(), 0)
+	}
+	if lim.DailyUSD > 0 {
+		r.DailyUSD = max(lim.DailyUSD-day.USD, 0)
+	}
+	if lim.MonthlyUSD > 0 {
+		r.MonthlyUSD = max(lim.MonthlyUSD-month.USD, 0)
+	}
+	return r
+}
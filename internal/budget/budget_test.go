@@ -0,0 +1,95 @@
+package budget
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLedgerSpent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mar31 := time.Date(2026, 3, 31, 12, 0, 0, 0, time.Local)
+	apr1 := time.Date(2026, 4, 1, 9, 0, 0, 0, time.Local)
+	apr2 := apr1.AddDate(0, 0, 1)
+	l.Add(mar31, Usage{TokensIn: 500, USD: 1})
+	l.Add(apr1, Usage{TokensIn: 100, TokensOut: 20, CacheRead: 7, USD: 0.5})
+	l.Add(apr1, Usage{TokensIn: 10, USD: 0.25})
+	if err := l.Add(apr2, Usage{TokensOut: 5, USD: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day, month := l.Spent(apr1)
+	if day.Tokens() != 130 || day.CacheRead != 7 || day.USD != 0.75 {
+		t.Errorf("apr 1 = %+v", day)
+	}
+	if month.Tokens() != 135 {
+		t.Errorf("april = %+v, want march left out", month)
+	}
+	if day, _ := l.Spent(apr2.AddDate(0, 0, 1)); day != (Usage{}) {
+		t.Errorf("empty day = %+v", day)
+	}
+
+	l.Add(mar31.AddDate(1, 1, 0), Usage{TokensIn: 1})
+	if _, month := l.Spent(mar31); month.Tokens() != 0 {
+		t.Errorf("march 2026 kept past %d months: %+v", keepMonths, month)
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	l, err := Open(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day, month := l.Spent(time.Now()); day != (Usage{}) || month != (Usage{}) {
+		t.Errorf("spent = %+v, %+v", day, month)
+	}
+}
+
+func TestLimitsCheck(t *testing.T) {
+	lim := Limits{DailyTokens: 100, MonthlyUSD: 10}
+	if err := lim.Check(Usage{TokensIn: 99}, Usage{USD: 9.99}, 0); err != nil {
+		t.Errorf("under both: %v", err)
+	}
+	err := lim.Check(Usage{TokensIn: 60, TokensOut: 40}, Usage{USD: 20}, 0)
+	var ex *ExceededError
+	if !errors.As(err, &ex) || ex.Period != "daily" || ex.USD {
+		t.Fatalf("both reached = %v, want the daily cap first", err)
+	}
+	if got := err.Error(); got != "daily budget of 100 tokens reached (100 spent)" {
+		t.Errorf("message = %q", got)
+	}
+	err = lim.Check(Usage{}, Usage{USD: 12.5}, 0)
+	if got := err.Error(); got != "monthly budget of $10.00 reached ($12.50 spent)" {
+		t.Errorf("message = %q", got)
+	}
+	err = lim.Check(Usage{TokensIn: 90}, Usage{}, 20)
+	if got := err.Error(); got != "daily budget of 100 tokens would be passed by a 20-token request (90 spent)" {
+		t.Errorf("message = %q", got)
+	}
+	if err := lim.Check(Usage{TokensIn: 90}, Usage{}, 10); err != nil {
+		t.Errorf("request that fits exactly: %v", err)
+	}
+	if err := lim.Check(Usage{TokensIn: 100}, Usage{}, 20); err.Error() != "daily budget of 100 tokens reached (100 spent)" {
+		t.Errorf("already reached = %v", err)
+	}
+	if (Limits{}).Check(Usage{TokensIn: 1e9}, Usage{USD: 1e9}, 1e9) != nil {
+		t.Error("no limits refused")
+	}
+}
+
+func TestLimitsRemaining(t *testing.T) {
+	lim := Limits{DailyTokens: 100, MonthlyUSD: 10}
+	r := lim.Remaining(Usage{TokensIn: 150}, Usage{USD: 4})
+	if r != (Remaining{DailyTokens: 0, MonthlyTokens: -1, DailyUSD: -1, MonthlyUSD: 6}) {
+		t.Errorf("remaining = %+v", r)
+	}
+}
@@ -64,6 +64,17 @@ func WellKnownKeys() []KeyDoc {
 		{Key: "system.top_p", Short: "Copilot Responses nucleus sampling (greater than 0 through 1; mutually exclusive with temperature)", Mode: KeyUserSettable},
 		{Key: "system.parallel_tool_calls", Short: "Whether Copilot Responses may emit parallel function calls", Mode: KeyUserSettable},
 		{Key: "system.environment.<name>", Short: "Allowlisted env var capture", Mode: KeyUserSettable},
+		{Key: "system.sink", Short: "Config [sinks] name (or list) each finished answer is POSTed to", Mode: KeyUserSettable},
+		{Key: "system.pins", Short: "Pinned messages and snippets kept in the system prompt (figaro pin)", Mode: KeyUserSettable},
+		{Key: "system.tools", Short: "Names of the only tools offered to the model (unset: every tool)", Mode: KeyUserSettable},
+		{Key: "system.persona", Short: "Config [personas] name the aria last took (send --persona)", Mode: KeyUserSettable},
+		{Key: "system.reply_lang", Short: `Language answers are written in (send --reply-lang); "auto" follows the prompt`, Mode: KeyUserSettable},
+		{Key: "system.guard", Short: `Prompt secret scan for this aria: "off", "block", or "mask" (overrides config [guard])`, Mode: KeyUserSettable},
+		{Key: "system.web_search", Short: "Anthropic server-side web search on (send --web-search); off by default", Mode: KeyUserSettable},
+		{Key: "system.web_search_max_uses", Short: "Web searches allowed per answer (default 5)", Mode: KeyUserSettable},
+		{Key: "system.web_search_domains", Short: "Only domains web search may return (list or comma-separated)", Mode: KeyUserSettable},
+		{Key: "system.web_fetch", Short: "Anthropic server-side web fetch on; off by default", Mode: KeyUserSettable},
+		{Key: "system.code_execution", Short: "Anthropic server-side code execution sandbox on; off by default", Mode: KeyUserSettable},
 
 		{Key: "system.cwd", Short: "Canonical working directory (set at create time)", Mode: KeySystemManaged},
 		{Key: "model", Short: "Active model ID", Mode: KeySystemManaged},
@@ -7,14 +7,18 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/filelock"
 	figOtel "github.com/jack-work/figaro/internal/otel"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
 )
 
-// lockStore takes a non-blocking exclusive flock on the aria store so only one
+// lockStore takes a non-blocking exclusive lock on the aria store so only one
 // angelus ever has it open. Returns the open handle (keep it alive for the
 // daemon's lifetime — closing it releases the lock) and whether it was
 // acquired. A crashed holder's lock is released by the kernel, so the next
@@ -29,7 +33,7 @@ func lockStore() (*os.File, bool) {
 	if err != nil {
 		return nil, false
 	}
-	if err := tryLockFile(f); err != nil {
+	if err := filelock.TryLock(f); err != nil {
 		f.Close()
 		return nil, false
 	}
@@ -82,6 +86,9 @@ func runAngelus() {
 		os.Exit(0)
 	}
 	defer lockF.Close()
+	if err := migrateAriaDir(stateDir(), dataDir()); err != nil {
+		slog.Warn("aria store migration", "err", err)
+	}
 
 	otelShutdown, err := figOtel.Init(context.Background(), stateDir())
 	if err != nil {
@@ -103,6 +110,31 @@ func runAngelus() {
 	})
 
 	cbTmpls := buildChalkboard()
+	for _, err := range applySinks(loaded.Config.Sinks) {
+		slog.Warn("config [sinks]", "err", err)
+	}
+	for _, err := range applyMiddleware(loaded.Config.Middleware, stateDir()) {
+		slog.Warn("config [middleware]", "err", err)
+	}
+	if err := applyAudit(loaded.Config.Audit); err != nil {
+		slog.Error("config [audit]: audit log not written", "err", err)
+	}
+	modelPrices = loaded.Config.Prices
+	if err := applyBudget(loaded.Config.Budget); err != nil {
+		slog.Error("config [budget]: spend not booked, limits off", "err", err)
+	}
+	if err := providerPkg.ConfigureTransport(loaded.Config.Network); err != nil {
+		slog.Error("config [network]: using the environment's proxy and system roots", "err", err)
+	}
+
+	promptGuard, errs := buildPromptGuard(loaded.Config.Guard)
+	for _, err := range errs {
+		slog.Error("config [guard]", "err", err)
+	}
+	respGuard, errs := buildResponseGuard(loaded.Config.Guard)
+	for _, err := range errs {
+		slog.Warn("config [guard]", "err", err)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -114,6 +146,20 @@ func runAngelus() {
 		AvailableProviders:  KnownProviders(),
 		Ctx:                 ctx,
 		ChalkboardTemplates: cbTmpls,
+		OnAgent:             onAgent(attachDelivery(ctx), attachSync(ctx, loaded)),
+		PromptGuard:         promptGuard,
+		ResponseGuard:       respGuard,
+		Budget:              buildBudget(),
+		Signer:              buildSigner(loaded),
+		Limits: figaro.Limits{
+			ToolResultBytes:  loaded.ToolResultBytes(),
+			ResponseTokens:   loaded.Config.Limits.ResponseTokens,
+			SummarizeTokens:  loaded.SummarizeTokens(),
+			Summarize:        buildSummarizer(loaded),
+			StreamSpillBytes: loaded.StreamSpillBytes(),
+			SpillDir:         streamSpillDir(),
+		},
+		ReplyLang: strings.TrimSpace(loaded.Config.ReplyLang),
 	})
 	a.Handlers = handlers.Map
 
@@ -123,6 +169,9 @@ func runAngelus() {
 	// loses its credential ("No provider connected") mid-session. This is the
 	// primary fix for the long-autonomous-session credential loss.
 	go keepHushAlive(ctx)
+	go runScheduler(ctx, scheduleDir(), handlers.Restore)
+	runMirrors(ctx, loaded)
+	serveShares(ctx, loaded, backend)
 
 	angelus.RestoreBindings(a.Registry, a.BindingsPath(), func(ariaID string) error {
 		_, err := handlers.Restore(ctx, ariaID)
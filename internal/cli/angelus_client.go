@@ -14,9 +14,9 @@ import (
 	"github.com/jack-work/figaro/internal/transport"
 )
 
-// ariaBackend constructs the XWAL aria tree under the configured state root.
+// ariaBackend constructs the XWAL aria tree under the configured data root.
 func ariaBackend() (store.Backend, error) {
-	return store.NewXwalBackend(filepath.Join(stateDir(), "arias"))
+	return store.NewXwalBackend(ariaDir())
 }
 
 func angelusRuntimeDir() string {
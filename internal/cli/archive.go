@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// Archiving moves a finished conversation out of the aria store into a
+// gzipped JSON file beside it (<data>/archive/<id>.json.gz): the log and
+// the chalkboard it ended with. It drops out of list, show still reads it,
+// and unarchive imports it back as a fresh aria (ids are minted by the
+// store, so the copy gets a new one). Each archive has a small sidecar
+// (<id>.meta.json) with what listing shows, so a list never decompresses
+// a log.
+
+// archivedAria is one archive file.
+type archivedAria struct {
+	ID         string                     `json:"id"`
+	Mantra     string                     `json:"mantra,omitempty"`
+	ArchivedAt int64                      `json:"archived_at"` // unix millis
+	Chalkboard map[string]json.RawMessage `json:"chalkboard,omitempty"`
+	Messages   []message.Message          `json:"messages"`
+}
+
+// archiveMeta is an archive's sidecar: what list shows of it.
+type archiveMeta struct {
+	ID         string `json:"id"`
+	Mantra     string `json:"mantra,omitempty"`
+	ArchivedAt int64  `json:"archived_at"` // unix millis
+	Messages   int    `json:"messages"`
+}
+
+func (a archivedAria) meta() archiveMeta {
+	return archiveMeta{ID: a.ID, Mantra: a.Mantra, ArchivedAt: a.ArchivedAt, Messages: len(a.Messages)}
+}
+
+func archiveDir() string { return filepath.Join(filepath.Dir(ariaDir()), "archive") }
+
+func archivePath(dir, id string) string { return filepath.Join(dir, id+".json.gz") }
+
+func archiveMetaPath(dir, id string) string { return filepath.Join(dir, id+".meta.json") }
+
+// writeArchive writes the archive through a temp file so a crash never leaves a
+// truncated archive under the final name.
+func writeArchive(dir string, a archivedAria) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, a.ID+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	zw := gzip.NewWriter(f)
+	zw.Name = a.ID + ".json"
+	err = json.NewEncoder(zw).Encode(a)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	path := archivePath(dir, a.ID)
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+	return path, writeArchiveMeta(dir, a.meta())
+}
+
+// writeArchiveMeta writes m atomically. An archive whose sidecar is lost
+// is still listed; listArchives rebuilds it.
+func writeArchiveMeta(dir string, m archiveMeta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "."+m.ID+".meta.tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, archiveMetaPath(dir, m.ID))
+}
+
+// removeArchive deletes an archive and its sidecar.
+func removeArchive(dir, id string) error {
+	os.Remove(archiveMetaPath(dir, id))
+	return os.Remove(archivePath(dir, id))
+}
+
+func readArchive(dir, id string) (archivedAria, error) {
+	var a archivedAria
+	f, err := os.Open(archivePath(dir, id))
+	if err != nil {
+		return a, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return a, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	if err := json.NewDecoder(zr).Decode(&a); err != nil {
+		return a, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return a, nil
+}
+
+// listArchives reads every archive's sidecar, newest first. An archive
+// written before sidecars existed is read once and given one; unreadable
+// files are skipped.
+func listArchives(dir string) ([]archiveMeta, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []archiveMeta
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json.gz")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if data, err := os.ReadFile(archiveMetaPath(dir, id)); err == nil {
+			var m archiveMeta
+			if json.Unmarshal(data, &m) == nil && m.ID == id {
+				out = append(out, m)
+				continue
+			}
+		}
+		a, err := readArchive(dir, id)
+		if err != nil {
+			continue
+		}
+		_ = writeArchiveMeta(dir, a.meta())
+		out = append(out, a.meta())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ArchivedAt > out[j].ArchivedAt })
+	return out, nil
+}
+
+// runArchive moves an aria into the archive. The file is written before
+// the aria is removed, and removed again if the store refuses (a trunk
+// with live branches).
+func runArchive(loaded *config.Loaded, id string) {
+	if err := rpc.ValidateAriaID(id); err != nil {
+		die("archive: %s", err)
+	}
+	if _, err := os.Stat(archivePath(archiveDir(), id)); err == nil {
+		die("archive: %s is already archived", id)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+
+	msgs, err := readAllMessages(ctx, acli, id)
+	if err != nil {
+		die("archive: %s", err)
+	}
+	if len(msgs) == 0 {
+		die("archive: %s has no messages (try: figaro kill %s)", id, id)
+	}
+	snap := fetchChalkboardSnapshot(loaded, id)
+	a := archivedAria{ID: id, ArchivedAt: time.Now().UnixMilli(), Chalkboard: snap, Messages: msgs}
+	_ = json.Unmarshal(snap["mantra"], &a.Mantra)
+	path, err := writeArchive(archiveDir(), a)
+	if err != nil {
+		die("archive: %s", err)
+	}
+	if err := acli.Kill(ctx, id, false); err != nil {
+		removeArchive(archiveDir(), id)
+		die("archive: %s", err)
+	}
+	size := ""
+	if fi, err := os.Stat(path); err == nil {
+		size = fmt.Sprintf(", %s", fmtBytes(fi.Size()))
+	}
+	fmt.Fprintf(os.Stderr, "archived %s (%d messages%s)\n", id, len(msgs), size)
+}
+
+// archiveFillins are chalkboard keys the store sets on every new aria;
+// unarchive leaves them to it.
+var archiveFillins = []string{"aria_id", "system.root"}
+
+// runUnarchive imports an archive as a fresh aria and, unless keep,
+// deletes the archive.
+func runUnarchive(loaded *config.Loaded, id string, keep bool) {
+	if err := rpc.ValidateAriaID(id); err != nil {
+		die("unarchive: %s", err)
+	}
+	a, err := readArchive(archiveDir(), id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			die("unarchive: no archived aria %s (try: figaro archive --list)", id)
+		}
+		die("unarchive: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	newID, err := restoreArchive(ctx, acli, a)
+	if err != nil {
+		die("unarchive: %s", err)
+	}
+	if !keep {
+		if err := removeArchive(archiveDir(), id); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unarchived %s as %s\n", id, newID)
+	fmt.Println(newID)
+}
+
+// restoreArchive imports an archive's log and chalkboard as a fresh aria
+// and returns its id.
+func restoreArchive(ctx context.Context, acli *angelus.Client, a archivedAria) (string, error) {
+	var patch *rpc.ChalkboardPatch
+	if len(a.Chalkboard) > 0 {
+		patch = &rpc.ChalkboardPatch{Set: maps.Clone(a.Chalkboard)}
+		for _, k := range archiveFillins {
+			delete(patch.Set, k)
+		}
+	}
+	msgs := slices.Clone(a.Messages)
+	for i := range msgs {
+		msgs[i].LogicalTime = 0 // the new log numbers its own
+	}
+	resp, err := acli.Import(ctx, "", patch, msgs)
+	if err != nil {
+		return "", err
+	}
+	return resp.FigaroID, nil
+}
+
+func runArchiveList(asJSON bool) {
+	archives, err := listArchives(archiveDir())
+	if err != nil {
+		die("archive: %s", err)
+	}
+	if asJSON {
+		if archives == nil {
+			archives = []archiveMeta{}
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(archives)
+		return
+	}
+	if len(archives) == 0 {
+		fmt.Fprintln(os.Stderr, "no archived arias")
+		return
+	}
+	printArchives(os.Stdout, archives)
+}
+
+func printArchives(out io.Writer, archives []archiveMeta) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSAVED\tMESSAGES\tMANTRA")
+	for _, a := range archives {
+		when := time.UnixMilli(a.ArchivedAt).Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", a.ID, when, a.Messages, truncateVisible(dash(a.Mantra), 60))
+	}
+	w.Flush()
+}
+
+// renderArchived is show for an archived aria: read-only, from the file.
+func renderArchived(loaded *config.Loaded, a archivedAria, opts showOpts) {
+	if opts.verbose {
+		die("show: %s is archived; --verbose needs a live aria (try: figaro unarchive --keep %s)", a.ID, a.ID)
+	}
+	entries := make([]store.Entry[message.Message], len(a.Messages))
+	for i, m := range a.Messages {
+		entries[i] = store.Entry[message.Message]{LT: m.LogicalTime, Payload: m}
+	}
+	fmt.Fprintf(os.Stderr, "(archived %s)\n", time.UnixMilli(a.ArchivedAt).Format("2006-01-02 15:04"))
+	renderEntries(loaded, a.ID, entries, opts)
+}
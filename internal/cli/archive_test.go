@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	older := archivedAria{ID: "aaaa1111", ArchivedAt: 1000, Messages: []message.Message{
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")}, LogicalTime: 3},
+	}}
+	newer := archivedAria{
+		ID: "bbbb2222", Mantra: "cleanup", ArchivedAt: 2000,
+		Chalkboard: map[string]json.RawMessage{"system.model": json.RawMessage(`"m"`)},
+		Messages:   []message.Message{{Role: message.RoleUser, Content: []message.Content{message.TextContent("yo")}}},
+	}
+	for _, a := range []archivedAria{older, newer} {
+		if _, err := writeArchive(dir, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "junk.json.gz"), []byte("not gzip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readArchive(dir, "aaaa1111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].LogicalTime != 3 || got.Messages[0].Content[0].Text != "hi" {
+		t.Errorf("read back %+v", got)
+	}
+
+	list, err := listArchives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != "bbbb2222" || list[1].ID != "aaaa1111" {
+		t.Errorf("list = %+v", list)
+	}
+	if list[0].Mantra != "cleanup" || list[0].Messages != 1 {
+		t.Errorf("listed %+v", list[0])
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 5 {
+		t.Errorf("temp files left behind: %d entries", len(entries))
+	}
+	if _, err := readArchive(dir, "cccc3333"); !os.IsNotExist(err) {
+		t.Errorf("missing archive: %v", err)
+	}
+}
+
+func TestListArchivesFromSidecars(t *testing.T) {
+	dir := t.TempDir()
+	a := archivedAria{ID: "aaaa1111", Mantra: "old", ArchivedAt: 1000, Messages: []message.Message{
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")}},
+	}}
+	if _, err := writeArchive(dir, a); err != nil {
+		t.Fatal(err)
+	}
+
+	// An archive from before sidecars is read once and given one.
+	if err := os.Remove(archiveMetaPath(dir, a.ID)); err != nil {
+		t.Fatal(err)
+	}
+	list, err := listArchives(dir)
+	if err != nil || len(list) != 1 || list[0] != a.meta() {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+	if _, err := os.Stat(archiveMetaPath(dir, a.ID)); err != nil {
+		t.Fatalf("sidecar not rebuilt: %v", err)
+	}
+
+	// Listing reads the sidecar alone, never the log.
+	if err := os.WriteFile(archivePath(dir, a.ID), []byte("not gzip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := listArchives(dir); len(list) != 1 || list[0].Mantra != "old" {
+		t.Errorf("list = %+v", list)
+	}
+
+	if err := removeArchive(dir, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("%d files left after remove", len(entries))
+	}
+}
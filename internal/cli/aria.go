@@ -13,6 +13,7 @@ import (
 
 	"github.com/jack-work/largo"
 
+	"github.com/jack-work/figaro/internal/angelus"
 	"github.com/jack-work/figaro/internal/compose"
 	"github.com/jack-work/figaro/internal/config"
 	"github.com/jack-work/figaro/internal/livedoc"
@@ -103,6 +104,17 @@ func renderAria(loaded *config.Loaded, id string, args []string) {
 		}
 	}
 
+	if store.IsJSONL(id) {
+		renderJSONL(loaded, id, opts)
+		return
+	}
+	if id != "" {
+		if a, err := readArchive(archiveDir(), id); err == nil {
+			renderArchived(loaded, a, opts)
+			return
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	acli := mustConnectAngelus(loaded)
@@ -128,13 +140,13 @@ func renderAria(loaded *config.Loaded, id string, args []string) {
 	if opts.before >= 0 {
 		resp, err = acli.AriaReadBefore(ctx, figaroID, 0, uint64(opts.before), opts.last)
 	} else if opts.from >= 0 {
-		resp, err = acli.AriaRead(ctx, figaroID, uint64(opts.from), 0)
+		resp, err = ariaReadAll(ctx, acli, figaroID, uint64(opts.from))
 	} else if !opts.all {
 		// Default tail read: fetch the last N entries from the true tail,
 		// not capped at the first 1000.
 		resp, err = acli.AriaReadBefore(ctx, figaroID, 0, ^uint64(0), opts.last)
 	} else {
-		resp, err = acli.AriaRead(ctx, figaroID, 0, 0)
+		resp, err = ariaReadAll(ctx, acli, figaroID, 0)
 	}
 	if err != nil {
 		die("aria.read: %s", err)
@@ -150,7 +162,29 @@ func renderAria(loaded *config.Loaded, id string, args []string) {
 			die("aria.read: parse LT=%d: %s", e.LT, err)
 		}
 	}
+	renderEntries(loaded, figaroID, entries, opts)
+}
 
+// ariaReadAll reads every entry from from on, following next_from past
+// aria.read's per-call cap.
+func ariaReadAll(ctx context.Context, acli *angelus.Client, id string, from uint64) (*rpc.AriaReadResponse, error) {
+	all := &rpc.AriaReadResponse{}
+	for {
+		resp, err := acli.AriaRead(ctx, id, from, 0)
+		if err != nil {
+			return nil, err
+		}
+		all.Entries = append(all.Entries, resp.Entries...)
+		all.Total = resp.Total
+		if resp.NextFrom == 0 || resp.NextFrom <= from {
+			return all, nil
+		}
+		from = resp.NextFrom
+	}
+}
+
+// renderEntries renders read IR entries per opts.
+func renderEntries(loaded *config.Loaded, figaroID string, entries []store.Entry[message.Message], opts showOpts) {
 	// --verbose / --literal: the raw IR path (inline transitions + extras,
 	// or unrendered IR markdown).
 	if opts.verbose || opts.literal {
@@ -190,7 +224,7 @@ func renderAria(loaded *config.Loaded, id string, args []string) {
 	fmt.Printf("# aria %s — %d units (showing %d–%d) · [N] is the LT to fork/send at\n\n", figaroID, len(units), lo+1, hi)
 	for i := lo; i < hi; i++ {
 		u := units[i]
-		hdr := messageHeader(u.Role)
+		hdr := messageHeader(u.Role, u.Author)
 		if hdr == "" {
 			hdr = u.Role // fallback for unknown roles
 		}
@@ -364,9 +398,9 @@ func renderMessage(w io.Writer, m message.Message, lt uint64, verbose bool) {
 			fmt.Fprintf(w, "**you** [#%d]\n\n> %s\n\n", lt, indentBlockquote(text))
 		}
 		for _, c := range toolResults {
-			marker := "↩"
+			marker := term.Sym("↩", "tool")
 			if c.IsError {
-				marker = "⚠"
+				marker = term.Sym("⚠", "failed tool")
 			}
 			fmt.Fprintf(w, "%s **%s** result\n\n```\n%s\n```\n\n", marker, c.ToolName, truncate(c.Text, 800))
 		}
@@ -400,10 +434,14 @@ func renderMessage(w io.Writer, m message.Message, lt uint64, verbose bool) {
 				fmt.Fprintf(w, "%s\n\n", c.Text)
 			case message.ContentThinking:
 				if verbose {
-					fmt.Fprintf(w, "> *🤔 %s*\n\n", c.Text)
+					fmt.Fprintf(w, "> *%s %s*\n\n", term.Sym("🤔", "thinking:"), c.Text)
+				}
+			case message.ContentToolInvoke, message.ContentServerToolInvoke:
+				fmt.Fprintf(w, "%s **%s** %s\n\n", term.Sym("→", "calls"), c.ToolName, toolCallSummary(c))
+			case message.ContentServerToolResult:
+				if verbose && c.Text != "" {
+					fmt.Fprintf(w, "> %s\n\n", indentBlockquote(c.Text))
 				}
-			case message.ContentToolInvoke:
-				fmt.Fprintf(w, "→ **%s** %s\n\n", c.ToolName, toolCallSummary(c))
 			}
 		}
 		if verbose && m.Usage != nil {
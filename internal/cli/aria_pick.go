@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/term"
+	"github.com/jack-work/figaro/internal/tui"
+)
+
+// pickAria lists the attendable arias and lets the user choose one. Used
+// when a viewer command runs with no id and no binding; errors when stdin
+// is not a terminal so scripts keep the plain "no figaro bound" failure.
+func pickAria(ctx context.Context, acli *angelus.Client) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no figaro bound to this shell (try: --id <id> or attend <id>)")
+	}
+	resp, err := acli.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list: %w", err)
+	}
+	opts := ariaPickOptions(resp.Figaros)
+	if len(opts) == 0 {
+		return "", fmt.Errorf("no arias yet (start one with: figaro new)")
+	}
+	return tui.PickAria(opts)
+}
+
+// ariaPickOptions turns a listing into picker rows, most recently active
+// first. Frozen fork points are skipped: they are read-only index nodes,
+// and their children carry the conversation on.
+func ariaPickOptions(figs []rpc.FigaroInfoResponse) []tui.ProviderOption {
+	live := make([]rpc.FigaroInfoResponse, 0, len(figs))
+	for _, f := range figs {
+		if !f.Frozen {
+			live = append(live, f)
+		}
+	}
+	sort.SliceStable(live, func(i, j int) bool { return live[i].LastActive > live[j].LastActive })
+
+	opts := make([]tui.ProviderOption, len(live))
+	for i, f := range live {
+		label := f.Mantra
+		if label == "" {
+			label = "(untitled)"
+		}
+		hint := fmt.Sprintf("%s · %d msgs", f.ID, f.MessageCount)
+		if f.LastActive > 0 {
+			hint += " · " + relAge(f.LastActive) + " ago"
+		}
+		if f.Parent != "" {
+			hint += " · fork of " + f.Parent
+		}
+		opts[i] = tui.ProviderOption{Key: f.ID, Label: firstRunes(label, 60), Hint: hint}
+	}
+	return opts
+}
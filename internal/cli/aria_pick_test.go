@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestAriaPickOptions(t *testing.T) {
+	figs := []rpc.FigaroInfoResponse{
+		{ID: "old", Mantra: "an old quest", MessageCount: 2, LastActive: 1000},
+		{ID: "root", Mantra: "forked", Frozen: true, LastActive: 5000},
+		{ID: "new", MessageCount: 7, LastActive: 3000, Parent: "root"},
+	}
+	opts := ariaPickOptions(figs)
+	if len(opts) != 2 {
+		t.Fatalf("got %d options, want 2 (frozen skipped): %+v", len(opts), opts)
+	}
+	if opts[0].Key != "new" || opts[1].Key != "old" {
+		t.Fatalf("order = %s, %s; want most recent first", opts[0].Key, opts[1].Key)
+	}
+	if opts[0].Label != "(untitled)" {
+		t.Errorf("label = %q, want (untitled)", opts[0].Label)
+	}
+	for _, want := range []string{"new", "7 msgs", "fork of root"} {
+		if !strings.Contains(opts[0].Hint, want) {
+			t.Errorf("hint %q missing %q", opts[0].Hint, want)
+		}
+	}
+	if opts[1].Label != "an old quest" {
+		t.Errorf("label = %q", opts[1].Label)
+	}
+}
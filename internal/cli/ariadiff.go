@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/term"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffTurn is one message flattened for comparison: a header line plus its
+// prose, with tool traffic reduced to one marker line per call or result.
+type diffTurn struct {
+	lt    uint64
+	lines []string
+}
+
+// runAriaDiff compares two arias that share history — typically the
+// alternatives of one fork. With one id (or none: the bound aria) the other
+// side is its next alternative at the nearest fork point. The shared prefix
+// is summarized; only the diverged turns are diffed.
+func runAriaDiff(loaded *config.Loaded, args []string, unified int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+
+	var a, b string
+	if len(args) > 0 {
+		a = args[0]
+	}
+	if len(args) > 1 {
+		b = args[1]
+	}
+	if a == "" {
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err != nil || !r.Found {
+			die("diff: no aria bound to this shell (pass <id> [<id>])")
+		}
+		a = r.FigaroID
+	}
+	if b == "" {
+		resp, err := acli.ListGlobal(ctx)
+		if err != nil {
+			die("diff: %s", err)
+		}
+		cands, at := forkCandidates(resp.Figaros, a)
+		if len(cands) < 2 {
+			die("diff: %s has no alternatives to compare against (pass a second id)", a)
+		}
+		b = cands[(at+1)%len(cands)]
+	}
+
+	left, err := readDiffTurns(ctx, acli, a)
+	if err != nil {
+		die("diff: %s", err)
+	}
+	right, err := readDiffTurns(ctx, acli, b)
+	if err != nil {
+		die("diff: %s", err)
+	}
+	shared := sharedTurns(left, right)
+	if shared == len(left) && shared == len(right) {
+		fmt.Fprintf(os.Stderr, "%s and %s have identical transcripts\n", a, b)
+		return
+	}
+	if shared > 0 {
+		fmt.Println(term.Dim(fmt.Sprintf("# %d shared turns through LT %d", shared, left[shared-1].lt)))
+	} else {
+		fmt.Println(term.Dim("# no shared turns"))
+	}
+	out, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        turnLines(left[shared:]),
+		B:        turnLines(right[shared:]),
+		FromFile: "aria " + a,
+		ToFile:   "aria " + b,
+		Context:  unified,
+	})
+	if err != nil {
+		die("diff: %s", err)
+	}
+	fmt.Print(colorizeDiff(out))
+}
+
+func readDiffTurns(ctx context.Context, acli *angelus.Client, id string) ([]diffTurn, error) {
+	resp, err := ariaReadAll(ctx, acli, id, 0)
+	if err != nil {
+		return nil, fmt.Errorf("aria.read %s: %w", id, err)
+	}
+	return diffTurnsOf(resp.Entries), nil
+}
+
+// diffTurnsOf flattens an aria page into comparable turns. Messages with
+// nothing to compare (chalkboard-only control turns, bare thinking) drop out.
+func diffTurnsOf(entries []rpc.AriaReadEntry) []diffTurn {
+	var turns []diffTurn
+	for _, e := range entries {
+		var m message.Message
+		if json.Unmarshal(e.Payload, &m) != nil {
+			continue
+		}
+		var body []string
+		for _, c := range m.Content {
+			switch c.Type {
+			case message.ContentProse:
+				body = append(body, strings.Split(strings.TrimRight(c.Text, "\n"), "\n")...)
+			case message.ContentToolInvoke:
+				body = append(body, "[tool "+c.ToolName+"]")
+			case message.ContentToolResult:
+				mark := "[result " + c.ToolName + "]"
+				if c.IsError {
+					mark = "[error " + c.ToolName + "]"
+				}
+				body = append(body, mark)
+			}
+		}
+		if len(body) == 0 {
+			continue
+		}
+		head := fmt.Sprintf("%s%s [%d]", term.Sym("── ", ""), m.Role, e.LT)
+		turns = append(turns, diffTurn{lt: e.LT, lines: append([]string{head}, body...)})
+	}
+	return turns
+}
+
+// sharedTurns counts the leading turns both sides hold verbatim. Headers
+// carry the LT, so a turn only matches at the same coordinate.
+func sharedTurns(a, b []diffTurn) int {
+	n := 0
+	for n < len(a) && n < len(b) && strings.Join(a[n].lines, "\n") == strings.Join(b[n].lines, "\n") {
+		n++
+	}
+	return n
+}
+
+func turnLines(turns []diffTurn) []string {
+	var out []string
+	for _, t := range turns {
+		for _, l := range t.lines {
+			out = append(out, l+"\n")
+		}
+	}
+	return out
+}
+
+// colorizeDiff paints a unified diff's +/-/@@ lines (no-op without color).
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, l := range lines {
+		body := strings.TrimSuffix(l, "\n")
+		nl := l[len(body):]
+		switch {
+		case strings.HasPrefix(body, "+++"), strings.HasPrefix(body, "---"):
+			lines[i] = term.Dim(body) + nl
+		case strings.HasPrefix(body, "+"):
+			lines[i] = term.Green(body) + nl
+		case strings.HasPrefix(body, "-"):
+			lines[i] = term.Red(body) + nl
+		case strings.HasPrefix(body, "@@"):
+			lines[i] = term.Cyan(body) + nl
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func diffEntry(t *testing.T, lt uint64, role message.Role, content ...message.Content) rpc.AriaReadEntry {
+	t.Helper()
+	b, err := json.Marshal(message.Message{Role: role, Content: content})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rpc.AriaReadEntry{LT: lt, Payload: b}
+}
+
+func TestDiffTurnsShareThePrefix(t *testing.T) {
+	prose := func(s string) message.Content { return message.Content{Type: message.ContentProse, Text: s} }
+	opener := diffEntry(t, 1, message.RoleUser, prose("name a colour"))
+	left := diffTurnsOf([]rpc.AriaReadEntry{
+		opener,
+		diffEntry(t, 2, message.RoleAssistant),
+		diffEntry(t, 3, message.RoleAssistant, prose("red\nlike a rose"),
+			message.Content{Type: message.ContentToolInvoke, ToolName: "bash"}),
+	})
+	right := diffTurnsOf([]rpc.AriaReadEntry{
+		opener,
+		diffEntry(t, 3, message.RoleAssistant, prose("blue\nlike the sea")),
+	})
+	if len(left) != 2 {
+		t.Fatalf("empty turns must drop out, got %d turns", len(left))
+	}
+	if got := sharedTurns(left, right); got != 1 {
+		t.Fatalf("sharedTurns = %d, want 1", got)
+	}
+	lines := strings.Join(turnLines(left[1:]), "")
+	if want := "── assistant [3]\nred\nlike a rose\n[tool bash]\n"; lines != want {
+		t.Fatalf("turnLines = %q, want %q", lines, want)
+	}
+}
+
+func TestColorizeDiffKeepsPlainTextWithoutColor(t *testing.T) {
+	// go test's stdout is not a TTY, so term color is off here.
+	in := "--- a\n+++ b\n@@ -1 +1 @@\n-red\n+blue\n"
+	if got := colorizeDiff(in); got != in {
+		t.Fatalf("colorizeDiff = %q, want unchanged", got)
+	}
+}
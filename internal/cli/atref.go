@@ -174,10 +174,18 @@ func fetchSnapshotForEndpoint(parent context.Context, ep transport.Endpoint) map
 }
 
 // expandAtRefsForEndpoint is the convenience wrapper used by the
-// prompt entry points: fetch the snapshot for ep and substitute @key!
-// references in prompt. Safe to call with a nil-ish endpoint; falls
-// through to the unexpanded prompt on any failure.
+// prompt entry points: substitute {{...}} prompt variables, then fetch
+// the snapshot for ep and substitute @key! references in prompt. Safe to
+// call with a nil-ish endpoint; falls through to the unexpanded prompt
+// on any failure. Variables go first so a chalkboard value can never
+// smuggle in a {{shell}}. A pending --paste attachment is appended
+// after both, so pasted text is never expanded.
 func expandAtRefsForEndpoint(ctx context.Context, ep transport.Endpoint, prompt string) string {
+	return withPaste(expandTypedPrompt(ctx, ep, prompt), takePaste())
+}
+
+func expandTypedPrompt(ctx context.Context, ep transport.Endpoint, prompt string) string {
+	prompt = warnPromptVars(prompt)
 	if !strings.ContainsRune(prompt, rune(refSigil)) {
 		return prompt
 	}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/audit"
+	"github.com/jack-work/figaro/internal/config"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+)
+
+// The audit log is the daemon's hash-chained record of every provider
+// request, prompt, tool call and answer, with the guard's decision on each.
+// It is written only while config [audit] enabled is set, and read back by
+// `figaro audit show|verify` straight from the file.
+
+// auditLog is the daemon's open audit log; nil when auditing is off.
+var auditLog *audit.Log
+
+func auditPath(a config.Audit) string {
+	if a.Path != "" {
+		return a.Path
+	}
+	return filepath.Join(stateDir(), "audit.jsonl")
+}
+
+// applyAudit opens the log and adds request recording to the provider
+// chain. Call it after applyMiddleware: the recorder goes innermost, so
+// request durations leave out rate-limit waits.
+func applyAudit(a config.Audit) error {
+	auditLog = nil
+	if !a.Enabled {
+		return nil
+	}
+	path := auditPath(a)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	l, err := audit.Open(path)
+	if err != nil {
+		return err
+	}
+	auditLog = l
+	providerMiddleware = append(providerMiddleware, auditRequests)
+	return nil
+}
+
+// recordAudit appends e when auditing is on. A failed write is logged; it
+// does not stop the turn.
+func recordAudit(e audit.Entry) {
+	if auditLog == nil {
+		return
+	}
+	if err := auditLog.Append(e); err != nil {
+		slog.Error("audit: append", "kind", e.Kind, "aria", e.Aria, "err", err)
+	}
+}
+
+// auditRequests records each provider Send once it returns.
+func auditRequests(p providerPkg.Provider) providerPkg.Provider {
+	name := p.Name()
+	return providerPkg.WrapSend(func(send providerPkg.SendFunc) providerPkg.SendFunc {
+		return func(ctx context.Context, in providerPkg.SendInput, bus providerPkg.Bus) error {
+			start := time.Now()
+			err := send(ctx, in, bus)
+			e := audit.Entry{
+				Kind:       audit.KindRequest,
+				Aria:       in.AriaID,
+				Provider:   name,
+				DurationMS: time.Since(start).Milliseconds(),
+				Decision:   "ok",
+			}
+			if raw, ok := in.Snapshot["system.model"]; ok {
+				_ = json.Unmarshal(raw, &e.Model)
+			}
+			if err != nil {
+				e.Decision, e.Error = "error", err.Error()
+			}
+			recordAudit(e)
+			return err
+		}
+	})(p)
+}
+
+// runAuditCmd dispatches `figaro audit <action>`.
+func runAuditCmd(loaded *config.Loaded, rawArgs []string) {
+	if len(rawArgs) == 0 {
+		die("usage: figaro audit show|verify ...")
+	}
+	path := auditPath(loaded.Config.Audit)
+	action, args := rawArgs[0], rawArgs[1:]
+	switch action {
+	case "show":
+		runAuditShow(path, args)
+	case "verify":
+		runAuditVerify(path)
+	default:
+		die("audit: unknown action %q (want show or verify)", action)
+	}
+}
+
+func openAuditFile(path string) *os.File {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			die("audit: no log at %s (set [audit] enabled = true and restart the daemon)", path)
+		}
+		die("audit: %s", err)
+	}
+	return f
+}
+
+func runAuditShow(path string, args []string) {
+	limit := 50
+	if v, ok, err := preDashFlagValue(args, "-n"); err != nil {
+		die("audit show: %s", err)
+	} else if ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			die("audit show: -n %q: want a count (0 = all)", v)
+		}
+		limit = n
+	}
+	aria, _, err := preDashFlagValue(args, "--aria", "-a")
+	if err != nil {
+		die("audit show: %s", err)
+	}
+	kind, _, err := preDashFlagValue(args, "--kind")
+	if err != nil {
+		die("audit show: %s", err)
+	}
+	f := openAuditFile(path)
+	defer f.Close()
+	var entries []audit.Entry
+	err = audit.Scan(f, func(e audit.Entry) error {
+		if (aria == "" || e.Aria == aria) && (kind == "" || e.Kind == kind) {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		die("audit show: %s", err)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	if hasPreDashFlag(args, "--json", "-j") {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			enc.Encode(e)
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEQ\tTIME\tKIND\tARIA\tDECISION\tDETAIL")
+	for _, e := range entries {
+		t := e.Time
+		if ts, err := time.Parse(time.RFC3339Nano, e.Time); err == nil {
+			t = ts.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", e.Seq, t, e.Kind, e.Aria, e.Decision, auditDetail(e))
+	}
+	w.Flush()
+}
+
+// auditDetail is the DETAIL column: what the entry was about.
+func auditDetail(e audit.Entry) string {
+	var parts []string
+	switch e.Kind {
+	case audit.KindRequest:
+		parts = append(parts, strings.TrimSuffix(e.Provider+"/"+e.Model, "/"), fmt.Sprintf("%dms", e.DurationMS))
+	case audit.KindTool:
+		parts = append(parts, e.Tool)
+	}
+	if len(e.Rules) > 0 {
+		parts = append(parts, "rules="+strings.Join(e.Rules, ","))
+	}
+	if e.Error != "" {
+		parts = append(parts, taskSummary(e.Error, 60))
+	}
+	return strings.Join(parts, " ")
+}
+
+func runAuditVerify(path string) {
+	f := openAuditFile(path)
+	defer f.Close()
+	last, err := audit.Verify(f)
+	if err != nil {
+		die("audit verify: %s: chain broken after %d good entries: %s", path, last.Seq, err)
+	}
+	fmt.Printf("%s: %d entries, chain intact\nlast hash %s\n", path, last.Seq, last.Hash)
+}
@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+// backupFormat versions the bundle layout; restore refuses newer ones.
+const (
+	backupFormat       = 1
+	backupManifestName = "figaro-backup.json"
+)
+
+// backupManifest is the bundle's first entry.
+type backupManifest struct {
+	Format      int       `json:"format"`
+	Created     time.Time `json:"created"`
+	Credentials bool      `json:"credentials"`
+}
+
+// backupRoot is a directory a bundle carries under the Name/ prefix.
+// Restore replaces whole top-level entries of Dir (a loadouts directory,
+// the aria store), never merges into them.
+type backupRoot struct {
+	Name string
+	Dir  string
+	// Only limits the root to these top-level entries; nil takes all.
+	Only []string
+	Skip []string
+}
+
+// stateEntries is the part of the state dir worth moving: bookmarks and
+// the task, schedule, batch, spend, audit and share records. OTel output
+// and the runtime dir are left behind.
+var stateEntries = []string{"tasks", "schedules", "batches", "usage.json", "audit.jsonl", "shares.json", "bookmarks.json"}
+
+// dataEntries is the part of the data dir worth moving: the aria store,
+// its archive and the scratch one-offs.
+var dataEntries = []string{"arias", "archive", "scratch"}
+
+// movedEntry maps a bundle or mirror path written before the aria store
+// left the state dir onto its current root.
+func movedEntry(name string) string {
+	if name == "state/arias" || strings.HasPrefix(name, "state/arias/") {
+		return "data/" + strings.TrimPrefix(name, "state/")
+	}
+	return name
+}
+
+// backupRoots covers the config dir (config.toml, loadouts, chalkboard
+// templates, themes), the state dir and the aria store. Provider
+// credentials are encrypted to this machine's key, so they travel only on
+// request.
+func backupRoots(loaded *config.Loaded, credentials bool) []backupRoot {
+	cfg := backupRoot{Name: "config", Dir: loaded.ConfigDir}
+	if !credentials {
+		cfg.Skip = []string{"providers", "identity"}
+	}
+	return []backupRoot{
+		cfg,
+		{Name: "state", Dir: stateDir(), Only: stateEntries},
+		{Name: "data", Dir: filepath.Dir(ariaDir()), Only: dataEntries},
+	}
+}
+
+func defaultBackupName(now time.Time) string {
+	return "figaro-backup-" + now.Format("20060102-150405") + ".tar.gz"
+}
+
+func runBackupCreate(loaded *config.Loaded, file string, credentials bool) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	if file == "" {
+		file = defaultBackupName(time.Now())
+	}
+	gz, err := backupCompression(file)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	n, err := writeBundle(f, backupRoots(loaded, credentials), gz, backupManifest{
+		Format: backupFormat, Created: time.Now().UTC(), Credentials: credentials,
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	fmt.Printf("wrote %s (%d files)\n", file, n)
+	if !credentials {
+		fmt.Println("provider credentials not included; log in again after restoring (or pass --credentials)")
+	}
+	return nil
+}
+
+func runBackupRestore(loaded *config.Loaded, file string, replace bool) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	replaced, err := restoreBundle(f, backupRoots(loaded, true), replace)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %s\n", file)
+	if len(replaced) > 0 {
+		fmt.Printf("replaced: %s\n", strings.Join(replaced, ", "))
+	}
+	return nil
+}
+
+// backupCompression reports whether file should be gzipped, by name.
+func backupCompression(file string) (bool, error) {
+	switch {
+	case strings.HasSuffix(file, ".tar.gz"), strings.HasSuffix(file, ".tgz"):
+		return true, nil
+	case strings.HasSuffix(file, ".tar"):
+		return false, nil
+	}
+	return false, fmt.Errorf("%s: bundles are .tar.gz, .tgz or .tar", file)
+}
+
+// writeBundle writes the manifest and then every regular file and
+// directory under roots. It returns the number of files written.
+func writeBundle(w io.Writer, roots []backupRoot, gz bool, man backupManifest) (int, error) {
+	var zw *gzip.Writer
+	if gz {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+	tw := tar.NewWriter(w)
+	raw, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0o600, Size: int64(len(raw)), ModTime: man.Created}); err != nil {
+		return 0, err
+	}
+	if _, err := tw.Write(raw); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = walkRoots(roots, func(name, p string, d fs.DirEntry) error {
+		wrote, err := addToBundle(tw, p, name, d)
+		if wrote {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		return n, err
+	}
+	if err := tw.Close(); err != nil {
+		return n, err
+	}
+	if zw != nil {
+		return n, zw.Close()
+	}
+	return n, nil
+}
+
+// walkRoots calls fn for every directory and regular file the roots
+// take, with its root/rel slash name.
+func walkRoots(roots []backupRoot, fn func(name, p string, d fs.DirEntry) error) error {
+	for _, root := range roots {
+		entries, err := os.ReadDir(root.Dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if slices.Contains(root.Skip, e.Name()) || (root.Only != nil && !slices.Contains(root.Only, e.Name())) {
+				continue
+			}
+			err := filepath.WalkDir(filepath.Join(root.Dir, e.Name()), func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && !d.Type().IsRegular() {
+					return nil
+				}
+				rel, err := filepath.Rel(root.Dir, p)
+				if err != nil {
+					return err
+				}
+				return fn(path.Join(root.Name, filepath.ToSlash(rel)), p, d)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addToBundle(tw *tar.Writer, p, name string, d fs.DirEntry) (bool, error) {
+	info, err := d.Info()
+	if err != nil {
+		return false, err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return false, err
+	}
+	hdr.Name = name
+	if d.IsDir() {
+		hdr.Name += "/"
+		return false, tw.WriteHeader(hdr)
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if err := tw.WriteHeader(hdr); err != nil {
+		return false, err
+	}
+	if _, err := io.CopyN(tw, f, hdr.Size); err != nil {
+		return false, fmt.Errorf("%s: %w", p, err)
+	}
+	return true, nil
+}
+
+// restoreBundle unpacks into a staging directory beside each root, then
+// swaps the staged top-level entries in. Entries that already exist are
+// replaced only with replace set; otherwise nothing is touched. It
+// returns the replaced entries as root/name.
+func restoreBundle(r io.Reader, roots []backupRoot, replace bool) ([]string, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != backupManifestName {
+		return nil, fmt.Errorf("not a figaro backup (no %s)", backupManifestName)
+	}
+	var man backupManifest
+	if err := json.NewDecoder(tr).Decode(&man); err != nil {
+		return nil, fmt.Errorf("%s: %w", backupManifestName, err)
+	}
+	if man.Format > backupFormat {
+		return nil, fmt.Errorf("backup format %d is newer than this figaro reads (%d); update figaro", man.Format, backupFormat)
+	}
+
+	staging := map[string]string{}
+	defer func() {
+		for _, dir := range staging {
+			os.RemoveAll(dir)
+		}
+	}()
+	dirOf := map[string]string{}
+	for _, root := range roots {
+		dirOf[root.Name] = root.Dir
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rootName, rel, _ := strings.Cut(movedEntry(strings.TrimSuffix(hdr.Name, "/")), "/")
+		dir, ok := dirOf[rootName]
+		if !ok || rel == "" || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil, fmt.Errorf("unexpected entry %q", hdr.Name)
+		}
+		stage, ok := staging[rootName]
+		if !ok {
+			if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+				return nil, err
+			}
+			if stage, err = os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".restore-"); err != nil {
+				return nil, err
+			}
+			staging[rootName] = stage
+		}
+		if err := extractEntry(tr, hdr, filepath.Join(stage, filepath.FromSlash(rel))); err != nil {
+			return nil, err
+		}
+	}
+
+	var existing []string
+	for _, root := range roots {
+		stage, ok := staging[root.Name]
+		if !ok {
+			continue
+		}
+		entries, err := os.ReadDir(stage)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if _, err := os.Lstat(filepath.Join(root.Dir, e.Name())); err == nil {
+				existing = append(existing, root.Name+"/"+e.Name())
+			}
+		}
+	}
+	if len(existing) > 0 && !replace {
+		return nil, fmt.Errorf("would replace %s; pass --replace to overwrite them", strings.Join(existing, ", "))
+	}
+
+	for _, root := range roots {
+		stage, ok := staging[root.Name]
+		if !ok {
+			continue
+		}
+		if err := os.MkdirAll(root.Dir, 0o700); err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(stage)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			dst := filepath.Join(root.Dir, e.Name())
+			if err := os.RemoveAll(dst); err != nil {
+				return nil, err
+			}
+			if err := os.Rename(filepath.Join(stage, e.Name()), dst); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return existing, nil
+}
+
+func extractEntry(tr *tar.Reader, hdr *tar.Header, dst string) error {
+	mode := hdr.FileInfo().Mode().Perm()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dst, mode|0o700)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode|0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(dst, hdr.ModTime, hdr.ModTime)
+	}
+	return fmt.Errorf("unexpected entry type in %q", hdr.Name)
+}
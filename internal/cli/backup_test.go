@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o700)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, p string) string {
+	t.Helper()
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func testRoots(base string) []backupRoot {
+	return []backupRoot{
+		{Name: "config", Dir: filepath.Join(base, "config"), Skip: []string{"providers"}},
+		{Name: "state", Dir: filepath.Join(base, "state"), Only: stateEntries},
+		{Name: "data", Dir: filepath.Join(base, "data"), Only: dataEntries},
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, filepath.Join(src, "config"), map[string]string{
+		"config.toml":        "default_loadout = \"main\"\n",
+		"loadouts/main.toml": "system = {}\n",
+		"providers/x.toml":   "api_key = \"secret\"\n",
+	})
+	writeTree(t, filepath.Join(src, "data"), map[string]string{
+		"arias/xwal.json": "{}",
+	})
+	writeTree(t, filepath.Join(src, "state"), map[string]string{
+		"usage.json":       "{}",
+		"traces.jsonl":     "telemetry",
+		"tasks/t1/meta.js": "task",
+	})
+	for _, gz := range []bool{true, false} {
+		var buf bytes.Buffer
+		n, err := writeBundle(&buf, testRoots(src), gz, backupManifest{Format: backupFormat, Created: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 5 {
+			t.Errorf("gz=%v: %d files, want 5", gz, n)
+		}
+
+		dst := t.TempDir()
+		replaced, err := restoreBundle(&buf, testRoots(dst), false)
+		if err != nil || len(replaced) != 0 {
+			t.Fatalf("gz=%v: restore: %v %v", gz, replaced, err)
+		}
+		if got := readFile(t, filepath.Join(dst, "config", "loadouts", "main.toml")); got != "system = {}\n" {
+			t.Errorf("loadout = %q", got)
+		}
+		if got := readFile(t, filepath.Join(dst, "state", "tasks", "t1", "meta.js")); got != "task" {
+			t.Errorf("task = %q", got)
+		}
+		for _, left := range []string{"config/providers", "state/traces.jsonl"} {
+			if _, err := os.Stat(filepath.Join(dst, left)); err == nil {
+				t.Errorf("%s was bundled", left)
+			}
+		}
+		if entries, _ := os.ReadDir(dst); len(entries) != 3 {
+			t.Errorf("staging left behind: %v", entries)
+		}
+	}
+}
+
+func TestBackupRestoreReplace(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, filepath.Join(src, "config"), map[string]string{"loadouts/main.toml": "new"})
+	var buf bytes.Buffer
+	if _, err := writeBundle(&buf, testRoots(src), true, backupManifest{Format: backupFormat}); err != nil {
+		t.Fatal(err)
+	}
+	bundle := buf.Bytes()
+
+	dst := t.TempDir()
+	writeTree(t, filepath.Join(dst, "config"), map[string]string{
+		"loadouts/main.toml":  "old",
+		"loadouts/extra.toml": "extra",
+		"config.toml":         "kept",
+	})
+	_, err := restoreBundle(bytes.NewReader(bundle), testRoots(dst), false)
+	if err == nil || !strings.Contains(err.Error(), "config/loadouts") {
+		t.Fatalf("conflict: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dst, "config", "loadouts", "main.toml")); got != "old" {
+		t.Errorf("refused restore touched %q", got)
+	}
+
+	replaced, err := restoreBundle(bytes.NewReader(bundle), testRoots(dst), true)
+	if err != nil || len(replaced) != 1 {
+		t.Fatalf("replace: %v %v", replaced, err)
+	}
+	if got := readFile(t, filepath.Join(dst, "config", "loadouts", "main.toml")); got != "new" {
+		t.Errorf("main = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "config", "loadouts", "extra.toml")); err == nil {
+		t.Error("replaced entry was merged, not swapped")
+	}
+	if got := readFile(t, filepath.Join(dst, "config", "config.toml")); got != "kept" {
+		t.Errorf("entry outside the bundle = %q", got)
+	}
+}
+
+func TestBackupRestoreRejects(t *testing.T) {
+	bundle := func(man backupManifest, name string) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		raw, _ := json.Marshal(man)
+		tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0o600, Size: int64(len(raw))})
+		tw.Write(raw)
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 1})
+		tw.Write([]byte("x"))
+		tw.Close()
+		return &buf
+	}
+	for name, tc := range map[string]struct {
+		bundle *bytes.Buffer
+		want   string
+	}{
+		"escape":      {bundle(backupManifest{Format: 1}, "config/../../evil"), "unexpected entry"},
+		"root":        {bundle(backupManifest{Format: 1}, "home/x"), "unexpected entry"},
+		"newer":       {bundle(backupManifest{Format: backupFormat + 1}, "config/x"), "update figaro"},
+		"no manifest": {bytes.NewBufferString("plain text"), "not a figaro backup"},
+	} {
+		dst := t.TempDir()
+		if _, err := restoreBundle(tc.bundle, testRoots(dst), true); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: %v", name, err)
+		}
+		if entries, _ := os.ReadDir(dst); len(entries) != 0 {
+			t.Errorf("%s: wrote %v", name, entries)
+		}
+	}
+}
+
+func TestBackupCompression(t *testing.T) {
+	for file, want := range map[string]bool{"b.tar.gz": true, "b.tgz": true, "b.tar": false} {
+		if gz, err := backupCompression(file); err != nil || gz != want {
+			t.Errorf("%s: %v %v", file, gz, err)
+		}
+	}
+	if _, err := backupCompression("bundle.tar.zst"); err == nil {
+		t.Error("zst accepted")
+	}
+}
@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/outfit"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// A batch is a file of prompts sent through the provider's batch API: no
+// aria runs while it is out, and once it has ended `figaro batch fetch`
+// turns each answer into its own dormant conversation. The CLI keeps one
+// record per batch under <state>/batches; the daemon is only involved at
+// fetch time, to write the conversations.
+
+// batchRecord is one submitted batch.
+type batchRecord struct {
+	ID          string                     `json:"id"`
+	Provider    string                     `json:"provider"`
+	Loadout     string                     `json:"loadout"`
+	Model       string                     `json:"model,omitempty"`
+	Prompts     []providerPkg.BatchRequest `json:"prompts"`
+	SubmittedAt int64                      `json:"submitted_at"`     // unix millis
+	Arias       map[string]string          `json:"arias,omitempty"`  // prompt id -> imported aria
+	Failed      map[string]string          `json:"failed,omitempty"` // prompt id -> error
+}
+
+var batchIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+func batchDir() string { return filepath.Join(stateDir(), "batches") }
+
+// saveBatch writes b atomically.
+func saveBatch(dir string, b batchRecord) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "."+b.ID+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, b.ID+".json"))
+}
+
+func loadBatch(dir, id string) (batchRecord, error) {
+	var b batchRecord
+	if !batchIDPattern.MatchString(id) {
+		return b, fmt.Errorf("bad batch id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return b, fmt.Errorf("no batch %s (figaro batch status)", id)
+		}
+		return b, err
+	}
+	return b, json.Unmarshal(data, &b)
+}
+
+// loadBatches reads every record, oldest first. Unreadable records are
+// skipped.
+func loadBatches(dir string) ([]batchRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []batchRecord
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var b batchRecord
+		if json.Unmarshal(data, &b) == nil && b.ID != "" {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SubmittedAt < out[j].SubmittedAt })
+	return out, nil
+}
+
+// readBatchPrompts parses the JSONL prompt file: one object per line with
+// "prompt" and optional "id", "model" and "max_tokens". Blank lines are
+// skipped; a missing id becomes p<line>.
+func readBatchPrompts(r io.Reader) ([]providerPkg.BatchRequest, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	seen := map[string]int{}
+	var out []providerPkg.BatchRequest
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var req providerPkg.BatchRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if strings.TrimSpace(req.Prompt) == "" {
+			return nil, fmt.Errorf("line %d: no prompt", line)
+		}
+		if req.ID == "" {
+			req.ID = fmt.Sprintf("p%d", line)
+		}
+		if !batchIDPattern.MatchString(req.ID) {
+			return nil, fmt.Errorf("line %d: id %q: want 1-64 letters, digits, _ or -", line, req.ID)
+		}
+		if prev, ok := seen[req.ID]; ok {
+			return nil, fmt.Errorf("line %d: id %q already used on line %d", line, req.ID, prev)
+		}
+		seen[req.ID] = line
+		out = append(out, req)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no prompts")
+	}
+	return out, nil
+}
+
+// runBatchCmd dispatches `figaro batch <action>`.
+func runBatchCmd(loaded *config.Loaded, rawArgs []string) {
+	if len(rawArgs) == 0 {
+		die("usage: figaro batch submit|status|fetch ...")
+	}
+	action, args := rawArgs[0], rawArgs[1:]
+	switch action {
+	case "submit":
+		runBatchSubmit(loaded, args)
+	case "status":
+		runBatchStatus(loaded, args)
+	case "fetch":
+		if len(args) != 1 {
+			die("usage: figaro batch fetch <id>")
+		}
+		runBatchFetch(loaded, args[0])
+	default:
+		die("batch: unknown action %q (want submit, status or fetch)", action)
+	}
+}
+
+// batchProvider builds the loadout's provider and checks it can batch.
+func batchProvider(loaded *config.Loaded, loadout, name string) providerPkg.BatchProvider {
+	p, _ := buildProviderKnobs(loaded, name, readLoadoutKnobs(loaded, loadout))
+	if p == nil {
+		die("batch: cannot build provider %q (figaro login %s?)", name, name)
+	}
+	bp, ok := p.(providerPkg.BatchProvider)
+	if !ok {
+		die("batch: provider %q has no batch API", name)
+	}
+	return bp
+}
+
+func runBatchSubmit(loaded *config.Loaded, args []string) {
+	const usage = "usage: figaro batch submit [-L <loadout>] [--model <model>] <prompts.jsonl | ->"
+	if len(args) == 0 {
+		die(usage)
+	}
+	path := args[len(args)-1]
+	if path != "-" && strings.HasPrefix(path, "-") {
+		die(usage)
+	}
+	loadout, _, err := preDashFlagValue(args, "--loadout", "-L")
+	if err != nil {
+		die("batch submit: %s", err)
+	}
+	model, _, err := preDashFlagValue(args, "--model")
+	if err != nil {
+		die("batch submit: %s", err)
+	}
+	if loadout == "" {
+		loadout = loaded.Config.DefaultLoadout
+	}
+	if loadout == "" {
+		die("batch submit: no loadout (pass -L or set default_loadout)")
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			die("batch submit: %s", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	reqs, err := readBatchPrompts(in)
+	if err != nil {
+		die("batch submit: %s: %s", path, err)
+	}
+
+	patch, err := outfit.New(loaded.ConfigDir).Load(loadout)
+	if err != nil {
+		die("batch submit: loadout %s: %s", loadout, err)
+	}
+	snap := chalkboard.Snapshot{}
+	for k, v := range patch.Set {
+		snap[k] = v
+	}
+	if model != "" {
+		snap["system.model"], _ = json.Marshal(model)
+	}
+	var provName string
+	_ = json.Unmarshal(snap["system.provider"], &provName)
+	if provName == "" {
+		die("batch submit: loadout %s names no system.provider", loadout)
+	}
+	bp := batchProvider(loaded, loadout, provName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	st, err := bp.SubmitBatch(ctx, snap, reqs)
+	if err != nil {
+		die("batch submit: %s", err)
+	}
+	b := batchRecord{
+		ID:          st.ID,
+		Provider:    provName,
+		Loadout:     loadout,
+		Model:       model,
+		Prompts:     reqs,
+		SubmittedAt: time.Now().UnixMilli(),
+	}
+	if err := saveBatch(batchDir(), b); err != nil {
+		die("batch submit: batch %s was submitted but not recorded: %s", st.ID, err)
+	}
+	fmt.Println(st.ID)
+	fmt.Fprintf(os.Stderr, "submitted %d prompts — check with figaro batch status %s\n", len(reqs), st.ID)
+}
+
+// runBatchStatus prints one batch's progress, or every recorded batch's.
+func runBatchStatus(loaded *config.Loaded, args []string) {
+	asJSON := hasPreDashFlag(args, "--json", "-j")
+	var all []batchRecord
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			b, err := loadBatch(batchDir(), a)
+			if err != nil {
+				die("batch status: %s", err)
+			}
+			all = append(all, b)
+		}
+	}
+	if len(all) == 0 {
+		var err error
+		if all, err = loadBatches(batchDir()); err != nil {
+			die("batch status: %s", err)
+		}
+	}
+	if len(all) == 0 {
+		fmt.Fprintln(os.Stderr, "no batches (figaro batch submit prompts.jsonl)")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	type row struct {
+		batchRecord
+		State    string `json:"state"`
+		Progress string `json:"progress"`
+	}
+	rows := make([]row, 0, len(all))
+	for _, b := range all {
+		r := row{batchRecord: b}
+		if len(b.Arias)+len(b.Failed) == len(b.Prompts) {
+			r.State = "fetched"
+		} else if st, err := batchProvider(loaded, b.Loadout, b.Provider).BatchStatus(ctx, b.ID); err != nil {
+			r.State = "error: " + taskSummary(err.Error(), 60)
+		} else {
+			r.State = st.State
+			r.Progress = fmt.Sprintf("%d ok, %d failed, %d pending", st.Succeeded, st.Errored+st.Canceled+st.Expired, st.Processing)
+			if st.Ended {
+				r.State = "ended (figaro batch fetch " + b.ID + ")"
+			}
+		}
+		rows = append(rows, r)
+	}
+	if asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(rows)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSUBMITTED\tPROMPTS\tPROGRESS\tSTATE")
+	for _, r := range rows {
+		submitted := time.UnixMilli(r.SubmittedAt).Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.ID, submitted, len(r.Prompts), r.Progress, r.State)
+	}
+	w.Flush()
+}
+
+// runBatchFetch imports each answer of an ended batch into a conversation
+// of its own: the prompt as the user turn, the answer as the reply. The
+// record notes every import as it goes, so a fetch that stops part way
+// can simply be run again.
+func runBatchFetch(loaded *config.Loaded, id string) {
+	b, err := loadBatch(batchDir(), id)
+	if err != nil {
+		die("batch fetch: %s", err)
+	}
+	bp := batchProvider(loaded, b.Loadout, b.Provider)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	st, err := bp.BatchStatus(ctx, b.ID)
+	if err != nil {
+		die("batch fetch: %s", err)
+	}
+	if !st.Ended {
+		die("batch fetch: batch %s is %s (%d of %d done); try again later",
+			b.ID, st.State, len(b.Prompts)-st.Processing, len(b.Prompts))
+	}
+	results, err := bp.BatchResults(ctx, b.ID)
+	if err != nil {
+		die("batch fetch: %s", err)
+	}
+	prompts := map[string]providerPkg.BatchRequest{}
+	for _, p := range b.Prompts {
+		prompts[p.ID] = p
+	}
+	if b.Arias == nil {
+		b.Arias = map[string]string{}
+	}
+	if b.Failed == nil {
+		b.Failed = map[string]string{}
+	}
+
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT\tARIA")
+	for _, r := range results {
+		p, ok := prompts[r.ID]
+		if !ok || b.Arias[r.ID] != "" {
+			continue
+		}
+		if r.Error != "" {
+			b.Failed[r.ID] = r.Error
+			fmt.Fprintf(w, "%s\tfailed: %s\n", r.ID, taskSummary(r.Error, 60))
+			continue
+		}
+		aria, err := importBatchResult(ctx, acli, b, p, r)
+		if err != nil {
+			w.Flush()
+			saveBatch(batchDir(), b)
+			die("batch fetch: %s: %s", r.ID, err)
+		}
+		b.Arias[r.ID] = aria
+		delete(b.Failed, r.ID)
+		if err := saveBatch(batchDir(), b); err != nil {
+			die("batch fetch: record %s: %s", b.ID, err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", r.ID, aria)
+	}
+	w.Flush()
+	if err := saveBatch(batchDir(), b); err != nil {
+		die("batch fetch: record %s: %s", b.ID, err)
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d prompts answered; read one with figaro show <aria>, continue it with figaro attend <aria>\n", len(b.Arias), len(b.Prompts))
+}
+
+type batchImporter interface {
+	Import(ctx context.Context, loadout string, patch *rpc.ChalkboardPatch, msgs []message.Message) (*rpc.ImportResponse, error)
+}
+
+func importBatchResult(ctx context.Context, acli batchImporter, b batchRecord, p providerPkg.BatchRequest, r providerPkg.BatchResult) (string, error) {
+	var patch *rpc.ChalkboardPatch
+	model := r.Model
+	if model == "" {
+		model = p.Model
+	}
+	if model == "" {
+		model = b.Model
+	}
+	if model != "" {
+		raw, _ := json.Marshal(model)
+		patch = &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.model": raw}}
+	}
+	reply := r.Message
+	reply.Role = message.RoleAssistant
+	resp, err := acli.Import(ctx, b.Loadout, patch, []message.Message{
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent(p.Prompt)}},
+		reply,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.FigaroID, nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestReadBatchPrompts(t *testing.T) {
+	reqs, err := readBatchPrompts(strings.NewReader(`{"prompt":"one"}
+
+{"id":"two","prompt":"second","model":"m2","max_tokens":50}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 2 || reqs[0].ID != "p1" || reqs[1].ID != "two" || reqs[1].Model != "m2" || reqs[1].MaxTokens != 50 {
+		t.Fatalf("reqs = %+v", reqs)
+	}
+
+	bad := map[string]string{
+		"no prompt": `{"id":"a"}`,
+		"bad id":    `{"id":"a b","prompt":"x"}`,
+		"duplicate": "{\"id\":\"a\",\"prompt\":\"x\"}\n{\"id\":\"a\",\"prompt\":\"y\"}",
+		"not json":  `prompt: x`,
+		"empty":     "\n\n",
+	}
+	for name, in := range bad {
+		if _, err := readBatchPrompts(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestBatchRecordRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	b := batchRecord{ID: "msgbatch_1", Provider: "anthropic", Loadout: "default", SubmittedAt: 2,
+		Prompts: []providerPkg.BatchRequest{{ID: "p1", Prompt: "hi"}}, Arias: map[string]string{"p1": "a1"}}
+	if err := saveBatch(dir, b); err != nil {
+		t.Fatal(err)
+	}
+	saveBatch(dir, batchRecord{ID: "msgbatch_0", SubmittedAt: 1})
+	got, err := loadBatch(dir, "msgbatch_1")
+	if err != nil || got.Arias["p1"] != "a1" || got.Prompts[0].Prompt != "hi" {
+		t.Fatalf("loadBatch = %+v, %v", got, err)
+	}
+	if _, err := loadBatch(dir, "../x"); err == nil {
+		t.Error("loadBatch accepted a path")
+	}
+	all, _ := loadBatches(dir)
+	if len(all) != 2 || all[0].ID != "msgbatch_0" {
+		t.Fatalf("loadBatches = %+v", all)
+	}
+}
+
+type recordingImporter struct {
+	loadout string
+	patch   *rpc.ChalkboardPatch
+	msgs    []message.Message
+}
+
+func (r *recordingImporter) Import(_ context.Context, loadout string, patch *rpc.ChalkboardPatch, msgs []message.Message) (*rpc.ImportResponse, error) {
+	r.loadout, r.patch, r.msgs = loadout, patch, msgs
+	return &rpc.ImportResponse{FigaroID: "a1"}, nil
+}
+
+func TestImportBatchResult(t *testing.T) {
+	imp := &recordingImporter{}
+	b := batchRecord{Loadout: "work", Model: "fallback"}
+	p := providerPkg.BatchRequest{ID: "p1", Prompt: "2+2?"}
+	r := providerPkg.BatchResult{ID: "p1", Model: "claude-x", Message: message.Message{
+		Content: []message.Content{message.TextContent("4")}, StopReason: message.StopEnd,
+	}}
+	aria, err := importBatchResult(context.Background(), imp, b, p, r)
+	if err != nil || aria != "a1" {
+		t.Fatalf("importBatchResult = %q, %v", aria, err)
+	}
+	if imp.loadout != "work" || len(imp.msgs) != 2 {
+		t.Fatalf("import = %+v", imp)
+	}
+	if imp.msgs[0].Role != message.RoleUser || imp.msgs[0].Content[0].Text != "2+2?" {
+		t.Errorf("user turn = %+v", imp.msgs[0])
+	}
+	if imp.msgs[1].Role != message.RoleAssistant || imp.msgs[1].Content[0].Text != "4" {
+		t.Errorf("reply = %+v", imp.msgs[1])
+	}
+	var model string
+	json.Unmarshal(imp.patch.Set["system.model"], &model)
+	if model != "claude-x" {
+		t.Errorf("system.model = %q, want the model that answered", model)
+	}
+}
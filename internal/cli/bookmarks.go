@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/bookmark"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/term"
+	"github.com/jack-work/figaro/internal/transport"
+	"github.com/jack-work/figaro/internal/tui"
+)
+
+// boundAria is the aria bound to this shell, or "" when there is none or
+// the angelus is not running.
+func boundAria() string {
+	acli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath()))
+	if err != nil {
+		return ""
+	}
+	defer acli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, err := resolveBinding(ctx, acli, os.Getppid())
+	if err != nil || !r.Found {
+		return ""
+	}
+	return r.FigaroID
+}
+
+// bookmarkTarget reads [<id>]:<LT>; a bare :<LT> names the bound aria.
+func bookmarkTarget(spec string) (string, int, error) {
+	id, lt, hasLT, err := parseSendTarget(spec)
+	if err != nil {
+		return "", 0, err
+	}
+	if !hasLT || lt < 1 {
+		return "", 0, fmt.Errorf("bad bookmark %q (want [<id>]:<LT>)", spec)
+	}
+	if id == "" {
+		if id = boundAria(); id == "" {
+			return "", 0, fmt.Errorf("no aria bound to this shell (use <id>:<LT>)")
+		}
+	}
+	return id, int(lt), nil
+}
+
+func runBookmarksAdd(spec, note string) error {
+	id, lt, err := bookmarkTarget(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := bookmarkStore().Set(id, lt, note); err != nil {
+		return err
+	}
+	fmt.Printf("bookmarked %s:%d\n", id, lt)
+	return nil
+}
+
+func runBookmarksRemove(spec string) error {
+	id, lt, err := bookmarkTarget(spec)
+	if err != nil {
+		return err
+	}
+	if _, err := bookmarkStore().Remove(id, lt); errors.Is(err, bookmark.ErrNoMark) {
+		return fmt.Errorf("no bookmark at %s:%d", id, lt)
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("removed %s:%d\n", id, lt)
+	return nil
+}
+
+// runBookmarksList lists id's bookmarks (default the bound aria; every
+// aria's with all or no binding). In a terminal it is a picker, and the
+// pick opens the transcript on the marked message.
+func runBookmarksList(loaded *config.Loaded, id string, all bool) error {
+	if id == "" && !all {
+		id = boundAria()
+	}
+	marks, err := bookmarkStore().List(id)
+	if err != nil {
+		return err
+	}
+	if len(marks) == 0 {
+		fmt.Println("no bookmarks")
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		for _, m := range marks {
+			fmt.Println(bookmarkLine(m))
+		}
+		return nil
+	}
+	picked, err := tui.PickProvider("Jump to a bookmark", bookmarkOptions(marks))
+	if err != nil {
+		return err
+	}
+	target, ltStr, _ := strings.Cut(picked, ":")
+	lt, _ := strconv.Atoi(ltStr)
+	runListen(loaded, target, lt)
+	return nil
+}
+
+func bookmarkLine(m bookmark.Mark) string {
+	return strings.TrimRight(fmt.Sprintf("%-16s  %s  %s", fmt.Sprintf("%s:%d", m.Aria, m.LT),
+		m.Created.Local().Format("2006-01-02 15:04"), m.Note), " ")
+}
+
+// bookmarkOptions turns bookmarks into picker rows keyed <id>:<LT>.
+func bookmarkOptions(marks []bookmark.Mark) []tui.ProviderOption {
+	opts := make([]tui.ProviderOption, len(marks))
+	for i, m := range marks {
+		label := m.Note
+		if label == "" {
+			label = "(bookmark)"
+		}
+		key := fmt.Sprintf("%s:%d", m.Aria, m.LT)
+		opts[i] = tui.ProviderOption{Key: key, Label: firstRunes(label, 60), Hint: key + " · " + relAge(m.Created.UnixMilli()) + " ago"}
+	}
+	return opts
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/bookmark"
+)
+
+func TestBookmarkOptions(t *testing.T) {
+	marks := []bookmark.Mark{
+		{Aria: "aria1", LT: 12, Note: "the fix", Created: time.Now()},
+		{Aria: "aria2", LT: 3, Created: time.Now()},
+	}
+	opts := bookmarkOptions(marks)
+	if len(opts) != 2 || opts[0].Key != "aria1:12" || opts[0].Label != "the fix" || opts[1].Label != "(bookmark)" {
+		t.Fatalf("options = %+v", opts)
+	}
+	if got := bookmarkLine(marks[1]); got != "aria2:3           "+marks[1].Created.Local().Format("2006-01-02 15:04") {
+		t.Errorf("line = %q", got)
+	}
+	if _, _, err := bookmarkTarget("aria1"); err == nil {
+		t.Error("a target without :<LT> was accepted")
+	}
+	if id, lt, err := bookmarkTarget("aria1:7"); err != nil || id != "aria1" || lt != 7 {
+		t.Errorf("target = %q %d %v", id, lt, err)
+	}
+}
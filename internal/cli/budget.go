@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/budget"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+)
+
+// The daemon books every provider response's usage into a per-day ledger
+// and, when config [budget] sets a cap, refuses new prompts once spend has
+// reached it. `--force` on send, new or task submit goes through anyway. The CLI reads
+// the ledger file for `figaro stats` and the status bar.
+
+// budgetLedger is the daemon's open ledger; nil when it could not be read.
+var budgetLedger *budget.Ledger
+
+// budgetLimits and budgetFile are the config [budget] caps and ledger
+// path, set at startup in the CLI and the daemon alike.
+var (
+	budgetLimits budget.Limits
+	budgetFile   string
+)
+
+func budgetPath(b config.Budget) string {
+	if b.Path != "" {
+		return b.Path
+	}
+	return filepath.Join(stateDir(), "usage.json")
+}
+
+func limitsOf(b config.Budget) budget.Limits {
+	return budget.Limits{
+		DailyTokens:   b.DailyTokens,
+		MonthlyTokens: b.MonthlyTokens,
+		DailyUSD:      b.DailyUSD,
+		MonthlyUSD:    b.MonthlyUSD,
+	}
+}
+
+// applyBudget opens the ledger and adds usage metering to the provider
+// chain. Spend is booked whether or not any cap is set, so stats work
+// before the first limit is configured.
+func applyBudget(b config.Budget) error {
+	budgetLedger, budgetLimits, budgetFile = nil, limitsOf(b), budgetPath(b)
+	l, err := budget.Open(budgetFile)
+	if err != nil {
+		return err
+	}
+	budgetLedger = l
+	providerMiddleware = append(providerMiddleware, meterUsage)
+	return nil
+}
+
+// meterUsage books the usage on every message the provider pushes.
+func meterUsage(p providerPkg.Provider) providerPkg.Provider {
+	return providerPkg.WrapSend(func(send providerPkg.SendFunc) providerPkg.SendFunc {
+		return func(ctx context.Context, in providerPkg.SendInput, bus providerPkg.Bus) error {
+			mb := meteredBus{Bus: bus}
+			if raw, ok := in.Snapshot["system.model"]; ok {
+				_ = json.Unmarshal(raw, &mb.model)
+			}
+			return send(ctx, in, mb)
+		}
+	})(p)
+}
+
+type meteredBus struct {
+	providerPkg.Bus
+	model string
+}
+
+func (b meteredBus) PushFigaro(msg message.Message, cache ...providerPkg.AssistantCache) {
+	if msg.Usage != nil {
+		recordSpend(b.model, *msg.Usage)
+	}
+	b.Bus.PushFigaro(msg, cache...)
+}
+
+// recordSpend books u, priced at model's rate when it has one. A failed
+// write is logged; it does not stop the turn.
+func recordSpend(model string, u message.Usage) {
+	if budgetLedger == nil {
+		return
+	}
+	usd, _ := tokenCost(model, u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheWriteTokens)
+	err := budgetLedger.Add(time.Now(), budget.Usage{
+		TokensIn:   u.InputTokens,
+		TokensOut:  u.OutputTokens,
+		CacheRead:  u.CacheReadTokens,
+		CacheWrite: u.CacheWriteTokens,
+		USD:        usd,
+	})
+	if err != nil {
+		slog.Error("budget: book usage", "model", model, "err", err)
+	}
+}
+
+// buildBudget is the agents' prompt check; nil when no cap is set.
+func buildBudget() figaro.Budget {
+	if budgetLimits.IsZero() || budgetLedger == nil {
+		return nil
+	}
+	return func(ariaID string, inputTokens int, force bool) error {
+		day, month := budgetLedger.Spent(time.Now())
+		err := budgetLimits.Check(day, month, inputTokens)
+		if err == nil {
+			return nil
+		}
+		if force {
+			slog.Info("budget: forced past limit", "aria", ariaID, "limit", err)
+			return nil
+		}
+		return errs.Wrap(errs.Budget, err)
+	}
+}
+
+// readSpend reads the ledger file the daemon writes.
+func readSpend() (day, month budget.Usage, err error) {
+	l, err := budget.Open(budgetFile)
+	if err != nil {
+		return day, month, err
+	}
+	day, month = l.Spent(time.Now())
+	return day, month, nil
+}
+
+// budgetLeft is the status bar's budget token: the tightest remaining
+// cap, "" when none is set.
+func budgetLeft() string {
+	if budgetLimits.IsZero() {
+		return ""
+	}
+	day, month, err := readSpend()
+	if err != nil {
+		return ""
+	}
+	if budgetLimits.Check(day, month, 0) != nil {
+		return "budget spent"
+	}
+	r := budgetLimits.Remaining(day, month)
+	if usd := tighter(r.DailyUSD, r.MonthlyUSD); usd >= 0 {
+		return "budget " + formatUSD(usd) + " left"
+	}
+	return "budget " + formatTokenCount(int(tighter(float64(r.DailyTokens), float64(r.MonthlyTokens)))) + " tok left"
+}
+
+// tighter is the smaller of two remaining amounts, ignoring unset (-1).
+func tighter(a, b float64) float64 {
+	switch {
+	case a < 0:
+		return b
+	case b < 0:
+		return a
+	}
+	return min(a, b)
+}
+
+// runStats prints spend today and this month against the [budget] caps.
+func runStats(asJSON bool) {
+	day, month, err := readSpend()
+	if err != nil {
+		die("stats: %s", err)
+	}
+	lim := budgetLimits
+	rem := lim.Remaining(day, month)
+	if asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(struct {
+			Today     budget.Usage     `json:"today"`
+			Month     budget.Usage     `json:"month"`
+			Limits    budget.Limits    `json:"limits"`
+			Remaining budget.Remaining `json:"remaining"`
+		}{day, month, lim, rem})
+		return
+	}
+	tok := func(n, unset int) string {
+		if n == unset {
+			return "-"
+		}
+		return formatTokenCount(n)
+	}
+	usd := func(v, unset float64) string {
+		if v == unset {
+			return "-"
+		}
+		return formatUSD(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PERIOD\tSPENT\tLIMIT\tLEFT")
+	fmt.Fprintf(w, "today\t%s tok\t%s\t%s\n", formatTokenCount(day.Tokens()), tok(lim.DailyTokens, 0), tok(rem.DailyTokens, -1))
+	fmt.Fprintf(w, "\t%s\t%s\t%s\n", formatUSD(day.USD), usd(lim.DailyUSD, 0), usd(rem.DailyUSD, -1))
+	fmt.Fprintf(w, "this month\t%s tok\t%s\t%s\n", formatTokenCount(month.Tokens()), tok(lim.MonthlyTokens, 0), tok(rem.MonthlyTokens, -1))
+	fmt.Fprintf(w, "\t%s\t%s\t%s\n", formatUSD(month.USD), usd(lim.MonthlyUSD, 0), usd(rem.MonthlyUSD, -1))
+	w.Flush()
+	if err := lim.Check(day, month, 0); err != nil {
+		fmt.Fprintf(os.Stderr, "%s; new prompts need --force\n", err)
+	}
+}
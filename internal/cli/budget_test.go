@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/budget"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/message"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+)
+
+type discardBus struct{ providerPkg.Bus }
+
+func (discardBus) PushFigaro(message.Message, ...providerPkg.AssistantCache) {}
+
+func withBudget(t *testing.T, b config.Budget) {
+	t.Helper()
+	t.Cleanup(func() {
+		providerMiddleware, budgetLedger, budgetLimits, budgetFile, modelPrices = nil, nil, budget.Limits{}, "", nil
+	})
+	if b.Path == "" {
+		b.Path = filepath.Join(t.TempDir(), "usage.json")
+	}
+	if err := applyBudget(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBudgetMetersAndBlocks(t *testing.T) {
+	withBudget(t, config.Budget{DailyUSD: 1})
+	modelPrices = map[string]config.Price{"m": {Input: 1_000_000, Output: 0}}
+	if len(providerMiddleware) != 1 {
+		t.Fatalf("chain = %d, want the meter added", len(providerMiddleware))
+	}
+	check := buildBudget()
+	if err := check("a1", 0, false); err != nil {
+		t.Fatalf("fresh ledger refused: %v", err)
+	}
+	if got := budgetLeft(); got != "budget $1.00 left" {
+		t.Errorf("budgetLeft = %q", got)
+	}
+
+	bus := meteredBus{Bus: discardBus{}, model: "m"}
+	bus.PushFigaro(message.Message{Role: message.RoleAssistant})
+	bus.PushFigaro(message.Message{Role: message.RoleAssistant, Usage: &message.Usage{InputTokens: 1, OutputTokens: 3}})
+	day, _, err := readSpend()
+	if err != nil || day.Tokens() != 4 || day.USD != 1 {
+		t.Fatalf("booked %+v, %v", day, err)
+	}
+
+	err = check("a1", 0, false)
+	if err == nil || !strings.Contains(err.Error(), "daily budget of $1.00 reached") || errs.KindOf(err) != errs.Budget {
+		t.Errorf("over the cap = %v", err)
+	}
+	if err := check("a1", 0, true); err != nil {
+		t.Errorf("forced = %v", err)
+	}
+	if got := budgetLeft(); got != "budget spent" {
+		t.Errorf("budgetLeft = %q", got)
+	}
+}
+
+func TestBudgetUncapped(t *testing.T) {
+	withBudget(t, config.Budget{})
+	if buildBudget() != nil || budgetLeft() != "" {
+		t.Error("no caps should mean no check and no status token")
+	}
+	if len(providerMiddleware) != 1 {
+		t.Error("spend should be metered without caps")
+	}
+}
+
+func TestBudgetLeftTokens(t *testing.T) {
+	withBudget(t, config.Budget{DailyTokens: 50_000, MonthlyTokens: 20_000})
+	recordSpend("unpriced", message.Usage{InputTokens: 5_000})
+	if got := budgetLeft(); got != "budget 15.0k tok left" {
+		t.Errorf("budgetLeft = %q, want the tighter monthly cap", got)
+	}
+}
+
+func TestSendForceFlag(t *testing.T) {
+	opts, rest, err := extractSendFlags([]string{"-q", "--", "--force", "me"})
+	if err != nil || opts.force || !reflect.DeepEqual(rest, []string{"-q", "--", "--force", "me"}) {
+		t.Errorf("after --: %v, force %v, err %v", rest, opts.force, err)
+	}
+	opts, rest, err = extractSendFlags([]string{"--force", "-q", "--", "hi"})
+	if err != nil || !opts.force || !reflect.DeepEqual(rest, []string{"-q", "--", "hi"}) {
+		t.Errorf("before --: %v, force %v, err %v", rest, opts.force, err)
+	}
+}
+
+func TestBudgetChecksNextRequest(t *testing.T) {
+	withBudget(t, config.Budget{MonthlyTokens: 1000})
+	recordSpend("m", message.Usage{InputTokens: 900})
+	check := buildBudget()
+	if err := check("a1", 0, false); err != nil {
+		t.Errorf("under the cap with no size = %v", err)
+	}
+	if err := check("a1", 200, false); err == nil || !strings.Contains(err.Error(), "would be passed by a 200-token request") {
+		t.Errorf("oversized request = %v", err)
+	}
+	if err := check("a1", 200, true); err != nil {
+		t.Errorf("forced = %v", err)
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/jack-work/figaro/internal/auth"
 	"github.com/jack-work/figaro/internal/cmdkit"
 	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/locale"
 	figOtel "github.com/jack-work/figaro/internal/otel"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
 )
 
 // Run dispatches a CLI invocation. progName is the basename of argv[0]
@@ -49,7 +53,11 @@ func Run(progName string, args []string) {
 	}
 
 	ctx := context.Background()
-	loaded := mustLoadConfig()
+	// Telemetry needs only the state dir: set it up while the config loads
+	// and join it before the first span.
+	otelInit := startOtel(ctx)
+	args = extractAccessibleFlag(args)
+	loaded := loadConfigFor(args)
 
 	// Apply config-driven sigil for chalkboard references.
 	if sigil, err := loaded.RefSigil(); err != nil {
@@ -57,6 +65,28 @@ func Run(progName string, args []string) {
 	} else {
 		SetRefSigil(sigil)
 	}
+	for _, err := range applyTheme(loaded.Config.Theme) {
+		fmt.Fprintf(os.Stderr, "warning: config [theme]: %s\n", err)
+	}
+	applyAccessible(loaded.Config.Accessibility)
+	for _, err := range applyKeymap(loaded.Config.Keys) {
+		fmt.Fprintf(os.Stderr, "warning: config [keys]: %s\n", err)
+	}
+	setTimeStyle(loaded.TimeFormat(), loaded.RelativeTime())
+	promptAuthor = authorName(loaded.Config.Author, os.Getenv)
+	promptVarShell = loaded.Config.Vars.Shell
+	if err := locale.Use(locale.Detect(loaded.Config.Locale, os.Getenv)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: config locale: %s\n", err)
+	}
+	modelPrices = loaded.Config.Prices
+	budgetLimits, budgetFile = limitsOf(loaded.Config.Budget), budgetPath(loaded.Config.Budget)
+	for _, err := range applyNotify(loaded.Config.Notify) {
+		fmt.Fprintf(os.Stderr, "warning: config [notify]: %s\n", err)
+	}
+	if err := providerPkg.ConfigureTransport(loaded.Config.Network); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: config [network]: %s\n", err)
+	}
+	auth.HTTPClient = providerPkg.Client(0)
 
 	// Compute binding policy (interactive? --no-bind? env?) once, before
 	// the router dispatches. Consulted by every command that would
@@ -64,7 +94,7 @@ func Run(progName string, args []string) {
 	initBindingPolicy()
 	args = extractNoBindFlag(args)
 
-	shutdown, err := figOtel.Init(ctx, stateDir())
+	shutdown, err := otelInit()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "warning: otel init: %s\n", err)
 	} else {
@@ -92,12 +122,36 @@ func Run(progName string, args []string) {
 	os.Exit(code)
 }
 
+// startOtel runs figOtel.Init in the background; the returned func waits
+// for it.
+func startOtel(ctx context.Context) func() (func(context.Context) error, error) {
+	type result struct {
+		shutdown func(context.Context) error
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		shutdown, err := figOtel.Init(ctx, stateDir())
+		done <- result{shutdown, err}
+	}()
+	return func() (func(context.Context) error, error) {
+		r := <-done
+		return r.shutdown, r.err
+	}
+}
+
 // figaro:
 // There has to be a better way to maintain these, like in declarative configurations perhaps.
 // Evaluate the necessity and the churn in the source's version history.
 func buildRouter(progName string, loaded *config.Loaded) *cmdkit.Router {
 	r := cmdkit.NewRouter(progName)
 	r.Extra = loaded
+	r.Text = func(key, def string) string {
+		if s, ok := locale.Lookup(key); ok {
+			return s
+		}
+		return def
+	}
 
 	r.Register(&cmdkit.Command{
 		Name:    "show",
@@ -118,6 +172,7 @@ labeled by their figaro LT (the coordinate send/fork <id>:<LT> target).
   figaro show --from 1 --to 3      units 1..3 inclusive
   figaro show --before 500 -n 20   20 units before LT 500 (paginate backwards)
   figaro show -a                   every unit
+  figaro show chat.figaro.jsonl    an exported file (figaro export)
   figaro show -j                   units as raw JSON (materialized, no deltas)
   figaro show eac16fef -v          verbose IR
   figaro show -l                   raw IR, no rendering`,
@@ -172,25 +227,104 @@ labeled by their figaro LT (the coordinate send/fork <id>:<LT> target).
 		CompleteArgs: completeAriaIDsPositionalOrFlag,
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "extract",
+		Group: "Prompt",
+		Short: "Write the last answer's code blocks to files",
+		Usage: "extract [<id>] [-o <dir>] [-d] [-n]",
+		Long: `Pull the fenced code blocks out of the aria's latest answer. A block
+whose fence names a path (` + "```go cmd/x.go" + ` or ` + "```go:cmd/x.go" + `) is written
+there, relative to -o (default: the current directory); paths may not
+escape it. Blocks without a path are printed to stdout.
+
+  figaro extract              write hinted blocks of the bound aria's answer
+  figaro extract -n           list what would be written
+  figaro extract -d           also apply unified-diff blocks (git apply)`,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "id", Description: "Target aria id (alias for the positional)"},
+			{Long: "out", Short: "o", Description: "Directory the hinted paths are relative to"},
+			{Long: "diff", Short: "d", IsBool: true, Description: "Apply diff/patch blocks with git apply"},
+			{Long: "dry-run", Short: "n", IsBool: true, Description: "Report only; write and apply nothing"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			ld := ctx.Extra.(*config.Loaded)
+			o := extractOpts{id: ctx.Flag("id"), dir: ctx.Flag("out"), diff: ctx.BoolFlag("diff"), dryRun: ctx.BoolFlag("dry-run")}
+			if o.id == "" && len(ctx.Args) > 0 {
+				o.id = ctx.Args[0]
+			}
+			runExtract(ld, o)
+			return nil
+		},
+		CompleteArgs: completeAriaIDsPositionalOrFlag,
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "diff",
+		Group: "Session",
+		Short: "Compare two branches of a forked conversation",
+		Usage: "diff [<id> [<other>]] [-U N]",
+		Long: `Diff the transcripts of two arias that share history — typically the
+alternatives of one fork. The shared turns are summarized; only where
+the branches diverge is shown, as a unified diff (tool calls appear as
+one-line markers).
+
+  figaro diff                 the bound aria vs. its next alternative
+  figaro diff <id>            <id> vs. its next alternative
+  figaro diff <id> <other>    any two arias`,
+		ArgsMax: 2,
+		Flags: []cmdkit.FlagDef{
+			{Long: "unified", Short: "U", Description: "Lines of context around each change (default 3)"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			ld := ctx.Extra.(*config.Loaded)
+			unified := 3
+			if v := ctx.Flag("unified"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return fmt.Errorf("diff: -U wants a non-negative integer, got %q", v)
+				}
+				unified = n
+			}
+			runAriaDiff(ld, ctx.Args, unified)
+			return nil
+		},
+		CompleteArgs: completeAriaIDsPositionalOrFlag,
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:    "send",
 		Aliases: []string{"qua"},
 		Group:   "Prompt",
 		Short:   "Send a prompt to an aria",
-		Usage:   "send [--id <id>] [-e] [-r] [-v] [-o] [-l] [-x] [-n] [-y] [-f] [-j] -- <prompt>",
+		Usage:   "send [--id <id>] [-e] [-r] [-v] [-o] [-l] [-x] [-n] [-y] [-f] [-j] [--format ansi|plain|json] [--events-json] [--paste] [--output <path>] [--reply-lang <lang>] [--web-search on|off] [--persona <name>] [--force] -- <prompt> | send --retry-last [--model <m>] [--temperature <t>]",
 		Long: `Send a prompt to an aria. Without --id, targets the pid-bound
 aria (creating one if this shell has no binding). With --id, targets
 the named aria, which must already exist (aria ids are system-minted).
 
+An unbound shell inside a project with a .figaro.yaml continues the
+project's conversation instead:
+
+  conversation: figaro-dev   # reused across shells; created on first use
+  persona: reviewer          # config [personas] name for the new aria
+  tools: [read, bash]        # only these tools (system.tools)
+
 Persistence (--ephemeral) and formatting (--raw) are orthogonal.
 
 Flags:
   --id <id>      Target a specific existing aria
   -e, --ephemeral
                  Spin a one-shot in-memory aria; kill it on completion.
-                 Contradicts --id. Says nothing about formatting.
+                 A copy is kept under scratch for scratch_days (default
+                 7) so ` + "`figaro keep`" + ` can still save it. Contradicts --id.
+                 Says nothing about formatting.
   -r, --raw      Stream verbatim to stdout: no ANSI, no markdown.
                  Pipe-friendly. Says nothing about persistence.
+  --format <f>   ansi (default: the live render), plain (same as --raw),
+                 or json: one event per line — start (the aria id),
+                 text/thinking deltas, tool status changes, guard
+                 violations, message_end, done — for tooling.
+  --events-json  Same as --format json.
   -v, --verbatim Dump the raw wire frames as JSON (one {"method","params"}
                  per line) — the literal protocol stream, no formatting,
                  no delta application.
@@ -209,6 +343,38 @@ Flags:
   -j, --json     Emit a single {"aria_id":..., "mode":...} JSON line on
                  stdout. With --forget: fire, then print. With <id>:<LT>:
                  fork, then print (mode="fork-send").
+  --output <path>
+                 Also write the answer's raw markdown (no ANSI, no thinking
+                 or tool output) to <path> while it renders.
+  --paste        Append the clipboard (wl-paste/xclip/xsel/pbpaste) to the
+                 prompt as its own paragraph; the prompt may then be empty.
+                 The clipboard is sent as-is: no {{...}} or @ref expansion.
+  --reply-lang <lang>
+                 Answer in <lang> ("French", "ja") from now on, whatever
+                 language the prompt is in. It sticks to the aria as
+                 system.reply_lang; "auto" goes back to following the
+                 prompt. Config reply_lang sets the default.
+  --web-search on|off
+                 Let Anthropic models search the web while answering (up
+                 to system.web_search_max_uses searches, default 5). It
+                 sticks to the aria as system.web_search.
+  --persona <name>
+                 Switch the aria to a config [personas.<name>] preset
+                 (credo, model, temperature, tools) with this prompt. See
+                 ` + "`figaro persona`" + `.
+  --force        Send the prompt even past a reached [budget] limit.
+  --retry-last   Re-ask the last prompt: fork at its LT and send it again
+                 on the fresh alternative. The old answer stays on the
+                 continuation. Takes no prompt body; --stay keeps the shell
+                 where it is.
+  --model <m>    --retry-last only: answer the retry with this model.
+  --temperature <t>
+                 --retry-last only: sampling temperature for the retry.
+
+Variables in the prompt are filled in before it is sent:
+{{env.USER}}, {{file "notes.md"}} and {{shell "git branch --show-current"}}.
+{{shell}} runs only with [vars] shell = true in the config. A variable
+that fails is left as written, with a warning.
 
 Keys while streaming:
   Ctrl-C         Interrupt the turn (sends figaro.interrupt).
@@ -222,7 +388,8 @@ Keys while streaming:
   figaro send -e -- <prompt>           ephemeral, rich
   figaro send -er -- <prompt>          ephemeral + raw (was: ` + "`figaro plain`" + `)
   figaro send -ex -y -- <instruction>  ephemeral exec, no confirmation
-  figaro send -f --id myid -- <prompt> fire-and-forget; do not stream`,
+  figaro send -f --id myid -- <prompt> fire-and-forget; do not stream
+  figaro send --retry-last --model m   regenerate the last answer with m`,
 		PassRaw: true,
 		Run: func(ctx *cmdkit.RunContext) error {
 			ld := ctx.Extra.(*config.Loaded)
@@ -236,8 +403,8 @@ Keys while streaming:
 		Name:    "new",
 		Group:   "Prompt",
 		Short:   "Start a fresh aria and prompt it",
-		Usage:   "new [-j|--json] [--loadout <name>] -- <prompt>",
-		Long:    "Creates a new aria (with server-generated id), binds it to this shell, and sends the prompt.\n-j/--json emits {aria_id, mode:'new'} on stdout instead of the streaming render.\n--loadout/-L <name> starts the aria under the named loadout (default:\nconfig.toml's default_loadout).",
+		Usage:   "new [-j|--json] [--loadout <name>] [--output <path>] [--force] -- <prompt>",
+		Long:    "Creates a new aria (with server-generated id), binds it to this shell, and sends the prompt.\n-j/--json emits {aria_id, mode:'new'} on stdout instead of the streaming render.\n--loadout/-L <name> starts the aria under the named loadout (default:\nconfig.toml's default_loadout).\n--output <path> also writes the answer's raw markdown to <path>.\n--force sends the prompt past a reached [budget] limit.",
 		PassRaw: true,
 		Run: func(ctx *cmdkit.RunContext) error {
 			ld := ctx.Extra.(*config.Loaded)
@@ -250,12 +417,78 @@ Keys while streaming:
 			if lerr != nil {
 				return fmt.Errorf("new: %s", lerr)
 			}
-			runNewPrompt(ld, prompt, loadout, renderSettings{jsonMode: asJSON})
+			output, _, oerr := preDashFlagValue(ctx.RawArgs, "--output")
+			if oerr != nil {
+				return fmt.Errorf("new: %s", oerr)
+			}
+			if output != "" && asJSON {
+				return fmt.Errorf("new: --output tees the rendered stream; it contradicts --json")
+			}
+			force := hasPreDashFlag(ctx.RawArgs, "--force")
+			runNewPrompt(ld, prompt, loadout, renderSettings{jsonMode: asJSON, output: output, force: force})
 			return nil
 		},
 		CompleteArgs: completeNewPrompt,
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "do",
+		Group: "Prompt",
+		Short: "Ask for one shell command, confirm, run it",
+		Usage: "do [-e] [-y] [-n] <request...>",
+		Long: `Asks a fresh aria for a single bash command that does what you
+describe, shows it, and runs it once you answer y. The aria is kept like
+any conversation (not bound to this shell), so figaro show <id> replays it.
+
+  figaro do find big files modified this week
+  figaro do -e -- strip exif data from every jpg here
+
+  -e/--explain   also print a short explanation of the command
+  -y/--yes       run without asking
+  -n/--dry-run   print the command only (also the default off a TTY)`,
+		ArgsMin: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "explain", Short: "e", IsBool: true, Description: "Explain the command under it"},
+			{Long: "yes", Short: "y", IsBool: true, Description: "Run without confirmation"},
+			{Long: "dry-run", Short: "n", IsBool: true, Description: "Print the command; do not run it"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			o := doOpts{explain: ctx.BoolFlag("explain"), yes: ctx.BoolFlag("yes"), dryRun: ctx.BoolFlag("dry-run")}
+			runDo(ctx.Extra.(*config.Loaded), o, strings.Join(ctx.Args, " "))
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "commit",
+		Group: "Prompt",
+		Short: "Draft a commit message for the staged diff",
+		Usage: "commit [-a]",
+		Long:  "Sends the staged diff to a one-shot ephemeral aria and prints a\nconventional commit message. -a/--apply commits with it directly\n(git commit -F -).",
+		Flags: []cmdkit.FlagDef{
+			{Long: "apply", Short: "a", IsBool: true, Description: "Commit with the drafted message"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			runCommitMessage(ctx.Extra.(*config.Loaded), ctx.BoolFlag("apply"))
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "pr",
+		Group: "Prompt",
+		Short: "Draft a PR description for this branch",
+		Usage: "pr [--base <ref>]",
+		Long:  "Sends the branch's commits and diff since its merge base to a one-shot\nephemeral aria and prints a PR body. --base defaults to origin's HEAD\nbranch (else main, else master).",
+		Flags: []cmdkit.FlagDef{
+			{Long: "base", Short: "b", Description: "Branch or ref the PR merges into"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			runPRBody(ctx.Extra.(*config.Loaded), ctx.Flag("base"))
+			return nil
+		},
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:    "plain",
 		Aliases: []string{"l"},
@@ -295,13 +528,15 @@ Keys while streaming:
 		Name:  "listen",
 		Group: "Prompt",
 		Short: "Attach to an aria's live stream without sending a prompt",
-		Usage: "listen [<id>]",
+		Usage: "listen [<id> | <id>:<LT> | :<LT>]",
 		Long: `Attach to an aria's live stream. Same view as a send mid-stream:
 catches up to the committed cursor, follows live frames, and supports
 Ctrl-T transcript mode — just without calling figaro.qua. Stays open
 until you close it.
 
-With no id, the pid-bound aria is used.
+With no id, the pid-bound aria is used; an unbound interactive shell
+gets a picker over the existing arias (type / to filter). With :<LT>
+the transcript opens centred on that message.
 
 Keys:
   Ctrl-C   Interrupt the in-flight turn (like in send).
@@ -313,16 +548,171 @@ Keys:
 		ArgsMax: 1,
 		Run: func(ctx *cmdkit.RunContext) error {
 			ld := ctx.Extra.(*config.Loaded)
+			var spec string
+			if len(ctx.Args) > 0 {
+				spec = ctx.Args[0]
+			}
+			id, at, _, err := parseSendTarget(spec)
+			if err != nil {
+				return err
+			}
+			runListen(ld, id, int(at))
+			return nil
+		},
+		CompleteArgs: completeAriaIDsPositionalOrFlag,
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "pane",
+		Group: "Prompt",
+		Short: "Follow an aria in a tmux/screen split next to this shell",
+		Usage: "pane [--below] [<id>]",
+		Long: `Split the current tmux (or GNU screen) window and run figaro listen
+in the new pane, started in this directory. With no id, the aria bound
+to this shell is used (an unbound shell gets the picker).
+
+The pane follows the aria through the daemon, so turns sent from any
+terminal — this shell, a task, a schedule — appear as they stream.`,
+		ArgsMin: 0,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "below", IsBool: true, Description: "Stack the pane under this one instead of beside it"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
 			var id string
 			if len(ctx.Args) > 0 {
 				id = ctx.Args[0]
 			}
-			runListen(ld, id)
+			runPane(ctx.Extra.(*config.Loaded), id, ctx.BoolFlag("below"))
 			return nil
 		},
 		CompleteArgs: completeAriaIDsPositionalOrFlag,
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "task",
+		Group: "Prompt",
+		Short: "Run a prompt in the background and collect the answer later",
+		Usage: "task submit [-L <loadout>] [--force] -- <prompt> | task list [-j] | task status|attach|resume|forget <id>",
+		Long: `A task is a prompt handed to a fresh aria (not bound to this shell)
+that the daemon works through while you do something else.
+
+  submit   create the aria, queue the prompt, print its id
+  list     submitted tasks: running, done, or gone (aria killed)
+  status   a task's state, then its latest answer on stdout
+  attach   follow a task's live stream (same as figaro listen <id>)
+  resume   restart a task that stopped partway (same as figaro resume <id>)
+  forget   drop a task from the list; the aria itself is kept
+
+Every tool round is written to the task's aria as it finishes, so a run
+cut short by a daemon crash or an error keeps its work. status reports it
+as interrupted.`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			runTask(ctx.Extra.(*config.Loaded), ctx.RawArgs)
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "persona",
+		Group: "State",
+		Short: "List, create and edit named personas",
+		Usage: "persona [list] | persona new|edit <name>",
+		Long: `A persona is a [personas.<name>] table in config.toml: a system prompt
+(credo), model, temperature and tool set. ` + "`figaro send --persona <name>`" + `
+applies it to the aria with the prompt; fields it leaves out keep their
+current values, and the aria remembers the persona (figaro status -m).
+
+  list     personas in the config (the default)
+  new      append a template persona and open it in $VISUAL/$EDITOR
+  edit     open config.toml at the persona's table
+
+Example:
+  [personas.reviewer]
+  credo = "Review the change for bugs and missing tests."
+  model = "claude-sonnet-4-5"
+  temperature = 0.2     # read by Copilot models only
+  tools = ["read", "bash"]
+
+Applying a persona replaces the aria's credo, temperature and tools. A
+persona without a credo gets the default loadout's; a temperature or tool
+list it leaves out is cleared. Its model, when set, replaces the aria's.`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			runPersona(ctx.Extra.(*config.Loaded), ctx.RawArgs)
+			return nil
+		},
+		CompleteArgs: completePersonas,
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "resume",
+		Group: "Prompt",
+		Short: "Restart a background task that stopped partway",
+		Usage: "resume <task-id>",
+		Long: `Picks an interrupted task back up. The task's aria already holds every
+tool round it finished; resume queues a prompt asking the model to carry on
+from there and returns, like task submit. A task that finished, is still
+running, or whose aria was killed is left alone.`,
+		Run: func(ctx *cmdkit.RunContext) error {
+			if len(ctx.Args) != 1 {
+				return fmt.Errorf("usage: figaro resume <task-id>")
+			}
+			runTaskResume(ctx.Extra.(*config.Loaded), ctx.Args[0], false)
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "batch",
+		Group: "Prompt",
+		Short: "Send a file of prompts through the provider's batch API",
+		Usage: "batch submit [-L <loadout>] [--model <model>] <prompts.jsonl | -> | batch status [<id>] [-j] | batch fetch <id>",
+		Long: `A batch runs many independent prompts offline at the provider's batch
+rate (half price on Anthropic). Results usually arrive within hours;
+nothing runs in the daemon meanwhile.
+
+  submit   send the prompts and print the batch id. One JSON object per
+           line: {"prompt": "...", "id": "...", "model": "...",
+           "max_tokens": N}; only prompt is required and id defaults to
+           p<line>. The loadout supplies provider, model and credo
+  status   progress of one batch, or of every batch submitted here
+  fetch    once a batch has ended, write each answer into a conversation
+           of its own and print prompt id -> aria. Safe to re-run`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			runBatchCmd(ctx.Extra.(*config.Loaded), ctx.RawArgs)
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "schedule",
+		Group: "Prompt",
+		Short: "Send a prompt to an aria on a cron timetable",
+		Usage: `schedule add --cron "<m h dom mon dow>" [--aria <id> | -L <loadout>] [--notify] [--sink <name>] -- <prompt> | schedule list [-j] | schedule remove <id>`,
+		Long: `The daemon sends a scheduled prompt to its aria whenever the cron
+expression matches, and the answers collect in that aria's log (follow
+them with figaro listen <aria>).
+
+  add      record a schedule and print its id; without --aria a fresh
+           aria is created to hold the runs. --notify posts a desktop
+           notification when a run finishes; --sink <name> delivers
+           each answer to a config [sinks.<name>] endpoint
+  list     schedules with their next and last run
+  remove   stop a schedule; its aria and past answers are kept
+
+The cron expression is the standard five fields in local time, e.g.
+"0 9 * * 1" for 09:00 every Monday. Runs only happen while the daemon
+is up; a missed minute is not made up.`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			runScheduleCmd(ctx.Extra.(*config.Loaded), ctx.RawArgs)
+			return nil
+		},
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:  "hup",
 		Group: "Prompt",
@@ -350,7 +740,7 @@ With no id, the pid-bound aria is used.`,
 		Aliases: []string{"ls"},
 		Group:   "Session",
 		Short:   "List arias — scoped to where you're attended (attend is `cd`)",
-		Usage:   "list [<id>] [-h|--home | -g|--global] [-a|--all | -n <count>] [-j|--json]",
+		Usage:   "list [<id>] [-h|--home | -g|--global] [-a|--all | -n <count>] [-j|--json] [--archived]",
 		Long: "Lists arias `ls`-style relative to where you're attended (attend is\nthe `cd`).\n\n" +
 			"Scope:\n" +
 			"  (default)     attended → your conversation's tree (● = you);\n" +
@@ -363,7 +753,8 @@ With no id, the pid-bound aria is used.`,
 			"  -a, --all     no cap\n" +
 			"  -n <count>    cap to <count>\n\n" +
 			"  -j, --json    pro/dev: every aria incl. null + loadouts as JSON;\n" +
-			"                takes no other flags",
+			"                takes no other flags\n\n" +
+			"  --archived    archived arias instead (figaro archive)",
 		ArgsMax: 1,
 		Flags: []cmdkit.FlagDef{
 			{Long: "home", Short: "h", IsBool: true, Description: "Home view: all top-level arias, without unbinding"},
@@ -371,9 +762,14 @@ With no id, the pid-bound aria is used.`,
 			{Long: "all", Short: "a", IsBool: true, Description: "Show all (remove the 10-most-recent cap)"},
 			{Long: "limit", Short: "n", Description: "Cap to N rows (default 10)"},
 			{Long: "json", Short: "j", IsBool: true, Description: "Pro/dev: all arias (incl. anchors) as JSON; no other flags"},
+			{Long: "archived", IsBool: true, Description: "List archived arias instead"},
 		},
 		Run: func(ctx *cmdkit.RunContext) error {
 			ld := ctx.Extra.(*config.Loaded)
+			if ctx.BoolFlag("archived") {
+				runArchiveList(ctx.BoolFlag("json"))
+				return nil
+			}
 			o := lsOpts{
 				jsonOut: ctx.BoolFlag("json"),
 				home:    ctx.BoolFlag("home"),
@@ -411,18 +807,71 @@ With no id, the pid-bound aria is used.`,
 		Aliases: []string{"at"},
 		Group:   "Session",
 		Short:   "Bind this shell to an existing aria (optionally at an LT)",
-		Usage:   "attend <id> | <id>:<LT> | :<LT> | null",
-		Long:    "Binds this shell to an aria. With :<LT> the binding carries a pending\nfork-point — the next bare prompt (`fig -- …`) forks the trunk there and\nmoves to the new branch. `:<LT>` alone re-pins the already-bound aria.\n\n`attend null` goes home: drops this shell's binding (named for the kindNull\ngenesis root). New conversations then default to the live loadout.",
-		ArgsMin: 1,
+		Usage:   "attend [<id> | <id>:<LT> | :<LT> | + | - | null]",
+		Long:    "Binds this shell to an aria. With :<LT> the binding carries a pending\nfork-point — the next bare prompt (`fig -- …`) forks the trunk there and\nmoves to the new branch. `:<LT>` alone re-pins the already-bound aria.\n\n`attend +` / `attend -` cycle between the alternatives at the nearest fork\npoint (e.g. the answers `send --retry-last` produced), one per branch.\n\n`attend null` goes home: drops this shell's binding (named for the kindNull\ngenesis root). New conversations then default to the live loadout.\n\nWith no argument, pick from the existing arias (type / to filter).",
+		ArgsMin: 0,
 		ArgsMax: 1,
 		Run: func(ctx *cmdkit.RunContext) error {
 			ld := ctx.Extra.(*config.Loaded)
-			runAttend(ld, ctx.Args[0])
+			spec := ""
+			if len(ctx.Args) > 0 {
+				spec = ctx.Args[0]
+			}
+			runAttend(ld, spec)
 			return nil
 		},
 		CompleteArgs: completeAriaIDsPositionalOrFlag,
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:    "bookmarks",
+		Aliases: []string{"bm"},
+		Group:   "Session",
+		Short:   "List, add and remove notes on messages",
+		Usage:   "bookmarks [ls] [<id> | -a] | bookmarks add [<id>]:<LT> [<note>...] | bookmarks rm [<id>]:<LT>",
+		Long: `Bookmarks mark single messages, optionally with a note. They are kept
+beside the aria store, so marking a message never changes the
+conversation or its signatures. In the transcript pager, m marks the
+selected message (or the one at the top of the screen) and shows any
+note it already has.
+
+  ls    the bound aria's bookmarks (every aria's with -a or no binding).
+        In a terminal, pick one to open the transcript on that message.
+  add   mark a message; :<LT> alone names the bound aria
+  rm    remove a bookmark`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "all", Short: "a", IsBool: true, Description: "list every aria's bookmarks"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			loaded := ctx.Extra.(*config.Loaded)
+			args := ctx.Args
+			if len(args) > 0 {
+				switch args[0] {
+				case "add":
+					if len(args) >= 2 {
+						return runBookmarksAdd(args[1], strings.Join(args[2:], " "))
+					}
+					return fmt.Errorf("usage: bookmarks add [<id>]:<LT> [<note>...]")
+				case "rm", "remove":
+					if len(args) == 2 {
+						return runBookmarksRemove(args[1])
+					}
+					return fmt.Errorf("usage: bookmarks rm [<id>]:<LT>")
+				case "ls", "list":
+					args = args[1:]
+				}
+			}
+			if len(args) > 1 {
+				return fmt.Errorf("usage: bookmarks [ls] [<id> | -a]")
+			}
+			var id string
+			if len(args) == 1 {
+				id = args[0]
+			}
+			return runBookmarksList(loaded, id, ctx.BoolFlag("all"))
+		},
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:  "fork",
 		Group: "Session",
@@ -503,6 +952,153 @@ nothing to promote into ("cannot promote into a loadout").`,
 		CompleteArgs: completeAriaIDsPositionalOrFlag,
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "archive",
+		Group: "Session",
+		Short: "Move a finished aria out of the store into a compressed file",
+		Usage: "archive [-c <id> | <id>] | archive --list [-j]",
+		Long: `Writes the aria's log and chalkboard to a gzipped file beside the aria
+store (<data>/archive/<id>.json.gz) and removes the aria. Archived arias
+drop out of ` + "`figaro list`" + ` (list --archived shows them); ` + "`figaro show <id>`" + `
+still reads them. ` + "`figaro unarchive <id>`" + ` brings one back as a fresh aria;
+--keep leaves the archive in place, forking a live copy off it.
+
+A trunk with live branches can't be archived; archive or kill the
+branches first.`,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "conversation", Short: "c", Description: "Aria id to archive"},
+			{Long: "list", IsBool: true, Description: "List archived arias"},
+			{Long: "json", Short: "j", IsBool: true, Description: "With --list: JSON"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			if ctx.BoolFlag("list") {
+				runArchiveList(ctx.BoolFlag("json"))
+				return nil
+			}
+			id := ctx.Flag("conversation")
+			if id == "" && len(ctx.Args) > 0 {
+				id = ctx.Args[0]
+			}
+			if id == "" {
+				die("usage: figaro archive [-c <id> | <id>] | archive --list")
+			}
+			runArchive(ctx.Extra.(*config.Loaded), id)
+			return nil
+		},
+		CompleteArgs: completeAriaIDsPositionalOrFlag,
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:    "unarchive",
+		Group:   "Session",
+		Short:   "Restore an archived aria as a fresh one",
+		Usage:   "unarchive [-c <id> | <id>] [--keep]",
+		Long:    "Imports an archive back into the aria store and prints the new aria's\nid (ids are minted by the store). The archive is deleted unless --keep.",
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "conversation", Short: "c", Description: "Archived aria id"},
+			{Long: "keep", IsBool: true, Description: "Keep the archive (restore a copy)"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			id := ctx.Flag("conversation")
+			if id == "" && len(ctx.Args) > 0 {
+				id = ctx.Args[0]
+			}
+			if id == "" {
+				die("usage: figaro unarchive [-c <id> | <id>] [--keep]")
+			}
+			runUnarchive(ctx.Extra.(*config.Loaded), id, ctx.BoolFlag("keep"))
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "export",
+		Group: "Session",
+		Short: "Write an aria to a .figaro.jsonl file",
+		Usage: "export [<id>] [-o <file>]",
+		Long: `Writes the aria's log to a plain file, one JSON message per line
+(default: <id>.figaro.jsonl in the current directory), with an index
+beside it (<file>.idx) for fast tail reads. The file is append-only:
+exporting the same aria to it again adds just the messages since.
+
+  figaro export                    the bound aria
+  figaro export eac16fef -o a.figaro.jsonl
+  figaro show a.figaro.jsonl       read it back, last units first
+  figaro import a.figaro.jsonl     a fresh aria from it
+
+The chalkboard is not exported; import starts the aria under a loadout.
+The index is derived: a file edited or copied without it is re-indexed
+when next opened.`,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "id", Description: "Target aria id (alias for the positional)"},
+			{Long: "out", Short: "o", Description: "File to write or continue (must end in .figaro.jsonl)"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			id := ctx.Flag("id")
+			if id == "" && len(ctx.Args) > 0 {
+				id = ctx.Args[0]
+			}
+			runExport(ctx.Extra.(*config.Loaded), id, ctx.Flag("out"))
+			return nil
+		},
+		CompleteArgs: completeAriaIDsPositionalOrFlag,
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "import",
+		Group: "Session",
+		Short: "Start a fresh aria from a .figaro.jsonl file",
+		Usage: "import <file> [-L <loadout>]",
+		Long: `Reads a .figaro.jsonl file (figaro export, or one message per line
+written by hand) into a new aria and prints its id (ids are minted by
+the store). The aria starts under --loadout, default config.toml's
+default_loadout. Lines without a logical_time are numbered in order.`,
+		ArgsMin: 1,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "loadout", Short: "L", Description: "Loadout the aria starts under"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			runImport(ctx.Extra.(*config.Loaded), ctx.Args[0], ctx.Flag("loadout"))
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "keep",
+		Group: "Session",
+		Short: "Turn the last one-off conversation into a real aria",
+		Usage: "keep [<scratch>] [--name <conversation>] | keep --list",
+		Long: `One-off sends (send -e, plain without an aria) are copied to
+<data>/scratch under a name made of the time and the prompt's first words,
+and pruned after scratch_days (default 7; 0 keeps none).
+
+keep imports the newest scratch (or the one named) as a fresh aria,
+attends it and deletes the scratch. --name also records it as that named
+conversation, the one a .figaro.yaml ` + "`conversation:`" + ` continues, for
+the project around the working directory.`,
+		ArgsMax: 1,
+		Flags: []cmdkit.FlagDef{
+			{Long: "name", Description: "Record the aria as this named conversation"},
+			{Long: "list", IsBool: true, Description: "List scratch conversations"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			if ctx.BoolFlag("list") {
+				runKeepList()
+				return nil
+			}
+			scratch := ""
+			if len(ctx.Args) > 0 {
+				scratch = ctx.Args[0]
+			}
+			runKeep(ctx.Extra.(*config.Loaded), scratch, ctx.Flag("name"))
+			return nil
+		},
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:    "state",
 		Aliases: []string{"chalkboard"},
@@ -561,6 +1157,50 @@ nothing to promote into ("cannot promote into a loadout").`,
 		CompleteArgs: completeAriaIDsAfterFlag(completeChalkboardKeys),
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "pin",
+		Group: "State",
+		Short: "Keep messages or snippets in every request",
+		Usage: "pin [--id <id>] [<LT>...] [--file F] [--text T] | pin ls | pin rm <n>",
+		Long: `Pins go in the system prompt of every request, so they stay in context
+when older history no longer is. A message pin keeps the message's prose
+and is sent only while its LT is missing from the replayed history;
+snippets (--file, --text) are always sent. Pins live on the chalkboard
+as system.pins.
+
+  figaro pin 12 14          pin the messages at LT 12 and 14
+  figaro pin --file api.md  pin a file's contents as it is now
+  figaro pin ls             the pins, numbered
+  figaro pin rm 2           unpin the second
+
+In the transcript pager, a then p pins the selection (again to unpin).`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "id", Description: "Target aria id (overrides pid binding)"},
+			{Long: "file", Description: "pin a file's contents"},
+			{Long: "text", Description: "pin literal text"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			ld := ctx.Extra.(*config.Loaded)
+			id := ctx.Flag("id")
+			if len(ctx.Args) > 0 {
+				switch ctx.Args[0] {
+				case "ls", "list":
+					runPinList(ld, id)
+					return nil
+				case "rm", "remove":
+					if len(ctx.Args) != 2 {
+						return fmt.Errorf("usage: pin rm <n>")
+					}
+					runPinRemove(ld, id, ctx.Args[1])
+					return nil
+				}
+			}
+			runPin(ld, id, ctx.Args, ctx.Flag("file"), ctx.Flag("text"))
+			return nil
+		},
+		CompleteArgs: completeAriaIDsAfterFlag(nil),
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:    "loadout",
 		Group:   "State",
@@ -664,19 +1304,343 @@ nothing to promote into ("cannot promote into a loadout").`,
 		},
 	})
 
+	r.Register(&cmdkit.Command{
+		Name:  "stats",
+		Group: "System",
+		Short: "Show spend against the [budget] limits, or one aria's statistics",
+		Usage: "stats [-c <id>] [-j]",
+		Long: `The daemon books every provider response into a per-day ledger
+(<state>/usage.json, or [budget] path). Dollar figures use the same
+prices as the status bar's cost.
+
+Config [budget] daily_tokens, monthly_tokens, daily_usd and
+monthly_usd cap spend; tokens count input plus output. Once a cap is
+reached the daemon refuses new prompts until the day or month rolls
+over, as it does a prompt whose request alone would pass a token cap
+(sized by the provider's token count where it has one). A turn
+already running finishes. Pass --force to send, new or task submit to
+send the prompt anyway.
+
+With -c <id> (--conversation) it describes one aria instead: messages,
+average and longest length per role (tool results count as "tool"),
+token totals, tool calls, how long answers took, and messages per day.`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			asJSON := hasPreDashFlag(ctx.RawArgs, "--json", "-j")
+			id, ok, err := preDashFlagValue(ctx.RawArgs, "--conversation", "-c")
+			if err != nil {
+				return fmt.Errorf("stats: %w", err)
+			}
+			if ok {
+				runConversationStats(ctx.Extra.(*config.Loaded), id, asJSON)
+				return nil
+			}
+			runStats(asJSON)
+			return nil
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "audit",
+		Group: "System",
+		Short: "Show or verify the daemon's hash-chained audit log",
+		Usage: "audit show [-n <count>] [--aria <id>] [--kind <kind>] [-j] | audit verify",
+		Long: `With config [audit] enabled = true the daemon appends one entry for
+every provider request, prompt, tool call and answer, recording what
+the guard decided about each. Text is stored as a SHA-256 digest, not
+verbatim. Each entry carries the hash of the one before it.
+
+  show     the newest entries (-n 50 by default, 0 for all), optionally
+           for one aria or one kind: request, prompt, tool or answer
+  verify   recompute the chain; fails at the first altered, dropped or
+           reordered entry. Keep a copy of the last hash elsewhere to
+           also detect a truncated tail.`,
+		PassRaw: true,
+		Run: func(ctx *cmdkit.RunContext) error {
+			runAuditCmd(ctx.Extra.(*config.Loaded), ctx.RawArgs)
+			return nil
+		},
+	})
+
 	r.Register(&cmdkit.Command{
 		Name:  "doctor",
 		Group: "System",
-		Short: "Store maintenance: gc removes dead channels (legacy translations, turn-wal, _live)",
-		Usage: "doctor gc [--dry-run]",
+		Short: "Check config, credentials, storage and terminal; gc reclaims dead store space",
+		Usage: "doctor [-j] | doctor gc [--dry-run]",
+		Long: `Bare doctor checks the setup and prints ok, warn or fail per check,
+with the fix under any that did not pass. It exits 1 when a check failed.
+
+  config        config.toml parses; [theme], [keys], [notify], [guard] are valid
+  loadout       the default loadout resolves and names a provider
+  <provider>    each configured provider accepts its credential (lists models)
+  state dir     writable; likewise the runtime dir
+  usage ledger  the [budget] ledger reads
+  angelus       whether the daemon is running
+  color         the terminal advertises truecolor (COLORTERM)
+  images        the inline image protocol the terminal advertises, if any
+
+doctor gc removes dead store channels (legacy translations, turn-wal,
+_live) and stored content no conversation refers to any more (left by
+removed and archived arias); the daemon must be stopped.`,
 		Flags: []cmdkit.FlagDef{
-			{Long: "dry-run", Short: "n", IsBool: true, Description: "Report what would be removed without touching the store"},
+			{Long: "dry-run", Short: "n", IsBool: true, Description: "gc: report what would be removed without touching the store"},
+			{Long: "json", Short: "j", IsBool: true, Description: "Print the checks as JSON"},
 		},
 		Run: func(ctx *cmdkit.RunContext) error {
-			if len(ctx.Args) != 1 || ctx.Args[0] != "gc" {
-				return fmt.Errorf("usage: doctor gc [--dry-run]")
+			switch {
+			case len(ctx.Args) == 0:
+				return runDoctor(ctx.Extra.(*config.Loaded), ctx.BoolFlag("json"))
+			case len(ctx.Args) == 1 && ctx.Args[0] == "gc":
+				return runDoctorGC(ctx.BoolFlag("dry-run"))
+			}
+			return fmt.Errorf("usage: doctor [-j] | doctor gc [--dry-run]")
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "backup",
+		Group: "System",
+		Short: "Bundle conversations, config and records into a tarball, or restore one",
+		Usage: "backup create [<file>] [--credentials] | backup restore <file> [--replace]",
+		Long: `backup create writes a .tar.gz (or .tar) of the config dir (config.toml,
+loadouts, chalkboard templates, themes) and the state dir's aria store,
+tasks, schedules, batches, usage ledger and audit log. The file defaults
+to figaro-backup-<date>-<time>.tar.gz in the current directory.
+
+Provider credentials are encrypted to this machine's key and are left
+out unless --credentials is passed; on a new machine run figaro login.
+Private signing keys (figaro keys) are credentials too.
+
+backup restore unpacks a bundle. It will not touch an existing entry
+(a loadouts dir, the aria store) unless --replace is passed, which
+swaps each entry the bundle carries for its copy; entries the bundle
+lacks are kept. Take a backup first if the current state matters.
+
+Both need the daemon stopped (figaro stop).`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "credentials", IsBool: true, Description: "create: include provider credentials and signing keys"},
+			{Long: "replace", IsBool: true, Description: "restore: overwrite existing entries"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			loaded := ctx.Extra.(*config.Loaded)
+			switch {
+			case len(ctx.Args) >= 1 && len(ctx.Args) <= 2 && ctx.Args[0] == "create":
+				file := ""
+				if len(ctx.Args) == 2 {
+					file = ctx.Args[1]
+				}
+				return runBackupCreate(loaded, file, ctx.BoolFlag("credentials"))
+			case len(ctx.Args) == 2 && ctx.Args[0] == "restore":
+				return runBackupRestore(loaded, ctx.Args[1], ctx.BoolFlag("replace"))
+			}
+			return fmt.Errorf("usage: backup create [<file>] [--credentials] | backup restore <file> [--replace]")
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "sync",
+		Group: "System",
+		Short: "Keep the aria store in a Git repository shared between machines",
+		Usage: "sync init [<remote>] | sync push | sync pull [--theirs]",
+		Long: `sync init makes <state>/arias a Git repository and, given a remote URL,
+points it there. If the remote already holds a store it is checked out,
+which needs an empty local store (figaro backup create, then move it
+aside).
+
+Once initialized the daemon commits the store after every turn; the
+commit names the aria and lists each new message as "lt role hash".
+[sync] auto_commit = false turns that off.
+
+sync push commits anything pending and pushes. sync pull merges the
+remote's commits. A file both machines changed since the last sync (an
+aria written on both) cannot be merged line by line; it keeps this
+machine's copy, or the remote's with --theirs, and is listed.
+
+init and pull need the daemon stopped (figaro stop).`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "theirs", IsBool: true, Description: "pull: resolve diverged files to the remote's copy"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			switch {
+			case len(ctx.Args) >= 1 && len(ctx.Args) <= 2 && ctx.Args[0] == "init":
+				remote := ""
+				if len(ctx.Args) == 2 {
+					remote = ctx.Args[1]
+				}
+				return runSyncInit(remote)
+			case len(ctx.Args) == 1 && ctx.Args[0] == "push":
+				return runSyncPush()
+			case len(ctx.Args) == 1 && ctx.Args[0] == "pull":
+				return runSyncPull(ctx.BoolFlag("theirs"))
+			}
+			return fmt.Errorf("usage: sync init [<remote>] | sync push | sync pull [--theirs]")
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "mirror",
+		Group: "System",
+		Short: "Push an encrypted copy of conversations and config to S3, GCS or WebDAV",
+		Usage: "mirror push [--claim] [<name>...] | mirror pull <name> [--replace] | mirror status [<name>...]",
+		Long: `A mirror holds what a backup bundle does (config without credentials,
+the aria store, tasks, schedules, usage and audit records) in storage
+you need not trust: each file is encrypted with AES-256-GCM under a key
+kept in <config>/mirrors/<name>.key, object names are keyed hashes, and
+the file list is itself encrypted. A push uploads only changed files.
+
+  [mirrors.offsite]
+  kind = "s3"                      # s3, gcs, webdav or dir
+  url = "https://s3.eu-west-1.amazonaws.com/my-bucket/figaro"
+  region = "eu-west-1"
+  every = "30m"                    # the daemon pushes on this interval
+
+Credentials come from AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY (s3),
+GCS_HMAC_ID/GCS_HMAC_SECRET (gcs, url https://storage.googleapis.com/<bucket>/...)
+or FIGARO_WEBDAV_USER/FIGARO_WEBDAV_PASSWORD (webdav); id_env and
+secret_env name others.
+
+The first push creates the key. Keep a copy elsewhere: without it the
+mirror cannot be read. To restore on another machine copy the key file
+over, add the same table, stop the daemon and run mirror pull. Files
+that differ locally are overwritten only with --replace.
+
+A mirror belongs to the machine that pushes it: a push from another host
+is refused, since it would delete the first machine's files. After a
+restore, mirror push --claim moves the mirror to the new machine.`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "replace", IsBool: true, Description: "pull: overwrite local files that differ"},
+			{Long: "claim", IsBool: true, Description: "push: take over a mirror another host pushes to"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			loaded := ctx.Extra.(*config.Loaded)
+			if len(ctx.Args) > 0 {
+				switch ctx.Args[0] {
+				case "push":
+					return runMirrorPush(loaded, ctx.Args[1:], ctx.BoolFlag("claim"))
+				case "status":
+					return runMirrorStatus(loaded, ctx.Args[1:])
+				case "pull":
+					if len(ctx.Args) == 2 {
+						return runMirrorPull(loaded, ctx.Args[1], ctx.BoolFlag("replace"))
+					}
+				}
+			}
+			return fmt.Errorf("usage: mirror push [<name>...] | mirror pull <name> [--replace] | mirror status [<name>...]")
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "share",
+		Group: "System",
+		Short: "Publish a read-only page of a conversation",
+		Usage: "share [<id>] [--expires D] | share ls | share revoke <token|id>",
+		Long: `Give an aria (default: the one bound to this shell) a link at an
+unguessable URL. The daemon renders the conversation as a standalone
+HTML page: prose as markdown, thinking and tool traffic folded away. The
+page runs no scripts and loads nothing from elsewhere.
+
+  [share]
+  listen = "127.0.0.1:8750"
+  base_url = "https://figaro.example.net"   # printed links; default http://<listen>
+
+A link expires after --expires (default 168h; 0 keeps it until revoked).
+The page is rendered on each request, so it shows the aria as it is now.
+share revoke takes a token (or its first 8 characters) or an aria id,
+which revokes every link to that aria. Expired and revoked links 404.`,
+		Flags: []cmdkit.FlagDef{
+			{Long: "expires", Description: "link lifetime, e.g. 24h (0: until revoked)"},
+		},
+		Run: func(ctx *cmdkit.RunContext) error {
+			loaded := ctx.Extra.(*config.Loaded)
+			if len(ctx.Args) > 0 {
+				switch ctx.Args[0] {
+				case "ls", "list":
+					return runShareList(loaded)
+				case "revoke":
+					if len(ctx.Args) != 2 {
+						return fmt.Errorf("usage: share revoke <token|id>")
+					}
+					return runShareRevoke(ctx.Args[1])
+				}
+			}
+			if len(ctx.Args) > 1 {
+				return fmt.Errorf("usage: share [<id>] [--expires D] | share ls | share revoke <token|id>")
+			}
+			ttl, err := parseShareTTL(ctx.Flag("expires"))
+			if err != nil {
+				return err
+			}
+			var id string
+			if len(ctx.Args) == 1 {
+				id = ctx.Args[0]
+			}
+			return runShare(loaded, id, ttl)
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "keys",
+		Group: "System",
+		Short: "Manage the keys that sign prompts and vouch for others'",
+		Usage: "keys generate [<name>] | keys ls | keys trust <name> <key>",
+		Long: `A prompt carries its author's name (config author, or $FIGARO_AUTHOR,
+default the login name). Once that name has a key, the daemon signs each
+of its prompts with Ed25519 over the aria's hash chain: the signature
+covers the prompt and every message before it.
+
+  generate  create a key pair for <name> (default your author name) and
+            print the public key to hand to others
+  ls        the trusted public keys; (signs) marks those with a private
+            key here
+  trust     record another author's public key, so figaro verify names
+            them
+
+Private keys live in <config>/identity and, like provider credentials,
+stay out of backups and mirrors unless asked for.`,
+		Run: func(ctx *cmdkit.RunContext) error {
+			loaded := ctx.Extra.(*config.Loaded)
+			if len(ctx.Args) > 0 {
+				switch ctx.Args[0] {
+				case "generate":
+					if len(ctx.Args) <= 2 {
+						var name string
+						if len(ctx.Args) == 2 {
+							name = ctx.Args[1]
+						}
+						return runKeysGenerate(loaded, name)
+					}
+				case "ls", "list":
+					return runKeysList(loaded)
+				case "trust":
+					if len(ctx.Args) == 3 {
+						return runKeysTrust(loaded, ctx.Args[1], ctx.Args[2])
+					}
+				}
+			}
+			return fmt.Errorf("usage: keys generate [<name>] | keys ls | keys trust <name> <key>")
+		},
+	})
+
+	r.Register(&cmdkit.Command{
+		Name:  "verify",
+		Group: "System",
+		Short: "Check the signatures on a conversation",
+		Usage: "verify [<id>]",
+		Long: `Recompute the aria's hash chain (default: the one bound to this shell)
+and check every signed message against it. Signers are named by the keys
+you trust (figaro keys trust); others are shown by public key. An edited,
+dropped or reordered message breaks every signature after it. Exits 1 on
+a bad signature.`,
+		Run: func(ctx *cmdkit.RunContext) error {
+			var id string
+			switch len(ctx.Args) {
+			case 0:
+			case 1:
+				id = ctx.Args[0]
+			default:
+				return fmt.Errorf("usage: verify [<id>]")
 			}
-			return runDoctorGC(ctx.BoolFlag("dry-run"))
+			return runVerify(ctx.Extra.(*config.Loaded), id)
 		},
 	})
 
@@ -0,0 +1,68 @@
+package cli
+
+import "strings"
+
+// codeBlock is one fenced block from assistant markdown. Info is the text
+// after the opening fence (a language, or a path hint like "go cmd/x.go").
+type codeBlock struct {
+	Info string
+	Body string
+}
+
+// fencedBlocks returns the fenced code blocks of markdown in order. Fences are
+// ``` or ~~~ runs of three or more; a block closes on a fence of the same
+// character at least as long. An unterminated block runs to the end.
+func fencedBlocks(markdown string) []codeBlock {
+	var out []codeBlock
+	var cur *codeBlock
+	var fence string
+	var body []string
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if cur == nil {
+			if f := fenceRun(trimmed); f != "" {
+				cur = &codeBlock{Info: strings.TrimSpace(trimmed[len(f):])}
+				fence, body = f, nil
+			}
+			continue
+		}
+		if f := fenceRun(trimmed); f != "" && f[0] == fence[0] && len(f) >= len(fence) && strings.TrimSpace(trimmed[len(f):]) == "" {
+			cur.Body = strings.Join(body, "\n")
+			out = append(out, *cur)
+			cur = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	if cur != nil {
+		cur.Body = strings.Join(body, "\n")
+		out = append(out, *cur)
+	}
+	return out
+}
+
+// fenceRun returns the leading ``` / ~~~ run of line, or "" if it has none.
+func fenceRun(line string) string {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return line[:n]
+}
+
+// codeOnly joins the bodies of every fenced block in markdown, or returns ""
+// when there are none.
+func codeOnly(markdown string) string {
+	blocks := fencedBlocks(markdown)
+	bodies := make([]string, len(blocks))
+	for i, b := range blocks {
+		bodies[i] = b.Body
+	}
+	return strings.Join(bodies, "\n\n")
+}
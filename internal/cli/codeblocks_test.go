@@ -0,0 +1,24 @@
+package cli
+
+import "testing"
+
+func TestFencedBlocks(t *testing.T) {
+	md := "intro\n```go main.go\npackage main\n```\nmid\n~~~~\nraw ``` inside\n~~~~\n```sh\nunterminated"
+	got := fencedBlocks(md)
+	want := []codeBlock{
+		{Info: "go main.go", Body: "package main"},
+		{Info: "", Body: "raw ``` inside"},
+		{Info: "sh", Body: "unterminated"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("block %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if codeOnly("no code here") != "" {
+		t.Fatal("prose without fences must yield no code")
+	}
+}
@@ -2,9 +2,12 @@ package cli
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"text/template"
 	"time"
@@ -14,6 +17,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/chalkboard"
 	"github.com/jack-work/figaro/internal/config"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/tui"
 )
@@ -139,10 +143,62 @@ func buildPromptChalkboard() *rpc.ChalkboardInput {
 	for k, v := range chalkboard.EnvironmentSnapshot() {
 		snap[k] = v
 	}
-	if len(snap) == 0 {
+	var patch *rpc.ChalkboardPatch
+	if promptPersona != nil || promptReplyLang != "" || promptWebSearch != "" {
+		patch = &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{}}
+		if promptPersona != nil {
+			patch.Set = maps.Clone(promptPersona.Set)
+			patch.Remove = slices.Clone(promptPersona.Remove)
+		}
+		if promptReplyLang != "" {
+			b, _ := json.Marshal(promptReplyLang)
+			patch.Set[providerPkg.ReplyLangKey] = b
+		}
+		if promptWebSearch != "" {
+			b, _ := json.Marshal(promptWebSearch == "on")
+			patch.Set[providerPkg.WebSearchKey] = b
+		}
+	}
+	if len(snap) == 0 && patch == nil {
 		return nil
 	}
-	return &rpc.ChalkboardInput{Context: snap}
+	return &rpc.ChalkboardInput{Context: snap, Patch: patch}
+}
+
+// promptReplyLang is send --reply-lang: set on the aria with the prompt.
+var promptReplyLang string
+
+// promptWebSearch is send --web-search ("on" or "off"): set on the aria
+// with the prompt.
+var promptWebSearch string
+
+// promptPersona is send --persona's chalkboard patch (personaPatch).
+var promptPersona *rpc.ChalkboardPatch
+
+// promptChalkboard is buildPromptChalkboard plus the send's own patch.
+func promptChalkboard(set renderSettings) *rpc.ChalkboardInput {
+	cb := buildPromptChalkboard()
+	if set.patch == nil {
+		return cb
+	}
+	if cb == nil {
+		cb = &rpc.ChalkboardInput{}
+	}
+	if cb.Patch == nil {
+		cb.Patch = set.patch
+		return cb
+	}
+	merged := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{}}
+	maps.Copy(merged.Set, cb.Patch.Set)
+	maps.Copy(merged.Set, set.patch.Set)
+	for _, k := range cb.Patch.Remove { // a persona's clears, unless the send sets the key
+		if _, ok := set.patch.Set[k]; !ok {
+			merged.Remove = append(merged.Remove, k)
+		}
+	}
+	merged.Remove = append(merged.Remove, set.patch.Remove...)
+	cb.Patch = merged
+	return cb
 }
 
 // buildChalkboard loads body templates with user overrides.
@@ -164,17 +220,59 @@ func buildChalkboard() *template.Template {
 }
 
 // stateDir returns the directory for persistent figaro state
-// (OTel data, aria archives, aria chalkboards). XDG_STATE_HOME and
+// (OTel data and logs, tasks, bookmarks, ledgers). XDG_STATE_HOME and
 // FIGARO_STATE_DIR are honored to allow dev-shell isolation.
 func stateDir() string {
 	if d := os.Getenv("FIGARO_STATE_DIR"); d != "" {
 		return d
 	}
-	if d := os.Getenv("XDG_STATE_HOME"); d != "" {
-		return filepath.Join(d, "figaro")
+	return config.DefaultStateDir()
+}
+
+// dataDir returns the directory for conversations (the aria store).
+// FIGARO_DATA_DIR wins; a FIGARO_STATE_DIR without it keeps dev shells in
+// one tree; otherwise XDG_DATA_HOME.
+func dataDir() string {
+	if d := os.Getenv("FIGARO_DATA_DIR"); d != "" {
+		return d
+	}
+	if d := os.Getenv("FIGARO_STATE_DIR"); d != "" {
+		return d
+	}
+	return config.DefaultDataDir()
+}
+
+// ariaDir is the aria store. Stores from before the data dir lived under
+// the state dir; one still there is used until migrateAriaDir moves it.
+func ariaDir() string {
+	dir := filepath.Join(dataDir(), "arias")
+	legacy := filepath.Join(stateDir(), "arias")
+	if legacy != dir && !exists(dir) && exists(legacy) {
+		return legacy
+	}
+	return dir
+}
+
+// migrateAriaDir moves a store left at <state>/arias into the data dir.
+// The angelus runs it under the store lock, before opening the backend.
+// A store that cannot be renamed (another filesystem) stays where it is.
+func migrateAriaDir(state, data string) error {
+	from, to := filepath.Join(state, "arias"), filepath.Join(data, "arias")
+	if from == to || !exists(from) {
+		return nil
+	}
+	if exists(to) {
+		return fmt.Errorf("both %s and %s exist; using %s", from, to, to)
 	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".local", "state", "figaro")
+	if err := os.MkdirAll(data, 0o700); err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+func exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
 }
 
 // cacheDir returns the directory for ephemeral figaro data that can
@@ -184,9 +282,5 @@ func cacheDir() string {
 	if d := os.Getenv("FIGARO_CACHE_DIR"); d != "" {
 		return d
 	}
-	if d := os.Getenv("XDG_CACHE_HOME"); d != "" {
-		return filepath.Join(d, "figaro")
-	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".cache", "figaro")
+	return config.DefaultCacheDir()
 }
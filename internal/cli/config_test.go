@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestMigrateAriaDir(t *testing.T) {
+	base := t.TempDir()
+	state, data := filepath.Join(base, "state"), filepath.Join(base, "data")
+	writeTree(t, state, map[string]string{"arias/xwal.json": "{}"})
+	t.Setenv("FIGARO_STATE_DIR", state)
+	t.Setenv("FIGARO_DATA_DIR", data)
+
+	if got := ariaDir(); got != filepath.Join(state, "arias") {
+		t.Fatalf("before migration: ariaDir = %q", got)
+	}
+	if err := migrateAriaDir(state, data); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(data, "arias", "xwal.json")); got != "{}" {
+		t.Errorf("migrated manifest = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(state, "arias")); err == nil {
+		t.Error("old store left behind")
+	}
+	if got := ariaDir(); got != filepath.Join(data, "arias") {
+		t.Errorf("after migration: ariaDir = %q", got)
+	}
+
+	writeTree(t, state, map[string]string{"arias/xwal.json": "{}"})
+	if err := migrateAriaDir(state, data); err == nil {
+		t.Error("two stores: no error")
+	}
+	if err := migrateAriaDir(data, data); err != nil {
+		t.Errorf("same dir: %v", err)
+	}
+}
+
+func TestPromptChalkboardReplyLang(t *testing.T) {
+	defer func() { promptReplyLang = "" }()
+	promptReplyLang = "French"
+	retry := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.model": []byte(`"m"`)}}
+	cb := promptChalkboard(renderSettings{patch: retry})
+	if string(cb.Patch.Set[providerPkg.ReplyLangKey]) != `"French"` || string(cb.Patch.Set["system.model"]) != `"m"` {
+		t.Errorf("patch = %v", cb.Patch.Set)
+	}
+	if len(retry.Set) != 1 {
+		t.Error("promptChalkboard modified the send's own patch")
+	}
+
+	promptReplyLang = ""
+	if cb := buildPromptChalkboard(); cb != nil && cb.Patch != nil {
+		t.Errorf("no --reply-lang, patch = %v", cb.Patch.Set)
+	}
+}
+
+func TestPromptChalkboardWebSearch(t *testing.T) {
+	defer func() { promptWebSearch = "" }()
+	promptWebSearch = "off"
+	cb := buildPromptChalkboard()
+	if cb == nil || cb.Patch == nil || string(cb.Patch.Set[providerPkg.WebSearchKey]) != "false" {
+		t.Fatalf("--web-search off, chalkboard = %+v", cb)
+	}
+	promptWebSearch = "on"
+	if cb := buildPromptChalkboard(); string(cb.Patch.Set[providerPkg.WebSearchKey]) != "true" {
+		t.Errorf("--web-search on, patch = %v", cb.Patch.Set)
+	}
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/term"
+)
+
+// roleStats is one role's share of a conversation. Length is prose in
+// characters; tool results count as the "tool" role.
+type roleStats struct {
+	Role     string `json:"role"`
+	Messages int    `json:"messages"`
+	AvgLen   int    `json:"avg_len"`
+	MaxLen   int    `json:"max_len"`
+	total    int
+}
+
+// dayStats is the activity of one calendar day (local time).
+type dayStats struct {
+	Day      string `json:"day"`
+	Messages int    `json:"messages"`
+}
+
+// convStats summarizes one aria's log for `figaro stats -c`.
+type convStats struct {
+	ID         string         `json:"id"`
+	Roles      []roleStats    `json:"roles"`
+	Usage      message.Usage  `json:"usage"`
+	ToolCalls  int            `json:"tool_calls"`
+	Tools      map[string]int `json:"tools,omitempty"`
+	AvgLatency time.Duration  `json:"avg_latency_ns"`
+	MaxLatency time.Duration  `json:"max_latency_ns"`
+	Activity   []dayStats     `json:"activity"`
+	latencies  []time.Duration
+}
+
+// statsRole names the row a message counts toward, or "" for control turns
+// (chalkboard-only messages carry no content).
+func statsRole(m message.Message) string {
+	if len(m.Content) == 0 {
+		return ""
+	}
+	if m.Role == message.RoleUser {
+		for _, c := range m.Content {
+			if c.Type == message.ContentToolResult {
+				return "tool"
+			}
+		}
+	}
+	return string(m.Role)
+}
+
+// conversationStats folds an aria's messages, oldest first. A response's
+// latency runs from the message it answers (a prompt or tool results) to
+// the assistant message's timestamp.
+func conversationStats(id string, msgs []message.Message) convStats {
+	s := convStats{ID: id, Tools: map[string]int{}}
+	roles := map[string]*roleStats{}
+	var order []string
+	days := map[string]int{}
+	var asked int64
+	for _, m := range msgs {
+		role := statsRole(m)
+		if role == "" {
+			continue
+		}
+		r, ok := roles[role]
+		if !ok {
+			r = &roleStats{Role: role}
+			roles[role] = r
+			order = append(order, role)
+		}
+		n := 0
+		for _, c := range m.Content {
+			switch c.Type {
+			case message.ContentProse:
+				n += utf8.RuneCountInString(c.Text)
+			case message.ContentToolInvoke:
+				s.ToolCalls++
+				s.Tools[c.ToolName]++
+			}
+		}
+		r.Messages++
+		r.total += n
+		r.MaxLen = max(r.MaxLen, n)
+		if m.Usage != nil {
+			s.Usage.InputTokens += m.Usage.InputTokens
+			s.Usage.OutputTokens += m.Usage.OutputTokens
+			s.Usage.CacheReadTokens += m.Usage.CacheReadTokens
+			s.Usage.CacheWriteTokens += m.Usage.CacheWriteTokens
+		}
+		if m.Timestamp > 0 {
+			days[time.UnixMilli(m.Timestamp).Format(time.DateOnly)]++
+		}
+		switch {
+		case m.Role != message.RoleAssistant:
+			asked = m.Timestamp
+		case asked > 0 && m.Timestamp >= asked:
+			s.latencies = append(s.latencies, time.Duration(m.Timestamp-asked)*time.Millisecond)
+			asked = 0
+		}
+	}
+	for _, role := range order {
+		r := roles[role]
+		r.AvgLen = r.total / r.Messages
+		s.Roles = append(s.Roles, *r)
+	}
+	var sum time.Duration
+	for _, l := range s.latencies {
+		sum += l
+		s.MaxLatency = max(s.MaxLatency, l)
+	}
+	if len(s.latencies) > 0 {
+		s.AvgLatency = sum / time.Duration(len(s.latencies))
+	}
+	for day, n := range days {
+		s.Activity = append(s.Activity, dayStats{Day: day, Messages: n})
+	}
+	sort.Slice(s.Activity, func(i, j int) bool { return s.Activity[i].Day < s.Activity[j].Day })
+	return s
+}
+
+// readAllMessages reads an aria's whole log as messages.
+func readAllMessages(ctx context.Context, acli *angelus.Client, id string) ([]message.Message, error) {
+	resp, err := ariaReadAll(ctx, acli, id, 0)
+	if err != nil {
+		return nil, fmt.Errorf("aria.read: %w", err)
+	}
+	out := make([]message.Message, len(resp.Entries))
+	for i, e := range resp.Entries {
+		if err := json.Unmarshal(e.Payload, &out[i]); err != nil {
+			return nil, fmt.Errorf("aria.read: parse LT=%d: %w", e.LT, err)
+		}
+		out[i].LogicalTime = e.LT
+	}
+	return out, nil
+}
+
+// runConversationStats prints `figaro stats -c <id>`.
+func runConversationStats(loaded *config.Loaded, id string, asJSON bool) {
+	if err := rpc.ValidateAriaID(id); err != nil {
+		die("stats: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	msgs, err := readAllMessages(ctx, acli, id)
+	if err != nil {
+		die("stats: %s", err)
+	}
+	s := conversationStats(id, msgs)
+	if asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(s)
+		return
+	}
+	printConversationStats(os.Stdout, s, termWidth())
+}
+
+// statsBarWidth caps the activity chart's bars.
+const statsBarWidth = 40
+
+func printConversationStats(out io.Writer, s convStats, width int) {
+	fmt.Fprintf(out, "aria %s\n\n", s.ID)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROLE\tMESSAGES\tAVG LEN\tLONGEST")
+	for _, r := range s.Roles {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.Role, r.Messages, r.AvgLen, r.MaxLen)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "tokens\t%s in · %s out · %s cache read · %s cache write\n",
+		formatTokenCount(s.Usage.InputTokens), formatTokenCount(s.Usage.OutputTokens),
+		formatTokenCount(s.Usage.CacheReadTokens), formatTokenCount(s.Usage.CacheWriteTokens))
+	fmt.Fprintf(w, "tool calls\t%d%s\n", s.ToolCalls, topTools(s.Tools, 5))
+	if s.AvgLatency > 0 || s.MaxLatency > 0 {
+		fmt.Fprintf(w, "latency\t%s avg · %s longest\n", s.AvgLatency.Round(100*time.Millisecond), s.MaxLatency.Round(100*time.Millisecond))
+	}
+	w.Flush()
+
+	if len(s.Activity) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	peak := 0
+	for _, d := range s.Activity {
+		peak = max(peak, d.Messages)
+	}
+	bar := min(statsBarWidth, max(width-20, 1))
+	for _, d := range s.Activity {
+		n := max(d.Messages*bar/peak, 1)
+		fmt.Fprintf(out, "%s  %s %d\n", d.Day, term.Accent(strings.Repeat(term.Sym("█", "#"), n)), d.Messages)
+	}
+}
+
+// topTools is " (name n, ...)" for the most-called tools, or "".
+func topTools(tools map[string]int, n int) string {
+	if len(tools) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tools[names[i]] != tools[names[j]] {
+			return tools[names[i]] > tools[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, tools[name])
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
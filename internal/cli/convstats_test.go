@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func TestConversationStats(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local).UnixMilli()
+	next := time.Date(2026, 3, 3, 10, 0, 0, 0, time.Local).UnixMilli()
+	msgs := []message.Message{
+		{Role: message.RoleUser, Patches: []message.Patch{{}}},
+		{Role: message.RoleUser, Timestamp: day, Content: []message.Content{message.TextContent("list files")}},
+		{Role: message.RoleAssistant, Timestamp: day + 2000, Usage: &message.Usage{InputTokens: 100, OutputTokens: 10},
+			Content: []message.Content{{Type: message.ContentToolInvoke, ToolCallID: "c1", ToolName: "bash"}}},
+		{Role: message.RoleUser, Timestamp: day + 3000, Content: []message.Content{message.ToolResultContent("c1", "bash", "a b", false)}},
+		{Role: message.RoleAssistant, Timestamp: day + 7000, Usage: &message.Usage{InputTokens: 120, OutputTokens: 20, CacheReadTokens: 90},
+			Content: []message.Content{message.TextContent("two files")}},
+		{Role: message.RoleUser, Timestamp: next, Content: []message.Content{message.TextContent("ok")}},
+	}
+	s := conversationStats("ab12", msgs)
+
+	roles := map[string]roleStats{}
+	for _, r := range s.Roles {
+		roles[r.Role] = r
+	}
+	if u := roles["user"]; u.Messages != 2 || u.MaxLen != 10 || u.AvgLen != 6 {
+		t.Errorf("user = %+v", u)
+	}
+	if a := roles["assistant"]; a.Messages != 2 || a.MaxLen != 9 {
+		t.Errorf("assistant = %+v", a)
+	}
+	if roles["tool"].Messages != 1 || len(s.Roles) != 3 {
+		t.Errorf("roles = %+v", s.Roles)
+	}
+	if s.Usage.InputTokens != 220 || s.Usage.OutputTokens != 30 || s.Usage.CacheReadTokens != 90 {
+		t.Errorf("usage = %+v", s.Usage)
+	}
+	if s.ToolCalls != 1 || s.Tools["bash"] != 1 {
+		t.Errorf("tools = %d %v", s.ToolCalls, s.Tools)
+	}
+	if s.AvgLatency != 3*time.Second || s.MaxLatency != 4*time.Second {
+		t.Errorf("latency avg %s max %s", s.AvgLatency, s.MaxLatency)
+	}
+	if len(s.Activity) != 2 || s.Activity[0] != (dayStats{"2026-03-02", 4}) || s.Activity[1].Messages != 1 {
+		t.Errorf("activity = %+v", s.Activity)
+	}
+
+	var buf bytes.Buffer
+	printConversationStats(&buf, s, 80)
+	for _, want := range []string{"aria ab12", "assistant", "220 in", "1 (bash 1)", "3s avg", "2026-03-03"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
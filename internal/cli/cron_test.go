@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronRejectsBadExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",      // four fields
+		"60 * * * *",   // minute out of range
+		"* 24 * * *",   // hour out of range
+		"* * 0 * *",    // day of month starts at 1
+		"*/0 * * * *",  // zero step
+		"5-1 * * * *",  // backwards range
+		"x * * * *",    // not a number
+		"* * * 1-13 *", // month out of range
+		"* * * * 8",    // weekday out of range
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) accepted", expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	for _, c := range []struct {
+		expr, at string
+		want     bool
+	}{
+		{"0 9 * * 1", "2026-10-12 09:00", true},  // a Monday
+		{"0 9 * * 1", "2026-10-13 09:00", false}, // Tuesday
+		{"0 9 * * 1", "2026-10-12 09:01", false},
+		{"*/15 * * * *", "2026-10-12 13:45", true},
+		{"*/15 * * * *", "2026-10-12 13:46", false},
+		{"5/20 * * * *", "2026-10-12 13:45", true},
+		{"0 8-17/3 * * *", "2026-10-12 14:00", true},
+		{"0 8-17/3 * * *", "2026-10-12 15:00", false},
+		{"30 6 * * 0", "2026-10-18 06:30", true}, // Sunday as 0
+		{"30 6 * * 7", "2026-10-18 06:30", true}, // and as 7
+		{"0 0 1,15 * *", "2026-10-15 00:00", true},
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 1 * 1", "2026-10-12 00:00", true},
+		{"0 0 1 * 1", "2026-10-01 00:00", true},
+		{"0 0 1 * 1", "2026-10-02 00:00", false},
+		// A stepped star is still a star: the other day field must match too.
+		{"0 0 */2 * 1", "2026-10-12 00:00", false}, // a Monday, but an even day
+		{"0 0 */2 * 1", "2026-10-13 00:00", false}, // an odd day, but Tuesday
+		{"0 0 */2 * 1", "2026-10-19 00:00", true},
+		{"0 0 1 * */2", "2026-10-01 00:00", true},  // Thursday
+		{"0 0 1 * */2", "2026-10-06 00:00", false}, // Tuesday, but not the 1st
+	} {
+		spec, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", c.expr, err)
+		}
+		if got := spec.matches(at(c.at)); got != c.want {
+			t.Errorf("%q at %s = %v, want %v", c.expr, c.at, got, c.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from, _ := time.ParseInLocation("2006-01-02 15:04:05", "2026-10-15 10:30:20", time.Local)
+	for _, c := range []struct{ expr, want string }{
+		{"* * * * *", "2026-10-15 10:31"},
+		{"0 9 * * 1", "2026-10-19 09:00"},
+		{"15 10 * * *", "2026-10-16 10:15"},
+		{"0 0 1 1 *", "2027-01-01 00:00"},
+		{"0 0 29 2 *", "2028-02-29 00:00"},
+	} {
+		spec, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := spec.next(from).Format("2006-01-02 15:04"); got != c.want {
+			t.Errorf("next(%q) = %s, want %s", c.expr, got, c.want)
+		}
+	}
+	spec, _ := parseCron("0 0 31 2 *")
+	if got := spec.next(from); !got.IsZero() {
+		t.Errorf("Feb 31 should never fire, got %s", got)
+	}
+}
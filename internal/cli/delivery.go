@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// Response delivery: when a turn on an aria finishes, the daemon POSTs the
+// answer to every sink named in the aria's system.sink chalkboard key. The
+// sinks themselves are the config's [sinks.<name>] tables, read when the
+// daemon starts. Delivery rides on the agent's notification fan-out, so it
+// covers every turn the daemon runs — interactive, task or schedule.
+
+// sinkKey is the chalkboard key naming an aria's sinks: a name or a list.
+const sinkKey = "system.sink"
+
+// responseSink is one configured [sinks.<name>] endpoint.
+type responseSink struct {
+	name     string
+	url      string
+	slack    bool
+	markdown bool // webhook body is the bare answer
+	retries  int
+}
+
+// responseSinks are the daemon's sinks by name, set from config at startup.
+var responseSinks map[string]responseSink
+
+// sinkBackoff is the delay before the first retry; it doubles each time.
+var sinkBackoff = time.Second
+
+// applySinks installs the config [sinks] tables. A bad table is reported
+// and left out; the rest still deliver.
+func applySinks(tables map[string]config.Sink) []error {
+	var errs []error
+	sinks := map[string]responseSink{}
+	for name, t := range tables {
+		s, err := newResponseSink(name, t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sinks[name] = s
+	}
+	responseSinks = sinks
+	return errs
+}
+
+func newResponseSink(name string, t config.Sink) (responseSink, error) {
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return responseSink{}, fmt.Errorf("%s: url %q: want an http(s) URL", name, t.URL)
+	}
+	s := responseSink{name: name, url: t.URL, retries: 3}
+	switch t.Kind {
+	case "", "webhook":
+	case "slack":
+		s.slack = true
+	default:
+		return responseSink{}, fmt.Errorf("%s: kind %q: want webhook or slack", name, t.Kind)
+	}
+	switch t.Format {
+	case "", "json":
+	case "markdown":
+		s.markdown = true
+	default:
+		return responseSink{}, fmt.Errorf("%s: format %q: want json or markdown", name, t.Format)
+	}
+	if t.Retries != nil {
+		if *t.Retries < 0 {
+			return responseSink{}, fmt.Errorf("%s: retries %d: must not be negative", name, *t.Retries)
+		}
+		s.retries = *t.Retries
+	}
+	return s, nil
+}
+
+// sinkNames reads system.sink: a string or a list of strings.
+func sinkNames(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		if one = strings.TrimSpace(one); one != "" {
+			return []string{one}
+		}
+		return nil
+	}
+	var many []string
+	_ = json.Unmarshal(raw, &many)
+	return many
+}
+
+// deliveryPayload is a json-format webhook body.
+type deliveryPayload struct {
+	Aria     string `json:"aria"`
+	Reason   string `json:"reason"`
+	Markdown string `json:"markdown"`
+	Time     string `json:"time"`
+}
+
+// body is what s POSTs for one finished turn, and its content type. A
+// turn that wrote no prose delivers just its reason.
+func (s responseSink) body(ariaID, reason, answer string, at time.Time) ([]byte, string) {
+	switch {
+	case s.slack:
+		text := answer
+		if answer == "" {
+			text = fmt.Sprintf("_figaro %s: %s_", ariaID, reason)
+		} else if strings.HasPrefix(reason, "error:") {
+			text = fmt.Sprintf("_figaro %s: %s_\n\n%s", ariaID, reason, answer)
+		}
+		b, _ := json.Marshal(map[string]string{"text": text})
+		return b, "application/json"
+	case s.markdown:
+		if answer == "" {
+			return []byte(reason), "text/markdown; charset=utf-8"
+		}
+		return []byte(answer), "text/markdown; charset=utf-8"
+	}
+	b, _ := json.Marshal(deliveryPayload{Aria: ariaID, Reason: reason, Markdown: answer, Time: at.UTC().Format(time.RFC3339)})
+	return b, "application/json"
+}
+
+// post sends body, retrying transport errors, 429s and 5xx with
+// exponential backoff. Other statuses are final.
+func (s responseSink) post(ctx context.Context, client *http.Client, body []byte, contentType string) error {
+	delay := sinkBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = s.postOnce(ctx, client, body, contentType)
+		if err == nil {
+			return nil
+		}
+		var status *sinkStatusError
+		if attempt >= s.retries || (errors.As(err, &status) && !status.retryable()) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s responseSink) postOnce(ctx context.Context, client *http.Client, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "figaro")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return &sinkStatusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+// sinkStatusError is a non-2xx reply.
+type sinkStatusError struct{ code int }
+
+func (e *sinkStatusError) Error() string { return fmt.Sprintf("HTTP %d", e.code) }
+
+func (e *sinkStatusError) retryable() bool {
+	return e.code == http.StatusTooManyRequests || e.code >= 500
+}
+
+// lastAnswer is the prose of the newest assistant message.
+func lastAnswer(msgs []message.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != message.RoleAssistant {
+			continue
+		}
+		var parts []string
+		for _, c := range msgs[i].Content {
+			if c.Type == message.ContentProse && strings.TrimSpace(c.Text) != "" {
+				parts = append(parts, strings.TrimSpace(c.Text))
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, "\n\n")
+		}
+	}
+	return ""
+}
+
+// deliveryNotifier is the daemon's per-agent subscriber that hands each
+// finished turn to the aria's sinks.
+type deliveryNotifier struct {
+	ctx    context.Context
+	agent  *figaro.Agent
+	client *http.Client
+}
+
+// attachDelivery subscribes delivery to agent; wired as the daemon's
+// angelus.ServerConfig.OnAgent.
+func attachDelivery(ctx context.Context) func(*figaro.Agent) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	return func(a *figaro.Agent) {
+		a.Subscribe(&deliveryNotifier{ctx: ctx, agent: a, client: client})
+	}
+}
+
+// Notify runs under the agent's fan-out lock, so it only starts the
+// delivery; reading the log and posting happen on their own goroutine.
+func (d *deliveryNotifier) Notify(method string, params any) error {
+	if method != rpc.MethodTurnDone || len(responseSinks) == 0 {
+		return nil
+	}
+	done, _ := params.(rpc.DoneEntry)
+	go d.deliver(done)
+	return nil
+}
+
+// deliver posts the turn's answer — read from the messages it logged, not
+// the whole history, so a turn that failed before writing prose never
+// resends the previous answer.
+func (d *deliveryNotifier) deliver(done rpc.DoneEntry) {
+	names := sinkNames(d.agent.Snapshot()[sinkKey])
+	if len(names) == 0 {
+		return
+	}
+	reason := done.Reason
+	answer := lastAnswer(d.agent.ContextSince(done.From))
+	if answer == "" && !strings.HasPrefix(reason, "error:") && reason != "interrupted" {
+		return
+	}
+	now := time.Now()
+	for _, name := range names {
+		s, ok := responseSinks[name]
+		if !ok {
+			slog.Warn("sink: not configured", "aria", d.agent.ID(), "sink", name)
+			continue
+		}
+		body, ct := s.body(d.agent.ID(), reason, answer, now)
+		if err := s.post(d.ctx, d.client, body, ct); err != nil {
+			slog.Warn("sink: delivery failed", "aria", d.agent.ID(), "sink", name, "err", err)
+			continue
+		}
+		slog.Info("sink: delivered", "aria", d.agent.ID(), "sink", name)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func TestApplySinksValidates(t *testing.T) {
+	neg := -1
+	errs := applySinks(map[string]config.Sink{
+		"ok":     {URL: "https://example.com/hook"},
+		"slack":  {URL: "https://hooks.slack.com/services/x", Kind: "slack"},
+		"nourl":  {URL: "example.com"},
+		"kind":   {URL: "https://example.com", Kind: "pager"},
+		"format": {URL: "https://example.com", Format: "xml"},
+		"neg":    {URL: "https://example.com", Retries: &neg},
+	})
+	t.Cleanup(func() { responseSinks = nil })
+	if len(errs) != 4 {
+		t.Fatalf("errs = %v, want 4", errs)
+	}
+	if len(responseSinks) != 2 || !responseSinks["slack"].slack || responseSinks["ok"].retries != 3 {
+		t.Fatalf("sinks = %+v", responseSinks)
+	}
+}
+
+func TestSinkNames(t *testing.T) {
+	for raw, want := range map[string]int{
+		``:               0,
+		`""`:             0,
+		`"team"`:         1,
+		`["team","ops"]`: 2,
+		`42`:             0,
+	} {
+		if got := sinkNames(json.RawMessage(raw)); len(got) != want {
+			t.Errorf("sinkNames(%s) = %v, want %d names", raw, got, want)
+		}
+	}
+}
+
+func TestSinkBodies(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	b, ct := responseSink{}.body("ab12", "end_turn", "**hi**", at)
+	var p deliveryPayload
+	if err := json.Unmarshal(b, &p); err != nil || ct != "application/json" {
+		t.Fatalf("webhook body %s (%s): %v", b, ct, err)
+	}
+	if p != (deliveryPayload{Aria: "ab12", Reason: "end_turn", Markdown: "**hi**", Time: "2026-10-15T09:00:00Z"}) {
+		t.Errorf("payload = %+v", p)
+	}
+
+	if b, ct := (responseSink{markdown: true}).body("ab12", "end_turn", "**hi**", at); string(b) != "**hi**" || ct != "text/markdown; charset=utf-8" {
+		t.Errorf("markdown body %q (%s)", b, ct)
+	}
+
+	b, _ = responseSink{slack: true}.body("ab12", "error: boom", "", at)
+	var slack map[string]string
+	if json.Unmarshal(b, &slack) != nil || slack["text"] != "_figaro ab12: error: boom_" {
+		t.Errorf("slack body %s", b)
+	}
+	if b, _ := (responseSink{markdown: true}).body("ab12", "interrupted", "", at); string(b) != "interrupted" {
+		t.Errorf("markdown body without prose %q, want the reason", b)
+	}
+}
+
+func TestSinkPostRetries(t *testing.T) {
+	defer func(d time.Duration) { sinkBackoff = d }(sinkBackoff)
+	sinkBackoff = time.Millisecond
+
+	var calls atomic.Int32
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+	}))
+	defer srv.Close()
+
+	s := responseSink{url: srv.URL, retries: 3}
+	if err := s.post(context.Background(), srv.Client(), []byte("answer"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 || got != "answer" {
+		t.Errorf("calls = %d, body %q; want 3 and the answer", calls.Load(), got)
+	}
+
+	calls.Store(-100) // keep failing
+	s.retries = 1
+	if err := s.post(context.Background(), srv.Client(), nil, "text/plain"); err == nil || calls.Load() != -98 {
+		t.Errorf("retries=1: err %v after %d calls, want failure after 2", err, calls.Load()+100)
+	}
+}
+
+func TestSinkPostDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	err := responseSink{url: srv.URL, retries: 3}.post(context.Background(), srv.Client(), nil, "text/plain")
+	if err == nil || err.Error() != "HTTP 404" || calls.Load() != 1 {
+		t.Errorf("err %v after %d calls, want HTTP 404 once", err, calls.Load())
+	}
+}
+
+func TestLastAnswer(t *testing.T) {
+	msgs := []message.Message{
+		{Role: message.RoleAssistant, Content: []message.Content{message.TextContent("old")}},
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent("q")}},
+		{Role: message.RoleAssistant, Content: []message.Content{
+			{Type: message.ContentThinking, Text: "hmm"},
+			message.TextContent("first"),
+			{Type: message.ContentToolInvoke, ToolName: "bash"},
+			message.TextContent("second"),
+		}},
+		{Role: message.RoleUser, Content: []message.Content{message.ToolResultContent("1", "bash", "out", false)}},
+	}
+	if got := lastAnswer(msgs); got != "first\n\nsecond" {
+		t.Errorf("lastAnswer = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/term"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+const doInstruction = "Answer with exactly ONE shell command for bash that does what is asked. " +
+	"Put the command alone on the first line, with no code fences and no prompt sigil."
+
+const doExplain = " Then a blank line, then at most three short lines explaining what the command does."
+
+const doTerse = " Output nothing else."
+
+// doOpts is the parsed flag state of `figaro do`.
+type doOpts struct {
+	explain bool // --explain / -e: ask for a short explanation under the command
+	yes     bool // --yes / -y: run without confirming
+	dryRun  bool // --dry-run / -n: print the command, never run it
+}
+
+// runDo asks a fresh aria for a single shell command, shows it, and runs it
+// through bash -c once confirmed. The aria is a normal persisted one (not
+// bound to this shell), so the exchange can be revisited with show/attend.
+func runDo(loaded *config.Loaded, o doOpts, request string) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	acli := mustConnectAngelus(loaded)
+	createResp, err := createWithFirstRun(ctx, loaded, func() (*rpc.CreateResponse, error) { return acli.Create(ctx, "", nil) })
+	acli.Close()
+	if err != nil {
+		die("create figaro: %s", err)
+	}
+	ep := transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
+	if err := waitForSocket(ep.Address, 3*time.Second); err != nil {
+		die("do: %s", err)
+	}
+
+	prompt := doInstruction + doTerse
+	if o.explain {
+		prompt = doInstruction + doExplain
+	}
+	prompt += "\n\nRequest: " + expandAtRefsForEndpoint(ctx, ep, request)
+
+	var buf bytes.Buffer
+	if code := plainPrompt(ctx, ep, prompt, &buf); code != 0 {
+		os.Exit(code)
+	}
+	command, explanation := splitDoAnswer(buf.String())
+	if command == "" {
+		die("do: empty command from agent (see: figaro show %s)", createResp.FigaroID)
+	}
+
+	if o.dryRun || (!o.yes && !term.IsTerminal(int(os.Stdin.Fd()))) {
+		fmt.Println(command)
+		if explanation != "" {
+			fmt.Fprintln(os.Stderr, explanation)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\x1b[1m$ %s\x1b[0m\n", command)
+	if explanation != "" {
+		fmt.Fprintf(os.Stderr, "\x1b[2m%s\x1b[0m\n", explanation)
+	}
+	if !o.yes {
+		fmt.Fprint(os.Stderr, "run it? [y/N] ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(line)); a != "y" && a != "yes" {
+			fmt.Fprintf(os.Stderr, "not run (aria %s)\n", createResp.FigaroID)
+			return
+		}
+	}
+	sh := exec.Command("bash", "-c", command)
+	sh.Stdin, sh.Stdout, sh.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := sh.Run(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			os.Exit(ee.ExitCode())
+		}
+		die("do: bash: %s", err)
+	}
+}
+
+// splitDoAnswer separates the command from any explanation around it. The
+// command is the whole first fenced block when the answer has one (a
+// continued or multi-line command stays intact), else the first non-blank
+// line; a leading "$ " is stripped from each of its lines.
+func splitDoAnswer(answer string) (command, explanation string) {
+	lines := strings.Split(strings.TrimSpace(answer), "\n")
+	start, end := -1, len(lines)
+	for i, l := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(l), "```") {
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		end = i
+		break
+	}
+	if start < 0 {
+		for i, l := range lines {
+			if l = strings.TrimSpace(l); l != "" {
+				return strings.TrimSpace(strings.TrimPrefix(l, "$ ")), strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+			}
+		}
+		return "", ""
+	}
+	body := lines[start+1 : end]
+	for i, l := range body {
+		body[i] = strings.TrimPrefix(l, "$ ")
+	}
+	command = strings.TrimSpace(strings.Join(body, "\n"))
+	rest := append(lines[:start:start], lines[min(end+1, len(lines)):]...)
+	explanation = strings.TrimSpace(strings.ReplaceAll(strings.Join(rest, "\n"), "```", ""))
+	return command, explanation
+}
@@ -0,0 +1,21 @@
+package cli
+
+import "testing"
+
+func TestSplitDoAnswer(t *testing.T) {
+	cases := []struct{ in, cmd, why string }{
+		{"find . -size +100M\n", "find . -size +100M", ""},
+		{"```bash\n$ du -sh *\n```", "du -sh *", ""},
+		{"\nls -la\n\nLists every file,\nhidden ones too.", "ls -la", "Lists every file,\nhidden ones too."},
+		{"  \n", "", ""},
+		{"```bash\nfor f in *.log; do\n  gzip \"$f\"\ndone\n```\nCompresses each log.", "for f in *.log; do\n  gzip \"$f\"\ndone", "Compresses each log."},
+		{"Run this:\n```\ntar czf out.tgz \\\n  src/\n```", "tar czf out.tgz \\\n  src/", "Run this:"},
+		{"```sh\n$ make\n$ make install", "make\nmake install", ""},
+	}
+	for _, c := range cases {
+		cmd, why := splitDoAnswer(c.in)
+		if cmd != c.cmd || why != c.why {
+			t.Errorf("splitDoAnswer(%q) = (%q, %q), want (%q, %q)", c.in, cmd, why, c.cmd, c.why)
+		}
+	}
+}
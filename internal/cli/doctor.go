@@ -1,17 +1,296 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/budget"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/outfit"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+	"github.com/jack-work/figaro/internal/term"
 	"github.com/jack-work/figaro/internal/transport"
 )
 
+// `figaro doctor` checks the setup end to end: config and default
+// loadout, each provider's credential (by listing its models, the
+// cheapest authenticated call), the state and runtime directories, the
+// daemon, and the terminal. Each finding that did not pass carries the
+// step that fixes it.
+
+// check is one doctor finding.
+type check struct {
+	Status string `json:"status"` // checkOK, checkWarn or checkFail
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorConfigErr is the config load error doctor reports. Every other
+// command dies on it before it runs.
+var doctorConfigErr error
+
+// doctorTimeout bounds each provider's credential check.
+const doctorTimeout = 10 * time.Second
+
+// loadConfigFor loads the config, dying on a broken one unless the
+// command is doctor, which runs on defaults and reports it.
+func loadConfigFor(args []string) *config.Loaded {
+	dir := config.DefaultConfigDir()
+	loaded, err := config.Load(dir)
+	if err == nil {
+		return loaded
+	}
+	if len(args) == 0 || args[0] != "doctor" {
+		die("config: %s", err)
+	}
+	doctorConfigErr = err
+	return &config.Loaded{ConfigDir: dir, ConfigPath: filepath.Join(dir, "config.toml")}
+}
+
+// runDoctor runs every check and prints them; it fails when any check
+// failed.
+func runDoctor(loaded *config.Loaded, asJSON bool) error {
+	checks := configChecks(loaded)
+	lc, provider := loadoutCheck(loaded)
+	checks = append(checks, lc)
+	checks = append(checks, credentialChecks(loaded, provider)...)
+	checks = append(checks,
+		dirCheck("state dir", stateDir(), "FIGARO_STATE_DIR"),
+		dirCheck("data dir", dataDir(), "FIGARO_DATA_DIR"),
+		dirCheck("runtime dir", angelusRuntimeDir(), "FIGARO_RUNTIME_DIR"),
+		ledgerCheck(budgetFile),
+		daemonCheck(),
+	)
+	checks = append(checks, terminalChecks(os.Getenv, term.IsTerminal(int(os.Stdout.Fd())))...)
+
+	if asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(checks)
+	} else {
+		printChecks(os.Stdout, checks)
+	}
+	failed := 0
+	for _, c := range checks {
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// printChecks writes one line per check, with the fix under any that
+// did not pass.
+func printChecks(w io.Writer, checks []check) {
+	for _, c := range checks {
+		status := fmt.Sprintf("%-4s", c.Status)
+		switch c.Status {
+		case checkOK:
+			status = term.Green(status)
+		case checkFail:
+			status = term.Red(status)
+		}
+		fmt.Fprintf(w, "%s  %-14s %s\n", status, c.Name, c.Detail)
+		if c.Status != checkOK && c.Fix != "" {
+			fmt.Fprintf(w, "%21sfix: %s\n", "", c.Fix)
+		}
+	}
+}
+
+// configChecks reports a config that failed to parse, and the sections
+// startup warns about and then ignores.
+func configChecks(loaded *config.Loaded) []check {
+	if doctorConfigErr != nil {
+		return []check{{checkFail, "config", doctorConfigErr.Error(), "correct the TOML in " + loaded.ConfigPath}}
+	}
+	var problems []string
+	note := func(section string, list []error) {
+		for _, err := range list {
+			problems = append(problems, fmt.Sprintf("[%s] %s", section, err))
+		}
+	}
+	note("theme", applyTheme(loaded.Config.Theme))
+	note("keys", applyKeymap(loaded.Config.Keys))
+	note("notify", applyNotify(loaded.Config.Notify))
+	_, promptErrs := buildPromptGuard(loaded.Config.Guard)
+	note("guard", promptErrs)
+	_, guardErrs := buildResponseGuard(loaded.Config.Guard)
+	note("guard", guardErrs)
+	if len(problems) > 0 {
+		return []check{{checkWarn, "config", strings.Join(problems, "; "), "edit " + loaded.ConfigPath + "; these settings are ignored until fixed"}}
+	}
+	detail := loaded.ConfigPath
+	if _, err := os.Stat(loaded.ConfigPath); os.IsNotExist(err) {
+		detail += " (absent; using defaults)"
+	}
+	return []check{{checkOK, "config", detail, ""}}
+}
+
+// loadoutCheck checks the default loadout resolves and names a provider,
+// which it returns.
+func loadoutCheck(loaded *config.Loaded) (check, string) {
+	name := loaded.Config.DefaultLoadout
+	if name == "" {
+		return check{checkWarn, "loadout", "no default_loadout", "run `figaro new` for first-run setup, or set default_loadout in " + loaded.ConfigPath}, ""
+	}
+	patch, err := outfit.New(loaded.ConfigDir).Load(name)
+	if err != nil {
+		return check{checkFail, "loadout", fmt.Sprintf("%s: %s", name, err), "correct " + loaded.LoadoutPath(name)}, ""
+	}
+	var provider string
+	_ = json.Unmarshal(patch.Set["system.provider"], &provider)
+	if provider == "" {
+		return check{checkFail, "loadout", name + " has no system.provider", "set system.provider in " + loaded.LoadoutPath(name)}, ""
+	}
+	return check{checkOK, "loadout", fmt.Sprintf("%s (provider %s)", name, provider), ""}, provider
+}
+
+// credentialChecks pings every provider with a credential on disk, and
+// the default loadout's.
+func credentialChecks(loaded *config.Loaded, provider string) []check {
+	names := loaded.ListProviders()
+	if provider != "" && !slices.Contains(names, provider) {
+		names = append(names, provider)
+	}
+	if len(names) == 0 {
+		return []check{{checkFail, "credentials", "no provider configured", loginFix(providerPkg.Names()...)}}
+	}
+	ensureHush()
+	out := make([]check, 0, len(names))
+	for _, name := range names {
+		out = append(out, credentialCheck(loaded, name))
+	}
+	return out
+}
+
+func credentialCheck(loaded *config.Loaded, name string) check {
+	if providerPkg.Lookup(name) == nil {
+		return check{checkFail, name, "unknown provider", "remove " + loaded.ProviderAuthPath(name)}
+	}
+	prov, _ := buildProvider(loaded, name)
+	if prov == nil {
+		return check{checkFail, name, "could not build the provider", "check " + loaded.ProviderAuthPath(name)}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	models, err := prov.Models(ctx)
+	if err != nil {
+		kind := errs.KindOf(err)
+		fix := strings.TrimPrefix(errorHint(kind, err.Error()), "hint: ")
+		if kind == errs.Auth {
+			fix = loginFix(name)
+		}
+		return check{checkFail, name, err.Error(), fix}
+	}
+	return check{checkOK, name, fmt.Sprintf("credential accepted (%d models)", len(models)), ""}
+}
+
+// loginFix lists the ways to give the named providers a credential.
+func loginFix(names ...string) string {
+	var ways []string
+	for _, name := range names {
+		reg := providerPkg.Lookup(name)
+		if reg == nil {
+			continue
+		}
+		if reg.HasOAuth {
+			ways = append(ways, "figaro login "+name)
+		}
+		if reg.EnvVar != "" {
+			ways = append(ways, "export "+reg.EnvVar)
+		}
+	}
+	return strings.Join(ways, ", or ")
+}
+
+// dirCheck creates dir if needed and writes a probe file into it.
+func dirCheck(name, dir, envVar string) check {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return check{checkFail, name, err.Error(), "make it creatable, or set " + envVar + " to a writable directory"}
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return check{checkFail, name, dir + " is not writable", "chmod u+w " + dir + ", or set " + envVar + " to a writable directory"}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return check{checkOK, name, dir, ""}
+}
+
+// ledgerCheck reads the usage ledger budgets and stats are built on.
+func ledgerCheck(path string) check {
+	if _, err := budget.Open(path); err != nil {
+		return check{checkFail, "usage ledger", err.Error(), "move " + path + " aside; a fresh one starts empty"}
+	}
+	return check{checkOK, "usage ledger", path, ""}
+}
+
+func daemonCheck() check {
+	cli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath()))
+	if err != nil {
+		return check{checkOK, "angelus", "not running; the first prompt starts it", ""}
+	}
+	cli.Close()
+	return check{checkOK, "angelus", "running at " + angelusSocketPath(), ""}
+}
+
+// terminalChecks reports color depth (the renderer emits 24-bit color)
+// and any inline image protocol the terminal advertises.
+func terminalChecks(env func(string) string, tty bool) []check {
+	if !tty {
+		return []check{{checkWarn, "terminal", "stdout is not a terminal; skipped", "run doctor in the terminal figaro is used from"}}
+	}
+	out := make([]check, 0, 2)
+	switch ct := strings.ToLower(env("COLORTERM")); ct {
+	case "truecolor", "24bit":
+		out = append(out, check{checkOK, "color", "truecolor", ""})
+	default:
+		out = append(out, check{checkWarn, "color", fmt.Sprintf("COLORTERM=%q; figaro renders 24-bit color", ct),
+			"export COLORTERM=truecolor if the terminal supports it, or use one that does"})
+	}
+	images := imageProtocol(env)
+	if images == "" {
+		images = "none detected"
+	}
+	return append(out, check{checkOK, "images", images, ""})
+}
+
+// imageProtocol names the inline image protocol env advertises; "" when
+// none.
+func imageProtocol(env func(string) string) string {
+	termName, program := env("TERM"), env("TERM_PROGRAM")
+	switch {
+	case termName == "xterm-kitty" || env("KITTY_WINDOW_ID") != "":
+		return "kitty graphics"
+	case termName == "xterm-ghostty" || program == "ghostty":
+		return "kitty graphics (Ghostty)"
+	case program == "WezTerm":
+		return "iTerm2 inline images (WezTerm)"
+	case program == "iTerm.app":
+		return "iTerm2 inline images"
+	}
+	return ""
+}
+
 // deadChannels are store channels no current code reads or writes:
 // translations/* was replaced by translations-v2/*, turn-wal by drain +
 // tail repair, _live by the transcript pivot. GC edits the figwal
@@ -22,19 +301,35 @@ func deadChannel(name string) bool {
 		(strings.HasPrefix(name, "translations/") && !strings.HasPrefix(name, "translations-v2/"))
 }
 
-func runDoctorGC(dryRun bool) error {
+// requireAngelusStopped fails while the daemon holds the store open.
+func requireAngelusStopped() error {
 	if cli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath())); err == nil {
 		cli.Close()
 		return fmt.Errorf("angelus is running; stop it first (figaro stop)")
 	}
-	root := filepath.Join(stateDir(), "arias")
+	return nil
+}
+
+func runDoctorGC(dryRun bool) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	root := ariaDir()
+	if _, err := os.Stat(filepath.Join(root, "xwal.json")); os.IsNotExist(err) {
+		fmt.Println("no store; nothing to do")
+		return nil
+	}
+	if err := gcChannels(root, dryRun); err != nil {
+		return err
+	}
+	return gcBlobs(root, dryRun)
+}
+
+// gcChannels drops dead channels from the store manifest and disk.
+func gcChannels(root string, dryRun bool) error {
 	manPath := filepath.Join(root, "xwal.json")
 	raw, err := os.ReadFile(manPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Println("no store; nothing to do")
-			return nil
-		}
 		return err
 	}
 	var man map[string]json.RawMessage
@@ -62,7 +357,7 @@ func runDoctorGC(dryRun bool) error {
 		}
 	}
 	if len(dead) == 0 {
-		fmt.Println("store clean; nothing to do")
+		fmt.Println("no dead channels")
 		return nil
 	}
 
@@ -101,6 +396,29 @@ func runDoctorGC(dryRun bool) error {
 	return nil
 }
 
+// gcBlobs removes stored content no conversation refers to any more. It
+// reads every record in the store, which is why it waits for doctor gc.
+func gcBlobs(root string, dryRun bool) error {
+	b, err := store.NewXwalBackend(root)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	n, freed, err := b.SweepBlobs(dryRun)
+	if err != nil {
+		return fmt.Errorf("blob sweep: %w", err)
+	}
+	switch {
+	case n == 0:
+		fmt.Println("no unreferenced content")
+	case dryRun:
+		fmt.Printf("would remove %d unreferenced blob(s) (%s)\n", n, fmtBytes(freed))
+	default:
+		fmt.Printf("removed %d unreferenced blob(s), freed %s\n", n, fmtBytes(freed))
+	}
+	return nil
+}
+
 func contains(xs []string, s string) bool {
 	for _, x := range xs {
 		if x == s {
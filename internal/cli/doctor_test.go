@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func envOf(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestTerminalChecks(t *testing.T) {
+	got := terminalChecks(envOf(map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-kitty"}), true)
+	if len(got) != 2 || got[0].Status != checkOK || got[1].Detail != "kitty graphics" {
+		t.Errorf("kitty: %+v", got)
+	}
+	got = terminalChecks(envOf(map[string]string{"TERM": "xterm-256color"}), true)
+	if got[0].Status != checkWarn || !strings.Contains(got[0].Fix, "COLORTERM=truecolor") || got[1].Detail != "none detected" {
+		t.Errorf("256 color: %+v", got)
+	}
+	got = terminalChecks(envOf(nil), false)
+	if len(got) != 1 || got[0].Status != checkWarn {
+		t.Errorf("not a tty: %+v", got)
+	}
+}
+
+func TestImageProtocol(t *testing.T) {
+	for env, want := range map[string]string{
+		"TERM=xterm-kitty":        "kitty graphics",
+		"KITTY_WINDOW_ID=1":       "kitty graphics",
+		"TERM_PROGRAM=ghostty":    "kitty graphics (Ghostty)",
+		"TERM_PROGRAM=WezTerm":    "iTerm2 inline images (WezTerm)",
+		"TERM_PROGRAM=iTerm.app":  "iTerm2 inline images",
+		"TERM_PROGRAM=Apple_Term": "",
+	} {
+		k, v, _ := strings.Cut(env, "=")
+		if got := imageProtocol(envOf(map[string]string{k: v})); got != want {
+			t.Errorf("%s: %q, want %q", env, got, want)
+		}
+	}
+}
+
+func TestDirCheck(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if c := dirCheck("state dir", dir, "FIGARO_STATE_DIR"); c.Status != checkOK {
+		t.Errorf("fresh dir: %+v", c)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("probe left behind: %v", entries)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o600)
+	c := dirCheck("state dir", filepath.Join(file, "sub"), "FIGARO_STATE_DIR")
+	if c.Status != checkFail || !strings.Contains(c.Fix, "FIGARO_STATE_DIR") {
+		t.Errorf("under a file: %+v", c)
+	}
+}
+
+func TestLedgerCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	if c := ledgerCheck(path); c.Status != checkOK {
+		t.Errorf("missing ledger: %+v", c)
+	}
+	os.WriteFile(path, []byte("{not json"), 0o600)
+	if c := ledgerCheck(path); c.Status != checkFail {
+		t.Errorf("corrupt ledger: %+v", c)
+	}
+}
+
+func TestLoadoutCheck(t *testing.T) {
+	dir := t.TempDir()
+	loaded := &config.Loaded{ConfigDir: dir, ConfigPath: filepath.Join(dir, "config.toml")}
+	if c, _ := loadoutCheck(loaded); c.Status != checkWarn {
+		t.Errorf("no default: %+v", c)
+	}
+
+	loaded.Config.DefaultLoadout = "main"
+	os.MkdirAll(loaded.LoadoutsDir(), 0o700)
+	os.WriteFile(loaded.LoadoutPath("main"), []byte("system = { model = \"m\" }\n"), 0o600)
+	if c, _ := loadoutCheck(loaded); c.Status != checkFail || !strings.Contains(c.Detail, "no system.provider") {
+		t.Errorf("no provider: %+v", c)
+	}
+
+	os.WriteFile(loaded.LoadoutPath("main"), []byte("system = { provider = \"anthropic\" }\n"), 0o600)
+	c, provider := loadoutCheck(loaded)
+	if c.Status != checkOK || provider != "anthropic" {
+		t.Errorf("ok: %+v, %q", c, provider)
+	}
+}
+
+func TestPrintChecks(t *testing.T) {
+	var b bytes.Buffer
+	printChecks(&b, []check{
+		{checkOK, "config", "/etc/figaro/config.toml", ""},
+		{checkFail, "anthropic", "anthropic: invalid x-api-key (401)", "figaro login anthropic"},
+	})
+	want := "ok    config         /etc/figaro/config.toml\n" +
+		"fail  anthropic      anthropic: invalid x-api-key (401)\n" +
+		"                     fix: figaro login anthropic\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
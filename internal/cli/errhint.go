@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/jkrpc"
+)
+
+// Failures the daemon classifies (internal/errs) reach the CLI with their
+// kind: on a turn.done as DoneEntry.Kind, or as an ErrClassified JSON-RPC
+// error. The message is printed as is, followed by what to do about it.
+
+// errorHint is the next step for a failure of kind; "" when there is none.
+func errorHint(kind errs.Kind, msg string) string {
+	switch kind {
+	case errs.Auth:
+		if strings.Contains(msg, "no credential") {
+			return providerSetupHint()
+		}
+		return "hint: the provider rejected the credential; sign in again with `figaro login <provider>`"
+	case errs.RateLimit:
+		return "hint: the provider is rate limiting; wait a minute and retry, or add rate_limit to [middleware] to pace requests"
+	case errs.Overloaded:
+		return "hint: the provider is overloaded; retry shortly"
+	case errs.ContextLength:
+		return "hint: the conversation no longer fits the model; start over with `figaro new`, or `figaro fork <id>:<LT>` from an earlier turn"
+	case errs.Network:
+		return "hint: the provider could not be reached; check the network and retry"
+	case errs.Budget:
+		return "hint: see `figaro stats`; pass --force to send anyway, or raise [budget] in config"
+	}
+	return ""
+}
+
+// printFailure writes msg and, when kind has one, its hint.
+func printFailure(w io.Writer, msg string, kind errs.Kind) {
+	fmt.Fprintln(w, msg)
+	if h := errorHint(kind, msg); h != "" {
+		fmt.Fprintln(w, strings.TrimRight(h, "\n"))
+	}
+}
+
+// rpcFailure is the message and kind of an error from a figaro call. A
+// classified error's message is the daemon's, without the JSON-RPC
+// framing.
+func rpcFailure(err error) (string, errs.Kind) {
+	var jerr *jkrpc.Error
+	if data, code, ok := decodeTypedError(err); ok && code == rpc.ErrClassified && errors.As(err, &jerr) {
+		return jerr.Message, errs.Kind(data.Kind)
+	}
+	return err.Error(), errs.KindOf(err)
+}
+
+// diePrompt reports a prompt the daemon refused, with its hint, and
+// exits 1.
+func diePrompt(err error) {
+	msg, kind := rpcFailure(err)
+	printFailure(os.Stderr, "error: prompt: "+msg, kind)
+	os.Exit(1)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/jkrpc"
+)
+
+func TestRPCFailure(t *testing.T) {
+	data, _ := json.Marshal(rpc.ErrorData{Kind: string(errs.Budget)})
+	classified := fmt.Errorf("qua: %w", &jkrpc.Error{Code: rpc.ErrClassified, Message: "daily budget of $1.00 reached", Data: data})
+	msg, kind := rpcFailure(classified)
+	if msg != "daily budget of $1.00 reached" || kind != errs.Budget {
+		t.Errorf("classified = %q, %q", msg, kind)
+	}
+
+	msg, kind = rpcFailure(&jkrpc.Error{Code: -32000, Message: "boom"})
+	if msg != "jsonrpc error -32000: boom" || kind != "" {
+		t.Errorf("unclassified = %q, %q", msg, kind)
+	}
+
+	msg, kind = rpcFailure(errs.Wrap(errs.Network, errors.New("dial: refused")))
+	if msg != "dial: refused" || kind != errs.Network {
+		t.Errorf("local = %q, %q", msg, kind)
+	}
+}
+
+func TestPrintFailure(t *testing.T) {
+	var b bytes.Buffer
+	printFailure(&b, "error: anthropic: slow down (429)", errs.RateLimit)
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "error: anthropic: slow down (429)" || !strings.HasPrefix(lines[1], "hint: ") {
+		t.Errorf("rate limit:\n%s", b.String())
+	}
+
+	b.Reset()
+	printFailure(&b, "error: boom", "")
+	if b.String() != "error: boom\n" {
+		t.Errorf("unclassified: %q", b.String())
+	}
+
+	b.Reset()
+	printFailure(&b, "error: resolve token: no credential available", errs.Auth)
+	if !strings.Contains(b.String(), "No provider connected") {
+		t.Errorf("missing credential should show provider setup:\n%s", b.String())
+	}
+	b.Reset()
+	printFailure(&b, "error: anthropic: invalid x-api-key (401)", errs.Auth)
+	if !strings.Contains(b.String(), "figaro login") {
+		t.Errorf("rejected credential should point at login:\n%s", b.String())
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// extractOpts is the parsed flag state of `figaro extract`.
+type extractOpts struct {
+	id     string
+	dir    string // -o: root the hinted paths here (default cwd)
+	diff   bool   // --diff: apply unified-diff blocks with git apply
+	dryRun bool   // -n: report what would be written/applied
+}
+
+// runExtract pulls the fenced code blocks out of the aria's latest assistant
+// answer. Blocks whose info string carries a path (```go cmd/x.go) are written
+// there; with --diff, diff blocks are applied instead. Unhinted blocks go to
+// stdout so the command still pipes.
+func runExtract(loaded *config.Loaded, o extractOpts) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+
+	ariaID := o.id
+	if ariaID == "" {
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err != nil {
+			die("resolve: %s", err)
+		}
+		if !r.Found {
+			die("no figaro bound to this shell")
+		}
+		ariaID = r.FigaroID
+	}
+	lt, text, ok, err := findLastProse(ctx, acli, ariaID, message.RoleAssistant)
+	if err != nil {
+		die("extract: %s", err)
+	}
+	if !ok {
+		die("extract: %s has no answer yet", ariaID)
+	}
+	blocks := fencedBlocks(text)
+	if len(blocks) == 0 {
+		die("extract: the answer at LT %d has no code blocks", lt)
+	}
+
+	root := o.dir
+	if root == "" {
+		root = "."
+	}
+	var loose []string
+	for _, b := range blocks {
+		if o.diff && isDiffBlock(b) {
+			if err := applyDiff(root, b.Body, o.dryRun); err != nil {
+				die("extract: %s", err)
+			}
+			continue
+		}
+		path := blockPath(b.Info)
+		if path == "" {
+			loose = append(loose, b.Body)
+			continue
+		}
+		if !filepath.IsLocal(path) {
+			die("extract: refusing path %q (must stay under %s)", path, root)
+		}
+		dst := filepath.Join(root, path)
+		if o.dryRun {
+			fmt.Fprintf(os.Stderr, "would write %s (%d lines)\n", dst, lineCount(b.Body))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			die("extract: %s", err)
+		}
+		if err := os.WriteFile(dst, []byte(b.Body+"\n"), 0o644); err != nil {
+			die("extract: %s", err)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s (%d lines)\n", dst, lineCount(b.Body))
+	}
+	if len(loose) > 0 {
+		fmt.Println(strings.Join(loose, "\n\n"))
+	}
+}
+
+// blockPath returns the path hint in a fence info string, or "". Both
+// "go cmd/x.go" and "go:cmd/x.go" name a file; a bare language does not.
+func blockPath(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	cand := fields[len(fields)-1]
+	if i := strings.Index(cand, ":"); i >= 0 && len(fields) == 1 {
+		cand = cand[i+1:]
+	}
+	if strings.Contains(cand, "/") || (filepath.Ext(cand) != "" && !strings.HasPrefix(cand, ".")) {
+		return cand
+	}
+	return ""
+}
+
+// isDiffBlock reports whether b holds a unified diff: tagged diff/patch, or
+// opening with git's or diff -u's file header.
+func isDiffBlock(b codeBlock) bool {
+	if f := strings.Fields(b.Info); len(f) > 0 && (f[0] == "diff" || f[0] == "patch") {
+		return true
+	}
+	return strings.HasPrefix(b.Body, "diff --git ") || strings.HasPrefix(b.Body, "--- ")
+}
+
+// applyDiff feeds one unified diff to git apply under root. --recount lets
+// model-written hunks with miscounted headers still land.
+func applyDiff(root, diff string, check bool) error {
+	args := []string{"apply", "--recount"}
+	if check {
+		args = append(args, "--check")
+	}
+	cmd := exec.Command("git", append(args, "-")...)
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(diff + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(stderr.String()))
+	}
+	if check {
+		fmt.Fprintln(os.Stderr, "diff applies cleanly")
+	} else {
+		fmt.Fprintln(os.Stderr, "applied diff")
+	}
+	return nil
+}
+
+func lineCount(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
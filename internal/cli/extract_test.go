@@ -0,0 +1,32 @@
+package cli
+
+import "testing"
+
+func TestBlockPath(t *testing.T) {
+	cases := map[string]string{
+		"go cmd/x.go":    "cmd/x.go",
+		"go:cmd/x.go":    "cmd/x.go",
+		"main.go":        "main.go",
+		"go":             "",
+		"":               "",
+		"sh .bashrc":     "",
+		"python app/run": "app/run",
+	}
+	for info, want := range cases {
+		if got := blockPath(info); got != want {
+			t.Errorf("blockPath(%q) = %q, want %q", info, got, want)
+		}
+	}
+}
+
+func TestIsDiffBlock(t *testing.T) {
+	if !isDiffBlock(codeBlock{Info: "diff", Body: "@@ -1 +1 @@"}) {
+		t.Error("a diff-tagged block is a diff")
+	}
+	if !isDiffBlock(codeBlock{Info: "go", Body: "--- a/x.go\n+++ b/x.go"}) {
+		t.Error("a block opening with a file header is a diff")
+	}
+	if isDiffBlock(codeBlock{Info: "go", Body: "package x"}) {
+		t.Error("plain code is not a diff")
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// gitgenDiffLimit caps the diff pasted into a commit/pr prompt. Past it the
+// model still sees the full --stat, which is enough to name the change.
+const gitgenDiffLimit = 60_000
+
+const commitInstruction = "Write a conventional commit message for the staged change below: " +
+	"a `type(scope): subject` line of at most 72 characters, a blank line, then a short body " +
+	"wrapped at 72 columns saying what changed and why. Output ONLY the message — no code " +
+	"fences, no preamble, no commentary."
+
+const prInstruction = "Write a pull request description for the branch below. Open with one or " +
+	"two plain sentences saying what the change does and why, then a short list of the notable " +
+	"changes. Output ONLY the markdown body — no title line, no code fences around it, no " +
+	"commentary."
+
+// runCommitMessage drafts a commit message for the staged diff. With apply it
+// commits directly through `git commit -F -`; otherwise it prints the message.
+func runCommitMessage(loaded *config.Loaded, apply bool) {
+	stat, err := gitOutput("diff", "--cached", "--stat")
+	if err != nil {
+		die("commit: %s", err)
+	}
+	if strings.TrimSpace(stat) == "" {
+		die("commit: nothing staged (git add first)")
+	}
+	diff, err := gitOutput("diff", "--cached")
+	if err != nil {
+		die("commit: %s", err)
+	}
+	msg := ephemeralAnswer(loaded, commitInstruction+"\n\n"+stat+"\n"+clipDiff(diff, gitgenDiffLimit))
+	if !apply {
+		fmt.Println(msg)
+		return
+	}
+	cmd := exec.Command("git", "commit", "-F", "-")
+	cmd.Stdin = strings.NewReader(msg + "\n")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		die("commit: git commit: %s", err)
+	}
+}
+
+// runPRBody drafts a PR description from the commits and diff between base
+// and HEAD. An empty base resolves to the remote's default branch.
+func runPRBody(loaded *config.Loaded, base string) {
+	if base == "" {
+		base = defaultBase()
+	}
+	mergeBase, err := gitOutput("merge-base", base, "HEAD")
+	if err != nil {
+		die("pr: no merge base with %s (pass --base)", base)
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+	log, err := gitOutput("log", "--reverse", "--format=%s%n%n%b", mergeBase+"..HEAD")
+	if err != nil {
+		die("pr: %s", err)
+	}
+	if strings.TrimSpace(log) == "" {
+		die("pr: HEAD has no commits beyond %s", base)
+	}
+	stat, _ := gitOutput("diff", "--stat", mergeBase, "HEAD")
+	diff, err := gitOutput("diff", mergeBase, "HEAD")
+	if err != nil {
+		die("pr: %s", err)
+	}
+	prompt := prInstruction + "\n\nCommits:\n" + log + "\n" + stat + "\n" + clipDiff(diff, gitgenDiffLimit)
+	fmt.Println(ephemeralAnswer(loaded, prompt))
+}
+
+// ephemeralAnswer asks a throwaway aria one question and returns the reply
+// with any wrapping fence stripped. The aria is killed afterwards.
+func ephemeralAnswer(loaded *config.Loaded, prompt string) string {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+
+	answer, err := askEphemeral(ctx, loaded, acli, prompt)
+	if err != nil {
+		die("%s", err)
+	}
+	return answer
+}
+
+// askEphemeral is ephemeralAnswer without the process exit, for callers
+// where a failed side question must not fail the command.
+func askEphemeral(ctx context.Context, loaded *config.Loaded, acli *angelus.Client, prompt string) (string, error) {
+	createResp, err := createWithFirstRun(ctx, loaded, func() (*rpc.CreateResponse, error) { return acli.CreateEphemeral(ctx, "", nil) })
+	if err != nil {
+		return "", fmt.Errorf("create figaro: %w", err)
+	}
+	defer func() {
+		killCtx, killCancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer killCancel()
+		_ = acli.Kill(killCtx, createResp.FigaroID, false)
+	}()
+	ep := transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
+	if err := waitForSocket(ep.Address, 3*time.Second); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if code := plainPrompt(ctx, ep, prompt, &buf); code != 0 {
+		return "", fmt.Errorf("prompt failed (exit %d)", code)
+	}
+	answer := strings.TrimSpace(stripBashFences(buf.String()))
+	if answer == "" {
+		return "", fmt.Errorf("empty answer from agent")
+	}
+	return answer, nil
+}
+
+// clipDiff truncates diff to limit bytes at a line boundary and says so.
+func clipDiff(diff string, limit int) string {
+	if len(diff) <= limit {
+		return diff
+	}
+	cut := diff[:limit]
+	if nl := strings.LastIndexByte(cut, '\n'); nl > 0 {
+		cut = cut[:nl+1]
+	}
+	return cut + fmt.Sprintf("[diff truncated: %d of %d bytes shown]\n", len(cut), len(diff))
+}
+
+// defaultBase is origin's HEAD branch when known, else main, else master.
+func defaultBase() string {
+	if ref, err := gitOutput("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			return ref
+		}
+	}
+	if _, err := gitOutput("rev-parse", "--verify", "--quiet", "main"); err == nil {
+		return "main"
+	}
+	return "master"
+}
+
+func gitOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
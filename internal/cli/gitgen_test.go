@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipDiffCutsAtALineBoundary(t *testing.T) {
+	diff := "+aaaa\n+bbbb\n+cccc\n"
+	if got := clipDiff(diff, 100); got != diff {
+		t.Fatalf("a diff under the limit must pass through, got %q", got)
+	}
+	got := clipDiff(diff, 9)
+	if !strings.HasPrefix(got, "+aaaa\n[diff truncated: 6 of 18 bytes shown]") {
+		t.Fatalf("clipDiff = %q", got)
+	}
+}
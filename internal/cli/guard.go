@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/audit"
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/guard"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// guardKey is the chalkboard key overriding [guard] mode for one aria.
+const guardKey = "system.guard"
+
+// Guard modes.
+const (
+	guardOff   = "off"
+	guardBlock = "block"
+	guardMask  = "mask"
+)
+
+// buildPromptGuard turns the [guard] table into the daemon's prompt guard.
+// It is installed even when the table's mode is off, so an aria can opt in
+// with system.guard. A bad table fails closed: an unknown mode blocks, and
+// a pattern that doesn't compile is dropped while the built-ins stay on.
+// The errors name what was ignored.
+func buildPromptGuard(g config.Guard) (figaro.PromptGuard, []error) {
+	var errs []error
+	mode := g.Mode
+	switch mode {
+	case "":
+		mode = guardOff
+	case guardOff, guardBlock, guardMask:
+	default:
+		errs = append(errs, fmt.Errorf("mode %q: want off, block or mask; blocking", g.Mode))
+		mode = guardBlock
+	}
+	sc, err := guard.New(g.Emails, g.Patterns)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return func(ariaID, text string, snap chalkboard.Snapshot) (string, error) {
+		out, kinds, err := guardPrompt(sc, ariaGuardMode(snap, mode), text)
+		decision := "sent"
+		switch {
+		case err != nil:
+			decision = "blocked"
+		case len(kinds) > 0:
+			decision = "masked"
+		}
+		recordAudit(audit.Entry{Kind: audit.KindPrompt, Aria: ariaID, Digest: audit.Digest(text), Decision: decision, Rules: kinds})
+		return out, err
+	}, errs
+}
+
+// ariaGuardMode is the aria's system.guard when it names a mode, else def.
+func ariaGuardMode(snap chalkboard.Snapshot, def string) string {
+	var m string
+	if raw, ok := snap[guardKey]; ok && json.Unmarshal(raw, &m) == nil {
+		switch m = strings.TrimSpace(m); m {
+		case guardOff, guardBlock, guardMask:
+			return m
+		}
+	}
+	return def
+}
+
+// guardPrompt applies mode to text. It returns the text to send and the
+// kinds of secret it masked, or an error naming the kinds it blocked on.
+func guardPrompt(sc *guard.Scanner, mode, text string) (string, []string, error) {
+	if mode == guardOff {
+		return text, nil, nil
+	}
+	found := sc.Scan(text)
+	if len(found) == 0 {
+		return text, nil, nil
+	}
+	kinds := guard.Kinds(found)
+	if mode == guardMask {
+		return guard.Mask(text, found), kinds, nil
+	}
+	return "", kinds, errs.New(errs.Blocked, "guard: prompt not sent: it contains %s (mask with `figaro set %s mask`, allow with `figaro set %s off`)",
+		strings.Join(kinds, ", "), guardKey, guardKey)
+}
+
+// buildResponseGuard turns [guard] destructive and [[guard.rules]] into the
+// daemon's response guard; nil when no rule is configured and there is no
+// audit log to record calls in. A bad rule is reported and left out.
+func buildResponseGuard(g config.Guard) (figaro.ResponseGuard, []error) {
+	var errs []error
+	var rules []guard.Rule
+	if g.Destructive {
+		rules = guard.DestructiveRules()
+	}
+	for i, r := range g.Rules {
+		if r.Name == "" {
+			errs = append(errs, fmt.Errorf("rules[%d]: name is required", i))
+			continue
+		}
+		if r.Pattern == "" {
+			errs = append(errs, fmt.Errorf("rule %s: pattern is required", r.Name))
+			continue
+		}
+		var block bool
+		switch r.Action {
+		case "", "block":
+			block = true
+		case "warn":
+		default:
+			errs = append(errs, fmt.Errorf("rule %s: action %q: want block or warn", r.Name, r.Action))
+			continue
+		}
+		rule, err := guard.NewRule(r.Name, r.Stage, r.Tool, r.Pattern, block)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 && auditLog == nil {
+		return nil, errs
+	}
+	return func(ariaID, stage, tool, text string) []rpc.GuardViolation {
+		var out []rpc.GuardViolation
+		var names []string
+		blocked := false
+		for _, v := range guard.Check(rules, stage, tool, text) {
+			out = append(out, rpc.GuardViolation{Stage: stage, Rule: v.Rule, Tool: tool, Match: v.Match, Blocked: v.Block})
+			names = append(names, v.Rule)
+			blocked = blocked || v.Block
+		}
+		e := audit.Entry{Kind: audit.KindAnswer, Aria: ariaID, Digest: audit.Digest(text), Decision: "ok", Rules: names}
+		if stage == guard.StageTool {
+			e.Kind, e.Tool, e.Decision = audit.KindTool, tool, "allowed"
+		}
+		switch {
+		case blocked:
+			e.Decision = "blocked"
+		case len(out) > 0:
+			e.Decision = "flagged"
+		}
+		recordAudit(e)
+		return out
+	}, errs
+}
+
+// guardNotice is the one-line form of a violation for terminal output.
+func guardNotice(v rpc.GuardViolation) string {
+	what := "answer"
+	if v.Stage == guard.StageTool {
+		what = v.Tool + " call"
+	}
+	verdict := "flagged"
+	if v.Blocked {
+		verdict = "blocked"
+	}
+	return fmt.Sprintf("guard: %s %s by rule %s (%q)", what, verdict, v.Rule, taskSummary(v.Match, 60))
+}
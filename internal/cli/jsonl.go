@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// A conversation can leave the aria store as a .figaro.jsonl file: one IR
+// message per line, with an index beside it (store.JSONLLog). Export
+// appends to the file, so re-exporting a growing aria writes only its new
+// messages; import brings a file back as a fresh aria; show reads one
+// directly, tail first.
+
+// exportPath is where export writes id when no file is named.
+func exportPath(id string) string { return id + store.JSONLExt }
+
+// runExport writes aria id's messages to file, continuing a previous
+// export of the same aria.
+func runExport(loaded *config.Loaded, id, file string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if id == "" {
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err != nil {
+			die("resolve: %s", err)
+		}
+		if !r.Found {
+			die("export: no figaro bound to this shell (name one: figaro export <id>)")
+		}
+		id = r.FigaroID
+	}
+	if err := rpc.ValidateAriaID(id); err != nil {
+		die("export: %s", err)
+	}
+	if file == "" {
+		file = exportPath(id)
+	}
+	if !store.IsJSONL(file) {
+		die("export: %s: the file name must end in %s", file, store.JSONLExt)
+	}
+	l, err := store.OpenJSONL(file)
+	if err != nil {
+		die("export: %s", err)
+	}
+	defer l.Close()
+
+	var from uint64
+	if tail, ok := l.PeekTail(); ok {
+		if err := continuesAria(ctx, acli, id, tail); err != nil {
+			die("export: %s: %s", file, err)
+		}
+		from = tail.LT + 1
+	}
+	resp, err := ariaReadAll(ctx, acli, id, from)
+	if err != nil {
+		die("export: aria.read: %s", err)
+	}
+	for _, e := range resp.Entries {
+		var m message.Message
+		if err := json.Unmarshal(e.Payload, &m); err != nil {
+			die("export: parse LT=%d: %s", e.LT, err)
+		}
+		if _, err := l.Append(store.Entry[message.Message]{LT: e.LT, Payload: m}); err != nil {
+			die("export: %s", err)
+		}
+	}
+	if err := l.Sync(); err != nil {
+		die("export: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "exported %s to %s (%d new, %d messages)\n", id, file, len(resp.Entries), l.Len())
+}
+
+// continuesAria checks that the file's last line is aria id's message at
+// that LT, so an export never appends one aria onto another's file.
+func continuesAria(ctx context.Context, acli ariaReader, id string, tail store.Entry[message.Message]) error {
+	resp, err := acli.AriaRead(ctx, id, tail.LT, 1)
+	if err != nil {
+		return err
+	}
+	if len(resp.Entries) == 1 && resp.Entries[0].LT == tail.LT {
+		var m message.Message
+		if json.Unmarshal(resp.Entries[0].Payload, &m) == nil &&
+			m.Role == tail.Payload.Role && m.Timestamp == tail.Payload.Timestamp {
+			return nil
+		}
+	}
+	return fmt.Errorf("not an export of %s (its LT %d differs); name a new file with -o", id, tail.LT)
+}
+
+// ariaReader is the slice of the angelus client continuesAria needs.
+type ariaReader interface {
+	AriaRead(ctx context.Context, figaroID string, from uint64, limit int) (*rpc.AriaReadResponse, error)
+}
+
+// runImport brings a .figaro.jsonl file back as a fresh aria under
+// loadout (default: the configured one) and prints its id.
+func runImport(loaded *config.Loaded, file, loadout string) {
+	msgs, err := readJSONL(file)
+	if err != nil {
+		die("import: %s", err)
+	}
+	if len(msgs) == 0 {
+		die("import: %s holds no messages", file)
+	}
+	for i := range msgs {
+		msgs[i].LogicalTime = 0 // the new log numbers its own
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	resp, err := acli.Import(ctx, loadout, nil, msgs)
+	if err != nil {
+		die("import: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "imported %s as %s (%d messages)\n", file, resp.FigaroID, len(msgs))
+	fmt.Println(resp.FigaroID)
+}
+
+// readJSONL reads every message of an existing .figaro.jsonl file.
+func readJSONL(file string) ([]message.Message, error) {
+	l, err := openJSONLFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	entries := l.Read()
+	msgs := make([]message.Message, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.Payload
+	}
+	return msgs, nil
+}
+
+// openJSONLFile opens a .figaro.jsonl file that must already exist.
+func openJSONLFile(file string) (*store.JSONLLog, error) {
+	if !store.IsJSONL(file) {
+		return nil, fmt.Errorf("%s: not a %s file", file, store.JSONLExt)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+	return store.OpenJSONL(file)
+}
+
+// renderJSONL is show for a .figaro.jsonl file. The default tail read
+// seeks through the index rather than reading the whole file.
+func renderJSONL(loaded *config.Loaded, file string, opts showOpts) {
+	if opts.verbose {
+		die("show: %s is a file; --verbose needs a live aria (try: figaro import %s)", file, file)
+	}
+	l, err := openJSONLFile(file)
+	if err != nil {
+		die("show: %s", err)
+	}
+	defer l.Close()
+	var entries []store.Entry[message.Message]
+	switch {
+	case opts.before >= 0:
+		entries, _ = l.ReadPage(0, uint64(opts.before), opts.last)
+	case opts.from >= 0:
+		entries = l.ReadFrom(uint64(opts.from), 0)
+	case !opts.all:
+		entries = store.TailSnapshot[message.Message](l, opts.last)
+	default:
+		entries = l.Read()
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "(empty aria)")
+		return
+	}
+	renderEntries(loaded, strings.TrimSuffix(filepath.Base(file), store.JSONLExt), entries, opts)
+}
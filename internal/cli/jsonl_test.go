@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// fakeAria serves aria.read from a fixed list of messages.
+type fakeAria []message.Message
+
+func (f fakeAria) AriaRead(_ context.Context, _ string, from uint64, limit int) (*rpc.AriaReadResponse, error) {
+	resp := &rpc.AriaReadResponse{Total: len(f)}
+	for _, m := range f {
+		if m.LogicalTime < from || (limit > 0 && len(resp.Entries) == limit) {
+			continue
+		}
+		b, _ := json.Marshal(m)
+		resp.Entries = append(resp.Entries, rpc.AriaReadEntry{LT: m.LogicalTime, Payload: b})
+	}
+	return resp, nil
+}
+
+func TestContinuesAria(t *testing.T) {
+	aria := fakeAria{
+		{Role: message.RoleUser, Timestamp: 100, LogicalTime: 1},
+		{Role: message.RoleAssistant, Timestamp: 200, LogicalTime: 2},
+	}
+	tail := func(m message.Message) store.Entry[message.Message] {
+		return store.Entry[message.Message]{LT: m.LogicalTime, Payload: m}
+	}
+	if err := continuesAria(context.Background(), aria, "aaaa1111", tail(aria[1])); err != nil {
+		t.Fatalf("own export refused: %v", err)
+	}
+	other := message.Message{Role: message.RoleAssistant, Timestamp: 999, LogicalTime: 2}
+	if err := continuesAria(context.Background(), aria, "aaaa1111", tail(other)); err == nil {
+		t.Fatal("another aria's file accepted")
+	}
+	past := message.Message{Role: message.RoleUser, Timestamp: 100, LogicalTime: 9}
+	if err := continuesAria(context.Background(), aria, "aaaa1111", tail(past)); err == nil {
+		t.Fatal("a file past the aria's end accepted")
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := readJSONL(filepath.Join(dir, "missing"+store.JSONLExt)); !os.IsNotExist(err) {
+		t.Fatalf("missing file: %v", err)
+	}
+	if _, err := readJSONL(filepath.Join(dir, "chat.json")); err == nil {
+		t.Fatal("wrong extension accepted")
+	}
+	path := filepath.Join(dir, "chat"+store.JSONLExt)
+	if err := os.WriteFile(path, []byte(`{"role":"user","content":[{"type":"prose","text":"hi"}]}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := readJSONL(path)
+	if err != nil || len(msgs) != 1 || msgs[0].Content[0].Text != "hi" || msgs[0].LogicalTime != 1 {
+		t.Fatalf("readJSONL = %+v, %v", msgs, err)
+	}
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/locale"
+)
+
+// Pager actions. These are the names the config [keys] table binds.
+const (
+	keyDown      = "down"
+	keyUp        = "up"
+	keyHalfDown  = "half_down"
+	keyHalfUp    = "half_up"
+	keyTop       = "top"
+	keyBottom    = "bottom"
+	keySearch    = "search"
+	keyNextMatch = "next_match"
+	keyPrevMatch = "prev_match"
+	keyJump      = "jump"
+	keyReply     = "reply"
+	keyActions   = "actions"
+	keyVisual    = "visual"
+	keyPager     = "pager"
+	keyClock     = "clock"
+	keyHelp      = "help"
+	keyStatus    = "status"
+	keyMark      = "mark"
+	keyNextAlt   = "next_alt"
+	keyPrevAlt   = "prev_alt"
+)
+
+// defaultKeys binds every pager action. A doubled key ("gg") fires on the
+// second press.
+var defaultKeys = map[string]string{
+	keyDown: "j", keyUp: "k", keyHalfDown: "d", keyHalfUp: "u",
+	keyTop: "gg", keyBottom: "G",
+	keySearch: "/", keyNextMatch: "n", keyPrevMatch: "N", keyJump: ":",
+	keyReply: "r", keyActions: "a", keyVisual: "v", keyPager: "|",
+	keyClock: "t", keyHelp: "?", keyStatus: "!", keyMark: "m",
+	keyPrevAlt: "[", keyNextAlt: "]",
+}
+
+// keyHelpRow is one row of the '?' panel. A configurable row names its
+// actions: groups are joined with " · ", actions within a group with "/".
+// A fixed row (the input loop's keys) gives its keys verbatim. help is a
+// locale catalog key.
+type keyHelpRow struct {
+	groups [][]string
+	fixed  string
+	help   string
+}
+
+var keyHelpRows = []keyHelpRow{
+	{groups: [][]string{{keyDown, keyUp}, {keyHalfUp, keyHalfDown}, {keyTop, keyBottom}}, help: "pager.key.scroll"},
+	{groups: [][]string{{keySearch}}, help: "pager.key.search"},
+	{groups: [][]string{{keyJump}}, help: "pager.key.jump"},
+	{groups: [][]string{{keyPager}}, help: "pager.key.pager"},
+	{groups: [][]string{{keyNextMatch, keyPrevMatch}}, help: "pager.key.match"},
+	{groups: [][]string{{keyReply}}, help: "pager.key.reply"},
+	{fixed: "y", help: "pager.key.copy"},
+	{groups: [][]string{{keyActions}}, help: "pager.key.actions"},
+	{groups: [][]string{{keyVisual}}, help: "pager.key.visual"},
+	{groups: [][]string{{keyMark}}, help: "pager.key.mark"},
+	{groups: [][]string{{keyPrevAlt, keyNextAlt}}, help: "pager.key.alternative"},
+	{groups: [][]string{{keyClock}}, help: "pager.key.clock"},
+	{fixed: "^O", help: "pager.key.verbose"},
+	{fixed: "^N/^P", help: "pager.key.node"},
+	{fixed: "^N/^P + Shift", help: "pager.key.extend"},
+	{fixed: "Enter / ^C", help: "pager.key.expand"},
+	{fixed: "^L", help: "pager.key.listen"},
+	{fixed: "^D", help: "pager.key.detach"},
+	{fixed: "^C", help: "pager.key.interrupt"},
+	{groups: [][]string{{keyStatus}}, help: "pager.key.status"},
+	{groups: [][]string{{keyHelp}}, help: "pager.key.help"},
+}
+
+// keymap resolves pager input bytes to actions. Ctrl-N/P, Enter, Esc and
+// the input loop's control keys are fixed and not part of it.
+type keymap struct {
+	single map[byte]string   // key → action on one press
+	double map[byte]string   // key → action on a second consecutive press
+	keys   map[string]string // action → its binding, for the help panel
+}
+
+// pagerKeys is the active keymap, replaced by applyKeymap from config.
+var pagerKeys, _ = newKeymap(nil)
+
+// newKeymap builds the default keymap with overrides (action → space-separated
+// keys) applied. A rebound key is taken away from the action that had it by
+// default; a bad entry is reported and skipped.
+func newKeymap(overrides map[string]string) (keymap, []error) {
+	var errs []error
+	keys := make(map[string]string, len(defaultKeys))
+	for a, k := range defaultKeys {
+		keys[a] = k
+	}
+	claimed := map[string]string{} // key spec → overriding action
+	names := make([]string, 0, len(overrides))
+	for a := range overrides {
+		names = append(names, a)
+	}
+	sort.Strings(names)
+	for _, a := range names {
+		if _, ok := defaultKeys[a]; !ok {
+			errs = append(errs, fmt.Errorf("unknown action %q", a))
+			continue
+		}
+		var specs []string
+		for _, spec := range strings.Fields(overrides[a]) {
+			spec = canonicalKey(spec)
+			if _, _, err := parseKeySpec(spec); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", a, err))
+				continue
+			}
+			if other, ok := claimed[spec]; ok {
+				errs = append(errs, fmt.Errorf("%s: %q is already bound to %s", a, spec, other))
+				continue
+			}
+			claimed[spec] = a
+			specs = append(specs, spec)
+		}
+		if len(specs) > 0 {
+			keys[a] = strings.Join(specs, " ")
+		}
+	}
+	for a, k := range keys {
+		if _, ok := overrides[a]; ok {
+			continue
+		}
+		var kept []string
+		for _, spec := range strings.Fields(k) {
+			if _, taken := claimed[spec]; !taken {
+				kept = append(kept, spec)
+			}
+		}
+		keys[a] = strings.Join(kept, " ")
+	}
+	km := keymap{single: map[byte]string{}, double: map[byte]string{}, keys: keys}
+	for a, k := range keys {
+		for _, spec := range strings.Fields(k) {
+			b, twice, _ := parseKeySpec(spec)
+			if twice {
+				km.double[b] = a
+			} else {
+				km.single[b] = a
+			}
+		}
+	}
+	return km, errs
+}
+
+// applyKeymap installs the config [keys] table. Bad entries are warnings;
+// the rest of the table still applies.
+func applyKeymap(overrides map[string]string) []error {
+	km, errs := newKeymap(overrides)
+	pagerKeys = km
+	return errs
+}
+
+// canonicalKey lower-cases the ctrl- form so "Ctrl-X" and "ctrl-x" collide.
+func canonicalKey(spec string) string {
+	if len(spec) > 5 && strings.EqualFold(spec[:5], "ctrl-") {
+		return strings.ToLower(spec)
+	}
+	return spec
+}
+
+// parseKeySpec reads one key: a printable character, the same character
+// twice ("gg"), or ctrl-<letter>. Keys the pager keeps for itself (Ctrl-N/P,
+// Enter, Esc, and the input loop's controls) are refused.
+func parseKeySpec(spec string) (b byte, twice bool, err error) {
+	switch {
+	case len(spec) == 1 && spec[0] > 0x20 && spec[0] < 0x7f:
+		b = spec[0]
+	case len(spec) == 2 && spec[0] == spec[1] && spec[0] > 0x20 && spec[0] < 0x7f:
+		b, twice = spec[0], true
+	case len(spec) == 6 && strings.EqualFold(spec[:5], "ctrl-"):
+		c := spec[5] | 0x20
+		if c < 'a' || c > 'z' {
+			return 0, false, fmt.Errorf("bad key %q", spec)
+		}
+		b = c - 'a' + 1
+	default:
+		return 0, false, fmt.Errorf("bad key %q (want a character, a doubled character, or ctrl-<letter>)", spec)
+	}
+	switch b {
+	case 0x03, 0x04, 0x08, 0x09, 0x0a, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x14, 'y':
+		return 0, false, fmt.Errorf("%q is reserved", spec)
+	}
+	return b, twice, nil
+}
+
+// action resolves b, given whether the previous key was an unconsumed first
+// press of b. first reports b as the first half of a doubled binding.
+func (km keymap) action(b byte, pending bool) (action string, first bool) {
+	if a, ok := km.double[b]; ok {
+		if pending {
+			return a, false
+		}
+		if single, ok := km.single[b]; ok {
+			return single, true
+		}
+		return "", true
+	}
+	return km.single[b], false
+}
+
+// opens reports whether b should pull the incipit view into the pager: the
+// scroll, search and help keys do.
+func (km keymap) opens(b byte) bool {
+	for _, a := range []string{keyDown, keyUp, keyHalfDown, keyHalfUp, keyTop, keyBottom, keySearch, keyHelp} {
+		for _, spec := range strings.Fields(km.keys[a]) {
+			if k, _, _ := parseKeySpec(spec); k == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// label is an action's binding as the help panel shows it.
+func (km keymap) label(action string) string {
+	var out []string
+	for _, spec := range strings.Fields(km.keys[action]) {
+		if strings.HasPrefix(spec, "ctrl-") {
+			spec = "^" + strings.ToUpper(spec[5:])
+		}
+		out = append(out, spec)
+	}
+	if len(out) == 0 {
+		return "-"
+	}
+	return strings.Join(out, ",")
+}
+
+// helpRows renders rows with the keys currently bound.
+func (km keymap) helpRows(rows []keyHelpRow) []string {
+	out := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if r.groups == nil {
+			out = append(out, fmt.Sprintf("  %-19s %s", r.fixed, locale.T(r.help)))
+			continue
+		}
+		groups := make([]string, len(r.groups))
+		for i, g := range r.groups {
+			labels := make([]string, len(g))
+			for j, a := range g {
+				labels[j] = km.label(a)
+			}
+			groups[i] = strings.Join(labels, "/")
+		}
+		out = append(out, fmt.Sprintf("  %-19s %s", strings.Join(groups, " · "), locale.T(r.help)))
+	}
+	return out
+}
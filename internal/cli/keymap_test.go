@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/locale"
+)
+
+func TestKeymapDefaults(t *testing.T) {
+	km, errs := newKeymap(nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if a, _ := km.action('j', false); a != keyDown {
+		t.Fatalf("j = %q, want %q", a, keyDown)
+	}
+	if a, first := km.action('g', false); a != "" || !first {
+		t.Fatalf("first g = %q/%v, want a pending first press", a, first)
+	}
+	if a, _ := km.action('g', true); a != keyTop {
+		t.Fatalf("gg = %q, want %q", a, keyTop)
+	}
+	if !km.opens('j') || km.opens('r') {
+		t.Fatal("scroll keys open the pager; reply does not")
+	}
+}
+
+func TestKeymapOverrides(t *testing.T) {
+	km, errs := newKeymap(map[string]string{
+		"top":    "H",
+		"down":   "k Ctrl-E",
+		"up":     "K",
+		"bogus":  "x",
+		"search": "y",
+	})
+	if len(errs) != 2 {
+		t.Fatalf("want errors for the unknown action and the reserved key, got %v", errs)
+	}
+	if a, _ := km.action('H', false); a != keyTop {
+		t.Fatalf("H = %q, want top", a)
+	}
+	if a, first := km.action('g', false); a != "" || first {
+		t.Fatal("gg is unbound once top is rebound")
+	}
+	if a, _ := km.action('k', false); a != keyDown {
+		t.Fatalf("k = %q, want down (rebound away from up)", a)
+	}
+	if a, _ := km.action(0x05, false); a != keyDown {
+		t.Fatalf("ctrl-e = %q, want down", a)
+	}
+	if a, _ := km.action('/', false); a != keySearch {
+		t.Fatal("a rejected override keeps the default binding")
+	}
+	help := strings.Join(km.helpRows(keyHelpRows), "\n")
+	if !strings.Contains(help, "k,^E/K") || !strings.Contains(help, "H/G") {
+		t.Fatalf("help does not reflect the rebound keys:\n%s", help)
+	}
+}
+
+func TestKeyHelpLocalized(t *testing.T) {
+	defer locale.Use(locale.Default)
+	for _, tag := range locale.Tags() {
+		if err := locale.Use(tag); err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range keyHelpRows {
+			if _, ok := locale.Lookup(r.help); !ok {
+				t.Errorf("%s: no catalog entry for %q", tag, r.help)
+			}
+		}
+	}
+	locale.Use("es")
+	if help := strings.Join(pagerKeys.helpRows(keyHelpRows), "\n"); !strings.Contains(help, "cerrar la ayuda") {
+		t.Fatalf("help is not in Spanish:\n%s", help)
+	}
+}
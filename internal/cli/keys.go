@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provenance"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// keyStore keeps private signing keys in <config>/identity, left out of
+// backups and mirrors like provider credentials, and trusted public keys
+// in <config>/keys.
+func keyStore(loaded *config.Loaded) provenance.Keys {
+	return provenance.Keys{
+		Private: filepath.Join(loaded.ConfigDir, "identity"),
+		Public:  filepath.Join(loaded.ConfigDir, "keys"),
+	}
+}
+
+// buildSigner signs a prompt with its author's key when this machine has
+// one. Keys are read once; an author without one is looked up again on
+// the next prompt, so a key generated while the daemon runs takes effect.
+func buildSigner(loaded *config.Loaded) figaro.Signer {
+	ks := keyStore(loaded)
+	var mu sync.Mutex
+	keys := map[string]ed25519.PrivateKey{}
+	return func(author string) ed25519.PrivateKey {
+		mu.Lock()
+		defer mu.Unlock()
+		if k, ok := keys[author]; ok {
+			return k
+		}
+		k, err := ks.Load(author)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("signing key", "author", author, "err", err)
+			}
+			return nil
+		}
+		keys[author] = k
+		return k
+	}
+}
+
+func runKeysGenerate(loaded *config.Loaded, name string) error {
+	if name == "" {
+		name = promptAuthor
+	}
+	pub, err := keyStore(loaded).Generate(name)
+	if err != nil {
+		return err
+	}
+	key := provenance.FormatPublic(pub)
+	fmt.Printf("signing key for %s\n%s\n", name, key)
+	fmt.Fprintf(os.Stderr, "prompts sent as %s are signed from now on; others trust them with:\n  figaro keys trust %s %s\n", name, name, key)
+	return nil
+}
+
+func runKeysList(loaded *config.Loaded) error {
+	infos := keyStore(loaded).List()
+	if len(infos) == 0 {
+		fmt.Println("no keys; create one with figaro keys generate")
+		return nil
+	}
+	for _, k := range infos {
+		if k.Err != nil {
+			fmt.Printf("%-16s  %v\n", k.Name, k.Err)
+			continue
+		}
+		mark := ""
+		if k.Private {
+			mark = "  (signs)"
+		}
+		fmt.Printf("%-16s  %s%s\n", k.Name, k.Public, mark)
+	}
+	return nil
+}
+
+func runKeysTrust(loaded *config.Loaded, name, key string) error {
+	if err := keyStore(loaded).Trust(name, key); err != nil {
+		return err
+	}
+	fmt.Printf("trusted %s\n", name)
+	return nil
+}
+
+// runVerify checks the signatures on an aria (default: the bound one)
+// against its hash chain, naming signers by the trusted keys.
+func runVerify(loaded *config.Loaded, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if id == "" {
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err != nil || !r.Found {
+			return fmt.Errorf("no aria bound to this shell (pass <id>)")
+		}
+		id = r.FigaroID
+	}
+	resp, err := ariaReadAll(ctx, acli, id, 0)
+	if err != nil {
+		return fmt.Errorf("aria.read %s: %w", id, err)
+	}
+	msgs := make([]message.Message, 0, len(resp.Entries))
+	for _, e := range resp.Entries {
+		var m message.Message
+		if err := json.Unmarshal(e.Payload, &m); err != nil {
+			return fmt.Errorf("aria %s LT %d: %w", id, e.LT, err)
+		}
+		msgs = append(msgs, m)
+	}
+	trusted, err := keyStore(loaded).Trusted()
+	if err != nil {
+		return err
+	}
+	rep := provenance.Verify(msgs, trusted)
+	fmt.Print(verifySummary(id, rep, resp.Entries))
+	if !rep.OK() {
+		return fmt.Errorf("%d bad signature(s)", len(rep.Failures))
+	}
+	return nil
+}
+
+func verifySummary(id string, rep provenance.Report, entries []rpc.AriaReadEntry) string {
+	s := fmt.Sprintf("aria %s: %d messages, chain %s\n", id, rep.Messages, rep.Head.String()[:12])
+	for _, name := range slices.Sorted(maps.Keys(rep.Signed)) {
+		s += fmt.Sprintf("  %4d signed by %s\n", rep.Signed[name], name)
+	}
+	if rep.Untrusted > 0 {
+		s += fmt.Sprintf("  %d of those by keys you have not trusted (figaro keys trust)\n", rep.Untrusted)
+	}
+	if rep.Unsigned > 0 {
+		s += fmt.Sprintf("  %4d unsigned prompts\n", rep.Unsigned)
+	}
+	for _, f := range rep.Failures {
+		s += fmt.Sprintf("  BAD  LT %d: %v\n", entries[f.Index].LT, f.Err)
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func TestBuildSigner(t *testing.T) {
+	loaded := &config.Loaded{ConfigDir: t.TempDir()}
+	sign := buildSigner(loaded)
+	if sign("alice") != nil {
+		t.Fatal("key before generate")
+	}
+	pub, err := keyStore(loaded).Generate("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := sign("alice"); k == nil || !pub.Equal(k.Public()) {
+		t.Errorf("alice key = %v", k)
+	}
+	if sign("bob") != nil {
+		t.Error("bob has a key")
+	}
+	roots := backupRoots(loaded, false)
+	if !contains(roots[0].Skip, "identity") {
+		t.Errorf("backup takes private keys: skip %v", roots[0].Skip)
+	}
+}
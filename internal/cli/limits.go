@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/toolout"
+)
+
+// trimNotice is the stderr line for a [limits] cut.
+func trimNotice(t rpc.Trimmed) string {
+	switch t.Stage {
+	case "response":
+		return fmt.Sprintf("trimmed: the answer stopped at the %d-token cap ([limits] response_tokens)", t.MaxTokens)
+	case "summary":
+		return fmt.Sprintf("summarized: %s result %s → %s by [limits] summary_model; full output at %s", t.Tool, fmtBytes(int64(t.Bytes)), fmtBytes(int64(t.KeptBytes)), t.Path)
+	case "tool":
+		return fmt.Sprintf("trimmed: %s result %s → %s, head and tail kept ([limits] tool_result_bytes)", t.Tool, fmtBytes(int64(t.Bytes)), fmtBytes(int64(t.KeptBytes)))
+	default:
+		return fmt.Sprintf("trimmed: %s %s → %s, head and tail kept ([limits] attachment_bytes)", t.Stage, fmtBytes(int64(t.Bytes)), fmtBytes(int64(t.KeptBytes)))
+	}
+}
+
+// trimAttachment bounds text attached to a prompt, saying so on stderr.
+func trimAttachment(what, text string, max int) string {
+	out, trimmed := toolout.Trim(text, max)
+	if trimmed {
+		fmt.Fprintln(os.Stderr, trimNotice(rpc.Trimmed{Stage: what, Bytes: len(text), KeptBytes: len(out)}))
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestTrimNoticeNamesTheKnob(t *testing.T) {
+	for _, c := range []struct {
+		in   rpc.Trimmed
+		want string
+	}{
+		{rpc.Trimmed{Stage: "response", MaxTokens: 1000}, "response_tokens"},
+		{rpc.Trimmed{Stage: "tool", Tool: "bash", Bytes: 1 << 20, KeptBytes: 1 << 10}, "tool_result_bytes"},
+		{rpc.Trimmed{Stage: "pasted text", Bytes: 1 << 20, KeptBytes: 1 << 10}, "attachment_bytes"},
+		{rpc.Trimmed{Stage: "summary", Tool: "bash", Bytes: 1 << 20, KeptBytes: 1 << 10, Path: "/d/tc_1.txt"}, "/d/tc_1.txt"},
+	} {
+		if got := trimNotice(c.in); !strings.Contains(got, c.want) {
+			t.Errorf("trimNotice(%+v) = %q, want mention of %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTrimAttachment(t *testing.T) {
+	if got := trimAttachment("pasted text", "short", 100); got != "short" {
+		t.Fatalf("short text changed: %q", got)
+	}
+	long := strings.Repeat("line\n", 1000)
+	got := trimAttachment("pasted text", long, 200)
+	if len(got) >= len(long) || !strings.Contains(got, "trimmed from the middle") {
+		t.Fatalf("not trimmed: %d bytes", len(got))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/figaro"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	ldmouse "github.com/jack-work/figaro/internal/livelog/render/mouse"
@@ -26,29 +27,42 @@ import (
 // the user closes it. Ctrl-C still sends figaro.interrupt (just like
 // inside a send stream); Ctrl-D disconnects without touching the turn.
 //
-// With no ariaID, the pid-bound aria is used.
-func runListen(loaded *config.Loaded, ariaID string) {
+// With no ariaID, the pid-bound aria is used; failing that, an interactive
+// shell gets a picker over the existing arias. A nonzero atLT opens the
+// transcript centred on that message.
+func runListen(loaded *config.Loaded, ariaID string, atLT int) {
+	defer waitNotify()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	acli := mustConnectAngelus(loaded)
 	defer acli.Close()
 
+	if ariaID == "" {
+		if r, err := resolveBinding(ctx, acli, os.Getppid()); err == nil && !r.Found {
+			picked, err := pickAria(ctx, acli)
+			if err != nil {
+				die("%s", err)
+			}
+			ariaID = picked
+		}
+	}
+
 	resolvedID, figaroEP, err := resolveTargetEndpoint(ctx, loaded, acli, ariaID, false)
 	if err != nil {
 		die("%s", err)
 	}
 
-	tailFigaro(ctx, cancel, figaroEP, resolvedID, loaded)
+	tailFigaro(ctx, cancel, figaroEP, resolvedID, loaded, atLT)
 }
 
 // tailFigaro is the read-only twin of mustPromptFigaro. It opens the
 // same incipit-seal renderer, catches up from LT 0, then follows live
 // frames forever. Ctrl-C -> figaro.interrupt; Ctrl-D -> clean
-// disconnect (turn keeps running); Ctrl-T -> transcript pager.
-// Returns when the user disconnects, the agent socket dies, or ctx
+// disconnect (turn keeps running); Ctrl-T -> transcript pager. A
+// nonzero atLT jumps the pager there once it opens. Returns when the user disconnects, the agent socket dies, or ctx
 // is canceled.
-func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.Endpoint, figaroID string, loaded *config.Loaded) {
+func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.Endpoint, figaroID string, loaded *config.Loaded, atLT int) {
 	ctx, span := figOtel.Start(ctx, "cli.listen")
 	defer span.End()
 
@@ -96,6 +110,16 @@ func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.End
 			if json.Unmarshal(params, &r) == nil {
 				lt.apply(r)
 			}
+		case rpc.MethodGuardViolation:
+			var v rpc.GuardViolation
+			if json.Unmarshal(params, &v) == nil {
+				fmt.Fprintln(os.Stderr, "\n"+guardNotice(v))
+			}
+		case rpc.MethodContentTrimmed:
+			var t rpc.Trimmed
+			if json.Unmarshal(params, &t) == nil {
+				fmt.Fprintln(os.Stderr, "\n"+trimNotice(t))
+			}
 		case rpc.MethodTurnDone:
 			// listen is a tail — we don't exit on turn boundaries.
 			// Just surface error reasons so the user sees them.
@@ -103,7 +127,10 @@ func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.End
 			_ = json.Unmarshal(params, &d)
 			lt.finishTurn(d.Reason)
 			if strings.HasPrefix(d.Reason, "error:") {
-				fmt.Fprintln(os.Stderr, "\n"+d.Reason)
+				printFailure(os.Stderr, "\n"+d.Reason, errs.Kind(d.Kind))
+			}
+			if d.Idle == nil || *d.Idle {
+				turnNotify.turnDone(os.Stdout, figaroID, d.Reason, status.turnElapsed())
 			}
 		}
 	}
@@ -119,7 +146,7 @@ func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.End
 		go func() {
 			rctx, rcancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer rcancel()
-			r, rerr := fcli.Read(rctx, sinceLT)
+			r, rerr := catchUp(rctx, fcli, sinceLT)
 			if rerr != nil {
 				return
 			}
@@ -134,8 +161,16 @@ func tailFigaro(ctx context.Context, cancel context.CancelFunc, ep transport.End
 	in := &interactiveInput{
 		tc: tc, lt: lt, fcli: fcli, mu: &mu, set: &set,
 		figaroID: figaroID, listen: &listen, cancel: cancel, disconnectCh: disconnectCh,
+		reply:       replySender(ctx, fcli, ep, &mu, lt, false, nil),
+		fork:        forkHere(figaroID),
+		pin:         pinHere(fcli),
+		alternative: attendAlternative(figaroID),
 	}
+	lt.setTranscriptReplyable(true)
 	in.enterTranscript()
+	if atLT > 0 {
+		in.jumpTranscript(atLT)
+	}
 
 	// Local spinner animation.
 	stopTick := make(chan struct{})
@@ -25,11 +25,12 @@ type livelogTurn struct {
 	tr     *transcript
 	status *sessionStatus
 
-	openLT   int
-	openRole string
-	open     []livedoc.Node
-	pending  *aria.Message
-	finished bool
+	openLT     int
+	openRole   string
+	openAuthor string
+	open       []livedoc.Node
+	pending    *aria.Message
+	finished   bool
 
 	// lastSealedLT is the highest LT incipit has committed to native scrollback
 	// inline (via Seal). It marks the flush boundary: on leaving the pager,
@@ -38,6 +39,8 @@ type livelogTurn struct {
 	// inline (we entered the pager cold, e.g. `figaro listen`).
 	lastSealedLT int
 	pagerClosed  []aria.Message
+
+	tee *answerTee // --output; nil when not teeing
 }
 
 func newLivelogTurn(out io.Writer, w, h int, settings *renderSettings, figaroID string, startedAt time.Time, status *sessionStatus, bookend func() []string, rule func() string) *livelogTurn {
@@ -56,6 +59,7 @@ func newLivelogTurn(out io.Writer, w, h int, settings *renderSettings, figaroID
 	}
 	t.client.OnClosed = func(m aria.Message) {
 		t.tr.observeCommitted(m)
+		t.tee.add(m)
 		if t.tr.active {
 			if t.lastSealedLT != 0 {
 				t.pagerClosed = append(t.pagerClosed, m)
@@ -73,14 +77,15 @@ func newLivelogTurn(out io.Writer, w, h int, settings *renderSettings, figaroID
 			}
 		}
 	}
-	t.client.OnLive = func(lt int, role string, nodes []livedoc.Node) {
+	t.client.OnLive = func(lt int, role, author string, nodes []livedoc.Node) {
 		newOpen := lt != t.openLT
-		t.openLT, t.openRole, t.open = lt, role, nodes
+		t.openLT, t.openRole, t.openAuthor, t.open = lt, role, author, nodes
 		if role == "assistant" {
 			if newOpen {
 				t.finished = false
 			}
 			t.status.beginTurn()
+			t.status.streaming(lt, streamedChars(nodes))
 		}
 		// A turn taller than the viewport can't render inline without scrolling
 		// its own live region off-screen; move it to the scrollable pager
@@ -92,12 +97,24 @@ func newLivelogTurn(out io.Writer, w, h int, settings *renderSettings, figaroID
 		if t.tr.active {
 			t.tr.render()
 		} else {
-			t.in.Open(lt, role, nodes)
+			t.in.OpenBy(lt, role, author, nodes)
 		}
 	}
 	return t
 }
 
+// streamedChars is the model-written text in an open message (prose and
+// thinking; tool output is not the model's), for the live token estimate.
+func streamedChars(nodes []livedoc.Node) int {
+	n := 0
+	for _, nd := range nodes {
+		if nd.Type == livedoc.NodeProse || nd.Type == livedoc.NodeThinking {
+			n += len(nd.Markdown)
+		}
+	}
+	return n
+}
+
 // minPagerHeight floors the auto-pager: below this viewport height an
 // overflowing turn stays inline and scrolls natively rather than yanking a tiny
 // pane into the full-screen pager.
@@ -174,7 +191,7 @@ func (t *livelogTurn) render() {
 	if t.tr.active {
 		t.tr.render()
 	} else if t.openLT != 0 {
-		t.in.Open(t.openLT, t.openRole, t.open)
+		t.in.OpenBy(t.openLT, t.openRole, t.openAuthor, t.open)
 	}
 }
 
@@ -225,6 +242,10 @@ func (t *livelogTurn) transcriptSelectionPlan() (selectionCopyPlan, bool) {
 	return t.tr.selectionPlan()
 }
 
+// transcriptVisual reports whether the selection is a visual (whole-message)
+// range, which 'y' copies in full rather than code-only.
+func (t *livelogTurn) transcriptVisual() bool { return t.tr.visual }
+
 func (t *livelogTurn) clearTranscriptSelection() {
 	t.tr.clearSelection()
 	t.tr.render()
@@ -305,9 +326,50 @@ func lastTurnStartLT(v aria.View) int {
 // transcriptScroll moves the pager viewport by delta lines (native wheel).
 func (t *livelogTurn) transcriptScroll(delta int) { t.tr.scrollBy(delta) }
 
-// transcriptSearching reports whether the pager is in its search prompt, so the
-// input loop routes typeable keys (like 'y') to the query instead of acting.
-func (t *livelogTurn) transcriptSearching() bool { return t.tr.active && t.tr.inSearch }
+// transcriptSearching reports whether the pager is in its search prompt,
+// reply box, jump prompt, action menu or note prompt, so the input loop
+// routes typeable keys (like 'y') to the pager instead of acting.
+func (t *livelogTurn) transcriptSearching() bool {
+	return t.tr.active && (t.tr.inSearch || t.tr.inReply || t.tr.inJump || t.tr.inActions || t.tr.inMark)
+}
+
+// setTranscriptReplyable arms the pager's 'r' reply box (only callers that
+// can send a prompt do).
+func (t *livelogTurn) setTranscriptReplyable(on bool) { t.tr.replyable = on }
+
+func (t *livelogTurn) takeTranscriptReply() string { return t.tr.takeReply() }
+
+func (t *livelogTurn) takeTranscriptAction() (byte, selectionCopyPlan) { return t.tr.takeAction() }
+
+func (t *livelogTurn) takeTranscriptJump() int { return t.tr.takeJump() }
+
+func (t *livelogTurn) takeTranscriptPager() bool { return t.tr.takePager() }
+
+func (t *livelogTurn) takeTranscriptAlternative() int { return t.tr.takeAlternative() }
+
+func (t *livelogTurn) takeTranscriptMark() (markEdit, bool) { return t.tr.takeMark() }
+
+func (t *livelogTurn) setTranscriptMarks(marks map[int]string) { t.tr.setMarks(marks) }
+
+func (t *livelogTurn) setTranscriptMark(e markEdit) { t.tr.setMark(e) }
+
+func (t *livelogTurn) transcriptRenderAll(messages []aria.Message) string {
+	return t.tr.renderAll(messages)
+}
+
+func (t *livelogTurn) suspendTranscript() { t.tr.suspend() }
+
+func (t *livelogTurn) resumeTranscript() { t.tr.resume() }
+
+func (t *livelogTurn) transcriptJumpWindow(lt int, messages []aria.Message) {
+	t.tr.jumpWindow(lt, messages)
+}
+
+// transcriptNotice puts a one-shot message on the pager's status row.
+func (t *livelogTurn) transcriptNotice(msg string) {
+	t.tr.notice = msg
+	t.tr.render()
+}
 
 // Transcript page fetches run off-lock; applying a page restores the viewport
 // anchor and evicts the far edge of the bounded window.
@@ -1,10 +0,0 @@
-//go:build !windows
-
-package cli
-
-import "os"
-import "syscall"
-
-func tryLockFile(f *os.File) error {
-	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
-}
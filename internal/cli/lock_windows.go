@@ -1,18 +0,0 @@
-//go:build windows
-
-package cli
-
-import (
-	"os"
-
-	"golang.org/x/sys/windows"
-)
-
-func tryLockFile(f *os.File) error {
-	ol := new(windows.Overlapped)
-	return windows.LockFileEx(
-		windows.Handle(f.Fd()),
-		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
-		0, 1, 0, ol,
-	)
-}
@@ -161,22 +161,13 @@ func runList(loaded *config.Loaded, o lsOpts) {
 		var rows []listRow
 		ppid := os.Getppid()
 		marker := func(f rpc.FigaroInfoResponse) string {
-			if slices.Contains(f.BoundPIDs, ppid) {
-				return "●"
-			}
-			if f.State == "active" {
-				return "▸"
-			}
-			return "○"
+			return listMark(slices.Contains(f.BoundPIDs, ppid), f.State == "active")
 		}
 		var emit func(f rpc.FigaroInfoResponse, prefix string, isLast, isRoot bool)
 		emit = func(f rpc.FigaroInfoResponse, prefix string, isLast, isRoot bool) {
 			glyph := ""
 			if !isRoot {
-				glyph = prefix + "├─"
-				if isLast {
-					glyph = prefix + "└─"
-				}
+				glyph = prefix + treeBranch(isLast)
 			}
 			label := f.Mantra
 			if label == "" {
@@ -204,11 +195,7 @@ func runList(loaded *config.Loaded, o lsOpts) {
 			})
 			cp := prefix
 			if !isRoot {
-				if isLast {
-					cp += "  "
-				} else {
-					cp += "│ "
-				}
+				cp += treeStem(isLast)
 			}
 			ck := kids[vecKey(f.Vector)]
 			for i, c := range ck {
@@ -243,11 +230,11 @@ func runList(loaded *config.Loaded, o lsOpts) {
 		width := listOutputWidth()
 		summary := ""
 		if width < listCompactWidth {
-			summary = fmt.Sprintf("%d aria(s) · %d branch(es) · %d/%d%s · ● here ▸ running ○ idle",
-				len(roots), branches, shown, total, hint)
+			summary = fmt.Sprintf("%d aria(s) · %d branch(es) · %d/%d%s%s",
+				len(roots), branches, shown, total, hint, listLegend(true))
 		} else {
-			summary = fmt.Sprintf("%d top-level aria(s), %d branch(es) · showing %d of %d%s        ●=here ▸=running ○=idle",
-				len(roots), branches, shown, total, hint)
+			summary = fmt.Sprintf("%d top-level aria(s), %d branch(es) · showing %d of %d%s%s",
+				len(roots), branches, shown, total, hint, listLegend(false))
 		}
 		fmt.Fprintln(os.Stderr, truncateVisible(summary, width))
 		fmt.Fprintln(os.Stderr)
@@ -288,13 +275,7 @@ func renderGlobal(figs []rpc.FigaroInfoResponse, boundID string, limit int) {
 	}
 	ppid := os.Getppid()
 	mark := func(f rpc.FigaroInfoResponse) string {
-		if slices.Contains(f.BoundPIDs, ppid) || (f.ID != "" && f.ID == liveLoadout) {
-			return "●"
-		}
-		if f.State == "active" {
-			return "▸"
-		}
-		return "○"
+		return listMark(slices.Contains(f.BoundPIDs, ppid) || (f.ID != "" && f.ID == liveLoadout), f.State == "active")
 	}
 	var rows []listRow
 	var emit func(id, prefix string, isLast, isRoot bool)
@@ -302,10 +283,7 @@ func renderGlobal(figs []rpc.FigaroInfoResponse, boundID string, limit int) {
 		f := byID[id]
 		glyph := ""
 		if !isRoot {
-			glyph = prefix + "├─"
-			if isLast {
-				glyph = prefix + "└─"
-			}
+			glyph = prefix + treeBranch(isLast)
 		}
 		var label, detail string
 		switch f.Kind {
@@ -332,11 +310,7 @@ func renderGlobal(figs []rpc.FigaroInfoResponse, boundID string, limit int) {
 		})
 		cp := prefix
 		if !isRoot {
-			if isLast {
-				cp += "  "
-			} else {
-				cp += "│ "
-			}
+			cp += treeStem(isLast)
 		}
 		ck := childrenOf[id]
 		for i, c := range ck {
@@ -359,9 +333,9 @@ func renderGlobal(figs []rpc.FigaroInfoResponse, boundID string, limit int) {
 	width := listOutputWidth()
 	summary := ""
 	if width < listCompactWidth {
-		summary = fmt.Sprintf("global · %d/%d%s · ● here ▸ running ○ idle", shown, total, hint)
+		summary = fmt.Sprintf("global · %d/%d%s%s", shown, total, hint, listLegend(true))
 	} else {
-		summary = fmt.Sprintf("global · showing %d of %d%s        ●=here ▸=running ○=idle", shown, total, hint)
+		summary = fmt.Sprintf("global · showing %d of %d%s%s", shown, total, hint, listLegend(false))
 	}
 	fmt.Fprintln(os.Stderr, truncateVisible(summary, width))
 	fmt.Fprintln(os.Stderr)
@@ -371,6 +345,48 @@ func renderGlobal(figs []rpc.FigaroInfoResponse, boundID string, limit int) {
 	}
 }
 
+// listMark is the state column of a list row: here, running or idle.
+func listMark(here, active bool) string {
+	switch {
+	case here:
+		return term.Sym("●", "[here]")
+	case active:
+		return term.Sym("▸", "[running]")
+	}
+	return term.Sym("○", "[idle]")
+}
+
+// listLegend explains the marks; screen-reader mode spells them out and
+// needs none.
+func listLegend(compact bool) string {
+	switch {
+	case term.Accessible():
+		return ""
+	case compact:
+		return " · ● here ▸ running ○ idle"
+	}
+	return "        ●=here ▸=running ○=idle"
+}
+
+// treeBranch joins a child row to the tree; treeStem carries its parent's
+// line past it. Screen-reader mode indents instead.
+func treeBranch(isLast bool) string {
+	switch {
+	case term.Accessible():
+		return "  "
+	case isLast:
+		return "└─"
+	}
+	return "├─"
+}
+
+func treeStem(isLast bool) string {
+	if isLast || term.Accessible() {
+		return "  "
+	}
+	return "│ "
+}
+
 const (
 	listCompactWidth = 100
 	listFullWidth    = 140
@@ -775,6 +791,25 @@ func runAttend(loaded *config.Loaded, spec string) {
 		})
 		return
 	}
+	if spec == "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		acli := mustConnectAngelus(loaded)
+		picked, err := pickAria(ctx, acli)
+		acli.Close()
+		cancel()
+		if err != nil {
+			die("attend: %s", err)
+		}
+		spec = picked
+	}
+	if spec == "+" || spec == "-" {
+		step := 1
+		if spec == "-" {
+			step = -1
+		}
+		runAttendSibling(loaded, step)
+		return
+	}
 	trunk, atMainLT, hasLT, err := parseSendTarget(spec)
 	if err != nil {
 		die("attend: %s", err)
@@ -812,3 +847,77 @@ func runAttend(loaded *config.Loaded, spec string) {
 		return nil
 	})
 }
+
+// runAttendSibling cycles this shell between the candidate answers of its
+// nearest fork point: the live leaves under the frozen parent, one per child
+// branch, in vector order. Rebinding is the whole switch; no IR is touched.
+func runAttendSibling(loaded *config.Loaded, step int) {
+	WithAngelus(loaded, func(acli *angelus.Client) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ppid := os.Getppid()
+		r, rerr := resolveBinding(ctx, acli, ppid)
+		if rerr != nil || !r.Found {
+			die("attend: no aria bound to this shell")
+		}
+		next, pos, n, err := stepAlternative(ctx, acli, ppid, r.FigaroID, step)
+		if err != nil {
+			die("attend: %s", err)
+		}
+		fmt.Fprintf(os.Stderr, "attending %s (alternative %d/%d)\n", next, pos, n)
+		return nil
+	})
+}
+
+// stepAlternative binds ppid's shell to the alternative step places from
+// id's own at its fork point, and returns it with its 1-based position
+// among the n alternatives.
+func stepAlternative(ctx context.Context, acli *angelus.Client, ppid int, id string, step int) (next string, pos, n int, err error) {
+	resp, err := acli.ListGlobal(ctx)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	cands, at := forkCandidates(resp.Figaros, id)
+	if len(cands) < 2 {
+		return "", 0, 0, fmt.Errorf("%s has no alternatives (fork or send --retry-last to make one)", id)
+	}
+	i := (at + step + len(cands)) % len(cands)
+	if err := bindBinding(ctx, acli, ppid, cands[i], 0); err != nil {
+		return "", 0, 0, err
+	}
+	return cands[i], i + 1, len(cands), nil
+}
+
+// forkCandidates lists the alternatives at id's nearest fork point. Each child
+// of the frozen parent stands for one candidate; a child that was itself forked
+// is followed down its continuation (first child) to the live leaf. at is the
+// index of id's own candidate, or -1 if id sits under no conversation fork.
+func forkCandidates(figs []rpc.FigaroInfoResponse, id string) (cands []string, at int) {
+	byID := map[string]rpc.FigaroInfoResponse{}
+	childrenOf := map[string][]rpc.FigaroInfoResponse{}
+	for _, f := range figs {
+		byID[f.ID] = f
+		childrenOf[f.Parent] = append(childrenOf[f.Parent], f)
+	}
+	for p := range childrenOf {
+		kids := childrenOf[p]
+		sort.SliceStable(kids, func(i, j int) bool { return vectorLess(kids[i].Vector, kids[j].Vector) })
+	}
+	self, ok := byID[id]
+	parent, pok := byID[self.Parent]
+	if !ok || !pok || !parent.Frozen || parent.Kind == "null" || parent.Kind == "loadout" {
+		return nil, -1
+	}
+	at = -1
+	for _, kid := range childrenOf[parent.ID] {
+		leaf := kid
+		for leaf.Frozen && len(childrenOf[leaf.ID]) > 0 {
+			leaf = childrenOf[leaf.ID][0]
+		}
+		if kid.ID == id || leaf.ID == id {
+			at = len(cands)
+		}
+		cands = append(cands, leaf.ID)
+	}
+	return cands, at
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/term"
 )
 
@@ -52,3 +53,33 @@ func TestRenderListRowsUsesReducedColumnsOnMediumTerminals(t *testing.T) {
 		}
 	}
 }
+
+func TestForkCandidatesFollowsReforkedBranchesToTheirLeaf(t *testing.T) {
+	figs := []rpc.FigaroInfoResponse{
+		{ID: "root", Kind: "conversation", Vector: []int{0}, Frozen: true},
+		{ID: "cont", Kind: "conversation", Vector: []int{0, 0}, Parent: "root"},
+		{ID: "alt", Kind: "conversation", Vector: []int{0, 1}, Parent: "root", Frozen: true},
+		{ID: "alt-cont", Kind: "conversation", Vector: []int{0, 1, 0}, Parent: "alt"},
+		{ID: "alt-alt", Kind: "conversation", Vector: []int{0, 1, 1}, Parent: "alt"},
+	}
+	cands, at := forkCandidates(figs, "cont")
+	if strings.Join(cands, ",") != "cont,alt-cont" || at != 0 {
+		t.Fatalf("forkCandidates(cont) = %v @ %d", cands, at)
+	}
+	// A leaf under a nested fork cycles among that fork's own children.
+	cands, at = forkCandidates(figs, "alt-alt")
+	if strings.Join(cands, ",") != "alt-cont,alt-alt" || at != 1 {
+		t.Fatalf("forkCandidates(alt-alt) = %v @ %d", cands, at)
+	}
+}
+
+func TestForkCandidatesIgnoresUnforkedConversations(t *testing.T) {
+	figs := []rpc.FigaroInfoResponse{
+		{ID: "lo", Kind: "loadout"},
+		{ID: "a", Kind: "conversation", Vector: []int{0}, Parent: "lo"},
+		{ID: "b", Kind: "conversation", Vector: []int{1}, Parent: "lo"},
+	}
+	if cands, at := forkCandidates(figs, "a"); cands != nil || at != -1 {
+		t.Fatalf("top-level conversations are not alternatives: %v @ %d", cands, at)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jack-work/figaro/internal/config"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+)
+
+// providerMiddleware wraps every provider the daemon's factory builds, set
+// from config [middleware] at daemon startup.
+var providerMiddleware []providerPkg.Middleware
+
+// applyMiddleware builds the [middleware] chain. An unknown or misconfigured
+// entry is reported and skipped; the rest still apply.
+func applyMiddleware(m config.Middleware, stateDir string) []error {
+	var errs []error
+	var chain []providerPkg.Middleware
+	for _, name := range m.Chain {
+		mw, err := buildMiddleware(name, m, stateDir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		chain = append(chain, mw)
+	}
+	providerMiddleware = chain
+	return errs
+}
+
+func buildMiddleware(name string, m config.Middleware, stateDir string) (providerPkg.Middleware, error) {
+	switch name {
+	case "rate_limit":
+		if m.RatePerMinute <= 0 {
+			return nil, fmt.Errorf("rate_limit needs rate_per_minute > 0")
+		}
+		return providerPkg.RateLimit(m.RatePerMinute), nil
+	case "audit":
+		path := m.AuditPath
+		if path == "" {
+			path = filepath.Join(stateDir, "provider-audit.jsonl")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+		// Held open for the daemon's life; O_APPEND keeps lines whole.
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+		return providerPkg.Audit(f), nil
+	}
+	return nil, fmt.Errorf("unknown middleware %q (want rate_limit or audit)", name)
+}
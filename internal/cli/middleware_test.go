@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func TestApplyMiddleware(t *testing.T) {
+	t.Cleanup(func() { providerMiddleware = nil })
+	state := t.TempDir()
+
+	errs := applyMiddleware(config.Middleware{Chain: []string{"rate_limit", "audit", "bogus"}, RatePerMinute: 0}, state)
+	if len(errs) != 2 || len(providerMiddleware) != 1 {
+		t.Fatalf("errs %v, chain %d; want rate_limit and bogus rejected", errs, len(providerMiddleware))
+	}
+	if _, err := os.Stat(filepath.Join(state, "provider-audit.jsonl")); err != nil {
+		t.Errorf("audit log not created at the default path: %v", err)
+	}
+
+	if errs := applyMiddleware(config.Middleware{Chain: []string{"rate_limit"}, RatePerMinute: 30}, state); len(errs) != 0 || len(providerMiddleware) != 1 {
+		t.Errorf("rate_limit with a rate: errs %v, chain %d", errs, len(providerMiddleware))
+	}
+	if applyMiddleware(config.Middleware{}, state); providerMiddleware != nil {
+		t.Error("no chain should clear the middleware")
+	}
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/mirror"
+)
+
+// A mirror is an encrypted copy of what a backup bundle carries, kept in
+// object storage: the config dir without credentials or mirror keys,
+// the state dir's records and the aria store.
+
+// mirrorTimeout bounds one push or pull.
+const mirrorTimeout = 10 * time.Minute
+
+func mirrorRoots(loaded *config.Loaded) []backupRoot {
+	roots := backupRoots(loaded, false)
+	roots[0].Skip = append(roots[0].Skip, "mirrors")
+	return roots
+}
+
+func mirrorKeyPath(loaded *config.Loaded, name string, m config.Mirror) string {
+	if m.KeyFile != "" {
+		return m.KeyFile
+	}
+	return filepath.Join(loaded.ConfigDir, "mirrors", name+".key")
+}
+
+// mirrorStore builds the storage target, reading credentials from the
+// environment variables the table names.
+func mirrorStore(name string, m config.Mirror, getenv func(string) string) (mirror.Store, error) {
+	if m.URL == "" {
+		return nil, fmt.Errorf("mirror %s: url is required", name)
+	}
+	env := func(set, def string) string {
+		if set != "" {
+			return getenv(set)
+		}
+		return getenv(def)
+	}
+	switch m.Kind {
+	case "", "s3":
+		region := m.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &mirror.S3{URL: m.URL, Region: region,
+			AccessKey: env(m.IDEnv, "AWS_ACCESS_KEY_ID"), SecretKey: env(m.SecretEnv, "AWS_SECRET_ACCESS_KEY")}, nil
+	case "gcs":
+		return &mirror.S3{URL: m.URL, Region: "auto",
+			AccessKey: env(m.IDEnv, "GCS_HMAC_ID"), SecretKey: env(m.SecretEnv, "GCS_HMAC_SECRET")}, nil
+	case "webdav":
+		return &mirror.WebDAV{URL: m.URL,
+			User: env(m.IDEnv, "FIGARO_WEBDAV_USER"), Password: env(m.SecretEnv, "FIGARO_WEBDAV_PASSWORD")}, nil
+	case "dir":
+		return mirror.Dir(m.URL), nil
+	}
+	return nil, fmt.Errorf("mirror %s: kind %q: want s3, gcs, webdav or dir", name, m.Kind)
+}
+
+// mirrorFiles maps each file a mirror carries to its local path. The
+// store's lock and any sync repository are machine-local.
+func mirrorFiles(roots []backupRoot) (map[string]string, error) {
+	files := map[string]string{}
+	err := walkRoots(roots, func(name, p string, d fs.DirEntry) error {
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != ".lock" && !strings.HasSuffix(d.Name(), ".mirror-tmp") {
+			files[name] = p
+		}
+		return nil
+	})
+	return files, err
+}
+
+// mirrorResolver maps a mirrored root/rel path back into roots.
+func mirrorResolver(roots []backupRoot) func(string) (string, error) {
+	return func(p string) (string, error) {
+		rootName, rel, _ := strings.Cut(movedEntry(p), "/")
+		for _, r := range roots {
+			if r.Name == rootName && rel != "" && filepath.IsLocal(filepath.FromSlash(rel)) {
+				return filepath.Join(r.Dir, filepath.FromSlash(rel)), nil
+			}
+		}
+		return "", fmt.Errorf("mirror holds unexpected path %q", p)
+	}
+}
+
+// mirrorNames picks the mirrors a command acts on: those named, or all.
+func mirrorNames(loaded *config.Loaded, args []string) ([]string, error) {
+	if len(loaded.Config.Mirrors) == 0 {
+		return nil, fmt.Errorf("no mirrors configured; add a [mirrors.<name>] table to %s", loaded.ConfigPath)
+	}
+	if len(args) == 0 {
+		return slices.Sorted(maps.Keys(loaded.Config.Mirrors)), nil
+	}
+	for _, a := range args {
+		if _, ok := loaded.Config.Mirrors[a]; !ok {
+			return nil, fmt.Errorf("no mirror %q (have %s)", a, strings.Join(slices.Sorted(maps.Keys(loaded.Config.Mirrors)), ", "))
+		}
+	}
+	return args, nil
+}
+
+// pushMirror pushes to one mirror, creating its key on first use. The
+// mirror is this host's; claim takes over one another host pushes to.
+func pushMirror(ctx context.Context, loaded *config.Loaded, name string, claim bool) (mirror.Stats, bool, error) {
+	m := loaded.Config.Mirrors[name]
+	st, err := mirrorStore(name, m, os.Getenv)
+	if err != nil {
+		return mirror.Stats{}, false, err
+	}
+	key, created, err := mirror.LoadKey(mirrorKeyPath(loaded, name, m), true)
+	if err != nil {
+		return mirror.Stats{}, false, err
+	}
+	roots := mirrorRoots(loaded)
+	files, err := mirrorFiles(roots)
+	if err != nil {
+		return mirror.Stats{}, created, err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return mirror.Stats{}, created, err
+	}
+	stats, err := mirror.Push(ctx, st, key, host, claim, files)
+	var oe *mirror.OwnerError
+	if errors.As(err, &oe) {
+		err = fmt.Errorf("pushed from %s, not this machine (%s); mirror push --claim %s takes it over and deletes what only %s has", oe.Owner, host, name, oe.Owner)
+	}
+	return stats, created, err
+}
+
+func runMirrorPush(loaded *config.Loaded, args []string, claim bool) error {
+	names, err := mirrorNames(loaded, args)
+	if err != nil {
+		return err
+	}
+	var failed bool
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), mirrorTimeout)
+		stats, created, err := pushMirror(ctx, loaded, name, claim)
+		cancel()
+		if created {
+			fmt.Printf("%s: new key at %s; copy it somewhere safe, the mirror cannot be read without it\n",
+				name, mirrorKeyPath(loaded, name, loaded.Config.Mirrors[name]))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: %d uploaded (%s), %d unchanged, %d removed\n",
+			name, stats.Changed, fmtBytes(stats.Bytes), stats.Unchanged, stats.Removed)
+	}
+	if failed {
+		return fmt.Errorf("push failed")
+	}
+	return nil
+}
+
+func runMirrorPull(loaded *config.Loaded, name string, replace bool) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	if _, err := mirrorNames(loaded, []string{name}); err != nil {
+		return err
+	}
+	m := loaded.Config.Mirrors[name]
+	st, err := mirrorStore(name, m, os.Getenv)
+	if err != nil {
+		return err
+	}
+	key, _, err := mirror.LoadKey(mirrorKeyPath(loaded, name, m), false)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no key at %s; copy it from the machine that pushed", mirrorKeyPath(loaded, name, m))
+	}
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mirrorTimeout)
+	defer cancel()
+	stats, err := mirror.Pull(ctx, st, key, mirrorResolver(mirrorRoots(loaded)), replace)
+	var ce *mirror.ConflictError
+	if errors.As(err, &ce) {
+		return fmt.Errorf("%d local file(s) differ from the mirror (%s); pass --replace to overwrite them", len(ce.Paths), summarizePaths(ce.Paths))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d fetched (%s), %d unchanged\n", name, stats.Changed, fmtBytes(stats.Bytes), stats.Unchanged)
+	return nil
+}
+
+func runMirrorStatus(loaded *config.Loaded, args []string) error {
+	names, err := mirrorNames(loaded, args)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		m := loaded.Config.Mirrors[name]
+		kind := m.Kind
+		if kind == "" {
+			kind = "s3"
+		}
+		fmt.Printf("%s  %s %s", name, kind, m.URL)
+		if m.Every != "" {
+			fmt.Printf("  every %s", m.Every)
+		}
+		fmt.Println()
+		st, err := mirrorStore(name, m, os.Getenv)
+		if err != nil {
+			fmt.Printf("  %v\n", err)
+			continue
+		}
+		key, _, err := mirror.LoadKey(mirrorKeyPath(loaded, name, m), false)
+		if err != nil {
+			fmt.Printf("  no key (%v)\n", err)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		man, err := mirror.ReadManifest(ctx, st, key)
+		cancel()
+		if err != nil {
+			fmt.Printf("  %v\n", err)
+			continue
+		}
+		if len(man.Files) == 0 {
+			fmt.Println("  empty; never pushed")
+			continue
+		}
+		var size int64
+		for _, e := range man.Files {
+			size += e.Size
+		}
+		fmt.Printf("  %d files, %s, pushed %s", len(man.Files), fmtBytes(size), man.Updated.Local().Format(time.DateTime))
+		if man.Owner != "" {
+			fmt.Printf(" from %s", man.Owner)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func summarizePaths(paths []string) string {
+	if len(paths) <= 3 {
+		return strings.Join(paths, ", ")
+	}
+	return strings.Join(paths[:3], ", ") + fmt.Sprintf(", and %d more", len(paths)-3)
+}
+
+// runMirrors is the daemon's loop for mirrors with an every interval.
+// A failed push is logged and retried at the next tick.
+func runMirrors(ctx context.Context, loaded *config.Loaded) {
+	for name, m := range loaded.Config.Mirrors {
+		if m.Every == "" {
+			continue
+		}
+		every, err := time.ParseDuration(m.Every)
+		if err != nil || every < time.Minute {
+			slog.Warn("config [mirrors]: every must be a duration of at least 1m", "mirror", name, "every", m.Every)
+			continue
+		}
+		go func() {
+			t := time.NewTicker(every)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+				}
+				pctx, cancel := context.WithTimeout(ctx, mirrorTimeout)
+				stats, _, err := pushMirror(pctx, loaded, name, false)
+				cancel()
+				if err != nil {
+					slog.Warn("mirror: push failed", "mirror", name, "err", err)
+					continue
+				}
+				slog.Info("mirror: pushed", "mirror", name, "uploaded", stats.Changed, "bytes", stats.Bytes, "removed", stats.Removed)
+			}
+		}()
+	}
+}
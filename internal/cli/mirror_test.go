@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/mirror"
+)
+
+func TestMirrorStore(t *testing.T) {
+	env := envOf(map[string]string{"AWS_ACCESS_KEY_ID": "aws-id", "MY_ID": "mine", "FIGARO_WEBDAV_USER": "u"})
+	st, err := mirrorStore("m", config.Mirror{URL: "https://s3.example/b"}, env)
+	if s3, ok := st.(*mirror.S3); err != nil || !ok || s3.AccessKey != "aws-id" || s3.Region != "us-east-1" {
+		t.Errorf("s3 default: %#v %v", st, err)
+	}
+	st, _ = mirrorStore("m", config.Mirror{Kind: "gcs", URL: "https://storage.googleapis.com/b", IDEnv: "MY_ID"}, env)
+	if s3 := st.(*mirror.S3); s3.AccessKey != "mine" || s3.Region != "auto" {
+		t.Errorf("gcs: %#v", s3)
+	}
+	st, _ = mirrorStore("m", config.Mirror{Kind: "webdav", URL: "https://dav.example/f"}, env)
+	if dav := st.(*mirror.WebDAV); dav.User != "u" {
+		t.Errorf("webdav: %#v", dav)
+	}
+	if _, err := mirrorStore("m", config.Mirror{Kind: "ftp", URL: "x"}, env); err == nil || !strings.Contains(err.Error(), "ftp") {
+		t.Errorf("bad kind: %v", err)
+	}
+	if _, err := mirrorStore("m", config.Mirror{}, env); err == nil {
+		t.Error("missing url accepted")
+	}
+}
+
+func TestMirrorFilesRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, filepath.Join(src, "config"), map[string]string{
+		"config.toml":      "x = 1\n",
+		"mirrors/m.key":    "key",
+		"providers/a.toml": "secret",
+	})
+	writeTree(t, filepath.Join(src, "data"), map[string]string{
+		"arias/.lock":      "",
+		"arias/ir/1.jsonl": "{}\n",
+		"arias/.git/HEAD":  "ref",
+	})
+	writeTree(t, filepath.Join(src, "state"), map[string]string{
+		"otel/traces.jsonl": "t",
+	})
+	roots := testRoots(src)
+	roots[0].Skip = append(roots[0].Skip, "mirrors")
+	files, err := mirrorFiles(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files["config/config.toml"] == "" || files["data/arias/ir/1.jsonl"] == "" {
+		t.Fatalf("files: %v", files)
+	}
+
+	ctx := context.Background()
+	key, _, _ := mirror.LoadKey(filepath.Join(t.TempDir(), "k"), true)
+	st := mirror.Dir(t.TempDir())
+	if _, err := mirror.Push(ctx, st, key, "host", false, files); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+	if _, err := mirror.Pull(ctx, st, key, mirrorResolver(testRoots(dst)), false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(dst, "data", "arias", "ir", "1.jsonl")); got != "{}\n" {
+		t.Errorf("pulled %q", got)
+	}
+	if p, _ := mirrorResolver(testRoots(dst))("state/arias/ir/2.jsonl"); p != filepath.Join(dst, "data", "arias", "ir", "2.jsonl") {
+		t.Errorf("pre-data-dir path resolved to %q", p)
+	}
+	if _, err := mirrorResolver(testRoots(dst))("state/../../etc/passwd"); err == nil {
+		t.Error("escaping path resolved")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "config", "providers")); err == nil {
+		t.Error("credentials mirrored")
+	}
+}
+
+func TestMirrorNames(t *testing.T) {
+	loaded := &config.Loaded{}
+	if _, err := mirrorNames(loaded, nil); err == nil {
+		t.Error("no mirrors: no error")
+	}
+	loaded.Config.Mirrors = map[string]config.Mirror{"b": {}, "a": {}}
+	if got, _ := mirrorNames(loaded, nil); strings.Join(got, ",") != "a,b" {
+		t.Errorf("all = %v", got)
+	}
+	if _, err := mirrorNames(loaded, []string{"c"}); err == nil || !strings.Contains(err.Error(), "have a, b") {
+		t.Errorf("unknown: %v", err)
+	}
+}
@@ -1,6 +1,41 @@
 package cli
 
-import "github.com/jack-work/figaro/internal/term"
+import (
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/render"
+	"github.com/jack-work/figaro/internal/term"
+)
+
+// Role labels for messageHeader; [theme] user_label / assistant_label
+// override them.
+var (
+	userLabel      = "❯ you"
+	assistantLabel = "‹ figaro"
+)
+
+// promptAuthor is the name this client's prompts carry (see authorName).
+// A user message with another author is headed with that name instead of
+// userLabel.
+var promptAuthor string
+
+// authorName resolves who is prompting: $FIGARO_AUTHOR, then the config
+// author key, then the login name.
+func authorName(configured string, getenv func(string) string) string {
+	if a := strings.TrimSpace(getenv("FIGARO_AUTHOR")); a != "" {
+		return a
+	}
+	if a := strings.TrimSpace(configured); a != "" {
+		return a
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return getenv("USER")
+}
 
 // messageHeader returns the user-visible role label drawn above a
 // message. It is the single source of truth for "who is speaking" in
@@ -9,20 +44,87 @@ import "github.com/jack-work/figaro/internal/term"
 //
 // Convention:
 //
-//	"user"      → "❯ you"     (bright cyan — your voice)
+//	"user"      → "❯ you"     (accent, cyan by default — your voice)
+//	"user" from another author → "❯ <author>"
 //	"assistant" → "‹ figaro"  (dim — the agent's voice)
 //	anything else (e.g. "system", "tool") → no header
 //
 // A steering interjection inside an assistant turn is a NODE
 // (livedoc.NodeSteering), not a message role, and carries its own
 // inline "↳ you" marker; this helper does not touch it.
-func messageHeader(role string) string {
+func messageHeader(role, author string) string {
 	switch role {
 	case "user":
-		return term.Cyan("❯ you")
+		if author != "" && author != promptAuthor {
+			return term.Accent(term.Sym("❯ "+author, author+":"))
+		}
+		return term.Accent(userLabel)
 	case "assistant":
-		return term.Dim("‹ figaro")
+		return term.Dim(assistantLabel)
 	default:
 		return ""
 	}
 }
+
+// applyTheme installs the config [theme] into the renderers. A bad entry is
+// a warning: the rest of the theme still applies and the command runs.
+func applyTheme(th config.Theme) []error {
+	var errs []error
+	if err := render.SetStyle(th.Markdown); err != nil {
+		errs = append(errs, err)
+	}
+	if err := render.SetCodeStyle(th.Code); err != nil {
+		errs = append(errs, err)
+	}
+	render.SetColorDepth(term.Depth())
+	if err := term.SetAccent(th.Accent); err != nil {
+		errs = append(errs, err)
+	}
+	if th.UserLabel != "" {
+		userLabel = th.UserLabel
+	}
+	if th.AssistantLabel != "" {
+		assistantLabel = th.AssistantLabel
+	}
+	return errs
+}
+
+// applyAccessible switches to screen-reader output when --plain-accessible,
+// $FIGARO_ACCESSIBLE or [accessibility] enabled asks for it: ASCII markdown
+// without color, words for glyphs, and textual role labels. It runs after
+// applyTheme and overrides it.
+func applyAccessible(a config.Accessibility) {
+	if !accessibleFlag && !a.Enabled && !envTruthy(os.Getenv("FIGARO_ACCESSIBLE")) {
+		return
+	}
+	term.SetAccessible(true)
+	_ = render.SetStyle("ascii")
+	_ = render.SetCodeStyle("")
+	userLabel, assistantLabel = "You:", "Figaro:"
+	if a.UserLabel != "" {
+		userLabel = a.UserLabel
+	}
+	if a.AssistantLabel != "" {
+		assistantLabel = a.AssistantLabel
+	}
+}
+
+// accessibleFlag is --plain-accessible, taken off the command line by
+// extractAccessibleFlag.
+var accessibleFlag bool
+
+func extractAccessibleFlag(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i, a := range args {
+		if a == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		if a == "--plain-accessible" {
+			accessibleFlag = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/render"
+	"github.com/jack-work/figaro/internal/term"
+)
+
+func TestMessageHeaderAuthor(t *testing.T) {
+	defer func(a string) { promptAuthor = a }(promptAuthor)
+	promptAuthor = "me"
+	for _, tc := range []struct{ author, want string }{
+		{"", userLabel},
+		{"me", userLabel},
+		{"alice", "❯ alice"},
+	} {
+		if got := messageHeader("user", tc.author); !strings.Contains(got, tc.want) {
+			t.Errorf("author %q: header %q, want %q", tc.author, got, tc.want)
+		}
+	}
+	if got := messageHeader("assistant", "alice"); strings.Contains(got, "alice") {
+		t.Errorf("assistant header %q names the author", got)
+	}
+}
+
+func TestAuthorName(t *testing.T) {
+	if got := authorName("cfg", envOf(map[string]string{"FIGARO_AUTHOR": "env"})); got != "env" {
+		t.Errorf("env: %q", got)
+	}
+	if got := authorName(" cfg ", envOf(nil)); got != "cfg" {
+		t.Errorf("config: %q", got)
+	}
+	if got := authorName("", envOf(map[string]string{"USER": "login"})); got == "" {
+		t.Error("no fallback name")
+	}
+}
+
+func TestApplyAccessible(t *testing.T) {
+	defer func(u, a string, f bool) {
+		userLabel, assistantLabel, accessibleFlag = u, a, f
+		term.SetAccessible(false)
+		render.SetStyle("")
+	}(userLabel, assistantLabel, accessibleFlag)
+	defer func(a string) { promptAuthor = a }(promptAuthor)
+	promptAuthor = "me"
+
+	applyAccessible(config.Accessibility{})
+	if term.Accessible() {
+		t.Fatal("accessible without being asked")
+	}
+	accessibleFlag = true
+	applyAccessible(config.Accessibility{AssistantLabel: "Figaro says:"})
+	if got := messageHeader("user", ""); got != "You:" {
+		t.Errorf("user header %q", got)
+	}
+	if got := messageHeader("user", "alice"); got != "alice:" {
+		t.Errorf("other author header %q", got)
+	}
+	if got := messageHeader("assistant", ""); got != "Figaro says:" {
+		t.Errorf("assistant header %q", got)
+	}
+	rows := renderToolNode(livedoc.Node{Name: "bash", Status: livedoc.StatusOK, Output: "ok"}, 60, 10, 0, false)
+	if got := strings.Join(rows, "\n"); got != "[done] bash\n    ok" {
+		t.Errorf("tool node:\n%q", got)
+	}
+	if got := listMark(false, true) + treeBranch(false) + treeStem(false); got != "[running]    " {
+		t.Errorf("list glyphs %q", got)
+	}
+	if got := labeledRule("[disconnected]"); got != "[disconnected]" {
+		t.Errorf("rule %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/render"
+	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/term"
 )
 
@@ -25,8 +27,14 @@ const (
 // verbose additionally expands tool inputs to the full wrapped command.
 type renderSettings struct {
 	verbose  bool
-	jsonMode bool // -j / --json: emit a single {aria_id, ...} JSON line on stdout instead of a live render
-	listen   bool // -l / --listen: auto-enter transcript and stay open past turn-done
+	jsonMode bool   // -j / --json: emit a single {aria_id, ...} JSON line on stdout instead of a live render
+	listen   bool   // -l / --listen: auto-enter transcript and stay open past turn-done
+	output   string // --output <path>: tee the raw assistant markdown to a file
+	force    bool   // --force: send the prompt past a reached [budget] limit
+
+	// patch rides the prompt's chalkboard input (e.g. --retry-last --model),
+	// so it lands on the aria the prompt reaches rather than the one resolved.
+	patch *rpc.ChalkboardPatch
 }
 
 // renderNodeList renders a unit's whole node list to terminal rows. The list
@@ -107,6 +115,16 @@ func clipToWidth(s string, width int) string {
 	return b.String()
 }
 
+// clipTailToWidth is clipToWidth keeping the END of s: an input line whose
+// caret sits at the end stays visible as it outgrows the row.
+func clipTailToWidth(s string, width int) string {
+	rs := []rune(s)
+	for len(rs) > 0 && term.VisibleLen(string(rs)) > width {
+		rs = rs[1:]
+	}
+	return clipToWidth(string(rs), width)
+}
+
 // scrolledGlyph marks a tool header that the viewport-overflow flush committed
 // to scrollback while the tool was still running. Scrollback is immutable, so
 // the tool's eventual ✓/✗ can never land there; freezing the live spinner frame
@@ -178,7 +196,7 @@ func renderThinkingNode(n livedoc.Node, width int) []string {
 // the assistant's turn, distinct from prose and thinking.
 func renderSteeringNode(n livedoc.Node, width int) []string {
 	rows := render.Prose(n.Markdown, width)
-	return append([]string{term.Dim("↳ you")}, rows...)
+	return append([]string{term.Dim(term.Sym("↳ you", "you, mid-turn:"))}, rows...)
 }
 
 // renderToolNode draws a tool as a widget with ZERO per-tool control flow:
@@ -191,18 +209,18 @@ func renderToolNode(n livedoc.Node, width, bashCap int, tick uint64, expand bool
 	var glyph string
 	switch n.Status {
 	case livedoc.StatusOK:
-		glyph = term.Green("✓")
+		glyph = term.Green(term.Sym("✓", "[done]"))
 	case livedoc.StatusError:
-		glyph = term.Red("✗")
+		glyph = term.Red(term.Sym("✗", "[failed]"))
 	default:
 		frames := livedoc.SpinnerFrames
-		glyph = term.Cyan(string(frames[int(tick)%len(frames)]))
+		glyph = term.Accent(term.Sym(string(frames[int(tick)%len(frames)]), "[running]"))
 	}
 	name := n.Name
 	if name == "" {
 		name = "tool"
 	}
-	header := glyph + " " + term.Cyan(name)
+	header := glyph + " " + term.Accent(name)
 	if n.Summary != "" {
 		header = header + " " + term.Dim(truncCols(n.Summary, toolSummaryCap))
 	}
@@ -219,8 +237,7 @@ func renderToolNode(n livedoc.Node, width, bashCap int, tick uint64, expand bool
 		}
 		sort.Strings(keys)
 		for _, k := range keys {
-			line := fmt.Sprintf("%s=%v", k, n.Args[k])
-			for _, l := range hardWrap(line, width-len(g)) {
+			for _, l := range hardWrap(toolArgLine(k, n.Args[k]), width-len(g)) {
 				rows = append(rows, term.Dim(g+l))
 			}
 		}
@@ -243,9 +260,9 @@ func renderToolNode(n livedoc.Node, width, bashCap int, tick uint64, expand bool
 		shown, total := tailOutput(safe, bashCap)
 		lines := strings.Split(shown, "\n")
 		if bashCap >= 0 && total > bashCap {
-			rows = append(rows, term.Dim(fmt.Sprintf("  │ … last %d of %d lines", bashCap, total)))
+			rows = append(rows, term.Dim(fmt.Sprintf("  %s… last %d of %d lines", term.Sym("│ ", ""), bashCap, total)))
 		}
-		const gutter = "  │ "
+		gutter := term.Sym("  │ ", "    ")
 		for _, l := range lines {
 			rows = append(rows, term.Dim(gutter)+truncCols(l, width-len(gutter)))
 		}
@@ -253,6 +270,19 @@ func renderToolNode(n livedoc.Node, width, bashCap int, tick uint64, expand bool
 	return rows
 }
 
+// toolArgLine renders one argument for the expanded tool view. Scalars stay
+// key=value; objects and arrays are pretty-printed JSON under the key, so a
+// nested argument reads as structure instead of Go's map[...] dump.
+func toolArgLine(k string, v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if b, err := json.MarshalIndent(v, "", "  "); err == nil {
+			return k + "=" + string(b)
+		}
+	}
+	return fmt.Sprintf("%s=%v", k, v)
+}
+
 func tailOutput(output string, limit int) (string, int) {
 	total := 1 + strings.Count(output, "\n")
 	if limit < 0 || total <= limit {
@@ -289,7 +319,13 @@ func toolDuration(n livedoc.Node, now time.Time) string {
 	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
 }
 
+// formatToolTime is the expanded tool view's timestamp: millisecond-precise
+// local time, since tool timings are read for debugging, or an age when
+// relative time is on.
 func formatToolTime(ms int64) string {
+	if relativeTime.Load() {
+		return relAge(ms) + " ago"
+	}
 	return time.UnixMilli(ms).Format("2006-01-02 15:04:05.000 MST")
 }
 
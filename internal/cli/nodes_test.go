@@ -103,6 +103,25 @@ func TestRenderToolNode_TimingAndVerboseDetails(t *testing.T) {
 	}
 }
 
+func TestRenderToolNode_ExpandedArgsPrettyPrintStructure(t *testing.T) {
+	n := livedoc.Node{
+		Type: livedoc.NodeTool, Name: "edit", Status: livedoc.StatusOK,
+		Args: map[string]interface{}{
+			"path":  "a.go",
+			"edits": []interface{}{map[string]interface{}{"old": "x", "new": "y"}},
+		},
+	}
+	got := stripANSI(strings.Join(renderToolNode(n, 120, 5, 0, true), "\n"))
+	for _, want := range []string{"path=a.go", "edits=[", `    "new": "y",`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expanded args missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "map[") {
+		t.Fatalf("nested args leaked Go map syntax:\n%s", got)
+	}
+}
+
 func TestTailOutput(t *testing.T) {
 	output := "one\ntwo\nthree\nfour"
 	if got, total := tailOutput(output, 2); got != "three\nfour" || total != 4 {
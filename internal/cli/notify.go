@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/term"
+)
+
+// turnNotifier announces a turn that ran at least after: a terminal bell
+// and, optionally, a desktop notification. The zero value is off.
+type turnNotifier struct {
+	after   time.Duration
+	bell    bool
+	desktop bool
+	post    func(title, body string) error // term.Notify; swapped in tests
+}
+
+// turnNotify is the active notifier, set from config [notify] at startup.
+var turnNotify turnNotifier
+
+// notifyPosts tracks desktop posts still in flight; waitNotify holds a
+// command's exit for them.
+var notifyPosts sync.WaitGroup
+
+// notifyWait bounds how long a command waits on exit for a desktop post.
+const notifyWait = 2 * time.Second
+
+// applyNotify installs the config [notify] table.
+func applyNotify(n config.Notify) []error {
+	if n.AfterSeconds < 0 {
+		return []error{fmt.Errorf("after_seconds %d: must not be negative", n.AfterSeconds)}
+	}
+	turnNotify = turnNotifier{
+		after:   time.Duration(n.AfterSeconds) * time.Second,
+		bell:    n.Bell == nil || *n.Bell,
+		desktop: n.Desktop,
+		post:    term.Notify,
+	}
+	return nil
+}
+
+// turnDone announces a settled turn that took elapsed. The bell goes to out
+// (the terminal the session draws on); the desktop post runs off the caller's
+// goroutine, which holds the render lock, and waitNotify lets it finish.
+func (n turnNotifier) turnDone(out io.Writer, figaroID, reason string, elapsed time.Duration) {
+	if n.after <= 0 || elapsed < n.after || (!n.bell && !n.desktop) {
+		return
+	}
+	if n.bell {
+		io.WriteString(out, term.Bell)
+	}
+	if n.desktop && n.post != nil {
+		title, body := turnNotice(figaroID, reason, elapsed)
+		notifyPosts.Add(1)
+		go func() {
+			defer notifyPosts.Done()
+			if err := n.post(title, body); err != nil {
+				fmt.Fprintf(os.Stderr, "\nnotify: %s\n", err)
+			}
+		}()
+	}
+}
+
+// waitNotify waits up to notifyWait for desktop posts still in flight, so a
+// command that exits right after turn-done doesn't drop its notification.
+func waitNotify() {
+	done := make(chan struct{})
+	go func() {
+		notifyPosts.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(notifyWait):
+	}
+}
+
+// turnNotice is the desktop notification's title and body.
+func turnNotice(figaroID, reason string, elapsed time.Duration) (string, string) {
+	what := "finished"
+	switch r := strings.ToLower(reason); {
+	case strings.HasPrefix(r, "error:"):
+		what = "failed"
+	case strings.Contains(r, "interrupt"):
+		what = "was interrupted"
+	}
+	return "figaro", fmt.Sprintf("%s %s after %s", figaroID, what, elapsed.Round(time.Second))
+}
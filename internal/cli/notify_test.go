@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func TestTurnNotifierRingsOnlyForLongTurns(t *testing.T) {
+	posted := make(chan string, 1)
+	n := turnNotifier{after: 30 * time.Second, bell: true, desktop: true, post: func(title, body string) error {
+		posted <- title + ": " + body
+		return nil
+	}}
+
+	var out strings.Builder
+	n.turnDone(&out, "a1", "end_turn", 10*time.Second)
+	if out.Len() != 0 {
+		t.Fatalf("short turn rang the bell: %q", out.String())
+	}
+
+	n.turnDone(&out, "a1", "error: overloaded", 95*time.Second)
+	if out.String() != "\a" {
+		t.Fatalf("long turn wrote %q, want a bell", out.String())
+	}
+	select {
+	case got := <-posted:
+		if got != "figaro: a1 failed after 1m35s" {
+			t.Fatalf("desktop notice %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no desktop notification posted")
+	}
+}
+
+func TestWaitNotifyHoldsForPost(t *testing.T) {
+	var posted atomic.Bool
+	n := turnNotifier{after: time.Second, desktop: true, post: func(title, body string) error {
+		time.Sleep(50 * time.Millisecond)
+		posted.Store(true)
+		return nil
+	}}
+	n.turnDone(io.Discard, "a1", "end_turn", time.Minute)
+	waitNotify()
+	if !posted.Load() {
+		t.Fatal("waitNotify returned before the desktop post finished")
+	}
+}
+
+func TestApplyNotifyDefaults(t *testing.T) {
+	defer func(old turnNotifier) { turnNotify = old }(turnNotify)
+
+	if errs := applyNotify(config.Notify{}); len(errs) != 0 || turnNotify.after != 0 {
+		t.Fatalf("unset [notify] should be off: %+v %v", turnNotify, errs)
+	}
+	if errs := applyNotify(config.Notify{AfterSeconds: 20}); len(errs) != 0 || !turnNotify.bell || turnNotify.desktop {
+		t.Fatalf("after_seconds alone should ring the bell only: %+v %v", turnNotify, errs)
+	}
+	if errs := applyNotify(config.Notify{AfterSeconds: -1}); len(errs) != 1 {
+		t.Fatalf("negative after_seconds accepted: %v", errs)
+	}
+}
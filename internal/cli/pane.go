@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// paneCommand is the multiplexer invocation that splits the current window
+// and runs `figaro listen <id>` in the new pane, started in cwd. below
+// stacks the pane under the current one instead of beside it. getenv finds
+// the multiplexer: $TMUX for tmux, $STY for GNU screen.
+func paneCommand(getenv func(string) string, exe, ariaID, cwd string, below bool) ([]string, error) {
+	switch {
+	case getenv("TMUX") != "":
+		dir := "-h"
+		if below {
+			dir = "-v"
+		}
+		return []string{"tmux", "split-window", dir, "-c", cwd, exe, "listen", ariaID}, nil
+	case getenv("STY") != "":
+		split := "split -v"
+		if below {
+			split = "split"
+		}
+		quote := func(s string) string { return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"` }
+		return []string{"screen", "-X", "eval", split, "focus", "chdir " + quote(cwd), "screen " + quote(exe) + " listen " + ariaID}, nil
+	}
+	return nil, fmt.Errorf("not inside tmux or screen (run figaro listen %s in another terminal)", ariaID)
+}
+
+// runPane opens a live view of an aria in a split beside this shell. The
+// pane's shell has its own pid, so the binding is resolved here and the id
+// passed on explicitly.
+func runPane(loaded *config.Loaded, ariaID string, below bool) {
+	if ariaID == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		acli := mustConnectAngelus(loaded)
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err == nil && !r.Found {
+			ariaID, err = pickAria(ctx, acli)
+		} else if err == nil {
+			ariaID = r.FigaroID
+		}
+		acli.Close()
+		cancel()
+		if err != nil {
+			die("pane: %s", err)
+		}
+	} else if err := rpc.ValidateAriaID(ariaID); err != nil {
+		die("pane: %s", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "figaro"
+	}
+	cwd, _ := os.Getwd()
+	argv, err := paneCommand(os.Getenv, exe, ariaID, cwd, below)
+	if err != nil {
+		die("pane: %s", err)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		die("pane: %s: %s", argv[0], err)
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaneCommand(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tmux := env(map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"})
+	got, err := paneCommand(tmux, "/bin/figaro", "ab12", "/src", false)
+	want := []string{"tmux", "split-window", "-h", "-c", "/src", "/bin/figaro", "listen", "ab12"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("tmux = %q, %v", got, err)
+	}
+	if got, _ := paneCommand(tmux, "/bin/figaro", "ab12", "/src", true); got[2] != "-v" {
+		t.Errorf("tmux --below = %q", got)
+	}
+
+	screen := env(map[string]string{"STY": "123.pts-0"})
+	got, err = paneCommand(screen, "/bin/figaro", "ab12", `/my "src"`, false)
+	want = []string{"screen", "-X", "eval", "split -v", "focus", `chdir "/my \"src\""`, `screen "/bin/figaro" listen ab12`}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("screen = %q, %v", got, err)
+	}
+
+	if _, err := paneCommand(env(nil), "/bin/figaro", "ab12", "/src", false); err == nil {
+		t.Error("no multiplexer should be an error")
+	}
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jack-work/figaro/internal/cmdkit"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/outfit"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// bareName keeps persona and conversation names usable as bare TOML keys
+// and file names.
+var bareName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// personaKeys is the chalkboard patch a persona applies. system.persona
+// records the choice on the conversation. The temperature and tool list
+// are the persona's whole: one it leaves out is removed, so switching from
+// a persona that narrowed the tools doesn't keep them narrowed. A persona
+// without a credo gets the loadout's (loadoutCredo), never none. An unset
+// model keeps the aria's.
+func personaKeys(name string, p config.Persona, loadoutCredo json.RawMessage) *rpc.ChalkboardPatch {
+	patch := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{}}
+	put := func(key string, v any, ok bool) {
+		if !ok {
+			patch.Remove = append(patch.Remove, key)
+			return
+		}
+		b, _ := json.Marshal(v)
+		patch.Set[key] = b
+	}
+	put("system.persona", name, true)
+	switch {
+	case p.Credo != "":
+		put("system.credo", p.Credo, true)
+	case len(loadoutCredo) > 0:
+		patch.Set["system.credo"] = loadoutCredo
+	default:
+		put("system.credo", nil, false)
+	}
+	if p.Model != "" {
+		put("system.model", p.Model, true)
+	}
+	var temp float64
+	if p.Temperature != nil {
+		temp = *p.Temperature
+	}
+	put("system.temperature", temp, p.Temperature != nil)
+	put("system.tools", p.Tools, len(p.Tools) > 0)
+	return patch
+}
+
+// personaPatch looks up a config persona for send --persona. An empty
+// tools list is refused: system.tools = [] would offer every tool.
+func personaPatch(loaded *config.Loaded, name string) (*rpc.ChalkboardPatch, error) {
+	p, ok := loaded.Config.Personas[name]
+	if !ok {
+		names := personaNames(loaded)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no persona %q (none in %s; try: figaro persona new %s)", name, loaded.ConfigPath, name)
+		}
+		return nil, fmt.Errorf("no persona %q (have: %s)", name, strings.Join(names, ", "))
+	}
+	if p.Tools != nil && len(p.Tools) == 0 {
+		return nil, fmt.Errorf("persona %q: tools = [] names no tools (leave tools out to offer all)", name)
+	}
+	var credo json.RawMessage
+	if p.Credo == "" && loaded.Config.DefaultLoadout != "" {
+		lp, err := outfit.New(loaded.ConfigDir).Load(loaded.Config.DefaultLoadout)
+		if err != nil {
+			return nil, fmt.Errorf("persona %q: %w", name, err)
+		}
+		credo = lp.Set["system.credo"]
+	}
+	return personaKeys(name, p, credo), nil
+}
+
+func personaNames(loaded *config.Loaded) []string {
+	names := make([]string, 0, len(loaded.Config.Personas))
+	for n := range loaded.Config.Personas {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// completePersonas completes the action, then persona names after edit.
+func completePersonas(c *cmdkit.CompleteContext) []string {
+	if c == nil {
+		return nil
+	}
+	if len(c.Args) == 0 {
+		return []string{"list", "new", "edit"}
+	}
+	loaded, _ := c.Extra.(*config.Loaded)
+	if c.Args[0] != "edit" || loaded == nil {
+		return nil
+	}
+	return personaNames(loaded)
+}
+
+// runPersona dispatches `figaro persona list|new|edit`.
+func runPersona(loaded *config.Loaded, args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	switch args[0] {
+	case "list", "ls":
+		runPersonaList(loaded)
+	case "new":
+		if len(args) != 2 {
+			die("usage: figaro persona new <name>")
+		}
+		runPersonaNew(loaded, args[1])
+	case "edit":
+		if len(args) != 2 {
+			die("usage: figaro persona edit <name>")
+		}
+		runPersonaEdit(loaded, args[1])
+	default:
+		die("persona: unknown action %q (list, new, edit)", args[0])
+	}
+}
+
+func runPersonaList(loaded *config.Loaded) {
+	names := personaNames(loaded)
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "no personas in", loaded.ConfigPath, "(try: figaro persona new <name>)")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMODEL\tTEMP\tTOOLS\tCREDO")
+	for _, n := range names {
+		p := loaded.Config.Personas[n]
+		temp := "-"
+		if p.Temperature != nil {
+			temp = strconv.FormatFloat(*p.Temperature, 'g', -1, 64)
+		}
+		tools := "all"
+		if len(p.Tools) > 0 {
+			tools = strings.Join(p.Tools, ",")
+		}
+		credo, _, _ := strings.Cut(strings.TrimSpace(p.Credo), "\n")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", n, dash(p.Model), temp, tools, truncateVisible(credo, 50))
+	}
+	w.Flush()
+}
+
+// personaTemplate is the table `persona new` appends to config.toml.
+const personaTemplate = `
+[personas.%s]
+credo = """
+You are a careful code reviewer. Point out bugs, risky changes and
+missing tests; keep it brief.
+"""
+# model = "claude-sonnet-4-5"
+# temperature = 0.2     # Copilot models only; Anthropic ignores it
+# tools = ["read", "bash"]
+`
+
+// runPersonaNew appends a template persona to config.toml and opens it.
+func runPersonaNew(loaded *config.Loaded, name string) {
+	if !bareName.MatchString(name) {
+		die("persona: name %q must be letters, digits, '-' or '_'", name)
+	}
+	if _, ok := loaded.Config.Personas[name]; ok {
+		die("persona %q already exists (try: figaro persona edit %s)", name, name)
+	}
+	if err := os.MkdirAll(filepath.Dir(loaded.ConfigPath), 0o755); err != nil {
+		die("persona: %s", err)
+	}
+	f, err := os.OpenFile(loaded.ConfigPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		die("persona: %s", err)
+	}
+	_, err = fmt.Fprintf(f, personaTemplate, name)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		die("persona: write %s: %s", loaded.ConfigPath, err)
+	}
+	fmt.Fprintf(os.Stderr, "added [personas.%s] to %s\n", name, loaded.ConfigPath)
+	runPersonaEdit(loaded, name)
+}
+
+// runPersonaEdit opens config.toml in $VISUAL/$EDITOR at the persona's
+// table, then re-reads the config so a typo shows up now rather than on
+// the next send.
+func runPersonaEdit(loaded *config.Loaded, name string) {
+	data, err := os.ReadFile(loaded.ConfigPath)
+	if err != nil {
+		die("persona: %s", err)
+	}
+	line := personaLine(data, name)
+	if line == 0 {
+		die("no persona %q in %s (try: figaro persona new %s)", name, loaded.ConfigPath, name)
+	}
+	argv := editorCommand()
+	if runtime.GOOS != "windows" {
+		argv = append(argv, "+"+strconv.Itoa(line))
+	}
+	cmd := exec.Command(argv[0], append(argv[1:], loaded.ConfigPath)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		die("persona: %s: %s", argv[0], err)
+	}
+	reloaded, err := config.Load(loaded.ConfigDir)
+	if err != nil {
+		die("persona: %s", err)
+	}
+	if _, ok := reloaded.Config.Personas[name]; !ok {
+		fmt.Fprintf(os.Stderr, "persona %q is no longer in %s\n", name, loaded.ConfigPath)
+	}
+}
+
+// personaLine is the 1-based line of [personas.<name>], or 0.
+func personaLine(data []byte, name string) int {
+	header := "[personas." + name + "]"
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		if strings.TrimSpace(sc.Text()) == header {
+			return n
+		}
+	}
+	return 0
+}
+
+// editorCommand is $VISUAL, then $EDITOR, split on spaces like $PAGER.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if argv := strings.Fields(os.Getenv(env)); len(argv) > 0 {
+			return argv
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestPersonaPatch(t *testing.T) {
+	temp := 0.2
+	loaded := &config.Loaded{ConfigPath: "config.toml", Config: config.Config{Personas: map[string]config.Persona{
+		"reviewer": {Credo: "Review it.", Model: "m1", Temperature: &temp, Tools: []string{"read"}},
+		"terse":    {Credo: "Be brief."},
+	}}}
+
+	patch, err := personaPatch(loaded, "reviewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := patch.Set
+	want := map[string]string{
+		"system.persona":     `"reviewer"`,
+		"system.credo":       `"Review it."`,
+		"system.model":       `"m1"`,
+		"system.temperature": `0.2`,
+		"system.tools":       `["read"]`,
+	}
+	if len(set) != len(want) || len(patch.Remove) != 0 {
+		t.Errorf("keys = %v, remove %v", set, patch.Remove)
+	}
+	for k, v := range want {
+		if string(set[k]) != v {
+			t.Errorf("%s = %s, want %s", k, set[k], v)
+		}
+	}
+
+	patch, _ = personaPatch(loaded, "terse")
+	for _, k := range []string{"system.model", "system.temperature", "system.tools"} {
+		if _, ok := patch.Set[k]; ok {
+			t.Errorf("terse sets %s", k)
+		}
+	}
+	// Switching to terse clears what reviewer left behind; the model stays.
+	if want := []string{"system.temperature", "system.tools"}; !reflect.DeepEqual(patch.Remove, want) {
+		t.Errorf("terse removes %v, want %v", patch.Remove, want)
+	}
+
+	if _, err := personaPatch(loaded, "nope"); err == nil || !strings.Contains(err.Error(), "reviewer, terse") {
+		t.Errorf("unknown persona: %v", err)
+	}
+}
+
+func TestPersonaPatchLoadoutCredo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "loadouts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "loadouts", "main.toml"), []byte("system = { credo = \"Be kind.\" }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &config.Loaded{ConfigDir: dir, Config: config.Config{DefaultLoadout: "main", Personas: map[string]config.Persona{
+		"fast": {Model: "m2"},
+		"mute": {Credo: "x", Tools: []string{}},
+	}}}
+
+	patch, err := personaPatch(loaded, "fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(patch.Set["system.credo"]); got != `"Be kind."` || slices.Contains(patch.Remove, "system.credo") {
+		t.Errorf("credo = %s, remove %v; want the loadout's credo", got, patch.Remove)
+	}
+	if _, err := personaPatch(loaded, "mute"); err == nil || !strings.Contains(err.Error(), "tools = []") {
+		t.Errorf("empty tools: %v", err)
+	}
+}
+
+func TestPersonaLine(t *testing.T) {
+	data := []byte("locale = \"en\"\n\n[personas.a]\ncredo = \"x\"\n\n  [personas.b]\n")
+	if got := personaLine(data, "a"); got != 3 {
+		t.Errorf("a at %d", got)
+	}
+	if got := personaLine(data, "b"); got != 6 {
+		t.Errorf("b at %d", got)
+	}
+	if got := personaLine(data, "c"); got != 0 {
+		t.Errorf("c at %d", got)
+	}
+}
+
+func TestPromptChalkboardPersona(t *testing.T) {
+	defer func() { promptPersona, promptReplyLang = nil, "" }()
+	promptPersona = &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.persona": []byte(`"reviewer"`)}, Remove: []string{"system.tools"}}
+	promptReplyLang = "ja"
+	cb := buildPromptChalkboard()
+	if cb == nil || cb.Patch == nil || string(cb.Patch.Set["system.persona"]) != `"reviewer"` || len(cb.Patch.Set) != 2 {
+		t.Fatalf("patch = %+v", cb)
+	}
+	if !reflect.DeepEqual(cb.Patch.Remove, []string{"system.tools"}) {
+		t.Errorf("remove = %v", cb.Patch.Remove)
+	}
+	if len(promptPersona.Set) != 1 {
+		t.Error("buildPromptChalkboard modified the persona keys")
+	}
+}
+
+func TestPromptChalkboardPersonaWithRetry(t *testing.T) {
+	defer func() { promptPersona = nil }()
+	promptPersona = &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.persona": []byte(`"terse"`)}, Remove: []string{"system.temperature", "system.tools"}}
+	retry := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"system.temperature": []byte(`0.7`)}}
+	cb := promptChalkboard(renderSettings{patch: retry})
+	if string(cb.Patch.Set["system.temperature"]) != `0.7` || !reflect.DeepEqual(cb.Patch.Remove, []string{"system.tools"}) {
+		t.Errorf("patch = %v, remove %v; want the retry's temperature kept", cb.Patch.Set, cb.Patch.Remove)
+	}
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// pinClient is the part of a figaro client that edits pins.
+type pinClient interface {
+	Chalkboard(context.Context) (*rpc.ChalkboardResponse, error)
+	Set(context.Context, rpc.ChalkboardPatch) (*rpc.SetResponse, error)
+}
+
+// editPins rewrites system.pins through edit; an empty result removes the key.
+func editPins(ctx context.Context, fc pinClient, edit func([]provider.Pin) ([]provider.Pin, error)) ([]provider.Pin, error) {
+	resp, err := fc.Chalkboard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chalkboard: %w", err)
+	}
+	pins, err := edit(provider.ReadPins(chalkboard.Snapshot(resp.Snapshot)))
+	if err != nil {
+		return nil, err
+	}
+	patch := rpc.ChalkboardPatch{Remove: []string{provider.PinsKey}}
+	if len(pins) > 0 {
+		raw, err := json.Marshal(pins)
+		if err != nil {
+			return nil, err
+		}
+		patch = rpc.ChalkboardPatch{Set: map[string]json.RawMessage{provider.PinsKey: raw}}
+	}
+	if _, err := fc.Set(ctx, patch); err != nil {
+		return nil, fmt.Errorf("set: %w", err)
+	}
+	return pins, nil
+}
+
+// addPin appends p, replacing an earlier pin of the same message.
+func addPin(pins []provider.Pin, p provider.Pin) []provider.Pin {
+	for i, old := range pins {
+		if p.LT != 0 && old.LT == p.LT {
+			pins[i] = p
+			return pins
+		}
+	}
+	return append(pins, p)
+}
+
+// pinIndex reads a 1-based position from pin ls.
+func pinIndex(pins []provider.Pin, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(pins) {
+		return 0, fmt.Errorf("no pin %q (figaro pin ls numbers them 1-%d)", arg, len(pins))
+	}
+	return n - 1, nil
+}
+
+// pinnedMessageText is what a message pin keeps: the message's prose.
+func pinnedMessageText(m message.Message) string {
+	var parts []string
+	for _, c := range m.Content {
+		if c.Type == message.ContentProse && strings.TrimSpace(c.Text) != "" {
+			parts = append(parts, strings.TrimSpace(c.Text))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func pinLabel(p provider.Pin) string {
+	switch {
+	case p.LT != 0:
+		return fmt.Sprintf("LT %d", p.LT)
+	case p.Source != "":
+		return p.Source
+	}
+	return "snippet"
+}
+
+// runPin pins messages by LT, or a file's contents, or literal text.
+func runPin(loaded *config.Loaded, ariaID string, lts []string, file, text string) {
+	WithSessionFor(loaded, ariaID, func(s *Session) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var add []provider.Pin
+		for _, arg := range lts {
+			lt, err := strconv.ParseUint(strings.TrimPrefix(arg, ":"), 10, 64)
+			if err != nil || lt == 0 {
+				die("pin: bad LT %q", arg)
+			}
+			resp, err := s.Angelus.AriaRead(ctx, s.AriaID, lt, 1)
+			if err != nil {
+				die("pin: aria.read: %s", err)
+			}
+			if len(resp.Entries) == 0 || resp.Entries[0].LT != lt {
+				die("pin: no message at LT %d", lt)
+			}
+			var m message.Message
+			if err := json.Unmarshal(resp.Entries[0].Payload, &m); err != nil {
+				die("pin: LT %d: %s", lt, err)
+			}
+			body := pinnedMessageText(m)
+			if body == "" {
+				die("pin: LT %d has no text to pin", lt)
+			}
+			add = append(add, provider.Pin{LT: lt, Text: body})
+		}
+		if file != "" {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				die("pin: %s", err)
+			}
+			add = append(add, provider.Pin{Source: file, Text: string(raw)})
+		}
+		if text != "" {
+			add = append(add, provider.Pin{Text: text})
+		}
+		if len(add) == 0 {
+			die("usage: pin [<LT>...] [--file F] [--text T] | pin ls | pin rm <n>")
+		}
+		pins, err := editPins(ctx, s.Figaro, func(pins []provider.Pin) ([]provider.Pin, error) {
+			for _, p := range add {
+				pins = addPin(pins, p)
+			}
+			return pins, nil
+		})
+		if err != nil {
+			die("pin: %s", err)
+		}
+		for _, p := range add {
+			fmt.Fprintf(os.Stderr, "pinned %s (figaro %s, %d pins)\n", pinLabel(p), s.AriaID, len(pins))
+		}
+		return nil
+	})
+}
+
+func runPinList(loaded *config.Loaded, ariaID string) {
+	WithSessionFor(loaded, ariaID, func(s *Session) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		resp, err := s.Figaro.Chalkboard(ctx)
+		if err != nil {
+			die("chalkboard: %s", err)
+		}
+		pins := provider.ReadPins(chalkboard.Snapshot(resp.Snapshot))
+		if len(pins) == 0 {
+			fmt.Println("no pins")
+			return nil
+		}
+		for i, p := range pins {
+			fmt.Printf("%2d  %-20s  %s\n", i+1, truncRunes(pinLabel(p), 20), truncRunes(p.Text, 60))
+		}
+		return nil
+	})
+}
+
+func runPinRemove(loaded *config.Loaded, ariaID, arg string) {
+	WithSessionFor(loaded, ariaID, func(s *Session) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var gone provider.Pin
+		_, err := editPins(ctx, s.Figaro, func(pins []provider.Pin) ([]provider.Pin, error) {
+			i, err := pinIndex(pins, arg)
+			if err != nil {
+				return nil, err
+			}
+			gone = pins[i]
+			return append(pins[:i], pins[i+1:]...), nil
+		})
+		if err != nil {
+			die("pin rm: %s", err)
+		}
+		fmt.Fprintf(os.Stderr, "unpinned %s (figaro %s)\n", pinLabel(gone), s.AriaID)
+		return nil
+	})
+}
+
+// togglePin pins the message at lt with text, or unpins it when it is
+// already pinned. It backs the pager's 'p' action.
+func togglePin(ctx context.Context, fc pinClient, lt uint64, text string) (pinned bool, err error) {
+	_, err = editPins(ctx, fc, func(pins []provider.Pin) ([]provider.Pin, error) {
+		for i, p := range pins {
+			if p.LT == lt {
+				return append(pins[:i], pins[i+1:]...), nil
+			}
+		}
+		pinned = true
+		return append(pins, provider.Pin{LT: lt, Text: text}), nil
+	})
+	return pinned, err
+}
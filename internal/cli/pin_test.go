@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// fakePinClient is a chalkboard that applies patches in memory.
+type fakePinClient struct {
+	snap map[string]json.RawMessage
+}
+
+func (f *fakePinClient) Chalkboard(context.Context) (*rpc.ChalkboardResponse, error) {
+	return &rpc.ChalkboardResponse{Snapshot: f.snap}, nil
+}
+
+func (f *fakePinClient) Set(_ context.Context, p rpc.ChalkboardPatch) (*rpc.SetResponse, error) {
+	for k, v := range p.Set {
+		f.snap[k] = v
+	}
+	for _, k := range p.Remove {
+		delete(f.snap, k)
+	}
+	return &rpc.SetResponse{}, nil
+}
+
+func TestTogglePin(t *testing.T) {
+	fc := &fakePinClient{snap: map[string]json.RawMessage{}}
+	ctx := context.Background()
+	if pinned, err := togglePin(ctx, fc, 12, "the plan"); err != nil || !pinned {
+		t.Fatalf("first toggle = %v %v", pinned, err)
+	}
+	if _, err := editPins(ctx, fc, func(pins []provider.Pin) ([]provider.Pin, error) {
+		return addPin(pins, provider.Pin{Source: "api.md", Text: "GET /v1"}), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if pinned, _ := togglePin(ctx, fc, 12, "the plan"); pinned {
+		t.Fatal("second toggle pinned again")
+	}
+	var pins []provider.Pin
+	json.Unmarshal(fc.snap[provider.PinsKey], &pins)
+	if len(pins) != 1 || pins[0].Source != "api.md" {
+		t.Fatalf("pins = %+v", pins)
+	}
+	if _, err := pinIndex(pins, "2"); err == nil {
+		t.Error("pinIndex accepted a position past the end")
+	}
+	editPins(ctx, fc, func(pins []provider.Pin) ([]provider.Pin, error) { return nil, nil })
+	if _, ok := fc.snap[provider.PinsKey]; ok {
+		t.Error("removing the last pin left the key behind")
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"time"
 
 	"github.com/jack-work/figaro/internal/config"
-	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	"github.com/jack-work/figaro/internal/rpc"
@@ -52,11 +52,7 @@ func runPlainPrompt(loaded *config.Loaded, rawArgs []string) {
 		}
 		figaroID = createResp.FigaroID
 		figaroEP = transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
-		defer func() {
-			killCtx, killCancel := context.WithTimeout(context.Background(), 3*time.Second)
-			defer killCancel()
-			_ = acli.Kill(killCtx, figaroID, false)
-		}()
+		defer func() { endEphemeral(loaded, acli, figaroID, figaroEP, prompt) }()
 		if err := waitForSocket(figaroEP.Address, 3*time.Second); err != nil {
 			die("plain: %s", err)
 		}
@@ -197,96 +193,21 @@ func stripBashFences(s string) string {
 
 // plainPrompt streams the response and returns an exit code.
 func plainPrompt(ctx context.Context, ep transport.Endpoint, prompt string, out io.Writer) int {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	sink := newPlainSink(out)
-	doneCh := sink.doneCh
-
-	fcli, err := figaro.DialClient(ep, sink.handle)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error: connect figaro:", err)
-		return 1
-	}
-	defer fcli.Close()
-
-	if _, err := fcli.Qua(ctx, prompt, buildPromptChalkboard()); err != nil {
-		fmt.Fprintln(os.Stderr, "error: prompt:", err)
-		return 1
-	}
-
-	select {
-	case <-doneCh:
-		if sink.sawError {
-			return 1
-		}
-		return 0
-	case <-fcli.Done():
-		fmt.Fprintln(os.Stderr, "error: agent disconnected before turn completed")
-		return 1
-	case <-ctx.Done():
-		intCtx, intCancel := context.WithTimeout(context.Background(), 3*time.Second)
-		_ = fcli.Interrupt(intCtx)
-		intCancel()
-		select {
-		case <-doneCh:
-		case <-fcli.Done():
-		case <-time.After(3 * time.Second):
-		}
-		return 130
-	}
+	return sinkPrompt(ctx, ep, prompt, false, newPlainSink(out))
 }
 
 // verbatimPrompt dumps the raw wire frames as JSON (one object per line)
 // and returns an exit code. No formatting, no delta application — the
 // literal protocol stream.
 func verbatimPrompt(ctx context.Context, ep transport.Endpoint, prompt string, out io.Writer) int {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	sink := &verbatimSink{out: out, doneCh: make(chan struct{}, 1)}
-	doneCh := sink.doneCh
-
-	fcli, err := figaro.DialClient(ep, sink.handle)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error: connect figaro:", err)
-		return 1
-	}
-	defer fcli.Close()
-
-	if _, err := fcli.Qua(ctx, prompt, buildPromptChalkboard()); err != nil {
-		fmt.Fprintln(os.Stderr, "error: prompt:", err)
-		return 1
-	}
-
-	select {
-	case <-doneCh:
-		if sink.sawError {
-			return 1
-		}
-		return 0
-	case <-fcli.Done():
-		fmt.Fprintln(os.Stderr, "error: agent disconnected before turn completed")
-		return 1
-	case <-ctx.Done():
-		intCtx, intCancel := context.WithTimeout(context.Background(), 3*time.Second)
-		_ = fcli.Interrupt(intCtx)
-		intCancel()
-		select {
-		case <-doneCh:
-		case <-fcli.Done():
-		case <-time.After(3 * time.Second):
-		}
-		return 130
-	}
+	return sinkPrompt(ctx, ep, prompt, false, &verbatimSink{out: out, sinkDone: newSinkDone()})
 }
 
 // verbatimSink writes every wire notification as a JSON line
 // {"method","params"} — the protocol exactly as it arrives, no decoding.
 type verbatimSink struct {
-	out      io.Writer
-	doneCh   chan struct{}
-	sawError bool
+	out io.Writer
+	sinkDone
 }
 
 func (s *verbatimSink) handle(method string, params json.RawMessage) {
@@ -301,13 +222,7 @@ func (s *verbatimSink) handle(method string, params json.RawMessage) {
 	if method == rpc.MethodTurnDone {
 		var d rpc.DoneEntry
 		_ = json.Unmarshal(params, &d)
-		if strings.HasPrefix(d.Reason, "error:") {
-			s.sawError = true
-		}
-		select {
-		case s.doneCh <- struct{}{}:
-		default:
-		}
+		s.finish(d)
 	}
 }
 
@@ -317,16 +232,15 @@ func (s *verbatimSink) handle(method string, params json.RawMessage) {
 // is skipped. Tool nodes contribute their raw output (no widget chrome);
 // raw-mode callers (figaro x) prompt the model for plain output anyway.
 type plainSink struct {
-	out      io.Writer
-	client   *aria.Client
-	written  string // exactly what's been emitted for the current assistant unit
-	doneCh   chan struct{}
-	sawError bool
+	out     io.Writer
+	client  *aria.Client
+	written string // exactly what's been emitted for the current assistant unit
+	sinkDone
 }
 
 func newPlainSink(out io.Writer) *plainSink {
-	s := &plainSink{out: out, doneCh: make(chan struct{}, 1), client: aria.NewClient()}
-	s.client.OnLive = func(_ int, role string, nodes []livedoc.Node) {
+	s := &plainSink{out: out, sinkDone: newSinkDone(), client: aria.NewClient()}
+	s.client.OnLive = func(_ int, role, _ string, nodes []livedoc.Node) {
 		if role == "assistant" {
 			s.emit(plainText(nodes))
 		}
@@ -351,17 +265,23 @@ func (s *plainSink) handle(method string, params json.RawMessage) {
 		if json.Unmarshal(params, &r) == nil {
 			s.client.Apply(r)
 		}
+	case rpc.MethodGuardViolation:
+		var v rpc.GuardViolation
+		if json.Unmarshal(params, &v) == nil {
+			fmt.Fprintln(os.Stderr, guardNotice(v))
+		}
+	case rpc.MethodContentTrimmed:
+		var t rpc.Trimmed
+		if json.Unmarshal(params, &t) == nil {
+			fmt.Fprintln(os.Stderr, trimNotice(t))
+		}
 	case rpc.MethodTurnDone:
 		var d rpc.DoneEntry
 		_ = json.Unmarshal(params, &d)
 		if strings.HasPrefix(d.Reason, "error:") {
-			fmt.Fprintln(os.Stderr, d.Reason)
-			s.sawError = true
-		}
-		select {
-		case s.doneCh <- struct{}{}:
-		default:
+			printFailure(os.Stderr, d.Reason, errs.Kind(d.Kind))
 		}
+		s.finish(d)
 	}
 }
 
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// A project pins its defaults in a .figaro.yaml at its root. A bare prompt
+// from anywhere below it, in a shell not bound to an aria, continues the
+// project's named conversation, creating it on first use with the
+// project's persona and tool list. The CLI remembers which aria carries a
+// name with one small file per project and name under
+// <state>/conversations.
+
+// projectFileName is looked up from the working directory toward /.
+const projectFileName = ".figaro.yaml"
+
+// projectConfig is a .figaro.yaml.
+type projectConfig struct {
+	// Conversation names the aria bare prompts continue.
+	Conversation string `yaml:"conversation"`
+
+	// Persona is a config [personas] name applied when the conversation
+	// is created.
+	Persona string `yaml:"persona"`
+
+	// Tools narrows the conversation's tools (system.tools), over the
+	// persona's own list.
+	Tools []string `yaml:"tools"`
+
+	path string
+}
+
+// findProjectConfig reads the nearest .figaro.yaml at or above dir, or
+// returns nil when there is none.
+func findProjectConfig(dir string) (*projectConfig, error) {
+	for {
+		path := filepath.Join(dir, projectFileName)
+		b, err := os.ReadFile(path)
+		if err == nil {
+			p := &projectConfig{path: path}
+			if err := yaml.Unmarshal(b, p); err != nil {
+				return nil, fmt.Errorf("parse %s: %w", path, err)
+			}
+			if p.Conversation != "" && !bareName.MatchString(p.Conversation) {
+				return nil, fmt.Errorf("%s: conversation %q must be letters, digits, '-' or '_'", path, p.Conversation)
+			}
+			return p, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// projectPatch is the chalkboard a project's new conversation starts
+// with: its persona, then its tool list.
+func (p *projectConfig) projectPatch(loaded *config.Loaded) (map[string]json.RawMessage, error) {
+	set := map[string]json.RawMessage{}
+	if p.Persona != "" {
+		keys, err := personaPatch(loaded, p.Persona)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.path, err)
+		}
+		set = keys.Set // a new conversation has nothing to clear
+	}
+	if len(p.Tools) > 0 {
+		b, _ := json.Marshal(p.Tools)
+		set["system.tools"] = b
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+func conversationDir() string { return filepath.Join(stateDir(), "conversations") }
+
+// conversationKey is the record a named conversation is kept under: the
+// name plus a hash of the project directory, so two repos that both say
+// `conversation: main` keep their own arias.
+func conversationKey(projectDir, name string) string {
+	h := sha256.Sum256([]byte(filepath.Clean(projectDir)))
+	return name + "-" + hex.EncodeToString(h[:6])
+}
+
+// dir is the directory holding the .figaro.yaml.
+func (p *projectConfig) dir() string { return filepath.Dir(p.path) }
+
+// namedConversation is the aria id recorded under key, or "".
+func namedConversation(dir, key string) string {
+	b, err := os.ReadFile(filepath.Join(dir, key))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func saveNamedConversation(dir, key, id string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, key), []byte(id+"\n"), 0o600)
+}
+
+// projectConversation picks the aria an unbound shell's bare prompt goes
+// to and binds the shell to it: the project's named conversation when it
+// is still around, else a new aria (recorded under the name, if any).
+// created reports the latter.
+func projectConversation(ctx context.Context, acli *angelus.Client, loaded *config.Loaded, ppid int) (id string, ep transport.Endpoint, created bool) {
+	cwd, _ := os.Getwd()
+	proj, err := findProjectConfig(cwd)
+	if err != nil {
+		die("%s", err)
+	}
+	if proj == nil {
+		id, ep = mustCreateAndBind(ctx, acli, loaded, ppid)
+		return id, ep, true
+	}
+	if proj.Conversation != "" {
+		if id = namedConversation(conversationDir(), conversationKey(proj.dir(), proj.Conversation)); id != "" {
+			if ep, err = resolveAria(ctx, acli, id); err == nil {
+				if err := bindBinding(ctx, acli, ppid, id, 0); err != nil {
+					die("bind: %s", err)
+				}
+				return id, ep, false
+			}
+		}
+	}
+	defaults, err := proj.projectPatch(loaded)
+	if err != nil {
+		die("%s", err)
+	}
+	if defaults != nil {
+		if promptPersona != nil {
+			maps.Copy(defaults, promptPersona.Set) // send --persona wins
+		}
+		promptPersona = &rpc.ChalkboardPatch{Set: defaults}
+	}
+	id, ep = mustCreateAndBind(ctx, acli, loaded, ppid)
+	if proj.Conversation != "" {
+		if err := saveNamedConversation(conversationDir(), conversationKey(proj.dir(), proj.Conversation), id); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: remember conversation %q: %s\n", proj.Conversation, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "conversation %q is %s\n", proj.Conversation, id)
+		}
+	}
+	return id, ep, true
+}
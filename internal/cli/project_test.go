@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := findProjectConfig(sub); err != nil || p != nil {
+		t.Fatalf("no file: %+v, %v", p, err)
+	}
+
+	yml := "conversation: figaro-dev\npersona: reviewer\ntools: [read, bash]\n"
+	if err := os.WriteFile(filepath.Join(root, projectFileName), []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := findProjectConfig(sub)
+	if err != nil || p == nil {
+		t.Fatalf("find: %+v, %v", p, err)
+	}
+	if p.Conversation != "figaro-dev" || p.Persona != "reviewer" || len(p.Tools) != 2 {
+		t.Errorf("parsed %+v", p)
+	}
+
+	if err := os.WriteFile(filepath.Join(sub, projectFileName), []byte("conversation: ../x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findProjectConfig(sub); err == nil {
+		t.Error("path-like conversation name accepted")
+	}
+}
+
+func TestProjectPatch(t *testing.T) {
+	loaded := &config.Loaded{Config: config.Config{Personas: map[string]config.Persona{
+		"reviewer": {Credo: "Review it.", Tools: []string{"read", "bash"}},
+	}}}
+	p := &projectConfig{Persona: "reviewer", Tools: []string{"read"}}
+	set, err := p.projectPatch(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(set["system.persona"]) != `"reviewer"` || string(set["system.tools"]) != `["read"]` {
+		t.Errorf("patch = %v", set)
+	}
+
+	if set, err := (&projectConfig{Conversation: "x"}).projectPatch(loaded); err != nil || set != nil {
+		t.Errorf("name only: %v, %v", set, err)
+	}
+	if _, err := (&projectConfig{Persona: "nope"}).projectPatch(loaded); err == nil {
+		t.Error("unknown persona accepted")
+	}
+}
+
+func TestNamedConversation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "conversations")
+	if id := namedConversation(dir, "proj"); id != "" {
+		t.Errorf("missing name = %q", id)
+	}
+	if err := saveNamedConversation(dir, "proj", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if id := namedConversation(dir, "proj"); id != "abc123" {
+		t.Errorf("saved name = %q", id)
+	}
+	if a, b := conversationKey("/src/one", "main"), conversationKey("/src/two", "main"); a == b {
+		t.Errorf("two projects share the record %q", a)
+	}
+	if a, b := conversationKey("/src/one", "main"), conversationKey("/src/one/", "main"); a != b {
+		t.Errorf("one project keyed twice: %q, %q", a, b)
+	}
+}
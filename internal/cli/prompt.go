@@ -12,13 +12,16 @@ import (
 	"github.com/jack-work/figaro/internal/angelus"
 	"github.com/jack-work/figaro/internal/config"
 	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/render"
 	"github.com/jack-work/figaro/internal/transport"
 )
 
-// runPrompt resolves the shell-bound figaro and prompts it.
+// runPrompt resolves the shell-bound figaro and prompts it. An unbound
+// shell goes to its project's conversation (.figaro.yaml) or a new aria.
 func runPrompt(loaded *config.Loaded, prompt string, set renderSettings) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
+	render.Prewarm(termWidth())
 
 	acli := mustConnectAngelus(loaded)
 	defer acli.Close()
@@ -32,21 +35,25 @@ func runPrompt(loaded *config.Loaded, prompt string, set renderSettings) {
 
 	var figaroID string
 	var figaroEP transport.Endpoint
+	created := false
 
 	if resp.Found {
 		// Bound at a pending fork-point (attend <id>:<LT>): this prompt forks
 		// there and moves to the new branch (one-shot — the rebind clears it).
 		if resp.AtMainLT > 0 {
-			runSendForkAt(loaded, resp.FigaroID, resp.AtMainLT, false, false, prompt, set)
+			runSendForkAt(loaded, resp.FigaroID, resp.AtMainLT, false, false, false, prompt, set)
 			return
 		}
 		figaroID = resp.FigaroID
 		figaroEP = transport.Endpoint{Scheme: resp.Endpoint.Scheme, Address: resp.Endpoint.Address}
 	} else {
-		figaroID, figaroEP = mustCreateAndBind(ctx, acli, loaded, ppid)
+		figaroID, figaroEP, created = projectConversation(ctx, acli, loaded, ppid)
 	}
 	prompt = expandAtRefsForEndpoint(ctx, figaroEP, prompt)
 	mustPromptFigaro(ctx, figaroEP, figaroID, prompt, loaded, set)
+	if created {
+		maybeAutoTitle(loaded, figaroEP, figaroID, prompt)
+	}
 }
 
 // runNewPrompt creates a fresh figaro and prompts it. Under jsonMode
@@ -71,10 +78,11 @@ func runNewPrompt(loaded *config.Loaded, prompt, loadout string, set renderSetti
 			die("connect figaro: %s", derr)
 		}
 		defer fcli.Close()
+		fcli.Author = promptAuthor
 		qctx, qcancel := context.WithTimeout(ctx, 10*time.Second)
-		if _, qerr := fcli.Qua(qctx, prompt, buildPromptChalkboard()); qerr != nil {
+		if _, qerr := fcli.QuaForce(qctx, prompt, buildPromptChalkboard(), set.force); qerr != nil {
 			qcancel()
-			die("prompt: %s", qerr)
+			diePrompt(qerr)
 		}
 		qcancel()
 		enc := json.NewEncoder(os.Stdout)
@@ -91,6 +99,7 @@ func runNewPrompt(loaded *config.Loaded, prompt, loadout string, set renderSetti
 		fmt.Fprintf(os.Stderr, "created %s\n", figaroID)
 	}
 	mustPromptFigaro(ctx, figaroEP, figaroID, prompt, loaded, set)
+	maybeAutoTitle(loaded, figaroEP, figaroID, prompt)
 }
 
 // runSendForkAt implements `send <trunk>:<LT>`: fork the trunk at atMainLT
@@ -98,7 +107,9 @@ func runNewPrompt(loaded *config.Loaded, prompt, loadout string, set renderSetti
 // trunk we end up attended to. By default we rebind this shell to the new
 // alternative and send there; with stay (--attend=false) we leave the shell
 // on the original trunk and send there (the alternative is parked at LT).
-func runSendForkAt(loaded *config.Loaded, trunkID string, atMainLT uint64, stay, asJSON bool, prompt string, set renderSettings) {
+// onAlt sends to the alternative even with stay, which then only skips the
+// rebind: a retried prompt belongs on the fresh branch.
+func runSendForkAt(loaded *config.Loaded, trunkID string, atMainLT uint64, stay, onAlt, asJSON bool, prompt string, set renderSettings) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
@@ -123,7 +134,11 @@ func runSendForkAt(loaded *config.Loaded, trunkID string, atMainLT uint64, stay,
 	}
 
 	target := fr.Alternative
-	if stay {
+	if stay && onAlt {
+		if !asJSON {
+			fmt.Fprintf(os.Stderr, "forked %s at LT %d -> %s (sending there; staying on %s)\n", trunkID, atMainLT, fr.Alternative, trunkID)
+		}
+	} else if stay {
 		target = trunkID // parked alternative; shell stays on the original
 		if !asJSON {
 			fmt.Fprintf(os.Stderr, "forked %s at LT %d -> %s (parked; staying on %s)\n", trunkID, atMainLT, fr.Alternative, trunkID)
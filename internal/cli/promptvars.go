@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// promptVarShell gates {{shell "..."}}: off unless config [vars] shell is
+// set, since a pasted prompt could otherwise run commands.
+var promptVarShell bool
+
+const (
+	promptVarShellTimeout = 10 * time.Second
+	promptVarMaxBytes     = 256 << 10
+)
+
+var promptVarEnvName = regexp.MustCompile(`^env\.([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// promptVars evaluates prompt substitutions. The hooks are swapped out in
+// tests.
+type promptVars struct {
+	getenv   func(string) string
+	readFile func(string) ([]byte, error)
+	shell    func(string) (string, error)
+}
+
+func defaultPromptVars() promptVars {
+	return promptVars{getenv: os.Getenv, readFile: os.ReadFile, shell: runPromptShell}
+}
+
+// expandPromptVars substitutes {{env.NAME}}, {{file "path"}} and
+// {{shell "command"}} in text. Other {{...}} is left alone, so code
+// templates in a prompt pass through. A substitution that fails stays
+// literal and is reported, the way an unknown @ref is.
+func expandPromptVars(text string) (string, []error) {
+	return defaultPromptVars().expand(text)
+}
+
+func (v promptVars) expand(text string) (string, []error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	var out strings.Builder
+	var errs []error
+	for {
+		i := strings.Index(text, "{{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(text[i+2:], "}}")
+		if j < 0 {
+			break
+		}
+		j += i + 2
+		out.WriteString(text[:i])
+		literal := text[i : j+2]
+		value, ok, err := v.eval(strings.TrimSpace(text[i+2 : j]))
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", literal, err))
+			out.WriteString(literal)
+		case ok:
+			out.WriteString(value)
+		default:
+			out.WriteString(literal)
+		}
+		text = text[j+2:]
+	}
+	out.WriteString(text)
+	return out.String(), errs
+}
+
+// eval resolves one {{...}} body; ok is false for anything that is not a
+// substitution.
+func (v promptVars) eval(body string) (value string, ok bool, err error) {
+	if m := promptVarEnvName.FindStringSubmatch(body); m != nil {
+		val := v.getenv(m[1])
+		if val == "" {
+			return "", true, fmt.Errorf("%s is not set", m[1])
+		}
+		return val, true, nil
+	}
+	verb, rest, _ := strings.Cut(body, " ")
+	if verb != "file" && verb != "shell" {
+		return "", false, nil
+	}
+	arg, uerr := strconv.Unquote(strings.TrimSpace(rest))
+	if uerr != nil {
+		return "", false, nil
+	}
+	switch verb {
+	case "file":
+		raw, err := v.readFile(arg)
+		if err != nil {
+			return "", true, err
+		}
+		if len(raw) > promptVarMaxBytes {
+			return "", true, fmt.Errorf("%s is over %d KiB", arg, promptVarMaxBytes>>10)
+		}
+		return strings.TrimRight(string(raw), "\n"), true, nil
+	default:
+		if !promptVarShell {
+			return "", true, fmt.Errorf("shell substitution is off (config [vars] shell = true)")
+		}
+		out, err := v.shell(arg)
+		return out, true, err
+	}
+}
+
+// runPromptShell runs command through sh and returns its trimmed output.
+func runPromptShell(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), promptVarShellTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("timed out after %s", promptVarShellTimeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(out) > promptVarMaxBytes {
+		return "", fmt.Errorf("output is over %d KiB", promptVarMaxBytes>>10)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// warnPromptVars expands text for a prompt sent from the terminal,
+// reporting failed substitutions on stderr.
+func warnPromptVars(text string) string {
+	text, errs := expandPromptVars(text)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+	}
+	return text
+}
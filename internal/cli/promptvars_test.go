@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestPromptVars(t *testing.T) {
+	defer func(was bool) { promptVarShell = was }(promptVarShell)
+	v := promptVars{
+		getenv: func(k string) string {
+			if k == "USER" {
+				return "ada"
+			}
+			return ""
+		},
+		readFile: func(p string) ([]byte, error) {
+			if p == "notes.md" {
+				return []byte("ship it\n"), nil
+			}
+			return nil, os.ErrNotExist
+		},
+		shell: func(c string) (string, error) {
+			if c == "git branch --show-current" {
+				return "main", nil
+			}
+			return "", errors.New("exit status 1")
+		},
+	}
+
+	promptVarShell = false
+	cases := []struct {
+		in, want string
+		errs     int
+	}{
+		{"hi {{env.USER}}", "hi ada", 0},
+		{"{{ env.USER }}/{{file \"notes.md\"}}", "ada/ship it", 0},
+		{"{{env.NOPE}}", "{{env.NOPE}}", 1},
+		{"{{file \"gone.md\"}}", "{{file \"gone.md\"}}", 1},
+		{"on {{shell \"git branch --show-current\"}}", "on {{shell \"git branch --show-current\"}}", 1},
+		{"{{ .Name }} and {{file notes.md}}", "{{ .Name }} and {{file notes.md}}", 0},
+		{"open {{env.USER", "open {{env.USER", 0},
+	}
+	for _, c := range cases {
+		got, errs := v.expand(c.in)
+		if got != c.want || len(errs) != c.errs {
+			t.Errorf("expand(%q) = %q, %v; want %q with %d errors", c.in, got, errs, c.want, c.errs)
+		}
+	}
+
+	promptVarShell = true
+	if got, errs := v.expand(`on {{shell "git branch --show-current"}}`); got != "on main" || errs != nil {
+		t.Errorf("shell on: %q %v", got, errs)
+	}
+	if got, errs := v.expand(`{{shell "false"}}`); got != `{{shell "false"}}` || len(errs) != 1 {
+		t.Errorf("failing shell: %q %v", got, errs)
+	}
+}
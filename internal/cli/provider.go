@@ -128,7 +128,7 @@ func buildProviderFactory(loaded *config.Loaded, cbTmpls *template.Template, bac
 			}
 			return backend.OpenTranslation(aria, providerName)
 		}
-		return reg.Build(providerPkg.BuildContext{
+		p, err := reg.Build(providerPkg.BuildContext{
 			Loaded:    loaded,
 			Knobs:     knobs,
 			Resolver:  resolver,
@@ -136,17 +136,25 @@ func buildProviderFactory(loaded *config.Loaded, cbTmpls *template.Template, bac
 			CacheOpen: cacheOpen,
 			Backend:   backend,
 		})
+		if err != nil {
+			return nil, err
+		}
+		return providerPkg.Chain(p, providerMiddleware...), nil
 	}
 }
 
 // buildProvider constructs a one-off provider for read-only flows
 // (e.g. `figaro models`).
 func buildProvider(loaded *config.Loaded, name string) (providerPkg.Provider, int) {
+	return buildProviderKnobs(loaded, name, defaultLoadoutKnobs(loaded))
+}
+
+// buildProviderKnobs is buildProvider with the knobs of a chosen loadout.
+func buildProviderKnobs(loaded *config.Loaded, name string, knobs providerPkg.Knobs) (providerPkg.Provider, int) {
 	reg := providerPkg.Lookup(name)
 	if reg == nil {
 		return nil, 0
 	}
-	knobs := defaultLoadoutKnobs(loaded)
 	if knobs.Model == "" {
 		knobs.Model = reg.DefaultModel
 	}
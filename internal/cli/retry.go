@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// retryScanPage bounds each backward aria.read while looking for the last
+// prompt. A tool-heavy turn can bury it under many tool_result messages.
+const retryScanPage = 64
+
+// runSendRetry implements `send --retry-last`: fork the trunk at the LT of its
+// last user prompt and re-ask that prompt on the fresh alternative. The old
+// answer is never dropped — it stays on the continuation, so the two replies
+// are sibling branches you can attend between.
+func runSendRetry(loaded *config.Loaded, opts sendOpts, set renderSettings) {
+	patch, err := retryPatch(opts.model, opts.temperature)
+	if err != nil {
+		die("send: %s", err)
+	}
+	set.patch = patch
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	acli := mustConnectAngelus(loaded)
+	trunkID := opts.id
+	if trunkID == "" {
+		r, rerr := resolveBinding(ctx, acli, os.Getppid())
+		if rerr != nil || !r.Found {
+			acli.Close()
+			cancel()
+			die("send: --retry-last: no aria bound to this shell (try: --id <id>)")
+		}
+		trunkID = r.FigaroID
+	}
+	lt, prompt, err := findLastPrompt(ctx, acli, trunkID)
+	acli.Close()
+	cancel()
+	if err != nil {
+		die("send: --retry-last: %s", err)
+	}
+	if !opts.json {
+		fmt.Fprintf(os.Stderr, "retrying LT %d of %s (the previous answer stays on the continuation)\n", lt, trunkID)
+	}
+	runSendForkAt(loaded, trunkID, lt, opts.stay, true, opts.json, prompt, set)
+}
+
+// retryPatch builds the chalkboard patch a retry carries onto its branch.
+// Nil when neither knob is given: the branch inherits the trunk's settings.
+func retryPatch(model, temperature string) (*rpc.ChalkboardPatch, error) {
+	if model == "" && temperature == "" {
+		return nil, nil
+	}
+	p := &rpc.ChalkboardPatch{Set: map[string]json.RawMessage{}}
+	if model != "" {
+		b, _ := json.Marshal(model)
+		p.Set["system.model"] = b
+	}
+	if temperature != "" {
+		t, err := strconv.ParseFloat(temperature, 64)
+		if err != nil {
+			return nil, fmt.Errorf("--temperature %q: not a number", temperature)
+		}
+		b, _ := json.Marshal(t)
+		p.Set["system.temperature"] = b
+	}
+	return p, nil
+}
+
+// findLastPrompt pages backward through the aria's IR until it meets a user
+// message carrying prose, returning its LT (the fork coordinate) and text.
+func findLastPrompt(ctx context.Context, acli *angelus.Client, ariaID string) (uint64, string, error) {
+	lt, text, ok, err := findLastProse(ctx, acli, ariaID, message.RoleUser)
+	if err == nil && !ok {
+		err = fmt.Errorf("%s has no prompt to retry", ariaID)
+	}
+	return lt, text, err
+}
+
+// findLastProse pages backward through the aria's IR for the newest message
+// of role that carries prose. ok is false when the log has none.
+func findLastProse(ctx context.Context, acli *angelus.Client, ariaID string, role message.Role) (uint64, string, bool, error) {
+	before := ^uint64(0)
+	for {
+		resp, err := acli.AriaReadBefore(ctx, ariaID, 0, before, retryScanPage)
+		if err != nil {
+			return 0, "", false, fmt.Errorf("aria.read: %w", err)
+		}
+		if len(resp.Entries) == 0 {
+			return 0, "", false, nil
+		}
+		if lt, text, ok := lastProseIn(resp.Entries, role); ok {
+			return lt, text, true, nil
+		}
+		before = resp.Entries[0].LT
+		if before <= 1 {
+			return 0, "", false, nil
+		}
+	}
+}
+
+// lastProseIn returns the newest message of role in an ascending page that
+// carries prose. Control turns (chalkboard-only messages) and tool results
+// are skipped.
+func lastProseIn(entries []rpc.AriaReadEntry, role message.Role) (uint64, string, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		var m message.Message
+		if json.Unmarshal(entries[i].Payload, &m) != nil || m.Role != role {
+			continue
+		}
+		var text string
+		for _, c := range m.Content {
+			if c.Type == message.ContentProse {
+				text += c.Text
+			}
+		}
+		if text != "" {
+			return entries[i].LT, text, true
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func retryEntry(t *testing.T, lt uint64, m message.Message) rpc.AriaReadEntry {
+	t.Helper()
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rpc.AriaReadEntry{LT: lt, Payload: raw}
+}
+
+func TestLastProseInSkipsToolResultsAndControlTurns(t *testing.T) {
+	entries := []rpc.AriaReadEntry{
+		retryEntry(t, 3, message.Message{Role: message.RoleUser, Content: []message.Content{message.TextContent("first")}}),
+		retryEntry(t, 4, message.Message{Role: message.RoleAssistant, Content: []message.Content{message.TextContent("a")}}),
+		retryEntry(t, 5, message.Message{Role: message.RoleUser, Content: []message.Content{message.TextContent("second")}}),
+		retryEntry(t, 6, message.Message{Role: message.RoleAssistant, Content: []message.Content{{Type: message.ContentToolInvoke, ToolCallID: "c1"}}}),
+		retryEntry(t, 7, message.Message{Role: message.RoleUser, Content: []message.Content{message.ToolResultContent("c1", "bash", "ok", false)}}),
+		retryEntry(t, 8, message.Message{Role: message.RoleAssistant, Content: []message.Content{message.TextContent("done")}}),
+		retryEntry(t, 9, message.Message{Role: message.RoleUser, Patches: []message.Patch{{Remove: []string{"x"}}}}),
+	}
+	lt, text, ok := lastProseIn(entries, message.RoleUser)
+	if !ok || lt != 5 || text != "second" {
+		t.Fatalf("lastProseIn = (%d, %q, %v), want (5, \"second\", true)", lt, text, ok)
+	}
+	if _, _, ok := lastProseIn(entries[5:], message.RoleUser); ok {
+		t.Fatal("a page without prose prompts must report no match")
+	}
+	if lt, text, _ := lastProseIn(entries, message.RoleAssistant); lt != 8 || text != "done" {
+		t.Fatalf("assistant scan = (%d, %q), want (8, \"done\")", lt, text)
+	}
+}
+
+func TestRetryPatch(t *testing.T) {
+	if p, err := retryPatch("", ""); err != nil || p != nil {
+		t.Fatalf("no knobs: got (%v, %v), want (nil, nil)", p, err)
+	}
+	p, err := retryPatch("gpt-5.6-luna", "0.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p.Set["system.model"]) != `"gpt-5.6-luna"` || string(p.Set["system.temperature"]) != "0.7" {
+		t.Fatalf("patch = %s / %s", p.Set["system.model"], p.Set["system.temperature"])
+	}
+	if _, err := retryPatch("", "warm"); err == nil {
+		t.Fatal("a non-numeric temperature must be rejected")
+	}
+}
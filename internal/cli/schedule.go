@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/term"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// A schedule is a prompt the daemon sends to one aria on a cron timetable.
+// The answers accumulate in that aria's log like any other turn. Schedules
+// live one file each under <state>/schedules; the CLI writes them and the
+// daemon re-reads the directory every minute, so there is no RPC to keep in
+// step and a schedule survives daemon restarts.
+
+// scheduleRecord is one schedule.
+type scheduleRecord struct {
+	ID         string `json:"id"`
+	Cron       string `json:"cron"`
+	Aria       string `json:"aria"`
+	Prompt     string `json:"prompt"`
+	Desktop    bool   `json:"desktop,omitempty"` // desktop notification when a run finishes
+	CreatedAt  int64  `json:"created_at"`        // unix millis
+	LastRun    int64  `json:"last_run,omitempty"`
+	LastReason string `json:"last_reason,omitempty"`
+}
+
+// scheduleTurnLimit bounds how long the daemon waits on one run's turn.done
+// before giving up on reporting it; the turn itself keeps going.
+const scheduleTurnLimit = time.Hour
+
+func scheduleDir() string { return filepath.Join(stateDir(), "schedules") }
+
+// saveSchedule writes s atomically, so the daemon never reads half a file.
+func saveSchedule(dir string, s scheduleRecord) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "."+s.ID+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, s.ID+".json"))
+}
+
+// loadSchedules reads every schedule, oldest first. Unreadable records are
+// skipped.
+func loadSchedules(dir string) ([]scheduleRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []scheduleRecord
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var s scheduleRecord
+		if json.Unmarshal(b, &s) == nil && s.ID != "" {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt < out[j].CreatedAt })
+	return out, nil
+}
+
+func newScheduleID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return "s" + hex.EncodeToString(b)
+}
+
+// dueSchedules picks the schedules that fire in now's minute and have not
+// already run in it.
+func dueSchedules(all []scheduleRecord, now time.Time) []scheduleRecord {
+	minute := now.Truncate(time.Minute)
+	var due []scheduleRecord
+	for _, s := range all {
+		spec, err := parseCron(s.Cron)
+		if err != nil || !spec.matches(minute) || s.LastRun >= minute.UnixMilli() {
+			continue
+		}
+		due = append(due, s)
+	}
+	return due
+}
+
+// runScheduler is the daemon's schedule loop. It wakes at the top of each
+// minute and sends every due prompt to its aria, restoring the aria first if
+// it is dormant.
+func runScheduler(ctx context.Context, dir string, restore func(context.Context, string) (figaro.Figaro, error)) {
+	for {
+		now := time.Now()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(now.Truncate(time.Minute).Add(time.Minute).Sub(now)):
+		}
+		all, err := loadSchedules(dir)
+		if err != nil {
+			slog.Warn("schedule: read", "dir", dir, "err", err)
+			continue
+		}
+		for _, s := range dueSchedules(all, time.Now()) {
+			s.LastRun = time.Now().UnixMilli()
+			s.LastReason = "running"
+			if err := saveSchedule(dir, s); err != nil {
+				slog.Warn("schedule: save", "id", s.ID, "err", err)
+				continue
+			}
+			go func(s scheduleRecord) {
+				s.LastReason = runSchedule(ctx, s, restore)
+				if _, err := os.Stat(filepath.Join(dir, s.ID+".json")); err != nil {
+					return // removed while it ran
+				}
+				if err := saveSchedule(dir, s); err != nil {
+					slog.Warn("schedule: save", "id", s.ID, "err", err)
+				}
+			}(s)
+		}
+	}
+}
+
+// runSchedule sends one run's prompt and waits for its turn to finish. It
+// returns the turn's stop reason, or an "error:" string.
+func runSchedule(ctx context.Context, s scheduleRecord, restore func(context.Context, string) (figaro.Figaro, error)) string {
+	start := time.Now()
+	slog.Info("schedule: run", "id", s.ID, "aria", s.Aria)
+	reason := func() string {
+		f, err := restore(ctx, s.Aria)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		ep := transport.Endpoint{Scheme: "unix", Address: f.SocketPath()}
+		if err := waitForSocket(ep.Address, 5*time.Second); err != nil {
+			return "error: " + err.Error()
+		}
+		done := make(chan string, 1)
+		fcli, err := figaro.DialClient(ep, func(method string, params json.RawMessage) {
+			if method != rpc.MethodTurnDone {
+				return
+			}
+			var d rpc.DoneEntry
+			_ = json.Unmarshal(params, &d)
+			select {
+			case done <- d.Reason:
+			default:
+			}
+		})
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		defer fcli.Close()
+		prompt, errs := expandPromptVars(s.Prompt)
+		for _, err := range errs {
+			slog.Warn("schedule: prompt variable", "id", s.ID, "err", err)
+		}
+		if _, err := fcli.Qua(ctx, prompt, nil); err != nil {
+			return "error: " + err.Error()
+		}
+		select {
+		case r := <-done:
+			return r
+		case <-fcli.Done():
+			return "error: aria disconnected"
+		case <-ctx.Done():
+			return "error: daemon stopped"
+		case <-time.After(scheduleTurnLimit):
+			return "error: no turn.done within " + scheduleTurnLimit.String()
+		}
+	}()
+	slog.Info("schedule: done", "id", s.ID, "aria", s.Aria, "reason", reason)
+	if s.Desktop {
+		title, body := turnNotice(s.Aria, reason, time.Since(start))
+		if err := term.Notify(title, body+" (schedule "+s.ID+")"); err != nil {
+			slog.Warn("schedule: notify", "id", s.ID, "err", err)
+		}
+	}
+	return reason
+}
+
+// runScheduleCmd dispatches `figaro schedule <action>`.
+func runScheduleCmd(loaded *config.Loaded, rawArgs []string) {
+	if len(rawArgs) == 0 {
+		die("usage: figaro schedule add|list|remove ...")
+	}
+	action, args := rawArgs[0], rawArgs[1:]
+	switch action {
+	case "add":
+		runScheduleAdd(loaded, args)
+	case "list", "ls":
+		runScheduleList(hasPreDashFlag(args, "--json", "-j"))
+	case "remove", "rm":
+		if len(args) != 1 {
+			die("usage: figaro schedule remove <id>")
+		}
+		if strings.ContainsAny(args[0], `/\.`) {
+			die("schedule remove: bad id %q", args[0])
+		}
+		if err := os.Remove(filepath.Join(scheduleDir(), args[0]+".json")); err != nil {
+			if os.IsNotExist(err) {
+				die("schedule remove: no schedule %s (figaro schedule list)", args[0])
+			}
+			die("schedule remove: %s", err)
+		}
+	default:
+		die("schedule: unknown action %q (want add, list or remove)", action)
+	}
+}
+
+// runScheduleAdd records a schedule. Without --aria, a fresh unbound aria is
+// created to collect the runs, so they do not interleave with a session.
+// --sink sets the aria's system.sink, so every run's answer is delivered.
+func runScheduleAdd(loaded *config.Loaded, args []string) {
+	const usage = "usage: figaro schedule add --cron \"<m h dom mon dow>\" [--aria <id> | -L <loadout>] [--notify] [--sink <name>] -- <prompt>"
+	expr, ok, err := preDashFlagValue(args, "--cron")
+	if err != nil {
+		die("schedule add: %s", err)
+	}
+	prompt := extractPrompt(args)
+	if !ok || prompt == "" {
+		die(usage)
+	}
+	spec, err := parseCron(expr)
+	if err != nil {
+		die("schedule add: %s", err)
+	}
+	ariaID, _, err := preDashFlagValue(args, "--aria", "-a")
+	if err != nil {
+		die("schedule add: %s", err)
+	}
+	loadout, _, err := preDashFlagValue(args, "--loadout", "-L")
+	if err != nil {
+		die("schedule add: %s", err)
+	}
+	sink, _, err := preDashFlagValue(args, "--sink")
+	if err != nil {
+		die("schedule add: %s", err)
+	}
+	if _, ok := loaded.Config.Sinks[sink]; sink != "" && !ok {
+		die("schedule add: no [sinks.%s] in config", sink)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if ariaID != "" {
+		if loadout != "" {
+			die("schedule add: --aria and --loadout conflict (the aria already has a loadout)")
+		}
+		if err := rpc.ValidateAriaID(ariaID); err != nil {
+			die("schedule add: %s", err)
+		}
+	} else {
+		resp, err := createWithFirstRun(ctx, loaded, func() (*rpc.CreateResponse, error) { return acli.Create(ctx, loadout, nil) })
+		if err != nil {
+			die("create figaro: %s", err)
+		}
+		ariaID = resp.FigaroID
+	}
+	if sink != "" {
+		name, _ := json.Marshal(sink)
+		mustCallSet(loaded, ariaID, rpc.ChalkboardPatch{Set: map[string]json.RawMessage{sinkKey: name}})
+	}
+
+	s := scheduleRecord{
+		ID:        newScheduleID(),
+		Cron:      strings.Join(strings.Fields(expr), " "),
+		Aria:      ariaID,
+		Prompt:    prompt,
+		Desktop:   hasPreDashFlag(args, "--notify"),
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if err := saveSchedule(scheduleDir(), s); err != nil {
+		die("schedule add: %s", err)
+	}
+	fmt.Println(s.ID)
+	fmt.Fprintf(os.Stderr, "scheduled into aria %s — next run %s\n", ariaID, scheduleNext(spec, time.Now()))
+}
+
+// scheduleNext formats a spec's next fire time for display.
+func scheduleNext(spec cronSpec, now time.Time) string {
+	t := spec.next(now)
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("Mon Jan 2 15:04")
+}
+
+func runScheduleList(asJSON bool) {
+	all, err := loadSchedules(scheduleDir())
+	if err != nil {
+		die("schedule list: %s", err)
+	}
+	if asJSON {
+		if all == nil {
+			all = []scheduleRecord{}
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(all)
+		return
+	}
+	if len(all) == 0 {
+		fmt.Fprintln(os.Stderr, "no schedules (figaro schedule add --cron \"...\" -- <prompt>)")
+		return
+	}
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCRON\tARIA\tNEXT\tLAST\tPROMPT")
+	for _, s := range all {
+		next := "invalid cron"
+		if spec, err := parseCron(s.Cron); err == nil {
+			next = scheduleNext(spec, now)
+		}
+		last := "-"
+		if s.LastRun > 0 {
+			last = relAge(s.LastRun) + " ago, " + s.LastReason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Cron, s.Aria, next, last, taskSummary(s.Prompt, 50))
+	}
+	w.Flush()
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScheduleRecordsRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schedules")
+	for _, s := range []scheduleRecord{
+		{ID: "s2", Cron: "0 9 * * 1", Aria: "bb", Prompt: "two", CreatedAt: 200},
+		{ID: "s1", Cron: "* * * * *", Aria: "aa", Prompt: "one", CreatedAt: 100, Desktop: true},
+	} {
+		if err := saveSchedule(dir, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := loadSchedules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "s1" || got[1].ID != "s2" || !got[0].Desktop {
+		t.Fatalf("loadSchedules = %+v, want s1 then s2", got)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*.tmp")); len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+}
+
+func TestDueSchedulesFireOncePerMinute(t *testing.T) {
+	now, _ := time.ParseInLocation("2006-01-02 15:04:05", "2026-10-12 09:00:03", time.Local)
+	all := []scheduleRecord{
+		{ID: "monday", Cron: "0 9 * * 1"},
+		{ID: "ran", Cron: "0 9 * * 1", LastRun: now.Add(-time.Second).UnixMilli()},
+		{ID: "earlier", Cron: "0 9 * * 1", LastRun: now.Add(-7 * 24 * time.Hour).UnixMilli()},
+		{ID: "other", Cron: "30 9 * * *"},
+		{ID: "broken", Cron: "nope"},
+	}
+	due := dueSchedules(all, now)
+	if len(due) != 2 || due[0].ID != "monday" || due[1].ID != "earlier" {
+		t.Fatalf("due = %+v, want monday and earlier", due)
+	}
+}
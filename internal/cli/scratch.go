@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// One-off conversations (send -e, plain without an aria) run on ephemeral
+// arias that die with the command. Before the kill, the CLI copies the log
+// into <data>/scratch in the archive format, named for the time and the
+// prompt's first words, so figaro keep can still turn it into a real
+// conversation. Saving a scratch prunes those older than scratch_days.
+
+func scratchDir() string { return filepath.Join(filepath.Dir(ariaDir()), "scratch") }
+
+// scratchWords caps how much of the prompt goes into a scratch name.
+const scratchWords = 4
+
+// scratchName is e.g. "20261015-153002-fix-the-flaky-test".
+func scratchName(now time.Time, prompt string) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	}) {
+		if len(words) == scratchWords {
+			break
+		}
+		words = append(words, w)
+	}
+	name := now.Format("20060102-150405")
+	if len(words) > 0 {
+		name += "-" + strings.Join(words, "-")
+	}
+	return name
+}
+
+// endEphemeral saves an ephemeral aria as a scratch (when scratch_days
+// allows), then kills it.
+func endEphemeral(loaded *config.Loaded, acli *angelus.Client, id string, ep transport.Endpoint, prompt string) {
+	if days := loaded.ScratchDays(); days > 0 {
+		if err := saveScratch(ep, prompt, days); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: scratch: %s\n", err)
+		}
+	}
+	killCtx, killCancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer killCancel()
+	_ = acli.Kill(killCtx, id, false)
+}
+
+func saveScratch(ep transport.Endpoint, prompt string, days int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	fcli, err := figaro.DialClient(ep, func(string, json.RawMessage) {})
+	if err != nil {
+		return err
+	}
+	defer fcli.Close()
+	resp, err := fcli.Context(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(resp.Messages)
+	if err != nil {
+		return err
+	}
+	var msgs []message.Message
+	if err := json.Unmarshal(b, &msgs); err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	a := archivedAria{ID: scratchName(now, prompt), ArchivedAt: now.UnixMilli(), Messages: msgs}
+	if cb, err := fcli.Chalkboard(ctx); err == nil {
+		a.Chalkboard = cb.Snapshot
+		_ = json.Unmarshal(cb.Snapshot["mantra"], &a.Mantra)
+	}
+	dir := scratchDir()
+	pruneScratch(dir, now.AddDate(0, 0, -days))
+	_, err = writeArchive(dir, a)
+	return err
+}
+
+// pruneScratch removes scratches written before cutoff.
+func pruneScratch(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json.gz")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			removeArchive(dir, id)
+		}
+	}
+}
+
+// runKeep turns a scratch (the newest by default) into a real aria, binds
+// this shell to it and, with name, records it as that named conversation
+// (the one a .figaro.yaml conversation: continues).
+func runKeep(loaded *config.Loaded, scratch, name string) {
+	if name != "" && !bareName.MatchString(name) {
+		die("keep: name %q must be letters, digits, '-' or '_'", name)
+	}
+	if scratch == "" {
+		all, err := listArchives(scratchDir())
+		if err != nil {
+			die("keep: %s", err)
+		}
+		if len(all) == 0 {
+			die("keep: no scratch conversations (one-off sends land there; see scratch_days)")
+		}
+		scratch = all[0].ID
+	} else if strings.ContainsAny(scratch, `/\`) {
+		die("keep: bad scratch name %q", scratch)
+	}
+	a, err := readArchive(scratchDir(), scratch)
+	if err != nil {
+		if os.IsNotExist(err) {
+			die("keep: no scratch %q (try: figaro keep --list)", scratch)
+		}
+		die("keep: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	id, err := restoreArchive(ctx, acli, a)
+	if err != nil {
+		die("keep: %s", err)
+	}
+	if err := removeArchive(scratchDir(), a.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+	}
+	if err := bindBinding(ctx, acli, os.Getppid(), id, 0); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not attend %s: %s\n", id, err)
+	}
+	if name != "" {
+		if err := saveNamedConversation(conversationDir(), conversationKey(keepProjectDir(), name), id); err != nil {
+			die("keep: %s", err)
+		}
+		fmt.Fprintf(os.Stderr, "kept %s as conversation %q (%s)\n", a.ID, name, id)
+	} else {
+		fmt.Fprintf(os.Stderr, "kept %s as %s\n", a.ID, id)
+	}
+	fmt.Println(id)
+}
+
+// keepProjectDir is the project keep --name records for: the nearest
+// .figaro.yaml's directory, else the working directory (where one may be
+// added later).
+func keepProjectDir() string {
+	cwd, _ := os.Getwd()
+	if proj, err := findProjectConfig(cwd); err == nil && proj != nil {
+		return proj.dir()
+	}
+	return cwd
+}
+
+func runKeepList() {
+	all, err := listArchives(scratchDir())
+	if err != nil {
+		die("keep: %s", err)
+	}
+	if len(all) == 0 {
+		fmt.Fprintln(os.Stderr, "no scratch conversations")
+		return
+	}
+	printArchives(os.Stdout, all)
+}
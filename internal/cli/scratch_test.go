@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScratchName(t *testing.T) {
+	now := time.Date(2026, 10, 15, 15, 30, 2, 0, time.Local)
+	cases := []struct{ prompt, want string }{
+		{"Fix the flaky test in CI, please", "20261015-153002-fix-the-flaky-test"},
+		{"¿Qué hora es?", "20261015-153002-qu-hora-es"},
+		{"  ", "20261015-153002"},
+		{"../../etc/passwd", "20261015-153002-etc-passwd"},
+	}
+	for _, c := range cases {
+		if got := scratchName(now, c.prompt); got != c.want {
+			t.Errorf("scratchName(%q) = %q, want %q", c.prompt, got, c.want)
+		}
+	}
+}
+
+func TestPruneScratch(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.json.gz")
+	fresh := filepath.Join(dir, "fresh.json.gz")
+	other := filepath.Join(dir, "notes.txt")
+	for _, p := range []string{old, fresh, other} {
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	week := time.Now().AddDate(0, 0, -8)
+	for _, p := range []string{old, other} {
+		if err := os.Chtimes(p, week, week); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruneScratch(dir, time.Now().AddDate(0, 0, -7))
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("old scratch kept")
+	}
+	for _, p := range []string{fresh, other} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s removed", filepath.Base(p))
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/config"
 	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/render"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/term"
 	"github.com/jack-work/figaro/internal/transport"
@@ -22,19 +23,32 @@ import (
 
 // sendOpts captures the parsed flag state of the send command.
 type sendOpts struct {
-	id        string
-	target    string // positional [<trunk>]:<LT> target (alt to --id)
-	stay      bool   // --attend=false / --stay: don't rebind to the new branch
-	ephemeral bool
-	raw       bool // --raw / -r: raw stream, no ANSI/markdown
-	verbatim  bool // --verbatim / -v: dump raw wire frames as JSON
-	verbose   bool // --verbose / -o (or -t alias): expand tool inputs (Ctrl-O toggles live)
-	exec      bool
-	dryRun    bool // --exec only
-	skipYes   bool // --exec only
-	forget    bool // --forget / -f: submit and exit; do not stream
-	json      bool // --json / -j: emit machine-readable result on stdout ({aria_id, ...})
-	listen    bool // --listen / -l: auto-enter transcript and stay open past turn-done
+	id         string
+	target     string // positional [<trunk>]:<LT> target (alt to --id)
+	stay       bool   // --attend=false / --stay: don't rebind to the new branch
+	ephemeral  bool
+	raw        bool // --raw / -r: raw stream, no ANSI/markdown
+	verbatim   bool // --verbatim / -v: dump raw wire frames as JSON
+	verbose    bool // --verbose / -o (or -t alias): expand tool inputs (Ctrl-O toggles live)
+	exec       bool
+	dryRun     bool   // --exec only
+	skipYes    bool   // --exec only
+	forget     bool   // --forget / -f: submit and exit; do not stream
+	json       bool   // --json / -j: emit machine-readable result on stdout ({aria_id, ...})
+	listen     bool   // --listen / -l: auto-enter transcript and stay open past turn-done
+	paste      bool   // --paste: append the clipboard to the prompt
+	output     string // --output <path>: tee the raw answer markdown to a file
+	format     string // --format ansi|plain|json; plain is --raw
+	eventsJSON bool   // --events-json: --format json
+
+	retryLast   bool   // --retry-last: re-ask the last prompt on a fresh branch
+	model       string // --model: system.model for the retry branch
+	temperature string // --temperature: system.temperature for the retry branch
+
+	replyLang string // --reply-lang: system.reply_lang for the aria
+	persona   string // --persona: config [personas] name applied with the prompt
+	webSearch string // --web-search on|off: system.web_search for the aria
+	force     bool   // --force: send past a reached [budget] limit
 }
 
 // extractSendFlags scans a PassRaw arg list for the send command's
@@ -163,6 +177,109 @@ func extractSendFlags(args []string) (sendOpts, []string, error) {
 			opts.json = true
 			i++
 			continue
+		case a == "--paste":
+			opts.paste = true
+			i++
+			continue
+		case a == "--format":
+			if i+1 >= len(expanded) || expanded[i+1] == "--" {
+				return opts, nil, fmt.Errorf("--format requires a value")
+			}
+			opts.format = expanded[i+1]
+			i += 2
+			continue
+		case strings.HasPrefix(a, "--format="):
+			opts.format = strings.TrimPrefix(a, "--format=")
+			i++
+			continue
+		case a == "--events-json":
+			opts.eventsJSON = true
+			i++
+			continue
+		case a == "--output":
+			if i+1 >= len(expanded) || expanded[i+1] == "--" {
+				return opts, nil, fmt.Errorf("--output requires a path")
+			}
+			opts.output = expanded[i+1]
+			i += 2
+			continue
+		case strings.HasPrefix(a, "--output="):
+			opts.output = strings.TrimPrefix(a, "--output=")
+			if opts.output == "" {
+				return opts, nil, fmt.Errorf("--output requires a path")
+			}
+			i++
+			continue
+		case a == "--reply-lang":
+			if i+1 >= len(expanded) || expanded[i+1] == "--" {
+				return opts, nil, fmt.Errorf("--reply-lang requires a language")
+			}
+			opts.replyLang = expanded[i+1]
+			i += 2
+			continue
+		case strings.HasPrefix(a, "--reply-lang="):
+			opts.replyLang = strings.TrimPrefix(a, "--reply-lang=")
+			if opts.replyLang == "" {
+				return opts, nil, fmt.Errorf("--reply-lang requires a language")
+			}
+			i++
+			continue
+		case a == "--web-search" || strings.HasPrefix(a, "--web-search="):
+			v, ok := strings.CutPrefix(a, "--web-search=")
+			if !ok {
+				if i+1 >= len(expanded) || expanded[i+1] == "--" {
+					return opts, nil, fmt.Errorf("--web-search requires on or off")
+				}
+				v = expanded[i+1]
+				i++
+			}
+			if v != "on" && v != "off" {
+				return opts, nil, fmt.Errorf("--web-search requires on or off, not %q", v)
+			}
+			opts.webSearch = v
+			i++
+			continue
+		case a == "--force":
+			opts.force = true
+			i++
+			continue
+		case a == "--persona":
+			if i+1 >= len(expanded) || expanded[i+1] == "--" {
+				return opts, nil, fmt.Errorf("--persona requires a name")
+			}
+			opts.persona = expanded[i+1]
+			i += 2
+			continue
+		case strings.HasPrefix(a, "--persona="):
+			opts.persona = strings.TrimPrefix(a, "--persona=")
+			if opts.persona == "" {
+				return opts, nil, fmt.Errorf("--persona requires a name")
+			}
+			i++
+			continue
+		case a == "--retry-last":
+			opts.retryLast = true
+			i++
+			continue
+		case a == "--model", a == "--temperature":
+			if i+1 >= len(expanded) || expanded[i+1] == "--" {
+				return opts, nil, fmt.Errorf("%s requires a value", a)
+			}
+			if a == "--model" {
+				opts.model = expanded[i+1]
+			} else {
+				opts.temperature = expanded[i+1]
+			}
+			i += 2
+			continue
+		case strings.HasPrefix(a, "--model="):
+			opts.model = strings.TrimPrefix(a, "--model=")
+			i++
+			continue
+		case strings.HasPrefix(a, "--temperature="):
+			opts.temperature = strings.TrimPrefix(a, "--temperature=")
+			i++
+			continue
 		case a == "--stay", a == "--no-attend", a == "--attend=false", a == "--attend=0":
 			opts.stay = true
 			i++
@@ -186,6 +303,32 @@ func extractSendFlags(args []string) (sendOpts, []string, error) {
 	return opts, rest, nil
 }
 
+// promptPaste is send --paste's clipboard text, held apart from the typed
+// prompt until expandAtRefsForEndpoint has expanded that: a pasted
+// {{file}} or {{shell}} must stay literal. Taken by the first send.
+var promptPaste string
+
+// takePaste returns the pending --paste text and clears it, so a reply
+// typed later in the same session doesn't carry it again.
+func takePaste() string {
+	clip := promptPaste
+	promptPaste = ""
+	return clip
+}
+
+// withPaste appends clipboard text to the prompt as its own paragraph. A
+// blank clipboard leaves the prompt alone.
+func withPaste(prompt, clip string) string {
+	clip = strings.TrimRight(clip, "\n")
+	if strings.TrimSpace(clip) == "" {
+		return prompt
+	}
+	if prompt == "" {
+		return clip
+	}
+	return prompt + "\n\n" + clip
+}
+
 // parseSendTarget splits a send target spec into a trunk id and an optional
 // :<LT>. "" -> bound trunk, no LT. ":6" -> bound trunk at LT 6. "t1:6" ->
 // trunk t1 at LT 6. "t1" -> trunk t1, no LT.
@@ -239,10 +382,61 @@ func runSend(loaded *config.Loaded, rawArgs []string) {
 	if err != nil {
 		die("send: %s", err)
 	}
+	promptReplyLang = strings.TrimSpace(opts.replyLang)
+	promptWebSearch = opts.webSearch
+	if opts.persona != "" {
+		if promptPersona, err = personaPatch(loaded, opts.persona); err != nil {
+			die("send: %s", err)
+		}
+	}
+	if opts.eventsJSON {
+		if opts.format != "" && opts.format != formatJSON {
+			die("send: --events-json contradicts --format %s", opts.format)
+		}
+		opts.format = formatJSON
+	}
+	format, ferr := parseFormat(opts.format)
+	if ferr != nil {
+		die("send: %s", ferr)
+	}
+	switch {
+	case format == formatJSON && opts.raw:
+		die("send: --raw contradicts --format json")
+	case format != formatANSI:
+		opts.raw = true // plain and json both take the non-interactive path
+	case term.Legacy() && opts.output == "":
+		opts.raw = true // a console without VT sequences can't draw the live view
+	}
 	prompt := extractPrompt(rest)
-	if prompt == "" {
+	if opts.paste {
+		if opts.retryLast {
+			die("send: --paste contradicts --retry-last (the prompt is the old one)")
+		}
+		clip, perr := term.Paste()
+		if perr != nil {
+			die("send: --paste: %s", perr)
+		}
+		promptPaste = trimAttachment("pasted text", clip, loaded.AttachmentBytes())
+	}
+	if (opts.model != "" || opts.temperature != "") && !opts.retryLast {
+		die("send: --model / --temperature only meaningful with --retry-last")
+	}
+	if opts.retryLast {
+		if prompt != "" {
+			die("send: --retry-last re-asks the last prompt; drop the prompt body")
+		}
+		if opts.ephemeral || opts.exec || opts.verbatim || opts.forget || opts.raw || opts.target != "" {
+			die("send: --retry-last is not compatible with a target or --ephemeral/--exec/--verbatim/--forget/--raw")
+		}
+		runSendRetry(loaded, opts, renderSettings{verbose: opts.verbose, listen: opts.listen, output: opts.output, force: opts.force})
+		return
+	}
+	if prompt == "" && strings.TrimSpace(promptPaste) == "" {
 		die("usage: figaro send [--id <id>] [-e|--ephemeral] [-r|--raw] [-v|--verbatim] [-x|--exec] [-n] [-y] -- <prompt>")
 	}
+	if !opts.raw {
+		render.Prewarm(termWidth())
+	}
 
 	spec := opts.id
 	if spec == "" {
@@ -262,11 +456,14 @@ func runSend(loaded *config.Loaded, rawArgs []string) {
 	if opts.forget && (opts.exec || opts.verbatim) {
 		die("send: --forget contradicts --exec/--verbatim")
 	}
+	if opts.output != "" && (opts.forget || opts.exec || opts.verbatim || opts.raw) {
+		die("send: --output tees the rendered stream; it contradicts --forget/--exec/--verbatim/--raw/--format (redirect --raw instead)")
+	}
 	if opts.forget && opts.ephemeral {
 		die("send: --forget contradicts --ephemeral (the aria would be killed before the turn ran)")
 	}
 
-	set := renderSettings{verbose: opts.verbose, listen: opts.listen}
+	set := renderSettings{verbose: opts.verbose, listen: opts.listen, output: opts.output, force: opts.force}
 
 	// `send <trunk>:<LT>` — fork at LT, then send. The message lands on
 	// whichever trunk we end up attended to: the new alternative by default
@@ -275,7 +472,7 @@ func runSend(loaded *config.Loaded, rawArgs []string) {
 		if opts.ephemeral || opts.exec || opts.verbatim {
 			die("send: <trunk>:<LT> is not compatible with --ephemeral/--exec/--verbatim")
 		}
-		runSendForkAt(loaded, trunkID, atMainLT, opts.stay, opts.json, prompt, set)
+		runSendForkAt(loaded, trunkID, atMainLT, opts.stay, false, opts.json, prompt, set)
 		return
 	}
 	// No LT: a positional target is just the aria to send to.
@@ -291,11 +488,11 @@ func runSend(loaded *config.Loaded, rawArgs []string) {
 	case opts.exec:
 		runSendExec(loaded, opts, prompt)
 	case opts.ephemeral && opts.raw:
-		runSendEphemeralRaw(loaded, prompt)
+		runSendEphemeralRaw(loaded, prompt, format, opts.force)
 	case opts.ephemeral:
 		runSendEphemeralRich(loaded, prompt, set)
 	case opts.raw:
-		runSendRaw(loaded, opts.id, prompt)
+		runSendRaw(loaded, opts.id, prompt, format, opts.force)
 	default:
 		// Today's interactive send: pid-bound or --id named.
 		if opts.id == "" {
@@ -306,9 +503,10 @@ func runSend(loaded *config.Loaded, rawArgs []string) {
 	}
 }
 
-// runSendEphemeralRaw spins an ephemeral aria, streams raw output
-// to stdout, kills it. Today's `figaro plain` with no --id.
-func runSendEphemeralRaw(loaded *config.Loaded, prompt string) {
+// runSendEphemeralRaw spins an ephemeral aria, streams raw output (or
+// --format json events) to stdout, kills it. Today's `figaro plain` with
+// no --id.
+func runSendEphemeralRaw(loaded *config.Loaded, prompt, format string, force bool) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
@@ -321,17 +519,13 @@ func runSendEphemeralRaw(loaded *config.Loaded, prompt string) {
 	}
 	figaroID := createResp.FigaroID
 	figaroEP := transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
-	defer func() {
-		killCtx, killCancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer killCancel()
-		_ = acli.Kill(killCtx, figaroID, false)
-	}()
+	defer func() { endEphemeral(loaded, acli, figaroID, figaroEP, prompt) }()
 	if err := waitForSocket(figaroEP.Address, 3*time.Second); err != nil {
 		die("send: %s", err)
 	}
 
 	prompt = expandAtRefsForEndpoint(ctx, figaroEP, prompt)
-	exitCode := plainPrompt(ctx, figaroEP, prompt, os.Stdout)
+	exitCode := sinkPrompt(ctx, figaroEP, prompt, force, newStreamSinkFor(format, os.Stdout, figaroID))
 	if exitCode != 0 {
 		os.Exit(exitCode)
 	}
@@ -339,7 +533,7 @@ func runSendEphemeralRaw(loaded *config.Loaded, prompt string) {
 
 // runSendEphemeralRich spins an ephemeral aria, interactive (rich)
 // stream, kills it. Useful for one-off conversations the user wants
-// to see formatted but not persist.
+// to see formatted but not keep (a scratch copy lingers for figaro keep).
 func runSendEphemeralRich(loaded *config.Loaded, prompt string, set renderSettings) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -353,11 +547,7 @@ func runSendEphemeralRich(loaded *config.Loaded, prompt string, set renderSettin
 	}
 	figaroID := createResp.FigaroID
 	figaroEP := transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
-	defer func() {
-		killCtx, killCancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer killCancel()
-		_ = acli.Kill(killCtx, figaroID, false)
-	}()
+	defer func() { endEphemeral(loaded, acli, figaroID, figaroEP, prompt) }()
 	if err := waitForSocket(figaroEP.Address, 3*time.Second); err != nil {
 		die("send: %s", err)
 	}
@@ -366,22 +556,23 @@ func runSendEphemeralRich(loaded *config.Loaded, prompt string, set renderSettin
 	mustPromptFigaro(ctx, figaroEP, figaroID, prompt, loaded, set)
 }
 
-// runSendRaw streams raw output from a persistent aria (bound or
-// named). The aria is left alive; only the formatting is raw.
-func runSendRaw(loaded *config.Loaded, ariaID, prompt string) {
+// runSendRaw streams raw output (or --format json events) from a
+// persistent aria (bound or named). The aria is left alive; only the
+// formatting is raw.
+func runSendRaw(loaded *config.Loaded, ariaID, prompt, format string, force bool) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
 	acli := mustConnectAngelus(loaded)
 	defer acli.Close()
 
-	_, figaroEP, err := resolveTargetEndpoint(ctx, loaded, acli, ariaID, true)
+	figaroID, figaroEP, err := resolveTargetEndpoint(ctx, loaded, acli, ariaID, true)
 	if err != nil {
 		die("%s", err)
 	}
 
 	prompt = expandAtRefsForEndpoint(ctx, figaroEP, prompt)
-	exitCode := plainPrompt(ctx, figaroEP, prompt, os.Stdout)
+	exitCode := sinkPrompt(ctx, figaroEP, prompt, force, newStreamSinkFor(format, os.Stdout, figaroID))
 	if exitCode != 0 {
 		os.Exit(exitCode)
 	}
@@ -525,9 +716,10 @@ func runSendForget(loaded *config.Loaded, opts sendOpts, prompt string) {
 		die("connect figaro: %s", derr)
 	}
 	defer fcli.Close()
+	fcli.Author = promptAuthor
 
-	if _, qerr := fcli.Qua(ctx, prompt, buildPromptChalkboard()); qerr != nil {
-		die("prompt: %s", qerr)
+	if _, qerr := fcli.QuaForce(ctx, prompt, buildPromptChalkboard(), opts.force); qerr != nil {
+		diePrompt(qerr)
 	}
 	if opts.json {
 		enc := json.NewEncoder(os.Stdout)
@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/jack-work/figaro/internal/transport"
 )
 
 func TestExtractSendFlags(t *testing.T) {
@@ -143,12 +146,109 @@ func TestExtractSendFlags(t *testing.T) {
 			in:      []string{"--id", "bad/id", "--", "p"},
 			wantErr: "--id",
 		},
+		{
+			name:     "paste",
+			in:       []string{"--paste", "--", "explain", "this"},
+			wantOpts: sendOpts{paste: true},
+			wantRest: []string{"--", "explain", "this"},
+		},
+		{
+			name:     "format",
+			in:       []string{"--format", "json", "--", "p"},
+			wantOpts: sendOpts{format: "json"},
+			wantRest: []string{"--", "p"},
+		},
+		{
+			name:     "events json",
+			in:       []string{"--events-json", "--", "p"},
+			wantOpts: sendOpts{eventsJSON: true},
+			wantRest: []string{"--", "p"},
+		},
+		{
+			name:     "output",
+			in:       []string{"--output", "answer.md", "--", "p"},
+			wantOpts: sendOpts{output: "answer.md"},
+			wantRest: []string{"--", "p"},
+		},
+		{
+			name:     "output equals",
+			in:       []string{"--output=answer.md", "-l", "--", "p"},
+			wantOpts: sendOpts{output: "answer.md", listen: true},
+			wantRest: []string{"--", "p"},
+		},
+		{
+			name:    "output missing path",
+			in:      []string{"--output", "--", "p"},
+			wantErr: "--output requires a path",
+		},
+		{
+			name:     "retry last",
+			in:       []string{"--retry-last"},
+			wantOpts: sendOpts{retryLast: true},
+			wantRest: []string{},
+		},
+		{
+			name:     "retry with model and temperature",
+			in:       []string{"--retry-last", "--model", "claude-opus-4-6", "--temperature=0.4"},
+			wantOpts: sendOpts{retryLast: true, model: "claude-opus-4-6", temperature: "0.4"},
+			wantRest: []string{},
+		},
+		{
+			name:    "model missing value",
+			in:      []string{"--retry-last", "--model"},
+			wantErr: "--model requires a value",
+		},
 		{
 			name:     "no -- boundary",
 			in:       []string{"-e", "hello"},
 			wantOpts: sendOpts{ephemeral: true},
 			wantRest: []string{"hello"},
 		},
+		{
+			name:     "reply lang",
+			in:       []string{"--reply-lang", "French", "--", "hola"},
+			wantOpts: sendOpts{replyLang: "French"},
+			wantRest: []string{"--", "hola"},
+		},
+		{
+			name:     "reply lang equals form",
+			in:       []string{"--reply-lang=ja", "hi"},
+			wantOpts: sendOpts{replyLang: "ja"},
+			wantRest: []string{"hi"},
+		},
+		{
+			name:    "reply lang without value",
+			in:      []string{"--reply-lang", "--", "hi"},
+			wantErr: "--reply-lang requires a language",
+		},
+		{
+			name:     "web search",
+			in:       []string{"--web-search", "on", "--", "news?"},
+			wantOpts: sendOpts{webSearch: "on"},
+			wantRest: []string{"--", "news?"},
+		},
+		{
+			name:     "web search equals form",
+			in:       []string{"--web-search=off", "hi"},
+			wantOpts: sendOpts{webSearch: "off"},
+			wantRest: []string{"hi"},
+		},
+		{
+			name:    "web search bad value",
+			in:      []string{"--web-search", "maybe", "--", "hi"},
+			wantErr: `--web-search requires on or off, not "maybe"`,
+		},
+		{
+			name:     "persona",
+			in:       []string{"--persona", "reviewer", "--", "look"},
+			wantOpts: sendOpts{persona: "reviewer"},
+			wantRest: []string{"--", "look"},
+		},
+		{
+			name:    "persona without value",
+			in:      []string{"--persona=", "hi"},
+			wantErr: "--persona requires a name",
+		},
 		{
 			name:     "flags ignored after --",
 			in:       []string{"-e", "--", "-x", "should", "be", "prompt"},
@@ -208,3 +308,30 @@ func TestParseSendTarget(t *testing.T) {
 		}
 	}
 }
+
+func TestWithPaste(t *testing.T) {
+	cases := []struct{ prompt, clip, want string }{
+		{"explain", "x := 1\n", "explain\n\nx := 1"},
+		{"", "only the clip", "only the clip"},
+		{"keep", "  \n", "keep"},
+	}
+	for _, c := range cases {
+		if got := withPaste(c.prompt, c.clip); got != c.want {
+			t.Errorf("withPaste(%q, %q) = %q, want %q", c.prompt, c.clip, got, c.want)
+		}
+	}
+}
+
+func TestPasteIsNotExpanded(t *testing.T) {
+	t.Setenv("FIGARO_PASTE_TEST", "secret")
+	promptPaste = `{{env.FIGARO_PASTE_TEST}} {{file "/etc/hostname"}}`
+	t.Cleanup(func() { promptPaste = "" })
+
+	got := expandAtRefsForEndpoint(context.Background(), transport.Endpoint{}, "typed {{env.FIGARO_PASTE_TEST}}")
+	if want := "typed secret\n\n" + `{{env.FIGARO_PASTE_TEST}} {{file "/etc/hostname"}}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := expandAtRefsForEndpoint(context.Background(), transport.Endpoint{}, "reply"); got != "reply" {
+		t.Errorf("a later reply = %q, want the paste taken once", got)
+	}
+}
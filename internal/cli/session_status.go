@@ -6,8 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jack-work/figaro/internal/config"
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/livelog/aria"
+	"github.com/jack-work/figaro/internal/locale"
 	"github.com/jack-work/figaro/internal/term"
 	"github.com/mattn/go-runewidth"
 )
@@ -29,10 +31,35 @@ type sessionStatus struct {
 	metrics   aria.Metrics
 	turn      turnStatus
 	tick      uint64
+
+	// Live turn progress: when the turn began, and how much assistant text
+	// it has streamed so far (closed messages in streamBase, the open one in
+	// streamCur) for the "~N tok" estimate.
+	turnStart  time.Time
+	turnEnd    time.Time
+	streamLT   int
+	streamBase int
+	streamCur  int
+
+	// latency is the last turn's time to first streamed text; firstText
+	// latches when that text arrives.
+	latency   time.Duration
+	firstText bool
+
+	// budget is the remaining-budget token, re-read after each turn.
+	budget string
 }
 
+// modelPrices prices the session cost shown in the status surfaces; set from
+// config [prices] at startup.
+var modelPrices map[string]config.Price
+
+// charsPerToken is the rough chars→tokens ratio for the live estimate; the
+// exact count lands with the message's usage metrics.
+const charsPerToken = 4
+
 func newSessionStatus(figaroID string, startedAt time.Time) *sessionStatus {
-	return &sessionStatus{figaroID: figaroID, startedAt: startedAt}
+	return &sessionStatus{figaroID: figaroID, startedAt: startedAt, budget: budgetLeft()}
 }
 
 func (s *sessionStatus) update(metrics aria.Metrics) {
@@ -49,15 +76,44 @@ func (s *sessionStatus) beginTurn() {
 		return
 	}
 	s.mu.Lock()
+	if s.turn != turnStatusThinking {
+		s.turnStart, s.turnEnd = time.Now(), time.Time{}
+		s.streamLT, s.streamBase, s.streamCur = 0, 0, 0
+		s.firstText = false
+	}
 	s.turn = turnStatusThinking
 	s.mu.Unlock()
 }
 
+// streaming records the open assistant message's streamed text size. A new
+// LT means the previous message closed; its size moves into the turn total.
+func (s *sessionStatus) streaming(lt, chars int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if lt != s.streamLT {
+		s.streamBase += s.streamCur
+		s.streamLT = lt
+	}
+	s.streamCur = chars
+	if chars > 0 && !s.firstText && !s.turnStart.IsZero() {
+		s.firstText = true
+		s.latency = time.Since(s.turnStart)
+	}
+	s.mu.Unlock()
+}
+
 func (s *sessionStatus) finishTurn(reason string) {
 	if s == nil {
 		return
 	}
+	left := budgetLeft()
 	s.mu.Lock()
+	s.budget = left
+	if s.turn == turnStatusThinking {
+		s.turnEnd = time.Now()
+	}
 	reason = strings.ToLower(reason)
 	switch {
 	case strings.Contains(reason, "interrupt"):
@@ -70,6 +126,19 @@ func (s *sessionStatus) finishTurn(reason string) {
 	s.mu.Unlock()
 }
 
+// turnElapsed is how long the last finished turn ran, or 0 while one runs.
+func (s *sessionStatus) turnElapsed() time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.turnStart.IsZero() || s.turnEnd.IsZero() {
+		return 0
+	}
+	return s.turnEnd.Sub(s.turnStart)
+}
+
 func (s *sessionStatus) advance() bool {
 	if s == nil {
 		return false
@@ -83,21 +152,42 @@ func (s *sessionStatus) advance() bool {
 	return true
 }
 
-// turnLabel is the current turn state as a short token ("thinking ⠧",
-// "completed ✓", …), "" when idle. Caller holds s.mu.
+// turnLabel is the current turn state as a short token ("thinking ⠧ 12s ·
+// ~340 tok", "completed ✓ 14s", …), "" when idle. Caller holds s.mu.
 func (s *sessionStatus) turnLabel() string {
+	var label string
 	switch s.turn {
 	case turnStatusThinking:
 		frames := livedoc.SpinnerFrames
-		return "thinking " + string(frames[int(s.tick)%len(frames)])
+		label = locale.T("status.thinking") + term.Sym(" "+string(frames[int(s.tick)%len(frames)]), "")
+		if !s.turnStart.IsZero() {
+			label += " " + formatElapsed(time.Since(s.turnStart))
+		}
+		if n := (s.streamBase + s.streamCur) / charsPerToken; n > 0 {
+			label += " · ~" + formatTokenCount(n) + " tok"
+		}
+		return label
 	case turnStatusCompleted:
-		return "completed ✓"
+		label = locale.T("status.completed") + term.Sym(" ✓", "")
 	case turnStatusInterrupted:
-		return "interrupted !"
+		label = locale.T("status.interrupted") + term.Sym(" !", "")
 	case turnStatusError:
-		return "error ✗"
+		label = locale.T("status.error") + term.Sym(" ✗", "")
+	default:
+		return ""
+	}
+	if !s.turnStart.IsZero() && !s.turnEnd.IsZero() {
+		label += " " + formatElapsed(s.turnEnd.Sub(s.turnStart))
 	}
-	return ""
+	return label
+}
+
+// formatElapsed renders a turn duration compactly: "8s", "2m05s".
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
 }
 
 // ruleLine is the upper of the two footer rows: a full-width rule with the
@@ -108,6 +198,9 @@ func (s *sessionStatus) ruleLine(width int, pos string) string {
 	if pos != "" {
 		label += " · " + pos
 	}
+	if term.Accessible() {
+		return clipToWidth(label, width)
+	}
 	right := " " + label + " ───"
 	fill := width - runewidth.StringWidth(right)
 	if fill < 3 {
@@ -117,10 +210,11 @@ func (s *sessionStatus) ruleLine(width int, pos string) string {
 }
 
 // statusLine is the lower footer row: plain left-aligned text —
-// "<mantra> · <turn state> · ctx … · cost … · <time>[ · ? help · ! status]".
-// hints adds the key hooks (live pager only; sealed scrollback omits them).
-// Narrow panes shed the mantra first, then cost, then ctx, then the time —
-// the turn state and the hints survive last.
+// "<mantra> · <turn state> · <model> · ctx … · cost … · budget … · <latency> · <time>
+// [ · ? help · ! status]". hints adds the key hooks (live pager only; sealed
+// scrollback omits them). Narrow panes shed the mantra first, then model,
+// cost, budget and latency, then ctx, then the time — the turn state and the hints
+// survive last.
 func (s *sessionStatus) statusLine(width int, hints bool) string {
 	if s == nil {
 		return ""
@@ -137,15 +231,27 @@ func (s *sessionStatus) statusLine(width int, hints bool) string {
 	if label := s.turnLabel(); label != "" {
 		tokens = append(tokens, tok{label, 4})
 	}
+	if s.metrics.Model != "" {
+		tokens = append(tokens, tok{s.metrics.Model, 1})
+	}
 	if context := formatContextUsage(s.metrics.ContextTokens, s.metrics.ContextLimit, s.metrics.ContextExact); context != "-" {
 		tokens = append(tokens, tok{"ctx " + context, 2})
 	}
 	if cost := formatSessionTokenCost(s.metrics.TokensIn, s.metrics.TokensOut); cost != "-" {
+		if usd, ok := sessionCost(s.metrics); ok {
+			cost += " " + formatUSD(usd)
+		}
 		tokens = append(tokens, tok{"cost " + cost, 1})
 	}
-	tokens = append(tokens, tok{s.startedAt.Format("15:04:05"), 3})
+	if s.budget != "" {
+		tokens = append(tokens, tok{s.budget, 1})
+	}
+	if s.latency > 0 {
+		tokens = append(tokens, tok{"ttft " + formatLatency(s.latency), 1})
+	}
+	tokens = append(tokens, tok{formatClock(s.startedAt), 3})
 	if hints {
-		tokens = append(tokens, tok{"? help", 5}, tok{"! status", 5})
+		tokens = append(tokens, tok{locale.T("status.hint.help"), 5}, tok{locale.T("status.hint.status"), 5})
 	}
 	s.mu.RUnlock()
 
@@ -188,17 +294,64 @@ func (s *sessionStatus) panelLines() []string {
 	if mantra := strings.Join(strings.Fields(s.metrics.Mantra), " "); mantra != "" {
 		rows = append(rows, "  mantra    "+mantra)
 	}
+	if s.metrics.Model != "" {
+		model := s.metrics.Model
+		if s.metrics.Provider != "" {
+			model = s.metrics.Provider + " · " + model
+		}
+		rows = append(rows, "  model     "+model)
+	}
 	if context := formatContextUsage(s.metrics.ContextTokens, s.metrics.ContextLimit, s.metrics.ContextExact); context != "-" {
 		rows = append(rows, "  context   "+context)
 	}
 	rows = append(rows,
 		fmt.Sprintf("  tokens    in %s · out %s", formatTokenCount(s.metrics.TokensIn), formatTokenCount(s.metrics.TokensOut)),
 		fmt.Sprintf("  cache     read %s · write %s", formatTokenCount(s.metrics.CacheReadTokens), formatTokenCount(s.metrics.CacheWriteTokens)),
-		"  started   "+s.startedAt.Format("15:04:05"),
 	)
+	if usd, ok := sessionCost(s.metrics); ok {
+		rows = append(rows, "  cost      "+formatUSD(usd))
+	}
+	if s.budget != "" {
+		rows = append(rows, "  budget    "+strings.TrimPrefix(s.budget, "budget "))
+	}
+	if s.latency > 0 {
+		rows = append(rows, "  latency   "+formatLatency(s.latency)+" to first text")
+	}
+	rows = append(rows, "  started   "+formatClock(s.startedAt))
 	return rows
 }
 
+// sessionCost prices the session's tokens at the [prices] rate for its
+// model; false when the model has no configured price.
+func sessionCost(m aria.Metrics) (float64, bool) {
+	return tokenCost(m.Model, m.TokensIn, m.TokensOut, m.CacheReadTokens, m.CacheWriteTokens)
+}
+
+// tokenCost prices token counts at model's [prices] rate.
+func tokenCost(model string, in, out, cacheRead, cacheWrite int) (float64, bool) {
+	p, ok := modelPrices[model]
+	if !ok || model == "" {
+		return 0, false
+	}
+	return (float64(in)*p.Input + float64(out)*p.Output +
+		float64(cacheRead)*p.CacheRead + float64(cacheWrite)*p.CacheWrite) / 1_000_000, true
+}
+
+func formatUSD(usd float64) string {
+	if usd < 1 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// formatLatency renders a first-text latency: "850ms", "2.4s".
+func formatLatency(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
 func formatContextUsage(tokens, limit int, exact bool) string {
 	if tokens <= 0 {
 		return "-"
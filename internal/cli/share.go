@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/share"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// defaultShareTTL is how long a link lives without --expires.
+const defaultShareTTL = 7 * 24 * time.Hour
+
+func shareRegistry() *share.Registry {
+	return share.Open(filepath.Join(stateDir(), "shares.json"))
+}
+
+// shareURL is the link printed for s.
+func shareURL(loaded *config.Loaded, s share.Share) string {
+	base := loaded.Config.Share.BaseURL
+	if base == "" {
+		base = "http://" + loaded.Config.Share.Listen
+	}
+	return strings.TrimRight(base, "/") + "/s/" + s.Token
+}
+
+// parseShareTTL reads --expires: empty is the default, 0 is no expiry.
+func parseShareTTL(v string) (time.Duration, error) {
+	if v == "" {
+		return defaultShareTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("--expires: want a duration like 24h, got %q", v)
+	}
+	return d, nil
+}
+
+// runShare publishes id (or the bound aria). ttl 0 lasts until revoked.
+func runShare(loaded *config.Loaded, id string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if id == "" {
+		r, err := resolveBinding(ctx, acli, os.Getppid())
+		if err != nil || !r.Found {
+			return fmt.Errorf("no aria bound to this shell (pass <id>)")
+		}
+		id = r.FigaroID
+	}
+	resp, err := acli.AriaRead(ctx, id, 0, 1)
+	if err != nil {
+		return fmt.Errorf("aria.read %s: %w", id, err)
+	}
+	if resp.Total == 0 {
+		return fmt.Errorf("aria %s is empty", id)
+	}
+	s, err := shareRegistry().Add(id, ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(shareURL(loaded, s))
+	if s.Expires.IsZero() {
+		fmt.Fprintln(os.Stderr, "live until revoked: figaro share revoke "+s.Token[:8])
+	} else {
+		fmt.Fprintf(os.Stderr, "expires %s\n", s.Expires.Local().Format("2006-01-02 15:04"))
+	}
+	if loaded.Config.Share.Listen == "" {
+		fmt.Fprintln(os.Stderr, "warning: [share] listen is not set; the daemon serves no share pages")
+	}
+	return nil
+}
+
+func runShareList(loaded *config.Loaded) error {
+	shares, err := shareRegistry().List()
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		fmt.Println("no live shares")
+		return nil
+	}
+	for _, s := range shares {
+		exp := "never"
+		if !s.Expires.IsZero() {
+			exp = s.Expires.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%s  %-10s  expires %-16s  %s\n", s.Token[:8], s.Aria, exp, shareURL(loaded, s))
+	}
+	return nil
+}
+
+func runShareRevoke(token string) error {
+	gone, err := shareRegistry().Revoke(token)
+	if errors.Is(err, share.ErrNoShare) {
+		return fmt.Errorf("no share matches %q", token)
+	}
+	if err != nil {
+		return err
+	}
+	for _, s := range gone {
+		fmt.Printf("revoked %s (aria %s)\n", s.Token[:8], s.Aria)
+	}
+	return nil
+}
+
+// shareLoader reads an aria straight from the daemon's backend.
+func shareLoader(backend store.Backend) share.Loader {
+	return func(id string) (string, []message.Message, error) {
+		meta, err := backend.Meta(id)
+		if err != nil {
+			return "", nil, err
+		}
+		log, err := backend.Open(id)
+		if err != nil {
+			return "", nil, err
+		}
+		entries := log.Read()
+		if meta == nil && len(entries) == 0 {
+			return "", nil, fmt.Errorf("aria %s not found", id)
+		}
+		msgs := make([]message.Message, 0, len(entries))
+		for _, e := range entries {
+			msgs = append(msgs, e.Payload)
+		}
+		var title string
+		if meta != nil {
+			title = meta.Mantra
+		}
+		return title, msgs, nil
+	}
+}
+
+// serveShares runs the share page server until ctx ends. It is off
+// unless [share] listen is set.
+func serveShares(ctx context.Context, loaded *config.Loaded, backend store.Backend) {
+	addr := loaded.Config.Share.Listen
+	if addr == "" {
+		return
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("config [share]: share pages not served", "listen", addr, "err", err)
+		return
+	}
+	srv := &http.Server{
+		Handler:           share.Handler(shareRegistry(), shareLoader(backend)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(sctx)
+	}()
+	slog.Info("share: serving", "listen", ln.Addr().String())
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("share: server stopped", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/share"
+)
+
+func TestParseShareTTL(t *testing.T) {
+	for in, want := range map[string]time.Duration{"": defaultShareTTL, "0": 0, "90m": 90 * time.Minute} {
+		if got, err := parseShareTTL(in); err != nil || got != want {
+			t.Errorf("parseShareTTL(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{"-1h", "week"} {
+		if _, err := parseShareTTL(bad); err == nil {
+			t.Errorf("parseShareTTL(%q) accepted", bad)
+		}
+	}
+}
+
+func TestShareURL(t *testing.T) {
+	loaded := &config.Loaded{}
+	loaded.Config.Share.Listen = "127.0.0.1:8750"
+	s := share.Share{Token: "tok"}
+	if got := shareURL(loaded, s); got != "http://127.0.0.1:8750/s/tok" {
+		t.Errorf("default = %q", got)
+	}
+	loaded.Config.Share.BaseURL = "https://figaro.example/"
+	if got := shareURL(loaded, s); got != "https://figaro.example/s/tok" {
+		t.Errorf("base url = %q", got)
+	}
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/livelog/aria"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// streamSink renders a prompt's notification stream for a non-interactive
+// output: raw text (plainSink), wire frames (verbatimSink) or JSON-lines
+// events (jsonlSink). The interactive ANSI renderer is mustPromptFigaro's
+// livelogTurn; it owns the terminal and is not a sink.
+type streamSink interface {
+	handle(method string, params json.RawMessage)
+	done() <-chan struct{}
+	failed() bool
+}
+
+// Output formats for --format.
+const (
+	formatANSI  = "ansi"
+	formatPlain = "plain"
+	formatJSON  = "json"
+)
+
+// parseFormat checks a --format value; "" is the default, ansi.
+func parseFormat(f string) (string, error) {
+	switch f {
+	case "", formatANSI:
+		return formatANSI, nil
+	case formatPlain, formatJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("--format %q: want ansi, plain or json", f)
+}
+
+// newStreamSink is the sink for a non-ANSI format.
+func newStreamSink(format string, out io.Writer) streamSink {
+	if format == formatJSON {
+		return newJSONLSink(out)
+	}
+	return newPlainSink(out)
+}
+
+// newStreamSinkFor is newStreamSink for a known aria: the json stream opens
+// with a start event naming it, so a consumer can follow up on the aria.
+func newStreamSinkFor(format string, out io.Writer, ariaID string) streamSink {
+	s := newStreamSink(format, out)
+	if j, ok := s.(*jsonlSink); ok {
+		j.enc.Encode(streamEvent{Type: "start", Aria: ariaID})
+	}
+	return s
+}
+
+// sinkDone is the turn-done latch the sinks share.
+type sinkDone struct {
+	doneCh   chan struct{}
+	sawError bool
+}
+
+func newSinkDone() sinkDone { return sinkDone{doneCh: make(chan struct{}, 1)} }
+
+func (d *sinkDone) done() <-chan struct{} { return d.doneCh }
+func (d *sinkDone) failed() bool          { return d.sawError }
+
+// finish records a turn.done; an "error:" reason fails the command.
+func (d *sinkDone) finish(e rpc.DoneEntry) {
+	if strings.HasPrefix(e.Reason, "error:") {
+		d.sawError = true
+	}
+	select {
+	case d.doneCh <- struct{}{}:
+	default:
+	}
+}
+
+// sinkPrompt sends prompt, feeds the stream to sink until turn.done, and
+// returns an exit code. Ctrl-C interrupts the turn and waits briefly for it
+// to wind down. force sends past a reached budget limit.
+func sinkPrompt(ctx context.Context, ep transport.Endpoint, prompt string, force bool, sink streamSink) int {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fcli, err := figaro.DialClient(ep, sink.handle)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: connect figaro:", err)
+		return 1
+	}
+	defer fcli.Close()
+	fcli.Author = promptAuthor
+
+	if _, err := fcli.QuaForce(ctx, prompt, buildPromptChalkboard(), force); err != nil {
+		msg, kind := rpcFailure(err)
+		printFailure(os.Stderr, "error: prompt: "+msg, kind)
+		return 1
+	}
+
+	select {
+	case <-sink.done():
+		if sink.failed() {
+			return 1
+		}
+		return 0
+	case <-fcli.Done():
+		fmt.Fprintln(os.Stderr, "error: agent disconnected before turn completed")
+		return 1
+	case <-ctx.Done():
+		intCtx, intCancel := context.WithTimeout(context.Background(), 3*time.Second)
+		_ = fcli.Interrupt(intCtx)
+		intCancel()
+		select {
+		case <-sink.done():
+		case <-fcli.Done():
+		case <-time.After(3 * time.Second):
+		}
+		return 130
+	}
+}
+
+// streamEvent is one line of --format json. start names the aria; text
+// and thinking arrive as deltas; a tool is reported each time its status
+// changes; guard reports a [guard] rule tripping; trimmed reports a
+// [limits] cap cutting an answer or cutting or summarizing a tool result;
+// message_end closes an assistant message; done ends the turn.
+type streamEvent struct {
+	Type   string                 `json:"type"` // start | text | thinking | tool | guard | trimmed | message_end | done
+	Aria   string                 `json:"aria,omitempty"`
+	LT     int                    `json:"lt,omitempty"`
+	Text   string                 `json:"text,omitempty"`
+	ID     string                 `json:"id,omitempty"`
+	Name   string                 `json:"name,omitempty"`
+	Args   map[string]interface{} `json:"args,omitempty"`
+	Status string                 `json:"status,omitempty"`
+	Output string                 `json:"output,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+	Error  bool                   `json:"error,omitempty"`
+	Kind   string                 `json:"kind,omitempty"` // an errs.Kind, on an error done
+
+	// guard events
+	Stage   string `json:"stage,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Blocked bool   `json:"blocked,omitempty"`
+
+	// trimmed events
+	Bytes     int    `json:"bytes,omitempty"`
+	KeptBytes int    `json:"kept_bytes,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+	Path      string `json:"path,omitempty"` // full output of a summarized result
+}
+
+// jsonlSink writes the assistant's side of the stream as JSON-lines events
+// for tooling: decoded and de-duplicated, unlike verbatim's wire frames.
+type jsonlSink struct {
+	enc    *json.Encoder
+	client *aria.Client
+	text   map[nodeKey]string // prose/thinking emitted so far, per node
+	tools  map[string]string  // tool id → last reported status
+	sinkDone
+}
+
+type nodeKey struct{ lt, index int }
+
+func newJSONLSink(out io.Writer) *jsonlSink {
+	s := &jsonlSink{
+		enc:      json.NewEncoder(out),
+		client:   aria.NewClient(),
+		text:     map[nodeKey]string{},
+		tools:    map[string]string{},
+		sinkDone: newSinkDone(),
+	}
+	s.client.OnLive = func(lt int, role, _ string, nodes []livedoc.Node) {
+		if role == "assistant" {
+			s.emitNodes(lt, nodes)
+		}
+	}
+	s.client.OnClosed = func(m aria.Message) {
+		if m.Role != "assistant" {
+			return
+		}
+		s.emitNodes(m.LT, m.Nodes)
+		s.enc.Encode(streamEvent{Type: "message_end", LT: m.LT})
+		for k := range s.text {
+			if k.lt == m.LT {
+				delete(s.text, k)
+			}
+		}
+	}
+	return s
+}
+
+func (s *jsonlSink) handle(method string, params json.RawMessage) {
+	switch method {
+	case rpc.MethodAriaFrame:
+		var r aria.AriaRead
+		if json.Unmarshal(params, &r) == nil {
+			s.client.Apply(r)
+		}
+	case rpc.MethodGuardViolation:
+		var v rpc.GuardViolation
+		if json.Unmarshal(params, &v) == nil {
+			s.enc.Encode(streamEvent{Type: "guard", Name: v.Tool, Stage: v.Stage, Rule: v.Rule, Text: v.Match, Blocked: v.Blocked})
+		}
+	case rpc.MethodContentTrimmed:
+		var t rpc.Trimmed
+		if json.Unmarshal(params, &t) == nil {
+			s.enc.Encode(streamEvent{Type: "trimmed", Name: t.Tool, Stage: t.Stage, Bytes: t.Bytes, KeptBytes: t.KeptBytes, MaxTokens: t.MaxTokens, Path: t.Path})
+		}
+	case rpc.MethodTurnDone:
+		var d rpc.DoneEntry
+		_ = json.Unmarshal(params, &d)
+		s.enc.Encode(streamEvent{Type: "done", Reason: d.Reason, Error: strings.HasPrefix(d.Reason, "error:"), Kind: d.Kind})
+		s.finish(d)
+	}
+}
+
+// emitNodes reports what changed in a message's nodes since the last call.
+// Like plainSink, text that is rewritten rather than extended is not resent.
+func (s *jsonlSink) emitNodes(lt int, nodes []livedoc.Node) {
+	for i, n := range nodes {
+		switch n.Type {
+		case livedoc.NodeProse, livedoc.NodeThinking:
+			k := nodeKey{lt, i}
+			prev := s.text[k]
+			s.text[k] = n.Markdown
+			if len(n.Markdown) > len(prev) && strings.HasPrefix(n.Markdown, prev) {
+				typ := "text"
+				if n.Type == livedoc.NodeThinking {
+					typ = "thinking"
+				}
+				s.enc.Encode(streamEvent{Type: typ, LT: lt, Text: n.Markdown[len(prev):]})
+			}
+		case livedoc.NodeTool:
+			if n.Status == "" || s.tools[n.ID] == n.Status {
+				continue
+			}
+			s.tools[n.ID] = n.Status
+			ev := streamEvent{Type: "tool", LT: lt, ID: n.ID, Name: n.Name, Args: n.Args, Status: n.Status}
+			if n.Status != livedoc.StatusRunning {
+				ev.Output = n.Output
+			}
+			s.enc.Encode(ev)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestJSONLSinkEmitsDeltasAndToolChanges(t *testing.T) {
+	var b strings.Builder
+	s := newJSONLSink(&b)
+	s.emitNodes(3, []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "Hel"}})
+	s.emitNodes(3, []livedoc.Node{
+		{Type: livedoc.NodeProse, Markdown: "Hello"},
+		{Type: livedoc.NodeTool, ID: "t1", Name: "bash", Status: livedoc.StatusRunning},
+	})
+	s.emitNodes(3, []livedoc.Node{
+		{Type: livedoc.NodeProse, Markdown: "Hello"},
+		{Type: livedoc.NodeTool, ID: "t1", Name: "bash", Status: livedoc.StatusOK, Output: "ok\n"},
+	})
+	params, _ := json.Marshal(rpc.DoneEntry{Reason: "end_turn"})
+	s.handle(rpc.MethodTurnDone, params)
+
+	var got []streamEvent
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var ev streamEvent
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			t.Fatalf("bad line %q: %v", l, err)
+		}
+		got = append(got, ev)
+	}
+	want := []streamEvent{
+		{Type: "text", LT: 3, Text: "Hel"},
+		{Type: "text", LT: 3, Text: "lo"},
+		{Type: "tool", LT: 3, ID: "t1", Name: "bash", Status: livedoc.StatusRunning},
+		{Type: "tool", LT: 3, ID: "t1", Name: "bash", Status: livedoc.StatusOK, Output: "ok\n"},
+		{Type: "done", Reason: "end_turn"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(got), len(want), b.String())
+	}
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Text != want[i].Text || got[i].Status != want[i].Status || got[i].Output != want[i].Output || got[i].Reason != want[i].Reason {
+			t.Errorf("event %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	select {
+	case <-s.done():
+	default:
+		t.Fatal("turn.done did not release the sink")
+	}
+	if s.failed() {
+		t.Fatal("clean turn reported as failed")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]string{"": formatANSI, "ansi": formatANSI, "plain": formatPlain, "json": formatJSON} {
+		if got, err := parseFormat(in); err != nil || got != want {
+			t.Errorf("parseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseFormat("html"); err == nil {
+		t.Error("parseFormat accepted html")
+	}
+}
+
+func TestStreamSinkForOpensJSONWithStart(t *testing.T) {
+	var b strings.Builder
+	newStreamSinkFor(formatJSON, &b, "ab12")
+	if got := strings.TrimSpace(b.String()); got != `{"type":"start","aria":"ab12"}` {
+		t.Errorf("json stream opens with %s", got)
+	}
+	b.Reset()
+	newStreamSinkFor(formatPlain, &b, "ab12")
+	if b.Len() != 0 {
+		t.Errorf("plain stream wrote %q before the turn", b.String())
+	}
+}
+
+func TestJSONLSinkGuardEvent(t *testing.T) {
+	var b strings.Builder
+	s := newJSONLSink(&b)
+	params, _ := json.Marshal(rpc.GuardViolation{Stage: "tool", Rule: "rm-root", Tool: "bash", Match: "rm -rf /", Blocked: true})
+	s.handle(rpc.MethodGuardViolation, params)
+	want := `{"type":"guard","text":"rm -rf /","name":"bash","stage":"tool","rule":"rm-root","blocked":true}`
+	if got := strings.TrimSpace(b.String()); got != want {
+		t.Errorf("guard event = %s\nwant %s", got, want)
+	}
+}
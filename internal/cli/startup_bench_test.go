@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	figOtel "github.com/jack-work/figaro/internal/otel"
+	"github.com/jack-work/figaro/internal/render"
+)
+
+// startupWait stands in for what a prompt blocks on before its first
+// reply frame: dialing the angelus and the aria, and the daemon's own
+// round-trips. The provider's time to first token comes on top.
+const startupWait = 2 * time.Millisecond
+
+// startupReply stands in for the first streamed reply.
+const startupReply = "Here is the fix:\n\n```go\nif err != nil {\n\treturn err\n}\n```\n\nThen rerun **go test**.\n"
+
+// TestStartupChild is BenchmarkStartup's cold process. It runs a prompt's
+// startup up to its first rendered row, sequentially or overlapped the way
+// Run and runPrompt do, and prints how long that took in nanoseconds.
+func TestStartupChild(t *testing.T) {
+	mode := os.Getenv("FIGARO_STARTUP_CHILD")
+	if mode == "" {
+		t.Skip("run by BenchmarkStartup")
+	}
+	ctx := context.Background()
+	start := time.Now()
+	var otelInit func() (func(context.Context) error, error)
+	if mode == "overlapped" {
+		otelInit = startOtel(ctx)
+	}
+	if _, err := config.Load(os.Getenv("FIGARO_STARTUP_CONFIG")); err != nil {
+		t.Fatal(err)
+	}
+	if otelInit == nil {
+		otelInit = func() (func(context.Context) error, error) { return figOtel.Init(ctx, stateDir()) }
+	}
+	shutdown, err := otelInit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+	if mode == "overlapped" {
+		render.Prewarm(80)
+	}
+	time.Sleep(startupWait)
+	render.Prose(startupReply, 80)
+	fmt.Printf("startup-ns %d\n", time.Since(start).Nanoseconds())
+}
+
+// BenchmarkStartup times a prompt's cold start up to its first rendered
+// row (config, telemetry, the wait for the daemon, the first markdown
+// render) in a fresh process per iteration, since the markdown stack warms
+// up once per process. The reported startup-ns/op excludes process spawn.
+func BenchmarkStartup(b *testing.B) {
+	cfgDir, stateDir := b.TempDir(), b.TempDir()
+	for _, mode := range []string{"sequential", "overlapped"} {
+		b.Run(mode, func(b *testing.B) {
+			var total time.Duration
+			for i := 0; i < b.N; i++ {
+				cmd := exec.Command(os.Args[0], "-test.run=^TestStartupChild$", "-test.count=1")
+				cmd.Env = append(os.Environ(),
+					"FIGARO_STARTUP_CHILD="+mode,
+					"FIGARO_STARTUP_CONFIG="+cfgDir,
+					"FIGARO_STATE_DIR="+stateDir)
+				out, err := cmd.Output()
+				if err != nil {
+					b.Fatalf("child: %v\n%s", err, out)
+				}
+				_, rest, ok := strings.Cut(string(out), "startup-ns ")
+				if !ok {
+					b.Fatalf("child printed no timing:\n%s", out)
+				}
+				ns, err := strconv.ParseInt(strings.Fields(rest)[0], 10, 64)
+				if err != nil {
+					b.Fatal(err)
+				}
+				total += time.Duration(ns)
+			}
+			b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "startup-ns/op")
+		})
+	}
+}
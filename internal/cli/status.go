@@ -133,6 +133,7 @@ func printStatusPanel(out *os.File, f *rpc.FigaroInfoResponse, more bool) {
 			loadout += " (" + f.LoadoutVer + ")"
 		}
 		row("loadout", loadout)
+		row("persona", dash(f.Persona))
 		if f.CreatedAt != 0 {
 			row("created", time.UnixMilli(f.CreatedAt).Format("2006-01-02 15:04:05"))
 		}
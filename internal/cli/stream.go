@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/figaro"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	ldmouse "github.com/jack-work/figaro/internal/livelog/render/mouse"
@@ -26,6 +27,16 @@ const spinnerFPS = 11 // spinner frames per second (~90ms/frame)
 // newest N committed messages — the pager's initial (lazy) window.
 const recentCursor = 1 << 60
 
+// catchUp re-reads after a version desync. A viewer holding no committed
+// message yet (sinceLT 0) takes only the recent window, not the whole
+// history; older messages page in on scroll-up as usual.
+func catchUp(ctx context.Context, fcli *figaro.Client, sinceLT int) (aria.AriaRead, error) {
+	if sinceLT == 0 {
+		return fcli.ReadTail(ctx, 0, transcriptPageSize)
+	}
+	return fcli.Read(ctx, sinceLT)
+}
+
 // Terminal control: disable auto-margin (so a full-width row never wraps) and
 // hide the cursor while the renderer owns the screen.
 const (
@@ -43,6 +54,7 @@ const (
 func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prompt string, loaded *config.Loaded, set renderSettings) {
 	ctx, span := figOtel.Start(ctx, "cli.prompt")
 	defer span.End()
+	defer waitNotify() // last out: a turn-done desktop post may be in flight
 
 	startedAt := time.Now()
 	listen := set.listen // Ctrl-L / --listen: stay open past turn-done
@@ -65,6 +77,9 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 	}
 
 	lt := newLivelogTurn(os.Stdout, width, height, &set, figaroID, startedAt, status, bookendFn, dimRule)
+	tee, closeTee := mustOpenAnswerTee(set.output)
+	defer closeTee()
+	lt.tee = tee
 	tc := term.NewClient() // platform terminal boundary: raw mode, resize, clipboard
 
 	// The renderer owns the cursor and assumes one row per line: disable the
@@ -96,16 +111,22 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 			if json.Unmarshal(params, &r) == nil {
 				lt.apply(r)
 			}
+		case rpc.MethodGuardViolation:
+			var v rpc.GuardViolation
+			if json.Unmarshal(params, &v) == nil {
+				fmt.Fprintln(os.Stderr, "\n"+guardNotice(v))
+			}
+		case rpc.MethodContentTrimmed:
+			var t rpc.Trimmed
+			if json.Unmarshal(params, &t) == nil {
+				fmt.Fprintln(os.Stderr, "\n"+trimNotice(t))
+			}
 		case rpc.MethodTurnDone:
 			var d rpc.DoneEntry
 			_ = json.Unmarshal(params, &d)
 			isErr := strings.HasPrefix(d.Reason, "error:")
 			if isErr {
-				if strings.Contains(d.Reason, "no credential") || strings.Contains(d.Reason, "resolve token") {
-					fmt.Fprint(os.Stderr, "\n"+providerSetupHint())
-				} else {
-					fmt.Fprintln(os.Stderr, "\n"+d.Reason)
-				}
+				printFailure(os.Stderr, "\n"+d.Reason, errs.Kind(d.Kind))
 			}
 			// Settle when the agent reports idle (inbox empty, no turn running):
 			// a turn that ended with our steer still queued reports idle=false,
@@ -124,6 +145,7 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 				break
 			}
 			running = false
+			turnNotify.turnDone(os.Stdout, figaroID, d.Reason, status.turnElapsed())
 			// Close on turn-done — in incipit OR transcript — UNLESS listening
 			// (Ctrl-L / --listen), which keeps the session open until Ctrl-D/C.
 			if !listen {
@@ -140,6 +162,23 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 		die("connect figaro: %s", err)
 	}
 	defer fcli.Close()
+	fcli.Author = promptAuthor
+
+	// --output holds this prompt's answer only. Mark the aria's head before
+	// anything replays history into the client (--listen's transcript, a
+	// cold catch-up), so older answers stay out of the file.
+	if tee != nil {
+		hctx, hcancel := context.WithTimeout(ctx, 5*time.Second)
+		head, herr := fcli.ReadTail(hctx, 0, 1)
+		hcancel()
+		if herr == nil {
+			mu.Lock()
+			for _, c := range head.Committed {
+				tee.skipThrough(c.LT)
+			}
+			mu.Unlock()
+		}
+	}
 
 	// On a version desync, re-read from the last fully-committed LT and re-apply
 	// the full snapshot (off the notify path so the pump isn't blocked).
@@ -147,7 +186,7 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 		go func() {
 			rctx, rcancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer rcancel()
-			r, rerr := fcli.Read(rctx, sinceLT)
+			r, rerr := catchUp(rctx, fcli, sinceLT)
 			if rerr != nil {
 				return
 			}
@@ -198,7 +237,14 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 			in := &interactiveInput{
 				tc: tc, lt: lt, fcli: fcli, mu: &mu, set: &set,
 				figaroID: figaroID, listen: &listen, cancel: cancel, disconnectCh: disconnectCh,
+				// A reply from the pager is a new prompt on this aria: keep the
+				// session open past its turn-done, as Ctrl-L would.
+				reply:       replySender(ctx, fcli, ep, &mu, lt, set.force, func() { listen, running = true, true }),
+				fork:        forkHere(figaroID),
+				pin:         pinHere(fcli),
+				alternative: attendAlternative(figaroID),
 			}
+			lt.setTranscriptReplyable(true)
 			if listen {
 				in.enterTranscript() // --listen: open the pager immediately
 			}
@@ -206,12 +252,13 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 		}
 	}
 
-	cursor, qerr := fcli.Qua(ctx, prompt, buildPromptChalkboard())
+	cursor, qerr := fcli.QuaForce(ctx, prompt, promptChalkboard(set), set.force)
 	if qerr != nil {
-		die("prompt: %s", qerr)
+		diePrompt(qerr)
 	}
 	mu.Lock()
 	sendCursor = cursor
+	tee.skipThrough(cursor)
 	lt.status.beginTurn()
 	mu.Unlock()
 
@@ -248,7 +295,7 @@ func mustPromptFigaro(ctx context.Context, ep transport.Endpoint, figaroID, prom
 
 // interactiveInput is the shared control-key + pager input loop for the live
 // TTY commands — send's mustPromptFigaro and listen's tailFigaro. It owns
-// Ctrl-C/D/L/T/O and 'y' (copy id), plus the pager's scroll + mouse, so both
+// Ctrl-C/D/L/T/O and 'y' (copy code or id), plus the pager's scroll + mouse, so both
 // commands behave identically in incipit and transcript.
 type interactiveInput struct {
 	tc           term.Client
@@ -270,6 +317,10 @@ type interactiveInput struct {
 	searchGen    uint64
 	searchQuery  string
 	searchDone   chan struct{}
+	reply        func(string)                        // sends a prompt typed in the pager's reply box; nil = read-only
+	fork         func(uint64) (string, error)        // the 'a' menu's fork-here; nil = unavailable
+	pin          func(uint64, string) (bool, error)  // the 'a' menu's pin toggle; nil = unavailable
+	alternative  func(int) (string, int, int, error) // '[' / ']': attend -/+ from this aria; nil = unavailable
 }
 
 type transcriptReadClient interface {
@@ -309,6 +360,7 @@ func (in *interactiveInput) enterTranscript() {
 		in.lt.apply(live)
 	}
 	in.mu.Unlock()
+	in.loadMarks()
 }
 
 func (in *interactiveInput) pageTranscript() {
@@ -606,14 +658,8 @@ func (in *interactiveInput) run() {
 						continue
 					}
 					if selected {
-						copyCtx, copyCancel := context.WithTimeout(context.Background(), 30*time.Second)
-						in.copyGen++
-						gen := in.copyGen
-						in.copyCancel = copyCancel
-						in.copyPlan = plan
-						in.copyFailed = false
+						in.startCopyLocked(plan)
 						in.mu.Unlock()
-						go in.copySelection(copyCtx, copyCancel, gen, plan)
 						continue
 					}
 					in.mu.Unlock()
@@ -649,25 +695,97 @@ func (in *interactiveInput) run() {
 				in.lt.render()
 				in.mu.Unlock()
 				continue
-			case 'y': // copy the aria id to the clipboard (OSC 52)
+			case 'y': // copy the selection's code blocks (a visual range: all of it), else the aria id (OSC 52)
 				if active && in.lt.transcriptSearching() {
 					break // typing into the search box — let it fall to the pager
 				}
+				if active {
+					in.mu.Lock()
+					plan, selected := in.lt.transcriptSelectionPlan()
+					if selected && in.copyCancel == nil {
+						plan.code = !in.lt.transcriptVisual()
+						in.startCopyLocked(plan)
+						in.mu.Unlock()
+						continue
+					}
+					in.mu.Unlock()
+					if selected {
+						continue // a copy is already in flight
+					}
+				}
 				in.tc.SetClipboard(in.figaroID)
 				continue
 			}
-			// Remaining keys drive the pager (scroll/search) when active.
+			// Remaining keys drive the pager (scroll/search/reply) when active.
 			if active {
 				in.mu.Lock()
 				in.cancelTranscriptSearchLocked()
 				in.lt.transcriptKey(b)
+				reply := in.lt.takeTranscriptReply()
+				action, plan := in.lt.takeTranscriptAction()
+				jump := in.lt.takeTranscriptJump()
+				pager := in.lt.takeTranscriptPager()
+				alt := in.lt.takeTranscriptAlternative()
+				mark, marked := in.lt.takeTranscriptMark()
 				in.mu.Unlock()
+				if reply != "" && in.reply != nil {
+					in.reply(reply)
+				}
+				if action != 0 {
+					in.runAction(action, plan)
+				}
+				if jump != 0 {
+					in.jumpTranscript(jump)
+				}
+				if pager {
+					in.openInPager()
+				}
+				if alt != 0 {
+					in.stepAlternative(alt)
+				}
+				if marked {
+					in.saveMark(mark)
+				}
 				in.pageTranscript()
 			}
 		}
 	}
 }
 
+// replySender returns the pager's reply hook: it expands @refs, then sends
+// the text as a prompt on fcli. The aria frames it produces stream back through
+// the caller's notify pump like any other turn. force sends past a reached
+// budget limit; onSend runs under mu.
+func replySender(ctx context.Context, fcli *figaro.Client, ep transport.Endpoint, mu *sync.Mutex, lt *livelogTurn, force bool, onSend func()) func(string) {
+	return func(text string) {
+		mu.Lock()
+		if onSend != nil {
+			onSend()
+		}
+		lt.status.beginTurn()
+		mu.Unlock()
+		go func() {
+			text = expandAtRefsForEndpoint(ctx, ep, text)
+			if _, err := fcli.QuaForce(ctx, text, buildPromptChalkboard(), force); err != nil {
+				msg, _ := rpcFailure(err)
+				mu.Lock()
+				lt.finishTurn("error: reply: " + msg)
+				mu.Unlock()
+			}
+		}()
+	}
+}
+
+// startCopyLocked begins an async copy of plan. Caller holds mu.
+func (in *interactiveInput) startCopyLocked(plan selectionCopyPlan) {
+	copyCtx, copyCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	in.copyGen++
+	in.copyCancel = copyCancel
+	in.copyPlan = plan
+	in.copyFailed = false
+	go in.copySelection(copyCtx, copyCancel, in.copyGen, plan)
+}
+
 func (in *interactiveInput) copySelection(ctx context.Context, cancel context.CancelFunc, gen uint64, plan selectionCopyPlan) {
 	text, err := selectionText(plan, transcriptPageSize, func(before, limit int) (aria.AriaRead, error) {
 		return in.fcli.ReadBefore(ctx, before, limit)
@@ -685,6 +803,9 @@ func (in *interactiveInput) copySelection(ctx context.Context, cancel context.Ca
 		in.lt.clearTranscriptSelection()
 	}
 	if err == nil {
+		if code := codeOnly(text); plan.code && code != "" {
+			text = code
+		}
 		in.copyFailed = false
 		in.tc.SetClipboard(text)
 	} else {
@@ -707,16 +828,16 @@ func (in *interactiveInput) cancelSelectionCopy() {
 
 func opensTranscriptFor(b byte) bool {
 	switch b {
-	case 'j', 'k', 'u', 'd', 'g', 'G', '/', '?', 0x0f, 0x0e, 0x10, 0x0d, 0x0a:
+	case 0x0f, 0x0e, 0x10, 0x0d, 0x0a:
 		return true
 	default:
-		return false
+		return pagerKeys.opens(b)
 	}
 }
 
 // dimRule returns a plain dim full-width horizontal rule — the opening rule and
 // the seal after a non-assistant (user/steering) message.
-func dimRule() string { return term.Dim(strings.Repeat("─", termWidth())) }
+func dimRule() string { return term.Dim(term.Rule(termWidth())) }
 
 // abandonRule returns a labeled dim rule used when a live region ends without
 // a normal seal (crash, disconnect, interrupt-timeout). Shape: "─── [reason] ───..."
@@ -729,6 +850,9 @@ func abandonRule(reason string) string {
 // "·" are multi-byte, and byte-length math is what made these rules render
 // shorter than the plain dimRule.
 func labeledRule(label string) string {
+	if term.Accessible() {
+		return label
+	}
 	prefix := "─── " + label + " "
 	fill := termWidth() - runewidth.StringWidth(prefix)
 	if fill < 3 {
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/outfit"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+	"github.com/jack-work/figaro/internal/toolout"
+)
+
+const summaryInstruction = "Summarize the tool output below for the agent that ran the tool; it " +
+	"will carry on from your summary alone. Keep every error, warning, failing test, file path, " +
+	"line number, count and value a next step could depend on. Drop repetition and boilerplate. " +
+	"Output ONLY the summary."
+
+// summaryInputBytes caps the output the summary model reads; its middle
+// goes first, as with [limits] tool_result_bytes.
+const summaryInputBytes = 400 << 10
+
+// summaryMaxTokens caps the digest.
+const summaryMaxTokens = 1024
+
+// summaryTimeout bounds one summary call; past it the result is trimmed.
+const summaryTimeout = 90 * time.Second
+
+// toolOutputDir holds tool results kept whole beside their summaries.
+func toolOutputDir() string { return filepath.Join(dataDir(), "tool-output") }
+
+// streamSpillDir holds answers streaming past [limits] stream_spill_bytes.
+func streamSpillDir() string { return filepath.Join(dataDir(), "stream-spill") }
+
+// buildSummarizer returns the [limits] summary_model hook, or nil when no
+// model is set.
+func buildSummarizer(loaded *config.Loaded) figaro.Summarizer {
+	lim := loaded.Config.Limits
+	if lim.SummaryModel == "" {
+		return nil
+	}
+	name := lim.SummaryProvider
+	if name == "" {
+		name = defaultLoadoutProvider(loaded)
+	}
+	dir := toolOutputDir()
+	return func(ctx context.Context, ariaID, tool, callID, text string) (string, string, error) {
+		path, err := keepToolOutput(dir, ariaID, callID, text)
+		if err != nil {
+			return "", "", err
+		}
+		prov, _ := buildProviderKnobs(loaded, name, providerPkg.Knobs{Model: lim.SummaryModel, MaxTokens: summaryMaxTokens})
+		if prov == nil {
+			return "", path, fmt.Errorf("summary provider %q unavailable", name)
+		}
+		ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+		defer cancel()
+		in, _ := toolout.Trim(text, summaryInputBytes)
+		prompt := summaryInstruction + "\n\nTool: " + tool + "\n\n" + in
+		summary, err := askProvider(ctx, providerPkg.Chain(prov, providerMiddleware...), lim.SummaryModel, prompt)
+		return summary, path, err
+	}
+}
+
+// keepToolOutput writes text to dir/<aria>/<call>.txt and returns the path.
+func keepToolOutput(dir, ariaID, callID, text string) (string, error) {
+	if ariaID == "" {
+		ariaID = "ephemeral"
+	}
+	if callID == "" {
+		callID = fmt.Sprintf("call-%d", time.Now().UnixNano())
+	}
+	d := filepath.Join(dir, filepath.Base(ariaID))
+	if err := os.MkdirAll(d, 0o700); err != nil {
+		return "", fmt.Errorf("keep tool output: %w", err)
+	}
+	path := filepath.Join(d, filepath.Base(callID)+".txt")
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return "", fmt.Errorf("keep tool output: %w", err)
+	}
+	return path, nil
+}
+
+// askProvider sends prompt as a one-message conversation and returns the
+// answer's text. Nothing is persisted.
+func askProvider(ctx context.Context, prov providerPkg.Provider, model, prompt string) (string, error) {
+	log := store.NewMemLog[message.Message]()
+	_, err := log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role:      message.RoleUser,
+		Content:   []message.Content{message.TextContent(prompt)},
+		Timestamp: time.Now().UnixMilli(),
+	}})
+	if err != nil {
+		return "", err
+	}
+	m, _ := json.Marshal(model)
+	bus := &answerBus{}
+	err = prov.Send(ctx, providerPkg.SendInput{
+		FigLog:    log,
+		Snapshot:  chalkboard.Snapshot{"system.model": m},
+		MaxTokens: summaryMaxTokens,
+	}, bus)
+	if err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, c := range bus.msg.Content {
+		if c.Type == message.ContentProse {
+			text.WriteString(c.Text)
+		}
+	}
+	answer := strings.TrimSpace(text.String())
+	if answer == "" {
+		return "", fmt.Errorf("empty summary from %s", prov.Name())
+	}
+	return answer, nil
+}
+
+// answerBus keeps the sealed message of a one-shot Send; the stream is
+// not shown anywhere.
+type answerBus struct{ msg message.Message }
+
+func (b *answerBus) PushDelta(message.Content) {}
+func (b *answerBus) PushFigaro(msg message.Message, _ ...providerPkg.AssistantCache) {
+	b.msg = msg
+}
+func (b *answerBus) PushToolInvokeStart(string, string) {}
+func (b *answerBus) PushToolInvokeDelta(string, string) {}
+func (b *answerBus) PushToolReady(message.Content)      {}
+func (b *answerBus) PushMessageEnd(string)              {}
+
+// defaultLoadoutProvider is the default loadout's system.provider, or "".
+func defaultLoadoutProvider(loaded *config.Loaded) string {
+	if loaded == nil || loaded.Config.DefaultLoadout == "" {
+		return ""
+	}
+	patch, err := outfit.New(loaded.ConfigDir).Load(loaded.Config.DefaultLoadout)
+	if err != nil {
+		return ""
+	}
+	var name string
+	_ = json.Unmarshal(patch.Set["system.provider"], &name)
+	return name
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/message"
+	providerPkg "github.com/jack-work/figaro/internal/provider"
+)
+
+// echoProvider answers with the model it was asked for and the prompt's
+// first line.
+type echoProvider struct{ failingProvider }
+
+func (echoProvider) Send(_ context.Context, in providerPkg.SendInput, bus providerPkg.Bus) error {
+	prompt := in.FigLog.Read()[0].Payload.Content[0].Text
+	first, _, _ := strings.Cut(prompt, "\n")
+	bus.PushFigaro(message.Message{
+		Role:    message.RoleAssistant,
+		Content: []message.Content{message.TextContent(string(in.Snapshot["system.model"]) + " " + first)},
+	})
+	return nil
+}
+
+func TestAskProvider(t *testing.T) {
+	got, err := askProvider(context.Background(), echoProvider{}, "cheap-1", "summarize this\nbody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `"cheap-1" summarize this` {
+		t.Fatalf("answer = %q", got)
+	}
+}
+
+func TestKeepToolOutput(t *testing.T) {
+	dir := t.TempDir()
+	path, err := keepToolOutput(dir, "aria-1", "../tc_1", "full output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(path, dir) {
+		t.Fatalf("path %s escaped %s", path, dir)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "full output" {
+		t.Fatalf("kept %q, %v", b, err)
+	}
+}
+
+func TestBuildSummarizerNeedsModel(t *testing.T) {
+	if buildSummarizer(&config.Loaded{}) != nil {
+		t.Fatal("no summary_model should mean no summarizer")
+	}
+	var loaded config.Loaded
+	loaded.Config.Limits.SummaryModel = "cheap-1"
+	if buildSummarizer(&loaded) == nil || loaded.SummarizeTokens() != 8000 {
+		t.Fatal("summary_model alone should summarize past 8000 tokens")
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/gitsync"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// syncTimeout bounds one git round trip.
+const syncTimeout = 2 * time.Minute
+
+// syncRepo is the aria store as a sync repository. The store's lock file
+// is per machine.
+func syncRepo() *gitsync.Repo {
+	return gitsync.Open(ariaDir(), ".lock")
+}
+
+func syncMessage() string {
+	host, _ := os.Hostname()
+	return "figaro: sync from " + host
+}
+
+func runSyncInit(remote string) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	r := syncRepo()
+	if err := r.Init(ctx, remote); err != nil {
+		return err
+	}
+	fmt.Printf("aria store at %s is a sync repository\n", r.Dir)
+	if remote == "" {
+		fmt.Println("add a remote with: figaro sync init <url>")
+	}
+	return nil
+}
+
+func runSyncPush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	if err := syncRepo().Push(ctx, syncMessage()); err != nil {
+		return err
+	}
+	fmt.Println("pushed")
+	return nil
+}
+
+// runSyncPull needs the daemon stopped: it caches what it has read of
+// each aria and would not see the merged segments.
+func runSyncPull(theirs bool) error {
+	if err := requireAngelusStopped(); err != nil {
+		return err
+	}
+	s := gitsync.Ours
+	if theirs {
+		s = gitsync.Theirs
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	conflicts, err := syncRepo().Pull(ctx, syncMessage(), s)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		fmt.Println("up to date")
+		return nil
+	}
+	side := "this machine's"
+	if theirs {
+		side = "the remote's"
+	}
+	fmt.Printf("%d file(s) diverged; kept %s copy:\n", len(conflicts), side)
+	for _, f := range conflicts {
+		fmt.Printf("  %s\n", f)
+	}
+	return nil
+}
+
+// syncNotifier is the daemon's per-agent subscriber that commits the
+// store after each finished turn, naming the turn's messages by hash.
+type syncNotifier struct {
+	ctx   context.Context
+	repo  *gitsync.Repo
+	agent *figaro.Agent
+
+	mu     sync.Mutex
+	lastLT uint64
+}
+
+// attachSync subscribes auto-commit to every agent when the store is a
+// sync repository and [sync] auto_commit is on. nil otherwise.
+func attachSync(ctx context.Context, loaded *config.Loaded) func(*figaro.Agent) {
+	repo := syncRepo()
+	if !loaded.SyncAutoCommit() || !repo.IsRepo() {
+		return nil
+	}
+	return func(a *figaro.Agent) {
+		n := &syncNotifier{ctx: ctx, repo: repo, agent: a, lastLT: a.Info().LastFigaroLT}
+		a.Subscribe(n)
+	}
+}
+
+func (n *syncNotifier) Notify(method string, params any) error {
+	if method != rpc.MethodTurnDone {
+		return nil
+	}
+	done, _ := params.(rpc.DoneEntry)
+	go n.commit(done.Reason)
+	return nil
+}
+
+func (n *syncNotifier) commit(reason string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	msgs := n.agent.ContextSince(n.lastLT)
+	msg := turnCommitMessage(n.agent.ID(), reason, msgs, n.lastLT)
+	if len(msgs) > 0 {
+		n.lastLT = msgs[len(msgs)-1].LogicalTime
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, syncTimeout)
+	defer cancel()
+	if _, err := n.repo.Commit(ctx, msg); err != nil {
+		slog.Warn("sync: commit failed", "aria", n.agent.ID(), "err", err)
+	}
+}
+
+// turnCommitMessage names the aria and turn outcome, then lists each
+// message after sinceLT as "lt role hash".
+func turnCommitMessage(ariaID, reason string, msgs []message.Message, sinceLT uint64) string {
+	reason, _, _ = strings.Cut(reason, "\n")
+	if len(reason) > 60 {
+		reason = reason[:60]
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "aria %s: %s\n", ariaID, reason)
+	sep := "\n"
+	for _, m := range msgs {
+		if m.LogicalTime <= sinceLT {
+			continue
+		}
+		b.WriteString(sep)
+		sep = ""
+		fmt.Fprintf(&b, "%d %s %s\n", m.LogicalTime, m.Role, messageHash(m))
+	}
+	return b.String()
+}
+
+// messageHash is the first 12 hex digits of the SHA-256 of m's JSON.
+func messageHash(m message.Message) string {
+	raw, _ := json.Marshal(m)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:6])
+}
+
+// onAgent chains the daemon's per-agent hooks, skipping nil ones.
+func onAgent(hooks ...func(*figaro.Agent)) func(*figaro.Agent) {
+	return func(a *figaro.Agent) {
+		for _, h := range hooks {
+			if h != nil {
+				h(a)
+			}
+		}
+	}
+}
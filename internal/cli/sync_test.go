@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func TestTurnCommitMessage(t *testing.T) {
+	msgs := []message.Message{
+		{LogicalTime: 3, Role: message.RoleUser, Content: []message.Content{message.TextContent("old")}},
+		{LogicalTime: 4, Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")}},
+		{LogicalTime: 5, Role: message.RoleAssistant, Content: []message.Content{message.TextContent("hello")}},
+	}
+	got := turnCommitMessage("ab12cd34", "stop", msgs, 3)
+	lines := strings.Split(got, "\n")
+	if lines[0] != "aria ab12cd34: stop" || lines[1] != "" || len(lines) != 5 {
+		t.Fatalf("message:\n%s", got)
+	}
+	for i, want := range []string{"4 user ", "5 assistant "} {
+		hash := strings.TrimPrefix(lines[2+i], want)
+		if hash == lines[2+i] || len(hash) != 12 {
+			t.Errorf("line %q", lines[2+i])
+		}
+	}
+	if messageHash(msgs[1]) == messageHash(msgs[2]) {
+		t.Error("distinct messages share a hash")
+	}
+
+	if got := turnCommitMessage("ab12cd34", "error: boom\ndetail", nil, 0); got != "aria ab12cd34: error: boom\n" {
+		t.Errorf("no messages: %q", got)
+	}
+}
@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// A task is a prompt handed to a fresh, unbound aria and left to run in the
+// daemon. The aria is the job: its inbox queues the work, its log holds the
+// result. The CLI only remembers which arias were submitted as tasks, one
+// small file per task under <state>/tasks, so list/status/attach can find
+// them again.
+//
+// The log is also the task's checkpoint: every tool round is sealed into it
+// as the loop runs. A daemon that dies mid-turn leaves the log ending on a
+// prompt or tool results, and resume queues a prompt that restarts the loop
+// from there.
+
+// taskRecord is one submitted task.
+type taskRecord struct {
+	ID          string `json:"id"`
+	Prompt      string `json:"prompt"`
+	SubmittedAt int64  `json:"submitted_at"` // unix millis
+}
+
+// taskPromptCap bounds the prompt kept in the record; list shows one line.
+const taskPromptCap = 200
+
+func taskDir() string { return filepath.Join(stateDir(), "tasks") }
+
+func saveTask(dir string, t taskRecord) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, t.ID+".json"), b, 0o600)
+}
+
+// loadTasks reads every task record, newest first. Unreadable records are
+// skipped.
+func loadTasks(dir string) ([]taskRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []taskRecord
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var t taskRecord
+		if json.Unmarshal(b, &t) == nil && t.ID != "" {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SubmittedAt > out[j].SubmittedAt })
+	return out, nil
+}
+
+// taskState maps an aria's list state onto a task's: an active aria is
+// still running; idle or dormant means it finished; missing means the aria
+// was killed.
+func taskState(ariaState string, found bool) string {
+	switch {
+	case !found:
+		return "gone"
+	case ariaState == "active":
+		return "running"
+	}
+	return "done"
+}
+
+// taskResumePrompt restarts an interrupted task's loop.
+const taskResumePrompt = "This task was interrupted before it finished. Continue from where it stopped; the work done so far is above."
+
+// taskInterrupted reports whether a page of an aria's log (ascending) ends
+// mid-loop: on a prompt or tool results with no answer after them, on an
+// answer that asked for tools, or on one that was cut off. Control turns
+// carry no content and are skipped.
+func taskInterrupted(entries []rpc.AriaReadEntry) bool {
+	for i := len(entries) - 1; i >= 0; i-- {
+		var m message.Message
+		if json.Unmarshal(entries[i].Payload, &m) != nil || len(m.Content) == 0 {
+			continue
+		}
+		if m.Role != message.RoleAssistant {
+			return true
+		}
+		for _, c := range m.Content {
+			if c.Type == message.ContentToolInvoke {
+				return true
+			}
+		}
+		switch m.StopReason {
+		case message.StopLength, message.StopError, message.StopAborted:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// readTaskInterrupted reads the tail of a task's log for taskInterrupted.
+func readTaskInterrupted(ctx context.Context, acli *angelus.Client, id string) (bool, error) {
+	resp, err := acli.AriaReadBefore(ctx, id, 0, ^uint64(0), retryScanPage)
+	if err != nil {
+		return false, fmt.Errorf("aria.read: %w", err)
+	}
+	return taskInterrupted(resp.Entries), nil
+}
+
+// findTask loads the record for id.
+func findTask(id string) (taskRecord, error) {
+	tasks, err := loadTasks(taskDir())
+	if err != nil {
+		return taskRecord{}, err
+	}
+	for _, t := range tasks {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return taskRecord{}, fmt.Errorf("no task %s (figaro task list)", id)
+}
+
+// taskSummary is the first line of a prompt, capped for the list view.
+func taskSummary(prompt string, width int) string {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(prompt), "\n", 2)[0])
+	if r := []rune(line); len(r) > width {
+		line = string(r[:width-1]) + "…"
+	}
+	return line
+}
+
+// runTask dispatches `figaro task <action>`.
+func runTask(loaded *config.Loaded, rawArgs []string) {
+	if len(rawArgs) == 0 {
+		die("usage: figaro task submit|list|status|attach|resume|forget ...")
+	}
+	action, args := rawArgs[0], rawArgs[1:]
+	switch action {
+	case "submit":
+		loadout, _, err := preDashFlagValue(args, "--loadout", "-L")
+		if err != nil {
+			die("task submit: %s", err)
+		}
+		prompt := extractPrompt(args)
+		if prompt == "" {
+			die("usage: figaro task submit [-L <loadout>] [--force] -- <prompt>")
+		}
+		runTaskSubmit(loaded, loadout, prompt, hasPreDashFlag(args, "--force"))
+	case "list", "ls":
+		runTaskList(loaded, hasPreDashFlag(args, "--json", "-j"))
+	case "status", "attach", "resume", "forget":
+		if len(args) != 1 {
+			die("usage: figaro task %s <id>", action)
+		}
+		if err := rpc.ValidateAriaID(args[0]); err != nil {
+			die("task %s: %s", action, err)
+		}
+		switch action {
+		case "status":
+			runTaskStatus(loaded, args[0])
+		case "attach":
+			runListen(loaded, args[0], 0)
+		case "resume":
+			runTaskResume(loaded, args[0], false)
+		case "forget":
+			if err := os.Remove(filepath.Join(taskDir(), args[0]+".json")); err != nil {
+				die("task forget: %s", err)
+			}
+		}
+	default:
+		die("task: unknown action %q (want submit, list, status, attach, resume or forget)", action)
+	}
+}
+
+// runTaskSubmit creates an unbound aria, queues the prompt on it and
+// returns without following the stream. The id goes to stdout for scripts.
+func runTaskSubmit(loaded *config.Loaded, loadout, prompt string, force bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	acli := mustConnectAngelus(loaded)
+	createResp, err := createWithFirstRun(ctx, loaded, func() (*rpc.CreateResponse, error) { return acli.Create(ctx, loadout, nil) })
+	acli.Close()
+	if err != nil {
+		die("create figaro: %s", err)
+	}
+	ep := transport.Endpoint{Scheme: createResp.Endpoint.Scheme, Address: createResp.Endpoint.Address}
+	if err := waitForSocket(ep.Address, 3*time.Second); err != nil {
+		die("task submit: %s", err)
+	}
+	prompt = expandAtRefsForEndpoint(ctx, ep, prompt)
+
+	fcli, err := figaro.DialClient(ep, func(string, json.RawMessage) {})
+	if err != nil {
+		die("connect figaro: %s", err)
+	}
+	defer fcli.Close()
+	fcli.Author = promptAuthor
+	if _, err := fcli.QuaForce(ctx, prompt, buildPromptChalkboard(), force); err != nil {
+		diePrompt(err)
+	}
+
+	rec := taskRecord{ID: createResp.FigaroID, Prompt: taskSummary(prompt, taskPromptCap), SubmittedAt: time.Now().UnixMilli()}
+	if err := saveTask(taskDir(), rec); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: task %s is running but was not recorded: %s\n", rec.ID, err)
+	}
+	fmt.Println(rec.ID)
+	fmt.Fprintf(os.Stderr, "submitted — figaro task status %s · figaro task attach %s\n", rec.ID, rec.ID)
+}
+
+// taskRow is a task joined with its aria's current state.
+type taskRow struct {
+	taskRecord
+	State string `json:"state"`
+}
+
+func taskRows(ctx context.Context, loaded *config.Loaded, tasks []taskRecord) []taskRow {
+	states := map[string]string{}
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if resp, err := acli.List(ctx); err == nil {
+		for _, f := range resp.Figaros {
+			states[f.ID] = f.State
+		}
+	}
+	rows := make([]taskRow, len(tasks))
+	for i, t := range tasks {
+		st, ok := states[t.ID]
+		rows[i] = taskRow{taskRecord: t, State: taskState(st, ok)}
+	}
+	return rows
+}
+
+func runTaskList(loaded *config.Loaded, asJSON bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	tasks, err := loadTasks(taskDir())
+	if err != nil {
+		die("task list: %s", err)
+	}
+	rows := taskRows(ctx, loaded, tasks)
+	if asJSON {
+		if rows == nil {
+			rows = []taskRow{}
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(rows)
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(os.Stderr, "no tasks (figaro task submit -- <prompt>)")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATE\tSUBMITTED\tPROMPT")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s ago\t%s\n", r.ID, r.State, relAge(r.SubmittedAt), taskSummary(r.Prompt, 60))
+	}
+	w.Flush()
+}
+
+// runTaskStatus prints a task's state and, once it has one, its answer.
+func runTaskStatus(loaded *config.Loaded, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rec, err := findTask(id)
+	if err != nil {
+		die("task status: %s", err)
+	}
+	row := taskRows(ctx, loaded, []taskRecord{rec})[0]
+	if row.State == "gone" {
+		fmt.Fprintf(os.Stderr, "%s · %s · submitted %s ago\n", row.ID, row.State, relAge(row.SubmittedAt))
+		return
+	}
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	if row.State == "done" {
+		if cut, err := readTaskInterrupted(ctx, acli, id); err == nil && cut {
+			row.State = "interrupted"
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s · %s · submitted %s ago\n", row.ID, row.State, relAge(row.SubmittedAt))
+	if row.State == "interrupted" {
+		fmt.Fprintf(os.Stderr, "stopped mid-run — figaro resume %s\n", id)
+	}
+	_, text, ok, err := findLastProse(ctx, acli, id, message.RoleAssistant)
+	if err != nil {
+		die("task status: %s", err)
+	}
+	if ok {
+		fmt.Println(text)
+	}
+}
+
+// runTaskResume restarts a task whose run stopped partway (daemon crash,
+// interrupt, provider error). Its finished rounds stay in the log; the
+// resume prompt asks the model to carry on from them.
+func runTaskResume(loaded *config.Loaded, id string, force bool) {
+	if err := rpc.ValidateAriaID(id); err != nil {
+		die("resume: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rec, err := findTask(id)
+	if err != nil {
+		die("resume: %s", err)
+	}
+	switch taskRows(ctx, loaded, []taskRecord{rec})[0].State {
+	case "gone":
+		die("resume: task %s's aria was killed; nothing to resume", id)
+	case "running":
+		die("resume: task %s is still running (figaro task attach %s)", id, id)
+	}
+
+	acli := mustConnectAngelus(loaded)
+	defer acli.Close()
+	cut, err := readTaskInterrupted(ctx, acli, id)
+	if err != nil {
+		die("resume: %s", err)
+	}
+	if !cut {
+		fmt.Fprintf(os.Stderr, "task %s finished; nothing to resume (figaro task status %s)\n", id, id)
+		return
+	}
+	ep, err := resolveAria(ctx, acli, id)
+	if err != nil {
+		die("resume: %s", err)
+	}
+	fcli, err := figaro.DialClient(ep, func(string, json.RawMessage) {})
+	if err != nil {
+		die("connect figaro: %s", err)
+	}
+	defer fcli.Close()
+	fcli.Author = promptAuthor
+	if _, err := fcli.QuaForce(ctx, taskResumePrompt, buildPromptChalkboard(), force); err != nil {
+		diePrompt(err)
+	}
+	fmt.Fprintf(os.Stderr, "resumed — figaro task status %s · figaro task attach %s\n", id, id)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+func TestTaskRecordsRoundTripNewestFirst(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tasks")
+	for _, r := range []taskRecord{
+		{ID: "a1", Prompt: "first", SubmittedAt: 100},
+		{ID: "b2", Prompt: "second", SubmittedAt: 300},
+		{ID: "c3", Prompt: "third", SubmittedAt: 200},
+	} {
+		if err := saveTask(dir, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "junk.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadTasks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].ID != "b2" || got[1].ID != "c3" || got[2].ID != "a1" {
+		t.Fatalf("loadTasks = %+v, want b2, c3, a1", got)
+	}
+	if none, err := loadTasks(filepath.Join(t.TempDir(), "missing")); err != nil || none != nil {
+		t.Fatalf("missing dir: %v, %v", none, err)
+	}
+}
+
+func TestTaskStateAndSummary(t *testing.T) {
+	for _, c := range []struct {
+		state string
+		found bool
+		want  string
+	}{
+		{"active", true, "running"},
+		{"idle", true, "done"},
+		{"dormant", true, "done"},
+		{"", false, "gone"},
+	} {
+		if got := taskState(c.state, c.found); got != c.want {
+			t.Errorf("taskState(%q, %v) = %q, want %q", c.state, c.found, got, c.want)
+		}
+	}
+	if got := taskSummary("  research the thing\nwith details", 80); got != "research the thing" {
+		t.Errorf("summary keeps only the first line: %q", got)
+	}
+	if got := taskSummary("abcdefghij", 5); got != "abcd…" {
+		t.Errorf("summary cap: %q", got)
+	}
+}
+
+func TestTaskInterrupted(t *testing.T) {
+	entry := func(m message.Message) rpc.AriaReadEntry {
+		b, _ := json.Marshal(m)
+		return rpc.AriaReadEntry{Payload: b}
+	}
+	prompt := entry(message.Message{Role: message.RoleUser, Content: []message.Content{message.TextContent("go")}})
+	calls := entry(message.Message{Role: message.RoleAssistant, StopReason: message.StopToolInvoke,
+		Content: []message.Content{{Type: message.ContentToolInvoke, ToolCallID: "c1", ToolName: "bash"}}})
+	results := entry(message.Message{Role: message.RoleUser,
+		Content: []message.Content{message.ToolResultContent("c1", "bash", "ok", false)}})
+	answer := entry(message.Message{Role: message.RoleAssistant, StopReason: message.StopEnd, Content: []message.Content{message.TextContent("done")}})
+	cutOff := entry(message.Message{Role: message.RoleAssistant, StopReason: message.StopLength, Content: []message.Content{message.TextContent("half")}})
+	control := entry(message.Message{Role: message.RoleUser})
+
+	for _, c := range []struct {
+		name string
+		log  []rpc.AriaReadEntry
+		want bool
+	}{
+		{"empty", nil, false},
+		{"answered", []rpc.AriaReadEntry{prompt, calls, results, answer}, false},
+		{"answered then control", []rpc.AriaReadEntry{prompt, answer, control}, false},
+		{"no answer", []rpc.AriaReadEntry{prompt}, true},
+		{"mid tool call", []rpc.AriaReadEntry{prompt, calls}, true},
+		{"after tool results", []rpc.AriaReadEntry{prompt, calls, results}, true},
+		{"cut off", []rpc.AriaReadEntry{prompt, cutOff}, true},
+	} {
+		if got := taskInterrupted(c.log); got != c.want {
+			t.Errorf("%s: taskInterrupted = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
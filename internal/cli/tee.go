@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/livelog/aria"
+)
+
+// answerTee is --output: it copies each committed assistant message's prose,
+// as the raw markdown the model wrote, to a file while the terminal renders
+// it. Thinking and tool output are left out; the file reads as the answer.
+type answerTee struct {
+	w      io.Writer
+	lastLT int
+	wrote  bool
+	err    error
+}
+
+// openAnswerTee creates (or truncates) path for the answer.
+func openAnswerTee(path string) (*answerTee, *os.File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &answerTee{w: f}, f, nil
+}
+
+// add writes m's prose if it is an assistant message not seen before. A
+// desync re-read replays committed messages; the LT guard drops the repeats.
+func (a *answerTee) add(m aria.Message) {
+	if a == nil || a.err != nil || m.Role != "assistant" || m.LT <= a.lastLT {
+		return
+	}
+	a.lastLT = m.LT
+	text := answerProse(m.Nodes)
+	if text == "" {
+		return
+	}
+	if a.wrote {
+		text = "\n\n" + text
+	}
+	_, a.err = io.WriteString(a.w, text)
+	a.wrote = true
+}
+
+// skipThrough drops assistant messages at or before lt: the history a
+// transcript or catch-up replays, committed before this prompt was sent.
+func (a *answerTee) skipThrough(lt int) {
+	if a != nil && lt > a.lastLT {
+		a.lastLT = lt
+	}
+}
+
+// finish ends the file with a newline and reports the first write error.
+func (a *answerTee) finish() error {
+	if a.wrote && a.err == nil {
+		_, a.err = io.WriteString(a.w, "\n")
+	}
+	return a.err
+}
+
+// answerProse joins a message's prose nodes, verbatim.
+func answerProse(nodes []livedoc.Node) string {
+	var parts []string
+	for _, n := range nodes {
+		if n.Type == livedoc.NodeProse && strings.TrimSpace(n.Markdown) != "" {
+			parts = append(parts, strings.TrimRight(n.Markdown, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// mustOpenAnswerTee opens --output for a live prompt, or returns nils when
+// none was asked for. The returned func closes the file, warning on a failed
+// write; the turn itself is never failed over the copy.
+func mustOpenAnswerTee(path string) (*answerTee, func()) {
+	if path == "" {
+		return nil, func() {}
+	}
+	tee, f, err := openAnswerTee(path)
+	if err != nil {
+		die("--output: %s", err)
+	}
+	return tee, func() {
+		err := tee.finish()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: --output %s: %s\n", path, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/livelog/aria"
+)
+
+func TestAnswerTeeWritesRawAssistantProse(t *testing.T) {
+	var b strings.Builder
+	tee := &answerTee{w: &b}
+	tee.add(aria.Message{LT: 1, Role: "user", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "question"}}})
+	tee.add(aria.Message{LT: 2, Role: "assistant", Nodes: []livedoc.Node{
+		{Type: livedoc.NodeThinking, Markdown: "hmm"},
+		{Type: livedoc.NodeProse, Markdown: "Let me look.\n"},
+		{Type: livedoc.NodeTool, Name: "bash", Output: "ls output"},
+	}})
+	tee.add(aria.Message{LT: 4, Role: "assistant", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "## Answer\n\n```go\nx := 1\n```"}}})
+	// A desync re-read replays an already-committed message.
+	tee.add(aria.Message{LT: 2, Role: "assistant", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "Let me look."}}})
+	if err := tee.finish(); err != nil {
+		t.Fatal(err)
+	}
+	want := "Let me look.\n\n## Answer\n\n```go\nx := 1\n```\n"
+	if b.String() != want {
+		t.Fatalf("tee wrote %q, want %q", b.String(), want)
+	}
+}
+
+func TestAnswerTeeSkipsHistory(t *testing.T) {
+	var b strings.Builder
+	tee := &answerTee{w: &b}
+	tee.skipThrough(5)
+	tee.add(aria.Message{LT: 4, Role: "assistant", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "an old answer"}}})
+	tee.skipThrough(3) // a stale mark never lowers the floor
+	tee.add(aria.Message{LT: 5, Role: "assistant", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "another"}}})
+	tee.add(aria.Message{LT: 7, Role: "assistant", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "this answer"}}})
+	if err := tee.finish(); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "this answer\n" {
+		t.Fatalf("tee wrote %q", b.String())
+	}
+}
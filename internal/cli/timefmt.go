@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clockLayout is the Go time layout for wall-clock times in the live views
+// (config time_format). relativeTime swaps them for ages ("3m ago"); it is
+// seeded from config relative_time and flipped by 't' in the pager, so it is
+// read from render paths on other goroutines.
+var (
+	clockLayout  = "15:04:05"
+	relativeTime atomic.Bool
+)
+
+func setTimeStyle(layout string, relative bool) {
+	if layout != "" {
+		clockLayout = layout
+	}
+	relativeTime.Store(relative)
+}
+
+// formatClock renders t in local time with the configured layout, or as an
+// age when relative time is on.
+func formatClock(t time.Time) string {
+	if relativeTime.Load() {
+		return relAge(t.UnixMilli()) + " ago"
+	}
+	return t.Local().Format(clockLayout)
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/livelog/aria"
+	ldrender "github.com/jack-work/figaro/internal/livelog/render"
+)
+
+func TestFormatClockStyles(t *testing.T) {
+	defer setTimeStyle("15:04:05", false)
+	at := time.Now().Add(-3 * time.Minute)
+
+	setTimeStyle("Jan 2 15:04", false)
+	if got, want := formatClock(at), at.Local().Format("Jan 2 15:04"); got != want {
+		t.Fatalf("formatClock = %q, want %q", got, want)
+	}
+	setTimeStyle("", true)
+	if got := formatClock(at); got != "3m ago" {
+		t.Fatalf("relative formatClock = %q, want 3m ago", got)
+	}
+}
+
+func TestTranscriptTogglesRelativeTime(t *testing.T) {
+	defer setTimeStyle("15:04:05", false)
+	ft := ldrender.NewFakeTerminal(100, 12)
+	tr := newTranscript(ft, 100, 12, ldrender.NodeText{}, aria.NewClient(), "aria1234", time.Now().Add(-2*time.Hour))
+	tr.enter()
+	tr.key('t')
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "2h ago") {
+		t.Fatalf("status line not relative after t:\n%s", scr)
+	}
+	tr.key('t')
+	if relativeTime.Load() {
+		t.Fatal("a second t must switch back to clock times")
+	}
+}
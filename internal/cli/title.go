@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// titleExcerpt bounds each side of the exchange quoted to the titler.
+const titleExcerpt = 2000
+
+// titleLookupTimeout bounds reading the first answer and the current
+// mantra, before the titler is asked.
+const titleLookupTimeout = 5 * time.Second
+
+// titleTimeout bounds the titler on its own: creating its ephemeral aria,
+// the model call and writing the title back. The pass runs after the answer has printed, so a
+// slow titler must not hold the shell for long; on timeout the
+// opener-seeded mantra stays.
+const titleTimeout = 10 * time.Second
+
+// maybeAutoTitle replaces a new conversation's opener-seeded mantra with a
+// model-written 5–8 word title once its first exchange has landed. Gated on
+// config auto_title; any failure is a warning, never a failed prompt.
+func maybeAutoTitle(loaded *config.Loaded, ep transport.Endpoint, figaroID, prompt string) {
+	if !loaded.AutoTitle() {
+		return
+	}
+	acli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto-title: %s\n", err)
+		return
+	}
+	defer acli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), titleLookupTimeout)
+	defer cancel()
+	_, answer, ok, err := findLastProse(ctx, acli, figaroID, message.RoleAssistant)
+	if err != nil || !ok {
+		return // interrupted or detached before an answer: keep the seed
+	}
+	fcli, err := figaro.DialClient(ep, func(string, json.RawMessage) {})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto-title: %s\n", err)
+		return
+	}
+	defer fcli.Close()
+	if cb, err := fcli.Chalkboard(ctx); err != nil || !seededMantra(cb.Snapshot["mantra"], prompt) {
+		return // the agent already named it
+	}
+	askCtx, askCancel := context.WithTimeout(context.Background(), titleTimeout)
+	defer askCancel()
+	reply, err := askEphemeral(askCtx, loaded, acli, titlePrompt(prompt, answer))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto-title: %s\n", err)
+		return
+	}
+	title := cleanTitle(reply)
+	if title == "" {
+		return
+	}
+	v, _ := json.Marshal(title)
+	if _, err := fcli.Set(askCtx, rpc.ChalkboardPatch{Set: map[string]json.RawMessage{"mantra": v}}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto-title: %s\n", err)
+	}
+}
+
+// seededMantra reports whether raw is still the mantra the agent seeds from
+// the opener (its first characters, newlines folded, ellipsized when cut).
+func seededMantra(raw json.RawMessage, prompt string) bool {
+	if len(raw) == 0 {
+		return true
+	}
+	var m string
+	if json.Unmarshal(raw, &m) != nil {
+		return false
+	}
+	opener := strings.TrimSpace(strings.ReplaceAll(prompt, "\n", " "))
+	return strings.HasPrefix(opener, strings.TrimSpace(strings.TrimSuffix(m, "…")))
+}
+
+func titlePrompt(prompt, answer string) string {
+	return "Give this conversation a title of 5 to 8 words. Output ONLY the title: " +
+		"no quotes, no trailing period, no preamble.\n\nUser:\n" + firstRunes(prompt, titleExcerpt) +
+		"\n\nAssistant:\n" + firstRunes(answer, titleExcerpt)
+}
+
+// cleanTitle keeps the first line of a titler reply, unquoted, without a
+// trailing period, capped at eight words.
+func cleanTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "Title:")
+	s = strings.Trim(strings.TrimSpace(s), "\"'`*“”")
+	s = strings.TrimRight(s, ".")
+	words := strings.Fields(s)
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	return strings.Join(words, " ")
+}
+
+func firstRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCleanTitle(t *testing.T) {
+	cases := map[string]string{
+		"Debugging a flaky unix socket test\n":             "Debugging a flaky unix socket test",
+		"\"Porting the pager to Windows.\"":                "Porting the pager to Windows",
+		"Title: Chasing cache misses":                      "Chasing cache misses",
+		"one two three four five six seven eight nine ten": "one two three four five six seven eight",
+		"  ": "",
+	}
+	for in, want := range cases {
+		if got := cleanTitle(in); got != want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSeededMantra(t *testing.T) {
+	raw := func(s string) json.RawMessage { b, _ := json.Marshal(s); return b }
+	if !seededMantra(raw("why does the pager"), "why does the pager\nflicker?") {
+		t.Error("the opener seed must count as seeded")
+	}
+	if !seededMantra(raw("a long opener…"), "a long opener that was cut") {
+		t.Error("an ellipsized seed must count as seeded")
+	}
+	if seededMantra(raw("taming the flaky pager"), "why does the pager flicker?") {
+		t.Error("an agent-written mantra must not be overwritten")
+	}
+	if !seededMantra(nil, "anything") {
+		t.Error("no mantra at all is fair game")
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"io"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	ldrender "github.com/jack-work/figaro/internal/livelog/render"
 	ldmouse "github.com/jack-work/figaro/internal/livelog/render/mouse"
+	"github.com/jack-work/figaro/internal/locale"
+	"github.com/jack-work/figaro/internal/term"
 )
 
 const (
@@ -25,8 +28,9 @@ const (
 // without retaining or re-rendering the whole aria. At the bottom it follows
 // the shared client's live tail; otherwise it holds the current page window.
 //
-// Keys: j/k line, u/d half-page, gg/G top/bottom, / literal search, ? help
-// panel. Exit is Ctrl-D/Ctrl-C at the input loop. Not safe for concurrent use;
+// Keys: j/k line, u/d half-page, gg/G top/bottom, / literal search (n/N step
+// through hits), r reply, a message actions on the selection, m bookmark,
+// t clock vs relative times, ? help panel. Exit is Ctrl-D/Ctrl-C at the input loop. Not safe for concurrent use;
 // the caller serializes all entry points.
 type transcript struct {
 	out    io.Writer
@@ -40,14 +44,58 @@ type transcript struct {
 	w, h       int
 	tick       int
 
-	prev   []string // last painted screen (full-frame diff)
-	lineLT []int    // LT owning each line of lines(), for resize anchoring
-	offset int      // top line of the viewport into lines()
-	follow bool     // stick to the bottom on new content
-	pendG  bool     // saw one 'g' (for gg)
+	prev    []string // last painted screen (full-frame diff)
+	lineLT  []int    // LT owning each line of lines(), for resize anchoring
+	offset  int      // top line of the viewport into lines()
+	follow  bool     // stick to the bottom on new content
+	pendKey byte     // first press of a doubled binding (gg)
 
 	inSearch bool
 	query    string
+	hits     string // last submitted query: highlighted, and what n/N step through
+
+	// Reply box ('r'): the status row becomes a one-line prompt; Enter parks
+	// the text in replyOut for the input loop to send on the aria's client.
+	replyable bool
+	inReply   bool
+	reply     string
+	replyOut  string
+
+	// Message actions ('a' with a node selection): the footer becomes an
+	// action menu; the pick is parked in actionOut for the input loop, whose
+	// result comes back as a one-shot notice on the status row.
+	inActions bool
+	actionOut byte
+	notice    string
+
+	// Jump prompt (':'): digits name a message by LT. One outside the loaded
+	// window is parked in jumpOut for the input loop to page in.
+	inJump  bool
+	jump    string
+	jumpOut int
+
+	// Bookmarks ('m'): marks holds the notes on this aria's messages by LT
+	// ("" for a plain bookmark). The prompt edits markLT's note; a saved
+	// edit is parked in markOut for the input loop to write.
+	marks   map[int]string
+	inMark  bool
+	markLT  int
+	mark    string
+	markOut *markEdit
+
+	// '|' asks the input loop to hand the whole rendered history to $PAGER;
+	// suspended stops repaints while that child owns the terminal.
+	pagerOut  bool
+	suspended bool
+
+	// '[' / ']' ask the input loop to rebind the shell to the previous or
+	// next alternative at the aria's fork point (attend -/+).
+	altOut int
+
+	// Visual mode ('v'): the selection snaps to whole messages, anchored on
+	// visualLT, and j/k move its far end.
+	visual   bool
+	visualLT int
 
 	// Lazy history paging: the pager opens on the recent window and pulls older
 	// messages via keyset ReadBefore only when you scroll near the top ("like
@@ -127,7 +175,10 @@ func newTranscript(out io.Writer, w, h int, view ldrender.NodeView, client *aria
 // row — which reads as the status line "eating" the line above it.
 func (t *transcript) enter() {
 	t.active, t.follow, t.prev = true, true, nil
-	t.pendG, t.inSearch, t.query = false, false, ""
+	t.pendKey, t.inSearch, t.query = 0, false, ""
+	t.inActions, t.notice = false, ""
+	t.inJump, t.jump = false, ""
+	t.inMark, t.mark = false, ""
 	t.resetToTail()
 	io.WriteString(t.out, altScreenOn+autowrapOff+ldmouse.Enable+cursorHide+"\x1b[2J")
 	t.render()
@@ -276,7 +327,7 @@ func committedMessages(in []aria.Committed) []aria.Message {
 	messages := make([]aria.Message, 0, len(in))
 	for _, m := range in {
 		if m.Full() {
-			messages = append(messages, aria.Message{LT: m.LT, Role: m.Role, Nodes: m.Nodes})
+			messages = append(messages, aria.Message{LT: m.LT, Role: m.Role, Author: m.Author, Nodes: m.Nodes})
 		}
 	}
 	return messages
@@ -506,8 +557,9 @@ func (t *transcript) invalidateRows() {
 }
 
 // lines renders the retained message window and live tail to physical rows.
-// Committed messages are immutable, so their rendered rows are cached by LT;
-// only the open message renders every frame.
+// Committed messages are immutable, so their rendered rows — and their
+// unselected gutter decoration — are cached by LT; only the open message and
+// the selected nodes are redone every frame.
 func (t *transcript) lines() []string {
 	if t.follow {
 		t.resetToTail()
@@ -520,15 +572,20 @@ func (t *transcript) lines() []string {
 	var out []string
 	var lts []int // LT owning each line (0 for separator rules), parallel to out
 	t.nodeRows = map[nodeRef]nodeSpan{}
-	appendMsg := func(rows []transcriptRow, lt int) {
+	rule := dimTransRule(t.w)
+	appendMsg := func(rows []transcriptRow, plain []string, lt int) {
 		if len(out) > 0 { // rule separator BETWEEN messages only — the footer
-			out = append(out, "", dimTransRule(t.w), "") // seals the last one, so a
-			lts = append(lts, lt, lt, lt)                // trailing rule+blank would
+			out = append(out, "", rule, "") // seals the last one, so a
+			lts = append(lts, lt, lt, lt)   // trailing rule+blank would
 		} // double up against it
-		for _, r := range rows {
+		for k, r := range rows {
 			line := r.text
 			if r.ref.valid() {
-				line = decorateNodeRow(line, marks[r.ref], t.w)
+				if mark, ok := marks[r.ref]; ok || plain == nil {
+					line = decorateNodeRow(line, mark, t.w)
+				} else {
+					line = plain[k]
+				}
 				span, ok := t.nodeRows[r.ref]
 				if !ok {
 					span.first = len(out)
@@ -544,12 +601,20 @@ func (t *transcript) lines() []string {
 		rows, ok := t.rowCache[m.LT]
 		if !ok {
 			rows = t.renderMsgBase(m)
+		}
+		if rows.plain == nil {
+			rows.plain = make([]string, len(rows.rows))
+			for k, r := range rows.rows {
+				if r.ref.valid() {
+					rows.plain[k] = decorateNodeRow(r.text, selectionMark{}, t.w)
+				}
+			}
 			t.rowCache[m.LT] = rows
 		}
-		appendMsg(rows.rows, m.LT)
+		appendMsg(rows.rows, rows.plain, m.LT)
 	}
 	if open := t.openMessage(); open != nil {
-		appendMsg(t.renderMsgBase(*open).rows, open.LT)
+		appendMsg(t.renderMsgBase(*open).rows, nil, open.LT)
 	}
 	t.lineLT = lts
 	return out
@@ -573,9 +638,12 @@ func (t *transcript) stopFollowing() {
 // instances are cached; open messages are rebuilt on every live frame.
 func (t *transcript) renderMsgBase(m aria.Message) cachedMessage {
 	var rows []transcriptRow
-	if h := messageHeader(m.Role); h != "" {
+	if h := messageHeader(m.Role, m.Author); h != "" {
 		rows = append(rows, transcriptRow{text: h}, transcriptRow{})
 	}
+	if note, ok := t.marks[m.LT]; ok {
+		rows = append(rows, transcriptRow{text: markRow(note, t.w)}, transcriptRow{})
+	}
 	for k, n := range m.Nodes {
 		if k > 0 {
 			rows = append(rows, transcriptRow{})
@@ -600,13 +668,15 @@ func (t *transcript) renderNode(n livedoc.Node, ref nodeRef) []string {
 }
 
 func (t *transcript) render() {
-	if !t.active {
+	if !t.active || t.suspended {
 		return
 	}
 	all := t.lines()
 	foot := []string{}
 	if t.showHelp {
 		foot = t.helpLines()
+	} else if t.inActions {
+		foot = t.actionLines()
 	} else if t.showStatus {
 		foot = t.statusPanelLines()
 	}
@@ -630,7 +700,7 @@ func (t *transcript) render() {
 	screen := make([]string, t.h)
 	for r := 0; r < body; r++ {
 		if i := t.offset + r; i < len(all) {
-			screen[r] = all[i]
+			screen[r] = highlightMatches(all[i], t.hits)
 		}
 	}
 	for k, l := range foot {
@@ -638,7 +708,7 @@ func (t *transcript) render() {
 			screen[r] = l
 		}
 	}
-	rule, status := t.footerRows(len(all), body)
+	rule, status := t.footerRows(all, body)
 	screen[t.h-2] = rule
 	screen[t.h-1] = status
 	t.paint(screen)
@@ -652,8 +722,10 @@ func (t *transcript) render() {
 //
 // The rule row carries the identity + scroll position right-aligned; the
 // status row is plain left-aligned text (fig status at a glance). In search,
-// the status row becomes the query prompt.
-func (t *transcript) footerRows(total, body int) (rule, status string) {
+// the status row becomes the query prompt. all is the frame's rendered
+// lines, which the match count reads rather than rendering them again.
+func (t *transcript) footerRows(all []string, body int) (rule, status string) {
+	total := len(all)
 	pos := ""
 	if total > body {
 		end := t.offset + body
@@ -665,10 +737,34 @@ func (t *transcript) footerRows(total, body int) (rule, status string) {
 			pos += " live"
 		}
 	}
+	if t.hits != "" {
+		cur, n := matchPosition(all, t.hits, t.offset)
+		hit := fmt.Sprintf("/%s %d/%d", truncRunes(t.hits, 16), cur, n)
+		if pos == "" {
+			pos = hit
+		} else {
+			pos = hit + " · " + pos
+		}
+	}
 	rule = "\x1b[2m" + t.status.ruleLine(t.w, pos) + "\x1b[0m"
 	if t.inSearch {
 		return rule, "\x1b[2m" + clipToWidth("/"+t.query, t.w) + "\x1b[0m"
 	}
+	if t.inReply {
+		return rule, clipTailToWidth(locale.T("pager.reply")+t.reply, t.w)
+	}
+	if t.inJump {
+		return rule, "\x1b[2m" + clipToWidth(":"+t.jump, t.w) + "\x1b[0m"
+	}
+	if t.inMark {
+		return rule, clipTailToWidth(locale.Tf("pager.note", t.markLT)+t.mark, t.w)
+	}
+	if t.notice != "" {
+		return rule, clipToWidth(t.notice, t.w)
+	}
+	if t.visual {
+		return rule, term.Accent(clipToWidth(t.visualStatus(), t.w))
+	}
 	return rule, "\x1b[2m" + t.status.statusLine(t.w, true) + "\x1b[0m"
 }
 
@@ -684,27 +780,14 @@ func (t *transcript) statusPanelLines() []string {
 	return rows
 }
 
-// helpLines is the '?' panel: the footer grown upward into a key reference,
-// drawn above the footer while output keeps streaming past above it. Any key
+// helpLines is the '?' panel: the footer grown upward into a key reference
+// (generated from the active keymap, so rebound keys show), drawn above the
+// footer while output keeps streaming past above it. Any key
 // wipes it. (Deliberately a bottom panel, not a floating overlay: the terminal
 // has exactly one alternate buffer, and compositing a float into every live
 // repaint buys nothing over this.)
 func (t *transcript) helpLines() []string {
-	rows := []string{
-		"",
-		"  j/k · u/d · gg/G    scroll · half-page · top/bottom",
-		"  /                   search (Enter jump · Esc cancel)",
-		"  y                   copy aria id",
-		"  ^O                  toggle verbose tool output",
-		"  ^N/^P               select next/previous node",
-		"  ^N/^P + Shift       extend node selection (Alt+^N/^P fallback)",
-		"  Enter / ^C          expand tools / copy selected node(s)",
-		"  ^L                  listen — stay open after the turn ends",
-		"  ^D                  detach; the turn keeps running",
-		"  ^C                  interrupt the turn / close",
-		"  !                   figaro status panel",
-		"  ?                   close help",
-	}
+	rows := append([]string{""}, pagerKeys.helpRows(keyHelpRows)...)
 	if v := helpVersionLine(); v != "" {
 		rows = append(rows, "", "  "+v)
 	}
@@ -743,61 +826,133 @@ func (t *transcript) key(b byte) {
 		t.render()
 		return
 	}
+	if t.inReply {
+		t.replyKey(b)
+		t.render()
+		return
+	}
+	t.notice = ""
+	if t.inJump {
+		t.jumpKey(b)
+		t.render()
+		return
+	}
+	if t.inActions {
+		t.actionKey(b)
+		t.render()
+		return
+	}
+	if t.inMark {
+		t.markKey(b)
+		t.render()
+		return
+	}
+	action, first := pagerKeys.action(b, t.pendKey == b)
+	if first {
+		t.pendKey = b
+	} else {
+		t.pendKey = 0
+	}
 	if t.showHelp || t.showStatus { // any key wipes the panel; nav keys also still act below
-		reopen := byte(0)
-		if t.showHelp && b == '!' {
-			reopen = '!' // switch panels directly
+		reopen := ""
+		if t.showHelp && action == keyStatus {
+			reopen = keyStatus // switch panels directly
 		}
-		if t.showStatus && b == '?' {
-			reopen = '?'
+		if t.showStatus && action == keyHelp {
+			reopen = keyHelp
 		}
 		t.showHelp, t.showStatus = false, false
 		switch {
-		case reopen == '!':
+		case reopen == keyStatus:
 			t.showStatus = true
-		case reopen == '?':
+		case reopen == keyHelp:
 			t.showHelp = true
-		case b == '?' || b == '!' || b == 0x1b || b == 'q':
+		case action == keyHelp || action == keyStatus || b == 0x1b || b == 'q':
 			t.render()
 			return
 		}
-		if reopen != 0 {
+		if reopen != "" {
 			t.render()
 			return
 		}
 	}
-	switch b {
-	case 'j':
+	if t.visual {
+		switch {
+		case action == keyDown || action == keyUp:
+			delta := 1
+			if action == keyUp {
+				delta = -1
+			}
+			t.visualMove(delta)
+			t.render()
+			return
+		case action == keyVisual || b == 0x1b:
+			t.clearSelection()
+			t.render()
+			return
+		}
+	}
+	switch action {
+	case keyDown:
 		t.offset++
 		t.stopFollowing()
 		t.checkNewer = true
-	case 'k':
+	case keyUp:
 		t.offset--
 		t.stopFollowing()
 		t.checkOlder = true
-	case 'd':
+	case keyHalfDown:
 		t.offset += t.h / 2
 		t.stopFollowing()
 		t.checkNewer = true
-	case 'u':
+	case keyHalfUp:
 		t.offset -= t.h / 2
 		t.stopFollowing()
 		t.checkOlder = true
-	case 'G':
+	case keyBottom:
 		t.follow = true
 		t.resetToTail()
-	case 'g':
-		if t.pendG {
-			t.offset = 0
-			t.stopFollowing()
-			t.checkOlder = true
-		}
-	case '/':
+	case keyTop:
+		t.offset = 0
+		t.stopFollowing()
+		t.checkOlder = true
+	case keySearch:
 		t.inSearch, t.query = true, ""
-	case '?':
+	case keyJump:
+		t.inJump, t.jump = true, ""
+	case keyPager:
+		t.pagerOut = true
+	case keyNextMatch:
+		t.find(t.hits)
+	case keyPrevMatch:
+		t.findPrev(t.hits)
+	case keyReply:
+		if t.replyable {
+			t.inReply, t.reply = true, ""
+		}
+	case keyActions:
+		if t.selection.active {
+			t.inActions = true
+		}
+	case keyVisual:
+		t.startVisual()
+	case keyMark:
+		t.startMark()
+	case keyNextAlt:
+		t.altOut = 1
+	case keyPrevAlt:
+		t.altOut = -1
+	case keyClock:
+		relativeTime.Store(!relativeTime.Load())
+		t.invalidateRows() // expanded tool rows carry timestamps
+	case keyHelp:
 		t.showHelp = true
-	case '!':
+	case keyStatus:
 		t.showStatus = true
+	}
+	switch b {
+	case 0x1b:
+		t.hits = ""
 	case 0x0e: // Ctrl-N
 		t.selectNode(1, false)
 	case 0x10: // Ctrl-P
@@ -805,7 +960,6 @@ func (t *transcript) key(b byte) {
 	case 0x0d, 0x0a:
 		t.toggleSelectedTools()
 	}
-	t.pendG = b == 'g' && !t.pendG
 	t.render()
 }
 
@@ -813,6 +967,7 @@ func (t *transcript) searchKey(b byte) {
 	switch b {
 	case 0x0d, 0x0a: // Enter → jump to first match
 		t.inSearch = false
+		t.hits = t.query
 		t.find(t.query)
 	case 0x1b: // Esc → cancel
 		t.inSearch, t.query = false, ""
@@ -827,6 +982,45 @@ func (t *transcript) searchKey(b byte) {
 	}
 }
 
+// replyKey edits the reply box. Unlike the search query it takes UTF-8, so
+// backspace drops a whole rune.
+func (t *transcript) replyKey(b byte) {
+	switch b {
+	case 0x0d, 0x0a:
+		if strings.TrimSpace(t.reply) != "" {
+			t.replyOut = t.reply
+		}
+		t.inReply, t.reply = false, ""
+	case 0x1b:
+		t.inReply, t.reply = false, ""
+	case 0x7f, 0x08:
+		if _, n := utf8.DecodeLastRuneInString(t.reply); n > 0 {
+			t.reply = t.reply[:len(t.reply)-n]
+		}
+	case 0x15: // Ctrl-U
+		t.reply = ""
+	default:
+		if b >= 0x20 && b != 0x7f {
+			t.reply += string([]byte{b})
+		}
+	}
+}
+
+// takeAlternative hands a pending '[' / ']' step (-1 or +1) to the caller
+// exactly once; zero means none.
+func (t *transcript) takeAlternative() int {
+	step := t.altOut
+	t.altOut = 0
+	return step
+}
+
+// takeReply hands a submitted reply to the caller exactly once.
+func (t *transcript) takeReply() string {
+	out := t.replyOut
+	t.replyOut = ""
+	return out
+}
+
 // find scrolls to the first line at/after the cursor containing q (wrapping).
 func (t *transcript) find(q string) {
 	if q == "" {
@@ -859,6 +1053,117 @@ func (t *transcript) find(q string) {
 	}
 }
 
+// findPrev scrolls to the nearest line above the cursor containing q,
+// wrapping within the loaded window. Unlike find it never pages history in.
+func (t *transcript) findPrev(q string) {
+	if q == "" {
+		return
+	}
+	all := t.lines()
+	for i := 1; i <= len(all); i++ {
+		idx := ((t.offset-i)%len(all) + len(all)) % len(all)
+		if searchContains(all[idx], q) {
+			t.offset = idx
+			t.stopFollowing()
+			return
+		}
+	}
+}
+
+// matchPosition counts the lines matching q and the 1-based index of the
+// first one at or below offset, the top of the viewport.
+func matchPosition(lines []string, q string, offset int) (cur, n int) {
+	for i, line := range lines {
+		if !searchContains(line, q) {
+			continue
+		}
+		n++
+		if cur == 0 && i >= offset {
+			cur = n
+		}
+	}
+	return cur, n
+}
+
+// highlightMatches reverse-videos every visible occurrence of q in row,
+// stepping over (and re-asserting across) the row's own SGR escapes.
+func highlightMatches(row, q string) string {
+	if q == "" || !searchContains(row, q) {
+		return row
+	}
+	var vis []byte
+	var at []int // raw index of each visible byte
+	for i := 0; i < len(row); {
+		if n := escapeLen(row, i); n > 0 {
+			i += n
+			continue
+		}
+		vis = append(vis, row[i])
+		at = append(at, i)
+		i++
+	}
+	hit := make(map[int]bool)
+	for from := 0; ; {
+		k := strings.Index(string(vis[from:]), q)
+		if k < 0 {
+			break
+		}
+		for j := from + k; j < from+k+len(q); j++ {
+			hit[at[j]] = true
+		}
+		from += k + len(q)
+	}
+	var b strings.Builder
+	on := false
+	for i := 0; i < len(row); {
+		if n := escapeLen(row, i); n > 0 {
+			b.WriteString(row[i : i+n])
+			if on {
+				b.WriteString("\x1b[7m")
+			}
+			i += n
+			continue
+		}
+		if hit[i] != on {
+			on = hit[i]
+			if on {
+				b.WriteString("\x1b[7m")
+			} else {
+				b.WriteString("\x1b[27m")
+			}
+		}
+		b.WriteByte(row[i])
+		i++
+	}
+	if on {
+		b.WriteString("\x1b[27m")
+	}
+	return b.String()
+}
+
+// escapeLen is the byte length of the escape sequence starting at row[i], or
+// 0 when row[i] is not ESC. It parses the same way searchContains skips.
+func escapeLen(row string, i int) int {
+	if row[i] != '\x1b' {
+		return 0
+	}
+	if i+1 >= len(row) {
+		return 1
+	}
+	if row[i+1] != '[' {
+		return 2
+	}
+	j := i + 2
+	for j < len(row) {
+		final := row[j]
+		j++
+		if final >= 0x40 && final <= 0x7e {
+			break
+		}
+	}
+	return j - i
+}
+
 func (t *transcript) findPage(q string, messages []aria.Message) bool {
 	for _, m := range messages {
 		if !t.messageMayRenderQuery(m, q) {
@@ -917,7 +1222,7 @@ func searchContains(row, q string) bool {
 }
 
 func (t *transcript) messageMayRenderQuery(m aria.Message, q string) bool {
-	if strings.Contains(messageHeader(m.Role), q) {
+	if strings.Contains(messageHeader(m.Role, m.Author), q) {
 		return true
 	}
 	verbose := false
@@ -1055,5 +1360,5 @@ func dimTransRule(w int) string {
 	if w < 3 {
 		w = 3
 	}
-	return "\x1b[2m" + strings.Repeat("─", w) + "\x1b[0m"
+	return "\x1b[2m" + term.Rule(w) + "\x1b[0m"
 }
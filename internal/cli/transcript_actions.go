@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jack-work/figaro/internal/angelus"
+	"github.com/jack-work/figaro/internal/livelog/aria"
+	"github.com/jack-work/figaro/internal/locale"
+	"github.com/jack-work/figaro/internal/transport"
+)
+
+// Message actions ('a' on a node selection). The pager only records which
+// action was picked; the input loop runs it, because copy, export, fork and
+// pin all need a client round trip the pager must not block on.
+const (
+	actionCopy   byte = 'c' // copy the selection's text
+	actionCode   byte = 'y' // copy only its fenced code blocks
+	actionFork   byte = 'f' // fork the aria at the first selected message
+	actionExport byte = 'e' // write the selection to a markdown file
+	actionPin    byte = 'p' // pin the selection into every request, or unpin it
+)
+
+// actionLines is the 'a' panel: the footer grown into the action menu, drawn
+// the same way as the help panel.
+func (t *transcript) actionLines() []string {
+	plan, _ := t.selectionPlan()
+	rows := []string{
+		"",
+		"  " + locale.Tf("pager.actions.title", plan.lo.lt),
+		"  c   " + locale.T("pager.actions.copy"),
+		"  y   " + locale.T("pager.actions.code"),
+		"  f   " + locale.T("pager.actions.fork"),
+		"  e   " + locale.T("pager.actions.export"),
+		"  p   " + locale.T("pager.actions.pin"),
+		"  Esc " + locale.T("pager.actions.close"),
+	}
+	for i, r := range rows {
+		rows[i] = "\x1b[2m" + clipToWidth(r, t.w) + "\x1b[0m"
+	}
+	return rows
+}
+
+// actionKey handles one key while the action panel is open. A known action
+// is parked in actionOut for the input loop; anything else closes the menu.
+func (t *transcript) actionKey(b byte) {
+	t.inActions = false
+	switch b {
+	case actionCopy, actionCode, actionFork, actionExport, actionPin:
+		t.actionOut = b
+	}
+}
+
+// takeAction hands a chosen action and the selection it applies to to the
+// caller exactly once.
+func (t *transcript) takeAction() (byte, selectionCopyPlan) {
+	a := t.actionOut
+	t.actionOut = 0
+	if a == 0 {
+		return 0, selectionCopyPlan{}
+	}
+	plan, ok := t.selectionPlan()
+	if !ok {
+		return 0, selectionCopyPlan{}
+	}
+	return a, plan
+}
+
+// runAction executes a message action picked in the pager. Results surface as
+// a one-shot notice on the pager's status row.
+func (in *interactiveInput) runAction(action byte, plan selectionCopyPlan) {
+	switch action {
+	case actionCopy, actionCode:
+		plan.code = action == actionCode
+		in.mu.Lock()
+		if in.copyCancel == nil {
+			in.startCopyLocked(plan)
+		}
+		in.mu.Unlock()
+	case actionExport:
+		go in.exportSelection(plan)
+	case actionPin:
+		if in.pin == nil {
+			in.notify("pin: not available here")
+			return
+		}
+		go in.pinSelection(plan)
+	case actionFork:
+		if in.fork == nil {
+			in.notify("fork: not available here")
+			return
+		}
+		go func() {
+			alt, err := in.fork(uint64(plan.lo.lt))
+			if err != nil {
+				in.notify("fork: " + err.Error())
+				return
+			}
+			in.notify(locale.Tf("pager.notice.forked", plan.lo.lt, alt, alt))
+		}()
+	}
+}
+
+// exportSelection writes the selected messages to <aria>-<LT>.md in the
+// working directory.
+func (in *interactiveInput) exportSelection(plan selectionCopyPlan) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	text, err := selectionText(plan, transcriptPageSize, func(before, limit int) (aria.AriaRead, error) {
+		return in.fcli.ReadBefore(ctx, before, limit)
+	})
+	if err != nil {
+		in.notify("export: " + err.Error())
+		return
+	}
+	name := exportName(in.figaroID, plan.lo.lt)
+	if err := os.WriteFile(name, []byte(text+"\n"), 0o644); err != nil {
+		in.notify("export: " + err.Error())
+		return
+	}
+	in.notify(locale.Tf("pager.notice.exported", name))
+}
+
+// pinSelection pins the selected messages under the first one's LT, or
+// unpins that LT when it is already pinned.
+func (in *interactiveInput) pinSelection(plan selectionCopyPlan) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	text, err := selectionText(plan, transcriptPageSize, func(before, limit int) (aria.AriaRead, error) {
+		return in.fcli.ReadBefore(ctx, before, limit)
+	})
+	if err != nil {
+		in.notify("pin: " + err.Error())
+		return
+	}
+	pinned, err := in.pin(uint64(plan.lo.lt), text)
+	switch {
+	case err != nil:
+		in.notify("pin: " + err.Error())
+	case pinned:
+		in.notify(locale.Tf("pager.notice.pinned", plan.lo.lt))
+	default:
+		in.notify(locale.Tf("pager.notice.unpinned", plan.lo.lt))
+	}
+}
+
+// pinHere returns the pager's pin hook over the aria's own client.
+func pinHere(fc pinClient) func(uint64, string) (bool, error) {
+	return func(lt uint64, text string) (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return togglePin(ctx, fc, lt, text)
+	}
+}
+
+func exportName(figaroID string, lt int) string {
+	return fmt.Sprintf("%s-%d.md", figaroID, lt)
+}
+
+// notify shows msg on the pager's status row until the next key.
+func (in *interactiveInput) notify(msg string) {
+	in.mu.Lock()
+	in.lt.transcriptNotice(msg)
+	in.mu.Unlock()
+}
+
+// forkHere returns the pager's fork hook: it forks figaroID at a main-LT and
+// returns the fresh alternative. The shell binding is left alone — the live
+// view stays on the (now frozen) aria, and the notice names the new branch.
+// Dials the angelus directly rather than through mustConnectAngelus: a
+// failure here is a notice, never an exit from under the raw-mode pager.
+func forkHere(figaroID string) func(uint64) (string, error) {
+	return func(lt uint64) (string, error) {
+		acli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath()))
+		if err != nil {
+			return "", fmt.Errorf("connect angelus: %w", err)
+		}
+		defer acli.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		resp, err := acli.Fork(ctx, figaroID, lt)
+		if err != nil {
+			return "", err
+		}
+		return resp.Alternative, nil
+	}
+}
+
+// stepAlternative runs the pager's '[' / ']': the shell moves to the
+// neighbouring alternative like attend -/+, and the notice names it. The
+// view stays on this aria, as it does after a fork.
+func (in *interactiveInput) stepAlternative(step int) {
+	if in.alternative == nil {
+		in.notify("attend: not available here")
+		return
+	}
+	go func() {
+		id, pos, n, err := in.alternative(step)
+		if err != nil {
+			in.notify("attend: " + err.Error())
+			return
+		}
+		in.notify(locale.Tf("pager.notice.attending", id, pos, n))
+	}()
+}
+
+// attendAlternative is the pager's attend -/+ for figaroID, dialing the
+// angelus directly like forkHere.
+func attendAlternative(figaroID string) func(int) (string, int, int, error) {
+	return func(step int) (string, int, int, error) {
+		acli, err := angelus.DialClient(transport.UnixEndpoint(angelusSocketPath()))
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("connect angelus: %w", err)
+		}
+		defer acli.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return stepAlternative(ctx, acli, os.Getppid(), figaroID, step)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jack-work/figaro/internal/livelog/aria"
+)
+
+// jumpKey edits the ':' prompt: digits name a message by LT. Enter jumps
+// straight there when the LT is in the loaded window; otherwise the LT is
+// parked in jumpOut for the input loop to fetch a window around it.
+func (t *transcript) jumpKey(b byte) {
+	switch b {
+	case 0x0d, 0x0a:
+		lt, err := strconv.Atoi(t.jump)
+		t.inJump, t.jump = false, ""
+		if err != nil || lt < 1 {
+			return
+		}
+		if t.committedW > 0 && lt > t.committedW {
+			t.notice = fmt.Sprintf("no LT %d (last is %d)", lt, t.committedW)
+			return
+		}
+		if !t.jumpTo(lt) {
+			t.jumpOut = lt
+		}
+	case 0x1b:
+		t.inJump, t.jump = false, ""
+	case 0x7f, 0x08:
+		if len(t.jump) > 0 {
+			t.jump = t.jump[:len(t.jump)-1]
+		}
+	default:
+		if b >= '0' && b <= '9' && len(t.jump) < 10 {
+			t.jump += string(b)
+		}
+	}
+}
+
+// takeJump hands an LT outside the loaded window to the caller exactly once.
+func (t *transcript) takeJump() int {
+	lt := t.jumpOut
+	t.jumpOut = 0
+	return lt
+}
+
+// jumpTo centres the message at lt (or the first one after it, for LTs that
+// hold no renderable message) when it lies inside the loaded window.
+func (t *transcript) jumpTo(lt int) bool {
+	oldest, ok := t.oldestLT()
+	if !ok || (lt < oldest && !t.noMoreOlder) {
+		return false
+	}
+	if newest, _ := t.newestLT(); lt > newest && t.hasNewerHistory() {
+		return false
+	}
+	t.lines()
+	first, last := -1, -1
+	for i, l := range t.lineLT {
+		if first < 0 && l >= lt {
+			first, lt = i, l
+		}
+		if first >= 0 && l == lt {
+			last = i
+		}
+	}
+	if first < 0 {
+		return false
+	}
+	t.stopFollowing()
+	t.centerSpan(first, last)
+	return true
+}
+
+// jumpWindow replaces the loaded window with messages — a page read around
+// lt — and centres lt in it. Paging outward from there works as it does from
+// the tail: older pages load by keyset, newer ones up to the watermark.
+func (t *transcript) jumpWindow(lt int, messages []aria.Message) {
+	if !t.active {
+		return
+	}
+	if len(messages) == 0 {
+		t.notice = fmt.Sprintf("no LT %d", lt)
+		t.render()
+		return
+	}
+	t.stopFollowing()
+	t.pages = []transcriptPage{{desc: describePage(messages), messages: messages}}
+	t.newer = nil
+	t.payloadLRU = nil
+	t.checkOlder, t.checkNewer = false, false
+	t.noMoreOlder = messages[0].LT <= 1
+	if last := messages[len(messages)-1].LT; last > t.committedW {
+		t.committedW = last
+	}
+	t.pruneCaches()
+	if !t.jumpTo(lt) {
+		t.notice = fmt.Sprintf("no LT %d", lt)
+	}
+	t.render()
+}
+
+// centerSpan scrolls so lines first..last sit mid-viewport; a span taller
+// than the viewport is top-aligned instead.
+func (t *transcript) centerSpan(first, last int) {
+	body := t.h - 2
+	if body < 1 {
+		body = 1
+	}
+	n := last - first + 1
+	if n >= body {
+		t.offset = first
+		return
+	}
+	t.offset = first - (body-n)/2
+	if t.offset < 0 {
+		t.offset = 0
+	}
+}
+
+// jumpTranscript loads the page around an LT the pager could not reach in
+// its window. Like the other page fetches it runs on the input loop, off-lock.
+func (in *interactiveInput) jumpTranscript(lt int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	r, err := in.fcli.ReadBefore(ctx, lt+transcriptPageSize/2, transcriptPageSize)
+	cancel()
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if err != nil {
+		in.lt.transcriptNotice(fmt.Sprintf("jump to LT %d: %s", lt, err))
+		return
+	}
+	in.lt.transcriptJumpWindow(lt, committedMessages(r.Committed))
+}
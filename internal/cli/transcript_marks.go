@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jack-work/figaro/internal/bookmark"
+	"github.com/jack-work/figaro/internal/locale"
+	"github.com/jack-work/figaro/internal/term"
+)
+
+// markEdit is a bookmark change made in the pager, applied by the input loop.
+type markEdit struct {
+	lt     int
+	note   string
+	remove bool
+}
+
+func bookmarkStore() *bookmark.Store {
+	return bookmark.Open(filepath.Join(stateDir(), "bookmarks.json"))
+}
+
+// markRow is the line under a bookmarked message's header.
+func markRow(note string, w int) string {
+	if note == "" {
+		note = "bookmarked"
+	}
+	return term.Accent(clipToWidth(term.Sym("◆ ", "bookmark: ")+note, w))
+}
+
+// startMark opens the note prompt on the first selected message, or the
+// message at the top of the viewport, filled with any note it already has.
+func (t *transcript) startMark() {
+	lt := 0
+	if plan, ok := t.selectionPlan(); ok {
+		lt = plan.lo.lt
+	} else if t.offset >= 0 && t.offset < len(t.lineLT) {
+		lt = t.lineLT[t.offset]
+	}
+	if lt < 1 {
+		return
+	}
+	t.inMark, t.markLT, t.mark = true, lt, t.marks[lt]
+}
+
+// markKey edits the note prompt. Enter saves (an empty note is a plain
+// bookmark), Ctrl-X removes the bookmark, Esc cancels.
+func (t *transcript) markKey(b byte) {
+	switch b {
+	case 0x0d, 0x0a:
+		t.markOut = &markEdit{lt: t.markLT, note: strings.TrimSpace(t.mark)}
+		t.inMark, t.mark = false, ""
+	case 0x18: // Ctrl-X
+		if _, ok := t.marks[t.markLT]; ok {
+			t.markOut = &markEdit{lt: t.markLT, remove: true}
+		}
+		t.inMark, t.mark = false, ""
+	case 0x1b:
+		t.inMark, t.mark = false, ""
+	case 0x7f, 0x08:
+		if _, n := utf8.DecodeLastRuneInString(t.mark); n > 0 {
+			t.mark = t.mark[:len(t.mark)-n]
+		}
+	case 0x15: // Ctrl-U
+		t.mark = ""
+	default:
+		if b >= 0x20 && b != 0x7f {
+			t.mark += string([]byte{b})
+		}
+	}
+}
+
+// takeMark hands a saved edit to the caller exactly once.
+func (t *transcript) takeMark() (markEdit, bool) {
+	e := t.markOut
+	t.markOut = nil
+	if e == nil {
+		return markEdit{}, false
+	}
+	return *e, true
+}
+
+// setMarks replaces the pager's bookmarks.
+func (t *transcript) setMarks(marks map[int]string) {
+	t.marks = marks
+	t.invalidateRows()
+	t.render()
+}
+
+// setMark applies one edit and redraws the message it touched.
+func (t *transcript) setMark(e markEdit) {
+	if t.marks == nil {
+		t.marks = map[int]string{}
+	}
+	if e.remove {
+		delete(t.marks, e.lt)
+	} else {
+		t.marks[e.lt] = e.note
+	}
+	delete(t.rowCache, e.lt)
+	t.render()
+}
+
+// loadMarks hands the aria's bookmarks to the pager.
+func (in *interactiveInput) loadMarks() {
+	marks, err := bookmarkStore().List(in.figaroID)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if err != nil {
+		in.lt.transcriptNotice("bookmarks: " + err.Error())
+		return
+	}
+	byLT := make(map[int]string, len(marks))
+	for _, m := range marks {
+		byLT[m.LT] = m.Note
+	}
+	in.lt.setTranscriptMarks(byLT)
+}
+
+// saveMark writes a bookmark edit from the pager and shows the result.
+func (in *interactiveInput) saveMark(e markEdit) {
+	store := bookmarkStore()
+	var err error
+	if e.remove {
+		_, err = store.Remove(in.figaroID, e.lt)
+	} else {
+		_, err = store.Set(in.figaroID, e.lt, e.note)
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if err != nil {
+		in.lt.transcriptNotice("bookmark: " + err.Error())
+		return
+	}
+	in.lt.setTranscriptMark(e)
+	if e.remove {
+		in.lt.transcriptNotice(locale.Tf("pager.notice.unbookmarked", e.lt))
+	} else {
+		in.lt.transcriptNotice(locale.Tf("pager.notice.bookmarked", e.lt))
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/livelog/aria"
+	ldmouse "github.com/jack-work/figaro/internal/livelog/render/mouse"
+)
+
+// defaultPager runs when $PAGER is unset; -R passes the renderer's SGR
+// colours through.
+const defaultPager = "less -R"
+
+// renderAll renders messages (plus the open one, if any) exactly as the pager
+// draws them, minus selection gutters: the text handed to $PAGER by '|'.
+func (t *transcript) renderAll(messages []aria.Message) string {
+	if open := t.client.View().Open; open != nil {
+		messages = append(messages[:len(messages):len(messages)], *open)
+	}
+	var out []string
+	rule := dimTransRule(t.w)
+	for _, m := range messages {
+		if len(out) > 0 {
+			out = append(out, "", rule, "")
+		}
+		for _, r := range t.renderMsgBase(m).rows {
+			line := r.text
+			if r.ref.valid() {
+				line = decorateNodeRow(line, selectionMark{}, t.w)
+			}
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// takePager reports a '|' press to the caller exactly once.
+func (t *transcript) takePager() bool {
+	on := t.pagerOut
+	t.pagerOut = false
+	return on
+}
+
+// suspend hands the screen to a child process: repaints stop, and mouse
+// reporting, no-wrap and the hidden cursor are undone until resume.
+func (t *transcript) suspend() {
+	t.suspended = true
+	io.WriteString(t.out, ldmouse.Disable+autowrapOn+cursorShow)
+}
+
+// resume takes the screen back after a child process exits. The child may
+// have switched screens, so the alt screen is re-entered and fully repainted.
+func (t *transcript) resume() {
+	t.suspended = false
+	if !t.active {
+		return
+	}
+	t.prev = nil
+	io.WriteString(t.out, altScreenOn+autowrapOff+ldmouse.Enable+cursorHide+"\x1b[2J")
+	t.render()
+}
+
+// openInPager reads the aria's whole committed history, renders it, and runs
+// $PAGER on it from a temp file. It runs on the input loop, so nothing else
+// reads stdin while the pager owns the terminal.
+func (in *interactiveInput) openInPager() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	messages, err := readAllHistory(ctx, in.fcli)
+	cancel()
+	if err != nil {
+		in.notify("pager: " + err.Error())
+		return
+	}
+	in.mu.Lock()
+	text := in.lt.transcriptRenderAll(messages)
+	in.lt.suspendTranscript()
+	in.mu.Unlock()
+
+	err = runPager(in.figaroID, text)
+
+	in.mu.Lock()
+	in.lt.resumeTranscript()
+	if err != nil {
+		in.lt.transcriptNotice("pager: " + err.Error())
+	}
+	in.mu.Unlock()
+}
+
+// readAllHistory pages the committed log back to LT 1, oldest first.
+func readAllHistory(ctx context.Context, fcli transcriptReadClient) ([]aria.Message, error) {
+	var pages [][]aria.Message
+	before := recentCursor
+	for {
+		r, err := fcli.ReadBefore(ctx, before, transcriptPageSize)
+		if err != nil {
+			return nil, err
+		}
+		messages := committedMessages(r.Committed)
+		if len(messages) == 0 {
+			break
+		}
+		pages = append(pages, messages)
+		before = messages[0].LT
+		if before <= 1 {
+			break
+		}
+	}
+	var out []aria.Message
+	for i := len(pages) - 1; i >= 0; i-- {
+		out = append(out, pages[i]...)
+	}
+	return out, nil
+}
+
+// runPager writes text to a temp file and runs $PAGER on it with the
+// terminal attached. $PAGER is split on spaces (no shell), so "less -R" and
+// "bat --paging=always" work as written.
+func runPager(figaroID, text string) error {
+	argv := pagerCommand(os.Getenv("PAGER"))
+	f, err := os.CreateTemp("", "figaro-"+figaroID+"-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], append(argv[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=R")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return nil
+}
+
+func pagerCommand(env string) []string {
+	if argv := strings.Fields(env); len(argv) > 0 {
+		return argv
+	}
+	return strings.Fields(defaultPager)
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -269,3 +270,41 @@ func TestTranscript_ReloadsOldestAfterNewerEviction(t *testing.T) {
 		t.Fatalf("oldest page was not reloadable: %+v, %v", req, ok)
 	}
 }
+
+type historyReader []aria.Committed
+
+func (r historyReader) Read(context.Context, int) (aria.AriaRead, error) {
+	return aria.AriaRead{}, nil
+}
+
+func (r historyReader) ReadBefore(_ context.Context, before, limit int) (aria.AriaRead, error) {
+	return readBefore(r, before, limit), nil
+}
+
+func TestTranscriptRenderAllForPager(t *testing.T) {
+	history := transcriptHistory(75)
+	messages, err := readAllHistory(context.Background(), historyReader(history))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 75 || messages[0].LT != 1 || messages[74].LT != 75 {
+		t.Fatalf("readAllHistory returned %d messages, want LT 1..75 in order", len(messages))
+	}
+	client := aria.NewClient()
+	tr := newTranscript(ldrender.NewFakeTerminal(50, 8), 50, 8, ldrender.NodeText{}, client, "aria1234", time.Now())
+	text := tr.renderAll(messages)
+	first, last := strings.Index(text, "message-001"), strings.Index(text, "message-075")
+	if first < 0 || last < first {
+		t.Fatalf("rendered transcript out of order or incomplete:\n%s", text)
+	}
+	if n := strings.Count(text, dimTransRule(50)); n != 74 {
+		t.Fatalf("want one rule between each pair of messages, got %d", n)
+	}
+
+	if got := pagerCommand(""); strings.Join(got, " ") != defaultPager {
+		t.Fatalf("unset $PAGER = %q, want %q", got, defaultPager)
+	}
+	if got := pagerCommand(" bat  --paging=always "); len(got) != 2 || got[0] != "bat" {
+		t.Fatalf("pagerCommand split = %q", got)
+	}
+}
@@ -35,6 +35,7 @@ type selectionCopyPlan struct {
 	lo   selectionPoint
 	hi   selectionPoint
 	open *aria.Message
+	code bool // 'y': keep only fenced code blocks when there are any
 }
 
 type transcriptRow struct {
@@ -44,6 +45,9 @@ type transcriptRow struct {
 
 type cachedMessage struct {
 	rows []transcriptRow
+	// plain memoizes decorateNodeRow for unselected rows (parallel to rows,
+	// filled on first layout), so a keypress only re-decorates marked nodes.
+	plain []string
 }
 
 type nodeSpan struct {
@@ -109,6 +113,7 @@ func (t *transcript) selectionMarks() map[nodeRef]selectionMark {
 }
 
 func (t *transcript) selectNode(delta int, extend bool) {
+	t.visual = false
 	refs := t.nodeRefs()
 	if len(refs) == 0 {
 		return
@@ -162,6 +167,7 @@ func (t *transcript) clearSelection() {
 	}
 	anchorLT, within := t.viewportAnchor()
 	t.selection = nodeSelection{}
+	t.visual = false
 	t.trimPages(direction)
 	t.pruneCaches()
 	t.lines()
@@ -363,6 +369,8 @@ func (t *transcript) toggleSelectedTools() bool {
 	return true
 }
 
+// ensureSelectionVisible centres the focused node when it has left the
+// viewport; a node already on screen doesn't move the view.
 func (t *transcript) ensureSelectionVisible() {
 	if !t.selection.active {
 		return
@@ -372,14 +380,12 @@ func (t *transcript) ensureSelectionVisible() {
 	if !ok {
 		return
 	}
-	body := t.h - 1
+	body := t.h - 2
 	if body < 1 {
 		body = 1
 	}
-	if span.first < t.offset {
-		t.offset = span.first
-	} else if span.last >= t.offset+body {
-		t.offset = span.last - body + 1
+	if span.first < t.offset || span.last >= t.offset+body {
+		t.centerSpan(span.first, span.last)
 	}
 }
 
@@ -387,9 +393,9 @@ func decorateNodeRow(row string, mark selectionMark, width int) string {
 	gutter := "  "
 	switch {
 	case mark.active:
-		gutter = term.Cyan("▸ ")
+		gutter = term.Accent(term.Sym("▸ ", "> "))
 	case mark.selected:
-		gutter = term.Cyan("│ ")
+		gutter = term.Accent(term.Sym("│ ", "+ "))
 	}
 	return gutter + clipToWidth(row, width-2)
 }
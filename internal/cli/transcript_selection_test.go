@@ -47,6 +47,40 @@ func TestTranscriptNodeSelectionRangeAndCopy(t *testing.T) {
 	}
 }
 
+func TestTranscriptCachesUnselectedGutters(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(80, 20)
+	client := aria.NewClient()
+	client.Apply(aria.AriaRead{Committed: []aria.Committed{{
+		LT: 1, Role: "assistant", Nodes: []livedoc.Node{
+			{Type: livedoc.NodeProse, Markdown: "first node"},
+			{Type: livedoc.NodeProse, Markdown: "second node"},
+		},
+	}}})
+	tr := newTranscript(ft, 80, 20, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	cached := tr.rowCache[1]
+	if len(cached.plain) != len(cached.rows) {
+		t.Fatalf("plain gutters not memoized: %d plain for %d rows", len(cached.plain), len(cached.rows))
+	}
+	before := tr.lines()
+
+	tr.key(0x0e) // Ctrl-N selects the first node only
+	during := tr.lines()
+	span := tr.nodeRows[nodeRef{lt: 1, index: 0}]
+	if !strings.Contains(during[span.first], "▸") {
+		t.Fatalf("selected row lost its marker: %q", during[span.first])
+	}
+	other := tr.nodeRows[nodeRef{lt: 1, index: 1}]
+	if during[other.first] != before[other.first] {
+		t.Fatalf("unselected row changed: %q vs %q", during[other.first], before[other.first])
+	}
+
+	tr.clearSelection()
+	if after := tr.lines(); strings.Join(after, "\n") != strings.Join(before, "\n") {
+		t.Fatal("clearing the selection must restore the cached rows")
+	}
+}
+
 func TestTranscriptEnterExpandsSelectedToolOutput(t *testing.T) {
 	ft := ldrender.NewFakeTerminal(80, 30)
 	client := aria.NewClient()
@@ -146,7 +146,7 @@ func TestTranscript_FooterWidthAndNoTrailingBlank(t *testing.T) {
 	if last := all[len(all)-1]; strings.TrimSpace(stripANSI(last)) == "" {
 		t.Fatalf("lines() must not end with a blank/separator (footer seals the last message); got %q", last)
 	}
-	rule, statusRow := tr.footerRows(len(all), tr.h-2)
+	rule, statusRow := tr.footerRows(all, tr.h-2)
 	rule, statusRow = stripANSI(rule), stripANSI(statusRow)
 	if w := runewidth.StringWidth(rule); w != 50 {
 		t.Fatalf("rule row display width = %d, want exactly 50: %q", w, rule)
@@ -179,7 +179,7 @@ func TestTranscript_HelpPanel(t *testing.T) {
 	if !tr.showHelp {
 		t.Fatalf("? should open the help panel")
 	}
-	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "copy aria id") {
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "copy selected code") {
 		t.Fatalf("help panel content missing:\n%s", scr)
 	}
 	tr.key('?')
@@ -195,3 +195,311 @@ func TestTranscript_HelpPanel(t *testing.T) {
 		t.Fatalf("help panel interactions must never exit the pager")
 	}
 }
+
+func TestTranscriptReplyBox(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(60, 14)
+	client := aria.NewClient()
+	client.Apply(aria.AriaRead{Committed: []aria.Committed{{
+		LT: 1, Role: "assistant",
+		Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "hello"}},
+	}}})
+	tr := newTranscript(ft, 60, 14, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	tr.key('r')
+	if tr.inReply {
+		t.Fatal("r must be inert when the caller cannot send")
+	}
+	tr.replyable = true
+	tr.key('r')
+	for _, b := range []byte("héllo j") {
+		tr.key(b)
+	}
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "reply> héllo j") {
+		t.Fatalf("reply box not drawn:\n%s", scr)
+	}
+	tr.key(0x7f)
+	tr.key(0x7f)
+	tr.key(0x0d)
+	if got := tr.takeReply(); got != "héllo" {
+		t.Fatalf("takeReply = %q, want %q", got, "héllo")
+	}
+	if tr.takeReply() != "" || tr.inReply {
+		t.Fatal("a reply is handed out once and closes the box")
+	}
+	tr.key('r')
+	tr.key('x')
+	tr.key(0x1b)
+	if tr.inReply || tr.takeReply() != "" {
+		t.Fatal("Esc cancels the reply")
+	}
+}
+
+func TestTranscriptActionMenu(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(70, 16)
+	client := aria.NewClient()
+	client.Apply(aria.AriaRead{Committed: []aria.Committed{{
+		LT: 3, Role: "assistant",
+		Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "hello"}},
+	}}})
+	tr := newTranscript(ft, 70, 16, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	tr.key('a')
+	if tr.inActions {
+		t.Fatal("a must be inert without a selection")
+	}
+	tr.key(0x10) // Ctrl-P selects the last node
+	tr.key('a')
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "actions on LT 3") {
+		t.Fatalf("action menu not drawn:\n%s", scr)
+	}
+	tr.key(actionFork)
+	action, plan := tr.takeAction()
+	if action != actionFork || plan.lo.lt != 3 {
+		t.Fatalf("takeAction = %q at LT %d, want fork at LT 3", action, plan.lo.lt)
+	}
+	if a, _ := tr.takeAction(); a != 0 || tr.inActions {
+		t.Fatal("an action is handed out once and closes the menu")
+	}
+	tr.key('a')
+	tr.key(0x1b)
+	if a, _ := tr.takeAction(); a != 0 || tr.inActions {
+		t.Fatal("Esc closes the menu without acting")
+	}
+	tr.notice = "exported to x.md"
+	tr.render()
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "exported to x.md") {
+		t.Fatalf("notice not drawn:\n%s", scr)
+	}
+	tr.key('j')
+	if tr.notice != "" {
+		t.Fatal("the next key clears a notice")
+	}
+}
+
+func TestTranscriptMarkPrompt(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(70, 16)
+	client := aria.NewClient()
+	client.Apply(aria.AriaRead{Committed: []aria.Committed{{
+		LT: 3, Role: "assistant",
+		Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: "hello"}},
+	}}})
+	tr := newTranscript(ft, 70, 16, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	tr.setMarks(map[int]string{3: "first"})
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "◆ first") {
+		t.Fatalf("mark not drawn:\n%s", scr)
+	}
+	tr.key(0x10)
+	tr.key('m')
+	if !tr.inMark || tr.markLT != 3 || tr.mark != "first" {
+		t.Fatalf("prompt = %v LT %d %q, want the existing note on LT 3", tr.inMark, tr.markLT, tr.mark)
+	}
+	tr.key(0x15)
+	for _, b := range []byte("why it broke") {
+		tr.key(b)
+	}
+	tr.key(0x0d)
+	e, ok := tr.takeMark()
+	if !ok || e != (markEdit{lt: 3, note: "why it broke"}) {
+		t.Fatalf("takeMark = %+v %v", e, ok)
+	}
+	if _, ok := tr.takeMark(); ok {
+		t.Fatal("an edit is handed out once")
+	}
+	tr.setMark(e)
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "◆ why it broke") {
+		t.Fatalf("edited mark not drawn:\n%s", scr)
+	}
+	tr.key('m')
+	tr.key(0x18)
+	if e, _ := tr.takeMark(); !e.remove || e.lt != 3 {
+		t.Fatalf("Ctrl-X = %+v, want a removal of LT 3", e)
+	}
+	tr.key('m')
+	tr.key(0x1b)
+	if _, ok := tr.takeMark(); ok || tr.inMark {
+		t.Fatal("Esc closes the prompt without an edit")
+	}
+}
+
+func TestTranscriptJumpCentresLoadedLT(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(70, 16)
+	client := aria.NewClient()
+	var committed []aria.Committed
+	for lt := 1; lt <= 20; lt++ {
+		committed = append(committed, aria.Committed{
+			LT: lt, Role: "assistant",
+			Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: fmt.Sprintf("message %d", lt)}},
+		})
+	}
+	client.Apply(aria.AriaRead{Committed: committed})
+	tr := newTranscript(ft, 70, 16, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	for _, b := range []byte(":1x2") {
+		tr.key(b)
+	}
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, ":12") {
+		t.Fatalf("jump prompt not drawn (non-digits dropped):\n%s", scr)
+	}
+	tr.key(0x0d)
+	if tr.inJump || tr.follow || tr.takeJump() != 0 {
+		t.Fatal("a loaded LT jumps in place and stops following")
+	}
+	first := -1
+	for i, lt := range tr.lineLT {
+		if lt == 12 {
+			first = i
+			break
+		}
+	}
+	if body := tr.h - 2; first <= tr.offset || first >= tr.offset+body-1 {
+		t.Fatalf("LT 12 at line %d not centred in viewport %d+%d", first, tr.offset, body)
+	}
+	tr.key(':')
+	tr.key('9')
+	tr.key('9')
+	tr.key(0x0d)
+	if tr.takeJump() != 0 || !strings.Contains(tr.notice, "no LT 99") {
+		t.Fatalf("an LT past the watermark is a notice, got %q", tr.notice)
+	}
+
+	tail := tr.client.View().Closed[15:]
+	tr.pages = []transcriptPage{{desc: describePage(tail), messages: tail}}
+	tr.noMoreOlder = false
+	tr.key(':')
+	tr.key('3')
+	tr.key(0x0d)
+	if lt := tr.takeJump(); lt != 3 {
+		t.Fatalf("an LT older than the window is handed to the input loop, got %d", lt)
+	}
+	tr.jumpWindow(3, tr.client.View().Closed[:10])
+	if oldest, _ := tr.oldestLT(); oldest != 1 || tr.lineLT[tr.offset] > 3 {
+		t.Fatalf("jumpWindow did not load and show LT 3 (oldest %d, top LT %d)", oldest, tr.lineLT[tr.offset])
+	}
+}
+
+func TestTranscriptVisualSelectsWholeMessages(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(70, 30)
+	client := aria.NewClient()
+	var committed []aria.Committed
+	for lt := 1; lt <= 4; lt++ {
+		committed = append(committed, aria.Committed{
+			LT: lt, Role: "assistant",
+			Nodes: []livedoc.Node{
+				{Type: livedoc.NodeProse, Markdown: fmt.Sprintf("first %d", lt)},
+				{Type: livedoc.NodeProse, Markdown: fmt.Sprintf("second %d", lt)},
+			},
+		})
+	}
+	client.Apply(aria.AriaRead{Committed: committed})
+	tr := newTranscript(ft, 70, 30, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	tr.key(0x10) // Ctrl-P: last node of LT 4
+	tr.key(0x10) // first node of LT 4
+	tr.key('v')
+	plan, ok := tr.selectionPlan()
+	if !tr.visual || !ok || plan.lo.nodeRef != (nodeRef{lt: 4}) || plan.hi.nodeRef != (nodeRef{lt: 4, index: 1}) {
+		t.Fatalf("v must select all of LT 4, got %+v..%+v", plan.lo.nodeRef, plan.hi.nodeRef)
+	}
+	tr.key('k')
+	tr.key('k')
+	plan, _ = tr.selectionPlan()
+	if plan.lo.nodeRef != (nodeRef{lt: 2}) || plan.hi.nodeRef != (nodeRef{lt: 4, index: 1}) {
+		t.Fatalf("k must extend by whole messages, got %+v..%+v", plan.lo.nodeRef, plan.hi.nodeRef)
+	}
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "VISUAL -- LT 2–4") {
+		t.Fatalf("visual status not drawn:\n%s", scr)
+	}
+	tr.key('j')
+	tr.key('j')
+	tr.key('j')
+	plan, _ = tr.selectionPlan()
+	if plan.lo.nodeRef != (nodeRef{lt: 4}) || plan.hi.nodeRef != (nodeRef{lt: 4, index: 1}) {
+		t.Fatalf("j past the anchor must shrink back to LT 4, got %+v..%+v", plan.lo.nodeRef, plan.hi.nodeRef)
+	}
+	tr.key(0x1b)
+	if tr.visual || tr.selection.active {
+		t.Fatal("Esc leaves visual mode and drops the selection")
+	}
+}
+
+func TestHighlightMatchesAcrossEscapes(t *testing.T) {
+	if got := highlightMatches("plain row", "zz"); got != "plain row" {
+		t.Fatalf("a row without hits must pass through, got %q", got)
+	}
+	got := highlightMatches("a foo b foo", "foo")
+	if want := "a \x1b[7mfoo\x1b[27m b \x1b[7mfoo\x1b[27m"; got != want {
+		t.Fatalf("highlight = %q, want %q", got, want)
+	}
+	// A reset inside the hit must not end the highlight early.
+	got = highlightMatches("x f\x1b[0moo", "foo")
+	if want := "x \x1b[7mf\x1b[0m\x1b[7moo\x1b[27m"; got != want {
+		t.Fatalf("highlight = %q, want %q", got, want)
+	}
+}
+
+func TestTranscriptSearchStepsThroughHits(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(60, 8)
+	client := aria.NewClient()
+	var committed []aria.Committed
+	for lt := 1; lt <= 12; lt++ {
+		text := fmt.Sprintf("line %d", lt)
+		if lt%4 == 0 {
+			text = fmt.Sprintf("needle %d", lt)
+		}
+		committed = append(committed, aria.Committed{
+			LT: lt, Role: "assistant",
+			Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: text}},
+		})
+	}
+	client.Apply(aria.AriaRead{Committed: committed})
+	tr := newTranscript(ft, 60, 8, ldrender.NodeText{}, client, "aria1234", time.Now())
+	tr.enter()
+	tr.key('g')
+	tr.key('g')
+	for _, b := range []byte("/needle\r") {
+		tr.key(b)
+	}
+	if tr.hits != "needle" {
+		t.Fatalf("hits = %q after submitting a search", tr.hits)
+	}
+	first := tr.offset
+	if !strings.Contains(tr.lines()[first], "needle 4") {
+		t.Fatalf("Enter should land on the first hit, top line %q", tr.lines()[first])
+	}
+	if cur, n := matchPosition(tr.lines(), tr.hits, tr.offset); cur != 1 || n != 3 {
+		t.Fatalf("matchPosition = %d/%d, want 1/3", cur, n)
+	}
+	tr.key('n')
+	if !strings.Contains(tr.lines()[tr.offset], "needle 8") {
+		t.Fatalf("n should step to the next hit, top line %q", tr.lines()[tr.offset])
+	}
+	tr.key('N')
+	if tr.offset != first {
+		t.Fatalf("N should step back to the previous hit")
+	}
+	if scr := strings.Join(ft.Screen(), "\n"); !strings.Contains(scr, "/needle 1/3") {
+		t.Fatalf("match counter missing from the footer:\n%s", scr)
+	}
+	tr.key(0x1b)
+	if tr.hits != "" {
+		t.Fatal("Esc clears the highlighted query")
+	}
+}
+
+func TestTranscriptAlternativeKeys(t *testing.T) {
+	ft := ldrender.NewFakeTerminal(60, 14)
+	tr := newTranscript(ft, 60, 14, ldrender.NodeText{}, aria.NewClient(), "aria1234", time.Now())
+	tr.enter()
+	tr.key(']')
+	if got := tr.takeAlternative(); got != 1 {
+		t.Fatalf("] = %d, want 1", got)
+	}
+	if tr.takeAlternative() != 0 {
+		t.Fatal("a step is handed out once")
+	}
+	tr.key('[')
+	if got := tr.takeAlternative(); got != -1 {
+		t.Fatalf("[ = %d, want -1", got)
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import "fmt"
+
+// Visual mode ('v') selects whole messages: j/k grow or shrink the range a
+// message at a time from the one 'v' started on, and the ordinary selection
+// machinery — 'y' copy, the 'a' action menu — acts on the range as a unit.
+
+// startVisual enters visual mode on the focused message, or with no node
+// selection, on the message nearest the middle of the viewport.
+func (t *transcript) startVisual() {
+	lt := 0
+	if t.selection.active {
+		lt = t.selection.focus.lt
+	} else {
+		t.lines()
+		mid := t.offset + (t.h-2)/2
+		if mid >= len(t.lineLT) {
+			mid = len(t.lineLT) - 1
+		}
+		if mid >= 0 {
+			lt = t.lineLT[mid]
+		}
+	}
+	refs := t.nodeRefs()
+	if len(refs) == 0 {
+		return
+	}
+	lts := messageLTs(refs)
+	at := len(lts) - 1
+	for i, l := range lts {
+		if l >= lt {
+			at = i
+			break
+		}
+	}
+	t.visual = true
+	t.visualLT = lts[at]
+	t.selectMessages(refs, lts[at])
+}
+
+// visualMove steps the moving end of the range by delta messages. Running
+// off the loaded window arms a page fetch, as node selection does.
+func (t *transcript) visualMove(delta int) {
+	refs := t.nodeRefs()
+	lts := messageLTs(refs)
+	at := -1
+	for i, l := range lts {
+		if l == t.selection.focus.lt {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		t.startVisual()
+		return
+	}
+	next := at + delta
+	switch {
+	case next < 0:
+		next = 0
+		t.checkOlder = true
+	case next >= len(lts):
+		next = len(lts) - 1
+		t.checkNewer = true
+	}
+	t.selectMessages(refs, lts[next])
+}
+
+// selectMessages spans the selection from the visual anchor message to the
+// message at lt, whole messages at both ends; the focus sits on the far edge
+// so ensureSelectionVisible follows the moving end.
+func (t *transcript) selectMessages(refs []selectionPoint, lt int) {
+	firstOf := func(lt int) selectionPoint {
+		for _, r := range refs {
+			if r.lt == lt {
+				return r
+			}
+		}
+		return selectionPoint{}
+	}
+	lastOf := func(lt int) selectionPoint {
+		for i := len(refs) - 1; i >= 0; i-- {
+			if refs[i].lt == lt {
+				return refs[i]
+			}
+		}
+		return selectionPoint{}
+	}
+	if lt >= t.visualLT {
+		t.selection.anchor, t.selection.focus = firstOf(t.visualLT), lastOf(lt)
+	} else {
+		t.selection.anchor, t.selection.focus = lastOf(t.visualLT), firstOf(lt)
+	}
+	t.selection.active = t.selection.anchor.valid() && t.selection.focus.valid()
+	t.stopFollowing()
+	t.ensureSelectionVisible()
+}
+
+// visualStatus is the status row while visual mode is on.
+func (t *transcript) visualStatus() string {
+	lo, hi := t.visualLT, t.selection.focus.lt
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	span := fmt.Sprintf("LT %d", lo)
+	if hi != lo {
+		span = fmt.Sprintf("LT %d–%d", lo, hi)
+	}
+	return "-- VISUAL -- " + span + " · j/k extend · y copy · a actions · Esc cancel"
+}
+
+// messageLTs is the distinct LTs of refs, in order.
+func messageLTs(refs []selectionPoint) []int {
+	var lts []int
+	for _, r := range refs {
+		if len(lts) == 0 || lts[len(lts)-1] != r.lt {
+			lts = append(lts, r.lt)
+		}
+	}
+	return lts
+}
@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/livelog/aria"
 )
 
 func TestSessionStatusShowsThinkingAndTerminalOutcomes(t *testing.T) {
@@ -25,3 +28,51 @@ func TestSessionStatusShowsThinkingAndTerminalOutcomes(t *testing.T) {
 		t.Fatalf("error state missing: %q", line)
 	}
 }
+
+func TestSessionStatusCountsStreamedTokensAcrossMessages(t *testing.T) {
+	status := newSessionStatus("aria1234", time.Now())
+	status.beginTurn()
+	status.streaming(3, 400)
+	status.streaming(3, 800) // the open message grew
+	status.streaming(5, 400) // LT 3 closed; a new message opened
+	if line := status.statusLine(120, false); !strings.Contains(line, "~300 tok") || !strings.Contains(line, "thinking") {
+		t.Fatalf("live estimate missing: %q", line)
+	}
+	status.finishTurn("end_turn")
+	status.beginTurn()
+	if line := status.statusLine(120, false); strings.Contains(line, "tok") {
+		t.Fatalf("a new turn must reset the estimate: %q", line)
+	}
+}
+
+func TestSessionStatusShowsModelCostAndLatency(t *testing.T) {
+	defer func(p map[string]config.Price) { modelPrices = p }(modelPrices)
+	modelPrices = map[string]config.Price{"sonnet": {Input: 3, Output: 15}}
+
+	status := newSessionStatus("aria1234", time.Now())
+	status.update(aria.Metrics{Provider: "anthropic", Model: "sonnet", TokensIn: 100_000, TokensOut: 10_000})
+	status.beginTurn()
+	status.streaming(4, 0)
+	if status.latency != 0 {
+		t.Fatal("latency latches on the first streamed text, not the first frame")
+	}
+	status.streaming(4, 12)
+	if status.latency <= 0 {
+		t.Fatal("first streamed text must record the turn's latency")
+	}
+	line := status.statusLine(200, false)
+	for _, want := range []string{"sonnet", "cost 110.0k tok $0.4500", "ttft "} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("status line missing %q: %q", want, line)
+		}
+	}
+	panel := strings.Join(status.panelLines(), "\n")
+	if !strings.Contains(panel, "anthropic · sonnet") || !strings.Contains(panel, "cost      $0.4500") {
+		t.Fatalf("panel missing model or cost:\n%s", panel)
+	}
+
+	status.update(aria.Metrics{Model: "unpriced", TokensIn: 10})
+	if line := status.statusLine(200, false); strings.Contains(line, "$") {
+		t.Fatalf("an unpriced model shows tokens only: %q", line)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/jack-work/figaro/internal/config"
+	"github.com/jack-work/figaro/internal/term"
 	"github.com/jack-work/figaro/internal/update"
 )
 
@@ -132,7 +133,7 @@ func runUpdate(loaded *config.Loaded, args []string) error {
 	}
 	fmt.Printf("  latest:  %s\n", info.Latest)
 	if !info.Available {
-		fmt.Println("  status:  up to date  ✓")
+		fmt.Println("  status:  up to date" + term.Sym("  ✓", ""))
 		return nil
 	}
 	cmd := update.UpgradeCommand(info.Channel, figaroModule, info.Latest)
@@ -30,6 +30,13 @@ type Router struct {
 	// Stderr is the output for help and errors. Defaults to os.Stderr.
 	Stderr io.Writer
 
+	// Text localizes help output: it returns the string for key, or def
+	// when it has none. Keys are "usage", "usage.command", "help.more",
+	// "help.flags", "help.aliases", "error.unknown", "error.suggest",
+	// "group.<Group>" and "cmd.<name>.short|long|flag.<long>". Nil keeps
+	// the defaults.
+	Text func(key, def string) string
+
 	// barePromptComplete is the CompleteArgs callback invoked when
 	// the user is in the bare-prompt form (`<prog> -- <body>`, or an
 	// alias thereof). See SetBarePromptComplete.
@@ -97,11 +104,12 @@ func (r *Router) Run(args []string) int {
 			return 0
 		}
 		// Did-you-mean suggestion.
+		unknown := r.text("error.unknown", "error: unknown command %q")
 		if suggestion := r.suggest(first); suggestion != "" {
-			fmt.Fprintf(r.Stderr, "error: unknown command %q\n", first)
-			fmt.Fprintf(r.Stderr, "  did you mean: %s %s\n\n", r.Name, suggestion)
+			fmt.Fprintf(r.Stderr, unknown+"\n", first)
+			fmt.Fprintf(r.Stderr, "  "+r.text("error.suggest", "did you mean: %s %s")+"\n\n", r.Name, suggestion)
 		} else {
-			fmt.Fprintf(r.Stderr, "error: unknown command %q\n\n", first)
+			fmt.Fprintf(r.Stderr, unknown+"\n\n", first)
 		}
 		r.printUsage()
 		return 2
@@ -314,26 +322,33 @@ func levenshtein(a, b string) int {
 
 // --- Help output ---
 
+func (r *Router) text(key, def string) string {
+	if r.Text == nil {
+		return def
+	}
+	return r.Text(key, def)
+}
+
 func (r *Router) printUsage() {
 	w := r.Stderr
 
-	fmt.Fprintf(w, "Usage: %s <command> [flags] [args]\n\n", r.Name)
+	fmt.Fprintf(w, r.text("usage", "Usage: %s <command> [flags] [args]")+"\n\n", r.Name)
 
 	// Group commands.
 	groups := r.groupedCommands()
 	for _, g := range groups {
-		fmt.Fprintf(w, "%s:\n", g.name)
+		fmt.Fprintf(w, "%s:\n", r.text("group."+g.name, g.name))
 		for _, cmd := range g.commands {
 			name := cmd.Name
 			if cmd.Usage != "" {
 				name = cmd.Usage
 			}
-			fmt.Fprintf(w, "  %-24s %s\n", name, cmd.Short)
+			fmt.Fprintf(w, "  %-24s %s\n", name, r.text("cmd."+cmd.Name+".short", cmd.Short))
 		}
 		fmt.Fprintln(w)
 	}
 
-	fmt.Fprintf(w, "Run '%s <command> --help' for details on a command.\n", r.Name)
+	fmt.Fprintf(w, r.text("help.more", "Run '%s <command> --help' for details on a command.")+"\n", r.Name)
 }
 
 func (r *Router) printCommandHelp(cmd *Command) {
@@ -343,30 +358,30 @@ func (r *Router) printCommandHelp(cmd *Command) {
 	if usage == "" {
 		usage = cmd.Name
 	}
-	fmt.Fprintf(w, "Usage: %s %s\n\n", r.Name, usage)
+	fmt.Fprintf(w, r.text("usage.command", "Usage: %s %s")+"\n\n", r.Name, usage)
 
-	if cmd.Long != "" {
-		fmt.Fprintln(w, cmd.Long)
+	if long := r.text("cmd."+cmd.Name+".long", cmd.Long); long != "" {
+		fmt.Fprintln(w, long)
 		fmt.Fprintln(w)
-	} else if cmd.Short != "" {
-		fmt.Fprintln(w, cmd.Short)
+	} else if short := r.text("cmd."+cmd.Name+".short", cmd.Short); short != "" {
+		fmt.Fprintln(w, short)
 		fmt.Fprintln(w)
 	}
 
 	if len(cmd.Flags) > 0 {
-		fmt.Fprintln(w, "Flags:")
+		fmt.Fprintln(w, r.text("help.flags", "Flags:"))
 		for _, f := range cmd.Flags {
 			short := ""
 			if f.Short != "" {
 				short = "-" + f.Short + ", "
 			}
-			fmt.Fprintf(w, "  %s--%s\t%s\n", short, f.Long, f.Description)
+			fmt.Fprintf(w, "  %s--%s\t%s\n", short, f.Long, r.text("cmd."+cmd.Name+".flag."+f.Long, f.Description))
 		}
 		fmt.Fprintln(w)
 	}
 
 	if len(cmd.Aliases) > 0 {
-		fmt.Fprintf(w, "Aliases: %s\n", strings.Join(cmd.Aliases, ", "))
+		fmt.Fprintf(w, r.text("help.aliases", "Aliases: %s")+"\n", strings.Join(cmd.Aliases, ", "))
 	}
 }
 
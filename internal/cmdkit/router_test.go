@@ -165,6 +165,50 @@ func TestHelpFlag(t *testing.T) {
 	}
 }
 
+func TestHelpText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRouter("test")
+	r.Stderr = buf
+	r.Text = func(key, def string) string {
+		switch key {
+		case "usage.command":
+			return "Uso: %s %s"
+		case "cmd.cmd.long":
+			return "Hace la cosa."
+		case "cmd.cmd.flag.verbose":
+			return "más detalle"
+		}
+		return def
+	}
+	r.Register(&Command{
+		Name:  "cmd",
+		Group: "Tools",
+		Short: "do a thing",
+		Long:  "Does the thing in detail.",
+		Flags: []FlagDef{
+			{Long: "verbose", Short: "v", IsBool: true, Description: "enable verbose"},
+		},
+		Run: func(c *RunContext) error { return nil },
+	})
+
+	r.Run([]string{"cmd", "--help"})
+	out := buf.String()
+	for _, want := range []string{"Uso: test cmd", "Hace la cosa.", "más detalle", "Flags:"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("help missing %q:\n%s", want, out)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte("Does the thing")) {
+		t.Errorf("untranslated long help:\n%s", out)
+	}
+
+	buf.Reset()
+	r.Run(nil)
+	if !bytes.Contains(buf.Bytes(), []byte("Tools:")) || !bytes.Contains(buf.Bytes(), []byte("do a thing")) {
+		t.Errorf("usage lost its defaults:\n%s", buf.String())
+	}
+}
+
 func TestPassRaw(t *testing.T) {
 	var ctx *RunContext
 	r := NewRouter("test")
@@ -64,8 +64,10 @@ type ToolTiming struct {
 // invoke → a tool node folding in its result (or streamed partial). A
 // tool with no result yet is left status=running with whatever output has
 // streamed. argPartials carries the raw, still-truncated tool_use argument
-// JSON per tool_call_id; when execution output hasn't started and the tool
-// declares a preview arg, its live value seeds the node's Output.
+// JSON per tool_call_id. Until the arguments decode it stands in as the
+// node's Summary, so the call shows what it is being written with; when
+// execution output hasn't started and the tool declares a preview arg, its
+// live value also seeds the node's Output.
 func Nodes(msgs []message.Message, partials, argPartials map[string]string, summarize ToolSummary, previewArg ToolPreviewArg, timings ...map[string]ToolTiming) []livedoc.Node {
 	results := indexResults(msgs)
 	var toolTimings map[string]ToolTiming
@@ -103,7 +105,7 @@ func Nodes(msgs []message.Message, partials, argPartials map[string]string, summ
 					continue
 				}
 				nodes = append(nodes, livedoc.Node{ID: nodeID(m.LogicalTime, ci), Type: livedoc.NodeThinking, Markdown: strings.TrimRight(c.Text, "\n")})
-			case message.ContentToolInvoke:
+			case message.ContentToolInvoke, message.ContentServerToolInvoke:
 				nodes = append(nodes, toolNode(c, results, partials, argPartials, summarize, previewArg, toolTimings))
 			}
 		}
@@ -123,6 +125,11 @@ func toolNode(inv message.Content, results map[string]message.Content, partials,
 		Args:    inv.Arguments,
 		Summary: summaryFor(name, inv.Arguments, summarize),
 	}
+	if inv.Arguments == nil {
+		if p := argsPreview(argPartials[inv.ToolCallID]); p != "" {
+			n.Summary = p
+		}
+	}
 	if timing, ok := timings[inv.ToolCallID]; ok {
 		n.StartedAt = timing.StartedAt
 		n.FinishedAt = timing.FinishedAt
@@ -175,6 +182,20 @@ func summaryFor(name string, args map[string]any, summarize ToolSummary) string
 	return strings.Join(parts, " ")
 }
 
+// argsPreviewCap bounds the streaming-arguments Summary, in runes. The
+// header truncates further; past this the JSON stops growing on the wire.
+const argsPreviewCap = 160
+
+// argsPreview is a one-line view of still-streaming argument JSON: runs of
+// whitespace collapse to one space and the head is kept.
+func argsPreview(raw string) string {
+	s := strings.Join(strings.Fields(raw), " ")
+	if r := []rune(s); len(r) > argsPreviewCap {
+		s = string(r[:argsPreviewCap]) + "…"
+	}
+	return s
+}
+
 // tailBound clamps streamed tool output to the last composeBashCap source
 // lines; the full result stays in the canonical Content IR.
 func tailBound(text string) string {
@@ -191,11 +212,13 @@ func tailBound(text string) string {
 // Unit is one committed conversational unit: a user prompt or an
 // assistant turn, as a typed node list. LT is the figwal main-LT of the
 // unit's last message — the coordinate `send`/`fork <trunk>:<LT>` address —
-// so a renderer can label units with the LT a fork would target.
+// so a renderer can label units with the LT a fork would target. Author is
+// the sender of a user prompt, when it named one.
 type Unit struct {
-	Role  string         `json:"role"`
-	Nodes []livedoc.Node `json:"nodes"`
-	LT    uint64         `json:"lt,omitempty"`
+	Role   string         `json:"role"`
+	Author string         `json:"author,omitempty"`
+	Nodes  []livedoc.Node `json:"nodes"`
+	LT     uint64         `json:"lt,omitempty"`
 }
 
 // Units folds a message log into committed conversational units in
@@ -223,7 +246,7 @@ func Units(msgs []message.Message, summarize ToolSummary, previewArg ToolPreview
 		if m.Role == message.RoleUser && !hasToolResult(m) {
 			if txt := messageText(m); txt != "" {
 				flush()
-				units = append(units, Unit{Role: "user", Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: txt}}, LT: m.LogicalTime})
+				units = append(units, Unit{Role: "user", Author: m.Author, Nodes: []livedoc.Node{{Type: livedoc.NodeProse, Markdown: txt}}, LT: m.LogicalTime})
 			}
 		}
 		group = append(group, m)
@@ -259,7 +282,7 @@ func indexResults(msgs []message.Message) map[string]message.Content {
 	out := map[string]message.Content{}
 	for _, m := range msgs {
 		for _, c := range m.Content {
-			if c.Type == message.ContentToolResult && c.ToolCallID != "" {
+			if (c.Type == message.ContentToolResult || c.Type == message.ContentServerToolResult) && c.ToolCallID != "" {
 				out[c.ToolCallID] = c
 			}
 		}
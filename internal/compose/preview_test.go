@@ -1,7 +1,9 @@
 package compose
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/livelog/aria"
@@ -95,8 +97,8 @@ func TestNodes_WriteContentPreview_ResultReplacesPreview(t *testing.T) {
 	}
 }
 
-// Without a PreviewArg lookup, a running tool with only arg-partials shows
-// nothing (previous behavior — arg stream is silently dropped).
+// Without a PreviewArg lookup, a running tool with only arg-partials gets
+// no arg-derived Output; the raw JSON shows in its Summary instead.
 func TestNodes_NoPreviewArg_NoLeak(t *testing.T) {
 	inv := message.Content{Type: message.ContentToolInvoke, ToolCallID: "b1", ToolName: "bash"}
 	msg := message.Message{Role: message.RoleAssistant, LogicalTime: 1, Content: []message.Content{inv}}
@@ -106,3 +108,53 @@ func TestNodes_NoPreviewArg_NoLeak(t *testing.T) {
 		t.Fatalf("no preview-arg fn ⇒ no arg-derived output: %+v", nodes)
 	}
 }
+
+// While a tool_use block streams, the accumulating argument JSON reaches
+// the client as the node's Summary; once the arguments decode, the
+// summarizer's line replaces it.
+func TestNodes_StreamingArgsSummary(t *testing.T) {
+	srv := aria.NewServer()
+	cli := aria.NewClient()
+	srv.Subscribe(func(r aria.AriaRead) { cli.Apply(r) })
+	srv.Open(1, "assistant")
+	summarize := func(name string, args map[string]any) string {
+		cmd, _ := args["command"].(string)
+		return "$ " + cmd
+	}
+
+	inv := message.Content{Type: message.ContentToolInvoke, ToolCallID: "b1", ToolName: "bash"}
+	msg := message.Message{Role: message.RoleAssistant, LogicalTime: 1, Content: []message.Content{inv}}
+	argPartials := map[string]string{}
+	var seen []string
+	for _, frame := range []string{`{"comm`, `{"command": "ls`, "{\"command\": \"ls -la\"}"} {
+		argPartials["b1"] = frame
+		srv.Update(Nodes([]message.Message{msg}, nil, argPartials, summarize, nil))
+		seen = append(seen, findTool(cli.View().Open.Nodes).Summary)
+	}
+	want := []string{`{"comm`, `{"command": "ls`, `{"command": "ls -la"}`}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("frame %d summary = %q, want %q", i, seen[i], want[i])
+		}
+	}
+
+	msg.Content[0].Arguments = map[string]any{"command": "ls -la"}
+	srv.Update(Nodes([]message.Message{msg}, nil, argPartials, summarize, nil))
+	if got := findTool(cli.View().Open.Nodes).Summary; got != "$ ls -la" {
+		t.Errorf("decoded summary = %q", got)
+	}
+}
+
+func TestArgsPreview(t *testing.T) {
+	if got := argsPreview("{\n  \"a\":\t1,\n  \"b\""); got != `{ "a": 1, "b"` {
+		t.Errorf("whitespace not collapsed: %q", got)
+	}
+	long := `{"content":"` + strings.Repeat("é", 300)
+	got := argsPreview(long)
+	if n := utf8.RuneCountInString(got); n != argsPreviewCap+1 || !strings.HasSuffix(got, "…") || !utf8.ValidString(got) {
+		t.Errorf("clamp = %d runes, valid %v", n, utf8.ValidString(got))
+	}
+	if argsPreview("") != "" {
+		t.Error("empty input should give no preview")
+	}
+}
@@ -126,3 +126,12 @@ func TestUnits_SteeringFoldsIntoTurn(t *testing.T) {
 		t.Fatalf("tool node should fold its result: %q", a.Nodes[1].Output)
 	}
 }
+
+func TestUnits_CarriesAuthor(t *testing.T) {
+	shared := userPrompt("from alice")
+	shared.Author = "alice"
+	units := Units([]message.Message{shared, assistant(message.TextContent("hi")), userPrompt("mine")}, nil, nil)
+	if len(units) != 3 || units[0].Author != "alice" || units[1].Author != "" || units[2].Author != "" {
+		t.Fatalf("authors: %+v", units)
+	}
+}
@@ -51,9 +51,383 @@ type Config struct {
 	// only for testing.
 	UpdateCheckTTLHours *int `toml:"update_check_ttl_hours"`
 
+	// AutoTitle asks the model for a 5–8 word mantra once a new
+	// conversation's first exchange completes, replacing the opener-seeded
+	// one. Costs one extra one-shot request per conversation. Default false.
+	AutoTitle *bool `toml:"auto_title"`
+
+	// ScratchDays is how long one-off conversations (send -e, plain
+	// without an aria) are kept under scratch for figaro keep. 0 stops
+	// saving them. Default 7.
+	ScratchDays *int `toml:"scratch_days"`
+
 	// RefSigil is the prefix character for chalkboard references in
 	// prompts and tab completion. Must be "@" or ":". Default "@".
 	RefSigil string `toml:"ref_sigil"`
+
+	// TimeFormat is the Go time layout for wall-clock times in the live
+	// views (status line, pager). Default "15:04:05".
+	TimeFormat string `toml:"time_format"`
+
+	// RelativeTime shows ages ("3m ago") instead of clock times; 't' in
+	// the pager toggles it per session. Default false.
+	RelativeTime *bool `toml:"relative_time"`
+
+	// Author is the name your prompts carry, so other clients attached
+	// to the same aria can tell who sent them. $FIGARO_AUTHOR overrides
+	// it; default the login name.
+	Author string `toml:"author"`
+
+	// Locale is the language of help and the pager ("en", "es"). Empty
+	// follows $LC_ALL, $LC_MESSAGES and $LANG; a language without a
+	// catalog falls back to English.
+	Locale string `toml:"locale"`
+
+	// ReplyLang is the language the model answers in ("French", "ja"),
+	// whatever the prompt's language, for arias without their own
+	// system.reply_lang. Empty leaves it to the model.
+	ReplyLang string `toml:"reply_lang"`
+
+	// Personas are named presets a send picks with --persona
+	// ([personas.<name>] tables): system prompt, model, temperature and
+	// tool set.
+	Personas map[string]Persona `toml:"personas"`
+
+	// Theme styles the terminal renderers ([theme] table).
+	Theme Theme `toml:"theme"`
+
+	// Keys rebinds the pager ([keys] table): action name → space-separated
+	// keys, e.g. down = "j ctrl-e". Unlisted actions keep their defaults.
+	Keys map[string]string `toml:"keys"`
+
+	// Prices turns token counts into a session cost in the status line
+	// ([prices] table, keyed by model id). Models without an entry show
+	// tokens only.
+	Prices map[string]Price `toml:"prices"`
+
+	// Notify announces a finished turn that ran long ([notify] table).
+	Notify Notify `toml:"notify"`
+
+	// Sinks are named HTTP endpoints that finished answers are POSTed to
+	// ([sinks.<name>] tables). An aria opts in by naming one in its
+	// system.sink chalkboard key.
+	Sinks map[string]Sink `toml:"sinks"`
+
+	// Middleware wraps every provider the daemon builds ([middleware]
+	// table).
+	Middleware Middleware `toml:"middleware"`
+
+	// Guard scans prompts for secrets before they reach a provider and
+	// checks the model's tool calls and answers ([guard] table).
+	Guard Guard `toml:"guard"`
+
+	// Audit keeps a hash-chained log of provider requests, prompts, tool
+	// calls and guard decisions ([audit] table).
+	Audit Audit `toml:"audit"`
+
+	// Budget caps token and dollar spend across all arias ([budget]
+	// table).
+	Budget Budget `toml:"budget"`
+
+	// Sync controls the Git-synced aria store ([sync] table).
+	Sync Sync `toml:"sync"`
+
+	// Mirrors are encrypted off-machine copies of the config and aria
+	// store, one per storage target ([mirrors.<name>] tables).
+	Mirrors map[string]Mirror `toml:"mirrors"`
+
+	// Share serves read-only pages of shared arias from the daemon
+	// ([share] table).
+	Share Share `toml:"share"`
+
+	// Vars controls {{...}} substitution in prompts ([vars] table).
+	Vars Vars `toml:"vars"`
+
+	// Accessibility is screen-reader output ([accessibility] table).
+	Accessibility Accessibility `toml:"accessibility"`
+
+	// Network is how providers reach their APIs ([network] table).
+	Network Network `toml:"network"`
+
+	// Limits caps content on its way to and from the model ([limits]
+	// table).
+	Limits Limits `toml:"limits"`
+}
+
+// Vars is the [vars] table.
+type Vars struct {
+	// Shell allows {{shell "command"}} in prompts. Off by default.
+	Shell bool `toml:"shell"`
+}
+
+// Accessibility is the [accessibility] table.
+type Accessibility struct {
+	// Enabled is --plain-accessible on every command: no color, no
+	// box-drawing, and words in place of status glyphs and role icons.
+	Enabled bool `toml:"enabled"`
+
+	// UserLabel and AssistantLabel are the role headers in this mode.
+	// Default "You:" / "Figaro:".
+	UserLabel      string `toml:"user_label"`
+	AssistantLabel string `toml:"assistant_label"`
+}
+
+// Limits is the [limits] table. Trimmed content keeps its head and tail
+// around a marker, and each cut is reported.
+type Limits struct {
+	// ToolResultBytes caps one tool result in the next request. Default
+	// 256 KiB; 0 turns it off.
+	ToolResultBytes *int `toml:"tool_result_bytes"`
+
+	// AttachmentBytes caps text attached to a prompt (send --paste).
+	// Default 1 MiB; 0 turns it off.
+	AttachmentBytes *int `toml:"attachment_bytes"`
+
+	// ResponseTokens caps max_tokens for every answer, whatever the
+	// loadout or system.max_tokens asks for. 0 (default) leaves it.
+	ResponseTokens int `toml:"response_tokens"`
+
+	// SummaryModel names a cheap model that digests a tool result past
+	// SummarizeTokens; the full result is kept on disk and its path put in
+	// the digest. Empty (default) trims instead.
+	SummaryModel string `toml:"summary_model"`
+
+	// SummaryProvider runs SummaryModel. Default: the default loadout's
+	// provider.
+	SummaryProvider string `toml:"summary_provider"`
+
+	// SummarizeTokens is the estimated size past which a tool result is
+	// summarized. Default 8000.
+	SummarizeTokens int `toml:"summarize_tokens"`
+
+	// StreamSpillBytes is the size past which a streaming answer block
+	// goes to a file under the data dir and the live views keep only its
+	// tail. Default 1 MiB; 0 turns it off.
+	StreamSpillBytes *int `toml:"stream_spill_bytes"`
+}
+
+// Network is the [network] table.
+type Network struct {
+	// Proxy is the proxy URL for provider and login requests, e.g.
+	// "http://proxy.corp:3128". Empty follows HTTPS_PROXY and HTTP_PROXY.
+	Proxy string `toml:"proxy"`
+
+	// NoProxy lists hosts reached directly, in NO_PROXY syntax:
+	// "internal.corp", ".corp", "10.0.0.0/8", "localhost:8080".
+	// Empty follows NO_PROXY.
+	NoProxy []string `toml:"no_proxy"`
+
+	// CABundle is a PEM file of extra root certificates, for a proxy or
+	// gateway that re-signs TLS.
+	CABundle string `toml:"ca_bundle"`
+}
+
+// Share is the [share] table.
+type Share struct {
+	// Listen is the address the daemon serves share pages on, e.g.
+	// "127.0.0.1:8750". Empty serves nothing.
+	Listen string `toml:"listen"`
+
+	// BaseURL prefixes printed share links, for when the pages sit behind
+	// a proxy. Default http://<listen>.
+	BaseURL string `toml:"base_url"`
+}
+
+// Mirror is one [mirrors.<name>] table.
+type Mirror struct {
+	// Kind is "s3" (default), "gcs", "webdav" or "dir".
+	Kind string `toml:"kind"`
+
+	// URL is the bucket and prefix (s3, gcs; path style), the collection
+	// (webdav) or the directory (dir).
+	URL string `toml:"url"`
+
+	// Region signs s3 requests. Default us-east-1; gcs always uses auto.
+	Region string `toml:"region"`
+
+	// IDEnv and SecretEnv name the environment variables holding the
+	// access key pair (s3, gcs) or the user and password (webdav).
+	// Defaults: AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY,
+	// GCS_HMAC_ID/GCS_HMAC_SECRET, FIGARO_WEBDAV_USER/FIGARO_WEBDAV_PASSWORD.
+	IDEnv     string `toml:"id_env"`
+	SecretEnv string `toml:"secret_env"`
+
+	// KeyFile holds the encryption key. Default
+	// <config>/mirrors/<name>.key, created by the first push.
+	KeyFile string `toml:"key_file"`
+
+	// Every makes the daemon push on this interval, e.g. "30m". Empty
+	// pushes only on figaro mirror push.
+	Every string `toml:"every"`
+}
+
+// Sync is the [sync] table. It has effect once figaro sync init has
+// made the aria store a repository.
+type Sync struct {
+	// AutoCommit commits the store after every finished turn. Default
+	// true.
+	AutoCommit *bool `toml:"auto_commit"`
+}
+
+// Budget is the [budget] table. Each cap is off at zero. Dollar caps
+// price tokens at [prices]; a model without a price counts toward the
+// token caps only.
+type Budget struct {
+	DailyTokens   int     `toml:"daily_tokens"`
+	MonthlyTokens int     `toml:"monthly_tokens"`
+	DailyUSD      float64 `toml:"daily_usd"`
+	MonthlyUSD    float64 `toml:"monthly_usd"`
+
+	// Path is the spend ledger. Default <state>/usage.json.
+	Path string `toml:"path"`
+}
+
+// Audit is the [audit] table.
+type Audit struct {
+	// Enabled turns the daemon's audit log on. Default false.
+	Enabled bool `toml:"enabled"`
+
+	// Path is the log file. Default <state>/audit.jsonl.
+	Path string `toml:"path"`
+}
+
+// Guard is the [guard] table. An aria overrides Mode with its
+// system.guard chalkboard key.
+type Guard struct {
+	// Mode is "off" (default), "block" (reject the prompt) or "mask"
+	// (send it with each finding replaced by a placeholder).
+	Mode string `toml:"mode"`
+
+	// Emails also flags email addresses. Default false.
+	Emails bool `toml:"emails"`
+
+	// Patterns adds regexes to flag, keyed by the name a finding reports.
+	Patterns map[string]string `toml:"patterns"`
+
+	// Destructive blocks bash calls that destroy data or the machine:
+	// rm -rf /, mkfs, dd to a device, fork bombs, force pushes, shutdown.
+	// Default false.
+	Destructive bool `toml:"destructive"`
+
+	// Rules check model output ([[guard.rules]] tables).
+	Rules []GuardRule `toml:"rules"`
+}
+
+// GuardRule is one [[guard.rules]] table.
+type GuardRule struct {
+	// Name identifies the rule in violations. Required.
+	Name string `toml:"name"`
+
+	// Stage is "tool" (default; a call's string arguments, before it
+	// runs) or "answer" (a finished turn's prose).
+	Stage string `toml:"stage"`
+
+	// Tool limits a tool rule to one tool, e.g. "bash". Empty = all.
+	Tool string `toml:"tool"`
+
+	// Pattern is the regex that trips the rule. Required.
+	Pattern string `toml:"pattern"`
+
+	// Action is "block" (default for tool rules; the call does not run)
+	// or "warn" (report only). Answer rules always warn.
+	Action string `toml:"action"`
+}
+
+// Middleware is the [middleware] table.
+type Middleware struct {
+	// Chain names the middlewares to apply, outermost first:
+	// "rate_limit", "audit". Empty applies none.
+	Chain []string `toml:"chain"`
+
+	// RatePerMinute caps provider requests across all arias. Required
+	// by rate_limit.
+	RatePerMinute int `toml:"rate_per_minute"`
+
+	// AuditPath is audit's JSON-lines file. Default
+	// <state>/provider-audit.jsonl.
+	AuditPath string `toml:"audit_path"`
+}
+
+// Persona is one [personas.<name>] table. A credo left out falls back to
+// the default loadout's; a temperature or tools left out is cleared; a
+// model left out keeps the aria's.
+type Persona struct {
+	// Credo is the system prompt (system.credo).
+	Credo string `toml:"credo"`
+
+	// Model is the model id (system.model).
+	Model string `toml:"model"`
+
+	// Temperature is system.temperature.
+	Temperature *float64 `toml:"temperature"`
+
+	// Tools are the only tools offered (system.tools). Left out offers
+	// every tool; an empty list is an error.
+	Tools []string `toml:"tools"`
+}
+
+// Sink is one [sinks.<name>] table.
+type Sink struct {
+	// URL receives the POST. Required.
+	URL string `toml:"url"`
+
+	// Kind is "webhook" (default) or "slack" (an incoming-webhook URL).
+	Kind string `toml:"kind"`
+
+	// Format is the webhook body: "json" (default; aria, reason and
+	// markdown fields) or "markdown" (the answer alone). Slack sinks
+	// always send {"text": ...}.
+	Format string `toml:"format"`
+
+	// Retries is how many times a failed POST is retried, with
+	// exponential backoff from one second. Default 3.
+	Retries *int `toml:"retries"`
+}
+
+// Notify is the [notify] table. Nothing is announced until after_seconds
+// is set.
+type Notify struct {
+	// AfterSeconds is how long a turn must run before its end is
+	// announced. Unset or 0 disables notifications.
+	AfterSeconds int `toml:"after_seconds"`
+
+	// Bell rings the terminal bell. Default true.
+	Bell *bool `toml:"bell"`
+
+	// Desktop also posts a desktop notification (notify-send on Linux,
+	// osascript on macOS). Default false.
+	Desktop bool `toml:"desktop"`
+}
+
+// Price is one model's rate in dollars per million tokens.
+type Price struct {
+	Input      float64 `toml:"input"`
+	Output     float64 `toml:"output"`
+	CacheRead  float64 `toml:"cache_read"`
+	CacheWrite float64 `toml:"cache_write"`
+}
+
+// Theme is the [theme] table. Every field is optional; empty keeps the
+// built-in look.
+type Theme struct {
+	// Markdown is the glamour style for prose: "dark" (default), "light",
+	// "notty", another standard glamour style, or a path to a glamour
+	// JSON style file.
+	Markdown string `toml:"markdown"`
+
+	// Code is the chroma style for fenced code blocks ("monokai",
+	// "github", "dracula", ...). Default: the markdown style's own colors.
+	Code string `toml:"code"`
+
+	// Accent colors the role header, tool names, spinners and the pager's
+	// selection gutter: a color name, a 0–255 palette index, or #rrggbb.
+	// Default cyan.
+	Accent string `toml:"accent"`
+
+	// UserLabel and AssistantLabel replace the "❯ you" / "‹ figaro" role
+	// headers.
+	UserLabel      string `toml:"user_label"`
+	AssistantLabel string `toml:"assistant_label"`
 }
 
 // EchoPrompt returns whether to echo the prompt. Default true.
@@ -120,6 +494,12 @@ func (l *Loaded) CheckUpdates() bool {
 	return *l.Config.CheckUpdates
 }
 
+// SyncAutoCommit returns whether the daemon commits a synced store
+// after each turn. Default true.
+func (l *Loaded) SyncAutoCommit() bool {
+	return l.Config.Sync.AutoCommit == nil || *l.Config.Sync.AutoCommit
+}
+
 // UpdateCheckTTLHours returns the update-check cache TTL. Default 24h.
 func (l *Loaded) UpdateCheckTTLHours() int {
 	if l.Config.UpdateCheckTTLHours == nil {
@@ -128,6 +508,75 @@ func (l *Loaded) UpdateCheckTTLHours() int {
 	return *l.Config.UpdateCheckTTLHours
 }
 
+// AutoTitle returns whether new conversations get a model-written
+// mantra after their first exchange. Default false.
+func (l *Loaded) AutoTitle() bool {
+	return l.Config.AutoTitle != nil && *l.Config.AutoTitle
+}
+
+// ScratchDays returns the one-off conversation retention in days; 0
+// means they are not kept. Default 7.
+func (l *Loaded) ScratchDays() int {
+	if l.Config.ScratchDays == nil {
+		return 7
+	}
+	return max(*l.Config.ScratchDays, 0)
+}
+
+// ToolResultBytes returns [limits] tool_result_bytes; default 256 KiB,
+// 0 for no cap.
+func (l *Loaded) ToolResultBytes() int {
+	if l.Config.Limits.ToolResultBytes == nil {
+		return 256 << 10
+	}
+	return max(*l.Config.Limits.ToolResultBytes, 0)
+}
+
+// AttachmentBytes returns [limits] attachment_bytes; default 1 MiB, 0 for
+// no cap.
+func (l *Loaded) AttachmentBytes() int {
+	if l.Config.Limits.AttachmentBytes == nil {
+		return 1 << 20
+	}
+	return max(*l.Config.Limits.AttachmentBytes, 0)
+}
+
+// StreamSpillBytes returns [limits] stream_spill_bytes; default 1 MiB, 0
+// for never.
+func (l *Loaded) StreamSpillBytes() int {
+	if l.Config.Limits.StreamSpillBytes == nil {
+		return 1 << 20
+	}
+	return max(*l.Config.Limits.StreamSpillBytes, 0)
+}
+
+// SummarizeTokens returns the tool-result size, in estimated tokens, past
+// which [limits] summary_model digests it. 0 when no model is set.
+func (l *Loaded) SummarizeTokens() int {
+	switch {
+	case l.Config.Limits.SummaryModel == "":
+		return 0
+	case l.Config.Limits.SummarizeTokens <= 0:
+		return 8000
+	}
+	return l.Config.Limits.SummarizeTokens
+}
+
+// TimeFormat returns the clock layout for the live views. Default
+// "15:04:05".
+func (l *Loaded) TimeFormat() string {
+	if l.Config.TimeFormat == "" {
+		return "15:04:05"
+	}
+	return l.Config.TimeFormat
+}
+
+// RelativeTime returns whether the live views start with relative ages
+// instead of clock times. Default false.
+func (l *Loaded) RelativeTime() bool {
+	return l.Config.RelativeTime != nil && *l.Config.RelativeTime
+}
+
 // ProviderAuth holds credentials for one provider. The on-disk file
 // lives at providers/<name>.toml (flat — no per-provider subdirectory).
 // Secret fields are AGE-encrypted at rest; callers must decrypt
@@ -225,7 +674,8 @@ func (l *Loaded) LoadProviderAuth(name string, target interface{}) error {
 	return nil
 }
 
-// DefaultConfigDir returns the config directory (XDG-aware).
+// DefaultConfigDir returns the config directory (XDG-aware; the roaming
+// AppData profile on Windows).
 func DefaultConfigDir() string {
 	// FIGARO_CONFIG_DIR is an explicit override used as-is (no
 	// "figaro" suffix appended) — lets dev shells point at an
@@ -233,11 +683,27 @@ func DefaultConfigDir() string {
 	if d := os.Getenv("FIGARO_CONFIG_DIR"); d != "" {
 		return d
 	}
-	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+	return userDir("XDG_CONFIG_HOME", "config")
+}
+
+// DefaultStateDir is where figaro keeps state: $XDG_STATE_HOME/figaro, else
+// ~/.local/state/figaro (%LOCALAPPDATA%\figaro\state on Windows).
+func DefaultStateDir() string { return userDir("XDG_STATE_HOME", "state") }
+
+// DefaultCacheDir is where figaro keeps data it can regenerate:
+// $XDG_CACHE_HOME/figaro, else ~/.cache/figaro (%LOCALAPPDATA%\figaro\cache
+// on Windows).
+func DefaultCacheDir() string { return userDir("XDG_CACHE_HOME", "cache") }
+
+// DefaultDataDir is where figaro keeps conversations: $XDG_DATA_HOME/figaro,
+// else ~/.local/share/figaro (%LOCALAPPDATA%\figaro\data on Windows).
+func DefaultDataDir() string { return userDir("XDG_DATA_HOME", "data") }
+
+func userDir(xdgVar, kind string) string {
+	if d := os.Getenv(xdgVar); d != "" {
 		return filepath.Join(d, "figaro")
 	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".config", "figaro")
+	return platformDir(kind)
 }
 
 // Load reads the top-level config. Returns defaults if missing.
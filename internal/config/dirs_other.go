@@ -0,0 +1,23 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// platformDir is figaro's per-user directory of one kind when no XDG
+// variable names it: the XDG default under the home directory.
+func platformDir(kind string) string {
+	home, _ := os.UserHomeDir()
+	switch kind {
+	case "config":
+		return filepath.Join(home, ".config", "figaro")
+	case "state":
+		return filepath.Join(home, ".local", "state", "figaro")
+	case "data":
+		return filepath.Join(home, ".local", "share", "figaro")
+	}
+	return filepath.Join(home, ".cache", "figaro")
+}
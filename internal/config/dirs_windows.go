@@ -0,0 +1,22 @@
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// platformDir is figaro's per-user directory of one kind when no XDG
+// variable names it: the roaming profile for config, the local one for
+// state and cache.
+func platformDir(kind string) string {
+	if kind == "config" {
+		if d, err := os.UserConfigDir(); err == nil {
+			return filepath.Join(d, "figaro")
+		}
+	} else if d, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(d, "figaro", kind)
+	}
+	return filepath.Join(os.TempDir(), "figaro", kind)
+}
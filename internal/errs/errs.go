@@ -0,0 +1,142 @@
+// Package errs classifies the failures a user can act on: a rejected
+// credential, a rate limit, a conversation too long for the model, a spent
+// budget. Providers and the daemon return an *Error with a Kind instead of
+// the raw response text; the CLI maps the Kind to a hint.
+package errs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Kind names a class of failure. The zero Kind is unclassified.
+type Kind string
+
+const (
+	Auth          Kind = "auth"           // credential missing, expired or rejected
+	RateLimit     Kind = "rate_limit"     // the provider is throttling requests
+	Overloaded    Kind = "overloaded"     // the provider is at capacity
+	ContextLength Kind = "context_length" // the prompt exceeds the model's window
+	Network       Kind = "network"        // the provider could not be reached
+	Budget        Kind = "budget"         // a [budget] limit was reached
+	Blocked       Kind = "blocked"        // the prompt guard refused the prompt
+)
+
+// Error is a classified failure. Msg is the one line to show; Err, when
+// set, is the cause and supplies the message if Msg is empty.
+type Error struct {
+	Kind   Kind
+	Status int // HTTP status, when the failure was a response
+	Msg    string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Msg != "":
+		return e.Msg
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return string(e.Kind)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an *Error of kind with a formatted message.
+func New(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Wrap classifies err as kind, keeping its message; nil stays nil.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf is the Kind of the first *Error in err's chain. Failing that, a
+// network error is Network; anything else is unclassified.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	if errors.Is(err, context.Canceled) {
+		return ""
+	}
+	var ne net.Error
+	var oe *net.OpError
+	var de *net.DNSError
+	if errors.As(err, &oe) || errors.As(err, &de) || errors.As(err, &ne) && ne.Timeout() {
+		return Network
+	}
+	return ""
+}
+
+// HTTP classifies a provider's non-2xx response. The message is the
+// provider's own error text when the body is a JSON error object, else
+// the body, trimmed.
+func HTTP(provider string, status int, body []byte) error {
+	typ, msg := apiError(body)
+	e := &Error{Kind: httpKind(status, typ, msg), Status: status}
+	e.Msg = fmt.Sprintf("%s: %s (%d)", provider, msg, status)
+	if msg == "" {
+		e.Msg = fmt.Sprintf("%s: %s (%d)", provider, http.StatusText(status), status)
+	}
+	return e
+}
+
+// apiError reads the error type and message from the shapes providers
+// use: {"error":{"type","message"}} (Anthropic) and
+// {"error":{"code","message"}} (OpenAI-style, Copilot).
+func apiError(body []byte) (typ, msg string) {
+	var v struct {
+		Error struct {
+			Type    string `json:"type"`
+			Code    any    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &v) == nil {
+		typ = v.Error.Type
+		if code, ok := v.Error.Code.(string); ok && typ == "" {
+			typ = code
+		}
+		if msg = v.Error.Message; msg == "" {
+			msg = v.Message
+		}
+		if msg != "" {
+			return typ, msg
+		}
+	}
+	msg = strings.Join(strings.Fields(string(body)), " ")
+	if len(msg) > 300 {
+		msg = msg[:300] + "…"
+	}
+	return typ, msg
+}
+
+func httpKind(status int, typ, msg string) Kind {
+	lower := strings.ToLower(msg)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		typ == "authentication_error" || typ == "permission_error":
+		return Auth
+	case status == http.StatusTooManyRequests || typ == "rate_limit_error":
+		return RateLimit
+	case status == 529 || status == http.StatusServiceUnavailable || typ == "overloaded_error":
+		return Overloaded
+	case typ == "context_length_exceeded" || strings.Contains(lower, "prompt is too long") ||
+		strings.Contains(lower, "context length") || strings.Contains(lower, "context window"):
+		return ContextLength
+	}
+	return ""
+}
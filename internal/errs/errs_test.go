@@ -0,0 +1,71 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestHTTP(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		kind   Kind
+		msg    string
+	}{
+		{"unauthorized", 401, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, Auth, "anthropic: invalid x-api-key (401)"},
+		{"permission type", 400, `{"error":{"type":"permission_error","message":"no access"}}`, Auth, "anthropic: no access (400)"},
+		{"rate limit", 429, `{"error":{"type":"rate_limit_error","message":"slow down"}}`, RateLimit, "anthropic: slow down (429)"},
+		{"overloaded", 529, `{"error":{"type":"overloaded_error","message":"Overloaded"}}`, Overloaded, "anthropic: Overloaded (529)"},
+		{"prompt too long", 400, `{"error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, ContextLength, "anthropic: prompt is too long: 210000 tokens > 200000 maximum (400)"},
+		{"openai code", 400, `{"error":{"code":"context_length_exceeded","message":"too many tokens"}}`, ContextLength, "anthropic: too many tokens (400)"},
+		{"plain body", 500, "upstream\n  failed", "", "anthropic: upstream failed (500)"},
+		{"empty body", 502, "", "", "anthropic: Bad Gateway (502)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := HTTP("anthropic", tc.status, []byte(tc.body))
+			if got := KindOf(err); got != tc.kind {
+				t.Errorf("kind = %q, want %q", got, tc.kind)
+			}
+			if err.Error() != tc.msg {
+				t.Errorf("msg = %q, want %q", err.Error(), tc.msg)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	wrapped := fmt.Errorf("giving up: %w", New(RateLimit, "slow down"))
+	if got := KindOf(wrapped); got != RateLimit {
+		t.Errorf("wrapped = %q", got)
+	}
+	if got := KindOf(&net.OpError{Op: "dial", Err: errors.New("connection refused")}); got != Network {
+		t.Errorf("dial = %q", got)
+	}
+	if got := KindOf(fmt.Errorf("http: %w", &net.DNSError{Err: "no such host", Name: "api.example"})); got != Network {
+		t.Errorf("dns = %q", got)
+	}
+	if got := KindOf(context.Canceled); got != "" {
+		t.Errorf("canceled = %q", got)
+	}
+	if got := KindOf(errors.New("boom")); got != "" {
+		t.Errorf("plain = %q", got)
+	}
+	if got := KindOf(nil); got != "" {
+		t.Errorf("nil = %q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(Auth, nil) != nil {
+		t.Error("Wrap(nil) is not nil")
+	}
+	cause := errors.New("no credential available")
+	err := Wrap(Auth, cause)
+	if err.Error() != cause.Error() || !errors.Is(err, cause) {
+		t.Errorf("Wrap lost the cause: %v", err)
+	}
+}
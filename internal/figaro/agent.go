@@ -2,10 +2,12 @@ package figaro
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,10 +15,12 @@ import (
 
 	"github.com/jack-work/figaro/internal/chalkboard"
 	"github.com/jack-work/figaro/internal/compose"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	"github.com/jack-work/figaro/internal/message"
 	figOtel "github.com/jack-work/figaro/internal/otel"
 	"github.com/jack-work/figaro/internal/outfit"
+	"github.com/jack-work/figaro/internal/provenance"
 	"github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/store"
@@ -40,6 +44,8 @@ type event struct {
 	// eventUserPrompt
 	text       string
 	chalkboard *rpc.ChalkboardInput
+	force      bool
+	author     string
 
 	// eventSet
 	setPatch message.Patch
@@ -73,9 +79,61 @@ type Config struct {
 	// have no channel, so this patch is folded onto the first IR turn so
 	// the loadout reminders still render. Ignored when Backend != nil.
 	InlineBoot *chalkboard.Patch
+
+	// PromptGuard vets each prompt's text before it is queued. nil = none.
+	PromptGuard PromptGuard
+
+	// ResponseGuard checks model output: tool calls before they run and
+	// each finished turn's answer. nil = none.
+	ResponseGuard ResponseGuard
+
+	// Budget is asked before each prompt is queued and again, with the
+	// request's input size, before the turn's first provider call.
+	// nil = no limits.
+	Budget Budget
+
+	// Signer supplies the key that signs an author's prompts. nil = no
+	// signing.
+	Signer Signer
+
+	// Limits caps tool results and answers. Zero fields are off.
+	Limits Limits
+
+	// ReplyLang is the language turns are answered in when the aria's
+	// chalkboard sets no system.reply_lang. "" leaves it to the model.
+	ReplyLang string
 }
 
+// PromptGuard checks outbound prompt text against the aria's chalkboard.
+// It returns the text to send — possibly masked — or an error rejecting
+// the prompt.
+type PromptGuard func(ariaID, text string, snapshot chalkboard.Snapshot) (string, error)
+
+// ResponseGuard checks model output at a guard stage: "tool", where tool
+// names the call and text is its string arguments, or "answer", where text
+// is the turn's prose. A Blocked violation keeps the tool call from running.
+type ResponseGuard func(ariaID, stage, tool, text string) []rpc.GuardViolation
+
+// Budget rejects a prompt once spend has reached a configured limit, or
+// would pass one with the next request's inputTokens (0 when not yet
+// known), unless the caller forces it. A turn already under way is not
+// cut short.
+type Budget func(ariaID string, inputTokens int, force bool) error
+
+// Signer returns the key to sign author's prompts with, or nil to leave
+// them unsigned.
+type Signer func(author string) ed25519.PrivateKey
+
 // Agent is the Figaro implementation.
+//
+// Concurrency: every exported method is safe to call from any goroutine.
+// Prompts are not run by the caller — SubmitPrompt only enqueues on the
+// inbox, and the single drain goroutine runs turns one at a time and owns
+// the turn and live-render state below. mu guards what other goroutines
+// read or signal: subscribers, the turn cancel, and the metrics fields.
+// The log, chalkboard and aria server lock themselves. Notifiers are
+// called synchronously under mu's read lock, so Notify must not block or
+// call back into the agent.
 type Agent struct {
 	id          string
 	socketPath  string
@@ -84,6 +142,16 @@ type Agent struct {
 	tools       *tool.Registry
 	summarize   compose.ToolSummary
 	previewArg  compose.ToolPreviewArg
+	guard       PromptGuard
+	respGuard   ResponseGuard
+	budget      Budget
+	signer      Signer
+	limits      Limits
+	replyLang   string // default system.reply_lang for turns
+	// chainHead is the provenance chain through the first chainN log
+	// entries, extended as prompts are signed. Actor-owned.
+	chainN      int
+	chainHead   provenance.Hash
 	inlineBoot *chalkboard.Patch // ephemeral first-turn boot fold
 	figLog     store.Log[message.Message]
 	backend    store.Backend // nil = ephemeral
@@ -111,6 +179,9 @@ type Agent struct {
 	argPartials map[string]string
 	toolTimings map[string]compose.ToolTiming
 	turn        *turnState
+	turnForce   bool      // the turn's prompt passed --force
+	turnFrom    uint64    // the log's tail LT before the turn's prompt, for turn.done
+	turnErrKind errs.Kind // the class of the error ending the turn, for turn.done
 
 	// ariaSrv is the rendered conversation (committed units + the open one),
 	// the single source of the aria-read wire: it serves both the live push
@@ -135,6 +206,7 @@ type Agent struct {
 	mantra        string
 	cwd           string
 	loadoutName   string
+	persona       string
 	loadoutVer    string
 
 	cancel context.CancelFunc
@@ -162,6 +234,12 @@ func NewAgent(cfg Config) *Agent {
 		tools:      cfg.Tools,
 		summarize:  compose.ToolSummary(tool.Summarizer(cfg.Tools)),
 		previewArg: compose.ToolPreviewArg(tool.PreviewArger(cfg.Tools)),
+		guard:      cfg.PromptGuard,
+		respGuard:  cfg.ResponseGuard,
+		budget:     cfg.Budget,
+		signer:     cfg.Signer,
+		limits:     cfg.Limits,
+		replyLang:  cfg.ReplyLang,
 		inlineBoot: cfg.InlineBoot,
 		backend:    cfg.Backend,
 		chalkboard: cfg.Chalkboard,
@@ -189,7 +267,7 @@ func NewAgent(cfg Config) *Agent {
 	a.ariaSrv = aria.NewServer()
 	for i, u := range compose.Units(messages, a.summarize, a.previewArg) {
 		a.unitLT = i + 1
-		a.ariaSrv.Commit(aria.Message{LT: a.unitLT, Role: u.Role, Nodes: u.Nodes})
+		a.ariaSrv.Commit(aria.Message{LT: a.unitLT, Role: u.Role, Author: u.Author, Nodes: u.Nodes})
 	}
 	a.ariaSrv.Subscribe(func(r aria.AriaRead) {
 		r.Metrics = a.sessionMetrics()
@@ -338,6 +416,7 @@ func (a *Agent) refreshMetrics() {
 	a.cwd = snapshotString(snapshot, "system.cwd")
 	a.loadoutName = snapshotString(snapshot, "system.loadout_name")
 	a.loadoutVer = snapshotString(snapshot, "system.loadout_version")
+	a.persona = snapshotString(snapshot, "system.persona")
 	a.mu.Unlock()
 }
 
@@ -380,6 +459,7 @@ func (a *Agent) refreshMetricsFrom(msgs []message.Message) {
 	a.cwd = snapshotString(snapshot, "system.cwd")
 	a.loadoutName = snapshotString(snapshot, "system.loadout_name")
 	a.loadoutVer = snapshotString(snapshot, "system.loadout_version")
+	a.persona = snapshotString(snapshot, "system.persona")
 	a.mu.Unlock()
 }
 
@@ -389,6 +469,8 @@ func (a *Agent) SubmitPrompt(req rpc.QuaRequest) {
 		typ:        eventUserPrompt,
 		text:       req.Text,
 		chalkboard: req.Chalkboard,
+		force:      req.Force,
+		author:     req.Author,
 	})
 }
 
@@ -429,6 +511,12 @@ func (a *Agent) Context() []message.Message {
 	return unwrapMessages(a.figLog.Read())
 }
 
+// ContextSince is the messages logged after LT lt — what a turn that
+// started there has written, without copying the history before it.
+func (a *Agent) ContextSince(lt uint64) []message.Message {
+	return unwrapMessages(a.figLog.ReadFrom(lt+1, 0))
+}
+
 // unwrapMessages projects entries to a flat []Message.
 func unwrapMessages(entries []store.Entry[message.Message]) []message.Message {
 	if len(entries) == 0 {
@@ -488,6 +576,7 @@ func (a *Agent) Info() FigaroInfo {
 		Cwd:              a.cwd,
 		LoadoutName:      a.loadoutName,
 		LoadoutVersion:   a.loadoutVer,
+		Persona:          a.persona,
 		LastFigaroLT:     a.metricsLT,
 	}
 	a.mu.RUnlock()
@@ -508,6 +597,8 @@ func (a *Agent) sessionMetrics() *aria.Metrics {
 		CacheReadTokens:  info.CacheReadTokens,
 		CacheWriteTokens: info.CacheWriteTokens,
 		Mantra:           mantra,
+		Provider:         info.Provider,
+		Model:            info.Model,
 	}
 }
 
@@ -575,7 +666,7 @@ func (a *Agent) reconcileAriaServer() {
 	units := compose.Units(a.Context(), a.summarize, a.previewArg)
 	history := make([]aria.Message, len(units))
 	for i, unit := range units {
-		history[i] = aria.Message{LT: i + 1, Role: unit.Role, Nodes: unit.Nodes}
+		history[i] = aria.Message{LT: i + 1, Role: unit.Role, Author: unit.Author, Nodes: unit.Nodes}
 	}
 	a.ariaSrv.Restore(history)
 	a.unitLT = len(history)
@@ -587,7 +678,7 @@ func (a *Agent) reconcileAriaServer() {
 			JSONRPC: "2.0",
 			Method:  rpc.MethodAriaFrame,
 			Params: aria.AriaRead{
-				Committed: []aria.Committed{{LT: message.LT, Role: message.Role, Nodes: message.Nodes}},
+				Committed: []aria.Committed{{LT: message.LT, Role: message.Role, Author: message.Author, Nodes: message.Nodes}},
 				Metrics:   a.sessionMetrics(),
 			},
 		})
@@ -711,6 +802,9 @@ func (m patchMap) PatchesAt(lt uint64) []message.Patch { return m[lt] }
 func (a *Agent) endTurn(reason string) {
 	a.refreshMetrics()
 	a.emitCommit() // freeze the live unit before signaling the turn idle
+	if !strings.HasPrefix(reason, "error:") {
+		a.checkAnswer()
+	}
 	a.finishTurn(reason)
 }
 
@@ -728,13 +822,15 @@ func (a *Agent) endTurnDiscarding(reason string) {
 
 func (a *Agent) finishTurn(reason string) {
 	idle := a.inbox.IsIdle()
+	kind := a.turnErrKind
+	a.turnErrKind = ""
 	a.mu.Lock()
 	a.lastActive = time.Now()
 	a.mu.Unlock()
 	a.fanOut(rpc.Notification{
 		JSONRPC: "2.0",
 		Method:  rpc.MethodTurnDone,
-		Params:  rpc.DoneEntry{Reason: reason, Idle: &idle},
+		Params:  rpc.DoneEntry{Reason: reason, Idle: &idle, Kind: string(kind), From: a.turnFrom},
 	})
 
 	a.publishMetadata()
@@ -760,6 +856,7 @@ func (a *Agent) publishMetadata() {
 		Cwd:              a.cwd,
 		LoadoutName:      a.loadoutName,
 		LoadoutVersion:   a.loadoutVer,
+		Persona:          a.persona,
 		ContextTokens:    a.contextTokens,
 		ContextLimit:     a.contextLimit,
 		ContextExact:     a.contextExact,
@@ -776,14 +873,39 @@ func (a *Agent) toolDefs() []provider.Tool {
 	if a.tools == nil {
 		return nil
 	}
+	allowed := a.allowedTools()
 	list := a.tools.List()
-	defs := make([]provider.Tool, len(list))
-	for i, t := range list {
-		defs[i] = provider.Tool{Name: t.Name(), Description: t.Description(), Parameters: t.Parameters()}
+	defs := make([]provider.Tool, 0, len(list))
+	for _, t := range list {
+		if allowed != nil && !allowed[t.Name()] {
+			continue
+		}
+		defs = append(defs, provider.Tool{Name: t.Name(), Description: t.Description(), Parameters: t.Parameters()})
 	}
 	return defs
 }
 
+// allowedTools is the system.tools set a persona narrows the aria to, or
+// nil when every registered tool is on offer.
+func (a *Agent) allowedTools() map[string]bool {
+	if a.chalkboard == nil {
+		return nil
+	}
+	raw, ok := a.chalkboard.Snapshot()["system.tools"]
+	if !ok {
+		return nil
+	}
+	var names []string
+	if json.Unmarshal(raw, &names) != nil || len(names) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, n := range names {
+		allowed[n] = true
+	}
+	return allowed
+}
+
 func (a *Agent) fanOut(n rpc.Notification) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -2,7 +2,9 @@ package figaro_test
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -15,10 +17,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/figaro"
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/livelog/aria"
 	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provenance"
 	"github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/figaro/internal/store"
@@ -640,6 +644,81 @@ func TestAgent_MultipleSubscribers(t *testing.T) {
 	}
 }
 
+func TestAgent_AuthorReachesEverySubscriber(t *testing.T) {
+	a := newTestAgent("hi")
+	defer a.Kill()
+
+	ch1, _ := subscribeChan(a)
+	ch2, _ := subscribeChan(a)
+	a.SubmitPrompt(rpc.QuaRequest{Text: "hello", Author: "alice"})
+
+	for i, ch := range []<-chan rpc.Notification{ch1, ch2} {
+		var author string
+		timeout := time.After(5 * time.Second)
+	wait:
+		for {
+			select {
+			case n := <-ch:
+				if n.Method == rpc.MethodTurnDone {
+					break wait
+				}
+				if r, ok := n.Params.(aria.AriaRead); ok && r.Live != nil && r.Live.Role == "user" {
+					author = r.Live.Author
+				}
+			case <-timeout:
+				t.Fatalf("subscriber %d: no turn.done", i)
+			}
+		}
+		assert.Equal(t, "alice", author, "subscriber %d", i)
+	}
+	assert.Equal(t, "alice", a.Context()[0].Author)
+}
+
+func TestAgent_SignsAuthoredPrompts(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":    json.RawMessage(`"mock-model-v1"`),
+		"system.provider": json.RawMessage(`"mock"`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "test-sign",
+		SocketPath: "/tmp/test-figaro-sign.sock",
+		Provider:   &mockProvider{response: "ok"},
+		Chalkboard: cb,
+		Signer: func(author string) ed25519.PrivateKey {
+			if author == "alice" {
+				return key
+			}
+			return nil
+		},
+	})
+	defer a.Kill()
+	ch, _ := subscribeChan(a)
+
+	for _, req := range []rpc.QuaRequest{{Text: "one", Author: "alice"}, {Text: "two", Author: "bob"}, {Text: "three", Author: "alice"}} {
+		a.SubmitPrompt(req)
+		timeout := time.After(5 * time.Second)
+	wait:
+		for {
+			select {
+			case n := <-ch:
+				if n.Method == rpc.MethodTurnDone {
+					break wait
+				}
+			case <-timeout:
+				t.Fatalf("no turn.done for %q", req.Text)
+			}
+		}
+	}
+
+	trusted := map[string]string{provenance.FormatPublic(key.Public().(ed25519.PublicKey)): "alice"}
+	rep := provenance.Verify(a.Context(), trusted)
+	assert.True(t, rep.OK(), "failures: %+v", rep.Failures)
+	assert.Equal(t, 2, rep.Signed["alice"])
+	assert.Equal(t, 1, rep.Unsigned)
+}
+
 func TestAgent_Unsubscribe(t *testing.T) {
 	a := newTestAgent("hi")
 	defer a.Kill()
@@ -1249,3 +1328,370 @@ func TestSecondTurnDoesNotRecomposePriorTurn(t *testing.T) {
 		}
 	}
 }
+
+// countingNotifier counts turn.done notifications.
+type countingNotifier struct {
+	mu   sync.Mutex
+	done int
+}
+
+func (c *countingNotifier) Notify(method string, _ any) error {
+	if method == rpc.MethodTurnDone {
+		c.mu.Lock()
+		c.done++
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// TestAgent_ConcurrentCallers drives one agent from many goroutines at
+// once — prompts, reads, sets and subscriber churn — the way several CLIs
+// and the daemon share an aria. Run under -race to check the concurrency
+// contract on Agent; without it, it checks every prompt still lands once.
+func TestAgent_ConcurrentCallers(t *testing.T) {
+	a := newTestAgent("ok")
+	defer a.Kill()
+	counter := &countingNotifier{}
+	a.Subscribe(counter)
+
+	const callers, perCaller = 8, 3
+	var wg sync.WaitGroup
+	for c := 0; c < callers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := 0; p < perCaller; p++ {
+				submitPrompt(a, "prompt")
+				_ = a.Info()
+				_ = a.Read(0)
+				_ = a.Context()
+				_ = a.Snapshot()
+				_, _, _ = a.Set(chalkboard.Patch{Set: map[string]json.RawMessage{"mantra": json.RawMessage(`"busy"`)}})
+				_, _ = a.Handle(context.Background(), rpc.MethodContext, nil)
+				_, unsub := subscribeChan(a)
+				unsub()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Count prompts, not user-role entries: an ephemeral aria logs each Set
+	// as a content-less user message carrying the patch.
+	prompts := func() int {
+		n := 0
+		for _, m := range a.Context() {
+			if m.Role == message.RoleUser && len(m.Content) > 0 {
+				n++
+			}
+		}
+		return n
+	}
+	require.Eventually(t, func() bool {
+		return prompts() == callers*perCaller && a.Info().State == "idle"
+	}, 10*time.Second, 10*time.Millisecond, "want %d prompts in the log", callers*perCaller)
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	assert.Positive(t, counter.done)
+}
+
+func TestAgent_PromptGuard(t *testing.T) {
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":      json.RawMessage(`"mock-model-v1"`),
+		"system.max_tokens": json.RawMessage(`1024`),
+	}})
+	var seen []string
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "guarded",
+		SocketPath: "/tmp/test-figaro-guard.sock",
+		Provider:   &mockProvider{response: "ok"},
+		Chalkboard: cb,
+		PromptGuard: func(_, text string, snap chalkboard.Snapshot) (string, error) {
+			seen = append(seen, string(snap["system.model"]))
+			switch text {
+			case "leak":
+				return "", errors.New("blocked")
+			case "mask me":
+				return "masked", nil
+			}
+			return text, nil
+		},
+	})
+	defer a.Kill()
+
+	qua := func(text string) error {
+		params, _ := json.Marshal(rpc.QuaRequest{Text: text})
+		_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+		return err
+	}
+	require.EqualError(t, qua("leak"), "blocked")
+
+	sub, unsub := subscribeChan(a)
+	defer unsub()
+	require.NoError(t, qua("mask me"))
+	waitTurnDone(t, sub)
+
+	var texts []string
+	for _, m := range a.Context() {
+		if m.Role == message.RoleUser && len(m.Content) > 0 {
+			texts = append(texts, m.Content[0].Text)
+		}
+	}
+	assert.Equal(t, []string{"masked"}, texts)
+	assert.Equal(t, []string{`"mock-model-v1"`, `"mock-model-v1"`}, seen)
+}
+
+func TestAgent_Budget(t *testing.T) {
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":      json.RawMessage(`"mock-model-v1"`),
+		"system.max_tokens": json.RawMessage(`1024`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "capped",
+		SocketPath: "/tmp/test-figaro-budget.sock",
+		Provider:   &mockProvider{response: "ok"},
+		Chalkboard: cb,
+		Budget: func(ariaID string, inputTokens int, force bool) error {
+			if force {
+				return nil
+			}
+			return errors.New("daily budget reached")
+		},
+	})
+	defer a.Kill()
+
+	qua := func(force bool) error {
+		params, _ := json.Marshal(rpc.QuaRequest{Text: "hi", Force: force})
+		_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+		return err
+	}
+	require.EqualError(t, qua(false), "daily budget reached")
+	assert.Empty(t, a.Context())
+
+	sub, unsub := subscribeChan(a)
+	defer unsub()
+	require.NoError(t, qua(true))
+	waitTurnDone(t, sub)
+	assert.NotEmpty(t, a.Context())
+}
+
+// countingProvider counts every request at a fixed size.
+type countingProvider struct {
+	mockProvider
+	count int
+	err   error
+	hold  chan struct{} // when set, counting waits on it
+}
+
+func (c *countingProvider) PrepareCount(provider.SendInput) (func(context.Context) (int, error), error) {
+	return func(ctx context.Context) (int, error) {
+		if c.hold != nil {
+			select {
+			case <-c.hold:
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		return c.count, c.err
+	}, nil
+}
+
+func TestAgent_CountsInputBeforeSend(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		prov    *countingProvider
+		blocked bool
+	}{
+		{"exact", &countingProvider{mockProvider: mockProvider{response: "ok"}, count: 9000}, true},
+		{"estimate", &countingProvider{mockProvider: mockProvider{response: "ok"}, err: errors.New("count unavailable")}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cb, _ := chalkboard.Open("")
+			cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+				"system.model":      json.RawMessage(`"mock-model-v1"`),
+				"system.max_tokens": json.RawMessage(`1024`),
+			}})
+			var asked []int
+			a := figaro.NewAgent(figaro.Config{
+				ID:         "counted-" + tc.name,
+				SocketPath: "/tmp/test-figaro-count.sock",
+				Provider:   tc.prov,
+				Chalkboard: cb,
+				Budget: func(_ string, inputTokens int, force bool) error {
+					asked = append(asked, inputTokens)
+					if inputTokens > 5000 && !force {
+						return errors.New("daily budget would be passed")
+					}
+					return nil
+				},
+			})
+			defer a.Kill()
+			sub, unsub := subscribeChan(a)
+			defer unsub()
+
+			reason := func() string {
+				t.Helper()
+				timeout := time.After(5 * time.Second)
+				for {
+					select {
+					case n := <-sub:
+						if n.Method == rpc.MethodTurnDone {
+							return n.Params.(rpc.DoneEntry).Reason
+						}
+					case <-timeout:
+						t.Fatal("timeout waiting for turn.done")
+					}
+				}
+			}
+			qua := func(force bool) {
+				params, _ := json.Marshal(rpc.QuaRequest{Text: "hi", Force: force})
+				_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+				require.NoError(t, err)
+			}
+
+			qua(false)
+			done := reason()
+			require.Len(t, asked, 2)
+			assert.Equal(t, 0, asked[0], "prompt acceptance has no size yet")
+			if !tc.blocked {
+				assert.Equal(t, string(message.StopEnd), done)
+				assert.Less(t, asked[1], 100, "a failed count falls back to the estimate")
+				assert.False(t, a.Info().ContextExact)
+				return
+			}
+			assert.Equal(t, "error: daily budget would be passed", done)
+			assert.Equal(t, 9000, asked[1])
+			assert.Equal(t, 9000, a.Info().ContextTokens)
+			assert.True(t, a.Info().ContextExact)
+
+			qua(true)
+			assert.Equal(t, string(message.StopEnd), reason())
+		})
+	}
+}
+
+func TestAgent_CountDoesNotHoldUpRoundWithoutBudget(t *testing.T) {
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":      json.RawMessage(`"mock-model-v1"`),
+		"system.max_tokens": json.RawMessage(`1024`),
+	}})
+	hold := make(chan struct{})
+	defer close(hold)
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "uncounted",
+		SocketPath: "/tmp/test-figaro-uncounted.sock",
+		Provider:   &countingProvider{mockProvider: mockProvider{response: "ok"}, count: 9000, hold: hold},
+		Chalkboard: cb,
+	})
+	defer a.Kill()
+	sub, unsub := subscribeChan(a)
+	defer unsub()
+
+	params, _ := json.Marshal(rpc.QuaRequest{Text: "hi"})
+	_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+	require.NoError(t, err)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case n := <-sub:
+			if n.Method == rpc.MethodTurnDone {
+				assert.Equal(t, string(message.StopEnd), n.Params.(rpc.DoneEntry).Reason)
+				return
+			}
+		case <-timeout:
+			t.Fatal("the round waited on the token count")
+		}
+	}
+}
+
+// snapshotProvider records the snapshot each Send is given.
+type snapshotProvider struct {
+	mockProvider
+	seen []chalkboard.Snapshot
+}
+
+func (p *snapshotProvider) Send(ctx context.Context, in provider.SendInput, bus provider.Bus) error {
+	p.seen = append(p.seen, in.Snapshot)
+	return p.mockProvider.Send(ctx, in, bus)
+}
+
+func TestAgent_DefaultReplyLangAppliesToTurns(t *testing.T) {
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":      json.RawMessage(`"mock-model-v1"`),
+		"system.max_tokens": json.RawMessage(`1024`),
+	}})
+	prov := &snapshotProvider{mockProvider: mockProvider{response: "ok"}}
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "reply-lang",
+		SocketPath: "/tmp/test-figaro-reply-lang.sock",
+		Provider:   prov,
+		Chalkboard: cb,
+		ReplyLang:  "French",
+	})
+	defer a.Kill()
+	sub, unsub := subscribeChan(a)
+	defer unsub()
+
+	params, _ := json.Marshal(rpc.QuaRequest{Text: "hi"})
+	_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+	require.NoError(t, err)
+	waitTurnDone(t, sub)
+	require.Len(t, prov.seen, 1)
+	assert.Equal(t, "French", provider.ReplyLang(prov.seen[0]))
+	_, onBoard := a.Snapshot()[provider.ReplyLangKey]
+	assert.False(t, onBoard, "the default is not written to the aria's chalkboard")
+}
+
+func TestAgent_TurnDoneCarriesErrorKind(t *testing.T) {
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":      json.RawMessage(`"mock-model-v1"`),
+		"system.max_tokens": json.RawMessage(`1024`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "kinded",
+		SocketPath: "/tmp/test-figaro-kind.sock",
+		Provider:   &countingProvider{mockProvider: mockProvider{response: "ok"}, count: 9000},
+		Chalkboard: cb,
+		Budget: func(_ string, inputTokens int, force bool) error {
+			if inputTokens > 5000 && !force {
+				return errs.Wrap(errs.Budget, errors.New("daily budget would be passed"))
+			}
+			return nil
+		},
+	})
+	defer a.Kill()
+	sub, unsub := subscribeChan(a)
+	defer unsub()
+
+	done := func() rpc.DoneEntry {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case n := <-sub:
+				if n.Method == rpc.MethodTurnDone {
+					return n.Params.(rpc.DoneEntry)
+				}
+			case <-timeout:
+				t.Fatal("timeout waiting for turn.done")
+			}
+		}
+	}
+	qua := func(force bool) {
+		params, _ := json.Marshal(rpc.QuaRequest{Text: "hi", Force: force})
+		_, err := a.Handle(context.Background(), rpc.MethodQua, params)
+		require.NoError(t, err)
+	}
+
+	qua(false)
+	d := done()
+	assert.Equal(t, "error: daily budget would be passed", d.Reason)
+	assert.Equal(t, string(errs.Budget), d.Kind)
+
+	qua(true)
+	assert.Empty(t, done().Kind, "the kind does not outlive its turn")
+}
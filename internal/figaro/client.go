@@ -16,6 +16,10 @@ type NotifyHandler func(method string, params json.RawMessage)
 // Client is a typed JSON-RPC client for talking to a figaro agent socket.
 type Client struct {
 	cli *jkrpc.Client
+
+	// Author is sent with every prompt so other clients attached to the
+	// aria can attribute it. Empty sends none.
+	Author string
 }
 
 // DialClient connects to a figaro agent.
@@ -31,8 +35,14 @@ func DialClient(ep transport.Endpoint, onNotify NotifyHandler) (*Client, error)
 // Qua sends a prompt and returns the cursor (highest committed figaro LT at
 // accept time) to stream from. The reply streams as figaro.aria notifications.
 func (c *Client) Qua(ctx context.Context, text string, cb *rpc.ChalkboardInput) (int, error) {
+	return c.QuaForce(ctx, text, cb, false)
+}
+
+// QuaForce is Qua that, when force is set, goes through even past a spend
+// limit.
+func (c *Client) QuaForce(ctx context.Context, text string, cb *rpc.ChalkboardInput, force bool) (int, error) {
 	var resp rpc.QuaResponse
-	err := c.cli.Call(ctx, rpc.MethodQua, rpc.QuaRequest{Text: text, Chalkboard: cb}, &resp)
+	err := c.cli.Call(ctx, rpc.MethodQua, rpc.QuaRequest{Text: text, Chalkboard: cb, Force: force, Author: c.Author}, &resp)
 	return resp.Cursor, err
 }
 
@@ -45,6 +55,14 @@ func (c *Client) Read(ctx context.Context, sinceLT int) (aria.AriaRead, error) {
 	return r, err
 }
 
+// ReadTail is Read bounded to the newest limit closed messages after sinceLT,
+// for a reader that should start at the recent end of a long history.
+func (c *Client) ReadTail(ctx context.Context, sinceLT, limit int) (aria.AriaRead, error) {
+	var r aria.AriaRead
+	err := c.cli.Call(ctx, rpc.MethodRead, rpc.ReadRequest{SinceLT: sinceLT, Limit: limit}, &r)
+	return r, err
+}
+
 // ReadBefore pulls up to limit closed messages with LT < beforeLT, ascending —
 // the backward keyset half of figaro.read, for a pager to walk history.
 func (c *Client) ReadBefore(ctx context.Context, beforeLT, limit int) (aria.AriaRead, error) {
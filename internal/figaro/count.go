@@ -0,0 +1,87 @@
+package figaro
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jack-work/figaro/internal/provider"
+)
+
+// countTimeout bounds the pre-send token count; past it the round goes
+// ahead on the estimate.
+const countTimeout = 5 * time.Second
+
+// countInput sizes the request a round is about to send, blocking until
+// the provider answers. Only the budget check needs that: a provider that
+// can count is asked, and its exact figure replaces the context metric
+// before the reply streams; otherwise, or when counting fails, the
+// estimate refreshMetrics keeps stands.
+func (a *Agent) countInput(ctx context.Context, in provider.SendInput) int {
+	if count := a.prepareCount(in); count != nil {
+		cctx, cancel := context.WithTimeout(ctx, countTimeout)
+		n, err := count(cctx)
+		cancel()
+		if err == nil {
+			a.mu.Lock()
+			a.contextTokens, a.contextExact = n, true
+			a.mu.Unlock()
+			a.publishMetadata()
+			return n
+		}
+		a.countFailed(ctx, err)
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.contextTokens
+}
+
+// countInputLater sizes the request without holding up the round: the
+// projection runs here, ahead of Send, and the count itself alongside the
+// reply. Its figure lands only if no later message has moved the metric
+// on, and rides the next metadata the drain loop publishes.
+func (a *Agent) countInputLater(ctx context.Context, in provider.SendInput) {
+	count := a.prepareCount(in)
+	if count == nil {
+		return
+	}
+	a.mu.RLock()
+	lt := a.metricsLT
+	a.mu.RUnlock()
+	go func() {
+		cctx, cancel := context.WithTimeout(ctx, countTimeout)
+		n, err := count(cctx)
+		cancel()
+		if err != nil {
+			a.countFailed(ctx, err)
+			return
+		}
+		a.mu.Lock()
+		if a.metricsLT == lt {
+			a.contextTokens, a.contextExact = n, true
+		}
+		a.mu.Unlock()
+	}()
+}
+
+// prepareCount returns the provider's count for in, or nil when it cannot
+// count.
+func (a *Agent) prepareCount(in provider.SendInput) func(context.Context) (int, error) {
+	counter, ok := a.prov.(provider.TokenCounter)
+	if !ok {
+		return nil
+	}
+	count, err := counter.PrepareCount(in)
+	if err != nil {
+		a.countFailed(context.Background(), err)
+		return nil
+	}
+	return count
+}
+
+func (a *Agent) countFailed(ctx context.Context, err error) {
+	if !errors.Is(err, errors.ErrUnsupported) && ctx.Err() == nil {
+		slog.Debug("count tokens; using estimate", "aria", a.id, "err", err)
+	}
+}
@@ -39,5 +39,6 @@ type FigaroInfo struct {
 	Cwd              string    `json:"cwd"`
 	LoadoutName      string    `json:"loadout_name"`
 	LoadoutVersion   string    `json:"loadout_version"`
+	Persona          string    `json:"persona,omitempty"`
 	LastFigaroLT     uint64    `json:"last_figaro_lt"`
 }
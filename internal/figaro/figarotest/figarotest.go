@@ -0,0 +1,292 @@
+// Package figarotest runs the full agent loop against a scripted
+// provider (mockllm) and renders what it produced — the IR log, the aria
+// read the clients render from, and each turn's outcome — as a plain
+// text transcript to compare with a golden file.
+//
+// Run the tests with -update to rewrite the golden files.
+package figarotest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/livedoc"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider/mockllm"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/store"
+	"github.com/jack-work/figaro/internal/tool"
+)
+
+var update = flag.Bool("update", false, "rewrite golden transcripts")
+
+// turnTimeout bounds how long Prompt waits for turn.done.
+const turnTimeout = 5 * time.Second
+
+// boot is the chalkboard every harness aria starts with.
+var boot = map[string]json.RawMessage{
+	"system.model":      json.RawMessage(`"mock-1"`),
+	"system.provider":   json.RawMessage(`"mockllm"`),
+	"system.max_tokens": json.RawMessage(`1024`),
+}
+
+// Options configure a Harness.
+type Options struct {
+	Tools []tool.Tool
+	// Backed persists the aria to a store in a temp dir, so Restart
+	// restores it from disk.
+	Backed bool
+}
+
+// Harness drives one aria.
+type Harness struct {
+	LLM   *mockllm.MockLLM
+	Agent *figaro.Agent
+
+	t       testing.TB
+	id      string
+	tools   *tool.Registry
+	backend store.Backend
+	done    chan rpc.DoneEntry
+	unsub   func()
+	turns   []rpc.DoneEntry
+}
+
+// New starts an aria on llm. It is killed when the test ends.
+func New(t testing.TB, llm *mockllm.MockLLM, opts Options) *Harness {
+	t.Helper()
+	h := &Harness{LLM: llm, t: t, id: "golden", tools: tool.NewRegistry()}
+	for _, tl := range opts.Tools {
+		if err := h.tools.Register(tl); err != nil {
+			t.Fatalf("register %s: %v", tl.Name(), err)
+		}
+	}
+	if opts.Backed {
+		h.backend, h.id = backed(t)
+	}
+	h.start()
+	t.Cleanup(h.stop)
+	return h
+}
+
+func backed(t testing.TB) (store.Backend, string) {
+	t.Helper()
+	b, err := store.NewXwalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	l, err := b.CreateLoadout("golden", message.Patch{Set: boot})
+	if err != nil {
+		t.Fatalf("loadout: %v", err)
+	}
+	id, err := b.CreateConversation(l)
+	if err != nil {
+		t.Fatalf("conversation: %v", err)
+	}
+	return b, id
+}
+
+func (h *Harness) start() {
+	cb, _ := chalkboard.Open("")
+	state := boot
+	if h.backend != nil {
+		var err error
+		if state, err = h.backend.ChalkboardState(h.id); err != nil {
+			h.t.Fatalf("chalkboard: %v", err)
+		}
+	}
+	cb.Apply(chalkboard.Patch{Set: state})
+	h.Agent = figaro.NewAgent(figaro.Config{
+		ID:         h.id,
+		SocketPath: filepath.Join(os.TempDir(), "figarotest-"+h.id+".sock"),
+		Provider:   h.LLM,
+		Tools:      h.tools,
+		Backend:    h.backend,
+		Chalkboard: cb,
+	})
+	h.done = make(chan rpc.DoneEntry, 16)
+	h.unsub = h.Agent.Subscribe(doneNotifier(h.done))
+}
+
+func (h *Harness) stop() {
+	if h.Agent == nil {
+		return
+	}
+	h.unsub()
+	h.Agent.Kill()
+	h.Agent = nil
+}
+
+// Restart kills the agent and starts a new one on the same aria, which
+// for a Backed harness restores it from the store.
+func (h *Harness) Restart() {
+	h.t.Helper()
+	h.stop()
+	h.start()
+}
+
+// Prompt sends text and waits for its turn to finish.
+func (h *Harness) Prompt(text string) rpc.DoneEntry {
+	h.t.Helper()
+	h.Agent.SubmitPrompt(rpc.QuaRequest{Text: text})
+	select {
+	case d := <-h.done:
+		h.turns = append(h.turns, d)
+		return d
+	case <-time.After(turnTimeout):
+		h.t.Fatalf("no turn.done within %s for %q", turnTimeout, text)
+	}
+	return rpc.DoneEntry{}
+}
+
+// doneNotifier forwards turn.done and drops everything else.
+type doneNotifier chan rpc.DoneEntry
+
+func (d doneNotifier) Notify(method string, params any) error {
+	if e, ok := params.(rpc.DoneEntry); ok && method == rpc.MethodTurnDone {
+		d <- e
+	}
+	return nil
+}
+
+// Transcript renders the aria: its IR messages, the committed aria read,
+// and each prompted turn's outcome. Empty-content messages (genesis,
+// chalkboard tics) and timestamps are left out, so it is stable run to
+// run.
+func (h *Harness) Transcript() string {
+	var b strings.Builder
+	b.WriteString("# log\n")
+	for _, m := range h.Agent.Context() {
+		if len(m.Content) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n[%d %s", m.LogicalTime, m.Role)
+		if m.StopReason != "" {
+			fmt.Fprintf(&b, " %s", m.StopReason)
+		}
+		b.WriteString("]\n")
+		for _, c := range m.Content {
+			writeContent(&b, c)
+		}
+	}
+	b.WriteString("\n# aria\n")
+	for _, c := range h.Agent.Read(0).Committed {
+		fmt.Fprintf(&b, "\n[%d %s]\n", c.LT, c.Role)
+		for _, n := range c.Nodes {
+			writeNode(&b, n)
+		}
+	}
+	b.WriteString("\n# turns\n")
+	for _, d := range h.turns {
+		b.WriteString(d.Reason)
+		if d.Kind != "" {
+			fmt.Fprintf(&b, " (%s)", d.Kind)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeContent(b *strings.Builder, c message.Content) {
+	switch c.Type {
+	case message.ContentProse:
+		writeText(b, "", c.Text)
+	case message.ContentThinking:
+		writeText(b, "thinking: ", c.Text)
+	case message.ContentToolInvoke:
+		args, _ := json.Marshal(c.Arguments)
+		fmt.Fprintf(b, "tool_invoke %s %s %s\n", c.ToolCallID, c.ToolName, args)
+	case message.ContentToolResult:
+		status := "ok"
+		if c.IsError {
+			status = "error"
+		}
+		writeText(b, fmt.Sprintf("tool_result %s %s %s: ", c.ToolCallID, c.ToolName, status), c.Text)
+	case message.ContentInterrupt:
+		writeText(b, fmt.Sprintf("interrupt %s %s: ", c.ToolCallID, c.Reason), c.Text)
+	case message.ContentImage:
+		fmt.Fprintf(b, "image %s (%d bytes base64)\n", c.MimeType, len(c.Data))
+	default:
+		writeText(b, string(c.Type)+": ", c.Text)
+	}
+}
+
+func writeNode(b *strings.Builder, n livedoc.Node) {
+	switch n.Type {
+	case livedoc.NodeTool:
+		fmt.Fprintf(b, "tool %s %s %s", n.ID, n.Name, n.Status)
+		if n.Summary != "" {
+			fmt.Fprintf(b, " %q", n.Summary)
+		}
+		b.WriteString("\n")
+		if n.Output != "" {
+			writeText(b, "> ", n.Output)
+		}
+	default:
+		writeText(b, string(n.Type)+": ", n.Markdown)
+	}
+}
+
+// writeText writes text after prefix, indenting continuation lines.
+func writeText(b *strings.Builder, prefix, text string) {
+	indent := strings.Repeat(" ", len(prefix))
+	for i, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if i == 0 {
+			b.WriteString(prefix)
+		} else {
+			b.WriteString(indent)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// Golden compares got with the golden file at path, or rewrites the
+// file under -update.
+func Golden(t testing.TB, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if string(want) != got {
+		t.Errorf("%s differs (run with -update to accept):\n--- want\n%s--- got\n%s", path, want, got)
+	}
+}
+
+// Func is a tool whose result is fn's; an error makes an error result.
+type Func struct {
+	ToolName string
+	Fn       func(args map[string]any) (string, error)
+}
+
+func (f Func) Name() string        { return f.ToolName }
+func (f Func) Description() string { return "scripted test tool" }
+func (f Func) Parameters() any     { return map[string]any{"type": "object"} }
+
+func (f Func) Execute(_ context.Context, args map[string]any, _ tool.OnOutput) ([]message.Content, error) {
+	out, err := f.Fn(args)
+	if err != nil {
+		return nil, err
+	}
+	return []message.Content{message.TextContent(out)}, nil
+}
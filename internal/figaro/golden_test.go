@@ -0,0 +1,102 @@
+package figaro_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/figaro/figarotest"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider/mockllm"
+	"github.com/jack-work/figaro/internal/tool"
+)
+
+// Golden transcripts: each test scripts the model with mockllm, drives
+// the real agent loop through figarotest, and compares the resulting
+// log, aria read and turn outcomes with testdata/golden. Run with
+// -update to accept a change.
+
+func golden(name string) string { return filepath.Join("testdata", "golden", name+".txt") }
+
+var echoTool = figarotest.Func{ToolName: "echo", Fn: func(args map[string]any) (string, error) {
+	return fmt.Sprint(args["text"]), nil
+}}
+
+func TestGolden_StreamedText(t *testing.T) {
+	llm := mockllm.New(mockllm.Reply{
+		Thinking: "A greeting.",
+		Text:     "Hello!\nHow can I help?",
+		Usage:    &message.Usage{InputTokens: 10, OutputTokens: 6},
+	})
+	llm.Chunk = 3
+	h := figarotest.New(t, llm, figarotest.Options{})
+
+	d := h.Prompt("hi")
+	assert.Equal(t, string(message.StopEnd), d.Reason)
+	assert.Zero(t, llm.Remaining())
+	figarotest.Golden(t, golden("streamed_text"), h.Transcript())
+}
+
+func TestGolden_ToolRoundTrip(t *testing.T) {
+	failing := figarotest.Func{ToolName: "fail", Fn: func(map[string]any) (string, error) {
+		return "", errors.New("disk full")
+	}}
+	llm := mockllm.New(
+		mockllm.Reply{Text: "Let me check.", ToolCalls: []mockllm.ToolCall{
+			{ID: "call_1", Name: "echo", Args: map[string]any{"text": "ping"}},
+			{ID: "call_2", Name: "fail", Args: map[string]any{}},
+		}},
+		mockllm.Text("echo said ping; fail failed."),
+	)
+	llm.Chunk = 4
+	h := figarotest.New(t, llm, figarotest.Options{Tools: []tool.Tool{echoTool, failing}})
+
+	h.Prompt("run both")
+	reqs := llm.Requests()
+	require.Len(t, reqs, 2)
+	assert.ElementsMatch(t, []string{"echo", "fail"}, reqs[0].Tools)
+	last := reqs[1].Messages[len(reqs[1].Messages)-1]
+	assert.Equal(t, message.RoleUser, last.Role, "the second round carries the tool results")
+	figarotest.Golden(t, golden("tool_round_trip"), h.Transcript())
+}
+
+func TestGolden_ProviderError(t *testing.T) {
+	llm := mockllm.New(
+		mockllm.Reply{Err: errs.New(errs.RateLimit, "mock: slow down (429)")},
+		mockllm.Text("Back again."),
+	)
+	h := figarotest.New(t, llm, figarotest.Options{})
+
+	d := h.Prompt("first")
+	assert.Equal(t, string(errs.RateLimit), d.Kind)
+	for _, m := range h.Agent.ContextSince(d.From) {
+		assert.NotEqual(t, message.RoleAssistant, m.Role, "the failed turn wrote no answer")
+	}
+	d = h.Prompt("retry")
+	since := h.Agent.ContextSince(d.From)
+	require.NotEmpty(t, since)
+	assert.Equal(t, message.RoleUser, since[0].Role, "the turn's own prompt comes first")
+	figarotest.Golden(t, golden("provider_error"), h.Transcript())
+}
+
+func TestGolden_RestoresFromStore(t *testing.T) {
+	llm := mockllm.New(
+		mockllm.Call("call_1", "echo", map[string]any{"text": "saved"}),
+		mockllm.Text("Stored."),
+		mockllm.Text("Still here."),
+	)
+	h := figarotest.New(t, llm, figarotest.Options{Tools: []tool.Tool{echoTool}, Backed: true})
+
+	h.Prompt("remember this")
+	before := h.Transcript()
+	h.Restart()
+	assert.Equal(t, before, h.Transcript(), "a restored aria reads the same")
+
+	h.Prompt("still there?")
+	figarotest.Golden(t, golden("restores_from_store"), h.Transcript())
+}
@@ -0,0 +1,81 @@
+package figaro
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/guard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+)
+
+// checkToolCall runs the response guard on tc before it executes. Every
+// violation is fanned out; when one blocks, the returned text is the
+// call's error result and the tool must not run.
+func (a *Agent) checkToolCall(tc message.Content) (string, bool) {
+	if a.respGuard == nil {
+		return "", false
+	}
+	var blocked []string
+	for _, v := range a.respGuard(a.id, guard.StageTool, tc.ToolName, toolArgText(tc.Arguments)) {
+		a.notifyViolation(v)
+		if v.Blocked {
+			blocked = append(blocked, v.Rule)
+		}
+	}
+	if len(blocked) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("Blocked by guard rule %s: the call was not run. Do not retry it in another form; ask the user.",
+		strings.Join(blocked, ", ")), true
+}
+
+// checkAnswer runs the response guard on the prose this turn's assistant
+// messages wrote. Answers are already in the log, so violations are
+// reported, not undone.
+func (a *Agent) checkAnswer() {
+	if a.respGuard == nil {
+		return
+	}
+	var parts []string
+	for _, e := range a.figLog.ReadFrom(a.turnStartLT+1, 0) {
+		if e.Payload.Role != message.RoleAssistant {
+			continue
+		}
+		for _, c := range e.Payload.Content {
+			if c.Type == message.ContentProse && c.Text != "" {
+				parts = append(parts, c.Text)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return
+	}
+	for _, v := range a.respGuard(a.id, guard.StageAnswer, "", strings.Join(parts, "\n\n")) {
+		a.notifyViolation(v)
+	}
+}
+
+func (a *Agent) notifyViolation(v rpc.GuardViolation) {
+	slog.Warn("guard violation", "aria", a.id, "stage", v.Stage, "rule", v.Rule, "tool", v.Tool, "blocked", v.Blocked)
+	a.fanOut(rpc.Notification{JSONRPC: "2.0", Method: rpc.MethodGuardViolation, Params: v})
+}
+
+// toolArgText is the text a tool rule matches: the call's string
+// arguments, one per line in key order.
+func toolArgText(args map[string]interface{}) string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, k := range keys {
+		if s, ok := args[k].(string); ok {
+			lines = append(lines, s)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,117 @@
+package figaro
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/tokens"
+	"github.com/jack-work/figaro/internal/toolout"
+)
+
+// Limits is the daemon's [limits]: ToolResultBytes bounds each tool
+// result before it joins the log (and so every later request);
+// ResponseTokens caps max_tokens. A result past SummarizeTokens goes to
+// Summarize first, when set. Every cut is fanned out as content.trimmed
+// from the drain loop, when the cut result is folded.
+// A streaming block past StreamSpillBytes is written under SpillDir and
+// only its tail kept live (see spill.go).
+type Limits struct {
+	ToolResultBytes  int
+	ResponseTokens   int
+	SummarizeTokens  int
+	Summarize        Summarizer
+	StreamSpillBytes int
+	SpillDir         string
+}
+
+// Summarizer digests a tool result too large to send whole. It keeps the
+// full text where the user can read it and returns that path with the
+// digest.
+type Summarizer func(ctx context.Context, ariaID, tool, callID, text string) (summary, path string, err error)
+
+// maxTokens is the aria's system.max_tokens under the ResponseTokens cap.
+// An aria without its own value gets the cap.
+func (a *Agent) maxTokens() int {
+	n := a.chalkboardInt("system.max_tokens")
+	if c := a.limits.ResponseTokens; c > 0 && (n == 0 || n > c) {
+		return c
+	}
+	return n
+}
+
+// shrinkToolResult fits oc for the log: summarized past SummarizeTokens,
+// then trimmed to ToolResultBytes. It runs on the tool's goroutine, so it
+// returns the cuts for the drain loop to fan out rather than sending them.
+func (a *Agent) shrinkToolResult(ctx context.Context, tc message.Content, oc toolOutcome) (toolOutcome, []rpc.Trimmed) {
+	var cuts []rpc.Trimmed
+	oc, cut := a.summarizeToolResult(ctx, tc, oc)
+	if cut != nil {
+		cuts = append(cuts, *cut)
+	}
+	oc, cut = a.trimToolResult(tc, oc)
+	if cut != nil {
+		cuts = append(cuts, *cut)
+	}
+	return oc, cuts
+}
+
+// summarizeToolResult swaps oc's text for a digest naming where the full
+// text was kept. A failed summary leaves oc to the trim.
+func (a *Agent) summarizeToolResult(ctx context.Context, tc message.Content, oc toolOutcome) (toolOutcome, *rpc.Trimmed) {
+	l := a.limits
+	if l.Summarize == nil || l.SummarizeTokens <= 0 {
+		return oc, nil
+	}
+	if tokens.EstimateMessage(message.Message{Content: oc.content}) <= l.SummarizeTokens {
+		return oc, nil
+	}
+	text := toolOutcomeText(oc)
+	summary, path, err := l.Summarize(ctx, a.id, tc.ToolName, tc.ToolCallID, text)
+	if err != nil {
+		slog.Warn("tool result summary failed, trimming instead", "aria", a.id, "tool", tc.ToolName, "err", err)
+		return oc, nil
+	}
+	digest := fmt.Sprintf("[Summary of a %d-byte result; the full output is at %s]\n\n%s", len(text), path, summary)
+	return toolOutcome{content: withProse(oc.content, digest), isErr: oc.isErr},
+		&rpc.Trimmed{Stage: "summary", Tool: tc.ToolName, Bytes: len(text), KeptBytes: len(digest), Path: path}
+}
+
+// trimToolResult bounds oc's text to ToolResultBytes, keeping its head
+// and tail. Non-text content passes through.
+func (a *Agent) trimToolResult(tc message.Content, oc toolOutcome) (toolOutcome, *rpc.Trimmed) {
+	if a.limits.ToolResultBytes <= 0 {
+		return oc, nil
+	}
+	text := toolOutcomeText(oc)
+	trimmed, ok := toolout.Trim(text, a.limits.ToolResultBytes)
+	if !ok {
+		return oc, nil
+	}
+	return toolOutcome{content: withProse(oc.content, trimmed), isErr: oc.isErr},
+		&rpc.Trimmed{Stage: "tool", Tool: tc.ToolName, Bytes: len(text), KeptBytes: len(trimmed)}
+}
+
+// withProse replaces content's text with text, keeping other blocks.
+func withProse(content []message.Content, text string) []message.Content {
+	out := []message.Content{message.TextContent(text)}
+	for _, c := range content {
+		if c.Type != message.ContentProse {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (a *Agent) notifyTrimmed(t rpc.Trimmed) {
+	slog.Info("content trimmed", "aria", a.id, "stage", t.Stage, "tool", t.Tool, "bytes", t.Bytes, "kept", t.KeptBytes, "max_tokens", t.MaxTokens)
+	a.fanOut(rpc.Notification{JSONRPC: "2.0", Method: rpc.MethodContentTrimmed, Params: t})
+}
+
+func (a *Agent) notifyTrimmedAll(ts []rpc.Trimmed) {
+	for _, t := range ts {
+		a.notifyTrimmed(t)
+	}
+}
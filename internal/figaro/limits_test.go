@@ -0,0 +1,242 @@
+package figaro_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/figaro"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/rpc"
+	"github.com/jack-work/figaro/internal/store"
+	"github.com/jack-work/figaro/internal/tool"
+)
+
+// bigTool returns size bytes of numbered lines.
+type bigTool struct{ size int }
+
+func (bigTool) Name() string        { return "big" }
+func (bigTool) Description() string { return "test tool" }
+func (bigTool) Parameters() any     { return map[string]any{} }
+
+func (b bigTool) Execute(context.Context, map[string]any, tool.OnOutput) ([]message.Content, error) {
+	return []message.Content{message.TextContent(b.output())}, nil
+}
+
+func (b bigTool) output() string {
+	var sb strings.Builder
+	sb.WriteString("HEAD\n")
+	for sb.Len() < b.size-5 {
+		sb.WriteString("0123456789abcdef\n")
+	}
+	sb.WriteString("TAIL\n")
+	return sb.String()
+}
+
+// collectUntilDone collects notifications until turn.done.
+func collectUntilDone(t *testing.T, ch <-chan rpc.Notification) []rpc.Notification {
+	t.Helper()
+	var got []rpc.Notification
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case n := <-ch:
+			got = append(got, n)
+			if n.Method == rpc.MethodTurnDone {
+				return got
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for turn.done")
+		}
+	}
+}
+
+func trimmedOf(t *testing.T, ns []rpc.Notification) []rpc.Trimmed {
+	var out []rpc.Trimmed
+	for _, n := range ns {
+		if n.Method != rpc.MethodContentTrimmed {
+			continue
+		}
+		b, _ := json.Marshal(n.Params)
+		var tr rpc.Trimmed
+		require.NoError(t, json.Unmarshal(b, &tr))
+		out = append(out, tr)
+	}
+	return out
+}
+
+func TestLimits_TrimsToolResult(t *testing.T) {
+	reg := tool.NewRegistry()
+	big := bigTool{size: 64 << 10}
+	require.NoError(t, reg.Register(big))
+	prov := &staggeredProvider{tools: []specTool{{id: "tc_1", name: "big"}}}
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":    json.RawMessage(`"mock"`),
+		"system.provider": json.RawMessage(`"staggered"`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "limits-001",
+		SocketPath: "/tmp/limits-test.sock",
+		Provider:   prov,
+		Tools:      reg,
+		Chalkboard: cb,
+		Limits:     figaro.Limits{ToolResultBytes: 4 << 10},
+	})
+	defer a.Kill()
+
+	ch, _ := subscribeChan(a)
+	submitPrompt(a, "go")
+	trims := trimmedOf(t, collectUntilDone(t, ch))
+
+	res := findToolResult(a.Context())
+	require.NotNil(t, res)
+	text := res.Content[0].Text
+	assert.LessOrEqual(t, len(text), 4<<10+80)
+	assert.True(t, strings.HasPrefix(text, "HEAD\n"))
+	assert.True(t, strings.HasSuffix(text, "TAIL\n"))
+	assert.Contains(t, text, "bytes trimmed from the middle")
+	require.Len(t, trims, 1)
+	assert.Equal(t, rpc.Trimmed{Stage: "tool", Tool: "big", Bytes: len(big.output()), KeptBytes: len(text)}, trims[0])
+}
+
+// lengthProvider answers once, stopping at max_tokens, and records the
+// cap it was sent.
+type lengthProvider struct{ maxTokens atomic.Int64 }
+
+func (p *lengthProvider) Name() string        { return "length" }
+func (p *lengthProvider) Fingerprint() string { return "length/v0" }
+func (p *lengthProvider) SetModel(string)     {}
+func (p *lengthProvider) Models(context.Context) ([]provider.ModelInfo, error) {
+	return nil, nil
+}
+
+func (p *lengthProvider) Send(ctx context.Context, in provider.SendInput, bus provider.Bus) error {
+	p.maxTokens.Store(int64(in.MaxTokens))
+	msg := message.Message{
+		Role:       message.RoleAssistant,
+		Content:    []message.Content{message.TextContent("and then")},
+		StopReason: message.StopLength,
+	}
+	entry, err := in.FigLog.Append(store.Entry[message.Message]{Payload: msg})
+	if err != nil {
+		return err
+	}
+	msg.LogicalTime = entry.LT
+	bus.PushMessageEnd(string(msg.StopReason))
+	bus.PushFigaro(msg)
+	return nil
+}
+
+func TestLimits_CapsResponseTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		own      string
+		want     int64
+		notified bool
+	}{
+		{"above the cap", `8192`, 1000, true},
+		{"below the cap", `500`, 500, false},
+		{"unset", ``, 1000, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prov := &lengthProvider{}
+			set := map[string]json.RawMessage{
+				"system.model":    json.RawMessage(`"mock"`),
+				"system.provider": json.RawMessage(`"length"`),
+			}
+			if tc.own != "" {
+				set["system.max_tokens"] = json.RawMessage(tc.own)
+			}
+			cb, _ := chalkboard.Open("")
+			cb.Apply(chalkboard.Patch{Set: set})
+			a := figaro.NewAgent(figaro.Config{
+				ID:         "limits-002",
+				SocketPath: "/tmp/limits-test-2.sock",
+				Provider:   prov,
+				Tools:      tool.NewRegistry(),
+				Chalkboard: cb,
+				Limits:     figaro.Limits{ResponseTokens: 1000},
+			})
+			defer a.Kill()
+
+			ch, _ := subscribeChan(a)
+			submitPrompt(a, "go")
+			trims := trimmedOf(t, collectUntilDone(t, ch))
+			assert.Equal(t, tc.want, prov.maxTokens.Load())
+			if tc.notified {
+				assert.Equal(t, []rpc.Trimmed{{Stage: "response", MaxTokens: 1000}}, trims)
+			} else {
+				assert.Empty(t, trims)
+			}
+		})
+	}
+}
+
+func summaryAgent(t *testing.T, id string, summarize figaro.Summarizer) *figaro.Agent {
+	reg := tool.NewRegistry()
+	require.NoError(t, reg.Register(bigTool{size: 64 << 10}))
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":    json.RawMessage(`"mock"`),
+		"system.provider": json.RawMessage(`"staggered"`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         id,
+		SocketPath: "/tmp/" + id + ".sock",
+		Provider:   &staggeredProvider{tools: []specTool{{id: "tc_1", name: "big"}}},
+		Tools:      reg,
+		Chalkboard: cb,
+		Limits:     figaro.Limits{ToolResultBytes: 4 << 10, SummarizeTokens: 1000, Summarize: summarize},
+	})
+	t.Cleanup(a.Kill)
+	return a
+}
+
+func TestLimits_SummarizesLargeToolResult(t *testing.T) {
+	var got string
+	a := summaryAgent(t, "limits-003", func(_ context.Context, ariaID, tool, callID, text string) (string, string, error) {
+		got = text
+		assert.Equal(t, "limits-003", ariaID)
+		assert.Equal(t, "big", tool)
+		assert.Equal(t, "tc_1", callID)
+		return "HEAD, many lines, TAIL", "/kept/tc_1.txt", nil
+	})
+	ch, _ := subscribeChan(a)
+	submitPrompt(a, "go")
+	trims := trimmedOf(t, collectUntilDone(t, ch))
+
+	full := bigTool{size: 64 << 10}.output()
+	assert.Equal(t, full, got, "the summarizer sees the whole result")
+	res := findToolResult(a.Context())
+	require.NotNil(t, res)
+	text := res.Content[0].Text
+	assert.Contains(t, text, "/kept/tc_1.txt")
+	assert.True(t, strings.HasSuffix(text, "HEAD, many lines, TAIL"))
+	require.Len(t, trims, 1)
+	assert.Equal(t, rpc.Trimmed{Stage: "summary", Tool: "big", Bytes: len(full), KeptBytes: len(text), Path: "/kept/tc_1.txt"}, trims[0])
+}
+
+func TestLimits_FailedSummaryTrims(t *testing.T) {
+	a := summaryAgent(t, "limits-004", func(context.Context, string, string, string, string) (string, string, error) {
+		return "", "", errors.New("no model")
+	})
+	ch, _ := subscribeChan(a)
+	submitPrompt(a, "go")
+	trims := trimmedOf(t, collectUntilDone(t, ch))
+
+	res := findToolResult(a.Context())
+	require.NotNil(t, res)
+	assert.Contains(t, res.Content[0].Text, "bytes trimmed from the middle")
+	require.Len(t, trims, 1)
+	assert.Equal(t, "tool", trims[0].Stage)
+}
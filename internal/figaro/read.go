@@ -6,8 +6,12 @@ import "github.com/jack-work/figaro/internal/livelog/aria"
 // same paginated read the live MethodAriaFrame stream pushes. A (re)connecting
 // client reads from its last LT, then follows the live frames; application is
 // idempotent, so a catch-up/live overlap can't double-apply.
-func (a *Agent) Read(sinceLT int) aria.AriaRead {
-	out := a.ariaSrv.Read(sinceLT)
+func (a *Agent) Read(sinceLT int) aria.AriaRead { return a.ReadTail(sinceLT, 0) }
+
+// ReadTail is Read bounded to the newest limit closed messages; older ones
+// are left to ReadBefore.
+func (a *Agent) ReadTail(sinceLT, limit int) aria.AriaRead {
+	out := a.ariaSrv.ReadTail(sinceLT, limit)
 	out.Metrics = a.sessionMetrics()
 	return out
 }
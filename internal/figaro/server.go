@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/rpc"
 	"github.com/jack-work/jkrpc"
 )
@@ -32,12 +33,24 @@ func buildHandlers(srv AgentServer) map[string]jkrpc.HandlerFunc {
 	for _, m := range agentMethods {
 		method := m
 		handlers[method] = func(ctx context.Context, params json.RawMessage) (any, error) {
-			return srv.Handle(ctx, method, params)
+			res, err := srv.Handle(ctx, method, params)
+			return res, rpcError(err)
 		}
 	}
 	return handlers
 }
 
+// rpcError carries a classified error's kind to the client in the
+// JSON-RPC error data, so it can print a hint rather than the bare text.
+func rpcError(err error) error {
+	kind := errs.KindOf(err)
+	if kind == "" {
+		return err
+	}
+	data, _ := json.Marshal(rpc.ErrorData{Kind: string(kind)})
+	return &jkrpc.Error{Code: rpc.ErrClassified, Message: err.Error(), Data: data}
+}
+
 // Handle dispatches RPC methods.
 func (a *Agent) Handle(ctx context.Context, method string, params json.RawMessage) (any, error) {
 	switch method {
@@ -46,6 +59,18 @@ func (a *Agent) Handle(ctx context.Context, method string, params json.RawMessag
 		if err := json.Unmarshal(params, &req); err != nil {
 			return nil, err
 		}
+		if a.budget != nil {
+			if err := a.budget(a.id, 0, req.Force); err != nil {
+				return nil, err
+			}
+		}
+		if a.guard != nil {
+			text, err := a.guard(a.id, req.Text, a.Snapshot())
+			if err != nil {
+				return nil, err
+			}
+			req.Text = text
+		}
 		cursor := a.ariaSrv.LastCommittedLT()
 		a.SubmitPrompt(req)
 		return rpc.QuaResponse{OK: true, Cursor: cursor}, nil
@@ -98,7 +123,7 @@ func (a *Agent) Handle(ctx context.Context, method string, params json.RawMessag
 		if req.Before > 0 {
 			return a.ReadBefore(req.Before, req.Limit), nil
 		}
-		return a.Read(req.SinceLT), nil
+		return a.ReadTail(req.SinceLT, req.Limit), nil
 	}
 	return nil, fmt.Errorf("unknown method: %s", method)
 }
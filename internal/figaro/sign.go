@@ -0,0 +1,33 @@
+package figaro
+
+import (
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provenance"
+)
+
+// sign signs msg, the next message to be appended, with its author's key
+// when the signer has one. The signature covers the chain through msg.
+func (a *Agent) sign(msg *message.Message) {
+	if a.signer == nil || msg.Author == "" {
+		return
+	}
+	key := a.signer(msg.Author)
+	if key == nil {
+		return
+	}
+	msg.Signature = provenance.Sign(key, provenance.Next(a.chainTail(), *msg))
+}
+
+// chainTail is the provenance link through the last logged message. It
+// hashes only what was appended since the previous call.
+func (a *Agent) chainTail() provenance.Hash {
+	entries := a.figLog.Read()
+	if len(entries) < a.chainN {
+		a.chainN, a.chainHead = 0, provenance.Hash{}
+	}
+	for _, e := range entries[a.chainN:] {
+		a.chainHead = provenance.Next(a.chainHead, e.Payload)
+	}
+	a.chainN = len(entries)
+	return a.chainHead
+}
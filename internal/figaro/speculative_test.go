@@ -3,6 +3,7 @@ package figaro_test
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -394,3 +395,128 @@ func assistantToolInvokeIDs(m message.Message) []string {
 	}
 	return ids
 }
+
+// TestResponseGuard_BlocksToolCall checks that a blocking violation keeps
+// the call from running, answers it with an error result, and is fanned
+// out ahead of turn.done along with the answer check.
+func TestResponseGuard_BlocksToolCall(t *testing.T) {
+	rec := &recordingTool{name: "rec", zero: time.Now()}
+	reg := tool.NewRegistry()
+	require.NoError(t, reg.Register(rec))
+	prov := &staggeredProvider{
+		tools: []specTool{
+			{id: "tc_ok", name: "rec", args: map[string]interface{}{"id": "tc_ok"}},
+			{id: "tc_bad", name: "rec", args: map[string]interface{}{"id": "tc_bad", "cmd": "rm -rf /"}},
+		},
+	}
+	var mu sync.Mutex
+	var answers []string
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":    json.RawMessage(`"mock"`),
+		"system.provider": json.RawMessage(`"staggered"`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "guard-001",
+		SocketPath: "/tmp/guard-test.sock",
+		Provider:   prov,
+		Tools:      reg,
+		Chalkboard: cb,
+		ResponseGuard: func(_, stage, name, text string) []rpc.GuardViolation {
+			switch {
+			case stage == "tool" && strings.Contains(text, "rm -rf"):
+				return []rpc.GuardViolation{{Stage: stage, Rule: "no-rm", Tool: name, Match: "rm -rf", Blocked: true}}
+			case stage == "answer":
+				mu.Lock()
+				answers = append(answers, text)
+				mu.Unlock()
+				return []rpc.GuardViolation{{Stage: stage, Rule: "flag", Match: text}}
+			}
+			return nil
+		},
+	})
+	defer a.Kill()
+
+	ch, _ := subscribeChan(a)
+	submitPrompt(a, "go")
+	var violations []rpc.GuardViolation
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case n := <-ch:
+			switch n.Method {
+			case rpc.MethodGuardViolation:
+				violations = append(violations, n.Params.(rpc.GuardViolation))
+			case rpc.MethodTurnDone:
+				done = true
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for turn.done")
+		}
+	}
+
+	_, ran := rec.startTimeOf("tc_bad")
+	assert.False(t, ran, "blocked call ran")
+	_, ran = rec.startTimeOf("tc_ok")
+	assert.True(t, ran, "allowed call did not run")
+
+	res := findToolResult(a.Context())
+	require.NotNil(t, res)
+	for _, c := range res.Content {
+		if c.ToolCallID == "tc_bad" {
+			assert.True(t, c.IsError)
+			assert.Contains(t, c.Text, "Blocked by guard rule no-rm")
+		}
+	}
+	require.Len(t, violations, 2)
+	assert.Equal(t, rpc.GuardViolation{Stage: "tool", Rule: "no-rm", Tool: "rec", Match: "rm -rf", Blocked: true}, violations[0])
+	assert.Equal(t, "answer", violations[1].Stage)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"done"}, answers)
+}
+
+// TestToolSet_RefusesToolsOutsidePersona checks that a system.tools list
+// keeps other registered tools from running.
+func TestToolSet_RefusesToolsOutsidePersona(t *testing.T) {
+	rec := &recordingTool{name: "rec", zero: time.Now()}
+	reg := tool.NewRegistry()
+	require.NoError(t, reg.Register(rec))
+	prov := &staggeredProvider{
+		tools: []specTool{{id: "tc_1", name: "rec", args: map[string]interface{}{"id": "tc_1"}}},
+	}
+	cb, _ := chalkboard.Open("")
+	cb.Apply(chalkboard.Patch{Set: map[string]json.RawMessage{
+		"system.model":    json.RawMessage(`"mock"`),
+		"system.provider": json.RawMessage(`"staggered"`),
+		"system.tools":    json.RawMessage(`["read"]`),
+	}})
+	a := figaro.NewAgent(figaro.Config{
+		ID:         "toolset-001",
+		SocketPath: "/tmp/toolset-test.sock",
+		Provider:   prov,
+		Tools:      reg,
+		Chalkboard: cb,
+	})
+	defer a.Kill()
+
+	ch, _ := subscribeChan(a)
+	submitPrompt(a, "go")
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case n := <-ch:
+			done = n.Method == rpc.MethodTurnDone
+		case <-timeout:
+			t.Fatal("timeout waiting for turn.done")
+		}
+	}
+
+	_, ran := rec.startTimeOf("tc_1")
+	assert.False(t, ran, "tool outside the set ran")
+	res := findToolResult(a.Context())
+	require.NotNil(t, res)
+	require.Len(t, res.Content, 1)
+	assert.True(t, res.Content[0].IsError)
+	assert.Equal(t, "Unknown tool: rec", res.Content[0].Text)
+}
@@ -0,0 +1,148 @@
+package figaro
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// spillTailBytes is how much of a spilled block the live views keep.
+const spillTailBytes = 16 << 10
+
+// spillMarker heads a spilled block's live text; the path after "to" is
+// where the whole block is. sealTurn reads it back from there.
+const spillMarker = "[Streamed %d bytes to %s]\n\n…"
+
+// spill is one in-flight block past Limits.StreamSpillBytes. Everything
+// streamed so far is in the file at path; the in-flight message, and so
+// every live copy of it (turn state, aria server, clients), holds only
+// the marker and the tail. The sealed message is the provider's own copy.
+type spill struct {
+	path string
+	f    *os.File
+	n    int
+	tail string
+}
+
+// openSpill writes text, the block so far, to a new file in dir.
+func openSpill(dir string, block int, text string) (*spill, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("spill: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%d.txt", time.Now().UnixNano(), block))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("spill: %w", err)
+	}
+	sp := &spill{path: path, f: f}
+	sp.write(text)
+	return sp, nil
+}
+
+// write appends text to the file and the tail. A failed write is logged
+// once; the live tail carries on without it.
+func (sp *spill) write(text string) {
+	if sp.f != nil {
+		if _, err := sp.f.WriteString(text); err != nil {
+			slog.Warn("stream spill write failed", "path", sp.path, "err", err)
+			sp.f.Close()
+			sp.f = nil
+		}
+	}
+	sp.n += len(text)
+	sp.tail += text
+	if len(sp.tail) > 2*spillTailBytes {
+		sp.tail = cutTail(sp.tail, spillTailBytes)
+	}
+}
+
+// view is the block's live text.
+func (sp *spill) view() string {
+	return fmt.Sprintf(spillMarker, sp.n, sp.path) + cutTail(sp.tail, spillTailBytes)
+}
+
+func (sp *spill) close() {
+	if sp.f != nil {
+		sp.f.Close()
+		sp.f = nil
+	}
+	os.Remove(sp.path)
+}
+
+// cutTail keeps about the last n bytes of s, from a line start when one
+// is near so the tail renders as whole lines.
+func cutTail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := len(s) - n
+	if j := strings.IndexByte(s[i:], '\n'); j >= 0 && j < 1024 {
+		return s[i+j+1:]
+	}
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
+
+// unspill restores spilled blocks of m from their files, for sealing an
+// interrupted answer. A block whose file is under dir and readable gets
+// its whole text back; any other keeps the marker and tail.
+func unspill(m message.Message, dir string) message.Message {
+	if dir == "" {
+		return m
+	}
+	var out []message.Content
+	for i, c := range m.Content {
+		path, ok := spillPath(c.Text)
+		if !ok || filepath.Dir(path) != dir {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Warn("stream spill unreadable, sealing its tail", "path", path, "err", err)
+			continue
+		}
+		if out == nil {
+			out = append([]message.Content(nil), m.Content...)
+		}
+		out[i].Text = string(data)
+	}
+	if out != nil {
+		m.Content = out
+	}
+	return m
+}
+
+// spillPath is the file named by a spilled block's marker.
+func spillPath(text string) (string, bool) {
+	rest, ok := strings.CutPrefix(text, "[Streamed ")
+	if !ok {
+		return "", false
+	}
+	_, rest, ok = strings.Cut(rest, " bytes to ")
+	if !ok {
+		return "", false
+	}
+	path, _, ok := strings.Cut(rest, "]\n\n")
+	return path, ok
+}
+
+// spillDir is this aria's directory under Limits.SpillDir, or "" when
+// spilling is off.
+func (a *Agent) spillDir() string {
+	if a.limits.StreamSpillBytes <= 0 || a.limits.SpillDir == "" {
+		return ""
+	}
+	id := a.id
+	if id == "" {
+		id = "ephemeral"
+	}
+	return filepath.Join(a.limits.SpillDir, filepath.Base(id))
+}
@@ -0,0 +1,87 @@
+package figaro
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// streamLines feeds n numbered lines to s as one delta each and returns
+// the whole text.
+func streamLines(s *asm, kind message.ContentType, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		line := strings.Repeat("x", 60) + " line " + string(rune('a'+i%26)) + "\n"
+		sb.WriteString(line)
+		s.addText(kind, line)
+	}
+	return sb.String()
+}
+
+func TestAsm_AccumulatesBlocks(t *testing.T) {
+	s := newAsm(message.RoleAssistant)
+	s.addText(message.ContentThinking, "hmm, ")
+	s.addText(message.ContentThinking, "ok")
+	s.toolOpen("c1", "bash")
+	s.addText(message.ContentProse, "Hello, ")
+	s.addText(message.ContentProse, "world.")
+
+	m := s.message()
+	require.Len(t, m.Content, 3)
+	assert.Equal(t, "hmm, ok", m.Content[0].Text)
+	assert.Equal(t, message.ContentToolInvoke, m.Content[1].Type)
+	assert.Equal(t, "Hello, world.", m.Content[2].Text)
+}
+
+func TestAsm_SpillsLongBlock(t *testing.T) {
+	dir := t.TempDir()
+	s := newAsm(message.RoleAssistant)
+	s.spillAt, s.spillDir = 4<<10, dir
+	s.addText(message.ContentThinking, "short thought")
+	whole := streamLines(s, message.ContentProse, 2000) // ~140 KiB
+
+	m := s.message()
+	require.Len(t, m.Content, 2)
+	assert.Equal(t, "short thought", m.Content[0].Text, "blocks under the limit stay whole")
+	live := m.Content[1].Text
+	assert.Less(t, len(live), spillTailBytes+512, "live text is bounded")
+	assert.True(t, strings.HasSuffix(whole, live[strings.Index(live, "…")+len("…"):]), "live text ends with the stream's tail")
+
+	path, ok := spillPath(live)
+	require.True(t, ok, live)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, whole, string(data))
+
+	sealed := unspill(*m, dir)
+	assert.Equal(t, whole, sealed.Content[1].Text)
+	assert.Equal(t, live, m.Content[1].Text, "unspill leaves the live message alone")
+
+	s.closeSpills()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "spill removed after the round")
+	assert.Equal(t, live, unspill(*m, dir).Content[1].Text, "a missing spill seals its tail")
+}
+
+func TestAsm_NoSpillWhenOff(t *testing.T) {
+	s := newAsm(message.RoleAssistant)
+	s.spillDir = t.TempDir()
+	whole := streamLines(s, message.ContentProse, 2000)
+	assert.Equal(t, whole, s.message().Content[0].Text)
+	entries, err := os.ReadDir(s.spillDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestUnspill_IgnoresPathsOutsideDir(t *testing.T) {
+	other := t.TempDir()
+	require.NoError(t, os.WriteFile(other+"/1-0.txt", []byte("secret"), 0o600))
+	text := "[Streamed 6 bytes to " + other + "/1-0.txt]\n\n…cret"
+	m := message.Message{Content: []message.Content{message.TextContent(text)}}
+	assert.Equal(t, text, unspill(m, t.TempDir()).Content[0].Text)
+}
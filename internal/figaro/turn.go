@@ -17,6 +17,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/chalkboard"
 	"github.com/jack-work/figaro/internal/compose"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/livedoc"
 	"github.com/jack-work/figaro/internal/message"
 	figOtel "github.com/jack-work/figaro/internal/otel"
@@ -157,6 +158,11 @@ func (a *Agent) runTurn(ctx context.Context, prompt event) {
 	// usually catches this, but cover the case where the boot check
 	// missed (e.g. dangling state appeared after boot).
 	repairInterruptedTail(a.figLog, a.id)
+	a.turnForce = prompt.force
+	a.turnFrom = 0
+	if tail, ok := a.figLog.PeekTail(); ok {
+		a.turnFrom = tail.FigaroLT
+	}
 	if _, err := a.appendUserPrompt(prompt, true); err != nil {
 		a.endTurn(fmt.Sprintf("error: append message: %s", err))
 		return
@@ -179,6 +185,7 @@ func (a *Agent) runTurn(ctx context.Context, prompt event) {
 func (a *Agent) appendUserPrompt(prompt event, allowInlineBoot bool) (store.Entry[message.Message], error) {
 	msg := message.Message{
 		Role:      message.RoleUser,
+		Author:    prompt.author,
 		Timestamp: time.Now().UnixMilli(),
 	}
 	var combined chalkboard.Patch
@@ -217,6 +224,7 @@ func (a *Agent) appendUserPrompt(prompt event, allowInlineBoot bool) (store.Entr
 	if prompt.text != "" {
 		msg.Content = append(msg.Content, message.TextContent(prompt.text))
 	}
+	a.sign(&msg)
 	entry, err := a.figLog.Append(store.Entry[message.Message]{Payload: msg})
 	if err != nil {
 		return store.Entry[message.Message]{}, err
@@ -227,7 +235,7 @@ func (a *Agent) appendUserPrompt(prompt event, allowInlineBoot bool) (store.Entr
 	a.refreshMetrics()
 
 	if prompt.text != "" {
-		a.emitSnapshot("user", []livedoc.Node{{Type: livedoc.NodeProse, Markdown: prompt.text}})
+		a.emitSnapshot("user", prompt.author, []livedoc.Node{{Type: livedoc.NodeProse, Markdown: prompt.text}})
 		a.emitCommit()
 	}
 	return entry, nil
@@ -243,7 +251,7 @@ func (a *Agent) startAssistantUnit() {
 	a.argPartials = map[string]string{}
 	a.toolTimings = map[string]compose.ToolTiming{}
 	a.turn = newTurnState()
-	a.emitSnapshot("assistant", nil)
+	a.emitSnapshot("assistant", "", nil)
 }
 
 // driveOneRound runs one provider.Send + tool dispatch cycle. The
@@ -268,10 +276,16 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 	in := provider.SendInput{
 		AriaID:     a.id,
 		FigLog:     deferredLog,
-		Snapshot:   a.chalkboard.Snapshot(),
+		Snapshot:   provider.ResolvePins(provider.DefaultReplyLang(a.chalkboard.Snapshot(), a.replyLang), a.figLog),
 		Chalkboard: a.chalkAccessor(),
 		Tools:      a.toolDefs(),
-		MaxTokens:  a.chalkboardInt("system.max_tokens"),
+		MaxTokens:  a.maxTokens(),
+	}
+	var budgetErr error
+	if !allowSteering && a.budget != nil {
+		budgetErr = a.budget(a.id, a.countInput(turnCtx, in), a.turnForce)
+	} else {
+		a.countInputLater(turnCtx, in)
 	}
 	sendDone := make(chan error, 1)
 	go func() {
@@ -282,6 +296,10 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 			close(bus.events)
 			close(bus.toolsReady)
 		}()
+		if budgetErr != nil {
+			sendDone <- budgetErr
+			return
+		}
 		started := time.Now()
 		err := a.prov.Send(turnCtx, in, bus)
 		figOtel.RecordRequestDuration(turnCtx, time.Since(started),
@@ -318,6 +336,8 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 	// drain loop, then drop the in-flight copy so compose reads it from the log
 	// instead — otherwise it would be counted twice.
 	asmMsg := newAsm(message.RoleAssistant)
+	asmMsg.spillAt, asmMsg.spillDir = a.limits.StreamSpillBytes, a.spillDir()
+	defer asmMsg.closeSpills()
 	sealedInline := false
 	metricsReady := false
 	var roundErr error
@@ -450,6 +470,7 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 				}
 			case toolEnd:
 				a.finishToolTiming(te.id, te.at)
+				a.notifyTrimmedAll(te.trimmed)
 				status := "ok"
 				if te.outcome.isErr {
 					status = "error"
@@ -497,6 +518,9 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 		if !a.isInterrupted() {
 			a.emitDelta(a.composeTurn(nil))
 		}
+		if lastFig.StopReason == message.StopLength && a.limits.ResponseTokens > 0 && in.MaxTokens == a.limits.ResponseTokens {
+			a.notifyTrimmed(rpc.Trimmed{Stage: "response", MaxTokens: in.MaxTokens})
+		}
 	}
 
 	if a.isInterrupted() {
@@ -514,6 +538,7 @@ func (a *Agent) driveOneRound(turnCtx context.Context, allowSteering bool) (done
 		return true
 	}
 	if sendErr != nil {
+		a.turnErrKind = errs.KindOf(sendErr)
 		if a.turn == nil {
 			if sealed {
 				a.serviceForks()
@@ -770,6 +795,7 @@ toolLoop:
 			}
 		case toolEnd:
 			a.finishToolTiming(te.id, te.at)
+			a.notifyTrimmedAll(te.trimmed)
 			outcomes[te.id] = te.outcome
 			status := "ok"
 			if te.outcome.isErr {
@@ -877,6 +903,7 @@ type toolEvent struct {
 	chunk   string
 	final   message.Content // toolEnd: the sealed tool_result block
 	outcome toolOutcome     // toolEnd: raw content for IR assembly
+	trimmed []rpc.Trimmed   // toolEnd: limit cuts, fanned out when folded
 }
 
 // toolOutcome holds the result of a single dispatched tool execution.
@@ -944,6 +971,7 @@ func (s *specDispatcher) dispatch(turnCtx context.Context, a *Agent, tc message.
 		s.events <- toolEvent{kind: toolBegin, id: tc.ToolCallID, name: tc.ToolName, at: time.Now().UnixMilli()}
 
 		emitEnd := func(oc toolOutcome) {
+			oc, cuts := a.shrinkToolResult(turnCtx, tc, oc)
 			var text string
 			for _, c := range oc.content {
 				if c.Type == message.ContentProse {
@@ -961,10 +989,14 @@ func (s *specDispatcher) dispatch(turnCtx context.Context, a *Agent, tc message.
 				at:      time.Now().UnixMilli(),
 				final:   message.ToolResultContent(tc.ToolCallID, tc.ToolName, text, oc.isErr),
 				outcome: oc,
+				trimmed: cuts,
 			}
 		}
 
 		t, ok := a.tools.Get(tc.ToolName)
+		if allowed := a.allowedTools(); allowed != nil && !allowed[tc.ToolName] {
+			ok = false
+		}
 		if !ok {
 			emitEnd(toolOutcome{
 				content: []message.Content{message.TextContent(fmt.Sprintf("Unknown tool: %s", tc.ToolName))},
@@ -972,6 +1004,10 @@ func (s *specDispatcher) dispatch(turnCtx context.Context, a *Agent, tc message.
 			})
 			return
 		}
+		if text, blocked := a.checkToolCall(tc); blocked {
+			emitEnd(toolOutcome{content: []message.Content{message.TextContent(text)}, isErr: true})
+			return
+		}
 		var firstChunk bool
 		onChunk := func(chunk []byte) {
 			if a.isInterrupted() {
@@ -1115,9 +1151,10 @@ func logComposeFrame(dir, ariaID string, hasInflight bool, nodes []livedoc.Node)
 
 // emitSnapshot opens a new unit at the next figaro LT and sets its initial
 // nodes. The aria server diffs subsequent Updates against this internally.
-func (a *Agent) emitSnapshot(role string, nodes []livedoc.Node) {
+// author is the user message's sender, if any.
+func (a *Agent) emitSnapshot(role, author string, nodes []livedoc.Node) {
 	a.unitLT++
-	a.ariaSrv.Open(a.unitLT, role)
+	a.ariaSrv.OpenBy(a.unitLT, role, author)
 	if len(nodes) > 0 {
 		a.ariaSrv.Update(nodes)
 	}
@@ -1180,6 +1217,16 @@ func firstChars(s string, n int) string {
 type asm struct {
 	msg     message.Message
 	toolIdx map[string]int
+
+	// text accumulates the last text block; its String shares the buffer,
+	// so the block grows amortized instead of recopying per delta.
+	text *strings.Builder
+
+	// spillAt and spillDir are Limits.StreamSpillBytes and the aria's
+	// spill directory; past spillAt a text block goes to a spill file.
+	spillAt  int
+	spillDir string
+	spills   map[int]*spill
 }
 
 func newAsm(role message.Role) *asm {
@@ -1191,11 +1238,47 @@ func (s *asm) addText(kind message.ContentType, text string) {
 		return
 	}
 	n := len(s.msg.Content)
-	if n > 0 && s.msg.Content[n-1].Type == kind {
-		s.msg.Content[n-1].Text += text
+	if n == 0 || s.msg.Content[n-1].Type != kind {
+		s.msg.Content = append(s.msg.Content, message.Content{Type: kind})
+		s.text = &strings.Builder{}
+		n++
+	}
+	c := &s.msg.Content[n-1]
+	if sp := s.spills[n-1]; sp != nil {
+		sp.write(text)
+		c.Text = sp.view()
 		return
 	}
-	s.msg.Content = append(s.msg.Content, message.Content{Type: kind, Text: text})
+	s.text.WriteString(text)
+	c.Text = s.text.String()
+	if s.spillAt > 0 && s.spillDir != "" && s.text.Len() > s.spillAt {
+		s.spill(n - 1)
+	}
+}
+
+// spill moves block i to a spill file. If the file cannot be made the
+// block stays in memory and spilling is off for the round.
+func (s *asm) spill(i int) {
+	sp, err := openSpill(s.spillDir, i, s.text.String())
+	if err != nil {
+		slog.Warn("stream spill failed, keeping the answer in memory", "err", err)
+		s.spillAt = 0
+		return
+	}
+	if s.spills == nil {
+		s.spills = map[int]*spill{}
+	}
+	s.spills[i] = sp
+	s.text = nil
+	s.msg.Content[i].Text = sp.view()
+}
+
+// closeSpills closes and removes the round's spill files.
+func (s *asm) closeSpills() {
+	for _, sp := range s.spills {
+		sp.close()
+	}
+	s.spills = nil
 }
 
 func (s *asm) toolOpen(id, name string) {
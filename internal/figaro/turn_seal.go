@@ -108,7 +108,7 @@ func (a *Agent) sealTurn() ([]message.Message, error) {
 		}
 		return []message.Message{e.Payload}, nil
 	}
-	assistant := t.assistant
+	assistant := unspill(t.assistant, a.spillDir())
 	assistant.Role = message.RoleAssistant
 	assistant.StopReason = message.StopAborted
 	if len(assistant.Content) == 0 {
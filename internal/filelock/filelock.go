@@ -0,0 +1,26 @@
+// Package filelock takes advisory whole-file locks that work the same on
+// Unix (flock) and Windows (LockFileEx). A lock belongs to the open file:
+// closing it releases the lock, and so does the process exiting.
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Acquire opens (creating it if needed) the lock file at path and blocks
+// until it holds it. Close the returned file to release the lock.
+func Acquire(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := Lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
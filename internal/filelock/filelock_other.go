@@ -0,0 +1,17 @@
+//go:build !unix && !windows
+
+package filelock
+
+import (
+	"errors"
+	"os"
+)
+
+// TryLock always fails where there is no file locking.
+func TryLock(*os.File) error { return errors.ErrUnsupported }
+
+// Lock always fails where there is no file locking.
+func Lock(*os.File) error { return errors.ErrUnsupported }
+
+// Unlock always fails where there is no file locking.
+func Unlock(*os.File) error { return errors.ErrUnsupported }
@@ -0,0 +1,30 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	held, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := TryLock(other); err == nil {
+		t.Fatal("second lock taken while the first is held")
+	}
+	held.Close()
+	if err := TryLock(other); err != nil {
+		t.Fatalf("lock not released on close: %v", err)
+	}
+	if err := Unlock(other); err != nil {
+		t.Fatal(err)
+	}
+}
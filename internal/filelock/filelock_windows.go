@@ -0,0 +1,30 @@
+//go:build windows
+
+package filelock
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// TryLock takes an exclusive lock on f without waiting; it fails when
+// another open file holds one.
+func TryLock(f *os.File) error {
+	return lock(f, windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY)
+}
+
+// Lock takes an exclusive lock on f, waiting for it.
+func Lock(f *os.File) error {
+	return lock(f, windows.LOCKFILE_EXCLUSIVE_LOCK)
+}
+
+// Unlock releases f's lock.
+func Unlock(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, new(windows.Overlapped))
+}
+
+// lock locks the file's first byte, which is all the lock stands for.
+func lock(f *os.File, flags uint32) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, new(windows.Overlapped))
+}
@@ -0,0 +1,251 @@
+// Package gitsync keeps a directory in a Git repository and moves it
+// between machines through a remote. figaro points it at the aria store,
+// whose segments are append-only JSONL, so commits stay small and most
+// pulls fast-forward.
+//
+// Two machines that wrote to the same file between syncs diverge. Pull
+// merges; a file both sides changed is resolved whole, to one side's
+// copy (Strategy), never line by line, since interleaving two appends to
+// a segment would corrupt it.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Branch is the branch the store lives on, locally and on the remote.
+const Branch = "main"
+
+// Remote is the name of the remote Init configures.
+const Remote = "origin"
+
+// Strategy picks the copy a conflicted file keeps.
+type Strategy string
+
+const (
+	Ours   Strategy = "ours"
+	Theirs Strategy = "theirs"
+)
+
+// ErrNotRepo means Init has not been run on the directory.
+var ErrNotRepo = errors.New("not a sync repository; run figaro sync init")
+
+// Repo is a synced directory. Its methods serialize, so the daemon's
+// per-turn commits and a CLI pull in the same process never race for
+// the index.
+type Repo struct {
+	Dir    string
+	Ignore []string
+
+	mu sync.Mutex
+}
+
+// Open returns the repo at dir.
+func Open(dir string, ignore ...string) *Repo {
+	return &Repo{Dir: dir, Ignore: ignore}
+}
+
+// IsRepo reports whether Init has run on the directory.
+func (r *Repo) IsRepo() bool {
+	_, err := os.Stat(filepath.Join(r.Dir, ".git"))
+	return err == nil
+}
+
+// git runs a git subcommand in the repo and returns its trimmed stdout.
+// A failure carries git's stderr.
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.Dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Init makes the directory a repository on Branch, with an identity for
+// commits if git has none, and sets remote when it is not empty. When
+// the remote already holds a store it is checked out, which requires
+// the directory to hold no files of its own; otherwise the directory is
+// committed as it is.
+func (r *Repo) Init(ctx context.Context, remote string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.Dir, 0o700); err != nil {
+		return err
+	}
+	if !r.IsRepo() {
+		if _, err := r.git(ctx, "init", "-q", "-b", Branch); err != nil {
+			return err
+		}
+	}
+	if email, _ := r.git(ctx, "config", "user.email"); email == "" {
+		host, _ := os.Hostname()
+		if host == "" {
+			host = "localhost"
+		}
+		if _, err := r.git(ctx, "config", "user.email", "figaro@"+host); err != nil {
+			return err
+		}
+		if _, err := r.git(ctx, "config", "user.name", "figaro"); err != nil {
+			return err
+		}
+	}
+	if err := r.writeIgnore(); err != nil {
+		return err
+	}
+	if remote != "" {
+		if _, err := r.git(ctx, "remote", "get-url", Remote); err == nil {
+			_, err = r.git(ctx, "remote", "set-url", Remote, remote)
+			if err != nil {
+				return err
+			}
+		} else if _, err := r.git(ctx, "remote", "add", Remote, remote); err != nil {
+			return err
+		}
+		if _, err := r.git(ctx, "fetch", "-q", Remote); err != nil {
+			return err
+		}
+		if _, err := r.git(ctx, "rev-parse", "--verify", "-q", Remote+"/"+Branch); err == nil {
+			return r.adoptRemote(ctx)
+		}
+	}
+	_, err := r.commit(ctx, "figaro: start sync")
+	return err
+}
+
+// adoptRemote checks out the remote's store into a directory that has
+// no history of its own. Two stores started apart cannot be merged file
+// by file: both number their nodes from the same root.
+func (r *Repo) adoptRemote(ctx context.Context) error {
+	if _, err := r.git(ctx, "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		if _, err := r.git(ctx, "merge-base", "HEAD", Remote+"/"+Branch); err != nil {
+			return fmt.Errorf("the remote holds a different store; back up and move %s aside, then init again", r.Dir)
+		}
+		return nil
+	}
+	if out, _ := r.git(ctx, "status", "--porcelain", "--untracked-files=all"); out != "" && out != "?? .gitignore" {
+		return fmt.Errorf("the remote already holds a store and %s is not empty; back up and move it aside, then init again", r.Dir)
+	}
+	os.Remove(filepath.Join(r.Dir, ".gitignore"))
+	_, err := r.git(ctx, "checkout", "-q", "-b", Branch, "--track", Remote+"/"+Branch)
+	return err
+}
+
+func (r *Repo) writeIgnore() error {
+	path := filepath.Join(r.Dir, ".gitignore")
+	if _, err := os.Stat(path); err == nil || len(r.Ignore) == 0 {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strings.Join(r.Ignore, "\n")+"\n"), 0o644)
+}
+
+// Commit stages every change and commits it with msg. It reports
+// whether there was anything to commit.
+func (r *Repo) Commit(ctx context.Context, msg string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.IsRepo() {
+		return false, ErrNotRepo
+	}
+	return r.commit(ctx, msg)
+}
+
+func (r *Repo) commit(ctx context.Context, msg string) (bool, error) {
+	if _, err := r.git(ctx, "add", "-A"); err != nil {
+		return false, err
+	}
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if _, err := r.git(ctx, "commit", "-q", "--no-verify", "-m", msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Push commits pending changes and pushes Branch. A remote that moved
+// on is reported as such; pull first.
+func (r *Repo) Push(ctx context.Context, msg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.IsRepo() {
+		return ErrNotRepo
+	}
+	if _, err := r.commit(ctx, msg); err != nil {
+		return err
+	}
+	if _, err := r.git(ctx, "push", "-q", "-u", Remote, Branch); err != nil {
+		if strings.Contains(err.Error(), "rejected") || strings.Contains(err.Error(), "fetch first") {
+			return fmt.Errorf("the remote has changes this machine lacks; run figaro sync pull first")
+		}
+		return err
+	}
+	return nil
+}
+
+// Pull commits pending changes, fetches, and merges the remote's Branch.
+// Files both sides changed are resolved to s's copy and returned.
+func (r *Repo) Pull(ctx context.Context, msg string, s Strategy) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.IsRepo() {
+		return nil, ErrNotRepo
+	}
+	if s != Ours && s != Theirs {
+		return nil, fmt.Errorf("strategy %q: want ours or theirs", s)
+	}
+	if _, err := r.commit(ctx, msg); err != nil {
+		return nil, err
+	}
+	if _, err := r.git(ctx, "fetch", "-q", Remote); err != nil {
+		return nil, err
+	}
+	upstream := Remote + "/" + Branch
+	if _, err := r.git(ctx, "rev-parse", "--verify", "-q", upstream); err != nil {
+		return nil, nil
+	}
+	if _, err := r.git(ctx, "merge", "-q", "--no-edit", upstream); err == nil {
+		return nil, nil
+	}
+	out, err := r.git(ctx, "diff", "--name-only", "--diff-filter=U")
+	if err != nil || out == "" {
+		r.git(ctx, "merge", "--abort")
+		if err == nil {
+			err = fmt.Errorf("merge %s failed", upstream)
+		}
+		return nil, err
+	}
+	conflicts := strings.Split(out, "\n")
+	for _, f := range conflicts {
+		if _, err := r.git(ctx, "checkout", "--"+string(s), "--", f); err == nil {
+			_, err = r.git(ctx, "add", "--", f)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// The chosen side deleted the file.
+		if _, err := r.git(ctx, "rm", "-q", "--", f); err != nil {
+			return nil, err
+		}
+	}
+	body := fmt.Sprintf("Merge %s, keeping %s for:\n\n%s", upstream, s, strings.Join(conflicts, "\n"))
+	if _, err := r.git(ctx, "commit", "-q", "--no-verify", "-m", body); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,153 @@
+package gitsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", "-b", Branch, dir).CombinedOutput(); err != nil {
+		t.Fatalf("bare: %v %s", err, out)
+	}
+	return dir
+}
+
+func write(t *testing.T, dir, name, body string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	os.MkdirAll(filepath.Dir(p), 0o700)
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendTo(t *testing.T, dir, name, line string) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(line + "\n")
+	f.Close()
+}
+
+func read(t *testing.T, dir, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// pair starts a store on machine a, pushes it, and adopts it on b.
+func pair(t *testing.T) (a, b *Repo) {
+	t.Helper()
+	ctx := context.Background()
+	remote := newRemote(t)
+	a = Open(filepath.Join(t.TempDir(), "a"), ".lock")
+	write(t, a.Dir, "ir/1.jsonl", "{\"lt\":1}\n")
+	write(t, a.Dir, ".lock", "")
+	if err := a.Init(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Push(ctx, "push"); err != nil {
+		t.Fatal(err)
+	}
+	b = Open(filepath.Join(t.TempDir(), "b"), ".lock")
+	if err := b.Init(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func TestInitAdoptsRemote(t *testing.T) {
+	a, b := pair(t)
+	if got := read(t, b.Dir, "ir/1.jsonl"); got != "{\"lt\":1}\n" {
+		t.Errorf("adopted = %q", got)
+	}
+	if out, _ := b.git(context.Background(), "ls-files"); strings.Contains(out, ".lock") {
+		t.Errorf("ignored file committed: %s", out)
+	}
+
+	c := Open(filepath.Join(t.TempDir(), "c"))
+	write(t, c.Dir, "ir/1.jsonl", "local\n")
+	remote, _ := a.git(context.Background(), "remote", "get-url", Remote)
+	if err := c.Init(context.Background(), remote); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("non-empty local store: %v", err)
+	}
+}
+
+func TestPullFastForwards(t *testing.T) {
+	ctx := context.Background()
+	a, b := pair(t)
+	appendTo(t, b.Dir, "ir/1.jsonl", "{\"lt\":2}")
+	if err := b.Push(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err := a.Pull(ctx, "a", Ours)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("pull: %v %v", conflicts, err)
+	}
+	if got := read(t, a.Dir, "ir/1.jsonl"); got != "{\"lt\":1}\n{\"lt\":2}\n" {
+		t.Errorf("pulled = %q", got)
+	}
+}
+
+func TestPullResolvesDivergedFilesWhole(t *testing.T) {
+	for _, s := range []Strategy{Ours, Theirs} {
+		t.Run(string(s), func(t *testing.T) {
+			ctx := context.Background()
+			a, b := pair(t)
+			appendTo(t, a.Dir, "ir/1.jsonl", "{\"lt\":2,\"from\":\"a\"}")
+			write(t, a.Dir, "ir/2.jsonl", "a only\n")
+			if err := a.Push(ctx, "a"); err != nil {
+				t.Fatal(err)
+			}
+			appendTo(t, b.Dir, "ir/1.jsonl", "{\"lt\":2,\"from\":\"b\"}")
+			if err := b.Push(ctx, "b"); err == nil || !strings.Contains(err.Error(), "pull first") {
+				t.Fatalf("diverged push: %v", err)
+			}
+
+			conflicts, err := b.Pull(ctx, "b", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(conflicts) != 1 || conflicts[0] != "ir/1.jsonl" {
+				t.Errorf("conflicts = %v", conflicts)
+			}
+			want := map[Strategy]string{Ours: "b", Theirs: "a"}[s]
+			got := read(t, b.Dir, "ir/1.jsonl")
+			if got != "{\"lt\":1}\n{\"lt\":2,\"from\":\""+want+"\"}\n" {
+				t.Errorf("resolved = %q", got)
+			}
+			if read(t, b.Dir, "ir/2.jsonl") != "a only\n" {
+				t.Error("clean change from the remote lost")
+			}
+			if err := b.Push(ctx, "b"); err != nil {
+				t.Errorf("push after pull: %v", err)
+			}
+		})
+	}
+}
+
+func TestNotRepo(t *testing.T) {
+	r := Open(t.TempDir())
+	if _, err := r.Commit(context.Background(), "x"); err != ErrNotRepo {
+		t.Errorf("commit: %v", err)
+	}
+	if _, err := r.Pull(context.Background(), "x", Ours); err != ErrNotRepo {
+		t.Errorf("pull: %v", err)
+	}
+}
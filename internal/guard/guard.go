@@ -0,0 +1,122 @@
+// Package guard finds secrets and personal data in outbound text — API
+// keys, private keys, email addresses and caller-supplied patterns — so a
+// prompt can be blocked or masked before it reaches a provider.
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Finding is one match: its rule's kind and the byte span in the text.
+type Finding struct {
+	Kind       string
+	Start, End int
+}
+
+type rule struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// builtin are the credential shapes every Scanner looks for.
+var builtin = []rule{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----(?s:.*?-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----)?`)},
+	{"anthropic key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"openai key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{32,}`)},
+	{"aws access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google api key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+}
+
+var emailRule = rule{"email address", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)}
+
+// Scanner holds the rules one guard applies.
+type Scanner struct {
+	rules []rule
+}
+
+// New builds a Scanner from the built-in rules, the email rule when emails
+// is set, and extra patterns keyed by the kind they report. A pattern that
+// doesn't compile is left out and reported; the Scanner is still returned
+// with every other rule.
+func New(emails bool, extra map[string]string) (*Scanner, error) {
+	s := &Scanner{rules: append([]rule(nil), builtin...)}
+	if emails {
+		s.rules = append(s.rules, emailRule)
+	}
+	kinds := make([]string, 0, len(extra))
+	for k := range extra {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	var errs []error
+	for _, k := range kinds {
+		re, err := regexp.Compile(extra[k])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pattern %s: %w", k, err))
+			continue
+		}
+		s.rules = append(s.rules, rule{k, re})
+	}
+	return s, errors.Join(errs...)
+}
+
+// Scan returns text's findings in order. Overlapping matches collapse to
+// the earliest, longest one.
+func (s *Scanner) Scan(text string) []Finding {
+	var all []Finding
+	for _, r := range s.rules {
+		for _, loc := range r.re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				all = append(all, Finding{Kind: r.kind, Start: loc[0], End: loc[1]})
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Start != all[j].Start {
+			return all[i].Start < all[j].Start
+		}
+		return all[i].End > all[j].End
+	})
+	var out []Finding
+	for _, f := range all {
+		if n := len(out); n > 0 && f.Start < out[n-1].End {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// Mask replaces each finding in text with "[REDACTED <kind>]". findings
+// must come from Scan on the same text.
+func Mask(text string, findings []Finding) string {
+	if len(findings) == 0 {
+		return text
+	}
+	var b []byte
+	last := 0
+	for _, f := range findings {
+		b = append(b, text[last:f.Start]...)
+		b = append(b, "[REDACTED "+f.Kind+"]"...)
+		last = f.End
+	}
+	return string(append(b, text[last:]...))
+}
+
+// Kinds lists findings' distinct kinds in first-seen order.
+func Kinds(findings []Finding) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, f := range findings {
+		if !seen[f.Kind] {
+			seen[f.Kind] = true
+			out = append(out, f.Kind)
+		}
+	}
+	return out
+}
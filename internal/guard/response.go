@@ -0,0 +1,87 @@
+package guard
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Checkpoints a Rule can apply at.
+const (
+	StageTool   = "tool"   // a tool call, before it runs
+	StageAnswer = "answer" // a finished turn's answer
+)
+
+// Rule checks model output at one stage. Tool, when set, limits a tool
+// rule to that tool. Block stops a tool call; answers are only flagged.
+type Rule struct {
+	Name  string
+	Stage string
+	Tool  string
+	Re    *regexp.Regexp
+	Block bool
+}
+
+// Violation is one rule's match.
+type Violation struct {
+	Rule  string
+	Match string
+	Block bool
+}
+
+// destructive are shell commands that destroy data or the machine.
+var destructive = []struct{ name, pattern string }{
+	{"rm-root", `\brm\s+(-[A-Za-z]*[rR][A-Za-z]*\s+|-[A-Za-z]*f[A-Za-z]*\s+|--\S+\s+)*(/|~|\$HOME|/\*)(\s|$|;|&|\|)`},
+	{"mkfs", `\bmkfs(\.\w+)?\s`},
+	{"dd-device", `\bdd\b[^\n;|&]*\bof=/dev/`},
+	{"fork-bomb", `:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`},
+	{"force-push", `\bgit\s+push\b[^\n;|&]*(\s--force|\s-f)(\s|$)`},
+	{"power", `(^|[;&|(\n]\s*)(sudo\s+)?(shutdown|reboot|halt|poweroff)\b`},
+	{"chmod-root", `\bchmod\s+(-R\s+)?[0-7]{3,4}\s+/(\s|$)`},
+}
+
+// DestructiveRules are blocking rules for the bash tool.
+func DestructiveRules() []Rule {
+	out := make([]Rule, len(destructive))
+	for i, d := range destructive {
+		out[i] = Rule{Name: d.name, Stage: StageTool, Tool: "bash", Re: regexp.MustCompile(d.pattern), Block: true}
+	}
+	return out
+}
+
+// NewRule compiles a rule. stage defaults to StageTool; only tool rules
+// can block.
+func NewRule(name, stage, tool, pattern string, block bool) (Rule, error) {
+	if stage == "" {
+		stage = StageTool
+	}
+	switch stage {
+	case StageTool:
+	case StageAnswer:
+		if tool != "" {
+			return Rule{}, fmt.Errorf("rule %s: tool is only for tool rules", name)
+		}
+		block = false
+	default:
+		return Rule{}, fmt.Errorf("rule %s: stage %q: want tool or answer", name, stage)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", name, err)
+	}
+	return Rule{Name: name, Stage: stage, Tool: tool, Re: re, Block: block}, nil
+}
+
+// Check runs the rules for stage against text. tool names the call at
+// StageTool.
+func Check(rules []Rule, stage, tool, text string) []Violation {
+	var out []Violation
+	for _, r := range rules {
+		if r.Stage != stage || (r.Tool != "" && r.Tool != tool) {
+			continue
+		}
+		if m := r.Re.FindString(text); m != "" {
+			out = append(out, Violation{Rule: r.Name, Match: m, Block: r.Block})
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package guard
+
+import "testing"
+
+func TestDestructiveRules(t *testing.T) {
+	rules := DestructiveRules()
+	blocked := []string{
+		"rm -rf /",
+		"sudo rm -rf ~ ",
+		"rm -fr /*",
+		"mkfs.ext4 /dev/sda1",
+		"dd if=/dev/zero of=/dev/sda bs=1M",
+		":(){ :|:& };:",
+		"git push --force origin main",
+		"git push -f",
+		"sudo shutdown -h now",
+		"make install && reboot",
+		"cd /tmp\npoweroff",
+	}
+	for _, cmd := range blocked {
+		v := Check(rules, StageTool, "bash", cmd)
+		if len(v) == 0 || !v[0].Block {
+			t.Errorf("%q not blocked", cmd)
+		}
+	}
+	allowed := []string{
+		"rm -rf ./build",
+		"rm -rf /tmp/figaro-test",
+		"dd if=in.img of=out.img",
+		"git push origin main",
+		"git push --force-with-lease",
+		"go test ./...",
+		"grep -rn shutdown .",
+		"git log --grep=reboot",
+	}
+	for _, cmd := range allowed {
+		if v := Check(rules, StageTool, "bash", cmd); len(v) != 0 {
+			t.Errorf("%q flagged by %s", cmd, v[0].Rule)
+		}
+	}
+	if v := Check(rules, StageTool, "write", "rm -rf /"); len(v) != 0 {
+		t.Errorf("bash rule applied to write: %+v", v)
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	r, err := NewRule("no-prod", "", "", `prod-db`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := Check([]Rule{r}, StageTool, "read", "open prod-db.conf"); len(v) != 1 || !v[0].Block || v[0].Match != "prod-db" {
+		t.Fatalf("Check = %+v", v)
+	}
+	a, err := NewRule("apology", StageAnswer, "", `(?i)as an ai`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Block {
+		t.Error("answer rule blocks")
+	}
+	if v := Check([]Rule{a}, StageTool, "bash", "as an AI"); len(v) != 0 {
+		t.Error("answer rule applied to a tool call")
+	}
+	for _, bad := range []struct{ stage, tool, pattern string }{
+		{"reply", "", "x"},
+		{StageAnswer, "bash", "x"},
+		{StageTool, "", "("},
+	} {
+		if _, err := NewRule("bad", bad.stage, bad.tool, bad.pattern, false); err == nil {
+			t.Errorf("NewRule(%q, %q, %q) accepted", bad.stage, bad.tool, bad.pattern)
+		}
+	}
+}
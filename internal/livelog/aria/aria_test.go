@@ -1,6 +1,7 @@
 package aria
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -181,7 +182,7 @@ func TestServer_PatchOnGrowth(t *testing.T) {
 	}
 	c := NewClient()
 	var got string
-	c.OnLive = func(_ int, _ string, nodes []livedoc.Node) { got = nodes[0].Output }
+	c.OnLive = func(_ int, _, _ string, nodes []livedoc.Node) { got = nodes[0].Output }
 	for _, p := range rc.pages {
 		c.Apply(p)
 	}
@@ -261,6 +262,37 @@ func TestServer_ReadBefore(t *testing.T) {
 	}
 }
 
+func TestServer_ReadTail(t *testing.T) {
+	s := NewServer()
+	for lt := 1; lt <= 10; lt++ {
+		s.Commit(Message{LT: lt, Role: "user", Nodes: []livedoc.Node{prose("m")}})
+	}
+	lts := func(r AriaRead) []int {
+		var out []int
+		for _, c := range r.Committed {
+			out = append(out, c.LT)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		since, limit int
+		want         []int
+	}{
+		{0, 3, []int{8, 9, 10}},
+		{7, 5, []int{8, 9, 10}},
+		{4, 0, []int{5, 6, 7, 8, 9, 10}},
+		{10, 3, nil},
+	} {
+		got := lts(s.ReadTail(tc.since, tc.limit))
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Fatalf("ReadTail(%d, %d) = %v, want %v", tc.since, tc.limit, got, tc.want)
+		}
+	}
+	if got := lts(s.Read(0)); len(got) != 10 {
+		t.Fatalf("Read(0) = %v, want all ten", got)
+	}
+}
+
 func TestClient_ClosedLimitKeepsTailAndCursor(t *testing.T) {
 	c := NewClient()
 	c.SetClosedLimit(2)
@@ -293,3 +325,30 @@ func ltsFromMessages(messages []Message) []int {
 	}
 	return out
 }
+
+func TestAuthorRoundTrip(t *testing.T) {
+	s := NewServer()
+	c := NewClient()
+	var live, closed string
+	c.OnLive = func(_ int, _, author string, _ []livedoc.Node) { live = author }
+	c.OnClosed = func(m Message) { closed = m.Author }
+	defer s.Subscribe(c.Apply)()
+
+	s.OpenBy(1, "user", "alice")
+	s.Update([]livedoc.Node{prose("hello")})
+	s.Close()
+	if live != "alice" || closed != "alice" {
+		t.Fatalf("live %q closed %q", live, closed)
+	}
+
+	// A catch-up read carries the author on the full snapshot.
+	r := s.Read(0)
+	if len(r.Committed) != 1 || r.Committed[0].Author != "alice" {
+		t.Fatalf("read: %+v", r.Committed)
+	}
+	late := NewClient()
+	late.Apply(r)
+	if v := late.View(); len(v.Closed) != 1 || v.Closed[0].Author != "alice" {
+		t.Fatalf("late view: %+v", v.Closed)
+	}
+}
@@ -23,14 +23,15 @@ type Client struct {
 	closedLimit     int
 	lastCommittedLT int
 
-	openLT    int
-	openRole  string
-	openV     int
-	openOrder []string
-	openBlock map[string]livedoc.Node
+	openLT     int
+	openRole   string
+	openAuthor string
+	openV      int
+	openOrder  []string
+	openBlock  map[string]livedoc.Node
 
 	OnClosed  func(Message)
-	OnLive    func(lt int, role string, nodes []livedoc.Node)
+	OnLive    func(lt int, role, author string, nodes []livedoc.Node)
 	OnDesync  func(sinceLT int)
 	OnMetrics func(Metrics)
 }
@@ -84,7 +85,7 @@ func (c *Client) Apply(r AriaRead) {
 		case cm.Full():
 			if !c.seenClosed(cm.LT) {
 				c.closedSeen[cm.LT] = true
-				finalized = append(finalized, Message{LT: cm.LT, Role: cm.Role, Nodes: cm.Nodes})
+				finalized = append(finalized, Message{LT: cm.LT, Role: cm.Role, Author: cm.Author, Nodes: cm.Nodes})
 			}
 			if cm.LT == c.openLT {
 				c.resetOpen()
@@ -94,7 +95,7 @@ func (c *Client) Apply(r AriaRead) {
 			// close marker for what we streamed, versions agree: promote.
 			if !c.closedSeen[cm.LT] {
 				c.closedSeen[cm.LT] = true
-				finalized = append(finalized, Message{LT: cm.LT, Role: c.openRole, Nodes: c.openNodes()})
+				finalized = append(finalized, Message{LT: cm.LT, Role: c.openRole, Author: c.openAuthor, Nodes: c.openNodes()})
 			}
 			c.advanceCommitted(cm.LT)
 			c.resetOpen()
@@ -108,22 +109,24 @@ func (c *Client) Apply(r AriaRead) {
 	c.trimClosed()
 
 	var (
-		haveLive  bool
-		liveLT    int
-		liveRole  string
-		liveNodes []livedoc.Node
+		haveLive   bool
+		liveLT     int
+		liveRole   string
+		liveAuthor string
+		liveNodes  []livedoc.Node
 	)
 	if r.Live != nil {
 		f := r.Live
 		if c.openLT != f.LT {
 			c.openLT = f.LT
 			c.openRole = f.Role
+			c.openAuthor = f.Author
 			c.openOrder = nil
 			c.openBlock = map[string]livedoc.Node{}
 		}
 
 		if f.Role != "" {
-			c.openRole = f.Role
+			c.openRole, c.openAuthor = f.Role, f.Author
 		}
 		for _, nd := range f.Nodes {
 			cur, ok := c.openBlock[nd.ID]
@@ -133,7 +136,7 @@ func (c *Client) Apply(r AriaRead) {
 			c.openBlock[nd.ID] = foldDelta(cur, nd)
 		}
 		c.openV = f.V
-		haveLive, liveLT, liveRole, liveNodes = true, c.openLT, c.openRole, c.openNodes()
+		haveLive, liveLT, liveRole, liveAuthor, liveNodes = true, c.openLT, c.openRole, c.openAuthor, c.openNodes()
 	}
 	c.mu.Unlock()
 
@@ -146,7 +149,7 @@ func (c *Client) Apply(r AriaRead) {
 		}
 	}
 	if haveLive && c.OnLive != nil {
-		c.OnLive(liveLT, liveRole, liveNodes)
+		c.OnLive(liveLT, liveRole, liveAuthor, liveNodes)
 	}
 	if desync >= 0 && c.OnDesync != nil {
 		c.OnDesync(desync)
@@ -189,7 +192,7 @@ func (c *Client) View() View {
 	sort.SliceStable(closed, func(i, j int) bool { return closed[i].LT < closed[j].LT })
 	v := View{Closed: closed}
 	if c.openLT != 0 {
-		v.Open = &Message{LT: c.openLT, Role: c.openRole, Nodes: c.openNodes()}
+		v.Open = &Message{LT: c.openLT, Role: c.openRole, Author: c.openAuthor, Nodes: c.openNodes()}
 	}
 	return v
 }
@@ -209,7 +212,7 @@ func (c *Client) openNodes() []livedoc.Node {
 }
 
 func (c *Client) resetOpen() {
-	c.openLT, c.openRole, c.openV = 0, "", 0
+	c.openLT, c.openRole, c.openAuthor, c.openV = 0, "", "", 0
 	c.openOrder = nil
 	c.openBlock = map[string]livedoc.Node{}
 }
@@ -23,11 +23,12 @@ type Server struct {
 }
 
 type openMsg struct {
-	lt    int
-	role  string
-	order []string
-	block map[string]livedoc.Node
-	ver   int // next frame version (0-indexed); last emitted is ver-1
+	lt     int
+	role   string
+	author string
+	order  []string
+	block  map[string]livedoc.Node
+	ver    int // next frame version (0-indexed); last emitted is ver-1
 }
 
 // NewServer returns an empty aria server.
@@ -60,9 +61,12 @@ func (s *Server) Restore(messages []Message) {
 
 // Open starts a new open message at lt (close any prior one first). It emits no
 // frame; the first Update carries the role at v 0.
-func (s *Server) Open(lt int, role string) {
+func (s *Server) Open(lt int, role string) { s.OpenBy(lt, role, "") }
+
+// OpenBy is Open for a message with an author.
+func (s *Server) OpenBy(lt int, role, author string) {
 	s.mu.Lock()
-	s.open = &openMsg{lt: lt, role: role, block: map[string]livedoc.Node{}}
+	s.open = &openMsg{lt: lt, role: role, author: author, block: map[string]livedoc.Node{}}
 	s.mu.Unlock()
 }
 
@@ -91,12 +95,12 @@ func (s *Server) Update(nodes []livedoc.Node) {
 		return
 	}
 	v := s.open.ver
-	role := ""
+	var role, author string
 	if v == 0 {
-		role = s.open.role
+		role, author = s.open.role, s.open.author
 	}
 	s.open.ver++
-	frame := AriaRead{Live: &Live{LT: s.open.lt, V: v, Role: role, Nodes: deltas}}
+	frame := AriaRead{Live: &Live{LT: s.open.lt, V: v, Role: role, Author: author, Nodes: deltas}}
 	subs := s.subsLocked()
 	s.mu.Unlock()
 	deliver(subs, frame)
@@ -110,7 +114,7 @@ func (s *Server) Close() {
 		s.mu.Unlock()
 		return
 	}
-	m := Message{LT: s.open.lt, Role: s.open.role}
+	m := Message{LT: s.open.lt, Role: s.open.role, Author: s.open.author}
 	for _, id := range s.open.order {
 		m.Nodes = append(m.Nodes, s.open.block[id])
 	}
@@ -144,7 +148,7 @@ func (s *Server) Commit(m Message) {
 	s.closed = append(s.closed, m)
 	subs := s.subsLocked()
 	s.mu.Unlock()
-	deliver(subs, AriaRead{Committed: []Committed{{LT: m.LT, Role: m.Role, Nodes: m.Nodes}}})
+	deliver(subs, AriaRead{Committed: []Committed{{LT: m.LT, Role: m.Role, Author: m.Author, Nodes: m.Nodes}}})
 }
 
 // Subscribe registers a live pusher for subsequent frames (no initial snapshot;
@@ -177,22 +181,28 @@ func (s *Server) ReadBefore(beforeLT, limit int) AriaRead {
 	}
 	var r AriaRead
 	for _, m := range s.closed[lo:hi] {
-		r.Committed = append(r.Committed, Committed{LT: m.LT, Role: m.Role, Nodes: m.Nodes})
+		r.Committed = append(r.Committed, Committed{LT: m.LT, Role: m.Role, Author: m.Author, Nodes: m.Nodes})
 	}
 	return r
 }
 
 // Read returns a catch-up snapshot from sinceLT: closed messages after it in
 // full, plus the open message (if any) as a full-create frame at its version.
-func (s *Server) Read(sinceLT int) AriaRead {
+func (s *Server) Read(sinceLT int) AriaRead { return s.ReadTail(sinceLT, 0) }
+
+// ReadTail is Read keeping only the newest limit closed messages (limit<=0
+// keeps all). The ones skipped are left for ReadBefore, so a reader with
+// nothing yet starts at the recent end of a long history.
+func (s *Server) ReadTail(sinceLT, limit int) AriaRead {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	var r AriaRead
-	for _, m := range s.closed {
-		if m.LT <= sinceLT {
-			continue
-		}
-		r.Committed = append(r.Committed, Committed{LT: m.LT, Role: m.Role, Nodes: m.Nodes})
+	lo := sort.Search(len(s.closed), func(i int) bool { return s.closed[i].LT > sinceLT })
+	if limit > 0 && len(s.closed)-lo > limit {
+		lo = len(s.closed) - limit
+	}
+	for _, m := range s.closed[lo:] {
+		r.Committed = append(r.Committed, Committed{LT: m.LT, Role: m.Role, Author: m.Author, Nodes: m.Nodes})
 	}
 	if s.open != nil && len(s.open.order) > 0 {
 		deltas := make([]NodeDelta, 0, len(s.open.order))
@@ -203,7 +213,7 @@ func (s *Server) Read(sinceLT int) AriaRead {
 		if v < 0 {
 			v = 0
 		}
-		r.Live = &Live{LT: s.open.lt, V: v, Role: s.open.role, Nodes: deltas}
+		r.Live = &Live{LT: s.open.lt, V: v, Role: s.open.role, Author: s.open.author, Nodes: deltas}
 	}
 	return r
 }
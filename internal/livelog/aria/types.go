@@ -44,15 +44,19 @@ type Metrics struct {
 	CacheReadTokens  int    `json:"cache_read_tokens"`
 	CacheWriteTokens int    `json:"cache_write_tokens"`
 	Mantra           string `json:"mantra,omitempty"`
+	Provider         string `json:"provider,omitempty"`
+	Model            string `json:"model,omitempty"`
 }
 
 // Live is one frame of the open message: its record version and the per-node
-// field deltas. Role appears on the first frame (v 0) and on catch-up snapshots.
+// field deltas. Role (and Author, for a user message with one) appears on the
+// first frame (v 0) and on catch-up snapshots.
 type Live struct {
-	LT    int         `json:"lt"`
-	V     int         `json:"v"`
-	Role  string      `json:"role,omitempty"`
-	Nodes []NodeDelta `json:"nodes"`
+	LT     int         `json:"lt"`
+	V      int         `json:"v"`
+	Role   string      `json:"role,omitempty"`
+	Author string      `json:"author,omitempty"`
+	Nodes  []NodeDelta `json:"nodes"`
 }
 
 // NodeDelta is a field-level change to one block, addressed by stable id.
@@ -71,18 +75,21 @@ func (d NodeDelta) Empty() bool {
 // Committed is a closed message: a close marker {lt, v} (promote iff seen==v) or
 // a full snapshot {lt, role, nodes} (adopt wholesale). Presence implies closed.
 type Committed struct {
-	LT    int            `json:"lt"`
-	V     int            `json:"v,omitempty"`
-	Role  string         `json:"role,omitempty"`
-	Nodes []livedoc.Node `json:"nodes,omitempty"`
+	LT     int            `json:"lt"`
+	V      int            `json:"v,omitempty"`
+	Role   string         `json:"role,omitempty"`
+	Author string         `json:"author,omitempty"`
+	Nodes  []livedoc.Node `json:"nodes,omitempty"`
 }
 
 // Full reports whether this is a content snapshot (vs a close marker).
 func (c Committed) Full() bool { return c.Nodes != nil }
 
 // Message is a closed (immutable) message, identified by its figaro LT.
+// Author is set on user messages sent by a named client.
 type Message struct {
-	LT    int
-	Role  string
-	Nodes []livedoc.Node
+	LT     int
+	Role   string
+	Author string
+	Nodes  []livedoc.Node
 }
@@ -52,15 +52,16 @@ func diffRange(old, next []string) (first, last int) {
 type Incipit struct {
 	term    Terminal
 	view    NodeView
-	Bookend func() []string          // sealed after an assistant message (the two-row status footer)
-	Rule    func() string            // sealed after any other message (a plain full-width rule)
-	Header  func(role string) string // printed above each message; "" suppresses
+	Bookend func() []string                  // sealed after an assistant message (the two-row status footer)
+	Rule    func() string                    // sealed after any other message (a plain full-width rule)
+	Header  func(role, author string) string // printed above each message; "" suppresses
 
 	tick int
 
 	// Open-message live region:
 	liveLT int
 	role   string   // open message's role; selects Bookend (assistant) vs Rule
+	author string   // open message's author, for its header
 	live   []string // rows on screen for the open message
 	vt     int      // rows of the live region scrolled above the viewport
 	cur    int      // cursor row within the live region (0 = top)
@@ -85,7 +86,7 @@ func (i *Incipit) Seal(m aria.Message) {
 	rows := i.renderNodes(m.Nodes)
 	var b strings.Builder
 	b.WriteString("\r\n") // leading blank — every message is prefaced with a newline
-	if h := i.header(m.Role); h != "" {
+	if h := i.header(m.Role, m.Author); h != "" {
 		b.WriteString(h)
 		b.WriteString("\r\n")
 		b.WriteString("\r\n")
@@ -124,7 +125,7 @@ func (i *Incipit) printMessage(m aria.Message) {
 		return
 	}
 	rows := []string{""}
-	if h := i.header(m.Role); h != "" {
+	if h := i.header(m.Role, m.Author); h != "" {
 		rows = append(rows, h, "")
 	}
 	rows = append(rows, body...)
@@ -140,6 +141,11 @@ func (i *Incipit) printMessage(m aria.Message) {
 
 // Open paints (or repaints) the open message's blocks as the live region.
 func (i *Incipit) Open(lt int, role string, nodes []livedoc.Node) {
+	i.OpenBy(lt, role, "", nodes)
+}
+
+// OpenBy is Open for a message with an author.
+func (i *Incipit) OpenBy(lt int, role, author string, nodes []livedoc.Node) {
 	if lt != i.liveLT {
 		// A new open message without a prior Seal: release whatever was live.
 		if i.liveLT != 0 {
@@ -148,7 +154,7 @@ func (i *Incipit) Open(lt int, role string, nodes []livedoc.Node) {
 		i.reset()
 		i.liveLT = lt
 	}
-	i.role = role
+	i.role, i.author = role, author
 	i.paint(i.compose(nodes))
 }
 
@@ -179,7 +185,7 @@ func (i *Incipit) compose(nodes []livedoc.Node) []string {
 	// header — sealed into scrollback alongside the rest of the live region.
 	rows := make([]string, 0, len(body)+5)
 	rows = append(rows, "")
-	if h := i.header(i.role); h != "" {
+	if h := i.header(i.role, i.author); h != "" {
 		rows = append(rows, h, "")
 	}
 	rows = append(rows, body...)
@@ -195,11 +201,11 @@ func (i *Incipit) compose(nodes []livedoc.Node) []string {
 
 // header returns the role-header line for role (e.g. "❯ you") or "" if no
 // Header function is configured or the role has no glyph.
-func (i *Incipit) header(role string) string {
+func (i *Incipit) header(role, author string) string {
 	if i.Header == nil {
 		return ""
 	}
-	return i.Header(role)
+	return i.Header(role, author)
 }
 
 // seal returns the rows that close a message of the given role: the two-row
@@ -325,5 +331,5 @@ func (i *Incipit) dropBelow() {
 }
 
 func (i *Incipit) reset() {
-	i.liveLT, i.role, i.live, i.vt, i.cur = 0, "", nil, 0, 0
+	i.liveLT, i.role, i.author, i.live, i.vt, i.cur = 0, "", "", nil, 0, 0
 }
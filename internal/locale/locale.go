@@ -0,0 +1,122 @@
+// Package locale is figaro's message catalog: the user-facing strings of
+// the help output and the pager, one JSON file per language.
+package locale
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Default is the language every catalog falls back to.
+const Default = "en"
+
+//go:embed catalog
+var catalogFS embed.FS
+
+var (
+	catalogs = mustLoad()
+	active   = Default
+)
+
+func mustLoad() map[string]map[string]string {
+	out := map[string]map[string]string{}
+	entries, err := fs.ReadDir(catalogFS, "catalog")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		raw, err := fs.ReadFile(catalogFS, path.Join("catalog", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var msgs map[string]string
+		if err := json.Unmarshal(raw, &msgs); err != nil {
+			panic(fmt.Sprintf("locale: %s: %s", e.Name(), err))
+		}
+		out[strings.TrimSuffix(e.Name(), ".json")] = msgs
+	}
+	return out
+}
+
+// Tags lists the languages with a catalog.
+func Tags() []string {
+	tags := make([]string, 0, len(catalogs))
+	for t := range catalogs {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Detect picks the language: configured when set, else the first of
+// $LC_ALL, $LC_MESSAGES and $LANG. A value like "es_MX.UTF-8" means "es".
+// An environment language without a catalog is English; a configured one
+// is returned as is, so Use can report it.
+func Detect(configured string, getenv func(string) string) string {
+	if configured != "" {
+		return normalize(configured)
+	}
+	for _, v := range []string{getenv("LC_ALL"), getenv("LC_MESSAGES"), getenv("LANG")} {
+		if v == "" {
+			continue
+		}
+		if tag := normalize(v); catalogs[tag] != nil {
+			return tag
+		}
+		return Default
+	}
+	return Default
+}
+
+// normalize reduces a POSIX locale ("pt_BR.UTF-8@euro") or a BCP 47 tag
+// ("pt-BR") to its language.
+func normalize(v string) string {
+	v = strings.ToLower(v)
+	if i := strings.IndexAny(v, "_-.@"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "c" || v == "posix" {
+		return Default
+	}
+	return v
+}
+
+// Use makes tag the active language. It is called once at startup.
+func Use(tag string) error {
+	if _, ok := catalogs[tag]; !ok {
+		return fmt.Errorf("no %q catalog (have %s)", tag, strings.Join(Tags(), ", "))
+	}
+	active = tag
+	return nil
+}
+
+// Active is the language in use.
+func Active() string { return active }
+
+// Lookup finds key in the active catalog, then in English.
+func Lookup(key string) (string, bool) {
+	if s, ok := catalogs[active][key]; ok {
+		return s, true
+	}
+	s, ok := catalogs[Default][key]
+	return s, ok
+}
+
+// T is the string for key; a key no catalog has comes back as itself,
+// so a missing entry shows up rather than vanishing.
+func T(key string) string {
+	if s, ok := Lookup(key); ok {
+		return s
+	}
+	return key
+}
+
+// Tf formats the string for key with args.
+func Tf(key string, args ...any) string {
+	return fmt.Sprintf(T(key), args...)
+}
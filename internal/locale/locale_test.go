@@ -0,0 +1,63 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	cases := []struct {
+		configured string
+		vars       map[string]string
+		want       string
+	}{
+		{"", nil, "en"},
+		{"", map[string]string{"LANG": "es_MX.UTF-8"}, "es"},
+		{"", map[string]string{"LANG": "es_ES.UTF-8", "LC_ALL": "C"}, "en"},
+		{"", map[string]string{"LANG": "fr_FR.UTF-8"}, "en"},
+		{"", map[string]string{"LC_MESSAGES": "es", "LANG": "en_US"}, "es"},
+		{"es-AR", map[string]string{"LANG": "en_US.UTF-8"}, "es"},
+		{"fr", nil, "fr"},
+	}
+	for _, c := range cases {
+		if got := Detect(c.configured, env(c.vars)); got != c.want {
+			t.Errorf("Detect(%q, %v) = %q, want %q", c.configured, c.vars, got, c.want)
+		}
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	defer func() { active = Default }()
+	en := catalogs[Default]
+	for _, tag := range Tags() {
+		for key, s := range catalogs[tag] {
+			if strings.HasPrefix(key, "cmd.") {
+				continue // command help overrides the English in the source
+			}
+			base, ok := en[key]
+			if !ok {
+				t.Errorf("%s: %q has no English entry", tag, key)
+				continue
+			}
+			if strings.Count(s, "%") != strings.Count(base, "%") {
+				t.Errorf("%s: %q = %q does not take the same arguments as %q", tag, key, s, base)
+			}
+		}
+	}
+
+	if err := Use("es"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Tf("pager.notice.pinned", 7); got != "LT 7 fijado en el contexto" {
+		t.Errorf("Tf = %q", got)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q", got)
+	}
+	if err := Use("fr"); err == nil || Active() != "es" {
+		t.Errorf("Use(fr) = %v, active %q", err, Active())
+	}
+}
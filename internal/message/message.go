@@ -103,6 +103,15 @@ const (
 	ContentToolInvoke ContentType = "tool_invoke" // assistant emits these
 	ContentToolResult ContentType = "tool_result" // user-role message carries these (one block per tool that completed)
 
+	// ContentServerToolInvoke and ContentServerToolResult are a tool the
+	// provider ran itself (web search, code execution) within one answer;
+	// both ride the assistant message. ToolCallID pairs them, ToolName
+	// names the tool and the result's Text is a display digest. The
+	// harness never dispatches them, and providers replay them from their
+	// own wire cache, never from these blocks.
+	ContentServerToolInvoke ContentType = "server_tool_invoke"
+	ContentServerToolResult ContentType = "server_tool_result"
+
 	// ContentInterrupt blocks live on a RoleSystemInterrupt message,
 	// one per dangling tool_call_id from the prior assistant turn.
 	// ToolCallID names the unmatched call; Reason carries a short
@@ -129,6 +138,12 @@ type Content struct {
 
 	// Reason populates ContentInterrupt blocks.
 	Reason InterruptReason `json:"reason,omitempty"`
+
+	// TextRef and DataRef stand in for a large Text or Data on disk: the
+	// store keeps the bytes once, content-addressed, and fills them back in
+	// on read. Never set on a message outside the store.
+	TextRef string `json:"text_ref,omitempty"`
+	DataRef string `json:"data_ref,omitempty"`
 }
 
 // Usage tracks token consumption for a single assistant response.
@@ -149,6 +164,14 @@ type Message struct {
 	// Patches are chalkboard mutations for this message.
 	Patches []Patch `json:"patches,omitempty"`
 
+	// Author names who sent a user message when several clients share
+	// the aria. Empty for the daemon's own messages and older logs.
+	Author string `json:"author,omitempty"`
+
+	// Signature, when set, is the author's signature over the aria's hash
+	// chain through this message (see internal/provenance).
+	Signature *Signature `json:"signature,omitempty"`
+
 	// Assistant-only metadata. (model/provider are NOT here — they are
 	// chalkboard values: system.model / system.provider, derived on read.)
 	Usage      *Usage     `json:"usage,omitempty"`
@@ -166,6 +189,12 @@ type Message struct {
 	Timestamp int64 `json:"timestamp"`
 }
 
+// Signature is an Ed25519 signature and the public key that made it.
+type Signature struct {
+	Key string `json:"key"` // "ed25519:<base64 public key>"
+	Sig string `json:"sig"` // base64
+}
+
 func TextContent(text string) Content {
 	return Content{Type: ContentProse, Text: text}
 }
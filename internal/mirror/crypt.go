@@ -0,0 +1,87 @@
+package mirror
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Key encrypts a mirror's objects and derives their names. It never
+// leaves the machine except as the key file the user copies.
+type Key [32]byte
+
+// ErrDecrypt means an object did not open under the key: the wrong key
+// file, or a tampered object.
+var ErrDecrypt = errors.New("mirror: object does not decrypt with this key")
+
+// LoadKey reads the base64 key at path. With create set, a missing file
+// is filled with a new random key; created reports that it was.
+func LoadKey(path string, create bool) (k Key, created bool, err error) {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) && create {
+		if _, err := rand.Read(k[:]); err != nil {
+			return k, false, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return k, false, err
+		}
+		enc := base64.StdEncoding.EncodeToString(k[:]) + "\n"
+		if err := os.WriteFile(path, []byte(enc), 0o600); err != nil {
+			return k, false, err
+		}
+		return k, true, nil
+	}
+	if err != nil {
+		return k, false, err
+	}
+	dec, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(dec) != len(k) {
+		return k, false, fmt.Errorf("%s: not a mirror key", path)
+	}
+	copy(k[:], dec)
+	return k, false, nil
+}
+
+func (k Key) aead() cipher.AEAD {
+	block, _ := aes.NewCipher(k[:])
+	gcm, _ := cipher.NewGCM(block)
+	return gcm
+}
+
+// seal encrypts plain with a random nonce, prepended. name is bound as
+// associated data, so an object swapped for another fails to open.
+func (k Key) seal(name string, plain []byte) []byte {
+	gcm := k.aead()
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plain)+gcm.Overhead())
+	rand.Read(nonce)
+	return gcm.Seal(nonce, nonce, plain, []byte(name))
+}
+
+func (k Key) open(name string, sealed []byte) ([]byte, error) {
+	gcm := k.aead()
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+// objectName hides a file's path and content hash behind an HMAC, so
+// the storage provider sees only opaque names.
+func (k Key) objectName(path, hash string) string {
+	mac := hmac.New(sha256.New, k[:])
+	mac.Write([]byte("object\x00" + path + "\x00" + hash))
+	return hex.EncodeToString(mac.Sum(nil)[:20])
+}
@@ -0,0 +1,235 @@
+// Package mirror keeps an encrypted copy of a set of files in object
+// storage the user does not have to trust. Every object is sealed with
+// AES-256-GCM under a key that stays on the user's machines, object
+// names are HMACs of path and content hash, and the file list lives in
+// a sealed manifest. A push uploads only files whose content changed
+// since the manifest was written.
+//
+// A mirror belongs to one machine at a time: the manifest records the
+// host that last pushed, and a push from another host is refused unless
+// it claims the mirror. Two machines pushing in turn would otherwise
+// each delete the other's files.
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// manifestName is the one object with a fixed name.
+const manifestName = "manifest"
+
+const manifestVersion = 1
+
+// Manifest lists a mirror's files by slash path.
+type Manifest struct {
+	Version int              `json:"version"`
+	Updated time.Time        `json:"updated"`
+	Owner   string           `json:"owner,omitempty"` // the host that pushed
+	Files   map[string]Entry `json:"files"`
+}
+
+// Entry is one mirrored file.
+type Entry struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// Stats sums up a push or pull.
+type Stats struct {
+	Changed   int
+	Unchanged int
+	Removed   int
+	Bytes     int64
+}
+
+// ReadManifest fetches and opens the manifest. A store with none yet
+// has an empty one.
+func ReadManifest(ctx context.Context, st Store, k Key) (*Manifest, error) {
+	sealed, err := st.Get(ctx, manifestName)
+	if errors.Is(err, ErrNotFound) {
+		return &Manifest{Version: manifestVersion, Files: map[string]Entry{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw, err := k.open(manifestName, sealed)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("mirror manifest: %w", err)
+	}
+	if m.Version > manifestVersion {
+		return nil, fmt.Errorf("mirror manifest version %d is newer than this figaro reads; update figaro", m.Version)
+	}
+	if m.Files == nil {
+		m.Files = map[string]Entry{}
+	}
+	return &m, nil
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Push makes the mirror hold exactly files, a map from slash path to
+// the local file, pushed from host. New and changed files are uploaded
+// first, then the manifest, and only then are superseded objects
+// deleted, so an interrupted push leaves the previous manifest readable.
+// A mirror another host owns is refused with an OwnerError unless claim
+// is set; its files not in files are then deleted.
+func Push(ctx context.Context, st Store, k Key, host string, claim bool, files map[string]string) (Stats, error) {
+	var stats Stats
+	old, err := ReadManifest(ctx, st, k)
+	if err != nil {
+		return stats, err
+	}
+	if old.Owner != "" && old.Owner != host && !claim {
+		return stats, &OwnerError{Owner: old.Owner}
+	}
+	next := &Manifest{Version: manifestVersion, Updated: time.Now().UTC(), Owner: host, Files: map[string]Entry{}}
+	for _, path := range sortedKeys(files) {
+		data, err := os.ReadFile(files[path])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+		e := Entry{Hash: hashOf(data), Size: int64(len(data))}
+		next.Files[path] = e
+		if prev, ok := old.Files[path]; ok && prev.Hash == e.Hash {
+			stats.Unchanged++
+			continue
+		}
+		name := k.objectName(path, e.Hash)
+		if err := st.Put(ctx, name, k.seal(name, data)); err != nil {
+			return stats, fmt.Errorf("%s: %w", path, err)
+		}
+		stats.Changed++
+		stats.Bytes += e.Size
+	}
+
+	raw, err := json.Marshal(next)
+	if err != nil {
+		return stats, err
+	}
+	if err := st.Put(ctx, manifestName, k.seal(manifestName, raw)); err != nil {
+		return stats, fmt.Errorf("manifest: %w", err)
+	}
+	for path, prev := range old.Files {
+		if cur, ok := next.Files[path]; ok && cur.Hash == prev.Hash {
+			continue
+		}
+		if _, ok := next.Files[path]; !ok {
+			stats.Removed++
+		}
+		if err := st.Delete(ctx, k.objectName(path, prev.Hash)); err != nil {
+			return stats, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return stats, nil
+}
+
+// OwnerError is a push from a host that doesn't own the mirror.
+type OwnerError struct{ Owner string }
+
+func (e *OwnerError) Error() string {
+	return "mirror is pushed from " + e.Owner
+}
+
+// ConflictError lists local files a pull would overwrite.
+type ConflictError struct{ Paths []string }
+
+func (e *ConflictError) Error() string {
+	return "would overwrite " + strings.Join(e.Paths, ", ")
+}
+
+// Pull writes the mirror's files to the local paths resolve gives.
+// Missing files are fetched; a local file with other content is
+// overwritten only with replace set, and otherwise the pull stops with a
+// ConflictError before writing anything. Local files the mirror lacks
+// are left alone.
+func Pull(ctx context.Context, st Store, k Key, resolve func(path string) (string, error), replace bool) (Stats, error) {
+	var stats Stats
+	m, err := ReadManifest(ctx, st, k)
+	if err != nil {
+		return stats, err
+	}
+	type fetch struct{ path, local string }
+	var todo []fetch
+	var conflicts []string
+	for _, path := range sortedKeys(m.Files) {
+		local, err := resolve(path)
+		if err != nil {
+			return stats, err
+		}
+		data, err := os.ReadFile(local)
+		switch {
+		case err == nil && hashOf(data) == m.Files[path].Hash:
+			stats.Unchanged++
+			continue
+		case err == nil:
+			conflicts = append(conflicts, path)
+		case !os.IsNotExist(err):
+			return stats, err
+		}
+		todo = append(todo, fetch{path, local})
+	}
+	if len(conflicts) > 0 && !replace {
+		return stats, &ConflictError{Paths: conflicts}
+	}
+	for _, f := range todo {
+		e := m.Files[f.path]
+		name := k.objectName(f.path, e.Hash)
+		sealed, err := st.Get(ctx, name)
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", f.path, err)
+		}
+		data, err := k.open(name, sealed)
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", f.path, err)
+		}
+		if hashOf(data) != e.Hash {
+			return stats, fmt.Errorf("%s: content does not match the manifest", f.path)
+		}
+		if err := writeAtomic(f.local, data); err != nil {
+			return stats, err
+		}
+		stats.Changed++
+		stats.Bytes += e.Size
+	}
+	return stats, nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".mirror-tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
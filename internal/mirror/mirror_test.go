@@ -0,0 +1,184 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(t *testing.T) Key {
+	t.Helper()
+	k, created, err := LoadKey(filepath.Join(t.TempDir(), "m.key"), true)
+	if err != nil || !created {
+		t.Fatalf("key: %v %v", created, err)
+	}
+	return k
+}
+
+// tree writes files under a temp dir and returns the path -> file map
+// Push takes.
+func tree(t *testing.T, files map[string]string) (string, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	out := map[string]string{}
+	for path, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(p), 0o700)
+		os.WriteFile(p, []byte(body), 0o600)
+		out[path] = p
+	}
+	return dir, out
+}
+
+func resolveIn(dir string) func(string) (string, error) {
+	return func(p string) (string, error) { return filepath.Join(dir, filepath.FromSlash(p)), nil }
+}
+
+func TestPushPullRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	k := testKey(t)
+	st := Dir(t.TempDir())
+	_, files := tree(t, map[string]string{"state/arias/ir/1.jsonl": "{\"secret\":\"hunter2\"}\n", "config/config.toml": "x = 1\n"})
+
+	stats, err := Push(ctx, st, k, "laptop", false, files)
+	if err != nil || stats.Changed != 2 {
+		t.Fatalf("push: %+v %v", stats, err)
+	}
+	entries, _ := os.ReadDir(string(st))
+	if len(entries) != 3 {
+		t.Errorf("objects: %d, want 2 files + manifest", len(entries))
+	}
+	for _, e := range entries {
+		b, _ := os.ReadFile(filepath.Join(string(st), e.Name()))
+		if bytes.Contains(b, []byte("hunter2")) || bytes.Contains(b, []byte("arias")) {
+			t.Errorf("%s leaks plaintext", e.Name())
+		}
+	}
+
+	dst := t.TempDir()
+	stats, err = Pull(ctx, st, k, resolveIn(dst), false)
+	if err != nil || stats.Changed != 2 {
+		t.Fatalf("pull: %+v %v", stats, err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dst, "state", "arias", "ir", "1.jsonl"))
+	if string(got) != "{\"secret\":\"hunter2\"}\n" {
+		t.Errorf("pulled %q", got)
+	}
+	if stats, _ := Pull(ctx, st, k, resolveIn(dst), false); stats.Unchanged != 2 || stats.Changed != 0 {
+		t.Errorf("second pull: %+v", stats)
+	}
+}
+
+func TestPushUploadsOnlyChanges(t *testing.T) {
+	ctx := context.Background()
+	k := testKey(t)
+	st := Dir(t.TempDir())
+	_, files := tree(t, map[string]string{"a": "1\n", "b": "2\n", "c": "3\n"})
+	if _, err := Push(ctx, st, k, "laptop", false, files); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(files["a"], []byte("1\n1b\n"), 0o600)
+	os.Remove(files["c"])
+	stats, err := Push(ctx, st, k, "laptop", false, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Changed != 1 || stats.Unchanged != 1 || stats.Removed != 1 || stats.Bytes != 5 {
+		t.Errorf("stats %+v", stats)
+	}
+	if entries, _ := os.ReadDir(string(st)); len(entries) != 3 {
+		t.Errorf("superseded objects kept: %d objects", len(entries))
+	}
+	m, err := ReadManifest(ctx, st, k)
+	if err != nil || len(m.Files) != 2 {
+		t.Errorf("manifest: %+v %v", m, err)
+	}
+}
+
+func TestPushRefusesForeignOwner(t *testing.T) {
+	ctx := context.Background()
+	k := testKey(t)
+	st := Dir(t.TempDir())
+	_, laptop := tree(t, map[string]string{"a": "laptop\n"})
+	_, desk := tree(t, map[string]string{"b": "desk\n"})
+	if _, err := Push(ctx, st, k, "laptop", false, laptop); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Push(ctx, st, k, "desk", false, desk)
+	var oe *OwnerError
+	if !errors.As(err, &oe) || oe.Owner != "laptop" {
+		t.Fatalf("foreign push: %v", err)
+	}
+	if m, _ := ReadManifest(ctx, st, k); m.Owner != "laptop" || len(m.Files) != 1 {
+		t.Errorf("refused push changed the manifest: %+v", m)
+	}
+
+	stats, err := Push(ctx, st, k, "desk", true, desk)
+	if err != nil || stats.Removed != 1 {
+		t.Fatalf("claim: %+v %v", stats, err)
+	}
+	if m, _ := ReadManifest(ctx, st, k); m.Owner != "desk" {
+		t.Errorf("owner after claim = %q", m.Owner)
+	}
+}
+
+func TestPullConflicts(t *testing.T) {
+	ctx := context.Background()
+	k := testKey(t)
+	st := Dir(t.TempDir())
+	_, files := tree(t, map[string]string{"a": "remote\n", "b": "same\n"})
+	Push(ctx, st, k, "laptop", false, files)
+
+	dst, _ := tree(t, map[string]string{"a": "local\n", "b": "same\n", "extra": "kept\n"})
+	_, err := Pull(ctx, st, k, resolveIn(dst), false)
+	var ce *ConflictError
+	if !errors.As(err, &ce) || len(ce.Paths) != 1 || ce.Paths[0] != "a" {
+		t.Fatalf("conflict: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dst, "a")); string(b) != "local\n" {
+		t.Error("refused pull wrote")
+	}
+	if _, err := Pull(ctx, st, k, resolveIn(dst), true); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dst, "a")); string(b) != "remote\n" {
+		t.Errorf("replace: %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "extra")); err != nil {
+		t.Error("local-only file removed")
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	ctx := context.Background()
+	st := Dir(t.TempDir())
+	_, files := tree(t, map[string]string{"a": "x"})
+	Push(ctx, st, testKey(t), "laptop", false, files)
+	if _, err := ReadManifest(ctx, st, testKey(t)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong key: %v", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k")
+	if _, _, err := LoadKey(path, false); !os.IsNotExist(err) {
+		t.Errorf("missing without create: %v", err)
+	}
+	k1, _, _ := LoadKey(path, true)
+	k2, created, err := LoadKey(path, true)
+	if err != nil || created || k1 != k2 {
+		t.Errorf("reload: %v %v", created, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode %v", info.Mode())
+	}
+	os.WriteFile(path, []byte("short"), 0o600)
+	if _, _, err := LoadKey(path, false); err == nil {
+		t.Error("garbage key accepted")
+	}
+}
@@ -0,0 +1,142 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is a Get of an object the store does not hold.
+var ErrNotFound = errors.New("mirror: object not found")
+
+// Store is flat object storage. Names are opaque hex strings plus the
+// fixed manifest name; stores need no directories.
+type Store interface {
+	Get(ctx context.Context, name string) ([]byte, error)
+	Put(ctx context.Context, name string, data []byte) error
+	Delete(ctx context.Context, name string) error
+}
+
+// Dir is a Store in a local directory, e.g. a mounted network drive.
+type Dir string
+
+func (d Dir) Get(_ context.Context, name string) ([]byte, error) {
+	b, err := os.ReadFile(filepath.Join(string(d), name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+func (d Dir) Put(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(string(d), 0o700); err != nil {
+		return err
+	}
+	tmp := filepath.Join(string(d), name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(string(d), name))
+}
+
+func (d Dir) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(string(d), name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// WebDAV is a Store in a WebDAV collection, with basic auth when User
+// is set.
+type WebDAV struct {
+	URL      string
+	User     string
+	Password string
+	Client   *http.Client
+}
+
+func (w *WebDAV) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.User != "" {
+		req.SetBasicAuth(w.User, w.Password)
+	}
+	return client(w.Client).Do(req)
+}
+
+func (w *WebDAV) object(name string) string { return strings.TrimSuffix(w.URL, "/") + "/" + name }
+
+func (w *WebDAV) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := w.do(ctx, http.MethodGet, w.object(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	return readObject(resp, "webdav get")
+}
+
+// Put creates the collection on the first 409 (missing parent) and
+// retries once.
+func (w *WebDAV) Put(ctx context.Context, name string, data []byte) error {
+	for attempt := 0; ; attempt++ {
+		resp, err := w.do(ctx, http.MethodPut, w.object(name), data)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusConflict && attempt == 0 {
+			resp.Body.Close()
+			mk, err := w.do(ctx, "MKCOL", strings.TrimSuffix(w.URL, "/")+"/", nil)
+			if err != nil {
+				return err
+			}
+			mk.Body.Close()
+			continue
+		}
+		_, err = readObject(resp, "webdav put")
+		return err
+	}
+}
+
+func (w *WebDAV) Delete(ctx context.Context, name string) error {
+	resp, err := w.do(ctx, http.MethodDelete, w.object(name), nil)
+	if err != nil {
+		return err
+	}
+	if _, err := readObject(resp, "webdav delete"); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func client(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// readObject drains resp: the body on 2xx, ErrNotFound on 404, and an
+// error naming the status otherwise.
+func readObject(resp *http.Response, op string) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return nil, fmt.Errorf("%s: %s: %s", op, resp.Status, msg)
+	}
+	return body, err
+}
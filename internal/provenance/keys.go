@@ -0,0 +1,133 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Keys is a key store: private keys as <Private>/<name>.key, trusted
+// public keys as <Public>/<name>.pub. Generating a key trusts it too.
+type Keys struct {
+	Private string
+	Public  string
+}
+
+// ErrKeyExists is a Generate over a key that is already there.
+var ErrKeyExists = errors.New("key exists")
+
+var keyName = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+func checkName(name string) error {
+	if !keyName.MatchString(name) || strings.Trim(name, ".") == "" {
+		return fmt.Errorf("key name %q: use letters, digits and . _ @ -", name)
+	}
+	return nil
+}
+
+// Generate creates name's key pair.
+func (k Keys) Generate(name string) (ed25519.PublicKey, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(k.Private, name+".key")
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrKeyExists)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(k.Private, 0o700); err != nil {
+		return nil, err
+	}
+	enc := base64.StdEncoding.EncodeToString(priv.Seed()) + "\n"
+	if err := os.WriteFile(path, []byte(enc), 0o600); err != nil {
+		return nil, err
+	}
+	return pub, k.Trust(name, FormatPublic(pub))
+}
+
+// Load returns name's private key; the error wraps os.ErrNotExist when
+// there is none.
+func (k Keys) Load(name string) (ed25519.PrivateKey, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(k.Private, name+".key")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s: not an ed25519 key", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Trust records pub as name's public key, replacing any before it.
+func (k Keys) Trust(name, pub string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	key, err := ParsePublic(pub)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(k.Public, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(k.Public, name+".pub"), []byte(FormatPublic(key)+"\n"), 0o644)
+}
+
+// Trusted maps each trusted public key to its name.
+func (k Keys) Trusted() (map[string]string, error) {
+	out := map[string]string{}
+	for _, e := range k.List() {
+		if e.Err != nil {
+			return nil, e.Err
+		}
+		out[e.Public] = e.Name
+	}
+	return out, nil
+}
+
+// KeyInfo is one trusted key.
+type KeyInfo struct {
+	Name    string
+	Public  string
+	Private bool // a private key of this name is here to sign with
+	Err     error
+}
+
+// List returns the trusted keys by name.
+func (k Keys) List() []KeyInfo {
+	paths, _ := filepath.Glob(filepath.Join(k.Public, "*.pub"))
+	sort.Strings(paths)
+	var out []KeyInfo
+	for _, p := range paths {
+		info := KeyInfo{Name: strings.TrimSuffix(filepath.Base(p), ".pub")}
+		raw, err := os.ReadFile(p)
+		if err == nil {
+			var pub ed25519.PublicKey
+			if pub, err = ParsePublic(string(raw)); err == nil {
+				info.Public = FormatPublic(pub)
+			}
+		}
+		if err != nil {
+			info.Err = fmt.Errorf("%s: %w", p, err)
+		}
+		_, err = os.Stat(filepath.Join(k.Private, info.Name+".key"))
+		info.Private = err == nil
+		out = append(out, info)
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package provenance
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func conversation() []message.Message {
+	return []message.Message{
+		{Role: message.RoleUser, Author: "alice", Content: []message.Content{message.TextContent("list files")}, Timestamp: 1},
+		{Role: message.RoleAssistant, Content: []message.Content{{Type: message.ContentToolInvoke, ToolCallID: "t1", ToolName: "bash", Arguments: map[string]any{"command": "ls", "timeout": 30}}}, Timestamp: 2},
+		{Role: message.RoleUser, Content: []message.Content{message.ToolResultContent("t1", "bash", "a\nb", false)}, Timestamp: 3},
+		{Role: message.RoleUser, Author: "bob", Content: []message.Content{message.TextContent("thanks")}, Timestamp: 4},
+	}
+}
+
+// signed signs the messages whose author has a key.
+func signed(t *testing.T, keys Keys, msgs []message.Message) []message.Message {
+	t.Helper()
+	var h Hash
+	for i := range msgs {
+		h = Next(h, msgs[i])
+		if msgs[i].Author == "" {
+			continue
+		}
+		key, err := keys.Load(msgs[i].Author)
+		if err != nil {
+			continue
+		}
+		msgs[i].Signature = Sign(key, h)
+	}
+	return msgs
+}
+
+func testKeys(t *testing.T) Keys {
+	dir := t.TempDir()
+	return Keys{Private: filepath.Join(dir, "identity"), Public: filepath.Join(dir, "keys")}
+}
+
+func TestVerify(t *testing.T) {
+	ks := testKeys(t)
+	if _, err := ks.Generate("alice"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := signed(t, ks, conversation())
+
+	// Round-trip through JSON, as verify reads the store.
+	raw, _ := json.Marshal(msgs)
+	var stored []message.Message
+	json.Unmarshal(raw, &stored)
+	for i := range stored {
+		stored[i].LogicalTime = uint64(i + 1)
+	}
+
+	trusted, _ := ks.Trusted()
+	rep := Verify(stored, trusted)
+	if !rep.OK() || rep.Signed["alice"] != 1 || rep.Unsigned != 1 || rep.Untrusted != 0 {
+		t.Fatalf("report: %+v", rep)
+	}
+	if rep.Head != Head(conversation()) {
+		t.Error("head differs from the unsigned chain")
+	}
+
+	if rep := Verify(stored, nil); !rep.OK() || rep.Untrusted != 1 {
+		t.Errorf("untrusted: %+v", rep)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	ks := testKeys(t)
+	ks.Generate("bob")
+	msgs := signed(t, ks, conversation())
+	trusted, _ := ks.Trusted()
+
+	msgs[0].Content[0].Text = "delete files"
+	rep := Verify(msgs, trusted)
+	if rep.OK() || rep.Failures[0].Index != 3 || !errors.Is(rep.Failures[0].Err, ErrBadSignature) {
+		t.Fatalf("edit before a signature: %+v", rep)
+	}
+
+	msgs = signed(t, ks, conversation())
+	msgs = append(msgs[:1], msgs[2:]...)
+	if rep := Verify(msgs, trusted); rep.OK() {
+		t.Fatal("dropped message not detected")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	ks := testKeys(t)
+	pub, err := ks.Generate("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Generate("alice"); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("regenerate: %v", err)
+	}
+	if _, err := ks.Generate("../x"); err == nil {
+		t.Error("path in key name accepted")
+	}
+	if err := ks.Trust("bob", "not-a-key"); err == nil {
+		t.Error("bad public key trusted")
+	}
+	other := testKeys(t)
+	bobPub, _ := other.Generate("bob")
+	if err := ks.Trust("bob", FormatPublic(bobPub)); err != nil {
+		t.Fatal(err)
+	}
+
+	list := ks.List()
+	if len(list) != 2 || list[0].Name != "alice" || !list[0].Private || list[1].Private {
+		t.Fatalf("list: %+v", list)
+	}
+	trusted, _ := ks.Trusted()
+	if trusted[FormatPublic(pub)] != "alice" || trusted[FormatPublic(bobPub)] != "bob" {
+		t.Errorf("trusted: %v", trusted)
+	}
+}
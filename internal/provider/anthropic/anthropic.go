@@ -21,6 +21,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/auth"
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/message"
 	figOtel "github.com/jack-work/figaro/internal/otel"
 	"github.com/jack-work/figaro/internal/provider"
@@ -67,7 +68,7 @@ func New(knobs provider.Knobs, resolver auth.TokenResolver, cacheOpen func(aria
 		auth:             resolver,
 		Model:            knobs.Model,
 		MaxTokens:        knobs.MaxTokens,
-		HTTPClient:       &http.Client{Timeout: 10 * time.Minute},
+		HTTPClient:       provider.Client(10 * time.Minute),
 		ReminderRenderer: rr,
 		CacheOpen:        cacheOpen,
 		CacheNamespace:   providerName,
@@ -125,6 +126,18 @@ func (a *Anthropic) setAuthHeaders(req *http.Request, apiKey string, betas strin
 	}
 }
 
+// addBetas appends beta flags to the request's anthropic-beta header; an
+// API-key request has none until a server tool needs one.
+func addBetas(req *http.Request, betas []string) {
+	if len(betas) == 0 {
+		return
+	}
+	if cur := req.Header.Get("anthropic-beta"); cur != "" {
+		betas = append([]string{cur}, betas...)
+	}
+	req.Header.Set("anthropic-beta", strings.Join(betas, ","))
+}
+
 const (
 	betaModels   = "claude-code-20250219,oauth-2025-04-20"
 	betaMessages = "claude-code-20250219,oauth-2025-04-20,fine-grained-tool-streaming-2025-05-14,prompt-caching-2024-07-31"
@@ -186,7 +199,7 @@ func sleepCtx(ctx context.Context, d time.Duration) bool {
 func (a *Anthropic) doWithAuthRetry(ctx context.Context, build func(apiKey string) (*http.Request, error)) (*http.Response, string, error) {
 	apiKey, err := a.auth.Resolve()
 	if err != nil {
-		return nil, "", fmt.Errorf("resolve token: %w", err)
+		return nil, "", errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", err))
 	}
 	authRetried := false
 	var lastErr error
@@ -221,13 +234,13 @@ func (a *Anthropic) doWithAuthRetry(ctx context.Context, build func(apiKey strin
 			ierr := a.auth.Invalidate(apiKey)
 			newKey, rerr := a.auth.Resolve()
 			if rerr != nil {
-				return nil, apiKey, fmt.Errorf("resolve after 401: %w", errors.Join(ierr, rerr))
+				return nil, apiKey, errs.Wrap(errs.Auth, fmt.Errorf("resolve after 401: %w", errors.Join(ierr, rerr)))
 			}
 			if newKey == apiKey {
 				if ierr != nil {
-					return nil, apiKey, fmt.Errorf("anthropic 401: invalidate failed: %w", ierr)
+					return nil, apiKey, errs.Wrap(errs.Auth, fmt.Errorf("anthropic 401: invalidate failed: %w", ierr))
 				}
-				return nil, apiKey, fmt.Errorf("anthropic 401: token unchanged after invalidate")
+				return nil, apiKey, errs.New(errs.Auth, "anthropic 401: token unchanged after invalidate")
 			}
 			apiKey = newKey
 			attempt-- // don't count the auth retry
@@ -235,9 +248,9 @@ func (a *Anthropic) doWithAuthRetry(ctx context.Context, build func(apiKey strin
 		}
 		if isTransientStatus(resp.StatusCode) {
 			ra := parseRetryAfter(resp.Header)
-			io.Copy(io.Discard, resp.Body)
+			errBody, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			lastErr = fmt.Errorf("anthropic %d (transient)", resp.StatusCode)
+			lastErr = errs.HTTP("anthropic", resp.StatusCode, errBody)
 			if ra > 0 {
 				delay = ra
 			} else {
@@ -270,7 +283,7 @@ func (a *Anthropic) Models(ctx context.Context) ([]provider.ModelInfo, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("anthropic models API %d: %s", resp.StatusCode, body)
+		return nil, errs.HTTP("anthropic models", resp.StatusCode, body)
 	}
 
 	var result struct {
@@ -329,7 +342,7 @@ func validNativeBlock(b nativeBlock) bool {
 		return strings.TrimSpace(b.Thinking) != ""
 	case "redacted_thinking":
 		return b.Data != ""
-	case "tool_use":
+	case "tool_use", "server_tool_use":
 		return b.ID != "" && b.Input != nil
 	case "":
 		return false
@@ -351,7 +364,7 @@ func cacheableNativeBlock(b nativeBlock) (keep, fatal bool) {
 		return b.Signature != "" || strings.TrimSpace(b.Thinking) != "", false
 	case "redacted_thinking":
 		return b.Data != "", false
-	case "tool_use":
+	case "tool_use", "server_tool_use":
 		if b.ID == "" {
 			return false, false
 		}
@@ -385,6 +398,11 @@ func decodeNativeMessage(nm nativeMessage) message.Message {
 			m.Content = append(m.Content, message.Content{
 				Type: message.ContentToolInvoke, ToolCallID: b.ID, ToolName: b.Name, Arguments: args,
 			})
+		case "server_tool_use":
+			args, _ := b.Input.(map[string]interface{})
+			m.Content = append(m.Content, message.Content{
+				Type: message.ContentServerToolInvoke, ToolCallID: b.ID, ToolName: b.Name, Arguments: args,
+			})
 		case "tool_result":
 			var text string
 			switch v := b.Content.(type) {
@@ -402,6 +420,11 @@ func decodeNativeMessage(nm nativeMessage) message.Message {
 			m.Content = append(m.Content, message.Content{
 				Type: message.ContentToolResult, ToolCallID: b.ToolUseID, Text: text, IsError: b.IsError,
 			})
+		default:
+			if provider.IsServerToolResult(b.Type) {
+				raw, _ := json.Marshal(b.Content)
+				m.Content = append(m.Content, provider.ServerToolResult(b.Type, b.ToolUseID, raw))
+			}
 		}
 	}
 	switch nm.StopReason {
@@ -478,6 +501,23 @@ type nativeBlock struct {
 	Content      interface{}   `json:"content,omitempty"`
 	Source       interface{}   `json:"source,omitempty"`
 	CacheControl *cacheControl `json:"cache_control,omitempty"`
+
+	// acc accumulates the block's streamed deltas (text, thinking or
+	// input JSON; a block carries one kind).
+	acc *strings.Builder
+}
+
+// grow appends delta to s, the block's streamed field so far, and returns
+// the result. Concatenating per delta recopies the whole block each time;
+// the builder grows amortized and its String shares the buffer, so the
+// field stays current after every event without a copy.
+func (b *nativeBlock) grow(s, delta string) string {
+	if b.acc == nil {
+		b.acc = &strings.Builder{}
+		b.acc.WriteString(s)
+	}
+	b.acc.WriteString(delta)
+	return b.acc.String()
 }
 
 // MarshalJSON emits thinking blocks with their required fields even when
@@ -501,6 +541,28 @@ type nativeTool struct {
 	Description  string        `json:"description"`
 	InputSchema  interface{}   `json:"input_schema"`
 	CacheControl *cacheControl `json:"cache_control,omitempty"`
+
+	// Type is set on a server tool ("web_search_20250305"), which has no
+	// description or schema of its own.
+	Type           string   `json:"-"`
+	MaxUses        int      `json:"-"`
+	AllowedDomains []string `json:"-"`
+}
+
+// MarshalJSON emits a server tool in its own shape: the API rejects the
+// description and input_schema keys on one.
+func (t nativeTool) MarshalJSON() ([]byte, error) {
+	if t.Type == "" {
+		type alias nativeTool
+		return json.Marshal(alias(t))
+	}
+	return json.Marshal(struct {
+		Type           string        `json:"type"`
+		Name           string        `json:"name"`
+		MaxUses        int           `json:"max_uses,omitempty"`
+		AllowedDomains []string      `json:"allowed_domains,omitempty"`
+		CacheControl   *cacheControl `json:"cache_control,omitempty"`
+	}{t.Type, t.Name, t.MaxUses, t.AllowedDomains, t.CacheControl})
 }
 
 // encode projects one IR message to native wire bytes.
@@ -647,7 +709,25 @@ func projectTools(tools []provider.Tool) []nativeTool {
 	return result
 }
 
-// systemBlocks builds the system prefix: preamble + credo.
+// projectServerTools lists the server tools the aria has on. They go
+// after its own tools, in a fixed order, so the tools prefix stays
+// byte-stable across requests.
+func projectServerTools(s provider.ServerTools) []nativeTool {
+	var out []nativeTool
+	if s.WebSearch {
+		out = append(out, nativeTool{Type: "web_search_20250305", Name: "web_search", MaxUses: s.WebSearchMaxUses, AllowedDomains: s.WebSearchDomains})
+	}
+	if s.WebFetch {
+		out = append(out, nativeTool{Type: "web_fetch_20250910", Name: "web_fetch"})
+	}
+	if s.CodeExecution {
+		out = append(out, nativeTool{Type: "code_execution_20250825", Name: "code_execution"})
+	}
+	return out
+}
+
+// systemBlocks builds the system prefix: preamble + credo + pins and
+// reply language.
 //
 // The credo lives on the chalkboard at `system.credo`. It may be a
 // bare string (inline TOML) or a ContentEnvelope object emitted by
@@ -665,6 +745,9 @@ func systemBlocks(snapshot chalkboard.Snapshot, oauth bool) []systemBlock {
 	} else if systemText != "" {
 		out = append(out, systemBlock{Type: "text", Text: systemText})
 	}
+	if managed := provider.ManagedText(snapshot); managed != "" {
+		out = append(out, systemBlock{Type: "text", Text: managed})
+	}
 	return out
 }
 
@@ -710,7 +793,7 @@ func (a *Anthropic) projectMessagesWithLTs(perMessage [][]json.RawMessage, lts [
 		Model: model, MaxTokens: maxTokens, Stream: true,
 		System: systemBlocks(snapshot, oauth),
 		// TODO: put tools on the chalkboard as an ordered list.
-		Tools: projectTools(tools),
+		Tools: append(projectTools(tools), projectServerTools(provider.ServerToolsFrom(snapshot))...),
 	}
 	var msgLTs []uint64
 	for i, entry := range perMessage {
@@ -816,7 +899,7 @@ func (a *Anthropic) Send(ctx context.Context, in provider.SendInput, bus provide
 
 	apiKey, err := a.auth.Resolve()
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", err))
 	}
 	model := a.resolveModel(in.Snapshot)
 
@@ -836,6 +919,7 @@ func (a *Anthropic) Send(ctx context.Context, in provider.SendInput, bus provide
 		}
 		httpReq.Header.Set("Content-Type", "application/json")
 		a.setAuthHeaders(httpReq, token, betaMessages)
+		addBetas(httpReq, provider.ServerToolsFrom(in.Snapshot).Betas())
 		return httpReq, nil
 	})
 	if err != nil {
@@ -844,7 +928,7 @@ func (a *Anthropic) Send(ctx context.Context, in provider.SendInput, bus provide
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("anthropic API error %d: %s", resp.StatusCode, string(errBody))
+		return errs.HTTP("anthropic", resp.StatusCode, errBody)
 	}
 
 	nm, err := a.drainSSE(ctx, resp.Body, model, bus)
@@ -913,7 +997,7 @@ func (a *Anthropic) SendWithTransport(ctx context.Context, in provider.SendInput
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		errBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("copilot API error %d: %s", resp.StatusCode, string(errBody))
+		return errs.HTTP("copilot", resp.StatusCode, errBody)
 	}
 
 	nm, err := a.drainSSE(ctx, resp.Body, model, bus)
@@ -1147,6 +1231,8 @@ func (a *Anthropic) foldSSEEvent(ctx context.Context, eventType string, data []b
 				Name      string `json:"name,omitempty"`
 				Signature string `json:"signature,omitempty"`
 				Data      string `json:"data,omitempty"`
+				ToolUseID string `json:"tool_use_id,omitempty"`
+				Content   any    `json:"content,omitempty"`
 			} `json:"content_block"`
 		}
 		if json.Unmarshal(data, &block) != nil {
@@ -1172,6 +1258,19 @@ func (a *Anthropic) foldSSEEvent(ctx context.Context, eventType string, data []b
 				attribute.String("tool_call_id", block.ContentBlock.ID),
 				attribute.String("tool_name", block.ContentBlock.Name),
 			)
+		case "server_tool_use":
+			// Run by the API; its input streams like a tool_use's but the
+			// harness has nothing to dispatch.
+			nm.Content[block.Index] = nativeBlock{
+				Type: "server_tool_use", ID: block.ContentBlock.ID, Name: block.ContentBlock.Name,
+			}
+		default:
+			if provider.IsServerToolResult(block.ContentBlock.Type) {
+				// Arrives whole; no deltas follow.
+				nm.Content[block.Index] = nativeBlock{
+					Type: block.ContentBlock.Type, ToolUseID: block.ContentBlock.ToolUseID, Content: block.ContentBlock.Content,
+				}
+			}
 		}
 	case "content_block_delta":
 		var d struct {
@@ -1190,29 +1289,28 @@ func (a *Anthropic) foldSSEEvent(ctx context.Context, eventType string, data []b
 		b := &nm.Content[d.Index]
 		switch d.Delta.Type {
 		case "text_delta":
-			b.Text += d.Delta.Text
+			b.Text = b.grow(b.Text, d.Delta.Text)
 			if d.Delta.Text != "" {
 				bus.PushDelta(message.Content{Type: message.ContentProse, Text: d.Delta.Text})
 			}
 		case "thinking_delta":
-			b.Thinking += d.Delta.Thinking
+			b.Thinking = b.grow(b.Thinking, d.Delta.Thinking)
 			if d.Delta.Thinking != "" {
 				bus.PushDelta(message.Content{Type: message.ContentThinking, Text: d.Delta.Thinking})
 			}
 		case "signature_delta":
 			b.Signature += d.Delta.Signature
 		case "input_json_delta":
-			if s, ok := b.Input.(string); ok {
-				b.Input = s + d.Delta.PartialJSON
-			} else {
-				b.Input = d.Delta.PartialJSON
+			s, ok := b.Input.(string)
+			if !ok && b.Type == "tool_use" {
 				figOtel.Event(ctx, "provider.tool_use.first_input_delta",
 					attribute.String("tool_call_id", b.ID),
 					attribute.String("tool_name", b.Name),
 					attribute.Int("bytes", len(d.Delta.PartialJSON)),
 				)
 			}
-			if d.Delta.PartialJSON != "" {
+			b.Input = b.grow(s, d.Delta.PartialJSON)
+			if d.Delta.PartialJSON != "" && b.Type == "tool_use" {
 				bus.PushToolInvokeDelta(b.ID, d.Delta.PartialJSON)
 			}
 		}
@@ -1222,6 +1320,16 @@ func (a *Anthropic) foldSSEEvent(ctx context.Context, eventType string, data []b
 			return
 		}
 		b := &nm.Content[stop.Index]
+		if b.Type == "server_tool_use" {
+			if s, ok := b.Input.(string); ok && s != "" {
+				var args map[string]interface{}
+				if json.Unmarshal([]byte(s), &args) == nil {
+					b.Input = args
+				}
+			} else if b.Input == nil {
+				b.Input = map[string]interface{}{}
+			}
+		}
 		if b.Type == "tool_use" {
 			var rawLen int
 			if s, ok := b.Input.(string); ok && s != "" {
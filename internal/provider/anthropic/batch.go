@@ -0,0 +1,209 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/provider"
+)
+
+// Message Batches API: https://docs.anthropic.com/en/api/creating-message-batches.
+// Each request is a one-message conversation under the snapshot's credo;
+// results are a JSONL file fetched from the batch's results_url.
+
+const apiBatchesURL = apiMessagesURL + "/batches"
+
+var _ provider.BatchProvider = (*Anthropic)(nil)
+
+type batchItem struct {
+	CustomID string        `json:"custom_id"`
+	Params   nativeRequest `json:"params"`
+}
+
+type nativeBatch struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"`
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	ResultsURL string     `json:"results_url"`
+}
+
+func (b nativeBatch) status() provider.BatchStatus {
+	st := provider.BatchStatus{
+		ID:         b.ID,
+		State:      b.ProcessingStatus,
+		Ended:      b.ProcessingStatus == "ended",
+		Processing: b.RequestCounts.Processing,
+		Succeeded:  b.RequestCounts.Succeeded,
+		Errored:    b.RequestCounts.Errored,
+		Canceled:   b.RequestCounts.Canceled,
+		Expired:    b.RequestCounts.Expired,
+		CreatedAt:  b.CreatedAt,
+	}
+	if b.EndedAt != nil {
+		st.EndedAt = *b.EndedAt
+	}
+	return st
+}
+
+type batchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string        `json:"type"` // succeeded, errored, canceled, expired
+		Message nativeMessage `json:"message"`
+		Error   struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"error"`
+	} `json:"result"`
+}
+
+// SubmitBatch creates a message batch, one single-turn request per req.
+func (a *Anthropic) SubmitBatch(ctx context.Context, snapshot chalkboard.Snapshot, reqs []provider.BatchRequest) (provider.BatchStatus, error) {
+	if len(reqs) == 0 {
+		return provider.BatchStatus{}, errors.New("anthropic: empty batch")
+	}
+	model := a.resolveModel(snapshot)
+	a.mu.Lock()
+	maxTokens := a.MaxTokens
+	a.mu.Unlock()
+	if maxTokens == 0 {
+		maxTokens = 8192
+	}
+	resp, _, err := a.doWithAuthRetry(ctx, func(apiKey string) (*http.Request, error) {
+		system := systemBlocks(snapshot, isOAuthToken(apiKey))
+		items := make([]batchItem, len(reqs))
+		for i, r := range reqs {
+			it := batchItem{CustomID: r.ID, Params: nativeRequest{
+				Model: model, MaxTokens: maxTokens, System: system,
+				Messages: []nativeMessage{{Role: "user", Content: []nativeBlock{{Type: "text", Text: r.Prompt}}}},
+			}}
+			if r.Model != "" {
+				it.Params.Model = r.Model
+			}
+			if r.MaxTokens > 0 {
+				it.Params.MaxTokens = r.MaxTokens
+			}
+			items[i] = it
+		}
+		body, err := json.Marshal(struct {
+			Requests []batchItem `json:"requests"`
+		}{items})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", apiBatchesURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		a.setAuthHeaders(req, apiKey, betaModels)
+		return req, nil
+	})
+	if err != nil {
+		return provider.BatchStatus{}, err
+	}
+	b, err := decodeBatch(resp)
+	return b.status(), err
+}
+
+// BatchStatus fetches the batch's processing status and request counts.
+func (a *Anthropic) BatchStatus(ctx context.Context, id string) (provider.BatchStatus, error) {
+	b, err := a.getBatch(ctx, id)
+	return b.status(), err
+}
+
+// BatchResults downloads an ended batch's results, in file order.
+func (a *Anthropic) BatchResults(ctx context.Context, id string) ([]provider.BatchResult, error) {
+	b, err := a.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.ResultsURL == "" {
+		return nil, fmt.Errorf("anthropic: batch %s is %s; results are available once it has ended", id, b.ProcessingStatus)
+	}
+	resp, err := a.get(ctx, b.ResultsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errs.HTTP("anthropic batch results", resp.StatusCode, body)
+	}
+	var out []provider.BatchResult
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var line batchResultLine
+		if err := dec.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return out, fmt.Errorf("decode batch results: %w", err)
+		}
+		r := provider.BatchResult{ID: line.CustomID}
+		switch line.Result.Type {
+		case "succeeded":
+			r.Model = line.Result.Message.Model
+			r.Message = decodeNativeMessage(line.Result.Message)
+		case "errored":
+			e := line.Result.Error.Error
+			r.Error = e.Type + ": " + e.Message
+		default:
+			r.Error = line.Result.Type
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (a *Anthropic) getBatch(ctx context.Context, id string) (nativeBatch, error) {
+	resp, err := a.get(ctx, apiBatchesURL+"/"+url.PathEscape(id))
+	if err != nil {
+		return nativeBatch{}, err
+	}
+	return decodeBatch(resp)
+}
+
+func (a *Anthropic) get(ctx context.Context, u string) (*http.Response, error) {
+	resp, _, err := a.doWithAuthRetry(ctx, func(apiKey string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		a.setAuthHeaders(req, apiKey, betaModels)
+		return req, nil
+	})
+	return resp, err
+}
+
+// decodeBatch reads a batch object from resp and closes it.
+func decodeBatch(resp *http.Response) (nativeBatch, error) {
+	defer resp.Body.Close()
+	var b nativeBatch
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return b, errs.HTTP("anthropic batches", resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+		return b, fmt.Errorf("decode batch: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,120 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+)
+
+// redirectTo sends every request to srv, keeping its path, so the
+// hard-wired API URLs reach the test server.
+func redirectTo(srv *httptest.Server) *http.Client {
+	target, _ := url.Parse(srv.URL)
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	var submitted struct {
+		Requests []struct {
+			CustomID string          `json:"custom_id"`
+			Params   json.RawMessage `json:"params"`
+		} `json:"requests"`
+	}
+	ended := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sk-test", r.Header.Get("x-api-key"))
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/messages/batches":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&submitted))
+			fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress","request_counts":{"processing":2},"created_at":"2026-10-15T10:00:00Z"}`)
+		case r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			if !ended {
+				fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress","request_counts":{"processing":2}}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"ended","request_counts":{"succeeded":1,"errored":1},`+
+				`"ended_at":"2026-10-15T11:00:00Z","results_url":"https://api.anthropic.com/v1/messages/batches/msgbatch_1/results"}`)
+		case r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			fmt.Fprintln(w, `{"custom_id":"p2","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"too long"}}}}`)
+			fmt.Fprintln(w, `{"custom_id":"p1","result":{"type":"succeeded","message":{"role":"assistant","model":"claude-x",`+
+				`"content":[{"type":"text","text":"four"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":2}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	a := &Anthropic{auth: &staticAuth{token: "sk-test"}, HTTPClient: redirectTo(srv), Model: "claude-default"}
+	ctx := context.Background()
+	snap := chalkboard.Snapshot{"system.credo": json.RawMessage(`"be brief"`)}
+	st, err := a.SubmitBatch(ctx, snap, []provider.BatchRequest{
+		{ID: "p1", Prompt: "2+2?"},
+		{ID: "p2", Prompt: "long", Model: "claude-other", MaxTokens: 100},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "msgbatch_1", st.ID)
+	assert.False(t, st.Ended)
+	assert.Equal(t, 2, st.Processing)
+
+	require.Len(t, submitted.Requests, 2)
+	var p1, p2 nativeRequest
+	require.NoError(t, json.Unmarshal(submitted.Requests[0].Params, &p1))
+	require.NoError(t, json.Unmarshal(submitted.Requests[1].Params, &p2))
+	assert.Equal(t, "p1", submitted.Requests[0].CustomID)
+	assert.Equal(t, "claude-default", p1.Model)
+	assert.Equal(t, 8192, p1.MaxTokens)
+	assert.Equal(t, "be brief", p1.System[0].Text)
+	assert.Equal(t, "2+2?", p1.Messages[0].Content[0].Text)
+	assert.False(t, p1.Stream)
+	assert.Equal(t, "claude-other", p2.Model)
+	assert.Equal(t, 100, p2.MaxTokens)
+
+	_, err = a.BatchResults(ctx, "msgbatch_1")
+	require.ErrorContains(t, err, "in_progress")
+
+	ended = true
+	st, err = a.BatchStatus(ctx, "msgbatch_1")
+	require.NoError(t, err)
+	assert.True(t, st.Ended)
+	assert.Equal(t, 1, st.Succeeded)
+	assert.False(t, st.EndedAt.IsZero())
+
+	results, err := a.BatchResults(ctx, "msgbatch_1")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "p2", results[0].ID)
+	assert.Equal(t, "invalid_request_error: too long", results[0].Error)
+	assert.Equal(t, "p1", results[1].ID)
+	assert.Equal(t, "claude-x", results[1].Model)
+	assert.Equal(t, message.RoleAssistant, results[1].Message.Role)
+	assert.Equal(t, "four", results[1].Message.Content[0].Text)
+	assert.Equal(t, message.StopEnd, results[1].Message.StopReason)
+	assert.Equal(t, 2, results[1].Message.Usage.OutputTokens)
+}
+
+func TestBatchAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"bad custom_id"}}`)
+	}))
+	defer srv.Close()
+	a := &Anthropic{auth: &staticAuth{token: "sk-test"}, HTTPClient: redirectTo(srv)}
+	_, err := a.SubmitBatch(context.Background(), nil, []provider.BatchRequest{{ID: "x", Prompt: "hi"}})
+	require.ErrorContains(t, err, "400")
+	_, err = a.SubmitBatch(context.Background(), nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/provider"
+)
+
+// Token counting API: https://docs.anthropic.com/en/api/messages-count-tokens.
+// The body is the Messages request Send would post, less max_tokens and
+// stream, which the endpoint rejects.
+
+const apiCountTokensURL = apiMessagesURL + "/count_tokens"
+
+var _ provider.TokenCounter = (*Anthropic)(nil)
+
+type countRequest struct {
+	Model    string          `json:"model"`
+	System   []systemBlock   `json:"system,omitempty"`
+	Messages []nativeMessage `json:"messages"`
+	Tools    []nativeTool    `json:"tools,omitempty"`
+	Thinking *thinkingParam  `json:"thinking,omitempty"`
+}
+
+// CountTokens returns the input tokens Send would bill for in; it is
+// PrepareCount and the count it returns in one call.
+func (a *Anthropic) CountTokens(ctx context.Context, in provider.SendInput) (int, error) {
+	count, err := a.PrepareCount(in)
+	if err != nil {
+		return 0, err
+	}
+	return count(ctx)
+}
+
+// PrepareCount projects in the same way Send does, so the translation
+// cache is caught up as a side effect and the Send that follows finds it
+// warm. The returned count builds and posts the request from that
+// projection alone.
+func (a *Anthropic) PrepareCount(in provider.SendInput) (func(ctx context.Context) (int, error), error) {
+	cache, err := a.cacheFor(in.AriaID)
+	if err != nil {
+		return nil, err
+	}
+	perMessage, lts := a.catchUp(in.FigLog, cache, in.Chalkboard)
+	if len(perMessage) == 0 {
+		return nil, fmt.Errorf("empty context")
+	}
+	return func(ctx context.Context) (int, error) {
+		return a.count(ctx, in, perMessage, lts)
+	}, nil
+}
+
+func (a *Anthropic) count(ctx context.Context, in provider.SendInput, perMessage [][]json.RawMessage, lts []uint64) (int, error) {
+	apiKey, err := a.auth.Resolve()
+	if err != nil {
+		return 0, errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", err))
+	}
+	model := a.resolveModel(in.Snapshot)
+	req, err := a.projectMessagesWithLTs(perMessage, lts, in.Snapshot, in.Tools, in.MaxTokens, isOAuthToken(apiKey), model)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(countRequest{
+		Model: req.Model, System: req.System, Messages: req.Messages, Tools: req.Tools, Thinking: req.Thinking,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("marshal count request: %w", err)
+	}
+
+	resp, _, err := a.doWithAuthRetry(ctx, func(token string) (*http.Request, error) {
+		httpReq, herr := http.NewRequestWithContext(ctx, "POST", apiCountTokensURL, bytes.NewReader(body))
+		if herr != nil {
+			return nil, fmt.Errorf("create request: %w", herr)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		a.setAuthHeaders(httpReq, token, betaMessages)
+		addBetas(httpReq, provider.ServerToolsFrom(in.Snapshot).Betas())
+		return httpReq, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(resp.Body)
+		return 0, errs.HTTP("anthropic count_tokens", resp.StatusCode, errBody)
+	}
+	var out struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("decode count: %w", err)
+	}
+	return out.InputTokens, nil
+}
@@ -0,0 +1,93 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+func TestCountTokens(t *testing.T) {
+	var got map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages/count_tokens", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"input_tokens":1234}`)
+	}))
+	defer srv.Close()
+
+	log := store.NewMemLog[message.Message]()
+	log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role: message.RoleUser, Content: []message.Content{message.TextContent("hello")},
+	}})
+	a := &Anthropic{auth: &staticAuth{token: "sk-test"}, HTTPClient: redirectTo(srv), Model: "claude-x"}
+	n, err := a.CountTokens(context.Background(), provider.SendInput{
+		FigLog:    log,
+		Snapshot:  chalkboard.Snapshot{"system.credo": json.RawMessage(`"be brief"`)},
+		Tools:     []provider.Tool{{Name: "bash", Description: "run", Parameters: map[string]any{"type": "object"}}},
+		MaxTokens: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1234, n)
+	assert.JSONEq(t, `"claude-x"`, string(got["model"]))
+	assert.Contains(t, got, "system")
+	assert.Contains(t, got, "tools")
+	assert.Contains(t, got, "messages")
+	assert.NotContains(t, got, "max_tokens", "count_tokens rejects max_tokens")
+	assert.NotContains(t, got, "stream", "count_tokens rejects stream")
+}
+
+func TestCountTokensErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"not here"}}`)
+	}))
+	defer srv.Close()
+	a := &Anthropic{auth: &staticAuth{token: "sk-test"}, HTTPClient: redirectTo(srv)}
+
+	_, err := a.CountTokens(context.Background(), provider.SendInput{FigLog: store.NewMemLog[message.Message]()})
+	require.ErrorContains(t, err, "empty context")
+
+	log := store.NewMemLog[message.Message]()
+	log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")},
+	}})
+	_, err = a.CountTokens(context.Background(), provider.SendInput{FigLog: log})
+	require.ErrorContains(t, err, "404")
+}
+
+func TestCountTokensClassifiesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		body   string
+		kind   errs.Kind
+	}{
+		{http.StatusUnauthorized, `{"error":{"type":"authentication_error","message":"invalid x-api-key"}}`, errs.Auth},
+		{http.StatusBadRequest, `{"error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, errs.ContextLength},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			fmt.Fprint(w, tc.body)
+		}))
+		a := &Anthropic{auth: &staticAuth{token: "sk-test"}, HTTPClient: redirectTo(srv)}
+		log := store.NewMemLog[message.Message]()
+		log.Append(store.Entry[message.Message]{Payload: message.Message{
+			Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")},
+		}})
+		_, err := a.CountTokens(context.Background(), provider.SendInput{FigLog: log})
+		srv.Close()
+		require.Error(t, err)
+		assert.Equal(t, tc.kind, errs.KindOf(err), "status %d: %v", tc.status, err)
+	}
+}
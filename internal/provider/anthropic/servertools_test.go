@@ -0,0 +1,115 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+func TestServerTools_Projected(t *testing.T) {
+	a := &Anthropic{}
+	snap := chalkboard.Snapshot{
+		provider.WebSearchKey:        json.RawMessage(`true`),
+		provider.WebSearchDomainsKey: json.RawMessage(`"go.dev, pkg.go.dev"`),
+		provider.CodeExecutionKey:    json.RawMessage(`"on"`),
+		"system.cache_control":       json.RawMessage(`"ephemeral"`),
+	}
+	tools := []provider.Tool{{Name: "bash", Description: "run", Parameters: map[string]any{"type": "object"}}}
+	req, err := a.projectMessagesWithModel(nil, snap, tools, 0, false, "claude-test")
+	require.NoError(t, err)
+	raw, err := json.Marshal(req.Tools)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name":"bash","description":"run","input_schema":{"type":"object"}},
+		{"type":"web_search_20250305","name":"web_search","max_uses":5,"allowed_domains":["go.dev","pkg.go.dev"]},
+		{"type":"code_execution_20250825","name":"code_execution","cache_control":{"type":"ephemeral"}}
+	]`, string(raw))
+
+	req, err = a.projectMessagesWithModel(nil, nil, tools, 0, false, "claude-test")
+	require.NoError(t, err)
+	assert.Len(t, req.Tools, 1, "server tools are off by default")
+}
+
+func TestServerTools_FoldAndDecode(t *testing.T) {
+	a := &Anthropic{ReminderRenderer: "tag", CacheNamespace: "anthropic"}
+	nm := nativeMessage{Role: "assistant", StopReason: "end_turn", Usage: &nativeUsage{}}
+	fold := func(event, data string) {
+		a.foldSSEEvent(context.Background(), event, []byte(data), &nm, nm.Usage, &nm.StopReason, noOpBus{})
+	}
+	fold("content_block_start", `{"index":0,"content_block":{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{}}}`)
+	fold("content_block_delta", `{"index":0,"delta":{"type":"input_json_delta","partial_json":"{\"query\":"}}`)
+	fold("content_block_delta", `{"index":0,"delta":{"type":"input_json_delta","partial_json":"\"go generics\"}"}}`)
+	fold("content_block_stop", `{"index":0}`)
+	fold("content_block_start", `{"index":1,"content_block":{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","title":"Tutorial","url":"https://go.dev/doc/tutorial/generics","encrypted_content":"opaque"}]}}`)
+	fold("content_block_stop", `{"index":1}`)
+	fold("content_block_start", `{"index":2,"content_block":{"type":"text","text":""}}`)
+	fold("content_block_delta", `{"index":2,"delta":{"type":"text_delta","text":"Use type parameters."}}`)
+	fold("content_block_stop", `{"index":2}`)
+
+	m := decodeNativeMessage(nm)
+	require.Len(t, m.Content, 3)
+	assert.Equal(t, message.Content{
+		Type: message.ContentServerToolInvoke, ToolCallID: "srvtoolu_1", ToolName: "web_search",
+		Arguments: map[string]any{"query": "go generics"},
+	}, m.Content[0])
+	assert.Equal(t, message.Content{
+		Type: message.ContentServerToolResult, ToolCallID: "srvtoolu_1", ToolName: "web_search",
+		Text: "Tutorial — https://go.dev/doc/tutorial/generics",
+	}, m.Content[1])
+	assert.Equal(t, "Use type parameters.", m.Content[2].Text)
+
+	cache, err := a.assistantCacheNative(nm)
+	require.NoError(t, err)
+	require.Len(t, cache.Payload, 1)
+	var replay nativeMessage
+	require.NoError(t, json.Unmarshal(cache.Payload[0], &replay))
+	require.Len(t, replay.Content, 3, "server blocks replay from the cache")
+	assert.Equal(t, "server_tool_use", replay.Content[0].Type)
+	assert.Equal(t, "web_search_tool_result", replay.Content[1].Type)
+	assert.Contains(t, string(cache.Payload[0]), `"encrypted_content":"opaque"`)
+}
+
+func TestServerTools_BetaHeader(t *testing.T) {
+	var betas []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betas = append(betas, r.Header.Get("anthropic-beta"))
+		fmt.Fprint(w, `{"input_tokens":1}`)
+	}))
+	defer srv.Close()
+
+	log := store.NewMemLog[message.Message]()
+	log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")},
+	}})
+	count := func(token string, snap chalkboard.Snapshot) {
+		a := &Anthropic{auth: &staticAuth{token: token}, HTTPClient: redirectTo(srv)}
+		_, err := a.CountTokens(context.Background(), provider.SendInput{FigLog: log, Snapshot: snap})
+		require.NoError(t, err)
+	}
+	tools := chalkboard.Snapshot{
+		provider.WebSearchKey:     json.RawMessage(`true`),
+		provider.WebFetchKey:      json.RawMessage(`true`),
+		provider.CodeExecutionKey: json.RawMessage(`true`),
+	}
+	count("sk-test", nil)
+	count("sk-test", chalkboard.Snapshot{provider.WebSearchKey: json.RawMessage(`true`)})
+	count("sk-test", tools)
+	count("sk-ant-oat-test", tools)
+	assert.Equal(t, []string{
+		"",
+		"",
+		"web-fetch-2025-09-10,code-execution-2025-08-25",
+		betaMessages + ",web-fetch-2025-09-10,code-execution-2025-08-25",
+	}, betas)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/auth"
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/message"
 	"github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/store"
@@ -50,6 +51,10 @@ type Provider struct {
 	// of what the token looks like.
 	NoOAuthIdentity bool
 
+	// NoServerTools, when true, never offers server tools (web search,
+	// code execution), for endpoints that only speak client tools.
+	NoServerTools bool
+
 	// CacheOpen opens the per-aria translation cache. nil disables caching.
 	CacheOpen      func(aria string) (store.Log[[]json.RawMessage], error)
 	CacheNamespace string
@@ -71,15 +76,15 @@ func New(knobs provider.Knobs, resolver auth.TokenResolver, cacheOpen func(aria
 		model:          knobs.Model,
 		maxTokens:      knobs.MaxTokens,
 		reminder:       rr,
-		httpClient:     &http.Client{Timeout: 10 * time.Minute, Transport: &wirelog.Transport{Inner: http.DefaultTransport}},
+		httpClient:     &http.Client{Timeout: 10 * time.Minute, Transport: &wirelog.Transport{Inner: provider.Transport()}},
 		CacheOpen:      cacheOpen,
 		CacheNamespace: providerName,
 	}, nil
 }
 
 // HTTPClient exposes the inner client so callers (cli wiring) can
-// install transports such as wirelog. The default already wraps
-// http.DefaultTransport with wirelog.
+// install transports such as wirelog. The default already wraps the
+// shared provider transport with wirelog.
 func (p *Provider) HTTPClient() *http.Client { return p.httpClient }
 
 func (p *Provider) Name() string { return providerName }
@@ -156,9 +161,11 @@ func (p *Provider) Send(ctx context.Context, in provider.SendInput, bus provider
 		// read it back from the resolver here for the system shape.
 		tok, terr := p.resolver.Resolve()
 		if terr != nil {
-			return fmt.Errorf("resolve token: %w", terr)
+			return errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", terr))
 		}
-		params := buildParams(projected.Messages, projected.LogicalTimes, in.Snapshot, in.Tools, int64(maxTokens), isOAuthToken(tok) && !p.NoOAuthIdentity, model)
+		snap := p.paramsSnapshot(in.Snapshot)
+		params := buildParams(projected.Messages, projected.LogicalTimes, snap, in.Tools, int64(maxTokens), isOAuthToken(tok) && !p.NoOAuthIdentity, model)
+		opts = append(opts, serverToolBetas(provider.ServerToolsFrom(snap))...)
 		client := anthropic.NewClient(opts...)
 		stream := client.Messages.NewStreaming(ctx, params, opts...)
 		assembled, raw, serr := drainStream(ctx, stream, model, bus)
@@ -246,3 +253,12 @@ func (p *Provider) resolveModel(snap chalkboard.Snapshot) string {
 	defer p.mu.Unlock()
 	return p.model
 }
+
+// paramsSnapshot is the snapshot requests are built from: in.Snapshot,
+// less the server-tool keys when the endpoint doesn't take them.
+func (p *Provider) paramsSnapshot(snap chalkboard.Snapshot) chalkboard.Snapshot {
+	if !p.NoServerTools {
+		return snap
+	}
+	return provider.WithoutServerTools(snap)
+}
@@ -17,7 +17,7 @@ func buildParams(messages []anthropic.MessageParam, lts []uint64, snap chalkboar
 		MaxTokens: maxTokens,
 		Model:     anthropic.Model(model),
 		System:    systemBlocks(snap, oauth),
-		Tools:     toolUnions(tools),
+		Tools:     append(toolUnions(tools), serverToolUnions(provider.ServerToolsFrom(snap))...),
 		Messages:  append([]anthropic.MessageParam(nil), messages...),
 	}
 	msgLTs := lts
@@ -161,7 +161,7 @@ func thinkingStr(raw json.RawMessage) string {
 }
 
 // systemBlocks builds the system prefix: identity preamble (OAuth
-// only) + credo. Credo lives at `system.credo` and may be a bare
+// only) + credo + pins and reply language. Credo lives at `system.credo` and may be a bare
 // string or a ContentEnvelope object (from the outfitter's fileName
 // loader). See readCredo for unwrap rules.
 func systemBlocks(snap chalkboard.Snapshot, oauth bool) []anthropic.TextBlockParam {
@@ -176,6 +176,9 @@ func systemBlocks(snap chalkboard.Snapshot, oauth bool) []anthropic.TextBlockPar
 	} else if systemText != "" {
 		out = append(out, anthropic.TextBlockParam{Text: systemText})
 	}
+	if managed := provider.ManagedText(snap); managed != "" {
+		out = append(out, anthropic.TextBlockParam{Text: managed})
+	}
 	return out
 }
 
@@ -220,6 +223,25 @@ func toolUnions(tools []provider.Tool) []anthropic.ToolUnionParam {
 	return out
 }
 
+// serverToolUnions lists the server tools the aria has on, after its own
+// tools in a fixed order.
+func serverToolUnions(s provider.ServerTools) []anthropic.ToolUnionParam {
+	var out []anthropic.ToolUnionParam
+	if s.WebSearch {
+		out = append(out, anthropic.ToolUnionParam{OfWebSearchTool20250305: &anthropic.WebSearchTool20250305Param{
+			MaxUses:        anthropic.Int(int64(s.WebSearchMaxUses)),
+			AllowedDomains: s.WebSearchDomains,
+		}})
+	}
+	if s.WebFetch {
+		out = append(out, anthropic.ToolUnionParam{OfWebFetchTool20250910: &anthropic.WebFetchTool20250910Param{}})
+	}
+	if s.CodeExecution {
+		out = append(out, anthropic.ToolUnionParam{OfCodeExecutionTool20250825: &anthropic.CodeExecutionTool20250825Param{}})
+	}
+	return out
+}
+
 // toolInputSchema lifts a free-form JSON-schema map into the SDK's
 // ToolInputSchemaParam, preserving unknown keys via ExtraFields.
 func toolInputSchema(params any) anthropic.ToolInputSchemaParam {
@@ -295,8 +317,8 @@ func markCacheBreakpoints(params *anthropic.MessageNewParams, setting string) {
 		params.System[n-1].CacheControl = cc
 	}
 	if n := len(params.Tools); n > 0 {
-		if t := params.Tools[n-1].OfTool; t != nil {
-			t.CacheControl = cc
+		if t := params.Tools[n-1].GetCacheControl(); t != nil {
+			*t = cc
 		}
 	}
 	if n := len(params.Messages); n >= 1 {
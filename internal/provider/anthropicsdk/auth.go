@@ -8,6 +8,9 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/provider"
 )
 
 // OAuth tokens are issued via the Claude Pro/Max OAuth flow. They
@@ -62,32 +65,42 @@ func (p *Provider) authOptions(token, betas string) []option.RequestOption {
 	return opts
 }
 
+// serverToolBetas is the option adding the beta flags the aria's server
+// tools need to the messages defaults; nil when none do.
+func serverToolBetas(s provider.ServerTools) []option.RequestOption {
+	extra := s.Betas()
+	if len(extra) == 0 {
+		return nil
+	}
+	return []option.RequestOption{option.WithHeader("anthropic-beta", betaMessages+","+strings.Join(extra, ","))}
+}
+
 // callWithAuthRetry resolves a token, runs do, and on 401 invalidates
 // the token and retries once.
 func (p *Provider) callWithAuthRetry(ctx context.Context, do func(opts []option.RequestOption) error) error {
 	token, err := p.resolver.Resolve()
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", err))
 	}
 	err = do(p.authOptions(token, betaMessages))
 	if err == nil {
 		return nil
 	}
 	if !isUnauthorized(err) {
-		return err
+		return classify(err)
 	}
 	ierr := p.resolver.Invalidate(token)
 	fresh, rerr := p.resolver.Resolve()
 	if rerr != nil {
-		return fmt.Errorf("resolve after 401: %w", errors.Join(ierr, rerr))
+		return errs.Wrap(errs.Auth, fmt.Errorf("resolve after 401: %w", errors.Join(ierr, rerr)))
 	}
 	if fresh == token {
 		if ierr != nil {
-			return fmt.Errorf("anthropicsdk 401: invalidate failed: %w", ierr)
+			return errs.Wrap(errs.Auth, fmt.Errorf("anthropicsdk 401: invalidate failed: %w", ierr))
 		}
-		return fmt.Errorf("anthropicsdk 401: token unchanged after invalidate")
+		return errs.New(errs.Auth, "anthropicsdk 401: token unchanged after invalidate")
 	}
-	return do(p.authOptions(fresh, betaMessages))
+	return classify(do(p.authOptions(fresh, betaMessages)))
 }
 
 func isUnauthorized(err error) bool {
@@ -97,3 +110,13 @@ func isUnauthorized(err error) bool {
 	}
 	return false
 }
+
+// classify turns an SDK API error into an *errs.Error; other errors pass
+// through.
+func classify(err error) error {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		return errs.HTTP("anthropic", apiErr.StatusCode, []byte(apiErr.RawJSON()))
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package anthropicsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/jack-work/figaro/internal/errs"
+	"github.com/jack-work/figaro/internal/provider"
+)
+
+var _ provider.TokenCounter = (*Provider)(nil)
+
+// countFields are the MessageNewParams fields count_tokens accepts; it
+// rejects the rest (max_tokens, sampling, metadata) as extra inputs.
+var countFields = []string{"model", "system", "messages", "tools", "tool_choice", "thinking"}
+
+// CountTokens returns the input tokens Send would bill for in; it is
+// PrepareCount and the count it returns in one call.
+func (p *Provider) CountTokens(ctx context.Context, in provider.SendInput) (int, error) {
+	count, err := p.PrepareCount(in)
+	if err != nil {
+		return 0, err
+	}
+	return count(ctx)
+}
+
+// PrepareCount catches the translation cache up the way Send does. The
+// SDK's count params are a separate type tree, so the returned count
+// builds the request Send would make and trims it to the fields the
+// endpoint takes.
+func (p *Provider) PrepareCount(in provider.SendInput) (func(ctx context.Context) (int, error), error) {
+	cache, err := p.cacheFor(in.AriaID)
+	if err != nil {
+		return nil, err
+	}
+	projected, err := p.catchUp(in.FigLog, cache, in.Chalkboard)
+	if err != nil {
+		return nil, err
+	}
+	if len(projected.Messages) == 0 {
+		return nil, fmt.Errorf("empty context")
+	}
+	return func(ctx context.Context) (int, error) {
+		return p.count(ctx, in, projected)
+	}, nil
+}
+
+func (p *Provider) count(ctx context.Context, in provider.SendInput, projected projectedMessages) (int, error) {
+	model := p.resolveModel(in.Snapshot)
+
+	var res anthropic.MessageTokensCount
+	err := p.callWithAuthRetry(ctx, func(opts []option.RequestOption) error {
+		tok, terr := p.resolver.Resolve()
+		if terr != nil {
+			return errs.Wrap(errs.Auth, fmt.Errorf("resolve token: %w", terr))
+		}
+		snap := p.paramsSnapshot(in.Snapshot)
+		params := buildParams(projected.Messages, projected.LogicalTimes, snap, in.Tools, int64(in.MaxTokens), isOAuthToken(tok) && !p.NoOAuthIdentity, model)
+		opts = append(opts, serverToolBetas(provider.ServerToolsFrom(snap))...)
+		body, err := countBody(params)
+		if err != nil {
+			return err
+		}
+		client := anthropic.NewClient(opts...)
+		return client.Post(ctx, "v1/messages/count_tokens", nil, &res, option.WithRequestBody("application/json", body))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(res.InputTokens), nil
+}
+
+func countBody(params anthropic.MessageNewParams) ([]byte, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal count request: %w", err)
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	kept := make(map[string]json.RawMessage, len(countFields))
+	for _, k := range countFields {
+		if v, ok := all[k]; ok {
+			kept[k] = v
+		}
+	}
+	return json.Marshal(kept)
+}
@@ -0,0 +1,49 @@
+package anthropicsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+func TestCountTokens(t *testing.T) {
+	var got map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages/count_tokens", r.URL.Path)
+		assert.Equal(t, "sk-test", r.Header.Get("x-api-key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"input_tokens":321}`)
+	}))
+	defer srv.Close()
+
+	p, err := New(provider.Knobs{Model: "claude-x", MaxTokens: 4096}, &fakeResolver{tokens: []string{"sk-test"}}, nil)
+	require.NoError(t, err)
+	p.ExtraOptions = []option.RequestOption{option.WithBaseURL(srv.URL)}
+
+	log := store.NewMemLog[message.Message]()
+	log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role: message.RoleUser, Content: []message.Content{message.TextContent("hello")},
+	}})
+	n, err := p.CountTokens(context.Background(), provider.SendInput{
+		FigLog:   log,
+		Snapshot: chalkboard.Snapshot{"system.credo": json.RawMessage(`"be brief"`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 321, n)
+	assert.JSONEq(t, `"claude-x"`, string(got["model"]))
+	assert.Contains(t, got, "messages")
+	assert.NotContains(t, got, "max_tokens", "count_tokens rejects max_tokens")
+}
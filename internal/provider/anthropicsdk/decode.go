@@ -7,6 +7,7 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 
 	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
 )
 
 // validAccumulatedBlock reports whether an accumulated block is
@@ -25,7 +26,7 @@ func validAccumulatedBlock(b anthropic.ContentBlockUnion) bool {
 		return strings.TrimSpace(b.Thinking) != ""
 	case "redacted_thinking":
 		return b.Data != ""
-	case "tool_use":
+	case "tool_use", "server_tool_use":
 		return b.ID != "" && len(b.Input) > 0
 	case "":
 		return false
@@ -48,7 +49,7 @@ func cacheableAccumulatedBlock(b anthropic.ContentBlockUnion) (keep, fatal bool)
 		return b.Signature != "" || strings.TrimSpace(b.Thinking) != "", false
 	case "redacted_thinking":
 		return b.Data != "", false
-	case "tool_use":
+	case "tool_use", "server_tool_use":
 		if b.ID == "" {
 			return false, false
 		}
@@ -89,6 +90,22 @@ func decodeAssistantMessage(m anthropic.Message) message.Message {
 				ToolName:   v.Name,
 				Arguments:  asArgsMap(v.Input),
 			})
+		case anthropic.ServerToolUseBlock:
+			out.Content = append(out.Content, message.Content{
+				Type:       message.ContentServerToolInvoke,
+				ToolCallID: v.ID,
+				ToolName:   string(v.Name),
+				Arguments:  asArgsMap(b.Input),
+			})
+		default:
+			if provider.IsServerToolResult(b.Type) {
+				var r struct {
+					ToolUseID string          `json:"tool_use_id"`
+					Content   json.RawMessage `json:"content"`
+				}
+				json.Unmarshal([]byte(b.RawJSON()), &r)
+				out.Content = append(out.Content, provider.ServerToolResult(b.Type, r.ToolUseID, r.Content))
+			}
 		}
 	}
 	out.StopReason = mapStopReason(m.StopReason)
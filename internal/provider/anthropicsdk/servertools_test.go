@@ -0,0 +1,99 @@
+package anthropicsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+func TestServerTools_Params(t *testing.T) {
+	snap := chalkboard.Snapshot{
+		provider.WebSearchKey:        json.RawMessage(`true`),
+		provider.WebSearchMaxUsesKey: json.RawMessage(`2`),
+		provider.WebSearchDomainsKey: json.RawMessage(`["go.dev"]`),
+		provider.WebFetchKey:         json.RawMessage(`true`),
+	}
+	tools := []provider.Tool{{Name: "bash", Description: "run", Parameters: map[string]any{"type": "object"}}}
+	params := buildParams(nil, nil, snap, tools, 1024, false, "claude-test")
+	require.Len(t, params.Tools, 3)
+	raw, err := json.Marshal(params.Tools[1:])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type":"web_search_20250305","name":"web_search","max_uses":2,"allowed_domains":["go.dev"]},
+		{"type":"web_fetch_20250910","name":"web_fetch","cache_control":{"type":"ephemeral"}}
+	]`, string(raw))
+
+	params = buildParams(nil, nil, nil, tools, 1024, false, "claude-test")
+	assert.Len(t, params.Tools, 1, "server tools are off by default")
+}
+
+func TestServerTools_Decode(t *testing.T) {
+	var m anthropic.Message
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id":"msg_1","type":"message","role":"assistant","model":"claude-test","stop_reason":"end_turn",
+		"usage":{"input_tokens":1,"output_tokens":1},
+		"content":[
+			{"type":"server_tool_use","id":"srvtoolu_1","name":"code_execution","input":{"code":"print(1)"}},
+			{"type":"code_execution_tool_result","tool_use_id":"srvtoolu_1","content":{"type":"code_execution_result","stdout":"1\n","stderr":"","return_code":0,"content":[]}},
+			{"type":"text","text":"It prints 1."}
+		]}`), &m))
+
+	out := decodeAssistantMessage(m)
+	require.Len(t, out.Content, 3)
+	assert.Equal(t, message.Content{
+		Type: message.ContentServerToolInvoke, ToolCallID: "srvtoolu_1", ToolName: "code_execution",
+		Arguments: map[string]any{"code": "print(1)"},
+	}, out.Content[0])
+	assert.Equal(t, message.Content{
+		Type: message.ContentServerToolResult, ToolCallID: "srvtoolu_1", ToolName: "code_execution", Text: "1",
+	}, out.Content[1])
+	assert.Equal(t, "It prints 1.", out.Content[2].Text)
+}
+
+func TestServerTools_BetaHeader(t *testing.T) {
+	var betas []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betas = append(betas, r.Header.Get("anthropic-beta"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"input_tokens":1}`)
+	}))
+	defer srv.Close()
+
+	log := store.NewMemLog[message.Message]()
+	log.Append(store.Entry[message.Message]{Payload: message.Message{
+		Role: message.RoleUser, Content: []message.Content{message.TextContent("hi")},
+	}})
+	snap := chalkboard.Snapshot{
+		provider.WebFetchKey:      json.RawMessage(`true`),
+		provider.CodeExecutionKey: json.RawMessage(`true`),
+	}
+	count := func(noServerTools bool, snap chalkboard.Snapshot) {
+		p, err := New(provider.Knobs{Model: "claude-x", MaxTokens: 4096}, &fakeResolver{tokens: []string{"sk-test"}}, nil)
+		require.NoError(t, err)
+		p.ExtraOptions = []option.RequestOption{option.WithBaseURL(srv.URL)}
+		p.NoServerTools = noServerTools
+		_, err = p.CountTokens(context.Background(), provider.SendInput{FigLog: log, Snapshot: snap})
+		require.NoError(t, err)
+	}
+	count(false, nil)
+	count(false, snap)
+	count(true, snap)
+	assert.Equal(t, []string{
+		betaMessages,
+		betaMessages + ",web-fetch-2025-09-10,code-execution-2025-08-25",
+		betaMessages,
+	}, betas)
+}
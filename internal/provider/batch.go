@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// BatchProvider optionally runs single-turn prompts as one offline batch,
+// billed at the provider's batch rate. A batch finishes as a whole, often
+// hours after submission: callers poll BatchStatus and collect
+// BatchResults once it has ended.
+type BatchProvider interface {
+	// SubmitBatch sends reqs in one batch. snapshot supplies the system
+	// prompt (system.credo) every request shares.
+	SubmitBatch(ctx context.Context, snapshot chalkboard.Snapshot, reqs []BatchRequest) (BatchStatus, error)
+	BatchStatus(ctx context.Context, id string) (BatchStatus, error)
+	// BatchResults returns one result per request of an ended batch.
+	BatchResults(ctx context.Context, id string) ([]BatchResult, error)
+}
+
+// BatchRequest is one prompt of a batch. ID is the caller's key for it,
+// unique within the batch. Model and MaxTokens default to the provider's.
+type BatchRequest struct {
+	ID        string `json:"id"`
+	Prompt    string `json:"prompt"`
+	Model     string `json:"model,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+}
+
+// BatchStatus is a batch's progress. The counts are requests per state.
+type BatchStatus struct {
+	ID         string
+	State      string // provider wording, e.g. "in_progress", "ended"
+	Ended      bool
+	Processing int
+	Succeeded  int
+	Errored    int
+	Canceled   int
+	Expired    int
+	CreatedAt  time.Time
+	EndedAt    time.Time
+}
+
+// BatchResult is the outcome of one request. Message is the assistant
+// reply; it is zero when Error is set.
+type BatchResult struct {
+	ID      string
+	Model   string
+	Message message.Message
+	Error   string
+}
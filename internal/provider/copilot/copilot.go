@@ -20,6 +20,7 @@ import (
 
 	"github.com/jack-work/figaro/internal/auth"
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/provider/anthropicsdk"
 	"github.com/jack-work/figaro/internal/store"
@@ -69,6 +70,7 @@ func New(
 		return nil, err
 	}
 	inner.NoOAuthIdentity = true
+	inner.NoServerTools = true
 	inner.CacheNamespace = "copilot-messages"
 	inner.ExtraOptions = copilotRequestOptions(tokenSrc)
 
@@ -310,7 +312,7 @@ func isAnthropicModel(model string) bool {
 func (c *Copilot) fetchCatalog(ctx context.Context) ([]catalogModel, error) {
 	token, err := c.tokenSrc.Resolve()
 	if err != nil {
-		return nil, fmt.Errorf("copilot models: resolve token: %w", err)
+		return nil, errs.Wrap(errs.Auth, fmt.Errorf("copilot models: resolve token: %w", err))
 	}
 	baseURL := baseURLFromToken(token, c.tokenSrc.domain)
 	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
@@ -325,7 +327,7 @@ func (c *Copilot) fetchCatalog(ctx context.Context) ([]catalogModel, error) {
 	for k, v := range copilotGitHubHeaders {
 		req.Header.Set(k, v)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := provider.Client(0).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +433,7 @@ func exchangeCopilotToken(githubAccessToken, enterpriseDomain string) (token str
 	for k, v := range copilotGitHubHeaders {
 		req.Header.Set(k, v)
 	}
-	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	resp, err := provider.Client(15 * time.Second).Do(req)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("copilot token exchange: %w", err)
 	}
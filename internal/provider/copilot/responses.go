@@ -16,6 +16,7 @@ import (
 	"golang.org/x/net/websocket"
 
 	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/errs"
 	"github.com/jack-work/figaro/internal/message"
 	"github.com/jack-work/figaro/internal/provider"
 	"github.com/jack-work/figaro/internal/store"
@@ -110,7 +111,7 @@ func (p *responsesProvider) Send(ctx context.Context, in provider.SendInput, bus
 	}
 	token, err := p.tokenSrc.Resolve()
 	if err != nil {
-		return fmt.Errorf("copilot responses: resolve token: %w", err)
+		return errs.Wrap(errs.Auth, fmt.Errorf("copilot responses: resolve token: %w", err))
 	}
 
 	err = p.sendWithToken(ctx, token, in, bus, options)
@@ -118,11 +119,11 @@ func (p *responsesProvider) Send(ctx context.Context, in provider.SendInput, bus
 		return err
 	}
 	if ierr := p.tokenSrc.Invalidate(token); ierr != nil {
-		return fmt.Errorf("copilot responses: invalidate token: %w", ierr)
+		return errs.Wrap(errs.Auth, fmt.Errorf("copilot responses: invalidate token: %w", ierr))
 	}
 	token, err = p.tokenSrc.Resolve()
 	if err != nil {
-		return fmt.Errorf("copilot responses: resolve refreshed token: %w", err)
+		return errs.Wrap(errs.Auth, fmt.Errorf("copilot responses: resolve refreshed token: %w", err))
 	}
 	return p.sendWithToken(ctx, token, in, bus, options)
 }
@@ -282,7 +283,7 @@ func (p *responsesProvider) validateContext(
 
 	used, _ := contextSizeForLog(in.FigLog)
 	if used > tierLimit {
-		return fmt.Errorf(
+		return errs.New(errs.ContextLength,
 			"copilot responses: estimated prompt context %d tokens exceeds the %s limit of %d for %q; compact the aria or set system.context_tier to \"long_context\"",
 			used,
 			contextTierName(options.contextTier),
@@ -452,6 +453,7 @@ func dialResponses(ctx context.Context, endpoint string, headers http.Header) (*
 	}
 	config.Header = headers
 	config.Dialer = &net.Dialer{Timeout: 30 * time.Second}
+	config.TlsConfig = provider.TLSConfig()
 	return config.DialContext(ctx)
 }
 
@@ -727,7 +729,18 @@ func escapeResponseAttr(value string) string {
 	return strings.ReplaceAll(value, "<", "&lt;")
 }
 
+// responseInstructions is the credo followed by any pins and the reply
+// language.
 func responseInstructions(snap chalkboard.Snapshot) string {
+	credo := responseCredo(snap)
+	managed := provider.ManagedText(snap)
+	if credo == "" || managed == "" {
+		return credo + managed
+	}
+	return credo + "\n\n" + managed
+}
+
+func responseCredo(snap chalkboard.Snapshot) string {
 	raw, ok := snap["system.credo"]
 	if !ok {
 		return ""
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+)
+
+// Middleware wraps a Provider with a cross-cutting concern around Send —
+// rate limiting, auditing, redaction — so providers need not each
+// implement it. Everything but Send passes through to the inner provider.
+type Middleware func(Provider) Provider
+
+// Chain wraps p in mws. The first middleware is outermost: it sees each
+// Send first and its result last.
+func Chain(p Provider, mws ...Middleware) Provider {
+	for i := len(mws) - 1; i >= 0; i-- {
+		p = mws[i](p)
+	}
+	return p
+}
+
+// SendFunc is the shape of Provider.Send.
+type SendFunc func(ctx context.Context, in SendInput, bus Bus) error
+
+// WrapSend builds a Middleware from a Send decorator: wrap receives the
+// inner Send and returns its replacement.
+func WrapSend(wrap func(next SendFunc) SendFunc) Middleware {
+	return func(p Provider) Provider {
+		return &wrapped{Provider: p, send: wrap(p.Send)}
+	}
+}
+
+// wrapped is a provider whose Send is decorated.
+type wrapped struct {
+	Provider
+	send SendFunc
+}
+
+func (w *wrapped) Send(ctx context.Context, in SendInput, bus Bus) error {
+	return w.send(ctx, in, bus)
+}
+
+// ContextLimit forwards to the inner provider; 0 (unknown) when it has no
+// limit to report, the same as not implementing ContextLimitProvider.
+func (w *wrapped) ContextLimit(model string, snapshot chalkboard.Snapshot) int {
+	if r, ok := w.Provider.(ContextLimitProvider); ok {
+		return r.ContextLimit(model, snapshot)
+	}
+	return 0
+}
+
+// PrepareCount forwards to the inner provider; errors.ErrUnsupported when
+// it cannot count, so callers use their estimate.
+func (w *wrapped) PrepareCount(in SendInput) (func(ctx context.Context) (int, error), error) {
+	if c, ok := w.Provider.(TokenCounter); ok {
+		return c.PrepareCount(in)
+	}
+	return nil, errors.ErrUnsupported
+}
+
+// RateLimit spaces Sends at least one interval apart across every
+// provider it wraps, so a fleet of arias shares one client-side budget of
+// perMinute requests. A Send waiting for its slot returns ctx's error if
+// the turn is cancelled first.
+func RateLimit(perMinute int) Middleware {
+	interval := time.Minute / time.Duration(perMinute)
+	var mu sync.Mutex
+	var next time.Time
+	reserve := func() time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if next.Before(now) {
+			next = now
+		}
+		wait := next.Sub(now)
+		next = next.Add(interval)
+		return wait
+	}
+	return WrapSend(func(send SendFunc) SendFunc {
+		return func(ctx context.Context, in SendInput, bus Bus) error {
+			if wait := reserve(); wait > 0 {
+				t := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return ctx.Err()
+				case <-t.C:
+				}
+			}
+			return send(ctx, in, bus)
+		}
+	})
+}
+
+// AuditRecord is one line of the Audit log.
+type AuditRecord struct {
+	Time       string `json:"time"`
+	Aria       string `json:"aria"`
+	Provider   string `json:"provider"`
+	Model      string `json:"model,omitempty"`
+	Tools      int    `json:"tools"`
+	MaxTokens  int    `json:"max_tokens,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Audit writes one JSON line per Send to w: which aria called which
+// provider and model, how long it took and whether it failed. Prompt and
+// answer text are not recorded; the aria's log already holds them.
+func Audit(w io.Writer) Middleware {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(p Provider) Provider {
+		name := p.Name()
+		return WrapSend(func(send SendFunc) SendFunc {
+			return func(ctx context.Context, in SendInput, bus Bus) error {
+				start := time.Now()
+				err := send(ctx, in, bus)
+				rec := AuditRecord{
+					Time:       start.UTC().Format(time.RFC3339Nano),
+					Aria:       in.AriaID,
+					Provider:   name,
+					Model:      snapshotModel(in.Snapshot),
+					Tools:      len(in.Tools),
+					MaxTokens:  in.MaxTokens,
+					DurationMS: time.Since(start).Milliseconds(),
+				}
+				if err != nil {
+					rec.Error = err.Error()
+				}
+				mu.Lock()
+				enc.Encode(rec)
+				mu.Unlock()
+				return err
+			}
+		})(p)
+	}
+}
+
+func snapshotModel(s chalkboard.Snapshot) string {
+	var m string
+	if raw, ok := s["system.model"]; ok {
+		_ = json.Unmarshal(raw, &m)
+	}
+	return m
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+)
+
+type stubProvider struct {
+	calls []string
+	err   error
+	limit int
+}
+
+func (s *stubProvider) Name() string                                 { return "stub" }
+func (s *stubProvider) Fingerprint() string                          { return "stub/v0" }
+func (s *stubProvider) Models(context.Context) ([]ModelInfo, error)  { return nil, nil }
+func (s *stubProvider) SetModel(string)                              {}
+func (s *stubProvider) ContextLimit(string, chalkboard.Snapshot) int { return s.limit }
+func (s *stubProvider) Send(_ context.Context, _ SendInput, _ Bus) error {
+	s.calls = append(s.calls, "send")
+	return s.err
+}
+
+type countingProvider struct{ *stubProvider }
+
+func (countingProvider) PrepareCount(SendInput) (func(context.Context) (int, error), error) {
+	return func(context.Context) (int, error) { return 42, nil }, nil
+}
+
+func tagging(tag string, log *[]string) Middleware {
+	return WrapSend(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, in SendInput, bus Bus) error {
+			*log = append(*log, tag)
+			return next(ctx, in, bus)
+		}
+	})
+}
+
+func TestChainOrderAndPassThrough(t *testing.T) {
+	var order []string
+	inner := &stubProvider{limit: 200_000}
+	p := Chain(inner, tagging("outer", &order), tagging("inner", &order))
+	if err := p.Send(context.Background(), SendInput{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "outer,inner" || len(inner.calls) != 1 {
+		t.Errorf("order %v, inner calls %v", order, inner.calls)
+	}
+	if p.Name() != "stub" || p.Fingerprint() != "stub/v0" {
+		t.Errorf("identity not passed through: %s %s", p.Name(), p.Fingerprint())
+	}
+	if r, ok := p.(ContextLimitProvider); !ok || r.ContextLimit("m", nil) != 200_000 {
+		t.Error("ContextLimit not forwarded through the chain")
+	}
+	if _, err := p.(TokenCounter).PrepareCount(SendInput{}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("PrepareCount on a provider that cannot count = %v, want ErrUnsupported", err)
+	}
+	counted := Chain(&countingProvider{stubProvider: inner}, tagging("outer", &order))
+	count, err := counted.(TokenCounter).PrepareCount(SendInput{})
+	if err != nil {
+		t.Fatalf("PrepareCount: %v", err)
+	}
+	if n, err := count(context.Background()); err != nil || n != 42 {
+		t.Errorf("count = %d, %v; not forwarded through the chain", n, err)
+	}
+	if Chain(inner) != Provider(inner) {
+		t.Error("an empty chain should return the provider itself")
+	}
+}
+
+func TestRateLimitSpacesSends(t *testing.T) {
+	p := Chain(&stubProvider{}, RateLimit(600)) // one per 100ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.Send(context.Background(), SendInput{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 190*time.Millisecond {
+		t.Errorf("3 sends at 600/min took %s, want >= 200ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Send(ctx, SendInput{}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting send on a cancelled turn = %v", err)
+	}
+}
+
+func TestAuditRecordsEachSend(t *testing.T) {
+	var b strings.Builder
+	boom := errors.New("boom")
+	p := Chain(&stubProvider{err: boom}, Audit(&b))
+	in := SendInput{
+		AriaID:    "ab12",
+		Snapshot:  chalkboard.Snapshot{"system.model": json.RawMessage(`"m-1"`)},
+		Tools:     []Tool{{Name: "bash"}},
+		MaxTokens: 1024,
+	}
+	if err := p.Send(context.Background(), in, nil); err != boom {
+		t.Fatalf("audit must return the inner error, got %v", err)
+	}
+	var rec AuditRecord
+	if err := json.Unmarshal([]byte(b.String()), &rec); err != nil {
+		t.Fatalf("audit line %q: %v", b.String(), err)
+	}
+	if rec.Aria != "ab12" || rec.Provider != "stub" || rec.Model != "m-1" || rec.Tools != 1 || rec.MaxTokens != 1024 || rec.Error != "boom" {
+		t.Errorf("record = %+v", rec)
+	}
+}
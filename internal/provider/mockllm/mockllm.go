@@ -0,0 +1,203 @@
+// Package mockllm is a deterministic provider for tests. Each Send plays
+// the next scripted Reply: prose streamed in chunks, tool calls streamed
+// as partial JSON and then made ready, or a failure. Nothing touches the
+// network, and the requests it was sent are kept for assertions.
+package mockllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// Reply is one scripted assistant message.
+type Reply struct {
+	Thinking  string
+	Text      string
+	ToolCalls []ToolCall
+
+	// Err fails the Send instead of producing a message.
+	Err error
+
+	// StopReason defaults to tool_invoke when there are tool calls and
+	// stop otherwise.
+	StopReason message.StopReason
+	Usage      *message.Usage
+}
+
+// ToolCall is a scripted tool_use block.
+type ToolCall struct {
+	ID   string
+	Name string
+	Args map[string]any
+}
+
+// Request is what one Send was given.
+type Request struct {
+	Messages []message.Message
+	Tools    []string
+}
+
+// MockLLM plays a script of replies, one per Send.
+type MockLLM struct {
+	// Chunk is how many runes each streamed delta carries; 0 sends the
+	// text in one delta.
+	Chunk int
+	// Delay is the pause between deltas.
+	Delay time.Duration
+
+	mu       sync.Mutex
+	script   []Reply
+	requests []Request
+}
+
+var _ provider.Provider = (*MockLLM)(nil)
+
+// New returns a MockLLM that plays script in order.
+func New(script ...Reply) *MockLLM {
+	return &MockLLM{script: script}
+}
+
+// Text is a Reply that only says text.
+func Text(text string) Reply { return Reply{Text: text} }
+
+// Call is a Reply that invokes one tool.
+func Call(id, name string, args map[string]any) Reply {
+	return Reply{ToolCalls: []ToolCall{{ID: id, Name: name, Args: args}}}
+}
+
+func (m *MockLLM) Name() string        { return "mockllm" }
+func (m *MockLLM) Fingerprint() string { return "mockllm/v1" }
+func (m *MockLLM) SetModel(string)     {}
+
+func (m *MockLLM) Models(context.Context) ([]provider.ModelInfo, error) {
+	return []provider.ModelInfo{{ID: "mock-1", Name: "Mock", Provider: "mockllm"}}, nil
+}
+
+// Queue appends replies to the script.
+func (m *MockLLM) Queue(replies ...Reply) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.script = append(m.script, replies...)
+}
+
+// Remaining is how many scripted replies have not been played.
+func (m *MockLLM) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.script)
+}
+
+// Requests returns what each Send so far was given.
+func (m *MockLLM) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Request(nil), m.requests...)
+}
+
+// Send plays the next reply. An exhausted script is an error, so a test
+// that drives more rounds than it scripted fails loudly.
+func (m *MockLLM) Send(ctx context.Context, in provider.SendInput, bus provider.Bus) error {
+	m.mu.Lock()
+	req := Request{Messages: payloads(in.FigLog)}
+	for _, t := range in.Tools {
+		req.Tools = append(req.Tools, t.Name)
+	}
+	m.requests = append(m.requests, req)
+	if len(m.script) == 0 {
+		n := len(m.requests) - 1
+		m.mu.Unlock()
+		return fmt.Errorf("mockllm: script exhausted after %d replies", n)
+	}
+	r := m.script[0]
+	m.script = m.script[1:]
+	m.mu.Unlock()
+
+	if r.Err != nil {
+		return r.Err
+	}
+	msg := message.Message{Role: message.RoleAssistant, StopReason: r.StopReason, Usage: r.Usage}
+	if r.Thinking != "" {
+		if err := m.stream(ctx, r.Thinking, func(s string) {
+			bus.PushDelta(message.Content{Type: message.ContentThinking, Text: s})
+		}); err != nil {
+			return err
+		}
+		msg.Content = append(msg.Content, message.Content{Type: message.ContentThinking, Text: r.Thinking})
+	}
+	if r.Text != "" {
+		if err := m.stream(ctx, r.Text, func(s string) { bus.PushDelta(message.TextContent(s)) }); err != nil {
+			return err
+		}
+		msg.Content = append(msg.Content, message.TextContent(r.Text))
+	}
+	for _, c := range r.ToolCalls {
+		raw, err := json.Marshal(c.Args)
+		if err != nil {
+			return fmt.Errorf("mockllm: tool %s args: %w", c.ID, err)
+		}
+		bus.PushToolInvokeStart(c.ID, c.Name)
+		if err := m.stream(ctx, string(raw), func(s string) { bus.PushToolInvokeDelta(c.ID, s) }); err != nil {
+			return err
+		}
+		invoke := message.Content{Type: message.ContentToolInvoke, ToolCallID: c.ID, ToolName: c.Name, Arguments: c.Args}
+		bus.PushToolReady(invoke)
+		msg.Content = append(msg.Content, invoke)
+	}
+	if msg.StopReason == "" {
+		msg.StopReason = message.StopEnd
+		if len(r.ToolCalls) > 0 {
+			msg.StopReason = message.StopToolInvoke
+		}
+	}
+
+	entry, err := in.FigLog.Append(store.Entry[message.Message]{Payload: msg})
+	if err != nil {
+		return fmt.Errorf("append assistant: %w", err)
+	}
+	msg.LogicalTime = entry.LT
+	bus.PushMessageEnd(string(msg.StopReason))
+	bus.PushFigaro(msg)
+	return nil
+}
+
+// stream hands text to push in Chunk-rune pieces, Delay apart.
+func (m *MockLLM) stream(ctx context.Context, text string, push func(string)) error {
+	runes := []rune(text)
+	size := m.Chunk
+	if size <= 0 {
+		size = len(runes)
+	}
+	for i := 0; i < len(runes); i += size {
+		if i > 0 && m.Delay > 0 {
+			select {
+			case <-time.After(m.Delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		push(string(runes[i:min(i+size, len(runes))]))
+	}
+	return nil
+}
+
+func payloads(log store.Log[message.Message]) []message.Message {
+	if log == nil {
+		return nil
+	}
+	entries := log.Read()
+	out := make([]message.Message, len(entries))
+	for i, e := range entries {
+		out[i] = e.Payload
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package mockllm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/provider"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+type recordingBus struct {
+	events []string
+	final  []message.Message
+}
+
+func (b *recordingBus) PushDelta(c message.Content) {
+	b.events = append(b.events, string(c.Type)+":"+c.Text)
+}
+func (b *recordingBus) PushFigaro(m message.Message, _ ...provider.AssistantCache) {
+	b.final = append(b.final, m)
+}
+func (b *recordingBus) PushToolInvokeStart(id, name string) {
+	b.events = append(b.events, "start:"+id+":"+name)
+}
+func (b *recordingBus) PushToolInvokeDelta(id, partial string) {
+	b.events = append(b.events, "args:"+partial)
+}
+func (b *recordingBus) PushToolReady(c message.Content) {
+	b.events = append(b.events, "ready:"+c.ToolCallID)
+}
+func (b *recordingBus) PushMessageEnd(stop string) {
+	b.events = append(b.events, "end:"+stop)
+}
+
+func TestSendStreamsInChunks(t *testing.T) {
+	m := New(Reply{Text: "héllo!", ToolCalls: []ToolCall{{ID: "c1", Name: "echo", Args: map[string]any{"a": 1}}}})
+	m.Chunk = 4
+	log := store.NewMemLog[message.Message]()
+	bus := &recordingBus{}
+	if err := m.Send(context.Background(), provider.SendInput{FigLog: log}, bus); err != nil {
+		t.Fatal(err)
+	}
+	want := "prose:héll|prose:o!|start:c1:echo|args:{\"a\"|args::1}|ready:c1|end:tool_invoke"
+	if got := strings.Join(bus.events, "|"); got != want {
+		t.Errorf("events:\n got %s\nwant %s", got, want)
+	}
+	if len(bus.final) != 1 || bus.final[0].LogicalTime == 0 || len(log.Read()) != 1 {
+		t.Errorf("message not appended: %+v", bus.final)
+	}
+}
+
+func TestSendPlaysScriptInOrder(t *testing.T) {
+	m := New(Text("one"))
+	m.Queue(Text("two"))
+	log := store.NewMemLog[message.Message]()
+	for _, want := range []string{"one", "two"} {
+		bus := &recordingBus{}
+		if err := m.Send(context.Background(), provider.SendInput{FigLog: log}, bus); err != nil {
+			t.Fatal(err)
+		}
+		if got := bus.final[0].Content[0].Text; got != want {
+			t.Errorf("reply = %q, want %q", got, want)
+		}
+	}
+	err := m.Send(context.Background(), provider.SendInput{FigLog: log}, &recordingBus{})
+	if err == nil || !strings.Contains(err.Error(), "exhausted after 2") {
+		t.Errorf("exhausted: %v", err)
+	}
+	reqs := m.Requests()
+	if len(reqs) != 3 || len(reqs[1].Messages) != 1 {
+		t.Errorf("requests: %+v", reqs)
+	}
+}
+
+func TestSendHonorsCancel(t *testing.T) {
+	m := New(Text("long reply"))
+	m.Chunk = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Send(ctx, provider.SendInput{FigLog: store.NewMemLog[message.Message]()}, &recordingBus{}); err != context.Canceled {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+// PinsKey is the chalkboard key holding an aria's pins.
+const PinsKey = "system.pins"
+
+// Pin is context kept in every request: a message copied out of the aria
+// (LT is set) or an outside snippet (Source says where it came from).
+type Pin struct {
+	LT     uint64 `json:"lt,omitempty"`
+	Source string `json:"source,omitempty"`
+	Text   string `json:"text"`
+}
+
+// ReadPins decodes system.pins; a missing or malformed key is no pins.
+func ReadPins(snap chalkboard.Snapshot) []Pin {
+	raw, ok := snap[PinsKey]
+	if !ok {
+		return nil
+	}
+	var pins []Pin
+	if json.Unmarshal(raw, &pins) != nil {
+		return nil
+	}
+	return pins
+}
+
+// ResolvePins drops the message pins log still replays, so a pinned
+// message is sent once while it is in the history and comes back through
+// the system prompt once it is not. Snippets always stay.
+func ResolvePins(snap chalkboard.Snapshot, log store.Log[message.Message]) chalkboard.Snapshot {
+	pins := ReadPins(snap)
+	if len(pins) == 0 {
+		return snap
+	}
+	keep := pins[:0:0]
+	for _, p := range pins {
+		if p.LT != 0 && log != nil {
+			if _, ok := log.Lookup(p.LT); ok {
+				continue
+			}
+		}
+		keep = append(keep, p)
+	}
+	if len(keep) == len(pins) {
+		return snap
+	}
+	out := maps.Clone(snap)
+	if len(keep) == 0 {
+		delete(out, PinsKey)
+		return out
+	}
+	out[PinsKey], _ = json.Marshal(keep)
+	return out
+}
+
+// PinnedText renders system.pins as a system-prompt block, or "" when
+// there are none.
+func PinnedText(snap chalkboard.Snapshot) string {
+	pins := ReadPins(snap)
+	if len(pins) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<pinned-context>\nThe user pinned these to stay in context.\n")
+	for _, p := range pins {
+		switch {
+		case p.LT != 0:
+			fmt.Fprintf(&b, "<pin lt=\"%d\">\n", p.LT)
+		case p.Source != "":
+			fmt.Fprintf(&b, "<pin source=%q>\n", p.Source)
+		default:
+			b.WriteString("<pin>\n")
+		}
+		b.WriteString(strings.TrimSpace(p.Text))
+		b.WriteString("\n</pin>\n")
+	}
+	b.WriteString("</pinned-context>")
+	return b.String()
+}
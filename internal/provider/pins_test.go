@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figaro/internal/store"
+)
+
+func TestResolvePins(t *testing.T) {
+	log := store.NewMemLog[message.Message]()
+	appendProjectionMessage(t, log, "one")
+	snap := chalkboard.Snapshot{
+		"system.credo": []byte(`"be brief"`),
+		PinsKey:        []byte(`[{"lt":1,"text":"one"},{"lt":40,"text":"old decision"},{"source":"api.md","text":"GET /v1"}]`),
+	}
+
+	got := ResolvePins(snap, log)
+	pins := ReadPins(got)
+	if len(pins) != 2 || pins[0].LT != 40 || pins[1].Source != "api.md" {
+		t.Fatalf("resolved = %+v", pins)
+	}
+	if len(ReadPins(snap)) != 3 {
+		t.Fatal("ResolvePins modified its input")
+	}
+	text := PinnedText(got)
+	for _, want := range []string{"<pinned-context>", "<pin lt=\"40\">\nold decision\n</pin>", "<pin source=\"api.md\">"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("pinned text missing %q:\n%s", want, text)
+		}
+	}
+
+	only := chalkboard.Snapshot{PinsKey: []byte(`[{"lt":1,"text":"one"}]`)}
+	if _, ok := ResolvePins(only, log)[PinsKey]; ok {
+		t.Error("a pin still in the log was kept")
+	}
+	if PinnedText(chalkboard.Snapshot{}) != "" {
+		t.Error("no pins rendered a block")
+	}
+}
@@ -112,3 +112,14 @@ type Provider interface {
 type ContextLimitProvider interface {
 	ContextLimit(model string, snapshot chalkboard.Snapshot) int
 }
+
+// TokenCounter optionally reports how many input tokens a Send of in
+// would bill, using the provider's own count. PrepareCount does the local
+// half — projecting in, which catches the translation cache up the way
+// Send would — and must run where Send does. The function it returns does
+// the network I/O and touches no shared state, so it may run on another
+// goroutine. Callers fall back to the tokens package estimate when either
+// half fails.
+type TokenCounter interface {
+	PrepareCount(in SendInput) (func(ctx context.Context) (int, error), error)
+}
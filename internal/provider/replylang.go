@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+)
+
+// ReplyLangKey is the chalkboard key naming the language an aria's answers
+// are written in. "auto" (or unset) follows the prompt.
+const ReplyLangKey = "system.reply_lang"
+
+// ReplyLangAuto turns the directive off for one aria, over a configured
+// default.
+const ReplyLangAuto = "auto"
+
+// ReplyLang reads system.reply_lang; "" when unset, malformed or auto.
+func ReplyLang(snap chalkboard.Snapshot) string {
+	var lang string
+	if raw, ok := snap[ReplyLangKey]; !ok || json.Unmarshal(raw, &lang) != nil {
+		return ""
+	}
+	if lang = strings.TrimSpace(lang); strings.EqualFold(lang, ReplyLangAuto) {
+		return ""
+	}
+	return lang
+}
+
+// ReplyLangText renders system.reply_lang as a system-prompt directive, or
+// "" when there is none.
+func ReplyLangText(snap chalkboard.Snapshot) string {
+	lang := ReplyLang(snap)
+	if lang == "" {
+		return ""
+	}
+	return fmt.Sprintf("<reply-language>\nAlways write your answers in %s, whatever language the user writes in. Leave code, commands, identifiers and quoted text as they are.\n</reply-language>", lang)
+}
+
+// ManagedText is the part of the system prompt figaro adds after the
+// credo: pins, then the reply-language directive. "" when neither is set.
+func ManagedText(snap chalkboard.Snapshot) string {
+	var parts []string
+	for _, s := range []string{PinnedText(snap), ReplyLangText(snap)} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// DefaultReplyLang returns snap with system.reply_lang set to lang when
+// snap does not set it, so a configured language applies without touching
+// the aria's chalkboard. snap itself is not modified.
+func DefaultReplyLang(snap chalkboard.Snapshot, lang string) chalkboard.Snapshot {
+	if lang == "" {
+		return snap
+	}
+	if _, ok := snap[ReplyLangKey]; ok {
+		return snap
+	}
+	raw, _ := json.Marshal(lang)
+	snap = maps.Clone(snap)
+	if snap == nil {
+		snap = chalkboard.Snapshot{}
+	}
+	snap[ReplyLangKey] = raw
+	return snap
+}
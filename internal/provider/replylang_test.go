@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+)
+
+func TestReplyLang(t *testing.T) {
+	snap := chalkboard.Snapshot{ReplyLangKey: []byte(`" French "`)}
+	if got := ReplyLang(snap); got != "French" {
+		t.Errorf("ReplyLang = %q", got)
+	}
+	if text := ReplyLangText(snap); !strings.Contains(text, "in French, whatever language") {
+		t.Errorf("directive = %q", text)
+	}
+	for _, raw := range []string{`"auto"`, `"AUTO"`, `""`, `42`} {
+		if got := ReplyLangText(chalkboard.Snapshot{ReplyLangKey: []byte(raw)}); got != "" {
+			t.Errorf("%s rendered %q", raw, got)
+		}
+	}
+
+	snap[PinsKey] = []byte(`[{"source":"a.md","text":"x"}]`)
+	managed := ManagedText(snap)
+	if p, l := strings.Index(managed, "<pinned-context>"), strings.Index(managed, "<reply-language>"); p < 0 || l < p {
+		t.Errorf("managed text order:\n%s", managed)
+	}
+	if ManagedText(chalkboard.Snapshot{}) != "" {
+		t.Error("empty snapshot rendered managed text")
+	}
+}
+
+func TestDefaultReplyLang(t *testing.T) {
+	own := chalkboard.Snapshot{"system.model": []byte(`"m"`)}
+	if got := DefaultReplyLang(own, "ja"); ReplyLang(got) != "ja" {
+		t.Errorf("default not applied: %s", got[ReplyLangKey])
+	}
+	if _, ok := own[ReplyLangKey]; ok {
+		t.Error("default modified the aria's snapshot")
+	}
+	if got := DefaultReplyLang(nil, "ja"); ReplyLang(got) != "ja" {
+		t.Errorf("default not applied to an empty snapshot: %s", got[ReplyLangKey])
+	}
+
+	set := chalkboard.Snapshot{ReplyLangKey: []byte(`"auto"`)}
+	if got := DefaultReplyLang(set, "ja"); ReplyLang(got) != "" {
+		t.Errorf("aria's own setting overridden: %s", got[ReplyLangKey])
+	}
+	if got := DefaultReplyLang(own, ""); len(got) != len(own) {
+		t.Errorf("no language configured, snapshot changed: %v", got)
+	}
+}
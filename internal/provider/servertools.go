@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// Server tools run on the provider's side within one answer: the model
+// calls them and their results come back in the same assistant message,
+// so the harness never dispatches them. Each is off until its key is set
+// on the aria (figaro set, a loadout, or send --web-search).
+const (
+	WebSearchKey        = "system.web_search"
+	WebSearchMaxUsesKey = "system.web_search_max_uses"
+	WebSearchDomainsKey = "system.web_search_domains"
+	WebFetchKey         = "system.web_fetch"
+	CodeExecutionKey    = "system.code_execution"
+)
+
+// DefaultWebSearchMaxUses caps searches per answer when
+// system.web_search_max_uses is unset.
+const DefaultWebSearchMaxUses = 5
+
+// ServerTools is the server tools an aria has switched on.
+type ServerTools struct {
+	WebSearch bool
+	// WebSearchMaxUses caps searches per answer.
+	WebSearchMaxUses int
+	// WebSearchDomains, when set, are the only domains searched.
+	WebSearchDomains []string
+	WebFetch         bool
+	CodeExecution    bool
+}
+
+// Any reports whether any server tool is on.
+func (s ServerTools) Any() bool { return s.WebSearch || s.WebFetch || s.CodeExecution }
+
+// Betas is the anthropic-beta flags the switched-on tools need on top of
+// the messages defaults; web search needs none.
+func (s ServerTools) Betas() []string {
+	var out []string
+	if s.WebFetch {
+		out = append(out, "web-fetch-2025-09-10")
+	}
+	if s.CodeExecution {
+		out = append(out, "code-execution-2025-08-25")
+	}
+	return out
+}
+
+// ServerToolsFrom reads the server-tool keys. Booleans may be JSON or the
+// strings "on"/"off"; domains a list or one comma-separated string.
+func ServerToolsFrom(snap chalkboard.Snapshot) ServerTools {
+	s := ServerTools{
+		WebSearch:     snapBool(snap, WebSearchKey),
+		WebFetch:      snapBool(snap, WebFetchKey),
+		CodeExecution: snapBool(snap, CodeExecutionKey),
+	}
+	if !s.WebSearch {
+		return s
+	}
+	s.WebSearchMaxUses = DefaultWebSearchMaxUses
+	if raw, ok := snap[WebSearchMaxUsesKey]; ok {
+		var n int
+		if json.Unmarshal(raw, &n) == nil && n > 0 {
+			s.WebSearchMaxUses = n
+		}
+	}
+	if raw, ok := snap[WebSearchDomainsKey]; ok {
+		var list []string
+		if json.Unmarshal(raw, &list) != nil {
+			var one string
+			json.Unmarshal(raw, &one)
+			list = strings.Split(one, ",")
+		}
+		for _, d := range list {
+			if d = strings.TrimSpace(d); d != "" {
+				s.WebSearchDomains = append(s.WebSearchDomains, d)
+			}
+		}
+	}
+	return s
+}
+
+// WithoutServerTools returns snap less the server-tool keys, for
+// providers whose endpoint only takes client tools.
+func WithoutServerTools(snap chalkboard.Snapshot) chalkboard.Snapshot {
+	out := make(chalkboard.Snapshot, len(snap))
+	for k, v := range snap {
+		switch k {
+		case WebSearchKey, WebSearchMaxUsesKey, WebSearchDomainsKey, WebFetchKey, CodeExecutionKey:
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func snapBool(snap chalkboard.Snapshot, key string) bool {
+	raw, ok := snap[key]
+	if !ok {
+		return false
+	}
+	var b bool
+	if json.Unmarshal(raw, &b) == nil {
+		return b
+	}
+	var s string
+	json.Unmarshal(raw, &s)
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "on", "true", "yes", "1":
+		return true
+	}
+	return false
+}
+
+// IsServerToolResult reports whether a provider block type is a server
+// tool's result ("web_search_tool_result", "bash_code_execution_tool_result",
+// ...), as opposed to the client tool_result.
+func IsServerToolResult(blockType string) bool {
+	return blockType != "tool_result" && strings.HasSuffix(blockType, "_tool_result")
+}
+
+// ServerToolResult maps a server tool's result block to the IR: the tool
+// it came from, and its content digested to text for display — search
+// hits as title and URL, code runs as their output. The block itself
+// replays from the provider's translation cache.
+func ServerToolResult(blockType, toolUseID string, content json.RawMessage) message.Content {
+	c := message.Content{
+		Type:       message.ContentServerToolResult,
+		ToolCallID: toolUseID,
+		ToolName:   strings.TrimSuffix(blockType, "_tool_result"),
+	}
+	var v any
+	if json.Unmarshal(content, &v) != nil {
+		return c
+	}
+	c.Text, c.IsError = digestServerResult(v)
+	return c
+}
+
+func digestServerResult(v any) (string, bool) {
+	switch r := v.(type) {
+	case []any:
+		var lines []string
+		for _, item := range r {
+			if m, ok := item.(map[string]any); ok {
+				if line := digestHit(m); line != "" {
+					lines = append(lines, line)
+				}
+			}
+		}
+		return strings.Join(lines, "\n"), false
+	case map[string]any:
+		if code, _ := r["error_code"].(string); code != "" {
+			return code, true
+		}
+		if _, ok := r["stdout"]; ok {
+			stdout, _ := r["stdout"].(string)
+			stderr, _ := r["stderr"].(string)
+			out := strings.TrimRight(stdout+stderr, "\n")
+			if rc, _ := r["return_code"].(float64); rc != 0 {
+				out = strings.TrimLeft(out+fmt.Sprintf("\nexit %d", int(rc)), "\n")
+				return out, true
+			}
+			return out, false
+		}
+		return digestHit(r), false
+	}
+	return "", false
+}
+
+// digestHit is one search or fetch result as "title — url".
+func digestHit(m map[string]any) string {
+	url, _ := m["url"].(string)
+	title, _ := m["title"].(string)
+	switch {
+	case title != "" && url != "":
+		return title + " — " + url
+	case url != "":
+		return url
+	}
+	return title
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/chalkboard"
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func TestServerToolsFrom(t *testing.T) {
+	if got := ServerToolsFrom(nil); got.Any() {
+		t.Errorf("unset snapshot = %+v", got)
+	}
+	got := ServerToolsFrom(chalkboard.Snapshot{
+		WebSearchKey:        []byte(`"on"`),
+		WebSearchDomainsKey: []byte(`" go.dev ,,pkg.go.dev"`),
+		CodeExecutionKey:    []byte(`false`),
+	})
+	want := ServerTools{WebSearch: true, WebSearchMaxUses: DefaultWebSearchMaxUses, WebSearchDomains: []string{"go.dev", "pkg.go.dev"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	got = ServerToolsFrom(chalkboard.Snapshot{WebSearchKey: []byte(`true`), WebSearchMaxUsesKey: []byte(`12`)})
+	if got.WebSearchMaxUses != 12 {
+		t.Errorf("max uses = %d", got.WebSearchMaxUses)
+	}
+	got = ServerToolsFrom(chalkboard.Snapshot{WebSearchKey: []byte(`"off"`), WebSearchMaxUsesKey: []byte(`12`)})
+	if got.Any() || got.WebSearchMaxUses != 0 {
+		t.Errorf("off = %+v", got)
+	}
+	snap := chalkboard.Snapshot{WebSearchKey: []byte(`true`), CodeExecutionKey: []byte(`true`), "system.model": []byte(`"m"`)}
+	if got := WithoutServerTools(snap); ServerToolsFrom(got).Any() || len(got) != 1 || len(snap) != 3 {
+		t.Errorf("WithoutServerTools = %v", got)
+	}
+}
+
+func TestServerToolResult(t *testing.T) {
+	cases := []struct {
+		blockType, content string
+		want               message.Content
+	}{
+		{
+			"web_search_tool_result",
+			`[{"type":"web_search_result","title":"Go","url":"https://go.dev","encrypted_content":"x"},{"type":"web_search_result","url":"https://pkg.go.dev"}]`,
+			message.Content{ToolName: "web_search", Text: "Go — https://go.dev\nhttps://pkg.go.dev"},
+		},
+		{
+			"web_search_tool_result",
+			`{"type":"web_search_tool_result_error","error_code":"max_uses_exceeded"}`,
+			message.Content{ToolName: "web_search", Text: "max_uses_exceeded", IsError: true},
+		},
+		{
+			"bash_code_execution_tool_result",
+			`{"type":"bash_code_execution_result","stdout":"","stderr":"boom\n","return_code":2}`,
+			message.Content{ToolName: "bash_code_execution", Text: "boom\nexit 2", IsError: true},
+		},
+		{
+			"web_fetch_tool_result",
+			`{"type":"web_fetch_result","url":"https://go.dev/doc","content":{}}`,
+			message.Content{ToolName: "web_fetch", Text: "https://go.dev/doc"},
+		},
+	}
+	for _, tc := range cases {
+		got := ServerToolResult(tc.blockType, "srvtoolu_1", json.RawMessage(tc.content))
+		tc.want.Type = message.ContentServerToolResult
+		tc.want.ToolCallID = "srvtoolu_1"
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.blockType, got, tc.want)
+		}
+	}
+	if IsServerToolResult("tool_result") || !IsServerToolResult("web_search_tool_result") {
+		t.Error("IsServerToolResult")
+	}
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+// Every provider in a process sends through one pooled transport, so the
+// daemon's arias share warm TLS connections (and HTTP/2 streams) to each
+// API host instead of handshaking per aria. ConfigureTransport applies the
+// [network] table once at startup; clients made earlier follow it, since
+// Transport hands out a handle to whichever transport is current.
+
+var (
+	transportMu sync.RWMutex
+	transport   = newTransport(http.ProxyFromEnvironment, nil)
+)
+
+// newTransport is http.DefaultTransport tuned for a few hosts and many
+// concurrent requests to each, with HTTP/2 keepalive pings so a dead
+// connection is noticed before a turn is sent on it.
+func newTransport(proxy func(*http.Request) (*url.URL, error), roots *x509.CertPool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = proxy
+	t.MaxIdleConnsPerHost = 16
+	t.IdleConnTimeout = 90 * time.Second
+	t.ForceAttemptHTTP2 = true
+	t.HTTP2 = &http.HTTP2Config{SendPingTimeout: 30 * time.Second, PingTimeout: 15 * time.Second}
+	if roots != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: roots}
+	}
+	return t
+}
+
+func current() *http.Transport {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return transport
+}
+
+// sharedTransport is the handle Transport returns.
+type sharedTransport struct{}
+
+func (sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return current().RoundTrip(req)
+}
+
+func (sharedTransport) CloseIdleConnections() { current().CloseIdleConnections() }
+
+// Transport is the shared provider transport.
+func Transport() http.RoundTripper { return sharedTransport{} }
+
+// TLSConfig is the shared transport's TLS settings (nil for the system
+// defaults), for connections that do not go through http.Transport.
+func TLSConfig() *tls.Config {
+	if c := current().TLSClientConfig; c != nil {
+		return c.Clone()
+	}
+	return nil
+}
+
+// Client is an http.Client on the shared transport.
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport()}
+}
+
+// ConfigureTransport rebuilds the shared transport from the [network]
+// table: proxy overrides HTTPS_PROXY and HTTP_PROXY, no_proxy overrides
+// NO_PROXY, ca_bundle adds a PEM file to the system roots.
+func ConfigureTransport(n config.Network) error {
+	proxy := http.ProxyFromEnvironment
+	if n.Proxy != "" || len(n.NoProxy) > 0 {
+		pc := httpproxy.FromEnvironment()
+		if n.Proxy != "" {
+			if u, err := url.Parse(n.Proxy); err != nil || u.Host == "" {
+				return fmt.Errorf("proxy %q: not a URL", n.Proxy)
+			}
+			pc.HTTPProxy, pc.HTTPSProxy = n.Proxy, n.Proxy
+		}
+		if len(n.NoProxy) > 0 {
+			pc.NoProxy = strings.Join(n.NoProxy, ",")
+		}
+		pf := pc.ProxyFunc()
+		proxy = func(req *http.Request) (*url.URL, error) { return pf(req.URL) }
+	}
+	var roots *x509.CertPool
+	if n.CABundle != "" {
+		pem, err := os.ReadFile(n.CABundle)
+		if err != nil {
+			return fmt.Errorf("ca_bundle: %w", err)
+		}
+		if roots, err = x509.SystemCertPool(); err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("ca_bundle %s: no PEM certificates", n.CABundle)
+		}
+	}
+	t := newTransport(proxy, roots)
+	transportMu.Lock()
+	old := transport
+	transport = t
+	transportMu.Unlock()
+	old.CloseIdleConnections()
+	return nil
+}
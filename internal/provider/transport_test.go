@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/config"
+)
+
+func resetTransport(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureTransport(config.Network{}) })
+}
+
+func TestConfigureTransportProxy(t *testing.T) {
+	resetTransport(t)
+	if err := ConfigureTransport(config.Network{Proxy: "http://proxy.example:3128"}); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", "https://api.anthropic.com/v1/messages", nil)
+	u, err := current().Proxy(req)
+	if err != nil || u == nil || u.Host != "proxy.example:3128" {
+		t.Fatalf("proxy = %v, %v", u, err)
+	}
+	if err := ConfigureTransport(config.Network{Proxy: "proxy.example"}); err == nil {
+		t.Fatal("a proxy without a scheme should be refused")
+	}
+}
+
+func TestConfigureTransportCABundle(t *testing.T) {
+	resetTransport(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if _, err := Client(5 * time.Second).Get(srv.URL); err == nil {
+		t.Fatal("self-signed server trusted without a bundle")
+	}
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureTransport(config.Network{CABundle: bundle}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Client(5 * time.Second).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("with bundle: %v", err)
+	}
+	resp.Body.Close()
+	if TLSConfig() == nil || TLSConfig().RootCAs == nil {
+		t.Fatal("TLSConfig should carry the bundle's roots")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a cert\n"), 0o600)
+	if err := ConfigureTransport(config.Network{CABundle: empty}); err == nil {
+		t.Fatal("a bundle without certificates should be refused")
+	}
+}
+
+func TestConfigureTransportNoProxy(t *testing.T) {
+	resetTransport(t)
+	err := ConfigureTransport(config.Network{Proxy: "http://proxy.example:3128", NoProxy: []string{".corp", "10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for target, proxied := range map[string]bool{
+		"https://api.anthropic.com/v1/messages": true,
+		"https://gateway.corp/v1/messages":      false,
+		"https://10.1.2.3/v1/messages":          false,
+	} {
+		req, _ := http.NewRequest("GET", target, nil)
+		u, err := current().Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (u != nil) != proxied {
+			t.Errorf("%s: proxy = %v, want proxied %v", target, u, proxied)
+		}
+	}
+}
+
+func TestClientFollowsLaterConfiguration(t *testing.T) {
+	resetTransport(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := Client(5 * time.Second) // made before [network] is applied
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureTransport(config.Network{CABundle: bundle}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("earlier client did not pick up the bundle: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientsSharePool(t *testing.T) {
+	resetTransport(t)
+	if Client(time.Minute).Transport != Client(time.Second).Transport {
+		t.Fatal("clients should share one transport")
+	}
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	glamourstyles "github.com/charmbracelet/glamour/styles"
+)
+
+// codeStyle is the chroma style for fenced code; empty keeps the markdown
+// style's own code colors.
+var codeStyle string
+
+// SetCodeStyle picks the chroma style ("monokai", "github", "dracula", ...)
+// for fenced code blocks, overriding the markdown style's palette. Call it
+// once at startup, like SetStyle; empty restores the default.
+func SetCodeStyle(s string) error {
+	if s != "" {
+		if _, ok := chromastyles.Registry[strings.ToLower(s)]; !ok {
+			return fmt.Errorf("code style %q: not a chroma style", s)
+		}
+		s = strings.ToLower(s)
+	}
+	rendererMu.Lock()
+	defer rendererMu.Unlock()
+	codeStyle = s
+	rendererCache = map[int]*glamour.TermRenderer{}
+	blockMu.Lock()
+	blockCache = map[blockKey][]string{}
+	blockMu.Unlock()
+	return nil
+}
+
+// styleConfig loads the markdown style with codeStyle swapped in for its
+// code block palette. Caller holds rendererMu.
+func styleConfig() (ansi.StyleConfig, error) {
+	cfg, err := loadStyle(style)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.CodeBlock.Theme = codeStyle
+	cfg.CodeBlock.Chroma = nil
+	return cfg, nil
+}
+
+// loadStyle resolves a markdown style: a name in glamour's style table, or
+// else a path to a glamour JSON style file.
+func loadStyle(name string) (ansi.StyleConfig, error) {
+	if std, ok := glamourstyles.DefaultStyles[name]; ok {
+		return *std, nil
+	}
+	var cfg ansi.StyleConfig
+	b, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return cfg, errors.New("no such style or style file")
+	}
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, json.Unmarshal(b, &cfg)
+}
+
+// labelFences gives each closed, unlabeled fence in md a guessed language
+// so it is highlighted. A fence still streaming is left alone: a guess made
+// on half the code could flip colors as the rest arrives.
+func labelFences(md string) string {
+	if !strings.Contains(md, "```") {
+		return md
+	}
+	lines := strings.Split(md, "\n")
+	open := -1
+	for i, l := range lines {
+		t := strings.TrimLeft(l, " ")
+		if !strings.HasPrefix(t, "```") {
+			continue
+		}
+		if open < 0 {
+			open = i
+			continue
+		}
+		if strings.TrimSpace(lines[open]) == "```" {
+			if lang := guessLanguage(strings.Join(lines[open+1:i], "\n")); lang != "" {
+				lines[open] += lang
+			}
+		}
+		open = -1
+	}
+	return strings.Join(lines, "\n")
+}
+
+// guessLanguage names the language of an unlabeled code snippet, or "".
+// Cheap telltales cover what models most often leave unlabeled; chroma's
+// analysers get the rest.
+func guessLanguage(code string) string {
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return ""
+	}
+	first := strings.SplitN(trimmed, "\n", 2)[0]
+	switch {
+	case strings.HasPrefix(first, "#!"):
+		switch {
+		case strings.Contains(first, "python"):
+			return "python"
+		case strings.Contains(first, "node"):
+			return "javascript"
+		case strings.Contains(first, "sh"):
+			return "bash"
+		}
+	case strings.HasPrefix(first, "$ "):
+		return "console"
+	case strings.HasPrefix(first, "diff --git"), strings.HasPrefix(first, "--- "), strings.HasPrefix(first, "@@ "):
+		return "diff"
+	case (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)):
+		return "json"
+	case strings.HasPrefix(first, "package "), strings.Contains(code, "\nfunc "), strings.HasPrefix(first, "func "):
+		return "go"
+	case strings.HasPrefix(first, "def "), strings.HasPrefix(first, "from "), strings.HasPrefix(first, "import ") && !strings.ContainsAny(first, "\"';{"):
+		return "python"
+	case strings.HasPrefix(first, "fn "), strings.HasPrefix(first, "use "), strings.Contains(code, "let mut "):
+		return "rust"
+	}
+	if l := lexers.Analyse(code); l != nil {
+		return strings.ToLower(l.Config().Name)
+	}
+	return ""
+}
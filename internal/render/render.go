@@ -1,15 +1,19 @@
 // Package render turns a markdown string into ANSI terminal rows via
 // glamour (tables, code blocks, syntax highlighting). It is a pure,
 // deterministic function of (markdown, width): identical inputs yield
-// identical rows, no retained state, no I/O. The CLI consumer holds the
-// rows and line-diffs them; the web consumer ignores this package.
+// identical rows, no I/O; the only retained state is a memo of rendered
+// blocks. The CLI consumer holds the rows and line-diffs them; the web
+// consumer ignores this package.
 package render
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/charmbracelet/glamour"
+	glamourstyles "github.com/charmbracelet/glamour/styles"
 	"github.com/mattn/go-runewidth"
 	"github.com/muesli/termenv"
 
@@ -20,20 +24,212 @@ import (
 var SpinnerFrames = livedoc.SpinnerFrames
 
 // Prose renders a full markdown string through glamour — prose, lists,
-// tables, and fenced code blocks all get glamour's styling (indent,
+// and fenced code blocks all get glamour's styling (indent,
 // surrounding blank lines, chroma syntax highlighting). A trailing
 // unclosed fence (mid-stream) is synth-closed so a code block renders with
-// a stable structure as it streams in.
+// a stable structure as it streams in. Tables are laid out by renderTable,
+// which wraps cells rather than truncating them.
+//
+// The text is rendered block by block (see splitBlocks) and each closed
+// block's rows are memoized, so a message growing a delta at a time
+// re-renders only its last, still-open block instead of the whole document
+// per frame. The last block is never memoized: mid-stream it changes every
+// frame, and caching each version would fill the memo with ever-longer
+// copies of it. A document with a reference link definition ("[x]: url")
+// is rendered whole, since a block holding "[text][x]" alone would show
+// the brackets literally.
 //
 // Every returned row is run through SanitizeForTerminal so embedded
 // terminal-state escapes (alt-screen, cursor visibility, line wrap,
 // mouse modes, OSC) from tool output or model-emitted text can never
 // reach the host terminal.
 func Prose(md string, width int) []string {
-	if strings.Count(md, "```")%2 == 1 {
-		md += "\n```"
+	if linkDefRE.MatchString(md) {
+		return proseBlock(md, width, false)
+	}
+	var rows []string
+	blocks := splitBlocks(md)
+	for i, block := range blocks {
+		r := proseBlock(block, width, i < len(blocks)-1)
+		if len(r) == 0 {
+			continue
+		}
+		if len(rows) > 0 {
+			rows = append(rows, "")
+		}
+		rows = append(rows, r...)
+	}
+	return rows
+}
+
+// proseBlock renders one top-level block, memoized by (block, width) when
+// closed.
+func proseBlock(block string, width int, closed bool) []string {
+	block = labelFences(block)
+	if fence := openFence(block); fence != "" {
+		block += "\n" + fence
+	}
+	key := blockKey{text: block, width: width}
+	if closed {
+		blockMu.Lock()
+		rows, ok := blockCache[key]
+		blockMu.Unlock()
+		if ok {
+			return rows
+		}
+	}
+	var rows []string
+	if t, ok := parseTable(block); ok {
+		rows = SanitizeRows(renderTable(t, width))
+	} else {
+		rows = trimBlankRows(SanitizeRows(renderMarkdown(block, width)))
+	}
+	if !closed {
+		return rows
+	}
+	blockMu.Lock()
+	if len(blockCache) >= blockCacheLimit {
+		blockCache = map[blockKey][]string{}
+	}
+	blockCache[key] = rows
+	blockMu.Unlock()
+	return rows
+}
+
+type blockKey struct {
+	text  string
+	width int
+}
+
+// blockCacheLimit bounds the block memo; past it the memo starts over,
+// which costs one re-render per block on screen.
+const blockCacheLimit = 1024
+
+var (
+	blockMu    sync.Mutex
+	blockCache = map[blockKey][]string{}
+)
+
+// linkDefRE finds a reference link definition line.
+var linkDefRE = regexp.MustCompile(`(?m)^ {0,3}\[[^\]\n]+\]:[ \t]*\S`)
+
+// fenceMarker is the fence a line opens or closes ("```" or "~~~"), or "".
+func fenceMarker(l string) string {
+	t := strings.TrimLeft(l, " ")
+	switch {
+	case strings.HasPrefix(t, "```"):
+		return "```"
+	case strings.HasPrefix(t, "~~~"):
+		return "~~~"
+	}
+	return ""
+}
+
+// openFence is the marker of a fence md leaves open, or "". A fence only
+// closes on its own marker, so ``` inside a ~~~ block is content.
+func openFence(md string) string {
+	open := ""
+	for _, l := range strings.Split(md, "\n") {
+		switch m := fenceMarker(l); {
+		case m == "":
+		case open == "":
+			open = m
+		case m == open:
+			open = ""
+		}
 	}
-	return SanitizeRows(renderMarkdown(md, width))
+	return open
+}
+
+// splitBlocks cuts markdown at blank lines into blocks glamour renders the
+// same alone as in place. It never cuts inside a fence, before an indented
+// line (a list item's continuation or indented code), or between two list
+// items (so a loose list stays one list with one numbering).
+func splitBlocks(md string) []string {
+	lines := strings.Split(md, "\n")
+	var blocks []string
+	var cur []string
+	fence, blank := "", false
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, strings.Join(cur, "\n"))
+			cur = nil
+		}
+	}
+	lastItem := func() bool {
+		for i := len(cur) - 1; i >= 0; i-- {
+			if strings.TrimSpace(cur[i]) != "" {
+				return listItem(cur[i]) || strings.HasPrefix(cur[i], " ") || strings.HasPrefix(cur[i], "\t")
+			}
+		}
+		return false
+	}
+	for _, l := range lines {
+		if fence == "" {
+			if strings.TrimSpace(l) == "" {
+				blank = true
+				cur = append(cur, l)
+				continue
+			}
+			indented := strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")
+			if blank && !indented && !(listItem(l) && lastItem()) {
+				flush()
+			}
+			blank = false
+		}
+		switch m := fenceMarker(l); {
+		case m == "":
+		case fence == "":
+			fence = m
+		case m == fence:
+			fence = ""
+		}
+		cur = append(cur, l)
+	}
+	flush()
+	return blocks
+}
+
+// listItem reports whether a line opens a list item ("- ", "* ", "+ ",
+// "1. ", "1) ").
+func listItem(l string) bool {
+	switch {
+	case strings.HasPrefix(l, "- "), strings.HasPrefix(l, "* "), strings.HasPrefix(l, "+ "):
+		return true
+	}
+	i := 0
+	for i < len(l) && l[i] >= '0' && l[i] <= '9' {
+		i++
+	}
+	return i > 0 && i+1 < len(l) && (l[i] == '.' || l[i] == ')') && l[i+1] == ' '
+}
+
+// trimBlankRows drops rows at either edge that show nothing once SGR codes
+// are set aside — glamour pads block edges with styled spaces.
+func trimBlankRows(rows []string) []string {
+	for len(rows) > 0 && blankRow(rows[0]) {
+		rows = rows[1:]
+	}
+	for len(rows) > 0 && blankRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+func blankRow(r string) bool {
+	for i := 0; i < len(r); i++ {
+		switch {
+		case r[i] == 0x1b && i+1 < len(r) && r[i+1] == '[':
+			j := i + 2
+			for j < len(r) && isCSIParamByte(r[j]) {
+				j++
+			}
+			i = j // the final byte
+		case r[i] != ' ' && r[i] != '\t':
+			return false
+		}
+	}
+	return true
 }
 
 // renderMarkdown renders markdown via glamour. Output rows are glamour's
@@ -61,15 +257,70 @@ func renderMarkdown(text string, width int) []string {
 var (
 	rendererMu    sync.Mutex
 	rendererCache = map[int]*glamour.TermRenderer{}
+	style         = "dark"
+	profile       = termenv.TrueColor
 )
 
+// SetColorDepth matches rendered colors to the terminal: 24 bits (the
+// default) keeps truecolor, 8 maps to the 256-color palette. Call it once
+// at startup, like SetStyle.
+func SetColorDepth(bits int) {
+	p := termenv.TrueColor
+	if bits < 24 {
+		p = termenv.ANSI256
+	}
+	rendererMu.Lock()
+	defer rendererMu.Unlock()
+	profile = p
+	rendererCache = map[int]*glamour.TermRenderer{}
+	blockMu.Lock()
+	blockCache = map[blockKey][]string{}
+	blockMu.Unlock()
+}
+
+// SetStyle picks the glamour style: a standard name ("dark", "light",
+// "notty", "dracula", ...) or a path to a glamour JSON style file. Call it
+// once at startup, before anything renders; empty keeps "dark". "ascii"
+// also draws tables without box-drawing characters or bold.
+func SetStyle(s string) error {
+	if s == "" {
+		s = "dark"
+	}
+	if s != glamourstyles.AutoStyle {
+		if _, err := loadStyle(s); err != nil {
+			return fmt.Errorf("markdown style %q: %w", s, err)
+		}
+	}
+	rendererMu.Lock()
+	defer rendererMu.Unlock()
+	style = s
+	if s == "ascii" {
+		tableSep, tableRule, tableCross, tableBold = " | ", "-", "-+-", ""
+	} else {
+		tableSep, tableRule, tableCross, tableBold = " │ ", "─", "─┼─", "\x1b[1m"
+	}
+	rendererCache = map[int]*glamour.TermRenderer{}
+	blockMu.Lock()
+	blockCache = map[blockKey][]string{}
+	blockMu.Unlock()
+	return nil
+}
+
 func rendererFor(width int) *glamour.TermRenderer {
 	rendererMu.Lock()
 	defer rendererMu.Unlock()
 	if r, ok := rendererCache[width]; ok {
 		return r
 	}
-	// The dark style adds a 2-column document margin on top of the wrap
+	r := newRenderer(width)
+	rendererCache[width] = r
+	return r
+}
+
+// newRenderer builds a glamour renderer for width from the current
+// settings. Caller holds rendererMu.
+func newRenderer(width int) *glamour.TermRenderer {
+	// The standard styles add a 2-column document margin on top of the wrap
 	// width, so glamour emits rows up to width+2 wide. Wrap to width-2 so
 	// rendered rows fit within width — a row that overflows the viewport
 	// auto-wraps in the terminal and desyncs the live painter's
@@ -78,19 +329,44 @@ func rendererFor(width int) *glamour.TermRenderer {
 	if wrap < 1 {
 		wrap = 1
 	}
+	styleOpt := glamour.WithStylePath(style)
+	if codeStyle != "" {
+		if cfg, err := styleConfig(); err == nil {
+			styleOpt = glamour.WithStyles(cfg)
+		}
+	}
 	r, err := glamour.NewTermRenderer(
-		glamour.WithStandardStyle("dark"),
-		glamour.WithColorProfile(termenv.TrueColor), // pinned: determinism, not env-detected
+		styleOpt,
+		glamour.WithColorProfile(profile), // pinned: determinism, not env-detected
 		glamour.WithWordWrap(wrap),
 	)
 	if err != nil {
 		// Width-only fallback; should not happen with a standard style.
 		r, _ = glamour.NewTermRenderer(glamour.WithWordWrap(wrap))
 	}
-	rendererCache[width] = r
 	return r
 }
 
+// prewarmDoc touches what a first reply usually needs: the markdown
+// parser, the style, and a chroma lexer for the fence.
+const prewarmDoc = "# a\n\nsome **bold** `code` and a [link](x)\n\n- one\n- two\n\n```go\nfunc main() {}\n```\n"
+
+// Prewarm builds and caches the renderer for width in the background and
+// renders a small document on a private copy (a TermRenderer keeps
+// per-render state, so the cached one must not render concurrently), so
+// goldmark and chroma's lazy setup overlaps the daemon round-trips instead
+// of delaying the first streamed row. Call it after SetStyle /
+// SetColorDepth.
+func Prewarm(width int) {
+	go func() {
+		rendererFor(width)
+		rendererMu.Lock()
+		r := newRenderer(width)
+		rendererMu.Unlock()
+		_, _ = r.Render(prewarmDoc)
+	}()
+}
+
 // wrapPlain hard-wraps a plain (no-ANSI) line to width display columns.
 func wrapPlain(line string, width int) []string {
 	if width <= 0 || runewidth.StringWidth(line) <= width {
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchAnswer is a long model answer: headings, prose, lists, a table and
+// fenced code, the mix Prose sees most.
+func benchAnswer(sections int) string {
+	var b strings.Builder
+	for i := 0; i < sections; i++ {
+		fmt.Fprintf(&b, "## Step %d\n\n", i)
+		b.WriteString("The handler reads the **config** once, then `Load` walks each loadout and ")
+		b.WriteString("merges its patch. See [the docs](https://example.com/docs) for the order.\n\n")
+		b.WriteString("- parse the file\n- validate the keys\n- apply the defaults\n\n")
+		b.WriteString("| key | default | note |\n|---|---|---|\n| model | sonnet | per loadout |\n| max_tokens | 8192 | capped by [limits] |\n\n")
+		fmt.Fprintf(&b, "```go\nfunc step%d(cfg *Config) error {\n\tif cfg == nil {\n\t\treturn errNoConfig\n\t}\n\treturn cfg.Apply(%d)\n}\n```\n\n", i, i)
+	}
+	return b.String()
+}
+
+func resetBlockCache() {
+	blockMu.Lock()
+	blockCache = map[blockKey][]string{}
+	blockMu.Unlock()
+}
+
+// BenchmarkMarkdownRender measures Prose throughput. cold renders every
+// block from scratch (a resize, a first paint); stream grows the answer a
+// delta at a time as it arrives, where only the open block should re-render.
+func BenchmarkMarkdownRender(b *testing.B) {
+	doc := benchAnswer(8)
+	rendererFor(100)
+	b.Run("cold", func(b *testing.B) {
+		b.SetBytes(int64(len(doc)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetBlockCache()
+			if len(Prose(doc, 100)) == 0 {
+				b.Fatal("no rows")
+			}
+		}
+	})
+	b.Run("stream", func(b *testing.B) {
+		const delta = 64
+		b.SetBytes(int64(len(doc)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetBlockCache()
+			for end := delta; end < len(doc)+delta; end += delta {
+				Prose(doc[:min(end, len(doc))], 100)
+			}
+		}
+	})
+}
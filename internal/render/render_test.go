@@ -1,9 +1,14 @@
 package render
 
 import (
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
 )
 
 var ansiRE = regexp.MustCompile(`\x1b\[[0-9;]*m`)
@@ -75,3 +80,248 @@ func TestProse_UnclosedFenceSynthClosed(t *testing.T) {
 		t.Fatalf("streaming (unclosed) code fence should still render its content:\n%s", visible(rows))
 	}
 }
+
+func TestSetStyle(t *testing.T) {
+	defer SetStyle("")
+	md := "# Title\n\nSome *prose*."
+	dark := strings.Join(Prose(md, 60), "\n")
+	if err := SetStyle("notty"); err != nil {
+		t.Fatalf("SetStyle(notty): %v", err)
+	}
+	if plain := strings.Join(Prose(md, 60), "\n"); plain == dark || ansiRE.MatchString(plain) {
+		t.Fatalf("notty style still colored or unchanged:\n%q", plain)
+	}
+	if err := SetStyle(t.TempDir() + "/missing.json"); err == nil {
+		t.Fatal("a missing style file must be rejected")
+	}
+	if err := SetStyle("mauve"); err == nil {
+		t.Fatal("a name not in the style table must be rejected")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetStyle(bad); err == nil {
+		t.Fatal("a malformed style file must be rejected")
+	}
+}
+
+func TestSetStyleASCII(t *testing.T) {
+	defer SetStyle("")
+	if err := SetStyle("ascii"); err != nil {
+		t.Fatal(err)
+	}
+	md := "# Title\n\n| a | b |\n| --- | --- |\n| 1 | 2 |\n\n```go\nfunc main() {}\n```"
+	out := strings.Join(Prose(md, 60), "\n")
+	if ansiRE.MatchString(out) || strings.ContainsAny(out, "│─┼") {
+		t.Fatalf("ascii style kept color or box drawing:\n%q", out)
+	}
+	if !strings.Contains(out, "a | b") || !strings.Contains(out, "--+--") {
+		t.Fatalf("ascii table separators missing:\n%s", out)
+	}
+}
+
+func TestSetColorDepth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(path, []byte(`{"heading":{"color":"#ff8800"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetStyle(path); err != nil {
+		t.Fatal(err)
+	}
+	defer SetStyle("")
+	defer SetColorDepth(24)
+	md := "# Title"
+	if out := strings.Join(Prose(md, 60), "\n"); !strings.Contains(out, "38;2;") {
+		t.Fatalf("hex heading without truecolor escape:\n%q", out)
+	}
+	SetColorDepth(8)
+	out := strings.Join(Prose(md, 60), "\n")
+	if strings.Contains(out, "38;2;") || !strings.Contains(out, "38;5;") {
+		t.Fatalf("256-color output kept truecolor escapes:\n%q", out)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	md := "# Title\n\nSome prose:\n\n- a\n\n- b\n\n```go\nx := 1\n\ny := 2\n```\n\n- item\n\n  continued\n\nlast"
+	got := splitBlocks(md)
+	want := []string{
+		"# Title\n",
+		"Some prose:\n",
+		"- a\n\n- b\n",
+		"```go\nx := 1\n\ny := 2\n```\n",
+		"- item\n\n  continued\n",
+		"last",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("splitBlocks =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// Block-wise rendering must read the same as rendering the whole document.
+// (Tables are left out: renderTable lays them out, not glamour.)
+func TestProse_BlocksMatchWholeDocument(t *testing.T) {
+	md := "# Title\n\nSome *prose* and a list:\n\n- a\n- b\n\n1. one\n\n2. two\n\n```go\nx := 1\n```\n\n> quote\n\nlast para"
+	squeeze := func(rows []string) string {
+		var out []string
+		for _, r := range strings.Split(visible(rows), "\n") {
+			out = append(out, strings.TrimRight(r, " "))
+		}
+		return strings.Join(out, "\n")
+	}
+	whole := squeeze(SanitizeRows(renderMarkdown(md, 60)))
+	if got := squeeze(Prose(md, 60)); got != whole {
+		t.Fatalf("block render differs from whole render:\n%s\n---\n%s", got, whole)
+	}
+}
+
+func TestSplitBlocksTildeFence(t *testing.T) {
+	got := splitBlocks("~~~\na\n\n```\n\nb\n~~~\n\nafter")
+	want := []string{"~~~\na\n\n```\n\nb\n~~~\n", "after"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("splitBlocks =\n%q\nwant\n%q", got, want)
+	}
+	if f := openFence("~~~\nx\n```"); f != "~~~" {
+		t.Errorf("openFence = %q, want ~~~", f)
+	}
+}
+
+func TestProse_ReferenceLinks(t *testing.T) {
+	out := visible(Prose("See [the docs][d].\n\nMore prose.\n\n[d]: https://example.com/docs", 80))
+	if strings.Contains(out, "[d]") || !strings.Contains(out, "example.com/docs") {
+		t.Fatalf("reference link rendered literally:\n%s", out)
+	}
+}
+
+func TestProse_MemoizesClosedBlocks(t *testing.T) {
+	blockMu.Lock()
+	blockCache = map[blockKey][]string{}
+	blockMu.Unlock()
+	Prose("first paragraph\n\nsecond, still stream", 60)
+	Prose("first paragraph\n\nsecond, still streaming", 60)
+	blockMu.Lock()
+	n := len(blockCache)
+	blockMu.Unlock()
+	if n != 1 {
+		t.Fatalf("want only the closed block memoized, not the open tail: got %d entries", n)
+	}
+}
+
+func TestGuessLanguage(t *testing.T) {
+	cases := map[string]string{
+		"#!/usr/bin/env python3\nprint(1)":   "python",
+		"#!/bin/bash\necho hi":               "bash",
+		"$ go test ./...":                    "console",
+		`{"a": [1, 2]}`:                      "json",
+		"package main\n\nfunc main() {}":     "go",
+		"def f(x):\n    return x":            "python",
+		"fn main() {\n    let mut x = 1;\n}": "rust",
+		"diff --git a/x b/x":                 "diff",
+		"just some words":                    "",
+	}
+	for code, want := range cases {
+		if got := guessLanguage(code); got != want {
+			t.Errorf("guessLanguage(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestLabelFencesOnlyClosedUnlabeled(t *testing.T) {
+	md := "```\n{\"a\": 1}\n```\n\n```sh\nls\n```\n\n```\npackage main"
+	want := "```json\n{\"a\": 1}\n```\n\n```sh\nls\n```\n\n```\npackage main"
+	if got := labelFences(md); got != want {
+		t.Fatalf("labelFences =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSetCodeStyle(t *testing.T) {
+	defer SetCodeStyle("")
+	md := "```go\nfunc main() {}\n```"
+	def := strings.Join(Prose(md, 60), "\n")
+	if err := SetCodeStyle("Monokai"); err != nil {
+		t.Fatalf("SetCodeStyle(Monokai): %v", err)
+	}
+	if got := strings.Join(Prose(md, 60), "\n"); got == def || visible(strings.Split(got, "\n")) != visible(strings.Split(def, "\n")) {
+		t.Fatal("a code style must recolor code without changing its text")
+	}
+	if err := SetCodeStyle("no-such-style"); err == nil {
+		t.Fatal("an unknown chroma style must be rejected")
+	}
+}
+
+func TestProse_TableWrapsCellsToWidth(t *testing.T) {
+	md := "| name | description |\n| --- | ---: |\n| build | compiles every package and reports a fairly long description of what failed |\n| vet | 1 |\n"
+	rows := Prose(md, 40)
+	for _, r := range rows {
+		if w := runewidth.StringWidth(stripANSI(r)); w > 40 {
+			t.Fatalf("row wider than 40 (%d): %q", w, stripANSI(r))
+		}
+	}
+	out := visible(rows)
+	for _, want := range []string{"name", "build", "fairly long", "what failed", "vet"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("cell text %q lost; got:\n%s", want, out)
+		}
+	}
+	// The right-aligned column puts "1" at the row's end.
+	for _, r := range rows {
+		if s := stripANSI(r); strings.Contains(s, "vet") && !strings.HasSuffix(s, " 1") {
+			t.Fatalf("right-aligned cell not at the edge: %q", s)
+		}
+	}
+}
+
+func TestColumnWidthsKeepNarrowColumns(t *testing.T) {
+	tb, ok := parseTable("| a | b | c |\n|---|---|---|\n| x | " + strings.Repeat("long ", 20) + "| " + strings.Repeat("more ", 20) + "|")
+	if !ok {
+		t.Fatal("table not parsed")
+	}
+	w, ok := columnWidths(tb, 50)
+	if !ok || w[0] != 1 {
+		t.Fatalf("narrow column squeezed: %v", w)
+	}
+	if sum := w[0] + w[1] + w[2] + 2*3 + 2; sum != 50 {
+		t.Fatalf("widths %v fill %d columns, want 50", w, sum)
+	}
+}
+
+func TestProse_ManyColumnsStayInWidth(t *testing.T) {
+	md := "| " + strings.Repeat("column | ", 12) + "\n|" + strings.Repeat(" --- |", 12) + "\n| " + strings.Repeat("value | ", 12)
+	rows := Prose(md, 40)
+	for _, r := range rows {
+		if w := runewidth.StringWidth(stripANSI(r)); w > 40 {
+			t.Fatalf("row wider than 40 (%d): %q", w, stripANSI(r))
+		}
+	}
+	if !strings.Contains(visible(rows), "value") {
+		t.Fatalf("cell text lost:\n%s", visible(rows))
+	}
+}
+
+func TestParseTableNeedsDelimiterRow(t *testing.T) {
+	if _, ok := parseTable("| a | b |\n| c | d |"); ok {
+		t.Fatal("rows without a delimiter row parsed as a table")
+	}
+	if _, ok := parseTable("| a | b |\n| --- | --- |\nplain text"); ok {
+		t.Fatal("a block with a non-table line parsed as a table")
+	}
+	tb, ok := parseTable(`| a \| b | c |` + "\n| :-: | - |\n| 1 |")
+	if !ok || tb.header[0] != "a | b" || tb.align[0] != alignCenter || len(tb.rows[0]) != 2 {
+		t.Fatalf("got %+v ok=%v", tb, ok)
+	}
+}
+
+func TestPrewarmCachesRenderer(t *testing.T) {
+	const width = 77
+	Prewarm(width)
+	for i := 0; i < 200; i++ {
+		rendererMu.Lock()
+		_, ok := rendererCache[width]
+		rendererMu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Prewarm did not cache a renderer for its width")
+}
@@ -0,0 +1,301 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Tables are laid out here rather than by glamour, which fits a wide table
+// to the terminal by truncating its cells. Columns that fit keep their
+// natural width; the rest share what is left evenly and wrap their text
+// across rows, so no cell loses content.
+
+// tableAlign is a column's alignment from the delimiter row.
+type tableAlign int
+
+const (
+	alignLeft tableAlign = iota
+	alignRight
+	alignCenter
+)
+
+// table is a parsed pipe table: a header row, body rows, and one alignment
+// per column. Every row has exactly len(align) cells.
+type table struct {
+	header []string
+	rows   [][]string
+	align  []tableAlign
+}
+
+const (
+	tableMargin = "  " // matches glamour's document margin
+	tableMinCol = 3    // a squeezed column keeps at least this many columns
+)
+
+// Cell separator, header rule and their crossing. The "ascii" style swaps
+// in plain characters (see SetStyle).
+var (
+	tableSep   = " │ "
+	tableRule  = "─"
+	tableCross = "─┼─"
+	tableBold  = "\x1b[1m"
+)
+
+// parseTable reads block as a pipe table: a header row, a delimiter row, and
+// body rows, every line carrying a '|'. ok is false for anything else,
+// including a table still streaming its header (no delimiter row yet).
+func parseTable(block string) (table, bool) {
+	lines := strings.Split(strings.TrimRight(block, "\n"), "\n")
+	if len(lines) < 2 {
+		return table{}, false
+	}
+	for _, l := range lines {
+		if !strings.Contains(l, "|") {
+			return table{}, false
+		}
+	}
+	align, ok := parseDelimiterRow(lines[1])
+	if !ok {
+		return table{}, false
+	}
+	header := splitCells(lines[0])
+	if len(header) != len(align) {
+		return table{}, false
+	}
+	t := table{header: fitCells(header, len(align)), align: align}
+	for _, l := range lines[2:] {
+		t.rows = append(t.rows, fitCells(splitCells(l), len(align)))
+	}
+	return t, true
+}
+
+// parseDelimiterRow reads "| --- | :-: | --: |" into column alignments.
+func parseDelimiterRow(l string) ([]tableAlign, bool) {
+	cells := splitCells(l)
+	if len(cells) == 0 {
+		return nil, false
+	}
+	align := make([]tableAlign, len(cells))
+	for i, c := range cells {
+		left, right := strings.HasPrefix(c, ":"), strings.HasSuffix(c, ":")
+		dashes := strings.Trim(c, ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil, false
+		}
+		switch {
+		case left && right:
+			align[i] = alignCenter
+		case right:
+			align[i] = alignRight
+		}
+	}
+	return align, true
+}
+
+// splitCells splits a table row on unescaped pipes, dropping the optional
+// outer ones, and trims each cell.
+func splitCells(l string) []string {
+	l = strings.TrimSpace(l)
+	l = strings.TrimPrefix(l, "|")
+	if strings.HasSuffix(l, "|") && !strings.HasSuffix(l, `\|`) {
+		l = l[:len(l)-1]
+	}
+	var cells []string
+	var b strings.Builder
+	for i := 0; i < len(l); i++ {
+		switch {
+		case l[i] == '\\' && i+1 < len(l) && l[i+1] == '|':
+			b.WriteByte('|')
+			i++
+		case l[i] == '|':
+			cells = append(cells, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(l[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(b.String()))
+}
+
+// fitCells pads or cuts a row to n cells, as GFM does for ragged rows.
+func fitCells(cells []string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		if i < len(cells) {
+			out[i] = plainCell(cells[i])
+		}
+	}
+	return out
+}
+
+// plainCell drops inline emphasis and code markers, which would otherwise
+// show literally and throw off the column widths.
+func plainCell(s string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(s)
+}
+
+// renderTable lays t out within width display columns. A table with too
+// many columns to give each tableMinCol falls back to plainTable.
+func renderTable(t table, width int) []string {
+	widths, ok := columnWidths(t, width)
+	if !ok {
+		return plainTable(t, width)
+	}
+	var rows []string
+	rows = append(rows, tableRows(t.header, widths, t.align, tableBold)...)
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat(tableRule, w)
+	}
+	rows = append(rows, tableMargin+strings.Join(rule, tableCross))
+	for _, r := range t.rows {
+		rows = append(rows, tableRows(r, widths, t.align, "")...)
+	}
+	return rows
+}
+
+// plainTable prints each row as its cells joined by the separator, wrapped
+// to width like prose: no columns, but nothing past the edge.
+func plainTable(t table, width int) []string {
+	var rows []string
+	for _, r := range append([][]string{t.header}, t.rows...) {
+		for _, l := range wordWrap(strings.Join(r, tableSep), max(width-len(tableMargin), 1)) {
+			rows = append(rows, tableMargin+l)
+		}
+	}
+	return rows
+}
+
+// columnWidths gives each column its natural width when the table fits, and
+// otherwise water-fills: columns narrower than an even share keep their
+// width and the rest split what remains. It reports false when even
+// tableMinCol per squeezed column would overflow width.
+func columnWidths(t table, width int) ([]int, bool) {
+	n := len(t.align)
+	natural := make([]int, n)
+	for _, r := range append([][]string{t.header}, t.rows...) {
+		for i, c := range r {
+			if w := runewidth.StringWidth(c); w > natural[i] {
+				natural[i] = w
+			}
+		}
+	}
+	avail := width - len(tableMargin) - (n-1)*runewidth.StringWidth(tableSep)
+	total := 0
+	for i := range natural {
+		if natural[i] < 1 {
+			natural[i] = 1
+		}
+		total += natural[i]
+	}
+	if total <= avail {
+		return natural, true
+	}
+	limit := avail
+	widths := make([]int, n)
+	open := n
+	for changed := true; changed && open > 0; {
+		changed = false
+		share := avail / open
+		for i, w := range natural {
+			if widths[i] == 0 && w <= share {
+				widths[i] = w
+				avail -= w
+				open--
+				changed = true
+			}
+		}
+	}
+	if open == 0 {
+		return widths, true
+	}
+	share, extra := avail/open, avail%open
+	for i := range widths {
+		if widths[i] != 0 {
+			continue
+		}
+		widths[i] = share
+		if extra > 0 {
+			widths[i]++
+			extra--
+		}
+		if widths[i] < tableMinCol {
+			widths[i] = tableMinCol
+		}
+	}
+	sum := 0
+	for _, w := range widths {
+		sum += w
+	}
+	return widths, sum <= limit
+}
+
+// tableRows renders one table row, wrapping each cell to its column; the
+// row is as tall as its tallest cell. sgr styles the cell text.
+func tableRows(cells []string, widths []int, align []tableAlign, sgr string) []string {
+	wrapped := make([][]string, len(cells))
+	height := 1
+	for i, c := range cells {
+		wrapped[i] = wordWrap(c, widths[i])
+		if len(wrapped[i]) > height {
+			height = len(wrapped[i])
+		}
+	}
+	rows := make([]string, height)
+	for line := range rows {
+		parts := make([]string, len(cells))
+		for i := range cells {
+			text := ""
+			if line < len(wrapped[i]) {
+				text = wrapped[i][line]
+			}
+			parts[i] = alignCell(text, widths[i], align[i])
+			if sgr != "" && text != "" {
+				parts[i] = sgr + parts[i] + "\x1b[0m"
+			}
+		}
+		rows[line] = strings.TrimRight(tableMargin+strings.Join(parts, tableSep), " ")
+	}
+	return rows
+}
+
+func alignCell(text string, width int, a tableAlign) string {
+	pad := width - runewidth.StringWidth(text)
+	if pad <= 0 {
+		return text
+	}
+	switch a {
+	case alignRight:
+		return strings.Repeat(" ", pad) + text
+	case alignCenter:
+		return strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+	}
+	return text + strings.Repeat(" ", pad)
+}
+
+// wordWrap wraps plain text to width at spaces, hard-breaking a word longer
+// than the line.
+func wordWrap(text string, width int) []string {
+	var rows []string
+	cur, col := "", 0
+	for _, word := range strings.Fields(text) {
+		w := runewidth.StringWidth(word)
+		if col > 0 && col+1+w <= width {
+			cur += " " + word
+			col += 1 + w
+			continue
+		}
+		if col > 0 {
+			rows = append(rows, cur)
+		}
+		parts := wrapPlain(word, width)
+		rows = append(rows, parts[:len(parts)-1]...)
+		cur = parts[len(parts)-1]
+		col = runewidth.StringWidth(cur)
+	}
+	if cur != "" || len(rows) == 0 {
+		rows = append(rows, cur)
+	}
+	return rows
+}
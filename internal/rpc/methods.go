@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/jack-work/figaro/internal/livelog/aria"
+	"github.com/jack-work/figaro/internal/message"
 )
 
 const (
@@ -11,8 +12,12 @@ const (
 	// aria reads: MethodAriaFrame pushes them live (server-pushed pagination),
 	// and MethodRead pulls one for catch-up from a figaro LT. Both carry an
 	// aria.AriaRead. MethodTurnDone is the one control signal (turn went idle).
-	MethodAriaFrame = "figaro.aria" // push one aria read (committed + live delta)
-	MethodTurnDone  = "turn.done"   // the turn went idle
+	// MethodGuardViolation reports a guard rule matching model output;
+	// MethodContentTrimmed, a [limits] cap cutting a tool result or answer.
+	MethodAriaFrame      = "figaro.aria"     // push one aria read (committed + live delta)
+	MethodTurnDone       = "turn.done"       // the turn went idle
+	MethodGuardViolation = "guard.violation" // a tool call or answer broke a rule
+	MethodContentTrimmed = "content.trimmed" // a tool result or answer hit a size limit
 
 	// Requests.
 	MethodQua        = "figaro.qua"
@@ -43,6 +48,10 @@ const (
 	// ErrLoadoutNotFound: named loadout is not on disk.
 	// Data: ErrorData{Name, SearchPaths}.
 	ErrLoadoutNotFound = -32012
+
+	// ErrClassified: a failure the user can act on (a rejected
+	// credential, a spent budget, ...). Data: ErrorData{Kind}.
+	ErrClassified = -32013
 )
 
 // ErrorData is the structured payload attached to typed JSON-RPC errors.
@@ -51,10 +60,12 @@ type ErrorData struct {
 	Loadout            string   `json:"loadout,omitempty"`
 	Name               string   `json:"name,omitempty"`
 	SearchPaths        []string `json:"search_paths,omitempty"`
+	Kind               string   `json:"kind,omitempty"` // an errs.Kind
 }
 
 const (
 	MethodCreate      = "figaro.create"
+	MethodImport      = "figaro.import"
 	MethodFork        = "figaro.fork"
 	MethodPromote     = "figaro.promote"
 	MethodKill        = "figaro.kill"
@@ -79,6 +90,11 @@ const (
 type QuaRequest struct {
 	Text       string           `json:"text"`
 	Chalkboard *ChalkboardInput `json:"chalkboard,omitempty"`
+	// Force sends the prompt even when a spend limit has been reached.
+	Force bool `json:"force,omitempty"`
+	// Author names the sender; it is kept on the user message so every
+	// attached client can attribute it.
+	Author string `json:"author,omitempty"`
 }
 
 // ChalkboardInput carries an optional state update.
@@ -147,7 +163,8 @@ type ChalkboardResponse struct {
 // ReadRequest is the catch-up request. SinceLT streams forward from a cursor
 // (0 = from the beginning). Before>0 switches to a backward keyset read:
 // return up to Limit closed messages with LT < Before, ascending — for pager
-// history without loading it all. The result is an aria.AriaRead.
+// history without loading it all. A Limit on a forward read keeps only the
+// newest Limit closed messages after SinceLT. The result is an aria.AriaRead.
 type ReadRequest struct {
 	SinceLT int `json:"sinceLT,omitempty"`
 	Before  int `json:"before,omitempty"`
@@ -173,6 +190,7 @@ type FigaroInfoResponse struct {
 	Cwd              string `json:"cwd"`                     // working directory (chalkboard "system.cwd")
 	LoadoutName      string `json:"loadout_name,omitempty"`  // chalkboard system.loadout_name
 	LoadoutVer       string `json:"loadout_ver,omitempty"`   // "live" if the stamped hash matches the current loadout, else its short hash
+	Persona          string `json:"persona,omitempty"`       // chalkboard system.persona
 	BoundPIDs        []int  `json:"bound_pids"`
 
 	// Fork-forest position (conversation nodes). Vector is the
@@ -200,6 +218,19 @@ type CreateResponse struct {
 	Endpoint Endpoint `json:"endpoint"`
 }
 
+// ImportRequest creates a dormant conversation holding messages produced
+// outside a live aria, such as batch results. Loadout and Patch resolve as
+// in CreateRequest.
+type ImportRequest struct {
+	Loadout  string            `json:"loadout,omitempty"`
+	Patch    *ChalkboardPatch  `json:"patch,omitempty"`
+	Messages []message.Message `json:"messages"`
+}
+
+type ImportResponse struct {
+	FigaroID string `json:"figaro_id"`
+}
+
 // ForkRequest branches a conversation. AtMainLT == 0 forks at the head;
 // a positive value is an interior fork at that IR logical time (the
 // shared prefix below it freezes).
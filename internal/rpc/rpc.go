@@ -22,4 +22,31 @@ type DoneEntry struct {
 	// settled, the pre-steering behavior) from an explicit false (a turn that
 	// ended with a steer still queued — keep waiting).
 	Idle *bool `json:"idle,omitempty"`
+	// Kind classifies an error Reason (errs.Kind: "auth", "rate_limit",
+	// ...); empty for a normal stop or an unclassified error.
+	Kind string `json:"kind,omitempty"`
+	// From is the main LT the turn started after: its messages are the
+	// ones past it.
+	From uint64 `json:"from,omitempty"`
+}
+
+// GuardViolation is one guard rule matching model output. Params for
+// MethodGuardViolation; sent before the turn's turn.done.
+type GuardViolation struct {
+	Stage   string `json:"stage"`          // "tool" or "answer"
+	Rule    string `json:"rule"`           // the rule's name
+	Tool    string `json:"tool,omitempty"` // the call's tool, at stage "tool"
+	Match   string `json:"match"`          // the text the rule matched
+	Blocked bool   `json:"blocked"`        // the tool call did not run
+}
+
+// Trimmed is one size limit cutting content. Params for
+// MethodContentTrimmed; the CLI reports pasted attachments the same way.
+type Trimmed struct {
+	Stage     string `json:"stage"`                // "tool", "summary", "response" or "attachment"
+	Tool      string `json:"tool,omitempty"`       // the call's tool, at stages "tool" and "summary"
+	Bytes     int    `json:"bytes,omitempty"`      // size before trimming
+	KeptBytes int    `json:"kept_bytes,omitempty"` // size after, marker included
+	MaxTokens int    `json:"max_tokens,omitempty"` // the cap an answer stopped at
+	Path      string `json:"path,omitempty"`       // where a summarized result was kept whole
 }
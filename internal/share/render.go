@@ -0,0 +1,151 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// toolOutputMax caps each tool result on the page; the aria keeps all
+// of it.
+const toolOutputMax = 8000
+
+// md renders prose. Raw HTML in a message is dropped, not passed
+// through: the page must not run anything a model or tool wrote.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Page is what a share renders.
+type Page struct {
+	Title    string
+	Aria     string
+	Expires  time.Time
+	Messages []message.Message
+}
+
+type block struct {
+	Kind    string // prose, thinking, tool, result, image, note
+	HTML    template.HTML
+	Summary string
+	Text    string
+	Error   bool
+	Src     template.URL
+}
+
+type turn struct {
+	Role   string
+	Blocks []block
+}
+
+// Render writes p as a standalone HTML document.
+func Render(w io.Writer, p Page) error {
+	data := struct {
+		Page
+		Turns []turn
+	}{Page: p}
+	for _, m := range p.Messages {
+		t := turn{Role: string(m.Role)}
+		for _, c := range m.Content {
+			if b, ok := renderContent(c); ok {
+				t.Blocks = append(t.Blocks, b)
+			}
+		}
+		if len(t.Blocks) > 0 {
+			data.Turns = append(data.Turns, t)
+		}
+	}
+	return page.Execute(w, data)
+}
+
+func renderContent(c message.Content) (block, bool) {
+	switch c.Type {
+	case message.ContentProse:
+		if strings.TrimSpace(c.Text) == "" {
+			return block{}, false
+		}
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(c.Text), &buf); err != nil {
+			return block{Kind: "result", Text: c.Text}, true
+		}
+		return block{Kind: "prose", HTML: template.HTML(buf.String())}, true
+	case message.ContentThinking:
+		if strings.TrimSpace(c.Text) == "" {
+			return block{}, false
+		}
+		return block{Kind: "thinking", Summary: "thinking", Text: c.Text}, true
+	case message.ContentToolInvoke, message.ContentServerToolInvoke:
+		args, _ := json.MarshalIndent(c.Arguments, "", "  ")
+		return block{Kind: "tool", Summary: c.ToolName, Text: string(args)}, true
+	case message.ContentToolResult, message.ContentServerToolResult:
+		text := c.Text
+		if len(text) > toolOutputMax {
+			text = text[:toolOutputMax] + fmt.Sprintf("\n… %d more bytes", len(c.Text)-toolOutputMax)
+		}
+		return block{Kind: "result", Summary: c.ToolName + " result", Text: text, Error: c.IsError}, true
+	case message.ContentImage:
+		switch c.MimeType {
+		case "image/png", "image/jpeg", "image/gif", "image/webp":
+		default:
+			return block{}, false
+		}
+		return block{Kind: "image", Src: template.URL("data:" + c.MimeType + ";base64," + c.Data)}, true
+	case message.ContentInterrupt:
+		return block{Kind: "note", Text: "interrupted: " + string(c.Reason)}, true
+	}
+	return block{}, false
+}
+
+var page = template.Must(template.New("share").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}aria {{.Aria}}{{end}} · figaro</title>
+<style>
+body { font: 15px/1.55 system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; background: #fff; }
+header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5rem; }
+header p { color: #656d76; font-size: 13px; }
+.turn { margin: 1.25rem 0; }
+.role { font-size: 12px; font-weight: 600; text-transform: uppercase; color: #656d76; }
+.user .body { background: #f6f8fa; border-radius: 6px; padding: .25rem 1rem; }
+pre, code { font: 13px/1.45 ui-monospace, monospace; }
+pre { background: #f6f8fa; padding: .75rem; overflow-x: auto; border-radius: 6px; white-space: pre-wrap; }
+details { margin: .5rem 0; }
+summary { cursor: pointer; color: #656d76; font-size: 13px; }
+.error summary { color: #cf222e; }
+.note { color: #9a6700; font-size: 13px; }
+img { max-width: 100%; }
+table { border-collapse: collapse; } td, th { border: 1px solid #d0d7de; padding: .25rem .5rem; }
+@media (prefers-color-scheme: dark) {
+  body { color: #e6edf3; background: #0d1117; }
+  pre, .user .body { background: #161b22; }
+  header { border-color: #30363d; }
+}
+</style>
+</head>
+<body>
+<header>
+<h1>{{if .Title}}{{.Title}}{{else}}aria {{.Aria}}{{end}}</h1>
+<p>Shared read-only from figaro{{if not .Expires.IsZero}}; this link expires {{.Expires.Format "2006-01-02 15:04 MST"}}{{end}}.</p>
+</header>
+{{range .Turns}}<section class="turn {{.Role}}">
+<div class="role">{{.Role}}</div>
+<div class="body">
+{{range .Blocks}}{{if eq .Kind "prose"}}{{.HTML}}
+{{else if eq .Kind "image"}}<img src="{{.Src}}" alt="">
+{{else if eq .Kind "note"}}<p class="note">{{.Text}}</p>
+{{else}}<details class="{{.Kind}}{{if .Error}} error{{end}}"><summary>{{.Summary}}</summary><pre>{{.Text}}</pre></details>
+{{end}}{{end}}</div>
+</section>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,49 @@
+package share
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// Loader reads an aria for rendering: its title (may be empty) and its
+// messages.
+type Loader func(aria string) (title string, msgs []message.Message, err error)
+
+// Handler serves GET /s/<token>. Unknown, expired and revoked tokens
+// all get the same 404, so a probe learns nothing.
+func Handler(reg *Registry, load Loader) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /s/{token}", func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("X-Robots-Tag", "noindex")
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Cache-Control", "no-store")
+
+		s, ok := reg.Lookup(strings.TrimSpace(r.PathValue("token")))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		title, msgs, err := load(s.Aria)
+		if err != nil {
+			slog.Warn("share: load aria", "aria", s.Aria, "err", err)
+			http.NotFound(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		if err := Render(&buf, Page{Title: title, Aria: s.Aria, Expires: s.Expires, Messages: msgs}); err != nil {
+			slog.Warn("share: render", "aria", s.Aria, "err", err)
+			http.Error(w, "render failed", http.StatusInternalServerError)
+			return
+		}
+		h.Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+	return mux
+}
@@ -0,0 +1,167 @@
+// Package share publishes read-only renders of arias at unguessable
+// URLs. A Registry is the list of live share tokens, kept in a JSON file
+// the CLI edits and the daemon's HTTP server reads on every request, so
+// a revocation or expiry takes effect at once.
+package share
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Share is one published aria.
+type Share struct {
+	Token   string    `json:"token"`
+	Aria    string    `json:"aria"`
+	Created time.Time `json:"created"`
+	// Expires is zero for a share that lasts until revoked.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Live reports whether the share is still served at now.
+func (s Share) Live(now time.Time) bool {
+	return s.Expires.IsZero() || now.Before(s.Expires)
+}
+
+// ErrNoShare is a revoke of a token the registry does not hold.
+var ErrNoShare = errors.New("no such share")
+
+// Registry is the share file.
+type Registry struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// Open returns the registry at path; the file is created by the first
+// Add.
+func Open(path string) *Registry {
+	return &Registry{path: path, now: time.Now}
+}
+
+func (r *Registry) load() ([]Share, error) {
+	raw, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var shares []Share
+	if err := json.Unmarshal(raw, &shares); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.path, err)
+	}
+	return shares, nil
+}
+
+func (r *Registry) save(shares []Share) error {
+	raw, err := json.MarshalIndent(shares, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// Add publishes aria for ttl (0: until revoked) and drops shares that
+// have expired.
+func (r *Registry) Add(aria string, ttl time.Duration) (Share, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shares, err := r.load()
+	if err != nil {
+		return Share{}, err
+	}
+	now := r.now()
+	live := shares[:0]
+	for _, s := range shares {
+		if s.Live(now) {
+			live = append(live, s)
+		}
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Share{}, err
+	}
+	s := Share{Token: base64.RawURLEncoding.EncodeToString(b), Aria: aria, Created: now.UTC()}
+	if ttl > 0 {
+		s.Expires = now.Add(ttl).UTC()
+	}
+	return s, r.save(append(live, s))
+}
+
+// List returns the live shares, newest first.
+func (r *Registry) List() ([]Share, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shares, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	var live []Share
+	for _, s := range shares {
+		if s.Live(now) {
+			live = append(live, s)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Created.After(live[j].Created) })
+	return live, nil
+}
+
+// Revoke removes the share whose token is or starts with token, or every
+// share of the aria token names. It returns what it removed.
+func (r *Registry) Revoke(token string) ([]Share, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shares, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	var kept, gone []Share
+	for _, s := range shares {
+		if s.Aria == token || (len(token) >= 6 && strings.HasPrefix(s.Token, token)) {
+			gone = append(gone, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	if len(gone) == 0 {
+		return nil, ErrNoShare
+	}
+	if len(gone) > 1 && gone[0].Aria != token {
+		return nil, fmt.Errorf("%q matches %d shares; give more of the token", token, len(gone))
+	}
+	return gone, r.save(kept)
+}
+
+// Lookup returns the live share with exactly token.
+func (r *Registry) Lookup(token string) (Share, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	shares, err := r.load()
+	if err != nil || token == "" {
+		return Share{}, false
+	}
+	for _, s := range shares {
+		if s.Token == token {
+			return s, s.Live(r.now())
+		}
+	}
+	return Share{}, false
+}
@@ -0,0 +1,137 @@
+package share
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func testRegistry(t *testing.T) (*Registry, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := Open(filepath.Join(t.TempDir(), "shares.json"))
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func TestRegistryLifecycle(t *testing.T) {
+	r, now := testRegistry(t)
+	a, err := r.Add("aria1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := r.Add("aria2", 0)
+	if len(a.Token) < 20 || a.Token == b.Token {
+		t.Fatalf("tokens %q %q", a.Token, b.Token)
+	}
+	if s, ok := r.Lookup(a.Token); !ok || s.Aria != "aria1" {
+		t.Errorf("lookup = %+v %v", s, ok)
+	}
+	if _, ok := r.Lookup(a.Token[:8]); ok {
+		t.Error("lookup matched a prefix")
+	}
+
+	*now = now.Add(2 * time.Hour)
+	if _, ok := r.Lookup(a.Token); ok {
+		t.Error("expired share still live")
+	}
+	live, _ := r.List()
+	if len(live) != 1 || live[0].Token != b.Token {
+		t.Errorf("list = %+v", live)
+	}
+
+	if _, err := r.Revoke(b.Token[:8]); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Lookup(b.Token); ok {
+		t.Error("revoked share still live")
+	}
+	if _, err := r.Revoke(b.Token); !errors.Is(err, ErrNoShare) {
+		t.Errorf("second revoke: %v", err)
+	}
+}
+
+func TestRevokeByAria(t *testing.T) {
+	r, _ := testRegistry(t)
+	r.Add("aria1", 0)
+	r.Add("aria1", 0)
+	r.Add("aria2", 0)
+	gone, err := r.Revoke("aria1")
+	if err != nil || len(gone) != 2 {
+		t.Fatalf("revoke aria = %v %v", gone, err)
+	}
+	if _, err := r.Revoke("ari"); !errors.Is(err, ErrNoShare) {
+		t.Errorf("short prefix: %v", err)
+	}
+	if live, _ := r.List(); len(live) != 1 || live[0].Aria != "aria2" {
+		t.Errorf("left = %+v", live)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r, now := testRegistry(t)
+	s, _ := r.Add("aria1", time.Hour)
+	msgs := []message.Message{
+		{Role: message.RoleUser, Content: []message.Content{message.TextContent("what is **2+2**?")}},
+		{Role: message.RoleAssistant, Content: []message.Content{
+			{Type: message.ContentThinking, Text: "easy"},
+			message.TextContent("Four.<script>alert(1)</script>"),
+			{Type: message.ContentImage, MimeType: "image/svg+xml", Data: "PHN2Zz4="},
+		}},
+	}
+	srv := httptest.NewServer(Handler(r, func(aria string) (string, []message.Message, error) {
+		return "arithmetic", msgs, nil
+	}))
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := get("/s/" + s.Token)
+	if resp.StatusCode != 200 {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	for _, want := range []string{"<title>arithmetic", "<strong>2+2</strong>", "Four.", "<summary>thinking</summary>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	for _, bad := range []string{"<script", "svg+xml"} {
+		if strings.Contains(body, bad) {
+			t.Errorf("page contains %q", bad)
+		}
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("csp = %q", csp)
+	}
+
+	if resp, _ := get("/s/nope"); resp.StatusCode != 404 {
+		t.Errorf("unknown token: %d", resp.StatusCode)
+	}
+	*now = now.Add(2 * time.Hour)
+	if resp, _ := get("/s/" + s.Token); resp.StatusCode != 404 {
+		t.Errorf("expired token: %d", resp.StatusCode)
+	}
+	resp, err := http.Post(srv.URL+"/s/"+s.Token, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("post: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+// blobStore is the IR's content-addressed side: a content block whose
+// Text or Data reaches blobMinBytes is written once under its sha256 and
+// the record keeps only the ref. The same file read in ten conversations,
+// or pasted into every fork of one, costs one copy on disk. Reads fill the
+// bytes back in, so nothing above the store sees a ref.
+//
+//	root/_blobs/ab/ab12…ef   (sha256 of the bytes, fanned out by prefix)
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jack-work/figaro/internal/message"
+	"github.com/jack-work/figwal/xwal"
+)
+
+// blobMinBytes is the smallest Text or Data moved out of a record. Below
+// it the ref and the file cost more than the copy they save.
+const blobMinBytes = 8 << 10
+
+// blobSweepGrace keeps a young unreferenced blob: its record may still be
+// in the flusher's buffer, not yet on disk for the sweep to find.
+const blobSweepGrace = time.Hour
+
+const blobRefPrefix = "sha256:"
+
+type blobStore struct {
+	dir string
+}
+
+func (b *blobStore) path(sum string) string {
+	return filepath.Join(b.dir, sum[:2], sum)
+}
+
+// put stores data once and returns its ref. A hit refreshes the blob's
+// mtime, so a sweep running alongside keeps it through blobSweepGrace
+// even when the record reusing it lands after its trunk was scanned.
+func (b *blobStore) put(data string) (string, error) {
+	h := sha256.Sum256([]byte(data))
+	sum := hex.EncodeToString(h[:])
+	path := b.path(sum)
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err == nil {
+			return blobRefPrefix + sum, nil
+		}
+		// Swept between the Stat and the touch: write it again.
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), sum+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return blobRefPrefix + sum, os.Rename(f.Name(), path)
+}
+
+func (b *blobStore) get(ref string) (string, error) {
+	sum, ok := strings.CutPrefix(ref, blobRefPrefix)
+	if !ok || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("blob store: bad ref %q", ref)
+	}
+	data, err := os.ReadFile(b.path(sum))
+	return string(data), err
+}
+
+// externalize returns m with each large Text and Data swapped for a ref.
+// m itself is not modified.
+func (b *blobStore) externalize(m message.Message) (message.Message, error) {
+	var out []message.Content
+	for i, c := range m.Content {
+		if len(c.Text) < blobMinBytes && len(c.Data) < blobMinBytes {
+			continue
+		}
+		if out == nil {
+			out = append([]message.Content(nil), m.Content...)
+		}
+		var err error
+		if len(c.Text) >= blobMinBytes {
+			if c.TextRef, err = b.put(c.Text); err != nil {
+				return m, err
+			}
+			c.Text = ""
+		}
+		if len(c.Data) >= blobMinBytes {
+			if c.DataRef, err = b.put(c.Data); err != nil {
+				return m, err
+			}
+			c.Data = ""
+		}
+		out[i] = c
+	}
+	if out != nil {
+		m.Content = out
+	}
+	return m, nil
+}
+
+// materialize fills m's refs back in. A missing blob leaves a marker in
+// the text rather than dropping the record; a block whose data (an image)
+// is missing becomes that marker, since empty data is no image at all.
+func (b *blobStore) materialize(m *message.Message) {
+	for i := range m.Content {
+		c := &m.Content[i]
+		if c.TextRef != "" {
+			text, err := b.get(c.TextRef)
+			if err != nil {
+				text = missingBlob(c.TextRef)
+			}
+			c.Text, c.TextRef = text, ""
+		}
+		if c.DataRef != "" {
+			data, err := b.get(c.DataRef)
+			if err != nil {
+				*c = message.TextContent(missingBlob(c.DataRef))
+				continue
+			}
+			c.Data, c.DataRef = data, ""
+		}
+	}
+}
+
+func missingBlob(ref string) string {
+	return fmt.Sprintf("[missing stored content %s]", ref)
+}
+
+// blobRefs is the slice of a record the sweep reads.
+type blobRefs struct {
+	Content []struct {
+		TextRef string `json:"text_ref"`
+		DataRef string `json:"data_ref"`
+	} `json:"content"`
+}
+
+// SweepBlobs removes blobs no IR record refers to any more (their
+// conversations removed or archived) and reports how many and their size;
+// with dryRun it only reports. It reads every record of every trunk, so it
+// runs from doctor gc, never on a live path. Blobs younger than an hour
+// are kept.
+func (s *XwalStore) SweepBlobs(dryRun bool) (removed int, freed int64, err error) {
+	live := map[string]bool{}
+	for _, t := range s.listTrunks() {
+		xw, err := s.OpenNode(t.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		scanBlobRefs(xw, live)
+		_ = xw.Close()
+	}
+	now := time.Now()
+	err = filepath.WalkDir(s.blobs.dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || live[blobRefPrefix+d.Name()] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil || now.Sub(info.ModTime()) < blobSweepGrace {
+			return nil
+		}
+		if dryRun || os.Remove(p) == nil {
+			removed++
+			freed += info.Size()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return removed, freed, err
+}
+
+func scanBlobRefs(xw *xwal.XWAL, live map[string]bool) {
+	for _, c := range xw.Channels() {
+		if c.Name != chanIR {
+			continue
+		}
+		first := max(c.First, 1)
+		for lt := first; lt <= c.Last; lt++ {
+			r, err := xw.ReadAt(chanIR, lt)
+			if err != nil || !strings.Contains(string(r.Payload), `_ref"`) {
+				continue
+			}
+			var refs blobRefs
+			if json.Unmarshal(r.Payload, &refs) != nil {
+				continue
+			}
+			for _, c := range refs.Content {
+				live[c.TextRef], live[c.DataRef] = true, true
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func blobFiles(t *testing.T, root string) []string {
+	t.Helper()
+	var out []string
+	filepath.WalkDir(filepath.Join(root, "_blobs"), func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			out = append(out, p)
+		}
+		return nil
+	})
+	return out
+}
+
+func bigResult(text string) message.Message {
+	return message.Message{Role: message.RoleUser, Content: []message.Content{
+		message.ToolResultContent("tc_1", "read", text, false),
+	}}
+}
+
+func TestBlobsStoreLargeContentOnce(t *testing.T) {
+	root := t.TempDir()
+	b, err := NewXwalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := b.CreateLoadout("default", patchSet(nil))
+	one, _ := b.CreateConversation(l)
+	two, _ := b.CreateConversation(l)
+	file := strings.Repeat("package main // a large file read twice\n", 1000)
+
+	for _, id := range []string{one, two} {
+		log, err := b.Open(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := log.Append(Entry[message.Message]{Payload: bigResult(file)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := log.Append(Entry[message.Message]{Payload: bigResult("small")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, alt, err := b.Fork(one); err != nil {
+		t.Fatal(err)
+	} else if log, _ := b.Open(alt); log != nil {
+		log.Append(Entry[message.Message]{Payload: bigResult(file)})
+	}
+	if got := blobFiles(t, root); len(got) != 1 {
+		t.Fatalf("blobs = %v, want the file stored once", got)
+	}
+
+	xw, err := b.store.OpenNode(two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw string
+	for _, c := range xw.Channels() {
+		if c.Name == chanIR {
+			r, _ := xw.ReadAt(chanIR, c.Last-1)
+			raw = string(r.Payload)
+		}
+	}
+	xw.Close()
+	if strings.Contains(raw, "large file") || !strings.Contains(raw, `"text_ref":"sha256:`) {
+		t.Fatalf("record should hold a ref, got %.200s", raw)
+	}
+
+	// A fresh backend on the same root reads the content back whole.
+	b.Close()
+	b2, err := NewXwalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b2.Close()
+	log, err := b2.Open(two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := log.Read()
+	var texts []string
+	for _, e := range entries {
+		for _, c := range e.Payload.Content {
+			if c.TextRef != "" {
+				t.Fatalf("ref leaked past the store: %s", c.TextRef)
+			}
+			texts = append(texts, c.Text)
+		}
+	}
+	if len(texts) < 2 || texts[len(texts)-2] != file || texts[len(texts)-1] != "small" {
+		t.Fatalf("read back %d texts, want the file then small", len(texts))
+	}
+}
+
+func TestSweepBlobsKeepsLiveContent(t *testing.T) {
+	root := t.TempDir()
+	b, err := NewXwalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	l, _ := b.CreateLoadout("default", patchSet(nil))
+	kept, _ := b.CreateConversation(l)
+	gone, _ := b.CreateConversation(l)
+	for id, text := range map[string]string{kept: strings.Repeat("k", blobMinBytes), gone: strings.Repeat("g", blobMinBytes)} {
+		log, _ := b.Open(id)
+		if _, err := log.Append(Entry[message.Message]{Payload: bigResult(text)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Remove(gone, false); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * blobSweepGrace)
+	for _, p := range blobFiles(t, root) {
+		os.Chtimes(p, old, old)
+	}
+
+	if removed, freed, err := b.SweepBlobs(true); err != nil || removed != 1 || freed != blobMinBytes {
+		t.Fatalf("SweepBlobs(dry run) = %d, %d, %v; want the removed conversation's blob", removed, freed, err)
+	}
+	if n := len(blobFiles(t, root)); n != 2 {
+		t.Fatalf("dry run removed blobs: %d left", n)
+	}
+	removed, _, err := b.SweepBlobs(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("SweepBlobs = %d, %v; want the removed conversation's blob", removed, err)
+	}
+	log, _ := b.Open(kept)
+	entries := log.Read()
+	if got := entries[len(entries)-1].Payload.Content[0].Text; got != strings.Repeat("k", blobMinBytes) {
+		t.Fatalf("live content lost: %.20q", got)
+	}
+}
+
+func TestMaterializeMarksMissingBlobs(t *testing.T) {
+	b := &blobStore{dir: t.TempDir()}
+	ref := blobRefPrefix + strings.Repeat("0", 64)
+	m := message.Message{Content: []message.Content{
+		{Type: message.ContentProse, TextRef: ref},
+		{Type: message.ContentImage, MimeType: "image/png", DataRef: ref},
+	}}
+	b.materialize(&m)
+	for i, c := range m.Content {
+		if c.Type != message.ContentProse || c.Text != "[missing stored content "+ref+"]" || c.Data != "" || c.DataRef != "" {
+			t.Errorf("block %d = %+v, want a text marker", i, c)
+		}
+	}
+}
+
+func TestBlobPutHitRefreshesMtime(t *testing.T) {
+	b := &blobStore{dir: t.TempDir()}
+	text := strings.Repeat("r", blobMinBytes)
+	ref, err := b.put(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := b.path(strings.TrimPrefix(ref, blobRefPrefix))
+	old := time.Now().Add(-2 * blobSweepGrace)
+	os.Chtimes(path, old, old)
+	if again, err := b.put(text); err != nil || again != ref {
+		t.Fatalf("put hit = %q, %v", again, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) >= blobSweepGrace {
+		t.Fatalf("reused blob not refreshed: %v", err)
+	}
+}
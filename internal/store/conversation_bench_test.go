@@ -0,0 +1,156 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// conversationSizes are the history lengths load and save are measured at.
+var conversationSizes = []int{100, 1_000, 10_000}
+
+// benchTurn is the i-th message of a synthetic conversation: alternating
+// user and assistant prose of a few hundred bytes.
+func benchTurn(i int) message.Message {
+	role := message.RoleUser
+	if i%2 == 1 {
+		role = message.RoleAssistant
+	}
+	return message.Message{Role: role, Timestamp: int64(i), Content: []message.Content{
+		message.TextContent(fmt.Sprintf("turn %d: the quick brown fox jumps over the lazy dog, "+
+			"then reads main.go, edits two functions and runs the tests again.", i)),
+	}}
+}
+
+// seedConversation writes an n-message conversation and closes the
+// backend, so the benchmark opens it cold.
+func seedConversation(tb testing.TB, n int) (string, string) {
+	tb.Helper()
+	root := tb.TempDir()
+	be, err := NewXwalBackend(root)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	l, err := be.CreateLoadout("bench", message.Patch{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	id, err := be.CreateConversation(l)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	lg, err := be.Open(id)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if _, err := lg.Append(Entry[message.Message]{Payload: benchTurn(i)}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := be.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return root, id
+}
+
+func seedJSONL(tb testing.TB, n int) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "bench"+JSONLExt)
+	l, err := OpenJSONL(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(Entry[message.Message]{Payload: benchTurn(i)}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// BenchmarkConversationLoad is a cold load of a whole conversation, as on
+// first attach after a daemon restart: open the store, read every message.
+func BenchmarkConversationLoad(b *testing.B) {
+	for _, n := range conversationSizes {
+		b.Run(fmt.Sprintf("xwal/messages=%d", n), func(b *testing.B) {
+			root, id := seedConversation(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				be, err := NewXwalBackend(root)
+				if err != nil {
+					b.Fatal(err)
+				}
+				lg, err := be.Open(id)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if got := len(lg.Read()); got < n {
+					b.Fatalf("read %d messages, want %d", got, n)
+				}
+				be.Close()
+			}
+		})
+		b.Run(fmt.Sprintf("jsonl/messages=%d", n), func(b *testing.B) {
+			path := seedJSONL(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l, err := OpenJSONL(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if got := len(l.Read()); got != n {
+					b.Fatalf("read %d messages, want %d", got, n)
+				}
+				l.Close()
+			}
+		})
+	}
+}
+
+// BenchmarkConversationSave appends one message to a conversation already
+// holding n. Its cost must not grow with n.
+func BenchmarkConversationSave(b *testing.B) {
+	for _, n := range conversationSizes {
+		b.Run(fmt.Sprintf("xwal/messages=%d", n), func(b *testing.B) {
+			root, id := seedConversation(b, n)
+			be, err := NewXwalBackend(root)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer be.Close()
+			lg, err := be.Open(id)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := lg.Append(Entry[message.Message]{Payload: benchTurn(n + i)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("jsonl/messages=%d", n), func(b *testing.B) {
+			l, err := OpenJSONL(seedJSONL(b, n))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer l.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := l.Append(Entry[message.Message]{Payload: benchTurn(n + i)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package store
+
+// JSONLLog is an aria's IR as a plain file: one message per line, each
+// carrying its logical_time. Adding a message appends a line; nothing
+// already written is rewritten. A sidecar index holds one fixed-width
+// (LT, offset) record per line, so a tail read or an LT lookup seeks
+// straight to its lines instead of scanning the file.
+//
+//	chat.figaro.jsonl       {"role":"user",...,"logical_time":1}\n ...
+//	chat.figaro.jsonl.idx   [lt uint64][offset uint64] per line, little-endian
+//
+// The index is derived: when it is missing or disagrees with the file (a
+// hand edit, a copy without it) OpenJSONL rebuilds it with one scan.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+// JSONLExt is the suffix of a JSONL conversation file.
+const JSONLExt = ".figaro.jsonl"
+
+const jsonlIndexRecord = 16
+
+// jsonlLine locates one line of the file.
+type jsonlLine struct {
+	lt     uint64
+	offset int64
+}
+
+// JSONLLog is a Log[message.Message] over a .figaro.jsonl file.
+type JSONLLog struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	idx   *os.File
+	lines []jsonlLine
+	size  int64
+}
+
+var _ Log[message.Message] = (*JSONLLog)(nil)
+
+// IsJSONL reports whether path names a JSONL conversation file.
+func IsJSONL(path string) bool { return strings.HasSuffix(path, JSONLExt) }
+
+// OpenJSONL opens (creating if absent) the conversation file at path and
+// its index.
+func OpenJSONL(path string) (*JSONLLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := os.OpenFile(path+".idx", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l := &JSONLLog{path: path, f: f, idx: idx}
+	if err := l.load(); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// load reads the index, rebuilding it when it does not match the file.
+func (l *JSONLLog) load() error {
+	fi, err := l.f.Stat()
+	if err != nil {
+		return err
+	}
+	l.size = fi.Size()
+	raw, err := io.ReadAll(io.NewSectionReader(l.idx, 0, 1<<62))
+	if err != nil {
+		return err
+	}
+	if len(raw)%jsonlIndexRecord == 0 {
+		l.lines = make([]jsonlLine, len(raw)/jsonlIndexRecord)
+		for i := range l.lines {
+			rec := raw[i*jsonlIndexRecord:]
+			l.lines[i] = jsonlLine{
+				lt:     binary.LittleEndian.Uint64(rec),
+				offset: int64(binary.LittleEndian.Uint64(rec[8:])),
+			}
+		}
+		if l.indexMatches() {
+			return nil
+		}
+	}
+	return l.reindex()
+}
+
+// indexMatches checks the index's last line ends the file and every line
+// starts where the previous one could have.
+func (l *JSONLLog) indexMatches() bool {
+	if len(l.lines) == 0 {
+		return l.size == 0
+	}
+	for i := 1; i < len(l.lines); i++ {
+		if l.lines[i].offset <= l.lines[i-1].offset || l.lines[i].lt <= l.lines[i-1].lt {
+			return false
+		}
+	}
+	last := l.lines[len(l.lines)-1]
+	if last.offset >= l.size {
+		return false
+	}
+	tail := make([]byte, l.size-last.offset)
+	if _, err := l.f.ReadAt(tail, last.offset); err != nil {
+		return false
+	}
+	return bytes.IndexByte(tail, '\n') == len(tail)-1
+}
+
+// reindex scans the file and rewrites the index. Lines that are blank or
+// do not parse are left out; lines must ascend in LT, and one without a
+// logical_time takes the one after its predecessor's. A last line
+// without its newline gets one, so the next append starts a line.
+func (l *JSONLLog) reindex() error {
+	l.lines = l.lines[:0]
+	r := bufio.NewReader(io.NewSectionReader(l.f, 0, l.size))
+	var off int64
+	var buf bytes.Buffer
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var m struct {
+				LT uint64 `json:"logical_time"`
+			}
+			if json.Unmarshal(bytes.TrimSpace(line), &m) == nil {
+				var last uint64
+				if n := len(l.lines); n > 0 {
+					last = l.lines[n-1].lt
+				}
+				switch {
+				case m.LT == 0:
+					m.LT = last + 1
+				case m.LT <= last:
+					return fmt.Errorf("line at byte %d: logical_time %d does not follow %d", off, m.LT, last)
+				}
+				l.lines = append(l.lines, jsonlLine{lt: m.LT, offset: off})
+				buf.Write(indexRecord(m.LT, off))
+			}
+		}
+		off += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if off > 0 && !l.endsWithNewline() {
+		if _, err := l.f.WriteAt([]byte("\n"), l.size); err != nil {
+			return err
+		}
+		l.size++
+	}
+	if err := l.idx.Truncate(0); err != nil {
+		return err
+	}
+	_, err := l.idx.WriteAt(buf.Bytes(), 0)
+	return err
+}
+
+func (l *JSONLLog) endsWithNewline() bool {
+	b := make([]byte, 1)
+	_, err := l.f.ReadAt(b, l.size-1)
+	return err == nil && b[0] == '\n'
+}
+
+func indexRecord(lt uint64, off int64) []byte {
+	rec := make([]byte, jsonlIndexRecord)
+	binary.LittleEndian.PutUint64(rec, lt)
+	binary.LittleEndian.PutUint64(rec[8:], uint64(off))
+	return rec
+}
+
+// Path is the conversation file's path.
+func (l *JSONLLog) Path() string { return l.path }
+
+// Close closes the file and its index.
+func (l *JSONLLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.f.Close(), l.idx.Close())
+}
+
+// readLines decodes lines[lo:hi] with one read of their byte span.
+func (l *JSONLLog) readLines(lo, hi int) []Entry[message.Message] {
+	if lo >= hi {
+		return nil
+	}
+	end := l.size
+	if hi < len(l.lines) {
+		end = l.lines[hi].offset
+	}
+	start := l.lines[lo].offset
+	span := make([]byte, end-start)
+	if _, err := l.f.ReadAt(span, start); err != nil && err != io.EOF {
+		return nil
+	}
+	out := make([]Entry[message.Message], 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		next := end
+		if i+1 < hi {
+			next = l.lines[i+1].offset
+		}
+		line := span[l.lines[i].offset-start : next-start]
+		if nl := bytes.IndexByte(line, '\n'); nl >= 0 {
+			line = line[:nl]
+		}
+		var m message.Message
+		if json.Unmarshal(line, &m) != nil {
+			continue
+		}
+		lt := l.lines[i].lt
+		m.LogicalTime = lt
+		out = append(out, Entry[message.Message]{LT: lt, FigaroLT: lt, Payload: m})
+	}
+	return out
+}
+
+// search returns the index of the first line with LT >= lt.
+func (l *JSONLLog) search(lt uint64) int {
+	return sort.Search(len(l.lines), func(i int) bool { return l.lines[i].lt >= lt })
+}
+
+func (l *JSONLLog) Read() []Entry[message.Message] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.readLines(0, len(l.lines))
+}
+
+func (l *JSONLLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.lines)
+}
+
+// TailSnapshot reads only the last n lines.
+func (l *JSONLLog) TailSnapshot(n int) []Entry[message.Message] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 {
+		return nil
+	}
+	return l.readLines(max(len(l.lines)-n, 0), len(l.lines))
+}
+
+func (l *JSONLLog) ReadFrom(figaroLT uint64, n int) []Entry[message.Message] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lo := l.search(figaroLT)
+	hi := len(l.lines)
+	if n > 0 && lo+n < hi {
+		hi = lo + n
+	}
+	return l.readLines(lo, hi)
+}
+
+func (l *JSONLLog) ReadPage(from, before uint64, n int) ([]Entry[message.Message], int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	total := len(l.lines)
+	if before > 0 {
+		if n <= 0 {
+			return nil, total
+		}
+		hi := l.search(before)
+		return l.readLines(max(hi-n, 0), hi), total
+	}
+	lo := l.search(from)
+	hi := total
+	if n > 0 && lo+n < hi {
+		hi = lo + n
+	}
+	return l.readLines(lo, hi), total
+}
+
+func (l *JSONLLog) Lookup(figaroLT uint64) (Entry[message.Message], bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := l.search(figaroLT)
+	if i == len(l.lines) || l.lines[i].lt != figaroLT {
+		return Entry[message.Message]{}, false
+	}
+	if e := l.readLines(i, i+1); len(e) == 1 {
+		return e[0], true
+	}
+	return Entry[message.Message]{}, false
+}
+
+func (l *JSONLLog) PeekTail() (Entry[message.Message], bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e := l.readLines(max(len(l.lines)-1, 0), len(l.lines)); len(e) == 1 {
+		return e[0], true
+	}
+	return Entry[message.Message]{}, false
+}
+
+// Append writes e as a new last line and indexes it. e.LT, when set
+// above the current tail, is kept (an export carries its aria's LTs);
+// otherwise the line gets the next LT.
+func (l *JSONLLog) Append(e Entry[message.Message]) (Entry[message.Message], error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var last uint64
+	if n := len(l.lines); n > 0 {
+		last = l.lines[n-1].lt
+	}
+	if e.LT <= last {
+		e.LT = last + 1
+	}
+	e.FigaroLT = e.LT
+	e.Payload.LogicalTime = e.LT
+	line, err := json.Marshal(e.Payload)
+	if err != nil {
+		return e, err
+	}
+	line = append(line, '\n')
+	if _, err := l.f.WriteAt(line, l.size); err != nil {
+		return e, err
+	}
+	if _, err := l.idx.WriteAt(indexRecord(e.LT, l.size), int64(len(l.lines))*jsonlIndexRecord); err != nil {
+		return e, err
+	}
+	l.lines = append(l.lines, jsonlLine{lt: e.LT, offset: l.size})
+	l.size += int64(len(line))
+	return e, nil
+}
+
+func (l *JSONLLog) Clear() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := errors.Join(l.f.Truncate(0), l.idx.Truncate(0)); err != nil {
+		return err
+	}
+	l.lines, l.size = nil, 0
+	return nil
+}
+
+// Sync flushes the file and its index to disk.
+func (l *JSONLLog) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.f.Sync(), l.idx.Sync())
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jack-work/figaro/internal/message"
+)
+
+func jsonlText(text string) Entry[message.Message] {
+	return Entry[message.Message]{Payload: message.Message{
+		Role:    message.RoleUser,
+		Content: []message.Content{message.TextContent(text)},
+	}}
+}
+
+func jsonlTexts(entries []Entry[message.Message]) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Payload.Content[0].Text)
+	}
+	return out
+}
+
+func TestJSONLLog_AppendsAndReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat"+JSONLExt)
+	l, err := OpenJSONL(path)
+	require.NoError(t, err)
+	for _, s := range []string{"one", "two", "three"} {
+		_, err := l.Append(jsonlText(s))
+		require.NoError(t, err)
+	}
+	before, _ := os.ReadFile(path)
+	e, err := l.Append(jsonlText("four"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), e.LT)
+	after, _ := os.ReadFile(path)
+	assert.True(t, strings.HasPrefix(string(after), string(before)), "append rewrote earlier lines")
+	assert.Equal(t, 4, strings.Count(string(after), "\n"))
+	require.NoError(t, l.Close())
+
+	l, err = OpenJSONL(path)
+	require.NoError(t, err)
+	defer l.Close()
+	assert.Equal(t, []string{"one", "two", "three", "four"}, jsonlTexts(l.Read()))
+	assert.Equal(t, []string{"three", "four"}, jsonlTexts(l.TailSnapshot(2)))
+	page, total := l.ReadPage(0, 3, 5)
+	assert.Equal(t, []string{"one", "two"}, jsonlTexts(page))
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"two", "three"}, jsonlTexts(l.ReadFrom(2, 2)))
+	got, ok := l.Lookup(3)
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), got.Payload.LogicalTime)
+	tail, ok := l.PeekTail()
+	require.True(t, ok)
+	assert.Equal(t, "four", tail.Payload.Content[0].Text)
+}
+
+func TestJSONLLog_KeepsExportedLTs(t *testing.T) {
+	l, err := OpenJSONL(filepath.Join(t.TempDir(), "fork"+JSONLExt))
+	require.NoError(t, err)
+	defer l.Close()
+	e := jsonlText("from a fork")
+	e.LT = 40
+	got, err := l.Append(e)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(40), got.LT)
+	got, err = l.Append(jsonlText("next"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(41), got.LT)
+	_, ok := l.Lookup(40)
+	assert.True(t, ok)
+	_, ok = l.Lookup(1)
+	assert.False(t, ok)
+}
+
+func TestJSONLLog_RebuildsIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat"+JSONLExt)
+	l, err := OpenJSONL(path)
+	require.NoError(t, err)
+	l.Append(jsonlText("one"))
+	l.Append(jsonlText("two"))
+	require.NoError(t, l.Close())
+
+	// A lost index, then a line added by hand behind the index's back.
+	require.NoError(t, os.Remove(path+".idx"))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	f.WriteString("\n" + `{"role":"user","content":[{"type":"prose","text":"three"}]}`)
+	f.Close()
+
+	for range 2 {
+		l, err = OpenJSONL(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "two", "three"}, jsonlTexts(l.Read()))
+		require.NoError(t, l.Close())
+	}
+	l, err = OpenJSONL(path)
+	require.NoError(t, err)
+	defer l.Close()
+	e, err := l.Append(jsonlText("four"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), e.LT)
+	assert.Equal(t, []string{"three", "four"}, jsonlTexts(l.TailSnapshot(2)))
+}
@@ -31,6 +31,7 @@ type AriaMeta struct {
 	Cwd              string `json:"cwd,omitempty"`
 	LoadoutName      string `json:"loadout_name,omitempty"`
 	LoadoutVersion   string `json:"loadout_version,omitempty"`
+	Persona          string `json:"persona,omitempty"`
 	ContextTokens    int    `json:"context_tokens,omitempty"`
 	ContextLimit     int    `json:"context_limit,omitempty"`
 	ContextExact     bool   `json:"context_exact,omitempty"`
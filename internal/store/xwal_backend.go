@@ -382,6 +382,11 @@ func (b *XwalBackend) Remove(ariaID string, recursive bool) error {
 	return b.store.RemoveLeaf(ariaID, recursive)
 }
 
+// SweepBlobs drops stored content no conversation refers to any more.
+func (b *XwalBackend) SweepBlobs(dryRun bool) (int, int64, error) {
+	return b.store.SweepBlobs(dryRun)
+}
+
 func (b *XwalBackend) Close() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/jack-work/figaro/internal/message"
 	"github.com/jack-work/figwal/xwal"
 )
 
@@ -49,13 +50,17 @@ func decodeMeta(meta []byte) string {
 	return fp
 }
 
-func decodeRecord[T any](r xwal.Record) (Entry[T], bool) {
+// decodeRecord unmarshals a record, filling IR blob refs back in.
+func (l *xwalLog[T]) decodeRecord(r xwal.Record) (Entry[T], bool) {
 	var v T
 	if len(r.Payload) > 0 {
 		if err := json.Unmarshal(r.Payload, &v); err != nil {
 			return Entry[T]{}, false
 		}
 	}
+	if m, ok := any(&v).(*message.Message); ok && l.channel == chanIR {
+		l.store.blobs.materialize(m)
+	}
 	return Entry[T]{
 		LT:          r.ChannelLT,
 		FigaroLT:    r.MainLT,
@@ -101,7 +106,7 @@ func (l *xwalLog[T]) Read() []Entry[T] {
 			if err != nil {
 				continue
 			}
-			if e, ok := decodeRecord[T](r); ok {
+			if e, ok := l.decodeRecord(r); ok {
 				out = append(out, e)
 			}
 		}
@@ -154,7 +159,7 @@ func (l *xwalLog[T]) ReadFrom(figaroLT uint64, n int) []Entry[T] {
 			if err != nil || r.MainLT < figaroLT {
 				continue
 			}
-			if e, ok := decodeRecord[T](r); ok {
+			if e, ok := l.decodeRecord(r); ok {
 				out = append(out, e)
 			}
 		}
@@ -210,7 +215,7 @@ func (l *xwalLog[T]) Lookup(figaroLT uint64) (Entry[T], bool) {
 	if !hit {
 		return Entry[T]{}, false
 	}
-	return decodeRecord[T](rec)
+	return l.decodeRecord(rec)
 }
 
 func (l *xwalLog[T]) PeekTail() (Entry[T], bool) {
@@ -242,13 +247,22 @@ func (l *xwalLog[T]) PeekTail() (Entry[T], bool) {
 	if !hit {
 		return Entry[T]{}, false
 	}
-	return decodeRecord[T](rec)
+	return l.decodeRecord(rec)
 }
 
 // Append routes through Trunks.Append / Trunks.AppendChannel, which
-// serialize against topology changes inside figwal.
+// serialize against topology changes inside figwal. Large IR content goes
+// to the blob store first; the record keeps its ref.
 func (l *xwalLog[T]) Append(e Entry[T]) (Entry[T], error) {
-	payload, err := json.Marshal(e.Payload)
+	var record any = e.Payload
+	if m, ok := record.(message.Message); ok && l.channel == chanIR {
+		ext, err := l.store.blobs.externalize(m)
+		if err != nil {
+			return Entry[T]{}, fmt.Errorf("xwalLog append: %w", err)
+		}
+		record = ext
+	}
+	payload, err := json.Marshal(record)
 	if err != nil {
 		return Entry[T]{}, fmt.Errorf("xwalLog append marshal: %w", err)
 	}
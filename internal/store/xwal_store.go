@@ -28,6 +28,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -135,6 +136,7 @@ type XwalStore struct {
 	root     string
 	mu       sync.Mutex
 	trunks   *xwal.Store
+	blobs    *blobStore
 	topology atomic.Pointer[topologySnapshot]
 	now      func() int64
 }
@@ -161,7 +163,8 @@ func OpenXwalStore(root string) (*XwalStore, error) {
 	}
 	return &XwalStore{
 		root: root, trunks: st,
-		now: func() int64 { return time.Now().UnixMilli() },
+		blobs: &blobStore{dir: filepath.Join(root, "_blobs")},
+		now:   func() int64 { return time.Now().UnixMilli() },
 	}, nil
 }
 
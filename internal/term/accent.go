@@ -0,0 +1,77 @@
+package term
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// accent is the SGR sequence Accent paints with. Set once at startup from
+// the config theme; cyan until then.
+var accent = codeCyan
+
+var namedColors = map[string]int{
+	"black": 30, "red": 31, "green": 32, "yellow": 33,
+	"blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+}
+
+// SetAccent sets the UI accent color: a basic color name ("magenta"), a
+// 256-color palette index ("208"), or a hex triple ("#ff8800"). Empty
+// restores the default cyan.
+func SetAccent(spec string) error {
+	code, err := parseColor(spec)
+	if err != nil {
+		return err
+	}
+	accent = code
+	return nil
+}
+
+func parseColor(spec string) (string, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return codeCyan, nil
+	}
+	if n, ok := namedColors[spec]; ok {
+		return fmt.Sprintf("\033[%dm", n), nil
+	}
+	if hex, ok := strings.CutPrefix(spec, "#"); ok {
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return "", fmt.Errorf("color %q: want #rrggbb", spec)
+		}
+		r, g, b := int(v>>16), int(v>>8&0xff), int(v&0xff)
+		if depth < 24 {
+			return fmt.Sprintf("\033[38;5;%dm", palette256(r, g, b)), nil
+		}
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b), nil
+	}
+	if n, err := strconv.Atoi(spec); err == nil && n >= 0 && n <= 255 {
+		return fmt.Sprintf("\033[38;5;%dm", n), nil
+	}
+	return "", fmt.Errorf("color %q: want a color name, 0–255, or #rrggbb", spec)
+}
+
+// Accent wraps s in the UI accent color if color is enabled.
+func Accent(s string) string {
+	if !Enabled() {
+		return s
+	}
+	return accent + s + reset
+}
+
+// palette256 is the nearest 256-color palette entry to an RGB color, for
+// terminals without truecolor: the gray ramp for grays, else the 6×6×6 cube.
+func palette256(r, g, b int) int {
+	if r == g && g == b {
+		switch {
+		case r < 8:
+			return 16
+		case r > 248:
+			return 231
+		}
+		return 232 + (r-8)*24/241
+	}
+	cube := func(c int) int { return (c*5 + 127) / 255 }
+	return 16 + 36*cube(r) + 6*cube(g) + cube(b)
+}
@@ -0,0 +1,39 @@
+package term
+
+import "strings"
+
+// accessible is screen-reader mode: no color, and the glyphs that carry
+// meaning (status marks, role icons, box-drawing rules and gutters) are
+// spelled out or dropped. Set once at startup.
+var accessible bool
+
+// SetAccessible turns screen-reader mode on or off. On also turns color
+// off; off detects it again.
+func SetAccessible(on bool) {
+	accessible = on
+	if on {
+		mode = ColorNever
+	} else {
+		detect()
+	}
+}
+
+// Accessible reports whether screen-reader mode is on.
+func Accessible() bool { return accessible }
+
+// Sym is glyph normally and word in screen-reader mode; an empty word drops
+// the glyph.
+func Sym(glyph, word string) string {
+	if accessible {
+		return word
+	}
+	return glyph
+}
+
+// Rule is a horizontal rule n columns wide, or "" in screen-reader mode.
+func Rule(n int) string {
+	if accessible || n <= 0 {
+		return ""
+	}
+	return strings.Repeat("─", n)
+}
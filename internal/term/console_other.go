@@ -0,0 +1,8 @@
+//go:build !windows
+
+package term
+
+func enableVT() bool { return true }
+
+// platformDepth assumes truecolor: every terminal figaro targets here has it.
+func platformDepth() int { return 24 }
@@ -0,0 +1,37 @@
+//go:build windows
+
+package term
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVT turns on escape-sequence processing for a console on stdout and
+// stderr. It reports false for a legacy console (before Windows 10), which
+// would print the escapes as text. A pipe or a pty is not a console and
+// needs nothing.
+func enableVT() bool {
+	ok := true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		h := windows.Handle(f.Fd())
+		var m uint32
+		if windows.GetConsoleMode(h, &m) != nil || m&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+			continue
+		}
+		if windows.SetConsoleMode(h, m|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) != nil {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// platformDepth is the color depth of a VT console: 24-bit in Windows
+// Terminal, else the 256-color palette every VT console has.
+func platformDepth() int {
+	if os.Getenv("WT_SESSION") != "" {
+		return 24
+	}
+	return 8
+}
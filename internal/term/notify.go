@@ -0,0 +1,37 @@
+package term
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Bell is the terminal bell; most terminals flag an unfocused tab or
+// window on it.
+const Bell = "\a"
+
+// notifyCommand is the desktop notifier for this platform, or nil.
+func notifyCommand(title, body string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		quote := func(s string) string { return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"` }
+		return []string{"osascript", "-e", "display notification " + quote(body) + " with title " + quote(title)}
+	case "windows":
+		return nil
+	}
+	return []string{"notify-send", "--app-name=figaro", title, body}
+}
+
+// Notify posts a desktop notification via notify-send (Linux) or
+// osascript (macOS).
+func Notify(title, body string) error {
+	c := notifyCommand(title, body)
+	if c == nil {
+		return errors.New("desktop notifications are not supported on " + runtime.GOOS)
+	}
+	if _, err := exec.LookPath(c[0]); err != nil {
+		return errors.New("no desktop notifier found (install libnotify's notify-send)")
+	}
+	return exec.Command(c[0], c[1:]...).Run()
+}
@@ -0,0 +1,40 @@
+package term
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// pasteCommands are the clipboard readers tried in order. OSC 52 reads are
+// disabled in most terminals, so unlike SetClipboard this has to shell out.
+func pasteCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	}
+	cmds := [][]string{{"xclip", "-o", "-selection", "clipboard"}, {"xsel", "-ob"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append([][]string{{"wl-paste", "--no-newline"}}, cmds...)
+	}
+	return cmds
+}
+
+// Paste returns the system clipboard's text via the first available reader
+// (wl-paste, xclip, xsel, pbpaste, or PowerShell's Get-Clipboard).
+func Paste() (string, error) {
+	for _, c := range pasteCommands() {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(c[0], c[1:]...).Output()
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+	return "", errors.New("no clipboard reader found (install wl-clipboard, xclip, or xsel)")
+}
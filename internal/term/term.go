@@ -5,6 +5,7 @@ package term
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 
 	"golang.org/x/term"
@@ -23,6 +24,8 @@ var (
 	initOnce sync.Once
 	mode     ColorMode
 	isTTY    bool
+	legacy   bool
+	depth    int
 )
 
 func init() {
@@ -31,8 +34,10 @@ func init() {
 
 func detect() {
 	isTTY = term.IsTerminal(int(os.Stdout.Fd()))
+	legacy = isTTY && !enableVT()
+	depth = colorDepth(os.Getenv)
 
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || legacy {
 		mode = ColorNever
 		return
 	}
@@ -55,6 +60,25 @@ func Enabled() bool {
 	}
 }
 
+// Legacy reports a console that cannot take escape sequences (Windows
+// before 10). Color is off there, and the live views fall back to plain
+// output.
+func Legacy() bool { return legacy }
+
+// Depth is the terminal's color depth in bits: 8 for the 256-color
+// palette, 24 for truecolor.
+func Depth() int { return depth }
+
+// colorDepth reads $COLORTERM, which terminals with truecolor set, before
+// the platform's guess.
+func colorDepth(getenv func(string) string) int {
+	switch getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return 24
+	}
+	return platformDepth()
+}
+
 // IsTerminal reports whether the given fd is a terminal.
 func IsTerminal(fd int) bool {
 	return term.IsTerminal(fd)
@@ -65,13 +89,20 @@ func ReadPassword(fd int) ([]byte, error) {
 	return term.ReadPassword(fd)
 }
 
-// Width returns the terminal width, defaulting to 80 if not a TTY
-// or if detection fails.
+// Width returns the terminal width. When stdout is piped (figaro show |
+// less -R) it falls back to $COLUMNS, then to the terminal on stderr, so the
+// output still wraps to the screen it ends up on. Defaults to 80.
 func Width() int {
-	if !isTTY {
+	if isTTY {
+		if c, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && c > 20 {
+			return c
+		}
 		return 80
 	}
-	if c, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && c > 20 {
+	if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 20 {
+		return c
+	}
+	if c, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && c > 20 {
 		return c
 	}
 	return 80
@@ -6,10 +6,17 @@ import (
 	"testing"
 )
 
+// keepMode restores the package color mode when t ends.
+func keepMode(t *testing.T) {
+	old := mode
+	t.Cleanup(func() { mode = old })
+}
+
 func TestDimNoColor(t *testing.T) {
 	os.Setenv("NO_COLOR", "1")
 	defer os.Unsetenv("NO_COLOR")
 	// Re-detect after env change.
+	keepMode(t)
 	mode = ColorNever
 
 	got := Dim("hello")
@@ -24,6 +31,7 @@ func TestDimNoColor(t *testing.T) {
 func TestDimForceColor(t *testing.T) {
 	os.Setenv("FORCE_COLOR", "1")
 	defer os.Unsetenv("FORCE_COLOR")
+	keepMode(t)
 	mode = ColorAlways
 
 	got := Dim("hello")
@@ -101,3 +109,70 @@ func TestWrapCount(t *testing.T) {
 		}
 	}
 }
+
+func TestSetAccent(t *testing.T) {
+	keepMode(t)
+	mode = ColorAlways
+	defer SetAccent("")
+	for spec, want := range map[string]string{
+		"":        "\033[36m",
+		"Magenta": "\033[35m",
+		"208":     "\033[38;5;208m",
+		"#ff8800": "\033[38;2;255;136;0m",
+	} {
+		if err := SetAccent(spec); err != nil {
+			t.Fatalf("SetAccent(%q): %v", spec, err)
+		}
+		if got := Accent("x"); got != want+"x"+reset {
+			t.Errorf("Accent after %q = %q, want %q", spec, got, want+"x"+reset)
+		}
+	}
+	for _, bad := range []string{"mauve", "256", "#ff88"} {
+		if SetAccent(bad) == nil {
+			t.Errorf("SetAccent(%q) must fail", bad)
+		}
+	}
+}
+
+func TestSetAccentPalette(t *testing.T) {
+	keepMode(t)
+	mode = ColorAlways
+	defer func(d int) { depth = d; SetAccent("") }(depth)
+	depth = 8
+	for spec, want := range map[string]string{
+		"#ff8800": "\033[38;5;214m",
+		"#808080": "\033[38;5;243m",
+		"#000000": "\033[38;5;16m",
+	} {
+		if err := SetAccent(spec); err != nil {
+			t.Fatal(err)
+		}
+		if got := Accent("x"); got != want+"x"+reset {
+			t.Errorf("Accent after %q = %q, want %q", spec, got, want+"x"+reset)
+		}
+	}
+}
+
+func TestColorDepth(t *testing.T) {
+	env := func(v string) func(string) string { return func(string) string { return v } }
+	if got := colorDepth(env("truecolor")); got != 24 {
+		t.Errorf("COLORTERM=truecolor: %d", got)
+	}
+	if got := colorDepth(env("")); got != platformDepth() {
+		t.Errorf("unset: %d", got)
+	}
+}
+
+func TestWidthHonorsColumnsWhenPiped(t *testing.T) {
+	if isTTY {
+		t.Skip("stdout is a terminal")
+	}
+	t.Setenv("COLUMNS", "132")
+	if got := Width(); got != 132 {
+		t.Fatalf("Width() = %d, want $COLUMNS (132)", got)
+	}
+	t.Setenv("COLUMNS", "junk")
+	if got := Width(); got < 20 {
+		t.Fatalf("Width() = %d with a bad $COLUMNS", got)
+	}
+}
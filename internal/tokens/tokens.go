@@ -54,14 +54,14 @@ func EstimateMessage(m message.Message) int {
 			chars += len(c.Text)
 		case message.ContentImage:
 			chars += 4800
-		case message.ContentToolInvoke:
+		case message.ContentToolInvoke, message.ContentServerToolInvoke:
 			chars += len(c.ToolName)
 			if c.Arguments != nil {
 				if b, err := json.Marshal(c.Arguments); err == nil {
 					chars += len(b)
 				}
 			}
-		case message.ContentToolResult:
+		case message.ContentToolResult, message.ContentServerToolResult:
 			chars += len(c.ToolCallID) + len(c.ToolName) + len(c.Text)
 		}
 	}
@@ -0,0 +1,35 @@
+package tool
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// BenchmarkToolOutputAggregation streams a command's output into an exec
+// session in 4 KiB chunks, as the bash tool's sink does, then formats the
+// result the model sees. Past sessionBufferCap every chunk drops the front
+// of the buffer, so the large sizes watch that path too.
+func BenchmarkToolOutputAggregation(b *testing.B) {
+	line := "ok  \tgithub.com/jack-work/figaro/internal/store\t0.412s\tcoverage: 81.2% of statements\n"
+	for _, size := range []int{64 << 10, 1 << 20, 8 << 20} {
+		out := []byte(strings.Repeat(line, size/len(line)+1)[:size])
+		b.Run(fmt.Sprintf("bytes=%d", size), func(b *testing.B) {
+			reg := NewSessionRegistry(DefaultSessionTTL)
+			bt := &BashTool{}
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sess := reg.Create("bench", "go test ./...")
+				for off := 0; off < len(out); off += 4 << 10 {
+					sess.writeChunk(out[off:min(off+4<<10, len(out))])
+				}
+				if _, err := bt.formatResult(sess.Log(), 1, false, false, time.Minute); err != nil {
+					b.Fatal(err)
+				}
+				reg.Remove("bench", sess.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package toolout
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Trim bounds s to about max bytes, keeping its head and tail and putting
+// a marker that names the cut in place of the middle. The cut snaps to
+// nearby line breaks and never splits a rune. max <= 0 keeps s whole;
+// trimmed reports whether anything was cut.
+func Trim(s string, max int) (out string, trimmed bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	head, tail := max/2, max-max/2
+	headEnd := head
+	for headEnd > 0 && !utf8.RuneStart(s[headEnd]) {
+		headEnd--
+	}
+	if i := strings.LastIndexByte(s[:headEnd], '\n'); i >= headEnd/2 {
+		headEnd = i + 1
+	}
+	tailStart := len(s) - tail
+	for tailStart < len(s) && !utf8.RuneStart(s[tailStart]) {
+		tailStart++
+	}
+	if i := strings.IndexByte(s[tailStart:], '\n'); i >= 0 && i < tail/2 {
+		tailStart += i + 1
+	}
+	marker := fmt.Sprintf("[… %d of %d bytes trimmed from the middle …]\n", tailStart-headEnd, len(s))
+	if headEnd > 0 && s[headEnd-1] != '\n' {
+		marker = "\n" + marker
+	}
+	return s[:headEnd] + marker + s[tailStart:], true
+}
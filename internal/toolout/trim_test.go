@@ -0,0 +1,51 @@
+package toolout
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTrimKeepsShortText(t *testing.T) {
+	for _, max := range []int{0, -1, 100} {
+		if out, trimmed := Trim("short", max); out != "short" || trimmed {
+			t.Fatalf("Trim(short, %d) = %q, %v", max, out, trimmed)
+		}
+	}
+}
+
+func TestTrimKeepsHeadAndTail(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 1000; i++ {
+		b.WriteString("line of tool output\n")
+	}
+	in := "FIRST\n" + b.String() + "LAST\n"
+	out, trimmed := Trim(in, 400)
+	if !trimmed {
+		t.Fatal("not trimmed")
+	}
+	if !strings.HasPrefix(out, "FIRST\n") || !strings.HasSuffix(out, "LAST\n") {
+		t.Fatalf("head or tail lost:\n%s", out)
+	}
+	if !strings.Contains(out, "bytes trimmed from the middle") {
+		t.Fatalf("no marker:\n%s", out)
+	}
+	if len(out) > 400+80 {
+		t.Fatalf("len = %d, want about 400", len(out))
+	}
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if l != "FIRST" && l != "LAST" && l != "line of tool output" && !strings.HasPrefix(l, "[…") {
+			t.Fatalf("cut mid-line: %q", l)
+		}
+	}
+}
+
+func TestTrimNeverSplitsRunes(t *testing.T) {
+	in := strings.Repeat("é日本", 500)
+	for _, max := range []int{7, 64, 101} {
+		out, _ := Trim(in, max)
+		if !utf8.ValidString(out) {
+			t.Fatalf("max %d: invalid UTF-8", max)
+		}
+	}
+}
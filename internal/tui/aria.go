@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// PickAria shows a filterable select of arias ("/" filters on the label and
+// hint) and returns the chosen Key. Options arrive most-recent first.
+//
+// Falls back to a numbered prompt when Available() is false.
+func PickAria(options []ProviderOption) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("no arias to choose from")
+	}
+	if !Available() {
+		return pickProviderFallback("Choose an aria", options)
+	}
+
+	hopts := make([]huh.Option[string], 0, len(options))
+	for _, o := range options {
+		display := o.Label
+		if o.Hint != "" {
+			display = fmt.Sprintf("%s — %s", o.Label, o.Hint)
+		}
+		hopts = append(hopts, huh.NewOption(display, o.Key))
+	}
+
+	var chosen string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Choose an aria").
+				Description("j/k or ↑/↓ to move, / to filter, Enter to open.").
+				Options(hopts...).
+				Height(min(len(hopts)+3, 20)).
+				Value(&chosen),
+		),
+	)
+	withFigaroKeymap(form)
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return chosen, nil
+}